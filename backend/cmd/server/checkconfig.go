@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"better-kiro-prompts/internal/config"
+)
+
+// checkConfig loads and validates the config at path, writes the effective
+// settings to stdout with secrets redacted, and returns the process exit code.
+// Nothing is started or connected.
+func checkConfig(path string, stdout, stderr io.Writer) int {
+	// A missing file silently falls back to defaults at startup; in a check it is a mistake
+	if _, err := os.Stat(path); err != nil {
+		fmt.Fprintf(stderr, "Configuration error: %v\n", err)
+		return 1
+	}
+
+	cfg, err := config.LoadFromPath(path)
+	if err != nil {
+		fmt.Fprintf(stderr, "Configuration error: %v\n", err)
+		return 1
+	}
+
+	cfg.LogConfig(slog.New(slog.NewTextHandler(stdout, nil)))
+	fmt.Fprintf(stdout, "Configuration OK: %s\n", path)
+	return 0
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	return path
+}
+
+func TestCheckConfig_ValidConfig(t *testing.T) {
+	secret := "internal-key-0123456789abcdef0123456789"
+	t.Setenv("INTERNAL_API_KEY", secret)
+	path := writeConfigFile(t, "[server]\nport = 9999\n")
+
+	var stdout, stderr bytes.Buffer
+	if code := checkConfig(path, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code = %d, want 0 (stderr: %s)", code, stderr.String())
+	}
+
+	out := stdout.String()
+	if !strings.Contains(out, "server.port=9999") {
+		t.Errorf("output missing effective port:\n%s", out)
+	}
+	if !strings.Contains(out, "server.internal_api_key_set=true") {
+		t.Errorf("output missing redacted key flag:\n%s", out)
+	}
+	if strings.Contains(out, secret) {
+		t.Error("output contains the internal API key")
+	}
+}
+
+func TestCheckConfig_InvalidConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		path func(t *testing.T) string
+		want string
+	}{
+		{"validation error", func(t *testing.T) string { return writeConfigFile(t, "[server]\nport = 0\n") }, "port"},
+		{"parse error", func(t *testing.T) string { return writeConfigFile(t, "[server\n") }, "parse"},
+		{"missing file", func(t *testing.T) string { return filepath.Join(t.TempDir(), "missing.toml") }, "missing.toml"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := checkConfig(tt.path(t), &stdout, &stderr); code == 0 {
+				t.Fatal("exit code = 0, want non-zero")
+			}
+			if !strings.Contains(stderr.String(), tt.want) {
+				t.Errorf("stderr = %q, want it to mention %q", stderr.String(), tt.want)
+			}
+			if stdout.Len() != 0 {
+				t.Errorf("stdout = %q, want nothing on failure", stdout.String())
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -13,10 +14,13 @@ import (
 	"better-kiro-prompts/internal/api"
 	"better-kiro-prompts/internal/config"
 	"better-kiro-prompts/internal/db"
+	"better-kiro-prompts/internal/download"
 	"better-kiro-prompts/internal/gallery"
 	"better-kiro-prompts/internal/generation"
 	"better-kiro-prompts/internal/logger"
 	"better-kiro-prompts/internal/openai"
+	"better-kiro-prompts/internal/pow"
+	"better-kiro-prompts/internal/privacy"
 	"better-kiro-prompts/internal/ratelimit"
 	"better-kiro-prompts/internal/scanner"
 	"better-kiro-prompts/internal/storage"
@@ -25,6 +29,12 @@ import (
 const version = "1.0.0"
 
 func main() {
+	checkOnly := flag.Bool("check-config", false, "validate the config, print the effective settings and exit")
+	flag.Parse()
+	if *checkOnly {
+		os.Exit(checkConfig(config.Path(), os.Stdout, os.Stderr))
+	}
+
 	ctx := context.Background()
 
 	// Load configuration first (before logger, as logger config comes from here)
@@ -55,79 +65,174 @@ func main() {
 	// Log loaded configuration (with sensitive values redacted)
 	cfg.LogConfig(appLog.App())
 
-	// Database connection
-	appLog.App().Info("database_connecting")
-	db.SetLogger(appLog.DB()) // Set logger for database operations
-	if err := db.Connect(ctx); err != nil {
-		appLog.App().Error("database_connection_failed", slog.String("error", err.Error()))
-		os.Exit(1)
+	// Salt client IP hashes before anything stores or logs one
+	privacy.SetIPSalt(cfg.Server.IPHashSalt)
+	privacy.SetPreviousIPSalt(cfg.Server.PreviousIPHashSalt, cfg.Server.PreviousSaltUntil)
+
+	// Database connection (the memory storage backend runs without one)
+	memoryStorage := cfg.Storage.Backend == "memory"
+	if memoryStorage {
+		appLog.App().Warn("database_skipped",
+			slog.String("reason", "storage backend is memory"),
+			slog.String("impact", "stored generations are lost on restart"))
+	} else {
+		appLog.App().Info("database_connecting")
+		db.SetLogger(appLog.DB()) // Set logger for database operations
+		db.SetPoolConfig(db.PoolConfig{
+			MaxOpenConns:    cfg.Database.MaxOpenConns,
+			MaxIdleConns:    cfg.Database.MaxIdleConns,
+			ConnMaxLifetime: cfg.Database.ConnMaxLifetime.Duration(),
+			ConnMaxIdleTime: cfg.Database.ConnMaxIdleTime.Duration(),
+		})
+		if err := db.Connect(ctx); err != nil {
+			appLog.App().Error("database_connection_failed", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		appLog.App().Info("database_connected")
+		db.ConnectReplica(ctx, cfg.Database.ReplicaURL)
 	}
-	appLog.App().Info("database_connected")
 
 	// Use port from config (already includes env var override)
 	port := fmt.Sprintf("%d", cfg.Server.Port)
 
+	// Config validation already rejected malformed entries
+	trustedProxies, err := api.ParseTrustedProxies(cfg.Server.TrustedProxies)
+	if err != nil {
+		appLog.App().Error("trusted_proxies_invalid", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
 	// Initialize dependencies
 	routerCfg := &api.RouterConfig{
-		Logger: appLog,
+		Logger:         appLog,
+		MaxBodyBytes:   cfg.Server.MaxBodyBytes,
+		MaxImportBytes: cfg.Server.MaxImportBytes,
+		TrustedProxies: trustedProxies,
+		Maintenance:    api.NewMaintenanceMode(cfg.Server.Maintenance),
+		InternalAPIKey: cfg.Server.InternalAPIKey,
 	}
 
-	// Initialize storage repository for gallery (only if DB is connected)
+	// Background sweepers stop when the server shuts down
+	sweeperCtx, stopSweeper := context.WithCancel(context.Background())
+	defer stopSweeper()
+
+	// Initialize storage repository for gallery (in memory, or only if DB is connected)
+	var galleryRepo storage.Repository
+	var memoryRepo *storage.MemoryRepository
 	var loggingDB *db.LoggingDB
-	if db.DB != nil {
+	if memoryStorage {
+		memoryRepo = storage.NewMemoryRepository()
+		galleryRepo = memoryRepo
+	} else if db.DB != nil {
 		loggingDB = db.NewLoggingDB(db.DB, appLog.DB())
+		loggingDB.SetSlowQueryThreshold(cfg.Database.SlowQueryThreshold.Duration())
+		routerCfg.QueryStats = loggingDB
 		repo := storage.NewPostgresRepositoryWithLogging(loggingDB)
+		repo.SetQueryTimeout(cfg.Database.QueryTimeout.Duration())
+		if db.ReplicaDB != nil {
+			replicaDB := db.NewLoggingDB(db.ReplicaDB, appLog.DB())
+			replicaDB.SetSlowQueryThreshold(cfg.Database.SlowQueryThreshold.Duration())
+			repo.SetReplicaWithLogging(replicaDB)
+		}
+		galleryRepo = repo
+	}
 
+	var galleryService *gallery.Service
+	if galleryRepo != nil {
 		// Initialize gallery service with rating limiter using config values
 		ratingLimiter := ratelimit.NewLimiterWithConfigAndLogger(cfg.RateLimit.RatingLimitPerHour, time.Hour, appLog.App())
-		galleryService := gallery.NewServiceWithConfig(repo, ratingLimiter, appLog, cfg.Gallery)
+		galleryService = gallery.NewServiceWithConfig(galleryRepo, ratingLimiter, appLog, cfg.Gallery)
 		routerCfg.GalleryService = galleryService
 		routerCfg.RatingLimiter = ratingLimiter
+
+		// Signed ZIP download links; an empty secret is replaced per process
+		signer, err := download.NewSigner([]byte(cfg.Gallery.DownloadSecret))
+		if err != nil {
+			appLog.App().Error("download_signer_init_failed", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		routerCfg.DownloadSigner = signer
+		routerCfg.DownloadLinkTTL = cfg.Gallery.DownloadLinkTTL.Duration()
+
+		// Archive old, unpopular generations when a retention policy is set
+		go galleryService.RunArchiveSweeper(sweeperCtx)
+
 		appLog.App().Info("gallery_service_initialized",
 			slog.Int("page_size", cfg.Gallery.PageSize),
 			slog.String("default_sort", cfg.Gallery.DefaultSort),
+			slog.String("storage_backend", cfg.Storage.Backend),
+			slog.Int("archive_after_days", cfg.Gallery.ArchiveAfterDays),
 		)
 	} else {
 		appLog.App().Warn("gallery_service_unavailable",
 			slog.String("reason", "database not connected"))
 	}
 
+	// Zero disables the breaker in config; the client takes a negative threshold for that
+	breakerThreshold := cfg.OpenAI.CircuitBreakerThreshold
+	if breakerThreshold == 0 {
+		breakerThreshold = -1
+	}
+
 	// Try to create OpenAI client (optional - may not have API key in dev)
 	// Use config values for model, timeout, reasoning effort, and verbosity
 	openaiClient, err := openai.NewClientWithConfig(openai.ClientConfig{
 		APIKey:          os.Getenv("OPENAI_API_KEY"),
+		OrgID:           cfg.OpenAI.OrgID,
 		BaseURL:         cfg.OpenAI.BaseURL,
+		ReviewBaseURL:   cfg.OpenAI.CodeReviewBaseURL,
 		Model:           cfg.OpenAI.Model,
 		Timeout:         cfg.OpenAI.Timeout.Duration(),
 		ReasoningEffort: openai.ReasoningEffort(cfg.OpenAI.ReasoningEffort),
 		Verbosity:       openai.Verbosity(cfg.OpenAI.Verbosity),
+		JSONMode:        cfg.OpenAI.JSONMode,
 		Logger:          appLog.App(),
+
+		BreakerThreshold: breakerThreshold,
+		BreakerCooldown:  cfg.OpenAI.CircuitBreakerCooldown.Duration(),
 	})
 	if err != nil {
 		appLog.App().Warn("openai_client_unavailable",
 			slog.String("error", err.Error()),
-			slog.String("impact", "generation endpoints will not be available"))
-	} else {
-		// Create generation service with repository for gallery storage and config
-		var repo storage.Repository
-		if loggingDB != nil {
-			repo = storage.NewPostgresRepositoryWithLogging(loggingDB)
+			slog.String("impact", "generation endpoints will only serve dry runs"))
+	}
+
+	// Create generation service with repository for gallery storage and config.
+	// Without an OpenAI client it still serves dry-run prompt previews.
+	var genRepo storage.Repository
+	if memoryRepo != nil {
+		genRepo = memoryRepo
+	} else if loggingDB != nil {
+		pgRepo := storage.NewPostgresRepositoryWithLogging(loggingDB)
+		pgRepo.SetQueryTimeout(cfg.Database.QueryTimeout.Duration())
+		genRepo = pgRepo
+	}
+	genService := generation.NewServiceWithConfig(openaiClient, nil, genRepo, appLog.App(), cfg.Generation)
+	genService.SetTimeouts(cfg.OpenAI.QuestionsTimeout.Duration(), cfg.OpenAI.OutputsTimeout.Duration())
+	// Use generation rate limit from config
+	rateLimiter := ratelimit.NewLimiterWithConfigAndLogger(cfg.RateLimit.GenerationLimitPerHour, time.Hour, appLog.App())
+	routerCfg.GenerationService = genService
+	routerCfg.RateLimiter = rateLimiter
+	if cfg.Generation.PowEnabled {
+		issuer, err := pow.NewIssuer([]byte(cfg.Generation.PowSecret), cfg.Generation.PowDifficulty, cfg.Generation.PowTTL.Duration())
+		if err != nil {
+			appLog.App().Error("pow_init_failed", slog.String("error", err.Error()))
+			os.Exit(1)
 		}
-		genService := generation.NewServiceWithConfig(openaiClient, nil, repo, appLog.App(), cfg.Generation)
-		// Use generation rate limit from config
-		rateLimiter := ratelimit.NewLimiterWithConfigAndLogger(cfg.RateLimit.GenerationLimitPerHour, time.Hour, appLog.App())
-		routerCfg.GenerationService = genService
-		routerCfg.RateLimiter = rateLimiter
-		appLog.App().Info("generation_service_initialized",
-			slog.Int("max_project_idea_length", cfg.Generation.MaxProjectIdeaLength),
-			slog.Int("max_answer_length", cfg.Generation.MaxAnswerLength),
-			slog.Int("min_questions", cfg.Generation.MinQuestions),
-			slog.Int("max_questions", cfg.Generation.MaxQuestions),
-			slog.Int("max_retries", cfg.Generation.MaxRetries),
-		)
+		routerCfg.ProofOfWork = issuer
 	}
+	appLog.App().Info("generation_service_initialized",
+		slog.Bool("ai_enabled", openaiClient != nil),
+		slog.Int("max_project_idea_length", cfg.Generation.MaxProjectIdeaLength),
+		slog.Int("max_answer_length", cfg.Generation.MaxAnswerLength),
+		slog.Int("min_questions", cfg.Generation.MinQuestions),
+		slog.Int("max_questions", cfg.Generation.MaxQuestions),
+		slog.Int("max_retries", cfg.Generation.MaxRetries),
+		slog.Bool("pow_enabled", routerCfg.ProofOfWork != nil),
+	)
 
 	// Initialize scanner service (requires DB, OpenAI client is optional for AI review)
+	var scannerService *scanner.Service
 	if db.DB != nil {
 		githubToken := os.Getenv("GITHUB_TOKEN")
 
@@ -138,18 +243,33 @@ func main() {
 		}
 
 		// Use NewServiceWithConfig to pass scanner configuration
-		scannerService := scanner.NewServiceWithConfig(db.DB, openaiClient, githubToken, cfg.Scanner, cfg.OpenAI.CodeReviewModel,
-			scanner.WithServiceLogger(appLog.Scanner()))
+		scannerService = scanner.NewServiceWithConfig(db.DB, openaiClient, githubToken, cfg.Scanner, cfg.OpenAI.CodeReviewModel,
+			scanner.WithServiceLogger(appLog.Scanner()),
+			scanner.WithServiceReviewTimeout(cfg.OpenAI.ReviewTimeout.Duration()))
 		// Scanner rate limiter using config values
 		scanRateLimiter := ratelimit.NewLimiterWithConfigAndLogger(cfg.RateLimit.ScanLimitPerHour, time.Hour, appLog.App())
 		routerCfg.ScannerService = scannerService
 		routerCfg.ScanRateLimiter = scanRateLimiter
 
+		// Probe scanning tools in the background so startup is not delayed
+		go scannerService.CheckTools(context.Background())
+
+		// Retained clones are tracked in memory, so remove any a previous process left
+		// behind, then remove new ones once their retention window expires
+		go func() {
+			scannerService.RemoveOrphanedClones(time.Now())
+			scannerService.RunCloneSweeper(sweeperCtx)
+		}()
+
+		// Delete scans and batches once their retention window ends
+		go scannerService.RunExpirySweeper(sweeperCtx)
+
 		appLog.App().Info("scanner_service_initialized",
 			slog.Bool("private_repo_support", githubToken != ""),
 			slog.Int("max_repo_size_mb", cfg.Scanner.MaxRepoSizeMB),
 			slog.Int("max_review_files", cfg.Scanner.MaxReviewFiles),
 			slog.Int("retention_days", cfg.Scanner.RetentionDays),
+			slog.Int("retain_clone_minutes", cfg.Scanner.RetainCloneMinutes),
 			slog.Int("tool_timeout_seconds", cfg.Scanner.ToolTimeoutSeconds),
 		)
 	} else {
@@ -175,6 +295,30 @@ func main() {
 	shutdown := make(chan os.Signal, 1)
 	signal.Notify(shutdown, syscall.SIGINT, syscall.SIGTERM)
 
+	// Reload rate limits, log level, model settings and maintenance mode on SIGHUP.
+	// Structural settings (port, host, scanner, database) still need a restart.
+	hotReload := &reloader{
+		current:       cfg,
+		log:           appLog,
+		openaiClient:  openaiClient,
+		genLimiter:    routerCfg.RateLimiter,
+		ratingLimiter: routerCfg.RatingLimiter,
+		scanLimiter:   routerCfg.ScanRateLimiter,
+		maintenance:   routerCfg.Maintenance,
+	}
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	go func() {
+		for range reload {
+			next, err := config.Load()
+			if err != nil {
+				appLog.App().Error("config_reload_failed", slog.String("error", err.Error()))
+				continue
+			}
+			hotReload.apply(next)
+		}
+	}()
+
 	// Start server in a goroutine
 	go func() {
 		appLog.App().Info("server_starting", slog.String("port", port))
@@ -199,6 +343,27 @@ func main() {
 		appLog.App().Info("server_stopped_gracefully")
 	}
 
+	// Drain in-flight scans before the database goes away
+	if scannerService != nil {
+		if err := scannerService.DrainScans(shutdownCtx); err != nil {
+			appLog.App().Warn("scan_drain_incomplete", slog.String("error", err.Error()))
+		} else {
+			appLog.App().Info("scans_drained")
+		}
+		stopSweeper()
+		scannerService.RemoveRetainedClones()
+	}
+
+	// Stop the archive sweeper before the database goes away
+	stopSweeper()
+
+	// Let queued top-rated webhooks finish delivering
+	if galleryService != nil {
+		if err := galleryService.WaitWebhooks(shutdownCtx); err != nil {
+			appLog.App().Warn("webhook_drain_incomplete", slog.String("error", err.Error()))
+		}
+	}
+
 	// Close database connection
 	if err := db.Close(); err != nil {
 		appLog.App().Error("database_close_error", slog.String("error", err.Error()))
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"log/slog"
+
+	"better-kiro-prompts/internal/api"
+	"better-kiro-prompts/internal/config"
+	"better-kiro-prompts/internal/logger"
+	"better-kiro-prompts/internal/openai"
+	"better-kiro-prompts/internal/ratelimit"
+)
+
+// reloader applies config changes to running components on SIGHUP.
+// Components that are not configured (nil) are skipped.
+type reloader struct {
+	current       *config.Config
+	log           *logger.Logger
+	openaiClient  *openai.Client
+	genLimiter    *ratelimit.Limiter
+	ratingLimiter *ratelimit.Limiter
+	scanLimiter   *ratelimit.Limiter
+	maintenance   *api.MaintenanceMode
+}
+
+// apply switches the running server to next's reloadable settings and warns
+// about changes that need a restart. Connections are left untouched.
+func (r *reloader) apply(next *config.Config) {
+	reloaded, ignored := r.current.WithReloaded(next)
+
+	if r.genLimiter != nil {
+		r.genLimiter.SetLimit(reloaded.RateLimit.GenerationLimitPerHour)
+	}
+	if r.ratingLimiter != nil {
+		r.ratingLimiter.SetLimit(reloaded.RateLimit.RatingLimitPerHour)
+	}
+	if r.scanLimiter != nil {
+		r.scanLimiter.SetLimit(reloaded.RateLimit.ScanLimitPerHour)
+	}
+	r.log.SetLevel(logger.ParseLevel(reloaded.Logging.Level))
+	if r.openaiClient != nil {
+		r.openaiClient.SetReasoningEffort(openai.ReasoningEffort(reloaded.OpenAI.ReasoningEffort))
+		r.openaiClient.SetVerbosity(openai.Verbosity(reloaded.OpenAI.Verbosity))
+	}
+	if r.maintenance != nil {
+		r.maintenance.Set(reloaded.Server.Maintenance)
+	}
+	r.current = reloaded
+
+	r.log.App().Info("config_reloaded",
+		slog.Int("generation_limit_per_hour", reloaded.RateLimit.GenerationLimitPerHour),
+		slog.Int("rating_limit_per_hour", reloaded.RateLimit.RatingLimitPerHour),
+		slog.Int("scan_limit_per_hour", reloaded.RateLimit.ScanLimitPerHour),
+		slog.String("log_level", reloaded.Logging.Level),
+		slog.String("reasoning_effort", reloaded.OpenAI.ReasoningEffort),
+		slog.String("verbosity", reloaded.OpenAI.Verbosity),
+		slog.Bool("maintenance", reloaded.Server.Maintenance),
+	)
+	if len(ignored) > 0 {
+		r.log.App().Warn("config_reload_requires_restart",
+			slog.Any("sections", ignored),
+		)
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"better-kiro-prompts/internal/api"
+	"better-kiro-prompts/internal/config"
+	"better-kiro-prompts/internal/logger"
+	"better-kiro-prompts/internal/ratelimit"
+)
+
+func TestReloader_UpdatesGenerationLimit(t *testing.T) {
+	dir := t.TempDir()
+	log, err := logger.New(logger.Config{Level: logger.LevelInfo, LogDir: dir})
+	if err != nil {
+		t.Fatalf("logger.New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = log.Close() })
+
+	cfg := config.DefaultConfig()
+	genLimiter := ratelimit.NewLimiterWithConfig(cfg.RateLimit.GenerationLimitPerHour, time.Hour)
+	r := &reloader{
+		current:     cfg,
+		log:         log,
+		genLimiter:  genLimiter,
+		maintenance: api.NewMaintenanceMode(false),
+	}
+
+	path := filepath.Join(dir, "config.toml")
+	content := "[server]\nport = 9999\nmaintenance = true\n\n[rate_limit]\ngeneration_limit_per_hour = 2\n\n[logging]\nlevel = \"DEBUG\"\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	next, err := config.LoadFromPath(path)
+	if err != nil {
+		t.Fatalf("LoadFromPath() error = %v", err)
+	}
+
+	r.apply(next)
+
+	if got := genLimiter.Limit(); got != 2 {
+		t.Errorf("generation limit = %d, want 2", got)
+	}
+	for i := 0; i < 2; i++ {
+		if ok, _ := genLimiter.Allow("203.0.113.7"); !ok {
+			t.Fatalf("request %d denied, want allowed", i+1)
+		}
+	}
+	if ok, _ := genLimiter.Allow("203.0.113.7"); ok {
+		t.Error("third request allowed, want the reloaded cap of 2 enforced")
+	}
+
+	if got := log.GetLevel(); got != logger.LevelDebug {
+		t.Errorf("log level = %v, want DEBUG", got)
+	}
+	if !r.maintenance.Enabled() {
+		t.Error("maintenance mode not enabled by reload")
+	}
+	// The port is structural, so the running config keeps the old value
+	if r.current.Server.Port != cfg.Server.Port {
+		t.Errorf("port = %d, want unchanged %d", r.current.Server.Port, cfg.Server.Port)
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"log/slog"
 	"net/http"
+	"time"
 
+	"better-kiro-prompts/internal/db"
 	"better-kiro-prompts/internal/logger"
 )
 
@@ -40,7 +42,7 @@ func HandleSetLogLevel(log *logger.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req LogLevelRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			WriteBadRequest(w, r, "invalid request body")
+			writeDecodeError(w, r, err)
 			return
 		}
 
@@ -69,6 +71,61 @@ func HandleSetLogLevel(log *logger.Logger) http.HandlerFunc {
 	}
 }
 
+// QueryStatsSource provides database query metrics; *db.LoggingDB implements it.
+type QueryStatsSource interface {
+	QueryStats() []db.QueryStat
+	SlowQueryThreshold() time.Duration
+}
+
+// QueryStatResponse reports the recorded durations of one query.
+type QueryStatResponse struct {
+	ID        string  `json:"id"`
+	Type      string  `json:"type"`
+	Count     int64   `json:"count"`
+	SlowCount int64   `json:"slowCount"`
+	TotalMs   float64 `json:"totalMs"`
+	AvgMs     float64 `json:"avgMs"`
+	MaxMs     float64 `json:"maxMs"`
+}
+
+// QueryStatsResponse lists query metrics, slowest total time first.
+type QueryStatsResponse struct {
+	SlowQueryThresholdMs float64             `json:"slowQueryThresholdMs"`
+	Queries              []QueryStatResponse `json:"queries"`
+}
+
+// HandleGetQueryStats returns per-query durations recorded since startup
+func HandleGetQueryStats(src QueryStatsSource) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		stats := src.QueryStats()
+		resp := QueryStatsResponse{
+			SlowQueryThresholdMs: milliseconds(src.SlowQueryThreshold()),
+			Queries:              make([]QueryStatResponse, 0, len(stats)),
+		}
+		for _, stat := range stats {
+			var avg time.Duration
+			if stat.Count > 0 {
+				avg = stat.Total / time.Duration(stat.Count)
+			}
+			resp.Queries = append(resp.Queries, QueryStatResponse{
+				ID:        stat.ID,
+				Type:      stat.Type,
+				Count:     stat.Count,
+				SlowCount: stat.SlowCount,
+				TotalMs:   milliseconds(stat.Total),
+				AvgMs:     milliseconds(avg),
+				MaxMs:     milliseconds(stat.Max),
+			})
+		}
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+// milliseconds converts d to fractional milliseconds.
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
 // levelToString converts a slog.Level to its string representation
 func levelToString(level slog.Level) string {
 	switch level {
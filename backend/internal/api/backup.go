@@ -0,0 +1,122 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"better-kiro-prompts/internal/storage"
+)
+
+// GalleryImportResponse reports the result of a gallery import.
+type GalleryImportResponse struct {
+	Imported int `json:"imported"`
+}
+
+// HandleExportGallery handles GET /api/admin/gallery/export.
+// It streams every generation as NDJSON and requires the internal API key.
+func (h *GalleryHandler) HandleExportGallery(w http.ResponseWriter, r *http.Request) {
+	if !requireInternalKey(w, r) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", `attachment; filename="gallery-`+time.Now().UTC().Format("20060102-150405")+`.ndjson"`)
+
+	cw := &countingWriter{w: w}
+	if err := h.service.ExportGenerations(r.Context(), cw); err != nil {
+		if cw.n == 0 {
+			w.Header().Del("Content-Disposition")
+			WriteServiceError(w, r, err, "Failed to export gallery")
+			return
+		}
+		// Abort rather than end cleanly so a truncated backup is never mistaken for a complete one
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// streamFlushEvery is how many NDJSON lines HandleStreamGallery writes
+// between flushes.
+const streamFlushEvery = 100
+
+// HandleStreamGallery handles GET /api/gallery/export.ndjson.
+// It streams every public generation as NDJSON, one GalleryDetail per line,
+// and requires the internal API key. Unlike the backup export it carries no
+// answers and nothing derived from IP addresses.
+func (h *GalleryHandler) HandleStreamGallery(w http.ResponseWriter, r *http.Request) {
+	if !requireInternalKey(w, r) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+
+	cw := &countingWriter{w: w}
+	enc := json.NewEncoder(cw)
+	flusher, _ := w.(http.Flusher)
+	lines := 0
+	err := h.service.StreamGenerations(r.Context(), func(gen *storage.Generation) error {
+		if err := enc.Encode(GalleryDetail{
+			ID:              gen.ID,
+			ProjectIdea:     gen.ProjectIdea,
+			ExperienceLevel: gen.ExperienceLevel,
+			HookPreset:      gen.HookPreset,
+			Files:           gen.Files,
+			Category:        gen.CategoryName,
+			AvgRating:       gen.AvgRating,
+			RatingCount:     gen.RatingCount,
+			ViewCount:       gen.ViewCount,
+			CreatedAt:       formatTimestamp(gen.CreatedAt),
+			ShortCode:       gen.ShortCode,
+		}); err != nil {
+			return err
+		}
+		lines++
+		if flusher != nil && lines%streamFlushEvery == 0 {
+			flusher.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		if cw.n == 0 {
+			WriteServiceError(w, r, err, "Failed to stream gallery")
+			return
+		}
+		// Abort so a consumer can tell a truncated stream from a complete one
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// HandleImportGallery handles POST /api/admin/gallery/import.
+// The body is an export stream; it requires the internal API key.
+func (h *GalleryHandler) HandleImportGallery(w http.ResponseWriter, r *http.Request) {
+	if !requireInternalKey(w, r) {
+		return
+	}
+
+	imported, err := h.service.ImportGenerations(r.Context(), r.Body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			WritePayloadTooLarge(w, r, maxBytesErr.Limit)
+			return
+		}
+		WriteServiceError(w, r, err, "Failed to import gallery")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, GalleryImportResponse{Imported: imported})
+}
+
+// countingWriter counts the bytes written through it.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"better-kiro-prompts/internal/gallery"
+	"better-kiro-prompts/internal/storage"
+)
+
+func TestHandleStreamGallery_OneLinePerPublicGeneration(t *testing.T) {
+	ctx := context.Background()
+	repo := storage.NewMemoryRepository()
+	want := map[string]bool{}
+	for _, idea := range []string{"first", "second", "third"} {
+		gen := &storage.Generation{ProjectIdea: idea, Files: json.RawMessage(`[]`), CategoryID: 1, Answers: json.RawMessage(`[{"answer":"secret"}]`)}
+		if err := repo.CreateGeneration(ctx, gen); err != nil {
+			t.Fatalf("CreateGeneration() error = %v", err)
+		}
+		if err := repo.RecordCreator(ctx, gen.ID, "creator-hash"); err != nil {
+			t.Fatalf("RecordCreator() error = %v", err)
+		}
+		want[gen.ID] = true
+	}
+	unlisted := &storage.Generation{ProjectIdea: "hidden", Files: json.RawMessage(`[]`), CategoryID: 1, Visibility: storage.VisibilityUnlisted}
+	if err := repo.CreateGeneration(ctx, unlisted); err != nil {
+		t.Fatalf("CreateGeneration() error = %v", err)
+	}
+
+	router := NewRouter(&RouterConfig{
+		GalleryService: gallery.NewService(repo, nil, nil),
+		InternalAPIKey: testInternalKey,
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/gallery/export.ndjson", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("status without key = %d, want 401", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/gallery/export.ndjson", nil)
+	req.Header.Set(InternalKeyHeader, testInternalKey)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Errorf("Content-Type = %q, want application/x-ndjson", ct)
+	}
+
+	body := rec.Body.String()
+	if strings.Contains(body, "secret") || strings.Contains(body, "creator-hash") {
+		t.Errorf("stream leaks answers or IP-derived data: %s", body)
+	}
+
+	seen := map[string]bool{}
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		var item GalleryDetail
+		if err := json.Unmarshal(scanner.Bytes(), &item); err != nil {
+			t.Fatalf("line %q is not JSON: %v", scanner.Text(), err)
+		}
+		if !want[item.ID] || seen[item.ID] {
+			t.Errorf("unexpected or repeated generation %s", item.ID)
+		}
+		seen[item.ID] = true
+	}
+	if len(seen) != len(want) {
+		t.Errorf("stream has %d generations, want %d", len(seen), len(want))
+	}
+}
+
+func TestHandleImportGallery_UsesImportBodyLimit(t *testing.T) {
+	router := NewRouter(&RouterConfig{
+		GalleryService: gallery.NewService(storage.NewMemoryRepository(), nil, nil),
+		InternalAPIKey: testInternalKey,
+		MaxBodyBytes:   1 << 10,
+		MaxImportBytes: 4 << 10,
+	})
+
+	for _, tt := range []struct {
+		size int
+		want int
+	}{
+		{2 << 10, http.StatusOK},
+		{8 << 10, http.StatusRequestEntityTooLarge},
+	} {
+		// Blank lines are skipped, so the body's size is all that matters
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/gallery/import", strings.NewReader(strings.Repeat("\n", tt.size)))
+		req.Header.Set(InternalKeyHeader, testInternalKey)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("import of %d bytes: status = %d, want %d; body: %s", tt.size, rec.Code, tt.want, rec.Body.String())
+		}
+	}
+}
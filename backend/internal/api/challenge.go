@@ -0,0 +1,80 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"better-kiro-prompts/internal/pow"
+	"better-kiro-prompts/internal/ratelimit"
+)
+
+// Headers carrying a solved proof-of-work challenge on generation requests.
+const (
+	PowTokenHeader    = "X-PoW-Token"
+	PowSolutionHeader = "X-PoW-Solution"
+)
+
+// ChallengeResponse is the response body for GET /api/generate/challenge.
+type ChallengeResponse struct {
+	Enabled    bool   `json:"enabled"`
+	Token      string `json:"token,omitempty"`
+	Difficulty int    `json:"difficulty,omitempty"`
+	ExpiresAt  string `json:"expiresAt,omitempty"`
+}
+
+// SetProofOfWork requires a solved challenge from issuer before generations
+// call the model. A nil issuer disables the check.
+func (h *GenerateHandler) SetProofOfWork(issuer *pow.Issuer) {
+	h.pow = issuer
+}
+
+// HandleGetChallenge handles GET /api/generate/challenge.
+// When proof-of-work is disabled it reports enabled: false and no token.
+func (h *GenerateHandler) HandleGetChallenge(w http.ResponseWriter, r *http.Request) {
+	if h.pow == nil {
+		writeJSON(w, http.StatusOK, ChallengeResponse{Enabled: false})
+		return
+	}
+
+	challenge, err := h.pow.Issue()
+	if err != nil {
+		WriteInternalError(w, r, "")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ChallengeResponse{
+		Enabled:    true,
+		Token:      challenge.Token,
+		Difficulty: challenge.Difficulty,
+		ExpiresAt:  challenge.ExpiresAt.UTC().Format(time.RFC3339),
+	})
+}
+
+// checkProofOfWork verifies the challenge solution sent with a generation
+// request and writes a 403 when it is missing or wrong. It reports whether
+// the handler may continue. Internal tooling exempt from rate limits is
+// exempt here too.
+func (h *GenerateHandler) checkProofOfWork(w http.ResponseWriter, r *http.Request) bool {
+	if h.pow == nil || ratelimit.IsExempt(r.Context()) {
+		return true
+	}
+
+	token := r.Header.Get(PowTokenHeader)
+	solution := r.Header.Get(PowSolutionHeader)
+	if token == "" || solution == "" {
+		WriteError(w, r, http.StatusForbidden, ErrCodeChallenge,
+			"Proof-of-work required. Solve a challenge from GET /api/generate/challenge.")
+		return false
+	}
+
+	switch err := h.pow.Verify(token, solution); {
+	case err == nil:
+		return true
+	case errors.Is(err, pow.ErrChallengeExpired):
+		WriteError(w, r, http.StatusForbidden, ErrCodeChallenge, "Proof-of-work challenge expired. Request a new one.")
+	default:
+		WriteError(w, r, http.StatusForbidden, ErrCodeChallenge, "Invalid proof-of-work solution.")
+	}
+	return false
+}
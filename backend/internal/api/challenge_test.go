@@ -0,0 +1,127 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"better-kiro-prompts/internal/generation"
+	"better-kiro-prompts/internal/pow"
+	"better-kiro-prompts/internal/ratelimit"
+)
+
+func newChallengeRouter(t *testing.T, issuer *pow.Issuer) http.Handler {
+	t.Helper()
+	return NewRouter(&RouterConfig{
+		GenerationService: generation.NewService(nil),
+		RateLimiter:       ratelimit.NewLimiterWithConfig(100, ratelimit.DefaultWindow),
+		ProofOfWork:       issuer,
+	})
+}
+
+func getChallenge(t *testing.T, router http.Handler) ChallengeResponse {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/generate/challenge", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /api/generate/challenge status = %d, want 200", rec.Code)
+	}
+	var resp ChallengeResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	return resp
+}
+
+func postQuestionsWithPow(router http.Handler, token, solution string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/generate/questions",
+		strings.NewReader(`{"projectIdea":"A todo app","experienceLevel":"beginner"}`))
+	if token != "" {
+		req.Header.Set(PowTokenHeader, token)
+		req.Header.Set(PowSolutionHeader, solution)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestProofOfWork_ValidSolutionAccepted(t *testing.T) {
+	issuer, err := pow.NewIssuer([]byte("secret"), 8, time.Minute)
+	if err != nil {
+		t.Fatalf("NewIssuer() error = %v", err)
+	}
+	router := newChallengeRouter(t, issuer)
+
+	challenge := getChallenge(t, router)
+	if !challenge.Enabled || challenge.Token == "" || challenge.Difficulty != 8 {
+		t.Fatalf("challenge = %+v, want an enabled challenge at difficulty 8", challenge)
+	}
+
+	rec := postQuestionsWithPow(router, challenge.Token, pow.Solve(challenge.Token, challenge.Difficulty))
+	// Without an AI client the request fails later; it must get past the gate
+	if rec.Code == http.StatusForbidden {
+		t.Errorf("status = 403 for a valid solution; body: %s", rec.Body.String())
+	}
+}
+
+func TestProofOfWork_InvalidOrExpiredRejected(t *testing.T) {
+	issuer, err := pow.NewIssuer([]byte("secret"), 8, time.Minute)
+	if err != nil {
+		t.Fatalf("NewIssuer() error = %v", err)
+	}
+	router := newChallengeRouter(t, issuer)
+	challenge := getChallenge(t, router)
+
+	// A token issued with an already-elapsed TTL by the same secret
+	expiredIssuer, err := pow.NewIssuer([]byte("secret"), 8, time.Nanosecond)
+	if err != nil {
+		t.Fatalf("NewIssuer() error = %v", err)
+	}
+	expired, err := expiredIssuer.Issue()
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+	time.Sleep(1100 * time.Millisecond)
+
+	tests := []struct {
+		name     string
+		token    string
+		solution string
+		wantMsg  string
+	}{
+		{name: "missing", wantMsg: "required"},
+		{name: "wrong solution", token: challenge.Token, solution: "not-a-solution", wantMsg: "Invalid"},
+		{name: "forged token", token: "00.1.9999999999.00", solution: "0", wantMsg: "Invalid"},
+		{name: "expired", token: expired.Token, solution: pow.Solve(expired.Token, expired.Difficulty), wantMsg: "expired"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := postQuestionsWithPow(router, tt.token, tt.solution)
+			if rec.Code != http.StatusForbidden {
+				t.Fatalf("status = %d, want 403", rec.Code)
+			}
+			var body ErrorResponse
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if body.Code != ErrCodeChallenge || !strings.Contains(body.Error, tt.wantMsg) {
+				t.Errorf("error = %q (%s), want %s containing %q", body.Error, body.Code, ErrCodeChallenge, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestProofOfWork_DisabledLeavesGenerationUnchanged(t *testing.T) {
+	router := newChallengeRouter(t, nil)
+
+	if challenge := getChallenge(t, router); challenge.Enabled || challenge.Token != "" {
+		t.Errorf("challenge = %+v, want disabled with no token", challenge)
+	}
+	if rec := postQuestionsWithPow(router, "", ""); rec.Code == http.StatusForbidden {
+		t.Errorf("status = 403 with proof-of-work disabled")
+	}
+}
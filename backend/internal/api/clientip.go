@@ -0,0 +1,160 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"better-kiro-prompts/internal/logger"
+)
+
+// ErrInvalidTrustedProxy is returned when a trusted proxy entry is neither an IP nor a CIDR.
+var ErrInvalidTrustedProxy = errors.New("invalid trusted proxy")
+
+// ClientIPKey is the context key for the resolved client IP.
+const ClientIPKey contextKey = "clientIP"
+
+// ParseTrustedProxies parses trusted proxy entries, each a single IP address
+// ("10.0.0.1", "::1") or a CIDR range ("10.0.0.0/8", "fd00::/8").
+func ParseTrustedProxies(entries []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if prefix, err := netip.ParsePrefix(entry); err == nil {
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidTrustedProxy, entry)
+		}
+		addr = normalizeAddr(addr)
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+// ClientIPResolver derives the real client IP of a request. Forwarding headers
+// are only honored when the direct peer is a trusted proxy; with no trusted
+// proxies the peer address is always used.
+type ClientIPResolver struct {
+	trusted []netip.Prefix
+}
+
+// NewClientIPResolver creates a resolver that trusts the given proxy ranges.
+func NewClientIPResolver(trusted []netip.Prefix) *ClientIPResolver {
+	return &ClientIPResolver{trusted: trusted}
+}
+
+// Resolve returns the client IP for r in canonical form: IPv4-mapped IPv6
+// addresses are unmapped and IPv6 zones are dropped.
+//
+// When the peer is trusted, X-Forwarded-For is walked right to left and the
+// first untrusted hop is the client, so addresses a client prepends to the
+// header are never reached. X-Real-IP is used when X-Forwarded-For is absent.
+func (c *ClientIPResolver) Resolve(r *http.Request) string {
+	peer, ok := parseIP(r.RemoteAddr)
+	if !ok {
+		return stripPort(r.RemoteAddr)
+	}
+	if !c.isTrusted(peer) {
+		return peer.String()
+	}
+
+	if hops := forwardedHops(r.Header); len(hops) > 0 {
+		client := peer
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop, ok := parseIP(hops[i])
+			if !ok {
+				break
+			}
+			client = hop
+			if !c.isTrusted(hop) {
+				break
+			}
+		}
+		return client.String()
+	}
+
+	if realIP, ok := parseIP(r.Header.Get("X-Real-IP")); ok {
+		return realIP.String()
+	}
+	return peer.String()
+}
+
+func (c *ClientIPResolver) isTrusted(addr netip.Addr) bool {
+	for _, prefix := range c.trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIPMiddleware resolves the client IP once per request and stores it in
+// the context for rate limiting, view and rating hashing, and logging.
+func ClientIPMiddleware(resolver *ClientIPResolver) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := resolver.Resolve(r)
+			ctx := context.WithValue(r.Context(), ClientIPKey, ip)
+			ctx = logger.WithUserIP(ctx, ip)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// GetClientIP retrieves the resolved client IP from the context.
+func GetClientIP(ctx context.Context) string {
+	if ip, ok := ctx.Value(ClientIPKey).(string); ok {
+		return ip
+	}
+	return ""
+}
+
+// forwardedHops returns every X-Forwarded-For entry in order, across repeated headers.
+func forwardedHops(h http.Header) []string {
+	var hops []string
+	for _, value := range h.Values("X-Forwarded-For") {
+		for _, hop := range strings.Split(value, ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				hops = append(hops, hop)
+			}
+		}
+	}
+	return hops
+}
+
+// parseIP parses an address with or without a port, including bracketed IPv6.
+func parseIP(s string) (netip.Addr, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return netip.Addr{}, false
+	}
+	if addrPort, err := netip.ParseAddrPort(s); err == nil {
+		return normalizeAddr(addrPort.Addr()), true
+	}
+	addr, err := netip.ParseAddr(strings.TrimSuffix(strings.TrimPrefix(s, "["), "]"))
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return normalizeAddr(addr), true
+}
+
+// normalizeAddr unmaps IPv4-in-IPv6 addresses and drops IPv6 zones so the
+// same client always hashes and rate limits under one key.
+func normalizeAddr(addr netip.Addr) netip.Addr {
+	return addr.Unmap().WithZone("")
+}
+
+// stripPort removes a port from an address that could not be parsed as an IP.
+func stripPort(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
@@ -0,0 +1,153 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func mustTrustedProxies(t *testing.T, entries ...string) *ClientIPResolver {
+	t.Helper()
+	prefixes, err := ParseTrustedProxies(entries)
+	if err != nil {
+		t.Fatalf("ParseTrustedProxies(%v) error = %v", entries, err)
+	}
+	return NewClientIPResolver(prefixes)
+}
+
+func TestClientIPResolver_DirectConnection(t *testing.T) {
+	resolver := mustTrustedProxies(t)
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		want       string
+	}{
+		{name: "ipv4", remoteAddr: "203.0.113.7:51234", want: "203.0.113.7"},
+		{name: "ipv6 with port", remoteAddr: "[2001:db8::1]:51234", want: "2001:db8::1"},
+		{name: "ipv6 non-canonical", remoteAddr: "[2001:0db8:0000::0001]:443", want: "2001:db8::1"},
+		{name: "ipv6 zone dropped", remoteAddr: "[fe80::1%eth0]:80", want: "fe80::1"},
+		{name: "ipv4-mapped ipv6", remoteAddr: "[::ffff:203.0.113.7]:80", want: "203.0.113.7"},
+		{name: "no port", remoteAddr: "203.0.113.7", want: "203.0.113.7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if got := resolver.Resolve(req); got != tt.want {
+				t.Errorf("Resolve() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientIPResolver_TrustedProxyChain(t *testing.T) {
+	resolver := mustTrustedProxies(t, "10.0.0.0/8", "fd00::/8")
+
+	tests := []struct {
+		name   string
+		xff    []string
+		realIP string
+		want   string
+	}{
+		{name: "single proxy", xff: []string{"198.51.100.4"}, want: "198.51.100.4"},
+		{name: "proxy chain", xff: []string{"198.51.100.4, 10.1.2.3, fd00::5"}, want: "198.51.100.4"},
+		{name: "repeated headers", xff: []string{"198.51.100.4", "10.1.2.3"}, want: "198.51.100.4"},
+		{name: "ipv6 client", xff: []string{"2001:DB8::0042, 10.1.2.3"}, want: "2001:db8::42"},
+		{name: "x-real-ip", realIP: "198.51.100.9", want: "198.51.100.9"},
+		{name: "all hops trusted", xff: []string{"10.9.9.9, 10.1.2.3"}, want: "10.9.9.9"},
+		{name: "no headers", want: "10.0.0.2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = "10.0.0.2:8080"
+			for _, v := range tt.xff {
+				req.Header.Add("X-Forwarded-For", v)
+			}
+			if tt.realIP != "" {
+				req.Header.Set("X-Real-IP", tt.realIP)
+			}
+			if got := resolver.Resolve(req); got != tt.want {
+				t.Errorf("Resolve() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientIPResolver_IgnoresSpoofedHeaders(t *testing.T) {
+	resolver := mustTrustedProxies(t, "10.0.0.0/8")
+
+	t.Run("untrusted peer", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "203.0.113.7:51234"
+		req.Header.Set("X-Forwarded-For", "1.2.3.4")
+		req.Header.Set("X-Real-IP", "5.6.7.8")
+		if got := resolver.Resolve(req); got != "203.0.113.7" {
+			t.Errorf("Resolve() = %q, want the peer address", got)
+		}
+	})
+
+	t.Run("client-prepended hop through trusted proxy", func(t *testing.T) {
+		// The client sent "X-Forwarded-For: 1.2.3.4"; the proxy appended the real peer
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "10.0.0.2:8080"
+		req.Header.Set("X-Forwarded-For", "1.2.3.4, 203.0.113.7")
+		if got := resolver.Resolve(req); got != "203.0.113.7" {
+			t.Errorf("Resolve() = %q, want 203.0.113.7", got)
+		}
+	})
+
+	t.Run("garbage hop stops the walk", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "10.0.0.2:8080"
+		req.Header.Set("X-Forwarded-For", "1.2.3.4, not-an-ip, 10.1.2.3")
+		if got := resolver.Resolve(req); got != "10.1.2.3" {
+			t.Errorf("Resolve() = %q, want the last parseable trusted hop", got)
+		}
+	})
+}
+
+func TestClientIPMiddleware_StoresResolvedIP(t *testing.T) {
+	var got string
+	handler := ClientIPMiddleware(mustTrustedProxies(t, "127.0.0.1"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = getClientIP(r)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "127.0.0.1:9000"
+	req.Header.Set("X-Forwarded-For", "2001:db8::7")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if got != "2001:db8::7" {
+		t.Errorf("getClientIP() = %q, want 2001:db8::7", got)
+	}
+}
+
+func TestGetClientIP_WithoutMiddlewareIgnoresHeaders(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "203.0.113.7:51234"
+	req.Header.Set("X-Forwarded-For", "1.2.3.4")
+	if got := getClientIP(req); got != "203.0.113.7" {
+		t.Errorf("getClientIP() = %q, want the peer address", got)
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	prefixes, err := ParseTrustedProxies([]string{"10.0.0.0/8", "192.168.1.1", "::1", "fd00::/8"})
+	if err != nil {
+		t.Fatalf("ParseTrustedProxies() error = %v", err)
+	}
+	if len(prefixes) != 4 {
+		t.Fatalf("got %d prefixes, want 4", len(prefixes))
+	}
+
+	for _, bad := range []string{"", "10.0.0.0/33", "proxy.internal"} {
+		if _, err := ParseTrustedProxies([]string{bad}); !errors.Is(err, ErrInvalidTrustedProxy) {
+			t.Errorf("ParseTrustedProxies(%q) error = %v, want ErrInvalidTrustedProxy", bad, err)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"better-kiro-prompts/internal/download"
+	"better-kiro-prompts/internal/generation"
+)
+
+// DownloadLinkResponse is the response body for POST /api/gallery/{id}/download-link.
+type DownloadLinkResponse struct {
+	URL       string `json:"url"`
+	ExpiresAt string `json:"expiresAt"`
+}
+
+// SetDownloadLinks enables signed ZIP download links that work for ttl.
+// A nil signer disables them.
+func (h *GalleryHandler) SetDownloadLinks(signer *download.Signer, ttl time.Duration) {
+	h.downloads = signer
+	h.downloadTTL = ttl
+}
+
+// HandleCreateDownloadLink handles POST /api/gallery/{id}/download-link.
+// It returns a relative URL that downloads the generation's files as a ZIP
+// until it expires, without any other authentication.
+func (h *GalleryHandler) HandleCreateDownloadLink(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if id == "" {
+		WriteValidationError(w, r, "Invalid generation ID")
+		return
+	}
+
+	// Only sign links to generations that exist
+	if _, err := h.service.GetGeneration(r.Context(), id); err != nil {
+		WriteServiceError(w, r, err, "")
+		return
+	}
+
+	token, expiresAt := h.downloads.GenerateDownloadToken(id, h.downloadTTL)
+	writeJSON(w, http.StatusOK, DownloadLinkResponse{
+		URL:       "/api/download/" + url.PathEscape(id) + "?token=" + url.QueryEscape(token),
+		ExpiresAt: expiresAt.UTC().Format(time.RFC3339),
+	})
+}
+
+// HandleDownloadZip handles GET /api/download/{id}?token=...
+// A missing, tampered or expired token returns 403.
+func (h *GalleryHandler) HandleDownloadZip(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if err := h.downloads.Verify(id, r.URL.Query().Get("token")); err != nil {
+		if errors.Is(err, download.ErrTokenExpired) {
+			WriteError(w, r, http.StatusForbidden, ErrCodeLinkInvalid, "This download link has expired. Request a new one.")
+			return
+		}
+		WriteError(w, r, http.StatusForbidden, ErrCodeLinkInvalid, "Invalid download link.")
+		return
+	}
+
+	gen, err := h.service.GetGeneration(r.Context(), id)
+	if err != nil {
+		WriteServiceError(w, r, err, "")
+		return
+	}
+
+	var files []generation.GeneratedFile
+	if err := json.Unmarshal(gen.Files, &files); err != nil {
+		WriteInternalError(w, r, "")
+		return
+	}
+	archive, err := zipFiles(files)
+	if err != nil {
+		WriteInternalError(w, r, "")
+		return
+	}
+
+	name := gen.ShortCode
+	if name == "" {
+		name = gen.ID
+	}
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="kiro-files-%s.zip"`, name))
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(archive)
+}
+
+// zipFiles builds a ZIP of files. Paths that would escape the archive root
+// are skipped.
+func zipFiles(files []generation.GeneratedFile) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range files {
+		name := path.Clean(strings.ReplaceAll(f.Path, `\`, "/"))
+		if name == "." || path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			continue
+		}
+		fw, err := zw.Create(name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := fw.Write([]byte(f.Content)); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"better-kiro-prompts/internal/download"
+	"better-kiro-prompts/internal/gallery"
+	"better-kiro-prompts/internal/storage"
+)
+
+// newDownloadRouter returns a router with download links enabled and the ID
+// of a stored generation with two files.
+func newDownloadRouter(t *testing.T, signer *download.Signer) (http.Handler, string) {
+	t.Helper()
+	repo := storage.NewMemoryRepository()
+	gen := &storage.Generation{
+		ProjectIdea: "A todo app",
+		Files: json.RawMessage(`[
+			{"path":"kickoff-prompt.md","content":"# Kickoff","type":"kickoff"},
+			{"path":".kiro/steering/product.md","content":"# Product","type":"steering"}
+		]`),
+	}
+	if err := repo.CreateGeneration(context.Background(), gen); err != nil {
+		t.Fatalf("CreateGeneration() error = %v", err)
+	}
+
+	router := NewRouter(&RouterConfig{
+		GalleryService:  gallery.NewService(repo, nil, nil),
+		DownloadSigner:  signer,
+		DownloadLinkTTL: time.Hour,
+	})
+	return router, gen.ID
+}
+
+func TestHandleDownloadZip_FreshLinkDownloads(t *testing.T) {
+	signer, err := download.NewSigner([]byte("test-secret"))
+	if err != nil {
+		t.Fatalf("NewSigner() error = %v", err)
+	}
+	router, id := newDownloadRouter(t, signer)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/gallery/"+id+"/download-link", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST download-link status = %d, want 200; body: %s", rec.Code, rec.Body.String())
+	}
+	var link DownloadLinkResponse
+	if err := json.NewDecoder(rec.Body).Decode(&link); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, link.URL, nil)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET %s status = %d, want 200; body: %s", link.URL, rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/zip" {
+		t.Errorf("Content-Type = %q, want application/zip", ct)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+	if err != nil {
+		t.Fatalf("response is not a ZIP: %v", err)
+	}
+	contents := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", f.Name, err)
+		}
+		data, _ := io.ReadAll(rc)
+		_ = rc.Close()
+		contents[f.Name] = string(data)
+	}
+	if contents["kickoff-prompt.md"] != "# Kickoff" || contents[".kiro/steering/product.md"] != "# Product" {
+		t.Errorf("ZIP contents = %v", contents)
+	}
+}
+
+func TestHandleDownloadZip_RejectsExpiredAndTamperedLinks(t *testing.T) {
+	signer, err := download.NewSigner([]byte("test-secret"))
+	if err != nil {
+		t.Fatalf("NewSigner() error = %v", err)
+	}
+	router, id := newDownloadRouter(t, signer)
+
+	expired, _ := signer.GenerateDownloadToken(id, -time.Minute)
+	valid, _ := signer.GenerateDownloadToken(id, time.Hour)
+	// Push the expiry later without re-signing
+	expires, signature, _ := strings.Cut(valid, ".")
+	tampered := expires[:len(expires)-5] + "99999." + signature
+
+	for name, token := range map[string]string{"expired": expired, "tampered": tampered, "missing": ""} {
+		req := httptest.NewRequest(http.MethodGet, "/api/download/"+id+"?token="+token, nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("%s token: status = %d, want 403", name, rec.Code)
+			continue
+		}
+		var body ErrorResponse
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil || body.Code != ErrCodeLinkInvalid {
+			t.Errorf("%s token: code = %q (%v), want %s", name, body.Code, err, ErrCodeLinkInvalid)
+		}
+	}
+}
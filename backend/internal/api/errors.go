@@ -1,8 +1,19 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
+	"strconv"
+
+	"better-kiro-prompts/internal/gallery"
+	"better-kiro-prompts/internal/generation"
+	"better-kiro-prompts/internal/openai"
+	"better-kiro-prompts/internal/scanner"
+	"better-kiro-prompts/internal/storage"
 )
 
 // Error codes for structured error responses.
@@ -14,6 +25,10 @@ const (
 	ErrCodeNotFound     = "CLIENT_NOT_FOUND"
 	ErrCodeBadRequest   = "CLIENT_BAD_REQUEST"
 	ErrCodeUnauthorized = "CLIENT_UNAUTHORIZED"
+	ErrCodeTooLarge     = "CLIENT_PAYLOAD_TOO_LARGE"
+	ErrCodeConflict     = "CLIENT_CONFLICT"
+	ErrCodeChallenge    = "CLIENT_CHALLENGE_FAILED"
+	ErrCodeLinkInvalid  = "CLIENT_LINK_INVALID"
 
 	// Server errors (5xx)
 	ErrCodeInternal    = "SERVER_INTERNAL"
@@ -53,7 +68,7 @@ func WriteErrorWithRetry(w http.ResponseWriter, r *http.Request, statusCode int,
 
 	w.Header().Set("Content-Type", "application/json")
 	if retryAfterSeconds > 0 {
-		w.Header().Set("Retry-After", string(rune(retryAfterSeconds)))
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfterSeconds))
 	}
 	w.WriteHeader(statusCode)
 	_ = json.NewEncoder(w).Encode(resp)
@@ -71,6 +86,23 @@ func WriteValidationError(w http.ResponseWriter, r *http.Request, message string
 	WriteError(w, r, http.StatusBadRequest, ErrCodeValidation, message)
 }
 
+// WritePayloadTooLarge writes a 413 Payload Too Large error.
+func WritePayloadTooLarge(w http.ResponseWriter, r *http.Request, limitBytes int64) {
+	WriteError(w, r, http.StatusRequestEntityTooLarge, ErrCodeTooLarge,
+		fmt.Sprintf("Request body exceeds the maximum size of %d bytes.", limitBytes))
+}
+
+// writeDecodeError writes a 413 if a JSON body was rejected by the body size limit,
+// or a 400 for any other decoding failure.
+func writeDecodeError(w http.ResponseWriter, r *http.Request, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		WritePayloadTooLarge(w, r, maxBytesErr.Limit)
+		return
+	}
+	WriteBadRequest(w, r, "Invalid request body")
+}
+
 // WriteNotFound writes a 404 Not Found error.
 func WriteNotFound(w http.ResponseWriter, r *http.Request, message string) {
 	WriteError(w, r, http.StatusNotFound, ErrCodeNotFound, message)
@@ -103,6 +135,103 @@ func WriteServiceUnavailable(w http.ResponseWriter, r *http.Request, retryAfterS
 		"Service temporarily unavailable. Please try again later.", retryAfterSeconds)
 }
 
+// errorMapping describes the response for a service sentinel error.
+type errorMapping struct {
+	target     error
+	status     int
+	code       string
+	message    string // Empty passes the error text through
+	retryAfter int
+}
+
+// errorMappings maps service sentinel errors to HTTP responses. Entries are
+// matched in order with errors.Is, so more specific errors come first.
+// The codes are part of the API contract documented in docs/api.md.
+var errorMappings = []errorMapping{
+	// OpenAI circuit breaker, shared by generation and scanning
+	{target: openai.ErrCircuitOpen, status: http.StatusServiceUnavailable, code: ErrCodeUnavailable, message: "AI is temporarily unavailable. Please try again shortly.", retryAfter: 30},
+
+	// Gallery
+	{target: gallery.ErrNotFound, status: http.StatusNotFound, code: ErrCodeNotFound, message: "Generation not found"},
+	{target: gallery.ErrInvalidSort, status: http.StatusBadRequest, code: ErrCodeValidation, message: "Invalid sort option"},
+	{target: gallery.ErrInvalidRating, status: http.StatusBadRequest, code: ErrCodeValidation, message: "Score must be between 1 and 5"},
+	{target: gallery.ErrInvalidPage, status: http.StatusBadRequest, code: ErrCodeValidation, message: "Invalid page number"},
+	{target: gallery.ErrInvalidImport, status: http.StatusBadRequest, code: ErrCodeValidation},
+	{target: gallery.ErrInvalidInput, status: http.StatusBadRequest, code: ErrCodeValidation, message: "Invalid input"},
+	{target: gallery.ErrRateLimited, status: http.StatusTooManyRequests, code: ErrCodeRateLimited, message: "Too many requests. Please try again later."},
+
+	// Generation input validation; the messages are safe to show
+	{target: generation.ErrEmptyProjectIdea, status: http.StatusBadRequest, code: ErrCodeValidation},
+	{target: generation.ErrProjectIdeaTooShort, status: http.StatusBadRequest, code: ErrCodeValidation},
+	{target: generation.ErrProjectIdeaTooLong, status: http.StatusBadRequest, code: ErrCodeValidation},
+	{target: generation.ErrAnswerTooLong, status: http.StatusBadRequest, code: ErrCodeValidation},
+	{target: generation.ErrInvalidQuestionID, status: http.StatusBadRequest, code: ErrCodeValidation},
+	{target: generation.ErrDuplicateAnswer, status: http.StatusBadRequest, code: ErrCodeValidation},
+	{target: generation.ErrTooManyAnswers, status: http.StatusBadRequest, code: ErrCodeValidation},
+	{target: generation.ErrEmptyQuestion, status: http.StatusBadRequest, code: ErrCodeValidation},
+	{target: generation.ErrQuestionTooLong, status: http.StatusBadRequest, code: ErrCodeValidation},
+	{target: generation.ErrPromptTooLarge, status: http.StatusBadRequest, code: ErrCodeValidation},
+	{target: generation.ErrGenerationNotFound, status: http.StatusNotFound, code: ErrCodeNotFound, message: "Generation not found"},
+	{target: generation.ErrNoStoredInputs, status: http.StatusUnprocessableEntity, code: ErrCodeValidation},
+	{target: generation.ErrIdempotencyKeyReused, status: http.StatusUnprocessableEntity, code: ErrCodeValidation},
+	{target: generation.ErrAIUnavailable, status: http.StatusServiceUnavailable, code: ErrCodeUnavailable, message: "Service temporarily unavailable. Please try again later."},
+	{target: generation.ErrInvalidResponse, status: http.StatusInternalServerError, code: ErrCodeInternal, message: "Generation failed. Please try again later."},
+	{target: generation.ErrNoQuestions, status: http.StatusInternalServerError, code: ErrCodeInternal, message: "Generation failed. Please try again later."},
+	{target: generation.ErrNoFiles, status: http.StatusInternalServerError, code: ErrCodeInternal, message: "Generation failed. Please try again later."},
+
+	// Scanner
+	{target: scanner.ErrJobNotFound, status: http.StatusNotFound, code: ErrCodeNotFound, message: "Scan job not found"},
+	{target: scanner.ErrFindingNotFound, status: http.StatusNotFound, code: ErrCodeNotFound, message: "Finding not found"},
+	{target: scanner.ErrBatchNotFound, status: http.StatusNotFound, code: ErrCodeNotFound, message: "Scan batch not found"},
+	{target: scanner.ErrScanNotComplete, status: http.StatusConflict, code: ErrCodeConflict, message: "Scan has not completed yet"},
+	{target: scanner.ErrReviewUnavailable, status: http.StatusServiceUnavailable, code: ErrCodeUnavailable, message: "Service temporarily unavailable. Please try again later."},
+	{target: scanner.ErrTooManyScans, status: http.StatusTooManyRequests, code: ErrCodeRateLimited, message: "Too many scans running. Wait for one to finish and try again.", retryAfter: 60},
+	{target: scanner.ErrShuttingDown, status: http.StatusServiceUnavailable, code: ErrCodeUnavailable, message: "Service temporarily unavailable. Please try again later.", retryAfter: 30},
+	{target: scanner.ErrRepoNotFound, status: http.StatusNotFound, code: ErrCodeNotFound, message: "Repository not found"},
+	{target: scanner.ErrPrivateRepo, status: http.StatusBadRequest, code: ErrCodeValidation, message: "Private repositories are not supported on this server"},
+	{target: scanner.ErrRepoTooLarge, status: http.StatusBadRequest, code: ErrCodeValidation},
+	{target: scanner.ErrSubdirNotFound, status: http.StatusBadRequest, code: ErrCodeValidation, message: "Subdirectory not found in repository"},
+	{target: scanner.ErrCloneTimeout, status: http.StatusGatewayTimeout, code: ErrCodeTimeout, message: "Cloning the repository timed out. Please try again."},
+	{target: scanner.ErrScanFailed, status: http.StatusInternalServerError, code: ErrCodeInternal, message: "Scan failed. Please try again later."},
+
+	// Storage
+	{target: storage.ErrQueryTimeout, status: http.StatusServiceUnavailable, code: ErrCodeUnavailable, message: "Service temporarily unavailable. Please try again later."},
+
+	{target: context.DeadlineExceeded, status: http.StatusGatewayTimeout, code: ErrCodeTimeout, message: "Request timed out. Please try again."},
+}
+
+// lookupErrorMapping returns the mapping for the first sentinel err wraps.
+func lookupErrorMapping(err error) (errorMapping, bool) {
+	for _, m := range errorMappings {
+		if errors.Is(err, m.target) {
+			return m, true
+		}
+	}
+	return errorMapping{}, false
+}
+
+// WriteServiceError writes the structured error response for a service error.
+// Errors without a mapping are written as 500s with fallback as the message,
+// so internal details never reach the client.
+func WriteServiceError(w http.ResponseWriter, r *http.Request, err error, fallback string) {
+	m, ok := lookupErrorMapping(err)
+	if !ok {
+		WriteInternalError(w, r, fallback)
+		return
+	}
+	message := m.message
+	if message == "" {
+		message = err.Error()
+	}
+	retryAfter := m.retryAfter
+	var circuitErr *openai.CircuitOpenError
+	if errors.As(err, &circuitErr) {
+		// Retry once the breaker lets a probe through, not before
+		retryAfter = max(int(math.Ceil(circuitErr.RetryAfter.Seconds())), 1)
+	}
+	WriteErrorWithRetry(w, r, m.status, m.code, message, retryAfter)
+}
+
 // IsClientError returns true if the error code indicates a client error.
 func IsClientError(code string) bool {
 	return len(code) >= 7 && code[:7] == "CLIENT_"
@@ -3,11 +3,20 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 	"testing/quick"
+	"time"
+
+	"better-kiro-prompts/internal/gallery"
+	"better-kiro-prompts/internal/generation"
+	"better-kiro-prompts/internal/openai"
+	"better-kiro-prompts/internal/scanner"
+	"better-kiro-prompts/internal/storage"
 )
 
 // Property 14: Structured Error Responses
@@ -82,6 +91,8 @@ func TestClientErrorCodes_Property(t *testing.T) {
 		ErrCodeNotFound,
 		ErrCodeBadRequest,
 		ErrCodeUnauthorized,
+		ErrCodeTooLarge,
+		ErrCodeConflict,
 	}
 
 	for _, code := range clientErrorCodes {
@@ -161,6 +172,121 @@ func TestWriteRateLimited_Property(t *testing.T) {
 	}
 }
 
+// TestWriteServiceError_SentinelMapping tests that each service sentinel maps
+// to its documented status and code, even when wrapped.
+func TestWriteServiceError_SentinelMapping(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{gallery.ErrNotFound, http.StatusNotFound, ErrCodeNotFound},
+		{gallery.ErrInvalidInput, http.StatusBadRequest, ErrCodeValidation},
+		{gallery.ErrInvalidSort, http.StatusBadRequest, ErrCodeValidation},
+		{gallery.ErrInvalidRating, http.StatusBadRequest, ErrCodeValidation},
+		{gallery.ErrInvalidPage, http.StatusBadRequest, ErrCodeValidation},
+		{gallery.ErrInvalidImport, http.StatusBadRequest, ErrCodeValidation},
+		{gallery.ErrRateLimited, http.StatusTooManyRequests, ErrCodeRateLimited},
+		{generation.ErrEmptyProjectIdea, http.StatusBadRequest, ErrCodeValidation},
+		{generation.ErrProjectIdeaTooShort, http.StatusBadRequest, ErrCodeValidation},
+		{generation.ErrProjectIdeaTooLong, http.StatusBadRequest, ErrCodeValidation},
+		{generation.ErrAnswerTooLong, http.StatusBadRequest, ErrCodeValidation},
+		{generation.ErrInvalidQuestionID, http.StatusBadRequest, ErrCodeValidation},
+		{generation.ErrDuplicateAnswer, http.StatusBadRequest, ErrCodeValidation},
+		{generation.ErrTooManyAnswers, http.StatusBadRequest, ErrCodeValidation},
+		{generation.ErrEmptyQuestion, http.StatusBadRequest, ErrCodeValidation},
+		{generation.ErrQuestionTooLong, http.StatusBadRequest, ErrCodeValidation},
+		{generation.ErrPromptTooLarge, http.StatusBadRequest, ErrCodeValidation},
+		{generation.ErrGenerationNotFound, http.StatusNotFound, ErrCodeNotFound},
+		{generation.ErrNoStoredInputs, http.StatusUnprocessableEntity, ErrCodeValidation},
+		{generation.ErrIdempotencyKeyReused, http.StatusUnprocessableEntity, ErrCodeValidation},
+		{generation.ErrAIUnavailable, http.StatusServiceUnavailable, ErrCodeUnavailable},
+		{openai.ErrCircuitOpen, http.StatusServiceUnavailable, ErrCodeUnavailable},
+		{generation.ErrInvalidResponse, http.StatusInternalServerError, ErrCodeInternal},
+		{generation.ErrNoQuestions, http.StatusInternalServerError, ErrCodeInternal},
+		{generation.ErrNoFiles, http.StatusInternalServerError, ErrCodeInternal},
+		{scanner.ErrJobNotFound, http.StatusNotFound, ErrCodeNotFound},
+		{scanner.ErrFindingNotFound, http.StatusNotFound, ErrCodeNotFound},
+		{scanner.ErrBatchNotFound, http.StatusNotFound, ErrCodeNotFound},
+		{scanner.ErrScanNotComplete, http.StatusConflict, ErrCodeConflict},
+		{scanner.ErrReviewUnavailable, http.StatusServiceUnavailable, ErrCodeUnavailable},
+		{scanner.ErrShuttingDown, http.StatusServiceUnavailable, ErrCodeUnavailable},
+		{scanner.ErrRepoNotFound, http.StatusNotFound, ErrCodeNotFound},
+		{scanner.ErrRepoTooLarge, http.StatusBadRequest, ErrCodeValidation},
+		{scanner.ErrSubdirNotFound, http.StatusBadRequest, ErrCodeValidation},
+		{scanner.ErrCloneTimeout, http.StatusGatewayTimeout, ErrCodeTimeout},
+		{scanner.ErrScanFailed, http.StatusInternalServerError, ErrCodeInternal},
+		{fmt.Errorf("%w: canceling statement", storage.ErrQueryTimeout), http.StatusServiceUnavailable, ErrCodeUnavailable},
+		{context.DeadlineExceeded, http.StatusGatewayTimeout, ErrCodeTimeout},
+		{errors.New("database is down"), http.StatusInternalServerError, ErrCodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			req = req.WithContext(setRequestID(req.Context(), "test-id"))
+			w := httptest.NewRecorder()
+
+			WriteServiceError(w, req, fmt.Errorf("%w: detail", tt.err), "fallback")
+
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d", w.Code, tt.status)
+			}
+			var resp ErrorResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if resp.Code != tt.code {
+				t.Errorf("code = %q, want %q", resp.Code, tt.code)
+			}
+			if resp.Error == "" || resp.RequestID != "test-id" {
+				t.Errorf("expected message and request ID, got %+v", resp)
+			}
+		})
+	}
+}
+
+func TestWriteServiceError_HidesUnmappedDetails(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	w := httptest.NewRecorder()
+
+	WriteServiceError(w, req, errors.New("pq: connection refused"), "Failed to load")
+
+	var resp ErrorResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Error != "Failed to load" {
+		t.Errorf("error = %q, want the fallback message", resp.Error)
+	}
+}
+
+func TestWriteServiceError_ShuttingDownRetryAfter(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/scan", nil)
+	w := httptest.NewRecorder()
+
+	WriteServiceError(w, req, scanner.ErrShuttingDown, "")
+
+	if got := w.Header().Get("Retry-After"); got != "30" {
+		t.Errorf("Retry-After = %q, want 30", got)
+	}
+}
+
+func TestWriteServiceError_CircuitOpenRetryAfter(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/generate/outputs", nil)
+	w := httptest.NewRecorder()
+
+	err := fmt.Errorf("generate: %w", &openai.CircuitOpenError{RetryAfter: 90*time.Second + 200*time.Millisecond})
+	WriteServiceError(w, req, err, "")
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "91" {
+		t.Errorf("Retry-After = %q, want 91, the rest of the breaker cooldown", got)
+	}
+}
+
 // TestErrorHelpers_Property tests that error helper functions produce correct status codes.
 // Property: Each error helper function SHALL produce the correct HTTP status code.
 func TestErrorHelpers_Property(t *testing.T) {
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"better-kiro-prompts/internal/gallery"
+	"better-kiro-prompts/internal/storage"
+)
+
+// etagRepository serves a single generation and counts recorded views.
+type etagRepository struct {
+	storage.Repository
+	gen   storage.Generation
+	views int
+}
+
+func (r *etagRepository) GetGeneration(_ context.Context, id string) (*storage.Generation, error) {
+	if id != r.gen.ID {
+		return nil, storage.ErrNotFound
+	}
+	gen := r.gen
+	return &gen, nil
+}
+
+func (r *etagRepository) ListGenerations(_ context.Context, _ storage.ListFilter) ([]storage.Generation, int, error) {
+	return []storage.Generation{r.gen}, 1, nil
+}
+
+func (r *etagRepository) RecordView(_ context.Context, _ string, _ string) (bool, error) {
+	r.views++
+	return true, nil
+}
+
+func (r *etagRepository) GetUserRating(_ context.Context, _ string, _ string) (int, error) {
+	return 0, nil
+}
+
+func newETagTestMux(repo *etagRepository) *http.ServeMux {
+	h := NewGalleryHandler(gallery.NewService(repo, nil, nil), nil)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/gallery", h.HandleListGallery)
+	mux.HandleFunc("GET /api/gallery/{id}", h.HandleGetGalleryItem)
+	mux.HandleFunc("POST /api/gallery/{id}/view", h.HandleRecordView)
+	return mux
+}
+
+func TestGalleryEndpoints_NotModifiedOnMatchingETag(t *testing.T) {
+	repo := &etagRepository{gen: storage.Generation{
+		ID:              "gen-1",
+		ProjectIdea:     "A todo app",
+		ExperienceLevel: "novice",
+		HookPreset:      "default",
+		Files:           json.RawMessage(`[]`),
+		CategoryID:      1,
+		CreatedAt:       time.Date(2026, 1, 14, 10, 30, 0, 0, time.UTC),
+	}}
+	mux := newETagTestMux(repo)
+
+	for _, path := range []string{"/api/gallery", "/api/gallery/gen-1"} {
+		t.Run(path, func(t *testing.T) {
+			first := httptest.NewRecorder()
+			mux.ServeHTTP(first, httptest.NewRequest(http.MethodGet, path, nil))
+			if first.Code != http.StatusOK {
+				t.Fatalf("first request: status = %d, want 200", first.Code)
+			}
+			etag := first.Header().Get("ETag")
+			if etag == "" {
+				t.Fatal("expected an ETag header")
+			}
+
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			req.Header.Set("If-None-Match", etag)
+			second := httptest.NewRecorder()
+			mux.ServeHTTP(second, req)
+			if second.Code != http.StatusNotModified {
+				t.Fatalf("matching ETag: status = %d, want 304", second.Code)
+			}
+			if second.Body.Len() != 0 {
+				t.Errorf("304 response should have no body, got %q", second.Body.String())
+			}
+			if second.Header().Get("ETag") != etag {
+				t.Errorf("304 ETag = %q, want %q", second.Header().Get("ETag"), etag)
+			}
+
+			req = httptest.NewRequest(http.MethodGet, path, nil)
+			req.Header.Set("If-None-Match", `"stale"`)
+			third := httptest.NewRecorder()
+			mux.ServeHTTP(third, req)
+			if third.Code != http.StatusOK {
+				t.Errorf("stale ETag: status = %d, want 200", third.Code)
+			}
+		})
+	}
+
+	// Fetching never registers a view; only the beacon does
+	if repo.views != 0 {
+		t.Errorf("GET recorded %d views, want 0", repo.views)
+	}
+
+	beacon := httptest.NewRecorder()
+	mux.ServeHTTP(beacon, httptest.NewRequest(http.MethodPost, "/api/gallery/gen-1/view", nil))
+	if beacon.Code != http.StatusOK {
+		t.Fatalf("view beacon: status = %d, want 200", beacon.Code)
+	}
+	if repo.views != 1 {
+		t.Errorf("views recorded = %d, want 1", repo.views)
+	}
+
+	missing := httptest.NewRecorder()
+	mux.ServeHTTP(missing, httptest.NewRequest(http.MethodPost, "/api/gallery/nope/view", nil))
+	if missing.Code != http.StatusNotFound {
+		t.Errorf("view beacon for unknown ID: status = %d, want 404", missing.Code)
+	}
+}
+
+func TestETagMatches(t *testing.T) {
+	etag := computeETag([]byte(`{"items":[]}`))
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{"", false},
+		{etag, true},
+		{"W/" + etag, true},
+		{`"other", ` + etag, true},
+		{"*", true},
+		{`"other"`, false},
+	}
+	for _, tt := range tests {
+		if got := etagMatches(tt.header, etag); got != tt.want {
+			t.Errorf("etagMatches(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
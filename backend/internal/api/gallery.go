@@ -2,21 +2,27 @@
 package api
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
+	"better-kiro-prompts/internal/download"
 	"better-kiro-prompts/internal/gallery"
+	"better-kiro-prompts/internal/privacy"
 	"better-kiro-prompts/internal/ratelimit"
+	"better-kiro-prompts/internal/storage"
 )
 
 // GalleryHandler holds dependencies for gallery endpoints.
 type GalleryHandler struct {
 	service       *gallery.Service
 	ratingLimiter *ratelimit.Limiter
+	downloads     *download.Signer // nil disables download links
+	downloadTTL   time.Duration
 }
 
 // NewGalleryHandler creates a new handler with the given dependencies.
@@ -46,6 +52,8 @@ type GalleryItem struct {
 	ViewCount   int     `json:"viewCount"`
 	CreatedAt   string  `json:"createdAt"`
 	Preview     string  `json:"preview"`
+	// CreatedAtRelative is set with ?relative=true, e.g. "3 hours ago"
+	CreatedAtRelative string `json:"createdAtRelative,omitempty"`
 }
 
 // GalleryDetailResponse is the response for a single gallery item.
@@ -66,6 +74,21 @@ type GalleryDetail struct {
 	RatingCount     int             `json:"ratingCount"`
 	ViewCount       int             `json:"viewCount"`
 	CreatedAt       string          `json:"createdAt"`
+	ShortCode       string          `json:"shortCode,omitempty"` // Share link code for /api/gallery/s/{code}
+	// CreatedAtRelative is set with ?relative=true, e.g. "3 hours ago"
+	CreatedAtRelative string `json:"createdAtRelative,omitempty"`
+}
+
+// GalleryCategoriesResponse is the response for listing categories with counts.
+type GalleryCategoriesResponse struct {
+	Categories []GalleryCategory `json:"categories"`
+}
+
+// GalleryCategory is a category with the number of listed generations in it.
+type GalleryCategory struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Count int    `json:"count"`
 }
 
 // RateRequest is the request body for rating a generation.
@@ -74,6 +97,11 @@ type RateRequest struct {
 	VoterHash string `json:"voterHash"`
 }
 
+// ViewResponse is the response for recording a view.
+type ViewResponse struct {
+	Counted bool `json:"counted"` // false when this IP already viewed the generation
+}
+
 // RateResponse is the response for rating a generation.
 type RateResponse struct {
 	Success bool `json:"success"`
@@ -112,14 +140,9 @@ func (h *GalleryHandler) HandleListGallery(w http.ResponseWriter, r *http.Reques
 		page = p
 	}
 
-	pageSize := 0 // Let service use its configured default
-	if sizeStr := query.Get("pageSize"); sizeStr != "" {
-		s, err := strconv.Atoi(sizeStr)
-		if err != nil || s < 1 {
-			WriteValidationError(w, r, "Invalid page size")
-			return
-		}
-		pageSize = s
+	pageSize, ok := parsePageSize(w, r) // Zero lets the service use its configured default
+	if !ok {
+		return
 	}
 
 	// Call service
@@ -130,15 +153,12 @@ func (h *GalleryHandler) HandleListGallery(w http.ResponseWriter, r *http.Reques
 		PageSize:   pageSize,
 	})
 	if err != nil {
-		if errors.Is(err, gallery.ErrInvalidSort) {
-			WriteValidationError(w, r, "Invalid sort option")
-			return
-		}
-		WriteInternalError(w, r, "")
+		WriteServiceError(w, r, err, "")
 		return
 	}
 
 	// Convert to response format
+	now := time.Now()
 	items := make([]GalleryItem, len(resp.Items))
 	for i, gen := range resp.Items {
 		items[i] = GalleryItem{
@@ -148,12 +168,15 @@ func (h *GalleryHandler) HandleListGallery(w http.ResponseWriter, r *http.Reques
 			AvgRating:   gen.AvgRating,
 			RatingCount: gen.RatingCount,
 			ViewCount:   gen.ViewCount,
-			CreatedAt:   gen.CreatedAt.Format("2006-01-02T15:04:05Z"),
+			CreatedAt:   formatTimestamp(gen.CreatedAt),
 			Preview:     truncateString(gen.ProjectIdea, 200),
+
+			CreatedAtRelative: relativeTimestamp(r, gen.CreatedAt, now),
 		}
 	}
 
-	writeJSON(w, http.StatusOK, GalleryListResponse{
+	setPaginationHeaders(w, r, resp.Page, resp.PageSize, resp.Total, resp.TotalPages)
+	writeJSONWithETag(w, r, GalleryListResponse{
 		Items:      items,
 		Total:      resp.Total,
 		Page:       resp.Page,
@@ -162,6 +185,26 @@ func (h *GalleryHandler) HandleListGallery(w http.ResponseWriter, r *http.Reques
 	})
 }
 
+// HandleListCategories handles GET /api/gallery/categories.
+func (h *GalleryHandler) HandleListCategories(w http.ResponseWriter, r *http.Request) {
+	counts, err := h.service.GetCategoriesWithCounts(r.Context())
+	if err != nil {
+		WriteInternalError(w, r, "")
+		return
+	}
+
+	categories := make([]GalleryCategory, len(counts))
+	for i, c := range counts {
+		categories[i] = GalleryCategory{
+			ID:    c.ID,
+			Name:  c.Name,
+			Count: c.Count,
+		}
+	}
+
+	writeJSON(w, http.StatusOK, GalleryCategoriesResponse{Categories: categories})
+}
+
 // HandleGetGalleryItem handles GET /api/gallery/{id}.
 func (h *GalleryHandler) HandleGetGalleryItem(w http.ResponseWriter, r *http.Request) {
 	// Extract ID from path using Go 1.22+ PathValue
@@ -171,29 +214,36 @@ func (h *GalleryHandler) HandleGetGalleryItem(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	// Hash the client IP for view tracking and rating lookup
-	clientIP := getClientIP(r)
-	ipHash := hashIP(clientIP)
+	// Read-only; views are registered through the view beacon
+	gen, err := h.service.GetGeneration(r.Context(), id)
+	if err != nil {
+		WriteServiceError(w, r, err, "")
+		return
+	}
 
-	// Get generation with IP-deduplicated view tracking
-	gen, err := h.service.GetGenerationWithView(r.Context(), id, ipHash)
+	h.writeGalleryDetail(w, r, gen)
+}
+
+// HandleResolveShortCode handles GET /api/gallery/s/{code}.
+// It returns the same body as GET /api/gallery/{id} for the generation the
+// short code belongs to; the response's id is used for views and ratings.
+func (h *GalleryHandler) HandleResolveShortCode(w http.ResponseWriter, r *http.Request) {
+	gen, err := h.service.GetGenerationByShortCode(r.Context(), r.PathValue("code"))
 	if err != nil {
-		if errors.Is(err, gallery.ErrNotFound) {
-			WriteNotFound(w, r, "Generation not found")
-			return
-		}
-		if errors.Is(err, gallery.ErrInvalidInput) {
-			WriteValidationError(w, r, "Invalid generation ID")
-			return
-		}
-		WriteInternalError(w, r, "")
+		WriteServiceError(w, r, err, "")
 		return
 	}
 
+	h.writeGalleryDetail(w, r, gen)
+}
+
+// writeGalleryDetail writes a generation with the caller's rating of it.
+func (h *GalleryHandler) writeGalleryDetail(w http.ResponseWriter, r *http.Request, gen *storage.Generation) {
 	// Get user rating using IP hash (Requirements 5.2, 5.4)
-	userRating, _ := h.service.GetUserRating(r.Context(), id, ipHash)
+	clientIP := getClientIP(r)
+	userRating, _ := h.service.GetUserRating(r.Context(), gen.ID, privacy.HashIP(clientIP), privacy.PreviousHashes(clientIP)...)
 
-	writeJSON(w, http.StatusOK, GalleryDetailResponse{
+	writeJSONWithETag(w, r, GalleryDetailResponse{
 		Generation: GalleryDetail{
 			ID:              gen.ID,
 			ProjectIdea:     gen.ProjectIdea,
@@ -204,12 +254,35 @@ func (h *GalleryHandler) HandleGetGalleryItem(w http.ResponseWriter, r *http.Req
 			AvgRating:       gen.AvgRating,
 			RatingCount:     gen.RatingCount,
 			ViewCount:       gen.ViewCount,
-			CreatedAt:       gen.CreatedAt.Format("2006-01-02T15:04:05Z"),
+			CreatedAt:       formatTimestamp(gen.CreatedAt),
+			ShortCode:       gen.ShortCode,
+
+			CreatedAtRelative: relativeTimestamp(r, gen.CreatedAt, time.Now()),
 		},
 		UserRating: userRating,
 	})
 }
 
+// HandleRecordView handles POST /api/gallery/{id}/view.
+// The frontend calls it once per page view; repeat views from the same IP
+// hash are accepted but not counted again.
+func (h *GalleryHandler) HandleRecordView(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if id == "" {
+		WriteValidationError(w, r, "Invalid generation ID")
+		return
+	}
+
+	clientIP := getClientIP(r)
+	counted, err := h.service.RecordView(r.Context(), id, privacy.HashIP(clientIP), privacy.PreviousHashes(clientIP)...)
+	if err != nil {
+		WriteServiceError(w, r, err, "")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ViewResponse{Counted: counted})
+}
+
 // HandleRateGalleryItem handles POST /api/gallery/{id}/rate.
 // Uses IP hash for vote deduplication per Requirements 5.2, 5.4, 5.5.
 func (h *GalleryHandler) HandleRateGalleryItem(w http.ResponseWriter, r *http.Request) {
@@ -223,7 +296,7 @@ func (h *GalleryHandler) HandleRateGalleryItem(w http.ResponseWriter, r *http.Re
 	// Parse request body
 	var req RateRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		WriteBadRequest(w, r, "Invalid request body")
+		writeDecodeError(w, r, err)
 		return
 	}
 
@@ -234,45 +307,74 @@ func (h *GalleryHandler) HandleRateGalleryItem(w http.ResponseWriter, r *http.Re
 	}
 
 	// Check rating rate limit
-	ip := getClientIP(r)
-	if h.ratingLimiter != nil {
-		allowed, retryAfter := h.ratingLimiter.Allow(ip)
-		if !allowed {
-			WriteRateLimited(w, r, int(retryAfter.Seconds()))
-			return
-		}
+	if h.ratingLimiter != nil && !checkRateLimit(w, r, h.ratingLimiter) {
+		return
 	}
+	ip := getClientIP(r)
 
 	// Use IP hash for voter identification (Requirements 5.2, 5.4, 5.5)
 	// This ensures one vote per IP address per generation
-	ipHash := hashIP(ip)
+	ipHash := privacy.HashIP(ip)
 
 	// Submit rating using IP hash for deduplication
-	retryAfter, err := h.service.RateGeneration(r.Context(), id, req.Score, ipHash, ip)
+	retryAfter, err := h.service.RateGeneration(r.Context(), id, req.Score, ipHash, ip, privacy.PreviousHashes(ip)...)
 	if err != nil {
-		if errors.Is(err, gallery.ErrNotFound) {
-			WriteNotFound(w, r, "Generation not found")
-			return
-		}
-		if errors.Is(err, gallery.ErrInvalidRating) {
-			WriteValidationError(w, r, "Score must be between 1 and 5")
-			return
-		}
-		if errors.Is(err, gallery.ErrInvalidInput) {
-			WriteValidationError(w, r, "Invalid input")
-			return
-		}
+		// The service reports how long until the voter may rate again
 		if errors.Is(err, gallery.ErrRateLimited) {
 			WriteRateLimited(w, r, retryAfter)
 			return
 		}
-		WriteInternalError(w, r, "")
+		WriteServiceError(w, r, err, "")
 		return
 	}
 
 	writeJSON(w, http.StatusOK, RateResponse{Success: true})
 }
 
+// setPaginationHeaders mirrors the list's pagination in response headers, with
+// a Link header pointing at the neighbouring pages under the same filters.
+func setPaginationHeaders(w http.ResponseWriter, r *http.Request, page, pageSize, total, totalPages int) {
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+	w.Header().Set("X-Page", strconv.Itoa(page))
+	w.Header().Set("X-Page-Size", strconv.Itoa(pageSize))
+
+	var links []string
+	if page > 1 {
+		links = append(links, `<`+pageURL(r, page-1)+`>; rel="prev"`)
+	}
+	if page < totalPages {
+		links = append(links, `<`+pageURL(r, page+1)+`>; rel="next"`)
+	}
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+}
+
+// parsePageSize reads the optional ?pageSize= query parameter shared by list
+// endpoints. It returns zero when the parameter is absent, leaving the default
+// to the list; larger values are capped by pagination.NormalizePageSize. It
+// writes a validation error and returns false for a malformed value.
+func parsePageSize(w http.ResponseWriter, r *http.Request) (int, bool) {
+	sizeStr := r.URL.Query().Get("pageSize")
+	if sizeStr == "" {
+		return 0, true
+	}
+	size, err := strconv.Atoi(sizeStr)
+	if err != nil || size < 1 {
+		WriteValidationError(w, r, "Invalid page size")
+		return 0, false
+	}
+	return size, true
+}
+
+// pageURL returns the request's path and query with the page replaced.
+func pageURL(r *http.Request, page int) string {
+	query := r.URL.Query()
+	query.Set("page", strconv.Itoa(page))
+	u := url.URL{Path: r.URL.Path, RawQuery: query.Encode()}
+	return u.String()
+}
+
 // truncateString truncates a string to the given length, adding "..." if truncated.
 func truncateString(s string, maxLen int) string {
 	if len(s) <= maxLen {
@@ -283,10 +385,3 @@ func truncateString(s string, maxLen int) string {
 	}
 	return s[:maxLen-3] + "..."
 }
-
-// hashIP creates a SHA-256 hash of an IP address for privacy-preserving storage.
-// The hash is returned as a lowercase hex string.
-func hashIP(ip string) string {
-	hash := sha256.Sum256([]byte(ip))
-	return hex.EncodeToString(hash[:])
-}
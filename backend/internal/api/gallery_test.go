@@ -1,10 +1,20 @@
 package api
 
 import (
+	"context"
+	"encoding/json"
 	"math/rand"
+	"net/http"
+	"net/http/httptest"
 	"regexp"
+	"strconv"
+	"strings"
 	"testing"
 	"testing/quick"
+
+	"better-kiro-prompts/internal/gallery"
+	"better-kiro-prompts/internal/privacy"
+	"better-kiro-prompts/internal/storage"
 )
 
 // Feature: ux-improvements, Property 6: IP Addresses Are Hashed
@@ -26,7 +36,7 @@ func TestProperty6_IPAddressesAreHashed(t *testing.T) {
 		ip := generateRandomIP(r)
 
 		// Hash the IP
-		hash := hashIP(ip)
+		hash := privacy.HashIP(ip)
 
 		// Property 1: Hash should be exactly 64 hex characters (SHA-256 = 256 bits = 64 hex chars)
 		if len(hash) != 64 {
@@ -47,7 +57,7 @@ func TestProperty6_IPAddressesAreHashed(t *testing.T) {
 		}
 
 		// Property 4: Same IP should produce same hash (deterministic)
-		hash2 := hashIP(ip)
+		hash2 := privacy.HashIP(ip)
 		if hash != hash2 {
 			t.Logf("Same IP should produce same hash: %s vs %s", hash, hash2)
 			return false
@@ -79,8 +89,8 @@ func TestProperty6_DifferentIPsProduceDifferentHashes(t *testing.T) {
 			return true
 		}
 
-		hash1 := hashIP(ip1)
-		hash2 := hashIP(ip2)
+		hash1 := privacy.HashIP(ip1)
+		hash2 := privacy.HashIP(ip2)
 
 		// Different IPs should produce different hashes
 		if hash1 == hash2 {
@@ -109,7 +119,7 @@ func TestProperty6_IPv6AddressesAreHashed(t *testing.T) {
 		ip := generateRandomIPv6(r)
 
 		// Hash the IP
-		hash := hashIP(ip)
+		hash := privacy.HashIP(ip)
 
 		// Property 1: Hash should be exactly 64 hex characters
 		if len(hash) != 64 {
@@ -186,3 +196,122 @@ func containsIP(hash, ip string) bool {
 	}
 	return false
 }
+
+// pagedRepository reports a fixed total and returns one page of generations.
+type pagedRepository struct {
+	storage.Repository
+	total int
+}
+
+func (r *pagedRepository) ListGenerations(_ context.Context, filter storage.ListFilter) ([]storage.Generation, int, error) {
+	var gens []storage.Generation
+	for i := (filter.Page - 1) * filter.PageSize; i < r.total && len(gens) < filter.PageSize; i++ {
+		gens = append(gens, storage.Generation{ID: "gen-" + strconv.Itoa(i), ProjectIdea: "idea", Files: json.RawMessage(`[]`)})
+	}
+	return gens, r.total, nil
+}
+
+func TestHandleListGallery_PaginationHeaders(t *testing.T) {
+	h := NewGalleryHandler(gallery.NewService(&pagedRepository{total: 25}, nil, nil), nil)
+
+	tests := []struct {
+		name     string
+		query    string
+		wantLink []string
+		noLink   []string
+	}{
+		{"first page", "page=1&pageSize=10&sort=highest_rated", []string{`page=2&pageSize=10&sort=highest_rated>; rel="next"`}, []string{`rel="prev"`}},
+		{"middle page", "page=2&pageSize=10", []string{`page=1&pageSize=10>; rel="prev"`, `page=3&pageSize=10>; rel="next"`}, nil},
+		{"last page", "page=3&pageSize=10", []string{`page=2&pageSize=10>; rel="prev"`}, []string{`rel="next"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/gallery?"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			h.HandleListGallery(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200; body: %s", rec.Code, rec.Body.String())
+			}
+			var body GalleryListResponse
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+
+			headers := map[string]int{
+				"X-Total-Count": body.Total,
+				"X-Page":        body.Page,
+				"X-Page-Size":   body.PageSize,
+			}
+			for name, want := range headers {
+				if got := rec.Header().Get(name); got != strconv.Itoa(want) {
+					t.Errorf("%s = %q, want %d", name, got, want)
+				}
+			}
+
+			link := rec.Header().Get("Link")
+			for _, want := range tt.wantLink {
+				if !strings.Contains(link, "</api/gallery?"+want) {
+					t.Errorf("Link = %q, want it to contain %q", link, want)
+				}
+			}
+			for _, unwanted := range tt.noLink {
+				if strings.Contains(link, unwanted) {
+					t.Errorf("Link = %q, should not contain %q", link, unwanted)
+				}
+			}
+		})
+	}
+}
+
+// shortCodeRepository resolves short codes to a fixed set of generations.
+type shortCodeRepository struct {
+	storage.Repository
+	byCode map[string]storage.Generation
+}
+
+func (r *shortCodeRepository) GetGenerationByShortCode(_ context.Context, code string) (*storage.Generation, error) {
+	gen, ok := r.byCode[code]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	return &gen, nil
+}
+
+func (r *shortCodeRepository) GetUserRating(_ context.Context, _ string, _ string) (int, error) {
+	return 0, nil
+}
+
+func TestHandleResolveShortCode(t *testing.T) {
+	repo := &shortCodeRepository{byCode: map[string]storage.Generation{
+		"Ab3dE6gH": {ID: "gen-1", ProjectIdea: "A todo app", ShortCode: "Ab3dE6gH", Files: json.RawMessage(`[]`)},
+		"zY9xW8vU": {ID: "gen-2", ProjectIdea: "A chat bot", ShortCode: "zY9xW8vU", Files: json.RawMessage(`[]`)},
+	}}
+	router := NewRouter(&RouterConfig{GalleryService: gallery.NewService(repo, nil, nil)})
+
+	for code, want := range repo.byCode {
+		req := httptest.NewRequest(http.MethodGet, "/api/gallery/s/"+code, nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET /api/gallery/s/%s status = %d, want 200; body: %s", code, rec.Code, rec.Body.String())
+		}
+		var body GalleryDetailResponse
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if body.Generation.ID != want.ID || body.Generation.ShortCode != code {
+			t.Errorf("resolved %s to %s (%s), want %s", code, body.Generation.ID, body.Generation.ShortCode, want.ID)
+		}
+	}
+
+	for _, code := range []string{"Qq1Qq1Qq", "bad"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/gallery/s/"+code, nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("GET /api/gallery/s/%s status = %d, want 404", code, rec.Code)
+		}
+	}
+}
@@ -2,46 +2,49 @@ package api
 
 import (
 	"better-kiro-prompts/internal/generation"
+	"better-kiro-prompts/internal/pow"
+	"better-kiro-prompts/internal/privacy"
+	"better-kiro-prompts/internal/prompts"
 	"better-kiro-prompts/internal/ratelimit"
+	"better-kiro-prompts/internal/storage"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // ExperienceLevel represents the user's programming experience level.
 type ExperienceLevel string
 
 const (
-	ExperienceLevelBeginner ExperienceLevel = "beginner"
-	ExperienceLevelNovice   ExperienceLevel = "novice"
-	ExperienceLevelExpert   ExperienceLevel = "expert"
+	ExperienceLevelBeginner ExperienceLevel = prompts.ExperienceBeginner
+	ExperienceLevelNovice   ExperienceLevel = prompts.ExperienceNovice
+	ExperienceLevelExpert   ExperienceLevel = prompts.ExperienceExpert
+	// ExperienceLevelAuto infers the level from the project idea; it is resolved before generation.
+	ExperienceLevelAuto ExperienceLevel = prompts.ExperienceAuto
 )
 
-// ValidExperienceLevels contains all valid experience level values.
-var ValidExperienceLevels = map[ExperienceLevel]bool{
-	ExperienceLevelBeginner: true,
-	ExperienceLevelNovice:   true,
-	ExperienceLevelExpert:   true,
-}
+// ValidExperienceLevels contains all valid experience level values, from
+// prompts.RequestExperienceLevels.
+var ValidExperienceLevels = optionSet[ExperienceLevel](prompts.RequestExperienceLevels())
 
 // HookPreset represents the hook configuration preset.
 type HookPreset string
 
 const (
-	HookPresetLight   HookPreset = "light"
-	HookPresetBasic   HookPreset = "basic"
-	HookPresetDefault HookPreset = "default"
-	HookPresetStrict  HookPreset = "strict"
+	HookPresetLight   HookPreset = prompts.HookPresetLight
+	HookPresetBasic   HookPreset = prompts.HookPresetBasic
+	HookPresetDefault HookPreset = prompts.HookPresetDefault
+	HookPresetStrict  HookPreset = prompts.HookPresetStrict
 )
 
-// ValidHookPresets contains all valid hook preset values.
-var ValidHookPresets = map[HookPreset]bool{
-	HookPresetLight:   true,
-	HookPresetBasic:   true,
-	HookPresetDefault: true,
-	HookPresetStrict:  true,
-}
+// ValidHookPresets contains all valid hook preset values, from
+// prompts.ValidHookPresets.
+var ValidHookPresets = optionSet[HookPreset](prompts.ValidHookPresets())
 
 // GenerateQuestionsRequest is the request body for generating questions.
 type GenerateQuestionsRequest struct {
@@ -51,7 +54,9 @@ type GenerateQuestionsRequest struct {
 
 // GenerateQuestionsResponse is the response body for generated questions.
 type GenerateQuestionsResponse struct {
-	Questions []generation.Question `json:"questions"`
+	Questions       []generation.Question `json:"questions"`
+	ExperienceLevel string                `json:"experienceLevel"` // Resolved level, never "auto"
+	Complexity      string                `json:"complexity"`      // small, medium or large; a heuristic estimate
 }
 
 // GenerateOutputsRequest is the request body for generating outputs.
@@ -60,20 +65,133 @@ type GenerateOutputsRequest struct {
 	Answers         []generation.Answer `json:"answers"`
 	ExperienceLevel ExperienceLevel     `json:"experienceLevel"`
 	HookPreset      HookPreset          `json:"hookPreset"`
+	Visibility      string              `json:"visibility,omitempty"` // "public" (default) or "unlisted"
 }
 
 // GenerateOutputsResponse is the response body for generated outputs.
 type GenerateOutputsResponse struct {
-	Files        []generation.GeneratedFile `json:"files"`
-	GenerationID string                     `json:"generationId,omitempty"`
+	Files           []generation.GeneratedFile     `json:"files"`
+	GenerationID    string                         `json:"generationId,omitempty"`
+	ShortCode       string                         `json:"shortCode,omitempty"` // Share link code for /api/gallery/s/{code}
+	Warnings        []generation.ValidationWarning `json:"warnings,omitempty"`
+	ExperienceLevel string                         `json:"experienceLevel"`     // Resolved level, never "auto"
+	Attempts        int                            `json:"attempts"`            // Model calls needed for a valid response
+	Duplicate       bool                           `json:"duplicate,omitempty"` // GenerationID links to an identical earlier generation
+}
+
+// GenerateKickoffRequest is the request body for generating only the kickoff prompt.
+type GenerateKickoffRequest struct {
+	ProjectIdea     string              `json:"projectIdea"`
+	Answers         []generation.Answer `json:"answers"`
+	ExperienceLevel ExperienceLevel     `json:"experienceLevel"`
+}
+
+// GenerateKickoffResponse is the response body for a generated kickoff prompt.
+type GenerateKickoffResponse struct {
+	File            generation.GeneratedFile `json:"file"`
+	ExperienceLevel string                   `json:"experienceLevel"` // Resolved level, never "auto"
+}
+
+// AssessAnswersRequest is the request body for checking answer quality.
+type AssessAnswersRequest struct {
+	Questions []generation.Question `json:"questions"`
+	Answers   []generation.Answer   `json:"answers"`
+}
+
+// AssessAnswersResponse is the response body for answer quality hints.
+type AssessAnswersResponse struct {
+	Hints []generation.AnswerHint `json:"hints"`
 }
 
+// GenerateExamplesRequest is the request body for regenerating a question's example answers.
+type GenerateExamplesRequest struct {
+	Question        string          `json:"question"`
+	ProjectIdea     string          `json:"projectIdea"`
+	ExperienceLevel ExperienceLevel `json:"experienceLevel"`
+}
+
+// GenerateExamplesResponse is the response body for regenerated example answers.
+type GenerateExamplesResponse struct {
+	Examples []string `json:"examples"`
+}
+
+// ReplayGenerationResponse is the response body for replaying a stored generation.
+type ReplayGenerationResponse struct {
+	GenerationID string                         `json:"generationId"`
+	Files        []generation.GeneratedFile     `json:"files"`
+	Warnings     []generation.ValidationWarning `json:"warnings,omitempty"`
+	Attempts     int                            `json:"attempts"`
+}
+
+// GenerateOptionsResponse lists the accepted experience levels and hook presets.
+type GenerateOptionsResponse struct {
+	ExperienceLevels []GenerateOption   `json:"experienceLevels"`
+	HookPresets      []HookPresetOption `json:"hookPresets"`
+}
+
+// GenerateOption is an accepted value with its display text.
+type GenerateOption struct {
+	Value       string `json:"value"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// HookPresetOption is a hook preset with the hooks it generates.
+type HookPresetOption struct {
+	GenerateOption
+	Hooks []string `json:"hooks"`
+}
+
+// GenerationHistoryResponse is the response body for the caller's generation history.
+type GenerationHistoryResponse struct {
+	Items []GenerationHistoryItem `json:"items"`
+}
+
+// GenerationHistoryItem is a generation the caller created. Open it with
+// /api/gallery/{id}, which also serves unlisted generations.
+type GenerationHistoryItem struct {
+	ID          string `json:"id"`
+	ProjectIdea string `json:"projectIdea"`
+	Category    string `json:"category"`
+	Visibility  string `json:"visibility"`
+	ShortCode   string `json:"shortCode,omitempty"`
+	CreatedAt   string `json:"createdAt"`
+	// CreatedAtRelative is set with ?relative=true, e.g. "3 hours ago"
+	CreatedAtRelative string `json:"createdAtRelative,omitempty"`
+}
+
+// DryRunResponse is returned instead of generated content when dry_run is set.
+// It contains the exact prompts that would have been sent to the model.
+type DryRunResponse struct {
+	DryRun       bool   `json:"dryRun"`
+	Operation    string `json:"operation"`
+	SystemPrompt string `json:"systemPrompt"`
+	UserPrompt   string `json:"userPrompt"`
+}
+
+// IdempotencyKeyHeader is the request header clients use to make output generation retry-safe.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// maxIdempotencyKeyLength bounds the size of client-supplied idempotency keys.
+const maxIdempotencyKeyLength = 255
+
+// Response formats for POST /api/generate/outputs, chosen with ?format=.
+const (
+	outputFormatJSON   = "json"   // files as a JSON array (default)
+	outputFormatBundle = "bundle" // one Markdown document with a section per file
+)
+
+// GenerationIDHeader carries the stored generation's ID on bundle responses,
+// which have no JSON body to hold it.
+const GenerationIDHeader = "X-Generation-ID"
+
 // Note: ErrorResponse is defined in errors.go
 
 // GenerateHandler holds dependencies for generation endpoints.
 type GenerateHandler struct {
 	service     *generation.Service
 	rateLimiter *ratelimit.Limiter
+	pow         *pow.Issuer // nil when proof-of-work is disabled
 }
 
 // NewGenerateHandler creates a new handler with the given dependencies.
@@ -86,23 +204,26 @@ func NewGenerateHandler(service *generation.Service, limiter *ratelimit.Limiter)
 
 // HandleGenerateQuestions handles POST /api/generate/questions.
 func (h *GenerateHandler) HandleGenerateQuestions(w http.ResponseWriter, r *http.Request) {
-	// Check rate limit
-	ip := getClientIP(r)
-	allowed, retryAfter := h.rateLimiter.Allow(ip)
-	if !allowed {
-		WriteRateLimited(w, r, int(retryAfter.Seconds()))
+	dryRun, err := parseDryRun(r)
+	if err != nil {
+		WriteBadRequest(w, r, "Invalid dry_run parameter")
+		return
+	}
+
+	// Check proof-of-work, then rate limit (dry runs never call the model)
+	if !dryRun && (!h.checkProofOfWork(w, r) || !checkRateLimit(w, r, h.rateLimiter)) {
 		return
 	}
 
 	// Parse request body
 	var req GenerateQuestionsRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		WriteBadRequest(w, r, "Invalid request body")
+		writeDecodeError(w, r, err)
 		return
 	}
 
 	// Validate input
-	if err := generation.ValidateProjectIdea(req.ProjectIdea); err != nil {
+	if err := h.service.ValidateProjectIdea(req.ProjectIdea); err != nil {
 		WriteValidationError(w, r, err.Error())
 		return
 	}
@@ -112,41 +233,61 @@ func (h *GenerateHandler) HandleGenerateQuestions(w http.ResponseWriter, r *http
 		WriteValidationError(w, r, err.Error())
 		return
 	}
+	level := h.service.ResolveExperienceLevel(string(req.ExperienceLevel), req.ProjectIdea)
+
+	if dryRun {
+		preview, err := h.service.PreviewQuestionsPrompt(r.Context(), req.ProjectIdea, level)
+		if err != nil {
+			handleGenerationError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, newDryRunResponse(preview))
+		return
+	}
 
 	// Generate questions
-	questions, err := h.service.GenerateQuestions(r.Context(), req.ProjectIdea, string(req.ExperienceLevel))
+	questions, err := h.service.GenerateQuestions(r.Context(), req.ProjectIdea, level)
 	if err != nil {
 		handleGenerationError(w, r, err)
 		return
 	}
 
 	// Return response
-	writeJSON(w, http.StatusOK, GenerateQuestionsResponse{Questions: questions})
+	writeJSON(w, http.StatusOK, GenerateQuestionsResponse{
+		Questions:       questions,
+		ExperienceLevel: level,
+		Complexity:      generation.EstimateComplexity(req.ProjectIdea, nil),
+	})
 }
 
 // HandleGenerateOutputs handles POST /api/generate/outputs.
+// With ?format=bundle the validated files are returned as a single Markdown
+// document instead of JSON.
 func (h *GenerateHandler) HandleGenerateOutputs(w http.ResponseWriter, r *http.Request) {
-	// Check rate limit
-	ip := getClientIP(r)
-	allowed, retryAfter := h.rateLimiter.Allow(ip)
-	if !allowed {
-		WriteRateLimited(w, r, int(retryAfter.Seconds()))
+	dryRun, err := parseDryRun(r)
+	if err != nil {
+		WriteBadRequest(w, r, "Invalid dry_run parameter")
+		return
+	}
+	format, err := parseOutputFormat(r)
+	if err != nil {
+		WriteBadRequest(w, r, "Invalid format parameter: must be json or bundle")
 		return
 	}
 
 	// Parse request body
 	var req GenerateOutputsRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		WriteBadRequest(w, r, "Invalid request body")
+		writeDecodeError(w, r, err)
 		return
 	}
 
 	// Validate input
-	if err := generation.ValidateProjectIdea(req.ProjectIdea); err != nil {
+	if err := h.service.ValidateProjectIdea(req.ProjectIdea); err != nil {
 		WriteValidationError(w, r, err.Error())
 		return
 	}
-	if err := generation.ValidateAnswers(req.Answers); err != nil {
+	if err := h.service.ValidateAnswers(req.Answers); err != nil {
 		WriteValidationError(w, r, err.Error())
 		return
 	}
@@ -156,71 +297,377 @@ func (h *GenerateHandler) HandleGenerateOutputs(w http.ResponseWriter, r *http.R
 		WriteValidationError(w, r, err.Error())
 		return
 	}
+	level := h.service.ResolveExperienceLevel(string(req.ExperienceLevel), req.ProjectIdea)
 
 	// Validate hook preset
 	if err := validateHookPreset(req.HookPreset); err != nil {
 		WriteValidationError(w, r, err.Error())
 		return
 	}
+	preset := h.service.ResolveHookPreset(string(req.HookPreset))
+
+	// Validate visibility (optional)
+	if err := validateVisibility(req.Visibility); err != nil {
+		WriteValidationError(w, r, err.Error())
+		return
+	}
+
+	if dryRun {
+		preview, err := h.service.PreviewOutputsPrompt(r.Context(), req.ProjectIdea, req.Answers, level, preset)
+		if err != nil {
+			handleGenerationError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, newDryRunResponse(preview))
+		return
+	}
+
+	// Validate idempotency key (optional)
+	idempotencyKey := strings.TrimSpace(r.Header.Get(IdempotencyKeyHeader))
+	if len(idempotencyKey) > maxIdempotencyKeyLength {
+		WriteValidationError(w, r, "Idempotency-Key header is too long")
+		return
+	}
+	clientID := privacy.HashIP(getClientIP(r))
+
+	// A retry of a finished request is answered from the cache without
+	// charging the client again
+	replayed, err := h.service.CachedOutputs(r.Context(), clientID, idempotencyKey, req.ProjectIdea, req.Answers, level, preset, req.Visibility)
+	if err != nil {
+		handleGenerationError(w, r, err)
+		return
+	}
+	if replayed != nil {
+		writeOutputsResult(w, format, replayed, level)
+		return
+	}
+
+	// Check proof-of-work, then rate limit
+	if !h.checkProofOfWork(w, r) || !checkRateLimit(w, r, h.rateLimiter) {
+		return
+	}
 
 	// Generate outputs and store in database
-	result, err := h.service.GenerateAndStoreOutputs(r.Context(), req.ProjectIdea, req.Answers, string(req.ExperienceLevel), string(req.HookPreset))
+	result, err := h.service.GenerateAndStoreOutputsIdempotent(r.Context(), clientID, idempotencyKey, req.ProjectIdea, req.Answers, level, preset, req.Visibility)
 	if err != nil {
 		handleGenerationError(w, r, err)
 		return
 	}
 
-	// Return response
+	// Remember the creator so the generation appears in their history
+	if result.GenerationID != "" {
+		h.service.RecordCreator(r.Context(), result.GenerationID, clientID)
+	}
+
+	writeOutputsResult(w, format, result, level)
+}
+
+// writeOutputsResult writes a generation result in the requested format.
+func writeOutputsResult(w http.ResponseWriter, format string, result *generation.GenerationResult, level string) {
+	// The files were validated as usual; the bundle only changes their presentation
+	if format == outputFormatBundle {
+		if result.GenerationID != "" {
+			w.Header().Set(GenerationIDHeader, result.GenerationID)
+		}
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		_, _ = io.WriteString(w, generation.BuildMarkdownBundle(result.Files))
+		return
+	}
+
 	writeJSON(w, http.StatusOK, GenerateOutputsResponse{
-		Files:        result.Files,
-		GenerationID: result.GenerationID,
+		Files:           result.Files,
+		GenerationID:    result.GenerationID,
+		ShortCode:       result.ShortCode,
+		Warnings:        result.Warnings,
+		ExperienceLevel: level,
+		Attempts:        result.Attempts,
+		Duplicate:       result.Duplicate,
 	})
 }
 
-// getClientIP extracts the client IP from the request.
-func getClientIP(r *http.Request) string {
-	// Check X-Forwarded-For header first (for proxied requests)
-	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
-		// Take the first IP in the list
-		if idx := strings.Index(xff, ","); idx != -1 {
-			return strings.TrimSpace(xff[:idx])
+// HandleGetOptions handles GET /api/generate/options.
+// It lists the experience levels and hook presets the generate endpoints
+// accept, so clients do not hard-code them.
+func (h *GenerateHandler) HandleGetOptions(w http.ResponseWriter, r *http.Request) {
+	resp := GenerateOptionsResponse{
+		ExperienceLevels: make([]GenerateOption, 0, len(ValidExperienceLevels)),
+		HookPresets:      make([]HookPresetOption, 0, len(ValidHookPresets)),
+	}
+	for _, level := range prompts.RequestExperienceLevels() {
+		info := prompts.ExperienceLevelDescriptions[level]
+		resp.ExperienceLevels = append(resp.ExperienceLevels, GenerateOption{
+			Value:       level,
+			Title:       info.Title,
+			Description: info.Description,
+		})
+	}
+	for _, preset := range prompts.ValidHookPresets() {
+		info := prompts.HookPresetDescriptions[preset]
+		resp.HookPresets = append(resp.HookPresets, HookPresetOption{
+			GenerateOption: GenerateOption{
+				Value:       preset,
+				Title:       info.Title,
+				Description: info.Description,
+			},
+			Hooks: info.Hooks,
+		})
+	}
+
+	writeJSONWithETag(w, r, resp)
+}
+
+// HandleGenerationHistory handles GET /api/generate/history.
+// It lists the generations created from the caller's IP, newest first, up to
+// ?pageSize= of them. The response depends on the caller, so it must never be
+// stored by shared caches.
+func (h *GenerateHandler) HandleGenerationHistory(w http.ResponseWriter, r *http.Request) {
+	pageSize, ok := parsePageSize(w, r)
+	if !ok {
+		return
+	}
+
+	generations, err := h.service.History(r.Context(), privacy.HashIP(getClientIP(r)), pageSize)
+	if err != nil {
+		WriteServiceError(w, r, err, "Failed to load generation history")
+		return
+	}
+
+	now := time.Now()
+	items := make([]GenerationHistoryItem, len(generations))
+	for i, gen := range generations {
+		items[i] = GenerationHistoryItem{
+			ID:          gen.ID,
+			ProjectIdea: gen.ProjectIdea,
+			Category:    gen.CategoryName,
+			Visibility:  gen.Visibility,
+			ShortCode:   gen.ShortCode,
+			CreatedAt:   formatTimestamp(gen.CreatedAt),
+
+			CreatedAtRelative: relativeTimestamp(r, gen.CreatedAt, now),
+		}
+	}
+
+	w.Header().Set("Cache-Control", "private, no-store")
+	writeJSON(w, http.StatusOK, GenerationHistoryResponse{Items: items})
+}
+
+// HandleGenerateKickoff handles POST /api/generate/kickoff.
+// It returns only the kickoff prompt, without storing it; clients call
+// /api/generate/outputs afterward for the full file set.
+func (h *GenerateHandler) HandleGenerateKickoff(w http.ResponseWriter, r *http.Request) {
+	dryRun, err := parseDryRun(r)
+	if err != nil {
+		WriteBadRequest(w, r, "Invalid dry_run parameter")
+		return
+	}
+
+	// Check proof-of-work, then rate limit (dry runs never call the model)
+	if !dryRun && (!h.checkProofOfWork(w, r) || !checkRateLimit(w, r, h.rateLimiter)) {
+		return
+	}
+
+	// Parse request body
+	var req GenerateKickoffRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeDecodeError(w, r, err)
+		return
+	}
+
+	// Validate input
+	if err := h.service.ValidateProjectIdea(req.ProjectIdea); err != nil {
+		WriteValidationError(w, r, err.Error())
+		return
+	}
+	if err := h.service.ValidateAnswers(req.Answers); err != nil {
+		WriteValidationError(w, r, err.Error())
+		return
+	}
+
+	// Validate experience level
+	if err := validateExperienceLevel(req.ExperienceLevel); err != nil {
+		WriteValidationError(w, r, err.Error())
+		return
+	}
+	level := h.service.ResolveExperienceLevel(string(req.ExperienceLevel), req.ProjectIdea)
+
+	if dryRun {
+		preview, err := h.service.PreviewKickoffPrompt(r.Context(), req.ProjectIdea, req.Answers, level)
+		if err != nil {
+			handleGenerationError(w, r, err)
+			return
 		}
-		return strings.TrimSpace(xff)
+		writeJSON(w, http.StatusOK, newDryRunResponse(preview))
+		return
 	}
 
-	// Check X-Real-IP header
-	if xri := r.Header.Get("X-Real-IP"); xri != "" {
-		return strings.TrimSpace(xri)
+	file, err := h.service.GenerateKickoff(r.Context(), req.ProjectIdea, req.Answers, level)
+	if err != nil {
+		handleGenerationError(w, r, err)
+		return
 	}
 
-	// Fall back to RemoteAddr
-	addr := r.RemoteAddr
-	// Remove port if present
-	if idx := strings.LastIndex(addr, ":"); idx != -1 {
-		return addr[:idx]
+	writeJSON(w, http.StatusOK, GenerateKickoffResponse{
+		File:            *file,
+		ExperienceLevel: level,
+	})
+}
+
+// HandleAssessAnswers handles POST /api/generate/assess.
+// It flags weak answers with simple heuristics and never calls the model,
+// so it is not rate limited.
+func (h *GenerateHandler) HandleAssessAnswers(w http.ResponseWriter, r *http.Request) {
+	// Parse request body
+	var req AssessAnswersRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeDecodeError(w, r, err)
+		return
 	}
-	return addr
+
+	// Validate input
+	if err := h.service.ValidateAnswers(req.Answers); err != nil {
+		WriteValidationError(w, r, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, AssessAnswersResponse{
+		Hints: h.service.AssessAnswers(r.Context(), req.Questions, req.Answers),
+	})
 }
 
-// handleGenerationError converts generation errors to appropriate HTTP responses.
-func handleGenerationError(w http.ResponseWriter, r *http.Request, err error) {
-	switch {
-	case errors.Is(err, generation.ErrEmptyProjectIdea),
-		errors.Is(err, generation.ErrProjectIdeaTooLong),
-		errors.Is(err, generation.ErrAnswerTooLong):
+// HandleReplayGeneration handles POST /api/admin/generate/{id}/replay.
+// It regenerates outputs from the stored inputs without storing the result,
+// and requires the internal API key.
+func (h *GenerateHandler) HandleReplayGeneration(w http.ResponseWriter, r *http.Request) {
+	if !requireInternalKey(w, r) {
+		return
+	}
+
+	id := r.PathValue("id")
+	if id == "" {
+		WriteBadRequest(w, r, "Generation ID is required")
+		return
+	}
+
+	result, err := h.service.ReplayGeneration(r.Context(), id)
+	if err != nil {
+		handleGenerationError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ReplayGenerationResponse{
+		GenerationID: result.GenerationID,
+		Files:        result.Files,
+		Warnings:     result.Warnings,
+		Attempts:     result.Attempts,
+	})
+}
+
+// HandleGenerateExamples handles POST /api/generate/examples.
+func (h *GenerateHandler) HandleGenerateExamples(w http.ResponseWriter, r *http.Request) {
+	dryRun, err := parseDryRun(r)
+	if err != nil {
+		WriteBadRequest(w, r, "Invalid dry_run parameter")
+		return
+	}
+
+	// Check proof-of-work, then rate limit (dry runs never call the model)
+	if !dryRun && (!h.checkProofOfWork(w, r) || !checkRateLimit(w, r, h.rateLimiter)) {
+		return
+	}
+
+	// Parse request body
+	var req GenerateExamplesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeDecodeError(w, r, err)
+		return
+	}
+
+	// Validate input
+	if err := generation.ValidateQuestion(req.Question); err != nil {
 		WriteValidationError(w, r, err.Error())
-	case errors.Is(err, generation.ErrInvalidResponse),
-		errors.Is(err, generation.ErrNoQuestions),
-		errors.Is(err, generation.ErrNoFiles):
-		WriteInternalError(w, r, "Generation failed. Please try again later.")
-	default:
-		// Check for timeout
-		if strings.Contains(err.Error(), "timed out") {
-			WriteTimeout(w, r)
+		return
+	}
+	if err := h.service.ValidateProjectIdea(req.ProjectIdea); err != nil {
+		WriteValidationError(w, r, err.Error())
+		return
+	}
+
+	// Validate experience level
+	if err := validateExperienceLevel(req.ExperienceLevel); err != nil {
+		WriteValidationError(w, r, err.Error())
+		return
+	}
+	level := h.service.ResolveExperienceLevel(string(req.ExperienceLevel), req.ProjectIdea)
+
+	if dryRun {
+		preview, err := h.service.PreviewExamplesPrompt(r.Context(), req.Question, req.ProjectIdea, level)
+		if err != nil {
+			handleGenerationError(w, r, err)
 			return
 		}
-		WriteInternalError(w, r, "Generation failed. Please try again later.")
+		writeJSON(w, http.StatusOK, newDryRunResponse(preview))
+		return
+	}
+
+	// Generate examples
+	examples, err := h.service.GenerateExamples(r.Context(), req.Question, req.ProjectIdea, level)
+	if err != nil {
+		handleGenerationError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, GenerateExamplesResponse{Examples: examples})
+}
+
+// parseDryRun reports whether the dry_run query parameter is set.
+func parseDryRun(r *http.Request) (bool, error) {
+	v := r.URL.Query().Get("dry_run")
+	if v == "" {
+		return false, nil
 	}
+	return strconv.ParseBool(v)
+}
+
+// parseOutputFormat returns the requested outputs response format, defaulting to JSON.
+func parseOutputFormat(r *http.Request) (string, error) {
+	switch v := r.URL.Query().Get("format"); v {
+	case "", outputFormatJSON:
+		return outputFormatJSON, nil
+	case outputFormatBundle:
+		return outputFormatBundle, nil
+	default:
+		return "", fmt.Errorf("unknown format %q", v)
+	}
+}
+
+// newDryRunResponse converts a prompt preview into a dry-run response.
+func newDryRunResponse(preview *generation.PromptPreview) DryRunResponse {
+	return DryRunResponse{
+		DryRun:       true,
+		Operation:    preview.Operation,
+		SystemPrompt: preview.SystemPrompt,
+		UserPrompt:   preview.UserPrompt,
+	}
+}
+
+// getClientIP returns the client IP resolved by ClientIPMiddleware. Without
+// the middleware, forwarding headers are not trusted and the peer address is used.
+func getClientIP(r *http.Request) string {
+	if ip := GetClientIP(r.Context()); ip != "" {
+		return ip
+	}
+	return NewClientIPResolver(nil).Resolve(r)
+}
+
+// handleGenerationError converts generation errors to appropriate HTTP responses.
+func handleGenerationError(w http.ResponseWriter, r *http.Request, err error) {
+	// Client timeouts are not always wrapped, so fall back to the message
+	if _, ok := lookupErrorMapping(err); !ok && strings.Contains(err.Error(), "timed out") {
+		WriteTimeout(w, r)
+		return
+	}
+	WriteServiceError(w, r, err, "Generation failed. Please try again later.")
 }
 
 // writeJSON writes a JSON response.
@@ -230,24 +677,58 @@ func writeJSON(w http.ResponseWriter, status int, data interface{}) {
 	_ = json.NewEncoder(w).Encode(data)
 }
 
-// validateExperienceLevel validates the experience level value.
+// validateExperienceLevel validates the optional experience level value.
+// An empty level uses the server's configured default.
 func validateExperienceLevel(level ExperienceLevel) error {
 	if level == "" {
-		return errors.New("experience level is required")
+		return nil
 	}
 	if !ValidExperienceLevels[level] {
-		return errors.New("invalid experience level: must be 'beginner', 'novice', or 'expert'")
+		return errors.New("invalid experience level: must be " + quotedOptions(prompts.RequestExperienceLevels()))
 	}
 	return nil
 }
 
-// validateHookPreset validates the hook preset value.
+// optionSet indexes option values for validation.
+func optionSet[T ~string](values []string) map[T]bool {
+	set := make(map[T]bool, len(values))
+	for _, v := range values {
+		set[T(v)] = true
+	}
+	return set
+}
+
+// quotedOptions lists option values for an error message, e.g. 'a', 'b', or 'c'.
+func quotedOptions(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = "'" + v + "'"
+	}
+	if len(quoted) < 2 {
+		return strings.Join(quoted, "")
+	}
+	return strings.Join(quoted[:len(quoted)-1], ", ") + ", or " + quoted[len(quoted)-1]
+}
+
+// validateHookPreset validates the optional hook preset value.
+// An empty preset uses the server's configured default.
 func validateHookPreset(preset HookPreset) error {
 	if preset == "" {
-		return errors.New("hook preset is required")
+		return nil
 	}
 	if !ValidHookPresets[preset] {
-		return errors.New("invalid hook preset: must be 'light', 'basic', 'default', or 'strict'")
+		return errors.New("invalid hook preset: must be " + quotedOptions(prompts.ValidHookPresets()))
+	}
+	return nil
+}
+
+// validateVisibility validates the optional gallery visibility value.
+func validateVisibility(visibility string) error {
+	if visibility == "" {
+		return nil
+	}
+	if !storage.IsValidVisibility(visibility) {
+		return errors.New("invalid visibility: must be 'public' or 'unlisted'")
 	}
 	return nil
 }
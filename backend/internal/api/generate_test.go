@@ -0,0 +1,416 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"better-kiro-prompts/internal/config"
+	"better-kiro-prompts/internal/generation"
+	"better-kiro-prompts/internal/privacy"
+	"better-kiro-prompts/internal/ratelimit"
+	"better-kiro-prompts/internal/storage"
+)
+
+// newDryRunHandler returns a handler whose service has no OpenAI client and
+// whose limiter allows a single request, so any model call or rate-limited
+// path would fail the test.
+func newDryRunHandler() *GenerateHandler {
+	return NewGenerateHandler(generation.NewService(nil), ratelimit.NewLimiterWithConfig(1, ratelimit.DefaultWindow))
+}
+
+func TestHandleGenerateOutputs_DryRun(t *testing.T) {
+	h := newDryRunHandler()
+	body := `{"projectIdea":"A plant watering reminder","answers":[{"questionId":1,"answer":"Indoor gardeners"}],"experienceLevel":"beginner","hookPreset":"light"}`
+
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/api/generate/outputs?dry_run=true", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		h.HandleGenerateOutputs(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("request %d: status = %d, want 200; body = %s", i+1, rec.Code, rec.Body.String())
+		}
+
+		var resp DryRunResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if !resp.DryRun {
+			t.Error("response should be marked as a dry run")
+		}
+		if resp.Operation != generation.OperationOutputs {
+			t.Errorf("Operation = %q, want %q", resp.Operation, generation.OperationOutputs)
+		}
+		if !strings.Contains(resp.UserPrompt, "A plant watering reminder") {
+			t.Error("user prompt should contain the project idea")
+		}
+		if !strings.Contains(resp.SystemPrompt, "Experience Level: beginner") {
+			t.Error("system prompt should contain the experience level")
+		}
+	}
+}
+
+func TestHandleGenerateQuestions_DryRun(t *testing.T) {
+	h := newDryRunHandler()
+	body := `{"projectIdea":"A book club organizer","experienceLevel":"expert"}`
+
+	req := httptest.NewRequest(http.MethodPost, "/api/generate/questions?dry_run=1", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.HandleGenerateQuestions(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body = %s", rec.Code, rec.Body.String())
+	}
+
+	var resp DryRunResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !resp.DryRun || resp.Operation != generation.OperationQuestions {
+		t.Errorf("unexpected dry run response: %+v", resp)
+	}
+	if !strings.Contains(resp.UserPrompt, "A book club organizer") || !strings.Contains(resp.UserPrompt, "expert") {
+		t.Error("user prompt should contain the project idea and experience level")
+	}
+}
+
+func TestHandleGenerateQuestions_WithoutClientIsUnavailable(t *testing.T) {
+	h := newDryRunHandler()
+	body := `{"projectIdea":"A book club organizer","experienceLevel":"expert"}`
+
+	req := httptest.NewRequest(http.MethodPost, "/api/generate/questions", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.HandleGenerateQuestions(rec, req)
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", rec.Code)
+	}
+}
+
+func TestHandleGenerateQuestions_InvalidDryRun(t *testing.T) {
+	h := newDryRunHandler()
+	req := httptest.NewRequest(http.MethodPost, "/api/generate/questions?dry_run=maybe", strings.NewReader(`{}`))
+	rec := httptest.NewRecorder()
+	h.HandleGenerateQuestions(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", rec.Code)
+	}
+}
+
+func TestHandleGenerateOutputs_InvalidFormat(t *testing.T) {
+	h := newDryRunHandler()
+	body := `{"projectIdea":"A plant watering reminder","answers":[{"questionId":1,"answer":"Indoor gardeners"}]}`
+
+	// An unknown format is rejected before the request counts against the limit
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/api/generate/outputs?format=pdf", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		h.HandleGenerateOutputs(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("request %d: status = %d, want 400", i+1, rec.Code)
+		}
+	}
+}
+
+func TestHandleGenerateQuestions_UsesConfiguredIdeaLimit(t *testing.T) {
+	svc := generation.NewServiceWithConfig(nil, nil, nil, nil, config.GenerationConfig{MaxProjectIdeaLength: 100, MaxAnswerLength: 100})
+	h := NewGenerateHandler(svc, ratelimit.NewLimiterWithConfig(1, ratelimit.DefaultWindow))
+	body := `{"projectIdea":"` + strings.Repeat("a", 150) + `","experienceLevel":"beginner"}`
+
+	req := httptest.NewRequest(http.MethodPost, "/api/generate/questions?dry_run=true", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.HandleGenerateQuestions(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400; body: %s", rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), generation.ErrProjectIdeaTooLong.Error()) {
+		t.Errorf("body = %s, want the project idea length error", rec.Body.String())
+	}
+}
+
+func TestHandleGenerateExamples_DryRun(t *testing.T) {
+	h := newDryRunHandler()
+	body := `{"question":"Who will use the app?","projectIdea":"A chore tracker for roommates","experienceLevel":"novice"}`
+
+	req := httptest.NewRequest(http.MethodPost, "/api/generate/examples?dry_run=1", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.HandleGenerateExamples(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", rec.Code, rec.Body.String())
+	}
+	var resp DryRunResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Operation != generation.OperationExamples {
+		t.Errorf("operation = %q, want %q", resp.Operation, generation.OperationExamples)
+	}
+	if !strings.Contains(resp.UserPrompt, "Who will use the app?") {
+		t.Error("user prompt should contain the question")
+	}
+}
+
+func TestHandleGenerateExamples_MissingQuestion(t *testing.T) {
+	h := newDryRunHandler()
+	body := `{"question":"","projectIdea":"A chore tracker for roommates","experienceLevel":"novice"}`
+
+	req := httptest.NewRequest(http.MethodPost, "/api/generate/examples", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.HandleGenerateExamples(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", rec.Code)
+	}
+}
+
+func TestHandleGenerateQuestions_AutoExperienceLevel(t *testing.T) {
+	h := newDryRunHandler()
+	body := `{"projectIdea":"Microservices on Kubernetes with CQRS, event sourcing and OAuth","experienceLevel":"auto"}`
+
+	req := httptest.NewRequest(http.MethodPost, "/api/generate/questions?dry_run=true", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.HandleGenerateQuestions(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", rec.Code, rec.Body.String())
+	}
+	var resp DryRunResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !strings.Contains(resp.UserPrompt, "User Experience Level: expert") {
+		t.Errorf("user prompt should use the inferred expert level, got: %s", resp.UserPrompt)
+	}
+}
+
+func TestHandleGenerateOutputs_OmittedLevelUsesConfiguredDefault(t *testing.T) {
+	cfg := config.DefaultConfig().Generation
+	cfg.DefaultLevel = "beginner"
+	cfg.DefaultHookPreset = "strict"
+	h := NewGenerateHandler(generation.NewServiceWithConfig(nil, nil, nil, nil, cfg), ratelimit.NewLimiterWithConfig(1, ratelimit.DefaultWindow))
+	body := `{"projectIdea":"A plant watering reminder","answers":[{"questionId":1,"answer":"Indoor gardeners"}]}`
+
+	req := httptest.NewRequest(http.MethodPost, "/api/generate/outputs?dry_run=true", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.HandleGenerateOutputs(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", rec.Code, rec.Body.String())
+	}
+	var resp DryRunResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !strings.Contains(resp.SystemPrompt, "Experience Level: beginner") {
+		t.Error("system prompt should use the configured default level, not novice")
+	}
+	if !strings.Contains(resp.SystemPrompt, "Hook Preset: strict") {
+		t.Error("system prompt should use the configured default hook preset")
+	}
+}
+
+// historyRepository records creators in memory; other Repository methods are unused.
+type historyRepository struct {
+	storage.Repository
+	generations map[string]storage.Generation
+	creators    map[string][]string // ipHash -> generation IDs in creation order
+}
+
+func (r *historyRepository) RecordCreator(_ context.Context, generationID string, ipHash string) error {
+	r.creators[ipHash] = append(r.creators[ipHash], generationID)
+	return nil
+}
+
+func (r *historyRepository) ListGenerationsByCreator(_ context.Context, ipHash string, limit int) ([]storage.Generation, error) {
+	ids := r.creators[ipHash]
+	generations := []storage.Generation{}
+	for i := len(ids) - 1; i >= 0 && len(generations) < limit; i-- {
+		generations = append(generations, r.generations[ids[i]])
+	}
+	return generations, nil
+}
+
+func TestHandleGenerationHistory_OnlyCallersGenerations(t *testing.T) {
+	repo := &historyRepository{
+		generations: map[string]storage.Generation{
+			"gen-1": {ID: "gen-1", ProjectIdea: "A todo app", Files: json.RawMessage(`[]`), Visibility: storage.VisibilityPublic},
+			"gen-2": {ID: "gen-2", ProjectIdea: "A chat app", Files: json.RawMessage(`[]`), Visibility: storage.VisibilityPublic},
+			"gen-3": {ID: "gen-3", ProjectIdea: "A private diary", Files: json.RawMessage(`[]`), Visibility: storage.VisibilityUnlisted},
+		},
+		creators: map[string][]string{},
+	}
+	svc := generation.NewService(nil)
+	svc.SetRepository(repo)
+	h := NewGenerateHandler(svc, nil)
+
+	callerHash := privacy.HashIP("192.0.2.1")
+	otherHash := privacy.HashIP("192.0.2.2")
+	ctx := context.Background()
+	svc.RecordCreator(ctx, "gen-1", callerHash)
+	svc.RecordCreator(ctx, "gen-2", otherHash)
+	svc.RecordCreator(ctx, "gen-3", callerHash)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/generate/history", nil)
+	req.RemoteAddr = "192.0.2.1:40000"
+	rec := httptest.NewRecorder()
+	h.HandleGenerationHistory(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body = %s", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Cache-Control"); got != "private, no-store" {
+		t.Errorf("Cache-Control = %q, want private, no-store", got)
+	}
+
+	body := rec.Body.String()
+	var resp GenerationHistoryResponse
+	if err := json.Unmarshal([]byte(body), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	var ids []string
+	for _, gen := range resp.Items {
+		ids = append(ids, gen.ID)
+	}
+	if want := []string{"gen-3", "gen-1"}; !slices.Equal(ids, want) {
+		t.Errorf("history = %v, want %v (newest first, caller's only)", ids, want)
+	}
+	for _, hash := range []string{callerHash, otherHash} {
+		if strings.Contains(body, hash) {
+			t.Errorf("history response exposes an IP hash: %s", body)
+		}
+	}
+}
+
+func TestGenerationSerialization_ExcludesCreator(t *testing.T) {
+	creatorHash := privacy.HashIP("192.0.2.1")
+	repo := &historyRepository{
+		generations: map[string]storage.Generation{
+			"gen-1": {ID: "gen-1", ProjectIdea: "A todo app", Files: json.RawMessage(`[]`), Answers: json.RawMessage(`[]`), ContentHash: "abc123"},
+		},
+		creators: map[string][]string{},
+	}
+	svc := generation.NewService(nil)
+	svc.SetRepository(repo)
+	svc.RecordCreator(context.Background(), "gen-1", creatorHash)
+
+	items, err := svc.History(context.Background(), creatorHash, 0)
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+	// Public gallery responses serialize the same storage.Generation
+	data, err := json.Marshal(items[0])
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	for _, private := range []string{creatorHash, "ipHash", "ip_hash", "answers", "abc123"} {
+		if strings.Contains(string(data), private) {
+			t.Errorf("serialized generation contains %q: %s", private, data)
+		}
+	}
+}
+
+func TestHandleGetOptions_ListsExactlyValidValues(t *testing.T) {
+	h := newDryRunHandler()
+	req := httptest.NewRequest(http.MethodGet, "/api/generate/options", nil)
+	rec := httptest.NewRecorder()
+	h.HandleGetOptions(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body = %s", rec.Code, rec.Body.String())
+	}
+	var resp GenerateOptionsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	levels := map[ExperienceLevel]bool{}
+	for _, opt := range resp.ExperienceLevels {
+		if levels[ExperienceLevel(opt.Value)] {
+			t.Errorf("experience level %q listed twice", opt.Value)
+		}
+		levels[ExperienceLevel(opt.Value)] = true
+		if opt.Title == "" || opt.Description == "" {
+			t.Errorf("experience level %q has no title or description", opt.Value)
+		}
+	}
+	if !maps.Equal(levels, ValidExperienceLevels) {
+		t.Errorf("experience levels = %v, want %v", levels, ValidExperienceLevels)
+	}
+
+	presets := map[HookPreset]bool{}
+	for _, opt := range resp.HookPresets {
+		if presets[HookPreset(opt.Value)] {
+			t.Errorf("hook preset %q listed twice", opt.Value)
+		}
+		presets[HookPreset(opt.Value)] = true
+		if opt.Title == "" || opt.Description == "" || len(opt.Hooks) == 0 {
+			t.Errorf("hook preset %q has no title, description or hooks", opt.Value)
+		}
+	}
+	if !maps.Equal(presets, ValidHookPresets) {
+		t.Errorf("hook presets = %v, want %v", presets, ValidHookPresets)
+	}
+}
+
+func TestReplayGeneration_RequiresInternalKey(t *testing.T) {
+	newRouter := func(key string) http.Handler {
+		return NewRouter(&RouterConfig{
+			GenerationService: generation.NewService(nil),
+			RateLimiter:       ratelimit.NewLimiterWithConfig(1, ratelimit.DefaultWindow),
+			InternalAPIKey:    key,
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/generate/gen-1/replay", nil)
+	rec := httptest.NewRecorder()
+	newRouter(testInternalKey).ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status without key = %d, want 401", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/admin/generate/gen-1/replay", nil)
+	req.Header.Set(InternalKeyHeader, "wrong-key")
+	rec = httptest.NewRecorder()
+	newRouter(testInternalKey).ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status with a wrong key = %d, want 401", rec.Code)
+	}
+
+	// Without an internal key configured the route does not exist
+	req = httptest.NewRequest(http.MethodPost, "/api/admin/generate/gen-1/replay", nil)
+	rec = httptest.NewRecorder()
+	newRouter("").ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status without a configured key = %d, want 404", rec.Code)
+	}
+}
+
+func TestValidateExperienceLevel_ListsEveryLevel(t *testing.T) {
+	err := validateExperienceLevel("guru")
+	if err == nil {
+		t.Fatal("expected an unknown level to be rejected")
+	}
+	if want := "invalid experience level: must be 'beginner', 'novice', 'expert', or 'auto'"; err.Error() != want {
+		t.Errorf("error = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestValidateHookPreset_ListsEveryPreset(t *testing.T) {
+	err := validateHookPreset("paranoid")
+	if err == nil {
+		t.Fatal("expected an unknown preset to be rejected")
+	}
+	if want := "invalid hook preset: must be 'light', 'basic', 'default', or 'strict'"; err.Error() != want {
+		t.Errorf("error = %q, want %q", err.Error(), want)
+	}
+}
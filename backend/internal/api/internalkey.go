@@ -0,0 +1,86 @@
+package api
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"log/slog"
+	"net/http"
+
+	"better-kiro-prompts/internal/ratelimit"
+)
+
+// InternalKeyHeader is the HTTP header internal tooling uses to present the internal API key.
+const InternalKeyHeader = "X-Internal-Key"
+
+// InternalKeyMiddleware exempts requests presenting the internal API key from
+// the generation, scan and rating rate limits. Every exempt request is logged.
+// An empty key disables the exemption entirely.
+func InternalKeyMiddleware(key string, log *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if key == "" {
+			return next
+		}
+		// Comparing digests keeps the comparison constant-time regardless of the presented length
+		want := sha256.Sum256([]byte(key))
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			presented := r.Header.Get(InternalKeyHeader)
+			if presented == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			got := sha256.Sum256([]byte(presented))
+			if subtle.ConstantTimeCompare(got[:], want[:]) != 1 {
+				if log != nil {
+					log.Warn("internal_key_rejected",
+						slog.String("request_id", GetRequestID(r.Context())),
+						slog.String("path", r.URL.Path),
+					)
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if log != nil {
+				log.Info("internal_key_used",
+					slog.String("request_id", GetRequestID(r.Context())),
+					slog.String("method", r.Method),
+					slog.String("path", r.URL.Path),
+				)
+			}
+			next.ServeHTTP(w, r.WithContext(ratelimit.WithExemption(r.Context())))
+		})
+	}
+}
+
+// checkRateLimit counts the request against limiter and writes a 429 when the
+// client is over the limit. It reports whether the handler may continue.
+// Requests exempted by InternalKeyMiddleware are not counted.
+func checkRateLimit(w http.ResponseWriter, r *http.Request, limiter *ratelimit.Limiter) bool {
+	return checkRateLimitN(w, r, limiter, 1)
+}
+
+// checkRateLimitN is like checkRateLimit for a request that counts as n
+// requests, such as a batch.
+func checkRateLimitN(w http.ResponseWriter, r *http.Request, limiter *ratelimit.Limiter, n int) bool {
+	if ratelimit.IsExempt(r.Context()) {
+		return true
+	}
+	allowed, retryAfter := limiter.AllowN(getClientIP(r), n)
+	if !allowed {
+		WriteRateLimited(w, r, int(retryAfter.Seconds()))
+		return false
+	}
+	return true
+}
+
+// requireInternalKey writes a 401 unless InternalKeyMiddleware accepted the
+// request's internal API key. It reports whether the handler may continue.
+func requireInternalKey(w http.ResponseWriter, r *http.Request) bool {
+	if ratelimit.IsExempt(r.Context()) {
+		return true
+	}
+	WriteError(w, r, http.StatusUnauthorized, ErrCodeUnauthorized, "A valid "+InternalKeyHeader+" header is required")
+	return false
+}
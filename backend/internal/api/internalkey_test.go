@@ -0,0 +1,83 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"better-kiro-prompts/internal/generation"
+	"better-kiro-prompts/internal/ratelimit"
+)
+
+const testInternalKey = "internal-test-key-0123456789abcdef"
+
+func newInternalKeyRouter() http.Handler {
+	return NewRouter(&RouterConfig{
+		GenerationService: generation.NewService(nil),
+		RateLimiter:       ratelimit.NewLimiterWithConfig(1, ratelimit.DefaultWindow),
+		InternalAPIKey:    testInternalKey,
+	})
+}
+
+func postQuestions(router http.Handler, key string) int {
+	req := httptest.NewRequest(http.MethodPost, "/api/generate/questions",
+		strings.NewReader(`{"projectIdea":"A todo app","experienceLevel":"beginner"}`))
+	if key != "" {
+		req.Header.Set(InternalKeyHeader, key)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestInternalKey_SkipsRateLimit(t *testing.T) {
+	router := newInternalKeyRouter()
+
+	for i := 0; i < 5; i++ {
+		if code := postQuestions(router, testInternalKey); code == http.StatusTooManyRequests {
+			t.Fatalf("request %d with the internal key was rate limited", i+1)
+		}
+	}
+
+	// Exempt requests are not counted, so the public budget is untouched
+	if code := postQuestions(router, ""); code == http.StatusTooManyRequests {
+		t.Error("first request without the key was rate limited")
+	}
+}
+
+func TestInternalKey_WithoutKeyStillLimited(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+	}{
+		{name: "no key", key: ""},
+		{name: "wrong key", key: "not-the-internal-key-0123456789abc"},
+		{name: "key prefix", key: testInternalKey[:10]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newInternalKeyRouter()
+			if code := postQuestions(router, tt.key); code == http.StatusTooManyRequests {
+				t.Fatal("first request was rate limited")
+			}
+			if code := postQuestions(router, tt.key); code != http.StatusTooManyRequests {
+				t.Errorf("second request status = %d, want 429", code)
+			}
+		})
+	}
+}
+
+func TestInternalKey_DisabledByDefault(t *testing.T) {
+	router := NewRouter(&RouterConfig{
+		GenerationService: generation.NewService(nil),
+		RateLimiter:       ratelimit.NewLimiterWithConfig(1, ratelimit.DefaultWindow),
+	})
+
+	postQuestions(router, "")
+	// With no key configured, any presented key is ignored
+	if code := postQuestions(router, testInternalKey); code != http.StatusTooManyRequests {
+		t.Errorf("status = %d, want 429 when no internal key is configured", code)
+	}
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+// ErrCodeMaintenance is returned when a write endpoint is blocked by maintenance mode.
+const ErrCodeMaintenance = "SERVER_MAINTENANCE"
+
+// maintenanceMessage tells clients what still works while writes are blocked.
+const maintenanceMessage = "The service is in read-only maintenance mode. Generation, scanning and rating are temporarily disabled; the gallery is still available."
+
+// maintenanceWritePrefixes are the path prefixes of endpoints blocked in maintenance mode.
+// Gallery ratings are matched separately because they share the /api/gallery/ prefix with reads.
+var maintenanceWritePrefixes = []string{
+	"/api/generate/",
+	"/api/admin/generate/",
+	"/api/scan",
+	galleryImportPath,
+}
+
+// MaintenanceMode is a read-only toggle that can be flipped at runtime.
+// It is safe for concurrent use.
+type MaintenanceMode struct {
+	enabled atomic.Bool
+}
+
+// NewMaintenanceMode creates a maintenance toggle with the given initial state.
+func NewMaintenanceMode(enabled bool) *MaintenanceMode {
+	m := &MaintenanceMode{}
+	m.enabled.Store(enabled)
+	return m
+}
+
+// Enabled reports whether maintenance mode is on.
+func (m *MaintenanceMode) Enabled() bool {
+	return m.enabled.Load()
+}
+
+// Set turns maintenance mode on or off and returns the previous state.
+func (m *MaintenanceMode) Set(enabled bool) bool {
+	return m.enabled.Swap(enabled)
+}
+
+// MaintenanceMiddleware rejects generate, scan and rate requests with 503 while
+// maintenance mode is on. Read endpoints, including the gallery, keep serving.
+func MaintenanceMiddleware(mode *MaintenanceMode) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if mode.Enabled() && isMaintenanceBlocked(r) {
+				WriteError(w, r, http.StatusServiceUnavailable, ErrCodeMaintenance, maintenanceMessage)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// isMaintenanceBlocked reports whether r is a write that maintenance mode blocks.
+func isMaintenanceBlocked(r *http.Request) bool {
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return false
+	}
+	if strings.HasPrefix(r.URL.Path, "/api/gallery/") && strings.HasSuffix(r.URL.Path, "/rate") {
+		return true
+	}
+	for _, prefix := range maintenanceWritePrefixes {
+		if strings.HasPrefix(r.URL.Path, prefix) {
+			return true
+		}
+	}
+	return false
+}
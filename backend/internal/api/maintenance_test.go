@@ -0,0 +1,104 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"better-kiro-prompts/internal/gallery"
+	"better-kiro-prompts/internal/generation"
+	"better-kiro-prompts/internal/ratelimit"
+)
+
+func newMaintenanceRouter(mode *MaintenanceMode) http.Handler {
+	return NewRouter(&RouterConfig{
+		GenerationService: generation.NewService(nil),
+		RateLimiter:       ratelimit.NewLimiterWithConfig(10, ratelimit.DefaultWindow),
+		GalleryService:    gallery.NewService(&pagedRepository{total: 3}, nil, nil),
+		Maintenance:       mode,
+	})
+}
+
+func TestMaintenanceMode_RejectsGenerate(t *testing.T) {
+	router := newMaintenanceRouter(NewMaintenanceMode(true))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/generate/questions",
+		strings.NewReader(`{"projectIdea":"A todo app","experienceLevel":"beginner"}`))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503", rec.Code)
+	}
+	var body ErrorResponse
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body.Code != ErrCodeMaintenance {
+		t.Errorf("code = %q, want %q", body.Code, ErrCodeMaintenance)
+	}
+	if !strings.Contains(body.Error, "maintenance") {
+		t.Errorf("error = %q, want a maintenance message", body.Error)
+	}
+}
+
+func TestMaintenanceMode_GalleryListStillServes(t *testing.T) {
+	router := newMaintenanceRouter(NewMaintenanceMode(true))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/gallery", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestMaintenanceMode_Toggle(t *testing.T) {
+	mode := NewMaintenanceMode(true)
+	router := newMaintenanceRouter(mode)
+
+	if was := mode.Set(false); !was {
+		t.Error("Set() returned false, want previous state true")
+	}
+
+	// An empty body fails validation, so anything but 503 means the request got through
+	req := httptest.NewRequest(http.MethodPost, "/api/generate/questions", strings.NewReader(`{}`))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code == http.StatusServiceUnavailable {
+		t.Fatalf("status = 503 after disabling maintenance mode")
+	}
+}
+
+func TestIsMaintenanceBlocked(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		want   bool
+	}{
+		{http.MethodPost, "/api/generate/outputs", true},
+		{http.MethodPost, "/api/admin/generate/abc/replay", true},
+		{http.MethodPost, "/api/scan", true},
+		{http.MethodPost, "/api/scan/abc/findings/1/remediate", true},
+		{http.MethodPost, "/api/gallery/abc/rate", true},
+		{http.MethodPost, "/api/admin/gallery/import", true},
+		{http.MethodGet, "/api/admin/gallery/export", false},
+		{http.MethodGet, "/api/scan/abc", false},
+		{http.MethodGet, "/api/gallery", false},
+		{http.MethodPost, "/api/gallery/abc/view", false},
+		{http.MethodPost, "/api/logs/client", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if got := isMaintenanceBlocked(req); got != tt.want {
+				t.Errorf("isMaintenanceBlocked() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -82,6 +82,11 @@ func (rw *responseWriter) Write(b []byte) (int, error) {
 	return n, err
 }
 
+// Unwrap exposes the underlying writer so http.ResponseController can flush streams.
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // LoggingMiddleware logs requests with timing and status.
 // It logs security-relevant events without logging sensitive data.
 func LoggingMiddleware(log *logger.Logger) func(http.Handler) http.Handler {
@@ -97,6 +102,7 @@ func LoggingMiddleware(log *logger.Logger) func(http.Handler) http.Handler {
 				slog.String("path", r.URL.Path),
 				slog.String("query", r.URL.RawQuery),
 				slog.String("remote_addr", r.RemoteAddr),
+				slog.String("client_ip", GetClientIP(r.Context())),
 				slog.String("user_agent", r.UserAgent()),
 				slog.Int64("content_length", r.ContentLength),
 			)
@@ -144,6 +150,11 @@ func RecoveryMiddleware(log *logger.Logger) func(http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			defer func() {
 				if err := recover(); err != nil {
+					// Handlers abort a partly written response this way; net/http closes the connection
+					if err == http.ErrAbortHandler {
+						panic(err)
+					}
+
 					requestID := GetRequestID(r.Context())
 
 					// Log the panic with stack trace
@@ -167,6 +178,22 @@ func RecoveryMiddleware(log *logger.Logger) func(http.Handler) http.Handler {
 	}
 }
 
+// MaxBodySizeMiddleware limits request bodies to limitBytes.
+// Requests declaring a larger Content-Length are rejected with 413 before the handler runs;
+// other bodies are wrapped so reads past the limit fail with *http.MaxBytesError.
+func MaxBodySizeMiddleware(limitBytes int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > limitBytes {
+				WritePayloadTooLarge(w, r, limitBytes)
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, limitBytes)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // Chain applies middleware in order (first middleware wraps outermost).
 // Usage: Chain(handler, middleware1, middleware2, middleware3)
 // Results in: middleware1(middleware2(middleware3(handler)))
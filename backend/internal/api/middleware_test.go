@@ -0,0 +1,79 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"better-kiro-prompts/internal/generation"
+	"better-kiro-prompts/internal/ratelimit"
+)
+
+func TestMaxBodySizeMiddleware_OversizedBody(t *testing.T) {
+	const limit = 256
+	router := NewRouter(&RouterConfig{
+		GenerationService: generation.NewService(nil),
+		RateLimiter:       ratelimit.NewLimiterWithConfig(10, ratelimit.DefaultWindow),
+		MaxBodyBytes:      limit,
+	})
+	body := `{"projectIdea":"` + strings.Repeat("a", 2*limit) + `","experienceLevel":"beginner"}`
+
+	tests := []struct {
+		name string
+		body io.Reader
+	}{
+		// strings.Reader lets httptest set Content-Length, so the middleware rejects up front
+		{name: "declared length", body: strings.NewReader(body)},
+		// An unknown length is only caught once the handler reads past the limit
+		{name: "unknown length", body: io.MultiReader(strings.NewReader(body))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/generate/questions", tt.body)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusRequestEntityTooLarge {
+				t.Fatalf("status = %d, want 413; body: %s", rec.Code, rec.Body.String())
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", ct)
+			}
+
+			var resp ErrorResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode error response: %v", err)
+			}
+			if resp.Code != ErrCodeTooLarge {
+				t.Errorf("code = %q, want %q", resp.Code, ErrCodeTooLarge)
+			}
+			if !strings.Contains(resp.Error, "256 bytes") {
+				t.Errorf("error = %q, want it to mention the limit", resp.Error)
+			}
+			if resp.RequestID == "" {
+				t.Error("expected a request ID")
+			}
+		})
+	}
+}
+
+func TestMaxBodySizeMiddleware_AllowsSmallBody(t *testing.T) {
+	router := NewRouter(&RouterConfig{
+		GenerationService: generation.NewService(nil),
+		RateLimiter:       ratelimit.NewLimiterWithConfig(10, ratelimit.DefaultWindow),
+		MaxBodyBytes:      1 << 10,
+	})
+	body := `{"projectIdea":"A recipe organizer","experienceLevel":"beginner"}`
+
+	req := httptest.NewRequest(http.MethodPost, "/api/generate/questions?dry_run=true", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200; body: %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"better-kiro-prompts/internal/gallery"
+	"better-kiro-prompts/internal/generation"
+	"better-kiro-prompts/internal/pagination"
+	"better-kiro-prompts/internal/privacy"
+	"better-kiro-prompts/internal/ratelimit"
+	"better-kiro-prompts/internal/storage"
+)
+
+// TestListEndpoints_SharePageBounds tests that every list endpoint defaults to
+// the shared page size and caps requests at the shared maximum.
+func TestListEndpoints_SharePageBounds(t *testing.T) {
+	const clientIP = "192.0.2.1"
+	ctx := context.Background()
+	repo := storage.NewMemoryRepository()
+	for i := 0; i < pagination.MaxPageSize+20; i++ {
+		gen := &storage.Generation{ProjectIdea: fmt.Sprintf("Idea %d", i), Files: json.RawMessage(`[]`), CategoryID: 1}
+		if err := repo.CreateGeneration(ctx, gen); err != nil {
+			t.Fatalf("CreateGeneration() error = %v", err)
+		}
+		if err := repo.RecordCreator(ctx, gen.ID, privacy.HashIP(clientIP)); err != nil {
+			t.Fatalf("RecordCreator() error = %v", err)
+		}
+	}
+
+	genSvc := generation.NewService(nil)
+	genSvc.SetRepository(repo)
+	router := NewRouter(&RouterConfig{
+		GenerationService: genSvc,
+		RateLimiter:       ratelimit.NewLimiter(),
+		GalleryService:    gallery.NewService(repo, nil, nil),
+	})
+
+	count := func(t *testing.T, target string) int {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.RemoteAddr = clientIP + ":40000"
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s status = %d, want 200; body: %s", target, rec.Code, rec.Body.String())
+		}
+		var resp struct {
+			Items []json.RawMessage `json:"items"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return len(resp.Items)
+	}
+
+	for _, path := range []string{"/api/gallery", "/api/generate/history"} {
+		t.Run(path, func(t *testing.T) {
+			if got := count(t, path); got != pagination.DefaultPageSize {
+				t.Errorf("default page has %d items, want %d", got, pagination.DefaultPageSize)
+			}
+			if got := count(t, path+"?pageSize=1000"); got != pagination.MaxPageSize {
+				t.Errorf("pageSize=1000 returned %d items, want the %d cap", got, pagination.MaxPageSize)
+			}
+			if got := count(t, path+"?pageSize=7"); got != 7 {
+				t.Errorf("pageSize=7 returned %d items", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"net/http"
+
+	"better-kiro-prompts/internal/ratelimit"
+)
+
+// RateLimitAllowance is the caller's standing against one rate limit.
+type RateLimitAllowance struct {
+	Limit     int    `json:"limit"`
+	Remaining int    `json:"remaining"`
+	ResetAt   string `json:"resetAt,omitempty"` // When the full allowance returns; omitted while none is used
+}
+
+// RateLimitStatusResponse is the response for GET /api/ratelimit/status.
+// Limits the server does not enforce are omitted.
+type RateLimitStatusResponse struct {
+	Generation *RateLimitAllowance `json:"generation,omitempty"`
+	Scan       *RateLimitAllowance `json:"scan,omitempty"`
+	Rating     *RateLimitAllowance `json:"rating,omitempty"`
+	Exempt     bool                `json:"exempt"` // Requests with the internal API key are not counted
+}
+
+// HandleRateLimitStatus handles GET /api/ratelimit/status - Report the caller's remaining
+// generation, scan and rating allowances, so clients need not trigger a 429 to find them.
+// Checking the status does not count against any limit.
+func HandleRateLimitStatus(generation, scan, rating *ratelimit.Limiter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ip := getClientIP(r)
+		resp := RateLimitStatusResponse{
+			Generation: rateLimitAllowance(generation, ip),
+			Scan:       rateLimitAllowance(scan, ip),
+			Rating:     rateLimitAllowance(rating, ip),
+			Exempt:     ratelimit.IsExempt(r.Context()),
+		}
+
+		// The counts change with every request, so they must not be cached
+		w.Header().Set("Cache-Control", "no-store")
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+// rateLimitAllowance returns the standing of ip against limiter, or nil
+// when the limit is not enforced.
+func rateLimitAllowance(limiter *ratelimit.Limiter, ip string) *RateLimitAllowance {
+	if limiter == nil {
+		return nil
+	}
+	allowance := &RateLimitAllowance{
+		Limit:     limiter.Limit(),
+		Remaining: limiter.Remaining(ip),
+	}
+	if resetAt := limiter.ResetAt(ip); !resetAt.IsZero() {
+		allowance.ResetAt = formatTimestamp(resetAt)
+	}
+	return allowance
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"better-kiro-prompts/internal/generation"
+	"better-kiro-prompts/internal/ratelimit"
+	"better-kiro-prompts/internal/scanner"
+)
+
+func getRateLimitStatus(t *testing.T, router http.Handler, remoteAddr string) RateLimitStatusResponse {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/api/ratelimit/status", nil)
+	req.RemoteAddr = remoteAddr
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /api/ratelimit/status status = %d, want 200", rec.Code)
+	}
+	if got := rec.Header().Get("Cache-Control"); got != "no-store" {
+		t.Errorf("Cache-Control = %q, want no-store", got)
+	}
+	var resp RateLimitStatusResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	return resp
+}
+
+func TestRateLimitStatus_ReportsRemaining(t *testing.T) {
+	genLimiter := ratelimit.NewLimiterWithConfig(10, time.Hour)
+	scanLimiter := ratelimit.NewLimiterWithConfig(5, time.Hour)
+	ratingLimiter := ratelimit.NewLimiterWithConfig(20, time.Hour)
+	router := NewRouter(&RouterConfig{
+		GenerationService: generation.NewService(nil),
+		RateLimiter:       genLimiter,
+		ScannerService:    scanner.NewService(nil, nil, ""),
+		ScanRateLimiter:   scanLimiter,
+		RatingLimiter:     ratingLimiter,
+	})
+	const addr = "203.0.113.9:4321"
+
+	before := getRateLimitStatus(t, router, addr)
+	if before.Scan == nil || before.Scan.Remaining != 5 || before.Scan.Limit != 5 || before.Scan.ResetAt != "" {
+		t.Fatalf("initial scan allowance = %+v, want 5 of 5 with no reset time", before.Scan)
+	}
+
+	// A rejected batch costs nothing; a two-repository batch counts as two scans
+	req := httptest.NewRequest(http.MethodPost, "/api/scan/batch",
+		strings.NewReader(`{"repo_urls":["https://github.com/a/b","not a url"]}`))
+	req.RemoteAddr = addr
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	scanLimiter.AllowN("203.0.113.9", 2)
+	genLimiter.Allow("203.0.113.9")
+
+	after := getRateLimitStatus(t, router, addr)
+	if after.Scan.Remaining != 3 {
+		t.Errorf("scan remaining = %d, want 3", after.Scan.Remaining)
+	}
+	if after.Generation == nil || after.Generation.Remaining != 9 {
+		t.Errorf("generation allowance = %+v, want 9 remaining", after.Generation)
+	}
+	if after.Rating == nil || after.Rating.Remaining != 20 || after.Rating.ResetAt != "" {
+		t.Errorf("rating allowance = %+v, want untouched", after.Rating)
+	}
+	resetAt, err := time.Parse(time.RFC3339, after.Scan.ResetAt)
+	if err != nil || resetAt.Before(time.Now()) || resetAt.After(time.Now().Add(time.Hour)) {
+		t.Errorf("scan resetAt = %q, want a time within the next hour", after.Scan.ResetAt)
+	}
+	if after.Exempt {
+		t.Error("expected a request without the internal key not to be exempt")
+	}
+
+	// Other callers keep their own allowance
+	if other := getRateLimitStatus(t, router, "198.51.100.1:80"); other.Scan.Remaining != 5 {
+		t.Errorf("other IP scan remaining = %d, want 5", other.Scan.Remaining)
+	}
+}
@@ -1,14 +1,19 @@
 package api
 
 import (
+	"log/slog"
 	"net/http"
+	"net/netip"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"better-kiro-prompts/internal/download"
 	"better-kiro-prompts/internal/gallery"
 	"better-kiro-prompts/internal/generation"
 	"better-kiro-prompts/internal/logger"
+	"better-kiro-prompts/internal/pow"
 	"better-kiro-prompts/internal/ratelimit"
 	"better-kiro-prompts/internal/scanner"
 )
@@ -22,6 +27,15 @@ type RouterConfig struct {
 	ScannerService    *scanner.Service
 	ScanRateLimiter   *ratelimit.Limiter
 	Logger            *logger.Logger
+	MaxBodyBytes      int64            // Request body limit; 0 disables the limit
+	MaxImportBytes    int64            // Body limit for gallery imports; 0 disables it there
+	TrustedProxies    []netip.Prefix   // Peers whose forwarding headers are honored
+	Maintenance       *MaintenanceMode // Read-only toggle; nil never blocks writes
+	InternalAPIKey    string           // Exempts internal tooling from rate limits; empty disables
+	ProofOfWork       *pow.Issuer      // Challenge issuer for generations; nil disables
+	QueryStats        QueryStatsSource // Database query metrics; nil disables /api/admin/db-stats
+	DownloadSigner    *download.Signer // Signs ZIP download links; nil disables them
+	DownloadLinkTTL   time.Duration    // How long a signed download link works
 }
 
 // NewRouter creates a new HTTP router with all API routes.
@@ -34,24 +48,71 @@ func NewRouter(cfg *RouterConfig) http.Handler {
 	// Generation endpoints (if service is configured)
 	if cfg != nil && cfg.GenerationService != nil && cfg.RateLimiter != nil {
 		genHandler := NewGenerateHandler(cfg.GenerationService, cfg.RateLimiter)
+		genHandler.SetProofOfWork(cfg.ProofOfWork)
+		mux.HandleFunc("GET /api/generate/challenge", genHandler.HandleGetChallenge)
+		mux.HandleFunc("GET /api/generate/options", genHandler.HandleGetOptions)
 		mux.HandleFunc("POST /api/generate/questions", genHandler.HandleGenerateQuestions)
 		mux.HandleFunc("POST /api/generate/outputs", genHandler.HandleGenerateOutputs)
+		mux.HandleFunc("GET /api/generate/history", genHandler.HandleGenerationHistory)
+		mux.HandleFunc("POST /api/generate/kickoff", genHandler.HandleGenerateKickoff)
+		mux.HandleFunc("POST /api/generate/assess", genHandler.HandleAssessAnswers)
+		mux.HandleFunc("POST /api/generate/examples", genHandler.HandleGenerateExamples)
+
+		// Replays call the model without a rate limit, so only internal tooling may use them
+		if cfg.InternalAPIKey != "" {
+			mux.HandleFunc("POST /api/admin/generate/{id}/replay", genHandler.HandleReplayGeneration)
+		}
 	}
 
 	// Gallery endpoints (if service is configured)
 	if cfg != nil && cfg.GalleryService != nil {
 		galleryHandler := NewGalleryHandler(cfg.GalleryService, cfg.RatingLimiter)
 		mux.HandleFunc("GET /api/gallery", galleryHandler.HandleListGallery)
+		mux.HandleFunc("GET /api/gallery/categories", galleryHandler.HandleListCategories)
+		mux.HandleFunc("GET /api/gallery/s/{code}", galleryHandler.HandleResolveShortCode)
 		mux.HandleFunc("GET /api/gallery/{id}", galleryHandler.HandleGetGalleryItem)
+		mux.HandleFunc("POST /api/gallery/{id}/view", galleryHandler.HandleRecordView)
 		mux.HandleFunc("POST /api/gallery/{id}/rate", galleryHandler.HandleRateGalleryItem)
+
+		if cfg.DownloadSigner != nil {
+			galleryHandler.SetDownloadLinks(cfg.DownloadSigner, cfg.DownloadLinkTTL)
+			mux.HandleFunc("POST /api/gallery/{id}/download-link", galleryHandler.HandleCreateDownloadLink)
+			mux.HandleFunc("GET /api/download/{id}", galleryHandler.HandleDownloadZip)
+		}
+
+		// Backups and the bulk stream need the internal key to authenticate the caller
+		if cfg.InternalAPIKey != "" {
+			mux.HandleFunc("GET /api/admin/gallery/export", galleryHandler.HandleExportGallery)
+			mux.HandleFunc("POST "+galleryImportPath, galleryHandler.HandleImportGallery)
+			mux.HandleFunc("GET /api/gallery/export.ndjson", galleryHandler.HandleStreamGallery)
+		}
 	}
 
 	// Scanner endpoints (if service is configured)
 	if cfg != nil && cfg.ScannerService != nil && cfg.ScanRateLimiter != nil {
 		scanHandler := NewScanHandler(cfg.ScannerService, cfg.ScanRateLimiter)
 		mux.HandleFunc("POST /api/scan", scanHandler.HandleStartScan)
+		mux.HandleFunc("POST /api/scan/plan", scanHandler.HandlePlanScan)
+		mux.HandleFunc("POST /api/scan/batch", scanHandler.HandleStartBatch)
+		mux.HandleFunc("GET /api/scan/batch/{id}", scanHandler.HandleGetBatch)
 		mux.HandleFunc("GET /api/scan/config", scanHandler.HandleGetScanConfig)
+		mux.HandleFunc("GET /api/scan/tools", scanHandler.HandleGetScanTools)
+
+		// Acknowledgements hide findings from every user's scans of a repository
+		if cfg.InternalAPIKey != "" {
+			mux.HandleFunc("POST /api/scan/ack", scanHandler.HandleAcknowledgeFinding)
+		}
 		mux.HandleFunc("GET /api/scan/{id}", scanHandler.HandleGetScan)
+		// Not "GET /api/scan/{id}/events", which conflicts with the batch route
+		mux.HandleFunc("GET /api/scan/{id}/{resource}", scanHandler.HandleScanResource)
+		mux.HandleFunc("GET /api/scan/{id}/findings/{fingerprint}", scanHandler.HandleGetFinding)
+		mux.HandleFunc("POST /api/scan/{id}/findings/{fid}/remediate", scanHandler.HandleRemediateFinding)
+		mux.HandleFunc("POST /api/scan/{id}/findings/{fid}/explain", scanHandler.HandleExplainFinding)
+	}
+
+	// Rate limit status for the caller, covering whichever limiters are configured
+	if cfg != nil && (cfg.RateLimiter != nil || cfg.ScanRateLimiter != nil || cfg.RatingLimiter != nil) {
+		mux.HandleFunc("GET /api/ratelimit/status", HandleRateLimitStatus(cfg.RateLimiter, cfg.ScanRateLimiter, cfg.RatingLimiter))
 	}
 
 	// Client logging endpoint (no rate limiting - logs are important)
@@ -63,26 +124,58 @@ func NewRouter(cfg *RouterConfig) http.Handler {
 		mux.HandleFunc("POST /api/admin/log-level", HandleSetLogLevel(cfg.Logger))
 	}
 
+	if cfg != nil && cfg.QueryStats != nil {
+		mux.HandleFunc("GET /api/admin/db-stats", HandleGetQueryStats(cfg.QueryStats))
+	}
+
 	// Serve static files from ./static directory (SPA with fallback to index.html)
 	staticDir := "./static"
 	if _, err := os.Stat(staticDir); err == nil {
 		mux.HandleFunc("/", spaHandler(staticDir))
 	}
 
-	// Apply middleware chain: Recovery -> RequestID -> Logging
+	// Body size limit runs innermost so rejections still get a request ID and are logged
+	var handler http.Handler = mux
+	if cfg != nil {
+		handler = bodyLimitHandler(mux, cfg.MaxBodyBytes, cfg.MaxImportBytes)
+	}
+
+	// Internal key exemption must run before any handler checks a rate limit
+	if cfg != nil && cfg.InternalAPIKey != "" {
+		var httpLog *slog.Logger
+		if cfg.Logger != nil {
+			httpLog = cfg.Logger.HTTP()
+		}
+		handler = InternalKeyMiddleware(cfg.InternalAPIKey, httpLog)(handler)
+	}
+
+	// Maintenance runs before body limits so blocked writes are rejected without reading the body
+	if cfg != nil && cfg.Maintenance != nil {
+		handler = MaintenanceMiddleware(cfg.Maintenance)(handler)
+	}
+
+	var trustedProxies []netip.Prefix
+	if cfg != nil {
+		trustedProxies = cfg.TrustedProxies
+	}
+	clientIP := ClientIPMiddleware(NewClientIPResolver(trustedProxies))
+
+	// Apply middleware chain: Recovery -> RequestID -> ClientIP -> Logging
 	// Order matters: Recovery is outermost to catch panics from all handlers
 	// Logger is required for Recovery and Logging middleware
 	if cfg != nil && cfg.Logger != nil {
-		return Chain(mux,
+		return Chain(handler,
 			RecoveryMiddleware(cfg.Logger),
 			RequestIDMiddleware,
+			clientIP,
 			LoggingMiddleware(cfg.Logger),
 		)
 	}
 
 	// Fallback without logging (for testing or when logger is not configured)
-	return Chain(mux,
+	return Chain(handler,
 		RequestIDMiddleware,
+		clientIP,
 	)
 }
 
@@ -122,3 +215,25 @@ func spaHandler(staticDir string) http.HandlerFunc {
 		http.ServeFile(w, r, path)
 	}
 }
+
+// galleryImportPath carries a whole gallery export, so it has its own body limit.
+const galleryImportPath = "/api/admin/gallery/import"
+
+// bodyLimitHandler applies maxBody to every request except gallery imports,
+// which get maxImport instead. A zero limit leaves those requests unbounded.
+func bodyLimitHandler(next http.Handler, maxBody, maxImport int64) http.Handler {
+	limited, imports := next, next
+	if maxBody > 0 {
+		limited = MaxBodySizeMiddleware(maxBody)(next)
+	}
+	if maxImport > 0 {
+		imports = MaxBodySizeMiddleware(maxImport)(next)
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == galleryImportPath {
+			imports.ServeHTTP(w, r)
+			return
+		}
+		limited.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"better-kiro-prompts/internal/gallery"
+	"better-kiro-prompts/internal/privacy"
+	"better-kiro-prompts/internal/storage"
+)
+
+func TestSaltRotation_OldSaltViewsAndRatingsStayDeduplicated(t *testing.T) {
+	t.Cleanup(func() {
+		privacy.SetIPSalt("")
+		privacy.SetPreviousIPSalt("", time.Time{})
+	})
+
+	repo := storage.NewMemoryRepository()
+	gen := &storage.Generation{ProjectIdea: "A todo app", Files: json.RawMessage(`[]`), CategoryID: 1}
+	if err := repo.CreateGeneration(context.Background(), gen); err != nil {
+		t.Fatalf("CreateGeneration() error = %v", err)
+	}
+	router := NewRouter(&RouterConfig{GalleryService: gallery.NewService(repo, nil, nil)})
+
+	view := func() bool {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/gallery/"+gen.ID+"/view", nil))
+		var resp ViewResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("decode view response (status %d): %v", rec.Code, err)
+		}
+		return resp.Counted
+	}
+	rate := func(score string) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/gallery/"+gen.ID+"/rate", strings.NewReader(`{"score":`+score+`}`)))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("rate status = %d, body: %s", rec.Code, rec.Body.String())
+		}
+	}
+
+	privacy.SetIPSalt("old-salt")
+	if !view() {
+		t.Fatal("first view was not counted")
+	}
+	rate("2")
+
+	// Rotate, keeping the old salt for a grace period
+	privacy.SetIPSalt("new-salt")
+	privacy.SetPreviousIPSalt("old-salt", time.Now().Add(time.Hour))
+	if view() {
+		t.Error("view from an IP hashed under the old salt counted again during the grace period")
+	}
+	rate("4")
+	stored, err := repo.GetGeneration(context.Background(), gen.ID)
+	if err != nil {
+		t.Fatalf("GetGeneration() error = %v", err)
+	}
+	if stored.RatingCount != 1 || stored.AvgRating != 4 {
+		t.Errorf("after re-rating: %d ratings averaging %v, want the old rating updated to 4", stored.RatingCount, stored.AvgRating)
+	}
+
+	// Once the grace period is over, old-salt records are no longer consulted
+	privacy.SetPreviousIPSalt("old-salt", time.Now().Add(-time.Minute))
+	if !view() {
+		t.Error("view after the grace period was not counted under the new salt")
+	}
+}
@@ -1,10 +1,15 @@
 package api
 
 import (
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
+	"better-kiro-prompts/internal/privacy"
 	"better-kiro-prompts/internal/ratelimit"
 	"better-kiro-prompts/internal/scanner"
 )
@@ -12,6 +17,12 @@ import (
 // ScanRequest is the request body for starting a scan.
 type ScanRequest struct {
 	RepoURL string `json:"repo_url"`
+	Subdir  string `json:"subdir,omitempty"`
+}
+
+// BatchScanRequest is the request body for starting a batch of scans.
+type BatchScanRequest struct {
+	RepoURLs []string `json:"repo_urls"`
 }
 
 // ScanConfigResponse is the response for scan configuration.
@@ -21,6 +32,24 @@ type ScanConfigResponse struct {
 	MaxFilesToReview   int  `json:"max_files_to_review,omitempty"`
 }
 
+// RemediateFindingResponse is the response for on-demand finding remediation.
+type RemediateFindingResponse struct {
+	Finding scanner.Finding `json:"finding"`
+}
+
+// FindingResponse is the response for a finding looked up by fingerprint.
+type FindingResponse struct {
+	Finding scanner.Finding `json:"finding"`
+}
+
+// ScanToolsResponse is the response for tool availability.
+type ScanToolsResponse struct {
+	Tools []scanner.ToolAvailability `json:"tools"`
+}
+
+// scanEventsHeartbeat is how often an idle event stream sends a keep-alive comment.
+const scanEventsHeartbeat = 15 * time.Second
+
 // ScanHandler holds dependencies for scan endpoints.
 type ScanHandler struct {
 	service     *scanner.Service
@@ -38,30 +67,32 @@ func NewScanHandler(service *scanner.Service, limiter *ratelimit.Limiter) *ScanH
 // HandleStartScan handles POST /api/scan - Start a new security scan.
 func (h *ScanHandler) HandleStartScan(w http.ResponseWriter, r *http.Request) {
 	// Check rate limit
-	ip := getClientIP(r)
-	allowed, retryAfter := h.rateLimiter.Allow(ip)
-	if !allowed {
-		WriteRateLimited(w, r, int(retryAfter.Seconds()))
+	if !checkRateLimit(w, r, h.rateLimiter) {
 		return
 	}
 
 	// Parse request body
 	var req ScanRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		WriteBadRequest(w, r, "Invalid request body")
+		writeDecodeError(w, r, err)
 		return
 	}
 
-	// Validate URL format first
-	if validationErr := scanner.ValidateGitHubURL(req.RepoURL); validationErr != nil {
+	// Validate URL format first, in the canonical form the scanner uses
+	if validationErr := scanner.ValidateGitHubURL(scanner.NormalizeGitHubURL(req.RepoURL)); validationErr != nil {
 		WriteValidationError(w, r, validationErr.Message)
 		return
 	}
 
-	// Start the scan
-	job, err := h.service.StartScan(r.Context(), scanner.ScanRequest{
+	// Start the scan; internal tooling is not held to the per-client scan limit
+	scanReq := scanner.ScanRequest{
 		RepoURL: req.RepoURL,
-	})
+		Subdir:  req.Subdir,
+	}
+	if !ratelimit.IsExempt(r.Context()) {
+		scanReq.ClientID = privacy.HashIP(getClientIP(r))
+	}
+	job, err := h.service.StartScan(r.Context(), scanReq)
 	if err != nil {
 		handleScanError(w, r, err)
 		return
@@ -70,7 +101,99 @@ func (h *ScanHandler) HandleStartScan(w http.ResponseWriter, r *http.Request) {
 	// Return 202 Accepted with job info
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusAccepted)
-	_ = json.NewEncoder(w).Encode(job)
+	_ = json.NewEncoder(w).Encode(newScanJobResponse(r, job))
+}
+
+// HandleStartBatch handles POST /api/scan/batch - Start scans of several repositories.
+// Each repository counts as one scan against the rate limit.
+func (h *ScanHandler) HandleStartBatch(w http.ResponseWriter, r *http.Request) {
+	var req BatchScanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeDecodeError(w, r, err)
+		return
+	}
+
+	// Validate first so a rejected batch is not charged, then charge one scan
+	// per distinct repository
+	repoURLs, verr := h.service.ValidateBatch(req.RepoURLs)
+	if verr != nil {
+		WriteValidationError(w, r, verr.Message)
+		return
+	}
+	// A batch larger than the hourly allowance could never be admitted
+	if limit := h.rateLimiter.Limit(); !ratelimit.IsExempt(r.Context()) && len(repoURLs) > limit {
+		WriteValidationError(w, r, fmt.Sprintf("repo_urls may list at most %d distinct repositories, the hourly scan limit", limit))
+		return
+	}
+	if !checkRateLimitN(w, r, h.rateLimiter, len(repoURLs)) {
+		return
+	}
+
+	// Internal tooling is not held to the per-client scan limit
+	batchReq := scanner.BatchRequest{RepoURLs: req.RepoURLs}
+	if !ratelimit.IsExempt(r.Context()) {
+		batchReq.ClientID = privacy.HashIP(getClientIP(r))
+	}
+	batch, err := h.service.StartBatch(r.Context(), batchReq)
+	if err != nil {
+		handleScanError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, newScanBatchResponse(batch))
+}
+
+// HandleGetBatch handles GET /api/scan/batch/{id} - Get the aggregated status of a batch.
+func (h *ScanHandler) HandleGetBatch(w http.ResponseWriter, r *http.Request) {
+	batchID := r.PathValue("id")
+	if batchID == "" {
+		WriteBadRequest(w, r, "Scan batch ID is required")
+		return
+	}
+
+	batch, err := h.service.GetBatch(r.Context(), batchID)
+	if err != nil {
+		WriteServiceError(w, r, err, "Failed to retrieve scan batch")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newScanBatchResponse(batch))
+}
+
+// HandlePlanScan handles POST /api/scan/plan - Report the languages and tools a scan would use.
+// The repository is cloned to detect languages, but no tools run and nothing is stored.
+func (h *ScanHandler) HandlePlanScan(w http.ResponseWriter, r *http.Request) {
+	// Planning clones the repository, so it shares the scan rate limit
+	if !checkRateLimit(w, r, h.rateLimiter) {
+		return
+	}
+
+	var req ScanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeDecodeError(w, r, err)
+		return
+	}
+
+	// Planning clones like a scan, so it is held to the same per-client limit
+	planReq := scanner.ScanRequest{
+		RepoURL: req.RepoURL,
+		Subdir:  req.Subdir,
+	}
+	if !ratelimit.IsExempt(r.Context()) {
+		planReq.ClientID = privacy.HashIP(getClientIP(r))
+	}
+	plan, err := h.service.PlanScan(r.Context(), planReq)
+	if err != nil {
+		var validationErr *scanner.ValidationError
+		if errors.As(err, &validationErr) {
+			WriteValidationError(w, r, validationErr.Message)
+			return
+		}
+		WriteServiceError(w, r, err, "Failed to plan scan. Please try again later.")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, plan)
 }
 
 // HandleGetScan handles GET /api/scan/{id} - Get scan status and results.
@@ -82,21 +205,249 @@ func (h *ScanHandler) HandleGetScan(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Parse optional finding filters
+	query := r.URL.Query()
+	minSeverity := query.Get("min_severity")
+	if minSeverity != "" && !scanner.IsValidSeverity(minSeverity) {
+		WriteValidationError(w, r, "Invalid min_severity. Must be one of: critical, high, medium, low, info")
+		return
+	}
+	minConfidence := query.Get("min_confidence")
+	if minConfidence != "" && !scanner.IsValidConfidence(minConfidence) {
+		WriteValidationError(w, r, "Invalid min_confidence. Must be one of: high, medium, low")
+		return
+	}
+
 	// Get the job
 	job, err := h.service.GetJob(r.Context(), jobID)
 	if err != nil {
-		if errors.Is(err, scanner.ErrJobNotFound) {
-			WriteNotFound(w, r, "Scan job not found")
-			return
-		}
-		WriteInternalError(w, r, "Failed to retrieve scan job")
+		WriteServiceError(w, r, err, "Failed to retrieve scan job")
 		return
 	}
 
+	job.Findings = filterFindings(job.Findings, minSeverity, minConfidence)
+
 	// Return job info
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	_ = json.NewEncoder(w).Encode(job)
+	_ = json.NewEncoder(w).Encode(newScanJobResponse(r, job))
+}
+
+// HandleAcknowledgeFinding handles POST /api/scan/ack - Accept a rule's findings in one
+// file of a repository. Matching findings of every scan of the repository are listed
+// separately and left out of the score. Since that affects every user, it requires
+// the internal API key.
+func (h *ScanHandler) HandleAcknowledgeFinding(w http.ResponseWriter, r *http.Request) {
+	if !requireInternalKey(w, r) {
+		return
+	}
+
+	var req scanner.AckRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeDecodeError(w, r, err)
+		return
+	}
+
+	ack, err := h.service.Acknowledge(r.Context(), req)
+	if err != nil {
+		var validationErr *scanner.ValidationError
+		if errors.As(err, &validationErr) {
+			WriteValidationError(w, r, validationErr.Message)
+			return
+		}
+		WriteServiceError(w, r, err, "Failed to acknowledge finding. Please try again later.")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, AcknowledgementResponse{
+		Acknowledgement: ack,
+		CreatedAt:       formatTimestamp(ack.CreatedAt),
+	})
+}
+
+// HandleScanResource handles GET /api/scan/{id}/{resource}. Only the events
+// resource exists; the wildcard keeps the route from conflicting with
+// GET /api/scan/batch/{id}.
+func (h *ScanHandler) HandleScanResource(w http.ResponseWriter, r *http.Request) {
+	if r.PathValue("resource") != "events" {
+		WriteNotFound(w, r, "Not found")
+		return
+	}
+	h.HandleScanEvents(w, r)
+}
+
+// HandleScanEvents handles GET /api/scan/{id}/events - Stream scan progress as server-sent events.
+// The stream opens with a "status" event carrying the job's current status, then
+// relays pipeline events until the scan completes or fails.
+func (h *ScanHandler) HandleScanEvents(w http.ResponseWriter, r *http.Request) {
+	jobID := r.PathValue("id")
+	if jobID == "" {
+		WriteBadRequest(w, r, "Scan job ID is required")
+		return
+	}
+
+	// Subscribe before loading the job so no event falls between the two
+	events, unsubscribe, running := h.service.Subscribe(jobID)
+	defer unsubscribe()
+
+	job, err := h.service.GetJob(r.Context(), jobID)
+	if err != nil {
+		WriteServiceError(w, r, err, "Failed to retrieve scan job")
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // Disable proxy buffering
+	w.WriteHeader(http.StatusOK)
+
+	if writeScanEvent(w, scanner.ScanEvent{Type: scanner.EventStatus, JobID: job.ID, Message: job.Status, Time: time.Now()}) != nil || rc.Flush() != nil {
+		return
+	}
+	if !running {
+		return
+	}
+
+	heartbeat := time.NewTicker(scanEventsHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if writeScanEvent(w, event) != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+		if rc.Flush() != nil {
+			return
+		}
+	}
+}
+
+// writeScanEvent writes a single server-sent event named after the event type.
+func writeScanEvent(w http.ResponseWriter, event scanner.ScanEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+	return err
+}
+
+// HandleRemediateFinding handles POST /api/scan/{id}/findings/{fid}/remediate -
+// Generate AI remediation for one finding of a completed scan.
+func (h *ScanHandler) HandleRemediateFinding(w http.ResponseWriter, r *http.Request) {
+	// Each request clones the repository and calls the model, so it shares the scan limit
+	if !checkRateLimit(w, r, h.rateLimiter) {
+		return
+	}
+
+	jobID := r.PathValue("id")
+	findingID := r.PathValue("fid")
+	if jobID == "" || findingID == "" {
+		WriteBadRequest(w, r, "Scan job ID and finding ID are required")
+		return
+	}
+
+	finding, err := h.service.RemediateFinding(r.Context(), jobID, findingID)
+	if err != nil {
+		WriteServiceError(w, r, err, "Failed to generate remediation. Please try again later.")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, RemediateFindingResponse{Finding: *finding})
+}
+
+// HandleGetFinding handles GET /api/scan/{id}/findings/{fingerprint} - Get one
+// finding of a scan by its fingerprint, which stays the same across rescans.
+func (h *ScanHandler) HandleGetFinding(w http.ResponseWriter, r *http.Request) {
+	jobID := r.PathValue("id")
+	fingerprint := strings.ToLower(r.PathValue("fingerprint"))
+	if jobID == "" {
+		WriteBadRequest(w, r, "Scan job ID is required")
+		return
+	}
+	if !isFingerprint(fingerprint) {
+		WriteValidationError(w, r, fmt.Sprintf("Invalid finding fingerprint. Must be %d hex characters", scanner.FingerprintLength))
+		return
+	}
+
+	finding, err := h.service.GetFindingByFingerprint(r.Context(), jobID, fingerprint)
+	if err != nil {
+		WriteServiceError(w, r, err, "Failed to retrieve finding")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, FindingResponse{Finding: *finding})
+}
+
+// isFingerprint reports whether s has the form of a finding fingerprint.
+func isFingerprint(s string) bool {
+	if len(s) != scanner.FingerprintLength {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+// HandleExplainFinding handles POST /api/scan/{id}/findings/{fid}/explain -
+// Explain a finding's rule in plain language without re-scanning.
+func (h *ScanHandler) HandleExplainFinding(w http.ResponseWriter, r *http.Request) {
+	// Uncached explanations call the model, so it shares the scan limit
+	if !checkRateLimit(w, r, h.rateLimiter) {
+		return
+	}
+
+	jobID := r.PathValue("id")
+	findingID := r.PathValue("fid")
+	if jobID == "" || findingID == "" {
+		WriteBadRequest(w, r, "Scan job ID and finding ID are required")
+		return
+	}
+
+	explanation, err := h.service.ExplainFinding(r.Context(), jobID, findingID)
+	if err != nil {
+		WriteServiceError(w, r, err, "Failed to explain finding. Please try again later.")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, explanation)
+}
+
+// filterFindings applies the optional severity and confidence thresholds.
+// An empty threshold leaves findings unfiltered on that dimension.
+func filterFindings(findings []scanner.Finding, minSeverity, minConfidence string) []scanner.Finding {
+	if minSeverity == "" && minConfidence == "" {
+		return findings
+	}
+
+	agg := scanner.NewAggregator()
+	if minSeverity != "" {
+		findings = agg.FilterBySeverity(findings, minSeverity)
+	}
+	if minConfidence != "" {
+		findings = agg.FilterByConfidence(findings, minConfidence)
+	}
+	if findings == nil {
+		findings = []scanner.Finding{}
+	}
+	return findings
+}
+
+// HandleGetScanTools handles GET /api/scan/tools - Report which scanning tools are installed.
+func (h *ScanHandler) HandleGetScanTools(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, ScanToolsResponse{Tools: h.service.CheckTools(r.Context())})
 }
 
 // HandleGetScanConfig handles GET /api/scan/config - Get scan configuration.
@@ -129,17 +480,5 @@ func handleScanError(w http.ResponseWriter, r *http.Request, err error) {
 		return
 	}
 
-	// Check for specific error types
-	if errors.Is(err, scanner.ErrJobNotFound) {
-		WriteNotFound(w, r, "Scan job not found")
-		return
-	}
-
-	if errors.Is(err, scanner.ErrScanFailed) {
-		WriteInternalError(w, r, "Scan failed. Please try again later.")
-		return
-	}
-
-	// Default to internal error
-	WriteInternalError(w, r, "Failed to start scan. Please try again later.")
+	WriteServiceError(w, r, err, "Failed to start scan. Please try again later.")
 }
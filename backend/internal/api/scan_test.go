@@ -0,0 +1,134 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"better-kiro-prompts/internal/ratelimit"
+	"better-kiro-prompts/internal/scanner"
+)
+
+func newScanRouter(t *testing.T, limiter *ratelimit.Limiter) http.Handler {
+	t.Helper()
+	return NewRouter(&RouterConfig{
+		ScannerService:  scanner.NewService(nil, nil, "", scanner.WithMaxBatchSize(2)),
+		ScanRateLimiter: limiter,
+	})
+}
+
+func postBatch(router http.Handler, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/scan/batch", strings.NewReader(body))
+	req.RemoteAddr = "203.0.113.7:1234"
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandleStartBatch_Validation(t *testing.T) {
+	router := newScanRouter(t, ratelimit.NewLimiterWithConfig(100, ratelimit.DefaultWindow))
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"empty", `{"repo_urls":[]}`},
+		{"too many", `{"repo_urls":["https://github.com/a/b","https://github.com/a/c","https://github.com/a/d"]}`},
+		{"invalid entry", `{"repo_urls":["https://github.com/a/b","not a url"]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := postBatch(router, tt.body)
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want 400: %s", rec.Code, rec.Body.String())
+			}
+			var resp ErrorResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil || resp.Code != ErrCodeValidation {
+				t.Errorf("error response = %+v (%v), want a validation error", resp, err)
+			}
+		})
+	}
+}
+
+func TestHandleStartBatch_ChargesEachRepository(t *testing.T) {
+	limiter := ratelimit.NewLimiterWithConfig(3, ratelimit.DefaultWindow)
+	router := newScanRouter(t, limiter)
+
+	// Invalid batches are rejected before they are charged
+	invalid := `{"repo_urls":["https://github.com/a/b","not a url"]}`
+	for i := range 2 {
+		if rec := postBatch(router, invalid); rec.Code != http.StatusBadRequest {
+			t.Fatalf("invalid batch %d status = %d, want 400", i+1, rec.Code)
+		}
+	}
+
+	// Two distinct repositories need two scans when only one is left
+	limiter.AllowN("203.0.113.7", 2)
+	rec := postBatch(router, `{"repo_urls":["https://github.com/a/b","https://github.com/a/c"]}`)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("valid batch status = %d, want 429", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("expected a Retry-After header")
+	}
+}
+
+func TestHandleStartBatch_LargerThanHourlyLimit(t *testing.T) {
+	router := newScanRouter(t, ratelimit.NewLimiterWithConfig(1, ratelimit.DefaultWindow))
+
+	rec := postBatch(router, `{"repo_urls":["https://github.com/a/b","https://github.com/a/c"]}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400: %s", rec.Code, rec.Body.String())
+	}
+	var resp ErrorResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil || resp.Code != ErrCodeValidation || !strings.Contains(resp.Error, "hourly scan limit") {
+		t.Errorf("error response = %+v (%v), want a validation error naming the hourly limit", resp, err)
+	}
+}
+
+func TestScanResourceRoute(t *testing.T) {
+	router := newScanRouter(t, ratelimit.NewLimiter())
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/scan/job-1/unknown", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("GET /api/scan/{id}/unknown status = %d, want 404", rec.Code)
+	}
+}
+
+func TestAcknowledgeFinding_RequiresInternalKey(t *testing.T) {
+	newRouter := func(key string) http.Handler {
+		return NewRouter(&RouterConfig{
+			ScannerService:  scanner.NewService(nil, nil, ""),
+			ScanRateLimiter: ratelimit.NewLimiterWithConfig(10, ratelimit.DefaultWindow),
+			InternalAPIKey:  key,
+		})
+	}
+	body := `{"repo_url":"https://github.com/owner/repo","rule_id":"r1","file_path":"main.go","reason":"reviewed"}`
+
+	req := httptest.NewRequest(http.MethodPost, "/api/scan/ack", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	newRouter(testInternalKey).ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status without key = %d, want 401", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/scan/ack", strings.NewReader(body))
+	req.Header.Set(InternalKeyHeader, "wrong-key")
+	rec = httptest.NewRecorder()
+	newRouter(testInternalKey).ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status with a wrong key = %d, want 401", rec.Code)
+	}
+
+	// Without an internal key configured the route does not exist; the path
+	// then only matches GET /api/scan/{id}
+	req = httptest.NewRequest(http.MethodPost, "/api/scan/ack", strings.NewReader(body))
+	rec = httptest.NewRecorder()
+	newRouter("").ServeHTTP(rec, req)
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("status without a configured key = %d, want 405", rec.Code)
+	}
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"better-kiro-prompts/internal/scanner"
+)
+
+// formatTimestamp formats t for API responses: RFC3339 in UTC, whatever the
+// server's local time zone.
+func formatTimestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// wantsRelative reports whether the request asked for relative timestamps
+// with ?relative=true.
+func wantsRelative(r *http.Request) bool {
+	relative, _ := strconv.ParseBool(r.URL.Query().Get("relative"))
+	return relative
+}
+
+// relativeTimestamp describes t relative to now in English, such as
+// "3 hours ago", or returns "" unless the request asked for it.
+func relativeTimestamp(r *http.Request, t, now time.Time) string {
+	if !wantsRelative(r) {
+		return ""
+	}
+	return relativeTime(t, now)
+}
+
+// relativeTime describes t relative to now in the largest whole unit, such
+// as "3 hours ago". Times under a minute old, or in the future, are "just now".
+func relativeTime(t, now time.Time) string {
+	d := now.Sub(t)
+	units := []struct {
+		name string
+		size time.Duration
+	}{
+		{"year", 365 * 24 * time.Hour},
+		{"month", 30 * 24 * time.Hour},
+		{"day", 24 * time.Hour},
+		{"hour", time.Hour},
+		{"minute", time.Minute},
+	}
+	for _, u := range units {
+		if n := int(d / u.size); n >= 1 {
+			if n == 1 {
+				return fmt.Sprintf("1 %s ago", u.name)
+			}
+			return fmt.Sprintf("%d %ss ago", n, u.name)
+		}
+	}
+	return "just now"
+}
+
+// ScanJobResponse is a scan job as served by the API, with its timestamps
+// in UTC and, when requested, relative to now.
+type ScanJobResponse struct {
+	*scanner.ScanJob
+	CreatedAt           string  `json:"created_at"`
+	CompletedAt         *string `json:"completed_at,omitempty"`
+	CreatedAtRelative   string  `json:"created_at_relative,omitempty"`
+	CompletedAtRelative string  `json:"completed_at_relative,omitempty"`
+}
+
+// newScanJobResponse wraps job for a response to r.
+func newScanJobResponse(r *http.Request, job *scanner.ScanJob) ScanJobResponse {
+	now := time.Now()
+	resp := ScanJobResponse{
+		ScanJob:           job,
+		CreatedAt:         formatTimestamp(job.CreatedAt),
+		CreatedAtRelative: relativeTimestamp(r, job.CreatedAt, now),
+	}
+	if job.CompletedAt != nil {
+		completedAt := formatTimestamp(*job.CompletedAt)
+		resp.CompletedAt = &completedAt
+		resp.CompletedAtRelative = relativeTimestamp(r, *job.CompletedAt, now)
+	}
+	return resp
+}
+
+// AcknowledgementResponse is an acknowledgement as served by the API, with
+// its timestamp in UTC.
+type AcknowledgementResponse struct {
+	*scanner.Acknowledgement
+	CreatedAt string `json:"created_at"`
+}
+
+// ScanBatchResponse is a scan batch as served by the API, with its timestamp
+// in UTC.
+type ScanBatchResponse struct {
+	*scanner.ScanBatch
+	CreatedAt string `json:"created_at"`
+}
+
+// newScanBatchResponse wraps batch for a response.
+func newScanBatchResponse(batch *scanner.ScanBatch) ScanBatchResponse {
+	return ScanBatchResponse{ScanBatch: batch, CreatedAt: formatTimestamp(batch.CreatedAt)}
+}
@@ -0,0 +1,147 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"better-kiro-prompts/internal/gallery"
+	"better-kiro-prompts/internal/scanner"
+	"better-kiro-prompts/internal/storage"
+)
+
+// useLocalZone makes loc the local time zone for the rest of the test.
+func useLocalZone(t *testing.T, loc *time.Location) {
+	t.Helper()
+	orig := time.Local
+	time.Local = loc
+	t.Cleanup(func() { time.Local = orig })
+}
+
+// assertUTCRFC3339 checks that s is an RFC3339 timestamp in UTC matching want.
+func assertUTCRFC3339(t *testing.T, field, s string, want time.Time) {
+	t.Helper()
+	got, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t.Errorf("%s = %q is not RFC3339: %v", field, s, err)
+		return
+	}
+	if !strings.HasSuffix(s, "Z") {
+		t.Errorf("%s = %q, want UTC (Z suffix)", field, s)
+	}
+	if !got.Equal(want.Truncate(time.Second)) {
+		t.Errorf("%s = %q, want %s", field, s, want.UTC().Format(time.RFC3339))
+	}
+}
+
+func TestTimestamps_UTCRegardlessOfLocalZone(t *testing.T) {
+	useLocalZone(t, time.FixedZone("UTC+9", 9*60*60))
+
+	repo := storage.NewMemoryRepository()
+	gen := &storage.Generation{ProjectIdea: "A todo app", Files: json.RawMessage(`[]`), CategoryID: 1}
+	if err := repo.CreateGeneration(context.Background(), gen); err != nil {
+		t.Fatalf("CreateGeneration() error = %v", err)
+	}
+	router := NewRouter(&RouterConfig{GalleryService: gallery.NewService(repo, nil, nil)})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/gallery?relative=true", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	var list GalleryListResponse
+	if err := json.NewDecoder(rec.Body).Decode(&list); err != nil || len(list.Items) != 1 {
+		t.Fatalf("GET /api/gallery = %d items, %v; want 1", len(list.Items), err)
+	}
+	assertUTCRFC3339(t, "list createdAt", list.Items[0].CreatedAt, gen.CreatedAt)
+	if list.Items[0].CreatedAtRelative != "just now" {
+		t.Errorf("list createdAtRelative = %q, want %q", list.Items[0].CreatedAtRelative, "just now")
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/gallery/"+gen.ID, nil)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	var detail GalleryDetailResponse
+	if err := json.NewDecoder(rec.Body).Decode(&detail); err != nil {
+		t.Fatalf("decode detail: %v", err)
+	}
+	assertUTCRFC3339(t, "detail createdAt", detail.Generation.CreatedAt, gen.CreatedAt)
+	if detail.Generation.CreatedAtRelative != "" {
+		t.Errorf("detail createdAtRelative = %q without ?relative=true", detail.Generation.CreatedAtRelative)
+	}
+
+	createdAt := time.Now().Add(-3 * time.Hour)
+	completedAt := createdAt.Add(90 * time.Second)
+	job := &scanner.ScanJob{ID: "scan", CreatedAt: createdAt, CompletedAt: &completedAt}
+	req = httptest.NewRequest(http.MethodGet, "/api/scan/scan?relative=true", nil)
+	data, err := json.Marshal(newScanJobResponse(req, job))
+	if err != nil {
+		t.Fatalf("marshal scan job: %v", err)
+	}
+	var scan map[string]any
+	if err := json.Unmarshal(data, &scan); err != nil {
+		t.Fatalf("unmarshal scan job: %v", err)
+	}
+	assertUTCRFC3339(t, "scan created_at", scan["created_at"].(string), createdAt)
+	assertUTCRFC3339(t, "scan completed_at", scan["completed_at"].(string), completedAt)
+	if scan["created_at_relative"] != "3 hours ago" || scan["completed_at_relative"] != "2 hours ago" {
+		t.Errorf("scan relative times = %v, %v", scan["created_at_relative"], scan["completed_at_relative"])
+	}
+}
+
+func TestRelativeTime(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		ago  time.Duration
+		want string
+	}{
+		{-time.Hour, "just now"},
+		{30 * time.Second, "just now"},
+		{time.Minute, "1 minute ago"},
+		{59 * time.Minute, "59 minutes ago"},
+		{3 * time.Hour, "3 hours ago"},
+		{36 * time.Hour, "1 day ago"},
+		{45 * 24 * time.Hour, "1 month ago"},
+		{800 * 24 * time.Hour, "2 years ago"},
+	}
+	for _, tt := range tests {
+		if got := relativeTime(now.Add(-tt.ago), now); got != tt.want {
+			t.Errorf("relativeTime(-%v) = %q, want %q", tt.ago, got, tt.want)
+		}
+	}
+}
+
+func TestAcknowledgementResponse_UTC(t *testing.T) {
+	created := time.Date(2026, 1, 14, 19, 40, 0, 0, time.FixedZone("UTC+9", 9*60*60))
+	data, err := json.Marshal(AcknowledgementResponse{
+		Acknowledgement: &scanner.Acknowledgement{RepoURL: "https://github.com/owner/repo", CreatedAt: created},
+		CreatedAt:       formatTimestamp(created),
+	})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	var resp map[string]any
+	if err := json.Unmarshal(data, &resp); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	createdAt, _ := resp["created_at"].(string)
+	assertUTCRFC3339(t, "created_at", createdAt, created)
+}
+
+func TestScanBatchResponse_UTC(t *testing.T) {
+	created := time.Date(2026, 1, 27, 19, 30, 0, 0, time.FixedZone("UTC+9", 9*60*60))
+	data, err := json.Marshal(newScanBatchResponse(&scanner.ScanBatch{ID: "batch-1", CreatedAt: created}))
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	var resp map[string]any
+	if err := json.Unmarshal(data, &resp); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	createdAt, _ := resp["created_at"].(string)
+	assertUTCRFC3339(t, "created_at", createdAt, created)
+}
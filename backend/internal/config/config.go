@@ -2,14 +2,25 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
+	"net/netip"
 	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/BurntSushi/toml"
+
+	"better-kiro-prompts/internal/pagination"
+	"better-kiro-prompts/internal/prompts"
+	"better-kiro-prompts/internal/scancatalog"
 )
 
 // Config holds all application configuration.
@@ -21,23 +32,42 @@ type Config struct {
 	Scanner    ScannerConfig    `toml:"scanner"`
 	Generation GenerationConfig `toml:"generation"`
 	Gallery    GalleryConfig    `toml:"gallery"`
+	Database   DatabaseConfig   `toml:"database"`
+	Storage    StorageConfig    `toml:"storage"`
 }
 
 // ServerConfig holds HTTP server settings.
 type ServerConfig struct {
-	Port            int      `toml:"port"`
-	Host            string   `toml:"host"`
-	ShutdownTimeout Duration `toml:"shutdown_timeout"`
+	Port               int       `toml:"port"`
+	Host               string    `toml:"host"`
+	ShutdownTimeout    Duration  `toml:"shutdown_timeout"`
+	MaxBodyBytes       int64     `toml:"max_body_bytes"`
+	MaxImportBytes     int64     `toml:"max_import_bytes"` // body limit for gallery imports, which carry a whole export
+	IPHashSalt         string    `toml:"ip_hash_salt"`
+	PreviousIPHashSalt string    `toml:"previous_ip_hash_salt"`       // salt replaced by the last rotation
+	PreviousSaltUntil  time.Time `toml:"previous_ip_hash_salt_until"` // zero disables; until then old-salt hashes still deduplicate
+	TrustedProxies     []string  `toml:"trusted_proxies"`
+	Maintenance        bool      `toml:"maintenance"` // Reloaded on SIGHUP
+	InternalAPIKey     string    `toml:"internal_api_key"`
 }
 
 // OpenAIConfig holds OpenAI API settings.
 type OpenAIConfig struct {
-	Model           string   `toml:"model"`
-	CodeReviewModel string   `toml:"code_review_model"`
-	BaseURL         string   `toml:"base_url"`
-	Timeout         Duration `toml:"timeout"`
-	ReasoningEffort string   `toml:"reasoning_effort"`
-	Verbosity       string   `toml:"verbosity"`
+	Model             string   `toml:"model"`
+	CodeReviewModel   string   `toml:"code_review_model"`
+	BaseURL           string   `toml:"base_url"`
+	CodeReviewBaseURL string   `toml:"code_review_base_url"`
+	OrgID             string   `toml:"org_id"`
+	Timeout           Duration `toml:"timeout"`
+	QuestionsTimeout  Duration `toml:"questions_timeout"` // zero falls back to Timeout; capped by it
+	OutputsTimeout    Duration `toml:"outputs_timeout"`   // zero falls back to Timeout; capped by it
+	ReviewTimeout     Duration `toml:"review_timeout"`    // zero falls back to Timeout; capped by it
+	ReasoningEffort   string   `toml:"reasoning_effort"`
+	Verbosity         string   `toml:"verbosity"`
+	JSONMode          bool     `toml:"json_mode"`
+	// Consecutive outages before calls fail fast for circuit_breaker_cooldown; zero disables
+	CircuitBreakerThreshold int      `toml:"circuit_breaker_threshold"`
+	CircuitBreakerCooldown  Duration `toml:"circuit_breaker_cooldown"`
 }
 
 // RateLimitConfig holds rate limiting settings.
@@ -58,26 +88,90 @@ type LoggingConfig struct {
 
 // ScannerConfig holds security scanner settings.
 type ScannerConfig struct {
-	MaxRepoSizeMB      int      `toml:"max_repo_size_mb"`
-	MaxReviewFiles     int      `toml:"max_review_files"`
-	ToolTimeoutSeconds int      `toml:"tool_timeout_seconds"`
-	RetentionDays      int      `toml:"retention_days"`
-	CloneTimeout       Duration `toml:"clone_timeout"`
+	MaxRepoSizeMB           int                 `toml:"max_repo_size_mb"`
+	MaxReviewFiles          int                 `toml:"max_review_files"`
+	MaxReviewFindings       int                 `toml:"max_review_findings"`
+	MaxReviewFileSizeKB     int                 `toml:"max_review_file_size_kb"`
+	ReviewMinSeverity       string              `toml:"review_min_severity"`
+	ToolTimeoutSeconds      int                 `toml:"tool_timeout_seconds"`
+	ToolTimeouts            map[string]Duration `toml:"tool_timeouts"`
+	ReviewModels            map[string]string   `toml:"review_models"`
+	MaxConcurrentTools      int                 `toml:"max_concurrent_tools"`
+	MaxConcurrentScansPerIP int                 `toml:"max_concurrent_scans_per_ip"`
+	MaxBatchSize            int                 `toml:"max_batch_size"`
+	EnabledTools            []string            `toml:"enabled_tools"`
+	DisabledTools           []string            `toml:"disabled_tools"`
+	RetentionDays           int                 `toml:"retention_days"`
+	RetainCloneMinutes      int                 `toml:"retain_clone_minutes"`
+	CloneTimeout            Duration            `toml:"clone_timeout"`
+	MaxScanDuration         Duration            `toml:"max_scan_duration"`
+	MinLanguagePercent      float64             `toml:"min_language_percent"`
+	MinLanguageFiles        int                 `toml:"min_language_files"`
+	MaxFindings             int                 `toml:"max_findings"`
+	VerifiedSecretsOnly     bool                `toml:"verified_secrets_only"`
+	SecretAllowlist         []string            `toml:"secret_allowlist"`
+	IncludeSnippets         bool                `toml:"include_snippets"` // store code around each finding
+	SnippetContextLines     int                 `toml:"snippet_context_lines"`
+	ReviewPrompt            string              `toml:"review_prompt"`      // AI review system prompt; empty uses the built-in one
+	ReviewPromptFile        string              `toml:"review_prompt_file"` // read into ReviewPrompt on load
 }
 
 // GenerationConfig holds AI generation settings.
 type GenerationConfig struct {
-	MaxProjectIdeaLength int `toml:"max_project_idea_length"`
-	MaxAnswerLength      int `toml:"max_answer_length"`
-	MinQuestions         int `toml:"min_questions"`
-	MaxQuestions         int `toml:"max_questions"`
-	MaxRetries           int `toml:"max_retries"`
+	MinProjectIdeaLength int      `toml:"min_project_idea_length"`
+	MaxProjectIdeaLength int      `toml:"max_project_idea_length"`
+	MaxAnswerLength      int      `toml:"max_answer_length"`
+	MinQuestions         int      `toml:"min_questions"`
+	MaxQuestions         int      `toml:"max_questions"`
+	MaxRetries           int      `toml:"max_retries"`
+	IdempotencyTTL       Duration `toml:"idempotency_ttl"`
+	DedupeWindow         Duration `toml:"dedupe_window"`            // zero stores every generation
+	SecretPolicy         string   `toml:"secret_policy"`            // reject, mask or off; applies to stored project ideas
+	ProfanityWords       []string `toml:"profanity_words"`          // masked in stored project ideas; empty disables
+	DefaultLevel         string   `toml:"default_experience_level"` // used when a request omits experienceLevel
+	DefaultHookPreset    string   `toml:"default_hook_preset"`      // used when a request omits hookPreset
+	MaxPromptTokens      int      `toml:"max_prompt_tokens"`
+	PowEnabled           bool     `toml:"pow_enabled"`
+	PowDifficulty        int      `toml:"pow_difficulty"` // leading zero bits
+	PowTTL               Duration `toml:"pow_ttl"`
+	PowSecret            string   `toml:"pow_secret"`           // empty uses a random per-process secret
+	PromptPrefix         string   `toml:"prompt_prefix"`        // house rules placed before every system prompt
+	PromptSuffix         string   `toml:"prompt_suffix"`        // house rules placed after every system prompt
+	NoCodingPhrases      []string `toml:"no_coding_phrases"`    // accepted in kickoff prompts alongside the built-in phrases
+	OutputPathPrefixes   []string `toml:"output_path_prefixes"` // allowed in generated file paths alongside .kiro/, AGENTS.md and kickoff-prompt.md
 }
 
 // GalleryConfig holds gallery settings.
 type GalleryConfig struct {
-	PageSize    int    `toml:"page_size"`
-	DefaultSort string `toml:"default_sort"`
+	PageSize          int      `toml:"page_size"`
+	DefaultSort       string   `toml:"default_sort"`
+	ArchiveAfterDays  int      `toml:"archive_after_days"`  // zero keeps every generation
+	ArchiveMinViews   int      `toml:"archive_min_views"`   // generations with this many views are kept
+	ArchiveMinRatings int      `toml:"archive_min_ratings"` // generations with this many ratings are kept
+	ArchiveMode       string   `toml:"archive_mode"`        // archive moves rows to generations_archive; delete drops them
+	DownloadLinkTTL   Duration `toml:"download_link_ttl"`   // how long a signed ZIP download link works
+	DownloadSecret    string   `toml:"download_secret"`     // empty uses a random per-process secret
+
+	// Top-rated webhook; an empty URL disables it
+	TopRatedWebhookURL string  `toml:"top_rated_webhook_url"`
+	TopRatedMinRating  float64 `toml:"top_rated_min_rating"` // average rating a generation must reach
+	TopRatedMinVotes   int     `toml:"top_rated_min_votes"`  // ratings it needs before the average counts
+}
+
+// DatabaseConfig holds database access settings.
+type DatabaseConfig struct {
+	QueryTimeout       Duration `toml:"query_timeout"`
+	MaxOpenConns       int      `toml:"max_open_conns"`
+	MaxIdleConns       int      `toml:"max_idle_conns"`
+	ConnMaxLifetime    Duration `toml:"conn_max_lifetime"`    // zero keeps connections indefinitely
+	ConnMaxIdleTime    Duration `toml:"conn_max_idle_time"`   // zero never closes idle connections
+	SlowQueryThreshold Duration `toml:"slow_query_threshold"` // zero disables slow-query logging
+	ReplicaURL         string   `toml:"replica_url"`          // empty serves all reads from the primary
+}
+
+// StorageConfig selects where gallery and generation data is kept.
+type StorageConfig struct {
+	Backend string `toml:"backend"` // postgres or memory; memory is for development and demos
 }
 
 // Duration is a wrapper around time.Duration that supports TOML unmarshaling.
@@ -110,6 +204,8 @@ func DefaultConfig() *Config {
 			Port:            8090,
 			Host:            "0.0.0.0",
 			ShutdownTimeout: Duration(30 * time.Second),
+			MaxBodyBytes:    1 << 20,
+			MaxImportBytes:  256 << 20,
 		},
 		OpenAI: OpenAIConfig{
 			Model:           "gpt-5.2",
@@ -118,6 +214,9 @@ func DefaultConfig() *Config {
 			Timeout:         Duration(240 * time.Second),
 			ReasoningEffort: "medium",
 			Verbosity:       "medium",
+
+			CircuitBreakerThreshold: 5,
+			CircuitBreakerCooldown:  Duration(30 * time.Second),
 		},
 		RateLimit: RateLimitConfig{
 			GenerationLimitPerHour: 10,
@@ -132,22 +231,57 @@ func DefaultConfig() *Config {
 			EnableColor: true,
 		},
 		Scanner: ScannerConfig{
-			MaxRepoSizeMB:      500,
-			MaxReviewFiles:     10,
-			ToolTimeoutSeconds: 300,
-			RetentionDays:      7,
-			CloneTimeout:       Duration(5 * time.Minute),
+			MaxRepoSizeMB:           500,
+			MaxReviewFiles:          10,
+			MaxReviewFindings:       10,
+			MaxReviewFileSizeKB:     50,
+			ReviewMinSeverity:       "medium",
+			ToolTimeoutSeconds:      300,
+			MaxConcurrentTools:      4,
+			MaxConcurrentScansPerIP: 2,
+			MaxBatchSize:            10,
+			RetentionDays:           7,
+			CloneTimeout:            Duration(5 * time.Minute),
+			MaxScanDuration:         Duration(30 * time.Minute),
+			MaxFindings:             1000,
+			SnippetContextLines:     3,
 		},
 		Generation: GenerationConfig{
+			MinProjectIdeaLength: 10,
 			MaxProjectIdeaLength: 2000,
 			MaxAnswerLength:      1000,
 			MinQuestions:         5,
 			MaxQuestions:         10,
 			MaxRetries:           1,
+			IdempotencyTTL:       Duration(24 * time.Hour),
+			DedupeWindow:         Duration(24 * time.Hour),
+			SecretPolicy:         "reject",
+			DefaultLevel:         "novice",
+			DefaultHookPreset:    "default",
+			MaxPromptTokens:      32000,
+			PowDifficulty:        16,
+			PowTTL:               Duration(2 * time.Minute),
 		},
 		Gallery: GalleryConfig{
-			PageSize:    20,
-			DefaultSort: "newest",
+			PageSize:          pagination.DefaultPageSize,
+			DefaultSort:       "newest",
+			ArchiveMinViews:   10,
+			ArchiveMinRatings: 1,
+			ArchiveMode:       "archive",
+			DownloadLinkTTL:   Duration(24 * time.Hour),
+			TopRatedMinRating: 4.5,
+			TopRatedMinVotes:  10,
+		},
+		Database: DatabaseConfig{
+			QueryTimeout:       Duration(5 * time.Second),
+			MaxOpenConns:       25,
+			MaxIdleConns:       5,
+			ConnMaxLifetime:    Duration(5 * time.Minute),
+			ConnMaxIdleTime:    Duration(time.Minute),
+			SlowQueryThreshold: Duration(500 * time.Millisecond),
+		},
+		Storage: StorageConfig{
+			Backend: "postgres",
 		},
 	}
 }
@@ -157,13 +291,16 @@ func DefaultConfig() *Config {
 // 2. config.toml file values
 // 3. Environment variable overrides
 func Load() (*Config, error) {
-	// Determine config path
-	configPath := os.Getenv("CONFIG_PATH")
-	if configPath == "" {
-		configPath = "config.toml"
-	}
+	return LoadFromPath(Path())
+}
 
-	return LoadFromPath(configPath)
+// Path returns the config file location: CONFIG_PATH, or config.toml in the
+// working directory.
+func Path() string {
+	if configPath := os.Getenv("CONFIG_PATH"); configPath != "" {
+		return configPath
+	}
+	return "config.toml"
 }
 
 // LoadFromPath reads configuration from a specific path.
@@ -178,7 +315,13 @@ func LoadFromPath(path string) (*Config, error) {
 	}
 
 	// Apply environment overrides
-	cfg.ApplyEnvironmentOverrides()
+	if err := cfg.ApplyEnvironmentOverrides(); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Scanner.loadReviewPrompt(filepath.Dir(path)); err != nil {
+		return nil, err
+	}
 
 	// Validate
 	if err := cfg.Validate(); err != nil {
@@ -188,9 +331,63 @@ func LoadFromPath(path string) (*Config, error) {
 	return cfg, nil
 }
 
+// loadReviewPrompt reads ReviewPromptFile into ReviewPrompt. A relative path
+// is resolved against baseDir, the directory of the config file.
+func (s *ScannerConfig) loadReviewPrompt(baseDir string) error {
+	if s.ReviewPromptFile == "" {
+		return nil
+	}
+	if s.ReviewPrompt != "" {
+		return errors.New("scanner.review_prompt and scanner.review_prompt_file cannot both be set")
+	}
+
+	path := s.ReviewPromptFile
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(baseDir, path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read scanner.review_prompt_file: %w", err)
+	}
+	s.ReviewPrompt = string(data)
+	return nil
+}
+
+// maxReviewPromptLength caps a custom AI review system prompt in characters.
+const maxReviewPromptLength = 20000
+
+// reviewResponseFields are the JSON fields of the scanner's review response.
+// Findings are matched to the model's remediation by these names, so a
+// custom review prompt must still ask for them.
+var reviewResponseFields = []string{"findings", "file_path", "line_number", "remediation", "code_example"}
+
+// ValidateReviewPrompt checks that a custom AI review system prompt still
+// asks for JSON output in the shape the scanner parses.
+func ValidateReviewPrompt(prompt string) error {
+	if len(prompt) > maxReviewPromptLength {
+		return fmt.Errorf("must be at most %d characters, got %d", maxReviewPromptLength, len(prompt))
+	}
+	if !strings.Contains(strings.ToLower(prompt), "json") {
+		return errors.New("must instruct the model to respond with JSON")
+	}
+	var missing []string
+	for _, field := range reviewResponseFields {
+		if !strings.Contains(prompt, field) {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("must describe the JSON response fields %s; missing %s",
+			strings.Join(reviewResponseFields, ", "), strings.Join(missing, ", "))
+	}
+	return nil
+}
+
 // ApplyEnvironmentOverrides reads env vars and overrides config values.
 // Environment variables take precedence for secrets and backward compatibility.
-func (c *Config) ApplyEnvironmentOverrides() {
+// It fails only for a malformed PREVIOUS_IP_HASH_SALT_UNTIL, since ignoring it
+// would silently end a salt rotation window.
+func (c *Config) ApplyEnvironmentOverrides() error {
 	// Server
 	if v := os.Getenv("PORT"); v != "" {
 		if port, err := strconv.Atoi(v); err == nil {
@@ -198,6 +395,52 @@ func (c *Config) ApplyEnvironmentOverrides() {
 		}
 	}
 
+	if v := os.Getenv("IP_HASH_SALT"); v != "" {
+		c.Server.IPHashSalt = v
+	}
+
+	if v := os.Getenv("PREVIOUS_IP_HASH_SALT"); v != "" {
+		c.Server.PreviousIPHashSalt = v
+	}
+
+	if v := os.Getenv("PREVIOUS_IP_HASH_SALT_UNTIL"); v != "" {
+		until, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return fmt.Errorf("PREVIOUS_IP_HASH_SALT_UNTIL must be an RFC3339 timestamp: %w", err)
+		}
+		c.Server.PreviousSaltUntil = until
+	}
+
+	if v := os.Getenv("POW_SECRET"); v != "" {
+		c.Generation.PowSecret = v
+	}
+
+	if v := os.Getenv("DOWNLOAD_SECRET"); v != "" {
+		c.Gallery.DownloadSecret = v
+	}
+
+	if v := os.Getenv("TOP_RATED_WEBHOOK_URL"); v != "" {
+		c.Gallery.TopRatedWebhookURL = v
+	}
+
+	if v := os.Getenv("INTERNAL_API_KEY"); v != "" {
+		c.Server.InternalAPIKey = v
+	}
+
+	if v := os.Getenv("TRUSTED_PROXIES"); v != "" {
+		c.Server.TrustedProxies = splitList(v)
+	}
+
+	// Database
+	if v := os.Getenv("DATABASE_REPLICA_URL"); v != "" {
+		c.Database.ReplicaURL = v
+	}
+
+	// Storage
+	if v := os.Getenv("STORAGE_BACKEND"); v != "" {
+		c.Storage.Backend = v
+	}
+
 	// Logging
 	if v := os.Getenv("LOG_LEVEL"); v != "" {
 		c.Logging.Level = v
@@ -207,6 +450,9 @@ func (c *Config) ApplyEnvironmentOverrides() {
 	if v := os.Getenv("OPENAI_MODEL"); v != "" {
 		c.OpenAI.Model = v
 	}
+	if v := os.Getenv("OPENAI_ORG_ID"); v != "" {
+		c.OpenAI.OrgID = v
+	}
 
 	// Scanner overrides (existing env vars for backward compatibility)
 	if v := os.Getenv("SCANNER_MAX_REPO_SIZE_MB"); v != "" {
@@ -246,6 +492,8 @@ func (c *Config) ApplyEnvironmentOverrides() {
 			c.RateLimit.ScanLimitPerHour = limit
 		}
 	}
+
+	return nil
 }
 
 // Valid values for enum fields
@@ -263,8 +511,77 @@ var (
 	validSortOptions = map[string]bool{
 		"newest": true, "highest_rated": true, "most_viewed": true,
 	}
+	validSeverities = map[string]bool{
+		"critical": true, "high": true, "medium": true, "low": true,
+	}
+	validSecretPolicies = map[string]bool{
+		"reject": true, "mask": true, "off": true,
+	}
+	validArchiveModes = map[string]bool{
+		"archive": true, "delete": true,
+	}
+	validStorageBackends = map[string]bool{
+		"postgres": true, "memory": true,
+	}
+)
+
+// minInternalAPIKeyLength keeps the rate-limit bypass key out of guessing range.
+const minInternalAPIKeyLength = 32
+
+// maxPromptAffixLength bounds each house-rules prompt prefix and suffix, so
+// they can't crowd the generation prompt out of the token budget.
+const maxPromptAffixLength = 4000
+
+// Limits for operator "no coding" enforcement phrases.
+const (
+	maxNoCodingPhrases      = 50
+	minNoCodingPhraseLength = 4
+	maxNoCodingPhraseLength = 200
 )
 
+// maxReviewFileSizeKB bounds the per-file buffer the code reviewer allocates;
+// keep in sync with scanner.MaxReviewFileSize.
+const maxReviewFileSizeKB = 1024
+
+// maxOutputPathPrefixes bounds the operator's extra generated file locations.
+const maxOutputPathPrefixes = 20
+
+// isSafeOutputPathPrefix reports whether prefix is a relative, normalized
+// file or directory (with a trailing "/") path that can't escape the project
+// root.
+func isSafeOutputPathPrefix(prefix string) bool {
+	p := strings.TrimSuffix(prefix, "/")
+	if p == "" || strings.ContainsAny(p, "\\\x00") || path.IsAbs(p) || (len(p) >= 2 && p[1] == ':') {
+		return false
+	}
+	for _, segment := range strings.Split(p, "/") {
+		if segment == ".." {
+			return false
+		}
+	}
+	return path.Clean(p) == p && p != "."
+}
+
+// isIPOrCIDR reports whether s is a single IP address or a CIDR range.
+func isIPOrCIDR(s string) bool {
+	if _, err := netip.ParsePrefix(s); err == nil {
+		return true
+	}
+	_, err := netip.ParseAddr(s)
+	return err == nil
+}
+
+// splitList splits a comma-separated environment value, dropping empty entries.
+func splitList(v string) []string {
+	var items []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 // Validate checks all configuration values are within acceptable ranges.
 func (c *Config) Validate() error {
 	var errs []string
@@ -276,6 +593,23 @@ func (c *Config) Validate() error {
 	if c.Server.ShutdownTimeout.Duration() < time.Second {
 		errs = append(errs, "server.shutdown_timeout must be at least 1s")
 	}
+	if c.Server.MaxBodyBytes < 1 {
+		errs = append(errs, fmt.Sprintf("server.max_body_bytes must be at least 1, got %d", c.Server.MaxBodyBytes))
+	}
+	if c.Server.MaxImportBytes < 1 {
+		errs = append(errs, fmt.Sprintf("server.max_import_bytes must be at least 1, got %d", c.Server.MaxImportBytes))
+	}
+	if c.Server.InternalAPIKey != "" && len(c.Server.InternalAPIKey) < minInternalAPIKeyLength {
+		errs = append(errs, fmt.Sprintf("server.internal_api_key must be at least %d characters when set", minInternalAPIKeyLength))
+	}
+	if !c.Server.PreviousSaltUntil.IsZero() && c.Server.PreviousIPHashSalt == c.Server.IPHashSalt {
+		errs = append(errs, "server.previous_ip_hash_salt must differ from server.ip_hash_salt when previous_ip_hash_salt_until is set")
+	}
+	for _, proxy := range c.Server.TrustedProxies {
+		if !isIPOrCIDR(proxy) {
+			errs = append(errs, fmt.Sprintf("server.trusted_proxies entries must be IP addresses or CIDR ranges; got %q", proxy))
+		}
+	}
 
 	// OpenAI validation
 	if c.OpenAI.Model == "" {
@@ -290,6 +624,26 @@ func (c *Config) Validate() error {
 	if c.OpenAI.Timeout.Duration() < 10*time.Second {
 		errs = append(errs, "openai.timeout must be at least 10s")
 	}
+	for _, t := range []struct {
+		name    string
+		timeout Duration
+	}{
+		{"questions_timeout", c.OpenAI.QuestionsTimeout},
+		{"outputs_timeout", c.OpenAI.OutputsTimeout},
+		{"review_timeout", c.OpenAI.ReviewTimeout},
+	} {
+		if d := t.timeout.Duration(); d != 0 && d < 10*time.Second {
+			errs = append(errs, fmt.Sprintf("openai.%s must be 0 or at least 10s", t.name))
+		} else if d > c.OpenAI.Timeout.Duration() {
+			errs = append(errs, fmt.Sprintf("openai.%s must not exceed openai.timeout (%s), got %s", t.name, c.OpenAI.Timeout.Duration(), d))
+		}
+	}
+	if c.OpenAI.CircuitBreakerThreshold < 0 {
+		errs = append(errs, fmt.Sprintf("openai.circuit_breaker_threshold must not be negative, got %d", c.OpenAI.CircuitBreakerThreshold))
+	}
+	if c.OpenAI.CircuitBreakerCooldown.Duration() < time.Second {
+		errs = append(errs, "openai.circuit_breaker_cooldown must be at least 1s")
+	}
 
 	// Rate limit validation
 	if c.RateLimit.GenerationLimitPerHour < 1 {
@@ -320,20 +674,92 @@ func (c *Config) Validate() error {
 	if c.Scanner.MaxReviewFiles < 1 {
 		errs = append(errs, "scanner.max_review_files must be at least 1")
 	}
+	if c.Scanner.MaxReviewFindings < 1 {
+		errs = append(errs, "scanner.max_review_findings must be at least 1")
+	}
+	// The reviewer reads up to this much of each flagged file into memory
+	if c.Scanner.MaxReviewFileSizeKB < 1 || c.Scanner.MaxReviewFileSizeKB > maxReviewFileSizeKB {
+		errs = append(errs, fmt.Sprintf("scanner.max_review_file_size_kb must be 1-%d, got %d", maxReviewFileSizeKB, c.Scanner.MaxReviewFileSizeKB))
+	}
+	if !validSeverities[c.Scanner.ReviewMinSeverity] {
+		errs = append(errs, fmt.Sprintf("scanner.review_min_severity must be critical, high, medium, or low, got %q", c.Scanner.ReviewMinSeverity))
+	}
 	if c.Scanner.ToolTimeoutSeconds < 10 {
 		errs = append(errs, "scanner.tool_timeout_seconds must be at least 10")
 	}
+	if c.Scanner.MaxConcurrentTools < 0 {
+		errs = append(errs, "scanner.max_concurrent_tools must be at least 0")
+	}
+	if c.Scanner.MaxConcurrentScansPerIP < 0 {
+		errs = append(errs, "scanner.max_concurrent_scans_per_ip must be at least 0")
+	}
+	if c.Scanner.MaxBatchSize < 1 || c.Scanner.MaxBatchSize > 100 {
+		errs = append(errs, "scanner.max_batch_size must be between 1 and 100")
+	}
 	if c.Scanner.RetentionDays < 1 {
 		errs = append(errs, "scanner.retention_days must be at least 1")
 	}
+	if c.Scanner.RetainCloneMinutes < 0 || c.Scanner.RetainCloneMinutes > 1440 {
+		errs = append(errs, "scanner.retain_clone_minutes must be between 0 and 1440")
+	}
 	if c.Scanner.CloneTimeout.Duration() < 10*time.Second {
 		errs = append(errs, "scanner.clone_timeout must be at least 10s")
 	}
+	if c.Scanner.MaxScanDuration.Duration() < time.Minute {
+		errs = append(errs, "scanner.max_scan_duration must be at least 1m")
+	}
+	if c.Scanner.MinLanguagePercent < 0 || c.Scanner.MinLanguagePercent > 100 {
+		errs = append(errs, "scanner.min_language_percent must be between 0 and 100")
+	}
+	if c.Scanner.MinLanguageFiles < 0 {
+		errs = append(errs, "scanner.min_language_files must be at least 0")
+	}
+	if c.Scanner.MaxFindings < 0 {
+		errs = append(errs, "scanner.max_findings must be at least 0")
+	}
+	if c.Scanner.SnippetContextLines < 0 || c.Scanner.SnippetContextLines > 10 {
+		errs = append(errs, "scanner.snippet_context_lines must be between 0 and 10")
+	}
+	for _, tool := range c.Scanner.EnabledTools {
+		if !scancatalog.IsTool(tool) {
+			errs = append(errs, fmt.Sprintf("scanner.enabled_tools contains unknown tool %q", tool))
+		}
+	}
+	for _, tool := range c.Scanner.DisabledTools {
+		if !scancatalog.IsTool(tool) {
+			errs = append(errs, fmt.Sprintf("scanner.disabled_tools contains unknown tool %q", tool))
+		}
+	}
+	for tool, timeout := range c.Scanner.ToolTimeouts {
+		if timeout.Duration() < 10*time.Second {
+			errs = append(errs, fmt.Sprintf("scanner.tool_timeouts.%s must be at least 10s", tool))
+		}
+	}
+	for lang, model := range c.Scanner.ReviewModels {
+		if !scancatalog.IsLanguage(lang) {
+			errs = append(errs, fmt.Sprintf("scanner.review_models contains unknown language %q", lang))
+		} else if model == "" {
+			errs = append(errs, fmt.Sprintf("scanner.review_models.%s must not be empty", lang))
+		}
+	}
+	for _, pattern := range c.Scanner.SecretAllowlist {
+		if _, err := regexp.Compile(pattern); err != nil {
+			errs = append(errs, fmt.Sprintf("scanner.secret_allowlist contains invalid pattern %q", pattern))
+		}
+	}
+	if c.Scanner.ReviewPrompt != "" {
+		if err := ValidateReviewPrompt(c.Scanner.ReviewPrompt); err != nil {
+			errs = append(errs, "scanner.review_prompt "+err.Error())
+		}
+	}
 
 	// Generation validation
 	if c.Generation.MaxProjectIdeaLength < 100 {
 		errs = append(errs, "generation.max_project_idea_length must be at least 100")
 	}
+	if c.Generation.MinProjectIdeaLength < 1 || c.Generation.MinProjectIdeaLength > 100 {
+		errs = append(errs, "generation.min_project_idea_length must be between 1 and 100")
+	}
 	if c.Generation.MaxAnswerLength < 100 {
 		errs = append(errs, "generation.max_answer_length must be at least 100")
 	}
@@ -343,17 +769,107 @@ func (c *Config) Validate() error {
 	if c.Generation.MaxQuestions < c.Generation.MinQuestions {
 		errs = append(errs, "generation.max_questions must be >= min_questions")
 	}
-	if c.Generation.MaxRetries < 0 {
-		errs = append(errs, "generation.max_retries must be at least 0")
+	if c.Generation.MaxRetries < 0 || c.Generation.MaxRetries > 5 {
+		errs = append(errs, "generation.max_retries must be between 0 and 5")
+	}
+	if c.Generation.IdempotencyTTL.Duration() < 0 {
+		errs = append(errs, "generation.idempotency_ttl must not be negative")
+	}
+	if c.Generation.DedupeWindow.Duration() < 0 {
+		errs = append(errs, "generation.dedupe_window must not be negative")
+	}
+	if !validSecretPolicies[c.Generation.SecretPolicy] {
+		errs = append(errs, fmt.Sprintf("generation.secret_policy must be one of: reject, mask, off; got %s", c.Generation.SecretPolicy))
+	}
+	if levels := prompts.RequestExperienceLevels(); !slices.Contains(levels, c.Generation.DefaultLevel) {
+		errs = append(errs, fmt.Sprintf("generation.default_experience_level must be one of: %s; got %s", strings.Join(levels, ", "), c.Generation.DefaultLevel))
+	}
+	if presets := prompts.ValidHookPresets(); !slices.Contains(presets, c.Generation.DefaultHookPreset) {
+		errs = append(errs, fmt.Sprintf("generation.default_hook_preset must be one of: %s; got %s", strings.Join(presets, ", "), c.Generation.DefaultHookPreset))
+	}
+	if c.Generation.MaxPromptTokens < 1000 {
+		errs = append(errs, "generation.max_prompt_tokens must be at least 1000")
+	}
+	if c.Generation.PowDifficulty < 1 || c.Generation.PowDifficulty > 24 {
+		errs = append(errs, fmt.Sprintf("generation.pow_difficulty must be 1-24, got %d", c.Generation.PowDifficulty))
+	}
+	if c.Generation.PowTTL.Duration() < 10*time.Second {
+		errs = append(errs, "generation.pow_ttl must be at least 10s")
+	}
+	if len(c.Generation.PromptPrefix) > maxPromptAffixLength || len(c.Generation.PromptSuffix) > maxPromptAffixLength {
+		errs = append(errs, fmt.Sprintf("generation.prompt_prefix and generation.prompt_suffix must be at most %d characters", maxPromptAffixLength))
+	}
+	if len(c.Generation.NoCodingPhrases) > maxNoCodingPhrases {
+		errs = append(errs, fmt.Sprintf("generation.no_coding_phrases must have at most %d phrases", maxNoCodingPhrases))
+	}
+	for _, phrase := range c.Generation.NoCodingPhrases {
+		// A very short phrase would match almost any prompt and disable the check
+		if n := utf8.RuneCountInString(strings.TrimSpace(phrase)); n < minNoCodingPhraseLength || n > maxNoCodingPhraseLength {
+			errs = append(errs, fmt.Sprintf("generation.no_coding_phrases entries must be %d-%d characters, got %q", minNoCodingPhraseLength, maxNoCodingPhraseLength, phrase))
+		}
+	}
+	if len(c.Generation.OutputPathPrefixes) > maxOutputPathPrefixes {
+		errs = append(errs, fmt.Sprintf("generation.output_path_prefixes must have at most %d entries", maxOutputPathPrefixes))
+	}
+	for _, prefix := range c.Generation.OutputPathPrefixes {
+		if !isSafeOutputPathPrefix(prefix) {
+			errs = append(errs, fmt.Sprintf("generation.output_path_prefixes entries must be relative, normalized paths without '..', got %q", prefix))
+		}
 	}
 
 	// Gallery validation
-	if c.Gallery.PageSize < 1 || c.Gallery.PageSize > 100 {
-		errs = append(errs, "gallery.page_size must be 1-100")
+	if c.Gallery.PageSize < 1 || c.Gallery.PageSize > pagination.MaxPageSize {
+		errs = append(errs, fmt.Sprintf("gallery.page_size must be 1-%d", pagination.MaxPageSize))
 	}
 	if !validSortOptions[c.Gallery.DefaultSort] {
 		errs = append(errs, fmt.Sprintf("gallery.default_sort must be one of: newest, highest_rated, most_viewed; got %s", c.Gallery.DefaultSort))
 	}
+	if c.Gallery.ArchiveAfterDays < 0 {
+		errs = append(errs, "gallery.archive_after_days must not be negative")
+	}
+	if c.Gallery.ArchiveMinViews < 0 || c.Gallery.ArchiveMinRatings < 0 {
+		errs = append(errs, "gallery.archive_min_views and gallery.archive_min_ratings must not be negative")
+	}
+	if !validArchiveModes[c.Gallery.ArchiveMode] {
+		errs = append(errs, fmt.Sprintf("gallery.archive_mode must be one of: archive, delete; got %s", c.Gallery.ArchiveMode))
+	}
+	if c.Gallery.DownloadLinkTTL.Duration() < time.Minute {
+		errs = append(errs, "gallery.download_link_ttl must be at least 1m")
+	}
+	if u := c.Gallery.TopRatedWebhookURL; u != "" && !strings.HasPrefix(u, "https://") && !strings.HasPrefix(u, "http://") {
+		errs = append(errs, "gallery.top_rated_webhook_url must be an http or https URL")
+	}
+	if c.Gallery.TopRatedMinRating < 1 || c.Gallery.TopRatedMinRating > 5 {
+		errs = append(errs, fmt.Sprintf("gallery.top_rated_min_rating must be 1-5, got %g", c.Gallery.TopRatedMinRating))
+	}
+	if c.Gallery.TopRatedMinVotes < 1 {
+		errs = append(errs, fmt.Sprintf("gallery.top_rated_min_votes must be at least 1, got %d", c.Gallery.TopRatedMinVotes))
+	}
+
+	// Database validation
+	if c.Database.QueryTimeout.Duration() < 100*time.Millisecond {
+		errs = append(errs, "database.query_timeout must be at least 100ms")
+	}
+	if c.Database.MaxOpenConns < 1 {
+		errs = append(errs, "database.max_open_conns must be at least 1")
+	}
+	if c.Database.MaxIdleConns < 0 || c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		errs = append(errs, fmt.Sprintf("database.max_idle_conns must be 0-%d (max_open_conns), got %d", c.Database.MaxOpenConns, c.Database.MaxIdleConns))
+	}
+	if c.Database.ConnMaxLifetime < 0 {
+		errs = append(errs, "database.conn_max_lifetime must not be negative")
+	}
+	if c.Database.ConnMaxIdleTime < 0 {
+		errs = append(errs, "database.conn_max_idle_time must not be negative")
+	}
+	if c.Database.SlowQueryThreshold < 0 {
+		errs = append(errs, "database.slow_query_threshold must not be negative")
+	}
+
+	// Storage validation
+	if !validStorageBackends[c.Storage.Backend] {
+		errs = append(errs, fmt.Sprintf("storage.backend must be one of: postgres, memory; got %s", c.Storage.Backend))
+	}
 
 	if len(errs) > 0 {
 		return fmt.Errorf("validation errors:\n  - %s", strings.Join(errs, "\n  - "))
@@ -369,14 +885,29 @@ func (c *Config) LogConfig(log *slog.Logger) {
 			slog.Int("port", c.Server.Port),
 			slog.String("host", c.Server.Host),
 			slog.Duration("shutdown_timeout", c.Server.ShutdownTimeout.Duration()),
+			slog.Int64("max_body_bytes", c.Server.MaxBodyBytes),
+			slog.Int64("max_import_bytes", c.Server.MaxImportBytes),
+			slog.Bool("ip_hash_salt_set", c.Server.IPHashSalt != ""),
+			slog.Time("previous_ip_hash_salt_until", c.Server.PreviousSaltUntil),
+			slog.Any("trusted_proxies", c.Server.TrustedProxies),
+			slog.Bool("maintenance", c.Server.Maintenance),
+			slog.Bool("internal_api_key_set", c.Server.InternalAPIKey != ""),
 		),
 		slog.Group("openai",
 			slog.String("model", c.OpenAI.Model),
 			slog.String("code_review_model", c.OpenAI.CodeReviewModel),
 			slog.String("base_url", c.OpenAI.BaseURL),
+			slog.String("code_review_base_url", c.OpenAI.CodeReviewBaseURL),
+			slog.String("org_id", c.OpenAI.OrgID),
 			slog.Duration("timeout", c.OpenAI.Timeout.Duration()),
+			slog.Duration("questions_timeout", c.OpenAI.QuestionsTimeout.Duration()),
+			slog.Duration("outputs_timeout", c.OpenAI.OutputsTimeout.Duration()),
+			slog.Duration("review_timeout", c.OpenAI.ReviewTimeout.Duration()),
 			slog.String("reasoning_effort", c.OpenAI.ReasoningEffort),
 			slog.String("verbosity", c.OpenAI.Verbosity),
+			slog.Bool("json_mode", c.OpenAI.JSONMode),
+			slog.Int("circuit_breaker_threshold", c.OpenAI.CircuitBreakerThreshold),
+			slog.Duration("circuit_breaker_cooldown", c.OpenAI.CircuitBreakerCooldown.Duration()),
 		),
 		slog.Group("rate_limit",
 			slog.Int("generation_per_hour", c.RateLimit.GenerationLimitPerHour),
@@ -393,20 +924,78 @@ func (c *Config) LogConfig(log *slog.Logger) {
 		slog.Group("scanner",
 			slog.Int("max_repo_size_mb", c.Scanner.MaxRepoSizeMB),
 			slog.Int("max_review_files", c.Scanner.MaxReviewFiles),
+			slog.Int("max_review_findings", c.Scanner.MaxReviewFindings),
+			slog.Int("max_review_file_size_kb", c.Scanner.MaxReviewFileSizeKB),
+			slog.String("review_min_severity", c.Scanner.ReviewMinSeverity),
 			slog.Int("tool_timeout_seconds", c.Scanner.ToolTimeoutSeconds),
+			slog.Int("tool_timeout_overrides", len(c.Scanner.ToolTimeouts)),
+			slog.Any("review_models", c.Scanner.ReviewModels),
+			slog.Int("max_concurrent_tools", c.Scanner.MaxConcurrentTools),
+			slog.Int("max_concurrent_scans_per_ip", c.Scanner.MaxConcurrentScansPerIP),
+			slog.Int("max_batch_size", c.Scanner.MaxBatchSize),
+			slog.Any("enabled_tools", c.Scanner.EnabledTools),
+			slog.Any("disabled_tools", c.Scanner.DisabledTools),
 			slog.Int("retention_days", c.Scanner.RetentionDays),
+			slog.Int("retain_clone_minutes", c.Scanner.RetainCloneMinutes),
 			slog.Duration("clone_timeout", c.Scanner.CloneTimeout.Duration()),
+			slog.Duration("max_scan_duration", c.Scanner.MaxScanDuration.Duration()),
+			slog.Float64("min_language_percent", c.Scanner.MinLanguagePercent),
+			slog.Int("min_language_files", c.Scanner.MinLanguageFiles),
+			slog.Int("max_findings", c.Scanner.MaxFindings),
+			slog.Bool("verified_secrets_only", c.Scanner.VerifiedSecretsOnly),
+			slog.Int("secret_allowlist_patterns", len(c.Scanner.SecretAllowlist)),
+			slog.Bool("include_snippets", c.Scanner.IncludeSnippets),
+			slog.Int("snippet_context_lines", c.Scanner.SnippetContextLines),
+			slog.String("review_prompt_file", c.Scanner.ReviewPromptFile),
+			slog.Int("review_prompt_length", len(c.Scanner.ReviewPrompt)),
 		),
 		slog.Group("generation",
+			slog.Int("min_project_idea_length", c.Generation.MinProjectIdeaLength),
 			slog.Int("max_project_idea_length", c.Generation.MaxProjectIdeaLength),
 			slog.Int("max_answer_length", c.Generation.MaxAnswerLength),
 			slog.Int("min_questions", c.Generation.MinQuestions),
 			slog.Int("max_questions", c.Generation.MaxQuestions),
 			slog.Int("max_retries", c.Generation.MaxRetries),
+			slog.Duration("idempotency_ttl", c.Generation.IdempotencyTTL.Duration()),
+			slog.Duration("dedupe_window", c.Generation.DedupeWindow.Duration()),
+			slog.String("secret_policy", c.Generation.SecretPolicy),
+			slog.Int("profanity_word_count", len(c.Generation.ProfanityWords)),
+			slog.String("default_experience_level", c.Generation.DefaultLevel),
+			slog.String("default_hook_preset", c.Generation.DefaultHookPreset),
+			slog.Int("max_prompt_tokens", c.Generation.MaxPromptTokens),
+			slog.Bool("pow_enabled", c.Generation.PowEnabled),
+			slog.Int("pow_difficulty", c.Generation.PowDifficulty),
+			slog.Duration("pow_ttl", c.Generation.PowTTL.Duration()),
+			slog.Bool("pow_secret_set", c.Generation.PowSecret != ""),
+			slog.Int("prompt_prefix_length", len(c.Generation.PromptPrefix)),
+			slog.Int("prompt_suffix_length", len(c.Generation.PromptSuffix)),
+			slog.Int("no_coding_phrase_count", len(c.Generation.NoCodingPhrases)),
+			slog.Any("output_path_prefixes", c.Generation.OutputPathPrefixes),
 		),
 		slog.Group("gallery",
 			slog.Int("page_size", c.Gallery.PageSize),
 			slog.String("default_sort", c.Gallery.DefaultSort),
+			slog.Int("archive_after_days", c.Gallery.ArchiveAfterDays),
+			slog.Int("archive_min_views", c.Gallery.ArchiveMinViews),
+			slog.Int("archive_min_ratings", c.Gallery.ArchiveMinRatings),
+			slog.String("archive_mode", c.Gallery.ArchiveMode),
+			slog.Duration("download_link_ttl", c.Gallery.DownloadLinkTTL.Duration()),
+			slog.Bool("download_secret_set", c.Gallery.DownloadSecret != ""),
+			slog.Bool("top_rated_webhook_set", c.Gallery.TopRatedWebhookURL != ""),
+			slog.Float64("top_rated_min_rating", c.Gallery.TopRatedMinRating),
+			slog.Int("top_rated_min_votes", c.Gallery.TopRatedMinVotes),
+		),
+		slog.Group("database",
+			slog.Duration("query_timeout", c.Database.QueryTimeout.Duration()),
+			slog.Int("max_open_conns", c.Database.MaxOpenConns),
+			slog.Int("max_idle_conns", c.Database.MaxIdleConns),
+			slog.Duration("conn_max_lifetime", c.Database.ConnMaxLifetime.Duration()),
+			slog.Duration("conn_max_idle_time", c.Database.ConnMaxIdleTime.Duration()),
+			slog.Duration("slow_query_threshold", c.Database.SlowQueryThreshold.Duration()),
+			slog.Bool("replica_url_set", c.Database.ReplicaURL != ""),
+		),
+		slog.Group("storage",
+			slog.String("backend", c.Storage.Backend),
 		),
 	)
 }
@@ -277,20 +277,27 @@ func generateValidConfig(rng *rand.Rand) *Config {
 	verbosities := []string{"low", "medium", "high"}
 	logLevels := []string{"DEBUG", "INFO", "WARN", "ERROR"}
 	sortOptions := []string{"newest", "highest_rated", "most_viewed"}
+	maxOpenConns := 1 + rng.Intn(100)
 
 	return &Config{
 		Server: ServerConfig{
 			Port:            1 + rng.Intn(65534),
 			Host:            "0.0.0.0",
 			ShutdownTimeout: Duration(time.Duration(1+rng.Intn(60)) * time.Second),
+			MaxBodyBytes:    int64(1 + rng.Intn(10<<20)),
+			MaxImportBytes:  int64(1 + rng.Intn(512<<20)),
+			TrustedProxies:  []string{"10.0.0.0/8", "::1"},
 		},
 		OpenAI: OpenAIConfig{
-			Model:           "gpt-" + randomString(rng, 5),
-			CodeReviewModel: "gpt-" + randomString(rng, 5),
-			BaseURL:         "https://api.openai.com/v1",
-			Timeout:         Duration(time.Duration(10+rng.Intn(300)) * time.Second),
-			ReasoningEffort: reasoningEfforts[rng.Intn(len(reasoningEfforts))],
-			Verbosity:       verbosities[rng.Intn(len(verbosities))],
+			Model:                   "gpt-" + randomString(rng, 5),
+			CodeReviewModel:         "gpt-" + randomString(rng, 5),
+			BaseURL:                 "https://api.openai.com/v1",
+			Timeout:                 Duration(time.Duration(310+rng.Intn(300)) * time.Second),
+			OutputsTimeout:          Duration(time.Duration(10+rng.Intn(300)) * time.Second),
+			ReasoningEffort:         reasoningEfforts[rng.Intn(len(reasoningEfforts))],
+			Verbosity:               verbosities[rng.Intn(len(verbosities))],
+			CircuitBreakerThreshold: rng.Intn(20),
+			CircuitBreakerCooldown:  Duration(time.Duration(1+rng.Intn(300)) * time.Second),
 		},
 		RateLimit: RateLimitConfig{
 			GenerationLimitPerHour: 1 + rng.Intn(100),
@@ -305,22 +312,64 @@ func generateValidConfig(rng *rand.Rand) *Config {
 			EnableColor: rng.Intn(2) == 1,
 		},
 		Scanner: ScannerConfig{
-			MaxRepoSizeMB:      1 + rng.Intn(1000),
-			MaxReviewFiles:     1 + rng.Intn(100),
-			ToolTimeoutSeconds: 10 + rng.Intn(600),
-			RetentionDays:      1 + rng.Intn(365),
-			CloneTimeout:       Duration(time.Duration(10+rng.Intn(600)) * time.Second),
+			MaxRepoSizeMB:           1 + rng.Intn(1000),
+			MaxReviewFiles:          1 + rng.Intn(100),
+			MaxReviewFindings:       1 + rng.Intn(50),
+			MaxReviewFileSizeKB:     1 + rng.Intn(1024),
+			ReviewMinSeverity:       []string{"critical", "high", "medium", "low"}[rng.Intn(4)],
+			ToolTimeoutSeconds:      10 + rng.Intn(600),
+			MaxConcurrentTools:      rng.Intn(9),
+			MaxConcurrentScansPerIP: rng.Intn(6),
+			MaxBatchSize:            1 + rng.Intn(100),
+			RetentionDays:           1 + rng.Intn(365),
+			RetainCloneMinutes:      rng.Intn(61),
+			CloneTimeout:            Duration(time.Duration(10+rng.Intn(600)) * time.Second),
+			MaxScanDuration:         Duration(time.Duration(1+rng.Intn(120)) * time.Minute),
+			MinLanguagePercent:      float64(rng.Intn(21)),
+			MinLanguageFiles:        rng.Intn(11),
+			MaxFindings:             rng.Intn(5001),
+			IncludeSnippets:         rng.Intn(2) == 0,
+			SnippetContextLines:     rng.Intn(11),
+			ReviewModels:            map[string]string{"go": "gpt-" + randomString(rng, 5)},
 		},
 		Generation: GenerationConfig{
+			MinProjectIdeaLength: 1 + rng.Intn(100),
 			MaxProjectIdeaLength: 100 + rng.Intn(10000),
 			MaxAnswerLength:      100 + rng.Intn(10000),
 			MinQuestions:         1 + rng.Intn(5),
-			MaxQuestions:         6 + rng.Intn(15),
+			MaxQuestions:         6 + rng.Intn(16),
 			MaxRetries:           rng.Intn(5),
+			IdempotencyTTL:       Duration(time.Duration(rng.Intn(48)) * time.Hour),
+			DedupeWindow:         Duration(time.Duration(rng.Intn(48)) * time.Hour),
+			SecretPolicy:         []string{"reject", "mask", "off"}[rng.Intn(3)],
+			DefaultLevel:         []string{"beginner", "novice", "expert", "auto"}[rng.Intn(4)],
+			DefaultHookPreset:    []string{"light", "basic", "default", "strict"}[rng.Intn(4)],
+			MaxPromptTokens:      1000 + rng.Intn(100000),
+			PowEnabled:           rng.Intn(2) == 0,
+			PowDifficulty:        1 + rng.Intn(24),
+			PowTTL:               Duration(time.Duration(10+rng.Intn(600)) * time.Second),
 		},
 		Gallery: GalleryConfig{
-			PageSize:    1 + rng.Intn(100),
-			DefaultSort: sortOptions[rng.Intn(len(sortOptions))],
+			PageSize:          1 + rng.Intn(100),
+			DefaultSort:       sortOptions[rng.Intn(len(sortOptions))],
+			ArchiveAfterDays:  rng.Intn(730),
+			ArchiveMinViews:   rng.Intn(100),
+			ArchiveMinRatings: rng.Intn(10),
+			ArchiveMode:       []string{"archive", "delete"}[rng.Intn(2)],
+			DownloadLinkTTL:   Duration(time.Duration(1+rng.Intn(720)) * time.Hour),
+			TopRatedMinRating: float64(10+rng.Intn(41)) / 10,
+			TopRatedMinVotes:  1 + rng.Intn(100),
+		},
+		Database: DatabaseConfig{
+			QueryTimeout:       Duration(time.Duration(100+rng.Intn(30000)) * time.Millisecond),
+			MaxOpenConns:       maxOpenConns,
+			MaxIdleConns:       rng.Intn(maxOpenConns + 1),
+			ConnMaxLifetime:    Duration(time.Duration(rng.Intn(60)) * time.Minute),
+			ConnMaxIdleTime:    Duration(time.Duration(rng.Intn(60)) * time.Minute),
+			SlowQueryThreshold: Duration(time.Duration(rng.Intn(5000)) * time.Millisecond),
+		},
+		Storage: StorageConfig{
+			Backend: []string{"postgres", "memory"}[rng.Intn(2)],
 		},
 	}
 }
@@ -329,7 +378,7 @@ func generateInvalidConfig(rng *rand.Rand) *Config {
 	cfg := generateValidConfig(rng)
 
 	// Randomly invalidate one field
-	invalidationType := rng.Intn(10)
+	invalidationType := rng.Intn(19)
 	switch invalidationType {
 	case 0:
 		cfg.Server.Port = -1 // Invalid port
@@ -351,6 +400,24 @@ func generateInvalidConfig(rng *rand.Rand) *Config {
 		cfg.Generation.MaxQuestions = 0 // Less than min
 	case 9:
 		cfg.Gallery.DefaultSort = "invalid" // Invalid sort
+	case 10:
+		cfg.Scanner.ReviewModels = map[string]string{"cobol": "gpt-5.2"} // Unknown language
+	case 11:
+		cfg.Generation.SecretPolicy = "ignore" // Unknown secret policy
+	case 12:
+		cfg.Generation.DefaultLevel = "intermediate" // Unknown experience level
+	case 13:
+		cfg.Generation.DefaultHookPreset = "none" // Unknown hook preset
+	case 14:
+		cfg.Storage.Backend = "sqlite" // Unknown storage backend
+	case 15:
+		cfg.Gallery.ArchiveMode = "compress" // Unknown archive mode
+	case 16:
+		cfg.Scanner.MaxConcurrentScansPerIP = -1 // Negative limit
+	case 17:
+		cfg.Gallery.TopRatedMinVotes = 0 // Threshold with no votes
+	case 18:
+		cfg.OpenAI.CircuitBreakerThreshold = -1 // Negative breaker threshold
 	}
 
 	return cfg
@@ -373,5 +440,7 @@ func configsEqual(a, b *Config) bool {
 		reflect.DeepEqual(a.Logging, b.Logging) &&
 		reflect.DeepEqual(a.Scanner, b.Scanner) &&
 		reflect.DeepEqual(a.Generation, b.Generation) &&
-		reflect.DeepEqual(a.Gallery, b.Gallery)
+		reflect.DeepEqual(a.Gallery, b.Gallery) &&
+		reflect.DeepEqual(a.Database, b.Database) &&
+		reflect.DeepEqual(a.Storage, b.Storage)
 }
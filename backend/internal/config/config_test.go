@@ -0,0 +1,133 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const customReviewPrompt = `Reference CWE IDs. Respond with JSON: {"findings": [{"file_path": "", "line_number": 0, "remediation": "", "code_example": ""}]}`
+
+func TestLoadFromPath_ReviewPromptFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "review.txt"), []byte(customReviewPrompt), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	configPath := filepath.Join(dir, "config.toml")
+	if err := os.WriteFile(configPath, []byte("[scanner]\nreview_prompt_file = \"review.txt\"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadFromPath(configPath)
+	if err != nil {
+		t.Fatalf("LoadFromPath: %v", err)
+	}
+	if cfg.Scanner.ReviewPrompt != customReviewPrompt {
+		t.Errorf("ReviewPrompt = %q, want the file contents", cfg.Scanner.ReviewPrompt)
+	}
+}
+
+func TestLoadFromPath_ReviewPromptErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		scanner string
+		want    string
+	}{
+		{"missing file", `review_prompt_file = "missing.txt"`, "failed to read scanner.review_prompt_file"},
+		{"both set", "review_prompt = \"x\"\nreview_prompt_file = \"review.txt\"", "cannot both be set"},
+		{"no json", `review_prompt = "Explain each finding in prose."`, "must instruct the model to respond with JSON"},
+		{"missing fields", `review_prompt = "Respond with JSON listing findings with a remediation."`, "missing file_path, line_number, code_example"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			configPath := filepath.Join(dir, "config.toml")
+			if err := os.WriteFile(configPath, []byte("[scanner]\n"+tt.scanner+"\n"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadFromPath(configPath)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadFromPath_MalformedPreviousSaltUntil(t *testing.T) {
+	t.Setenv("PREVIOUS_IP_HASH_SALT_UNTIL", "2026-13-01")
+
+	_, err := LoadFromPath(filepath.Join(t.TempDir(), "config.toml"))
+	if err == nil || !strings.Contains(err.Error(), "PREVIOUS_IP_HASH_SALT_UNTIL must be an RFC3339 timestamp") {
+		t.Errorf("error = %v, want a PREVIOUS_IP_HASH_SALT_UNTIL parse error", err)
+	}
+}
+
+func TestValidateReviewPrompt(t *testing.T) {
+	if err := ValidateReviewPrompt(customReviewPrompt); err != nil {
+		t.Errorf("valid prompt rejected: %v", err)
+	}
+	if err := ValidateReviewPrompt(strings.Repeat("x", maxReviewPromptLength+1)); err == nil {
+		t.Error("expected an over-long prompt to be rejected")
+	}
+}
+
+func TestValidate_MaxReviewFileSize(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Scanner.MaxReviewFileSizeKB = maxReviewFileSizeKB
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	cfg.Scanner.MaxReviewFileSizeKB = 4 * 1024 * 1024 // 4 GB per reviewed file
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "scanner.max_review_file_size_kb") {
+		t.Errorf("Validate() error = %v, want a max_review_file_size_kb error", err)
+	}
+}
+
+func TestValidate_NoCodingPhrases(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Generation.NoCodingPhrases = []string{"n'écrivez pas de code", "コード禁止"}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	// A phrase this short would match almost any kickoff prompt
+	cfg.Generation.NoCodingPhrases = []string{"no"}
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "generation.no_coding_phrases") {
+		t.Errorf("Validate() error = %v, want a no_coding_phrases error", err)
+	}
+}
+
+func TestValidate_PowDifficulty(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Generation.PowDifficulty = 24
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	// A browser could not solve this before the challenge expires
+	cfg.Generation.PowDifficulty = 25
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "generation.pow_difficulty must be 1-24") {
+		t.Errorf("Validate() error = %v, want a pow_difficulty error", err)
+	}
+}
+
+func TestValidate_OutputPathPrefixes(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Generation.OutputPathPrefixes = []string{".github/", "CLAUDE.md"}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	for _, prefix := range []string{"", "/etc/", "../", "docs/../..", "./docs/", `docs\rules`} {
+		cfg.Generation.OutputPathPrefixes = []string{prefix}
+		err := cfg.Validate()
+		if err == nil || !strings.Contains(err.Error(), "generation.output_path_prefixes") {
+			t.Errorf("Validate() with %q error = %v, want an output_path_prefixes error", prefix, err)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"testing"
+	"time"
 )
 
 func TestExampleConfigLoads(t *testing.T) {
@@ -32,4 +33,7 @@ func TestExampleConfigLoads(t *testing.T) {
 	if cfg.Gallery.PageSize != 20 {
 		t.Errorf("Expected gallery.page_size = 20, got %d", cfg.Gallery.PageSize)
 	}
+	if cfg.Database.QueryTimeout.Duration() != 5*time.Second {
+		t.Errorf("Expected database.query_timeout = 5s, got %s", cfg.Database.QueryTimeout.Duration())
+	}
 }
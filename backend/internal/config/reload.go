@@ -0,0 +1,34 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+)
+
+// WithReloaded returns a copy of c with the settings a running server can
+// change without a restart taken from next: rate limits, log level, OpenAI
+// reasoning effort and verbosity, and maintenance mode.
+//
+// It also returns the top-level sections ("server", "scanner", ...) where
+// next differs from c in any other setting. Those changes are not applied
+// and take effect only after a restart.
+func (c *Config) WithReloaded(next *Config) (*Config, []string) {
+	reloaded := *c
+	reloaded.RateLimit = next.RateLimit
+	reloaded.Logging.Level = next.Logging.Level
+	reloaded.OpenAI.ReasoningEffort = next.OpenAI.ReasoningEffort
+	reloaded.OpenAI.Verbosity = next.OpenAI.Verbosity
+	reloaded.Server.Maintenance = next.Server.Maintenance
+
+	var ignored []string
+	have := reflect.ValueOf(reloaded)
+	want := reflect.ValueOf(*next)
+	for i := 0; i < have.NumField(); i++ {
+		if !reflect.DeepEqual(have.Field(i).Interface(), want.Field(i).Interface()) {
+			section, _, _ := strings.Cut(have.Type().Field(i).Tag.Get("toml"), ",")
+			ignored = append(ignored, section)
+		}
+	}
+
+	return &reloaded, ignored
+}
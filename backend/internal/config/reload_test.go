@@ -0,0 +1,35 @@
+package config
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestWithReloaded(t *testing.T) {
+	current := DefaultConfig()
+	next := DefaultConfig()
+	next.RateLimit.GenerationLimitPerHour = current.RateLimit.GenerationLimitPerHour + 5
+	next.Logging.Level = "DEBUG"
+	next.OpenAI.ReasoningEffort = "high"
+	next.Server.Port = current.Server.Port + 1
+	next.Scanner.MaxRepoSizeMB = current.Scanner.MaxRepoSizeMB + 1
+
+	reloaded, ignored := current.WithReloaded(next)
+
+	if reloaded.RateLimit.GenerationLimitPerHour != next.RateLimit.GenerationLimitPerHour {
+		t.Errorf("generation limit = %d, want %d", reloaded.RateLimit.GenerationLimitPerHour, next.RateLimit.GenerationLimitPerHour)
+	}
+	if reloaded.Logging.Level != "DEBUG" || reloaded.OpenAI.ReasoningEffort != "high" {
+		t.Errorf("log level/effort = %s/%s, want DEBUG/high", reloaded.Logging.Level, reloaded.OpenAI.ReasoningEffort)
+	}
+	if reloaded.Server.Port != current.Server.Port {
+		t.Errorf("port = %d, want unchanged %d", reloaded.Server.Port, current.Server.Port)
+	}
+	if want := []string{"server", "scanner"}; !slices.Equal(ignored, want) {
+		t.Errorf("ignored = %v, want %v", ignored, want)
+	}
+
+	if _, ignored := current.WithReloaded(DefaultConfig()); len(ignored) != 0 {
+		t.Errorf("ignored = %v for an unchanged config, want none", ignored)
+	}
+}
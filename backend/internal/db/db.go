@@ -27,8 +27,47 @@ const (
 	defaultConnMaxIdleTime = 1 * time.Minute
 )
 
+// PoolConfig holds the connection pool settings applied by Connect.
+// Zero lifetimes mean connections are never closed for age or idleness.
+type PoolConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+// DefaultPoolConfig returns the pool settings used when none are configured.
+func DefaultPoolConfig() PoolConfig {
+	return PoolConfig{
+		MaxOpenConns:    defaultMaxOpenConns,
+		MaxIdleConns:    defaultMaxIdleConns,
+		ConnMaxLifetime: defaultConnMaxLifetime,
+		ConnMaxIdleTime: defaultConnMaxIdleTime,
+	}
+}
+
 var DB *sql.DB
 
+// ReplicaDB is an optional read replica; nil when none is configured or it
+// could not be reached
+var ReplicaDB *sql.DB
+
+// poolConfig holds the pool settings for the next Connect
+var poolConfig = DefaultPoolConfig()
+
+// SetPoolConfig sets the connection pool settings applied by Connect.
+func SetPoolConfig(cfg PoolConfig) {
+	poolConfig = cfg
+}
+
+// applyPoolConfig configures the pool of sqlDB.
+func applyPoolConfig(sqlDB *sql.DB, cfg PoolConfig) {
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+}
+
 // dbLogger holds the logger for database operations
 var dbLogger *slog.Logger
 
@@ -81,10 +120,7 @@ func Connect(ctx context.Context) error {
 	}
 
 	// Configure connection pool for concurrent access
-	DB.SetMaxOpenConns(defaultMaxOpenConns)
-	DB.SetMaxIdleConns(defaultMaxIdleConns)
-	DB.SetConnMaxLifetime(defaultConnMaxLifetime)
-	DB.SetConnMaxIdleTime(defaultConnMaxIdleTime)
+	applyPoolConfig(DB, poolConfig)
 
 	// Retry connection with backoff (postgres may not be ready yet)
 	maxRetries := 5
@@ -96,10 +132,10 @@ func Connect(ctx context.Context) error {
 
 		if err = DB.PingContext(ctx); err == nil {
 			logInfo("database_connected",
-				slog.Int("max_open_conns", defaultMaxOpenConns),
-				slog.Int("max_idle_conns", defaultMaxIdleConns),
-				slog.Duration("conn_max_lifetime", defaultConnMaxLifetime),
-				slog.Duration("conn_max_idle_time", defaultConnMaxIdleTime),
+				slog.Int("max_open_conns", poolConfig.MaxOpenConns),
+				slog.Int("max_idle_conns", poolConfig.MaxIdleConns),
+				slog.Duration("conn_max_lifetime", poolConfig.ConnMaxLifetime),
+				slog.Duration("conn_max_idle_time", poolConfig.ConnMaxIdleTime),
 			)
 
 			// Run migrations automatically
@@ -127,6 +163,34 @@ func Connect(ctx context.Context) error {
 	return fmt.Errorf("failed to connect to database after %d attempts: %w", maxRetries, err)
 }
 
+// ConnectReplica opens the read replica at dsn with the same pool settings
+// as the primary. A replica that cannot be reached is logged and skipped so
+// reads fall back to the primary; migrations only ever run on the primary.
+func ConnectReplica(ctx context.Context, dsn string) {
+	if dsn == "" {
+		return
+	}
+
+	replica, err := sql.Open("pgx", dsn)
+	if err != nil {
+		logWarn("database_replica_unavailable", slog.String("error", err.Error()))
+		return
+	}
+	applyPoolConfig(replica, poolConfig)
+
+	if err := replica.PingContext(ctx); err != nil {
+		logWarn("database_replica_unavailable", slog.String("error", err.Error()))
+		_ = replica.Close()
+		return
+	}
+
+	ReplicaDB = replica
+	logInfo("database_replica_connected",
+		slog.Int("max_open_conns", poolConfig.MaxOpenConns),
+		slog.Int("max_idle_conns", poolConfig.MaxIdleConns),
+	)
+}
+
 // runMigrations executes all SQL migration files in order
 func runMigrations(ctx context.Context) error {
 	logInfo("migrations_starting")
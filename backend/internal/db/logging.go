@@ -11,17 +11,21 @@ import (
 	"better-kiro-prompts/internal/logger"
 )
 
-// LoggingDB wraps sql.DB with query logging
+// LoggingDB wraps sql.DB with query logging and per-query duration metrics
 type LoggingDB struct {
-	db  *sql.DB
-	log *slog.Logger
+	db            *sql.DB
+	log           *slog.Logger
+	slowThreshold time.Duration
+	metrics       *queryMetrics
 }
 
 // NewLoggingDB creates a new LoggingDB wrapper
 func NewLoggingDB(db *sql.DB, log *slog.Logger) *LoggingDB {
 	return &LoggingDB{
-		db:  db,
-		log: log,
+		db:            db,
+		log:           log,
+		slowThreshold: DefaultSlowQueryThreshold,
+		metrics:       newQueryMetrics(),
 	}
 }
 
@@ -30,6 +34,39 @@ func (l *LoggingDB) DB() *sql.DB {
 	return l.db
 }
 
+// SetSlowQueryThreshold sets the duration above which queries are logged as
+// slow. Zero disables slow-query logging; durations are still recorded.
+func (l *LoggingDB) SetSlowQueryThreshold(d time.Duration) {
+	l.slowThreshold = d
+}
+
+// SlowQueryThreshold returns the slow-query threshold.
+func (l *LoggingDB) SlowQueryThreshold() time.Duration {
+	return l.slowThreshold
+}
+
+// QueryStats returns the recorded durations per query, slowest total first.
+func (l *LoggingDB) QueryStats() []QueryStat {
+	return l.metrics.snapshot()
+}
+
+// observe records a query's duration and warns when it was slow.
+func (l *LoggingDB) observe(requestID, queryType, query string, duration time.Duration) {
+	id := queryID(queryType, query)
+	slow := l.slowThreshold > 0 && duration >= l.slowThreshold
+	l.metrics.record(id, queryType, duration, slow)
+
+	if slow {
+		l.log.Warn("slow_query",
+			slog.String("request_id", requestID),
+			slog.String("type", queryType),
+			slog.String("query_id", id),
+			slog.Duration("duration", duration),
+			slog.Duration("threshold", l.slowThreshold),
+		)
+	}
+}
+
 // QueryContext executes a query and logs the operation
 func (l *LoggingDB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
 	start := time.Now()
@@ -38,6 +75,7 @@ func (l *LoggingDB) QueryContext(ctx context.Context, query string, args ...any)
 
 	rows, err := l.db.QueryContext(ctx, query, args...)
 	duration := time.Since(start)
+	l.observe(requestID, queryType, query, duration)
 
 	l.log.Info("query",
 		slog.String("request_id", requestID),
@@ -66,6 +104,7 @@ func (l *LoggingDB) QueryRowContext(ctx context.Context, query string, args ...a
 
 	row := l.db.QueryRowContext(ctx, query, args...)
 	duration := time.Since(start)
+	l.observe(requestID, queryType, query, duration)
 
 	l.log.Info("query",
 		slog.String("request_id", requestID),
@@ -85,6 +124,7 @@ func (l *LoggingDB) ExecContext(ctx context.Context, query string, args ...any)
 
 	result, err := l.db.ExecContext(ctx, query, args...)
 	duration := time.Since(start)
+	l.observe(requestID, queryType, query, duration)
 
 	var rowsAffected int64
 	if result != nil {
@@ -133,7 +173,7 @@ func (l *LoggingDB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*LoggingT
 		return nil, err
 	}
 
-	return &LoggingTx{tx: tx, log: l.log, requestID: requestID, startTime: time.Now()}, nil
+	return &LoggingTx{tx: tx, db: l, log: l.log, requestID: requestID, startTime: time.Now()}, nil
 }
 
 // Ping verifies the database connection
@@ -154,6 +194,7 @@ func (l *LoggingDB) Close() error {
 // LoggingTx wraps sql.Tx with logging
 type LoggingTx struct {
 	tx        *sql.Tx
+	db        *LoggingDB
 	log       *slog.Logger
 	requestID string
 	startTime time.Time
@@ -166,6 +207,7 @@ func (t *LoggingTx) QueryContext(ctx context.Context, query string, args ...any)
 
 	rows, err := t.tx.QueryContext(ctx, query, args...)
 	duration := time.Since(start)
+	t.db.observe(t.requestID, queryType, query, duration)
 
 	t.log.Info("tx_query",
 		slog.String("request_id", t.requestID),
@@ -192,6 +234,7 @@ func (t *LoggingTx) QueryRowContext(ctx context.Context, query string, args ...a
 
 	row := t.tx.QueryRowContext(ctx, query, args...)
 	duration := time.Since(start)
+	t.db.observe(t.requestID, queryType, query, duration)
 
 	t.log.Info("tx_query",
 		slog.String("request_id", t.requestID),
@@ -210,6 +253,7 @@ func (t *LoggingTx) ExecContext(ctx context.Context, query string, args ...any)
 
 	result, err := t.tx.ExecContext(ctx, query, args...)
 	duration := time.Since(start)
+	t.db.observe(t.requestID, queryType, query, duration)
 
 	var rowsAffected int64
 	if result != nil {
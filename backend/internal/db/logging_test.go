@@ -0,0 +1,103 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+// delayConnector opens connections whose statements take delay to run.
+type delayConnector struct {
+	delay time.Duration
+}
+
+func (c delayConnector) Connect(context.Context) (driver.Conn, error) { return delayConn(c), nil }
+func (c delayConnector) Driver() driver.Driver                        { return nil }
+
+type delayConn struct {
+	delay time.Duration
+}
+
+func (delayConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (delayConn) Close() error                        { return nil }
+func (delayConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c delayConn) ExecContext(ctx context.Context, _ string, _ []driver.NamedValue) (driver.Result, error) {
+	select {
+	case <-time.After(c.delay):
+		return driver.RowsAffected(1), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func newTestLoggingDB(t *testing.T, delay, threshold time.Duration) (*LoggingDB, *bytes.Buffer) {
+	t.Helper()
+	sqlDB := sql.OpenDB(delayConnector{delay: delay})
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	var buf bytes.Buffer
+	l := NewLoggingDB(sqlDB, slog.New(slog.NewJSONHandler(&buf, nil)))
+	l.SetSlowQueryThreshold(threshold)
+	return l, &buf
+}
+
+func TestLoggingDB_SlowQueryLogged(t *testing.T) {
+	l, buf := newTestLoggingDB(t, 30*time.Millisecond, 10*time.Millisecond)
+
+	query := `UPDATE generations SET view_count = view_count + 1 WHERE id = $1`
+	if _, err := l.ExecContext(context.Background(), query, "secret-generation-id"); err != nil {
+		t.Fatalf("ExecContext() error = %v", err)
+	}
+
+	logs := buf.String()
+	if !strings.Contains(logs, `"msg":"slow_query"`) {
+		t.Fatalf("expected a slow_query warning, got %s", logs)
+	}
+	if !strings.Contains(logs, `"query_id":"`+queryID("UPDATE", query)+`"`) {
+		t.Errorf("slow_query log missing the query ID: %s", logs)
+	}
+	if strings.Contains(logs, "secret-generation-id") {
+		t.Errorf("query arguments leaked into the logs: %s", logs)
+	}
+
+	stats := l.QueryStats()
+	if len(stats) != 1 || stats[0].Count != 1 || stats[0].SlowCount != 1 || stats[0].Max < 30*time.Millisecond {
+		t.Errorf("QueryStats() = %+v, want one slow UPDATE of at least 30ms", stats)
+	}
+}
+
+func TestLoggingDB_FastQueryNotLogged(t *testing.T) {
+	l, buf := newTestLoggingDB(t, 0, time.Second)
+
+	for range 3 {
+		if _, err := l.ExecContext(context.Background(), `DELETE FROM views WHERE id = $1`, "v-1"); err != nil {
+			t.Fatalf("ExecContext() error = %v", err)
+		}
+	}
+
+	if strings.Contains(buf.String(), "slow_query") {
+		t.Errorf("unexpected slow_query log: %s", buf.String())
+	}
+	stats := l.QueryStats()
+	if len(stats) != 1 || stats[0].Count != 3 || stats[0].SlowCount != 0 {
+		t.Errorf("QueryStats() = %+v, want three fast executions of one query", stats)
+	}
+}
+
+func TestQueryID_IgnoresWhitespace(t *testing.T) {
+	a := queryID("SELECT", "SELECT id\n\t\tFROM generations WHERE id = $1")
+	b := queryID("SELECT", "SELECT id FROM generations   WHERE id = $1")
+	if a != b {
+		t.Errorf("queryID differs by whitespace: %q vs %q", a, b)
+	}
+	if c := queryID("SELECT", "SELECT id FROM ratings WHERE id = $1"); c == a {
+		t.Errorf("different queries share ID %q", a)
+	}
+}
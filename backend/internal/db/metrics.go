@@ -0,0 +1,77 @@
+package db
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultSlowQueryThreshold is the duration above which LoggingDB logs a
+// query as slow unless SetSlowQueryThreshold changes it.
+const DefaultSlowQueryThreshold = 500 * time.Millisecond
+
+// QueryStat summarizes the recorded executions of one query.
+type QueryStat struct {
+	ID        string // Query type and a fingerprint of its text
+	Type      string
+	Count     int64
+	SlowCount int64
+	Total     time.Duration
+	Max       time.Duration
+}
+
+// queryMetrics accumulates per-query durations.
+type queryMetrics struct {
+	mu    sync.Mutex
+	stats map[string]*QueryStat
+}
+
+func newQueryMetrics() *queryMetrics {
+	return &queryMetrics{stats: make(map[string]*QueryStat)}
+}
+
+func (m *queryMetrics) record(id, queryType string, duration time.Duration, slow bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stat, ok := m.stats[id]
+	if !ok {
+		stat = &QueryStat{ID: id, Type: queryType}
+		m.stats[id] = stat
+	}
+	stat.Count++
+	stat.Total += duration
+	stat.Max = max(stat.Max, duration)
+	if slow {
+		stat.SlowCount++
+	}
+}
+
+// snapshot returns a copy of the stats, slowest total time first.
+func (m *queryMetrics) snapshot() []QueryStat {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := make([]QueryStat, 0, len(m.stats))
+	for _, stat := range m.stats {
+		stats = append(stats, *stat)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Total != stats[j].Total {
+			return stats[i].Total > stats[j].Total
+		}
+		return stats[i].ID < stats[j].ID
+	})
+	return stats
+}
+
+// queryID identifies a query by its type and a hash of its normalized text,
+// so logs and metrics can tell queries apart without including their SQL.
+// Arguments are bound separately and never part of the hash.
+func queryID(queryType, query string) string {
+	sum := sha256.Sum256([]byte(strings.Join(strings.Fields(query), " ")))
+	return strings.ToLower(queryType) + "-" + hex.EncodeToString(sum[:4])
+}
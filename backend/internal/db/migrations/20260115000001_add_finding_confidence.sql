@@ -0,0 +1,4 @@
+-- Migration: Add confidence column to scan_findings
+-- Stores the tool-reported likelihood that a finding is a true positive
+
+ALTER TABLE scan_findings ADD COLUMN IF NOT EXISTS confidence VARCHAR(10);
@@ -0,0 +1,4 @@
+-- Migration: Add suppressed_findings column to scan_jobs
+-- Counts secret findings dropped by the allowlist
+
+ALTER TABLE scan_jobs ADD COLUMN IF NOT EXISTS suppressed_findings INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,4 @@
+-- Migration: Add partial column to scan_jobs
+-- Set when a scan hit max_scan_duration and completed with the findings gathered so far
+
+ALTER TABLE scan_jobs ADD COLUMN IF NOT EXISTS partial BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,5 @@
+-- Migration: Add answers column to generations
+-- Stores the question answers a generation was produced from so it can be replayed.
+-- Never exposed by the public API; NULL for generations stored before this column existed.
+
+ALTER TABLE generations ADD COLUMN IF NOT EXISTS answers JSONB;
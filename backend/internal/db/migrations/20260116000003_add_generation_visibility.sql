@@ -0,0 +1,8 @@
+-- Migration: Add visibility column to generations
+-- Unlisted generations are hidden from gallery listings but reachable by direct link
+
+ALTER TABLE generations ADD COLUMN IF NOT EXISTS visibility VARCHAR(20) NOT NULL DEFAULT 'public'
+    CHECK (visibility IN ('public', 'unlisted'));
+
+-- Index for excluding unlisted generations from listings
+CREATE INDEX IF NOT EXISTS idx_generations_visibility ON generations(visibility);
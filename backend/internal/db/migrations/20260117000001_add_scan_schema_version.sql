@@ -0,0 +1,6 @@
+-- Migration: Add schema_version to scan_jobs and rule_id to scan_findings
+-- Existing jobs predate rule_id and keep schema version 1; new jobs record the
+-- version they were written with. Finding columns stay nullable so older rows still load.
+
+ALTER TABLE scan_jobs ADD COLUMN IF NOT EXISTS schema_version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE scan_findings ADD COLUMN IF NOT EXISTS rule_id TEXT;
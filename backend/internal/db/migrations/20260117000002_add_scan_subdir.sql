@@ -0,0 +1,4 @@
+-- Migration: Add subdir column to scan_jobs
+-- Set when a scan covers one subdirectory of a monorepo; finding paths are relative to it
+
+ALTER TABLE scan_jobs ADD COLUMN IF NOT EXISTS subdir TEXT;
@@ -0,0 +1,5 @@
+-- Migration: Add truncation columns to scan_jobs
+-- Set when a scan produced more findings than scanner.max_findings and the least severe were dropped
+
+ALTER TABLE scan_jobs ADD COLUMN IF NOT EXISTS truncated BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE scan_jobs ADD COLUMN IF NOT EXISTS truncated_findings INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,7 @@
+-- Migration: Add short codes to generations
+-- Short codes are base62 links for sharing; older generations keep NULL and are shared by ID
+
+ALTER TABLE generations ADD COLUMN IF NOT EXISTS short_code VARCHAR(16);
+
+-- Unique index for resolving short links; the repository retries inserts that collide on it
+CREATE UNIQUE INDEX IF NOT EXISTS idx_generations_short_code ON generations(short_code);
@@ -0,0 +1,4 @@
+-- Migration: Add no_code column to scan_jobs
+-- Set when a scan found no source files and completed without running tools
+
+ALTER TABLE scan_jobs ADD COLUMN IF NOT EXISTS no_code BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,9 @@
+-- Migration: Add content hashes to generations
+-- The hash covers the inputs a generation was made from; identical public generations
+-- made within the dedupe window link to the stored one instead of adding a row.
+-- Older generations keep NULL and are never matched.
+
+ALTER TABLE generations ADD COLUMN IF NOT EXISTS content_hash VARCHAR(64);
+
+-- Index for finding the latest generation with a hash
+CREATE INDEX IF NOT EXISTS idx_generations_content_hash ON generations(content_hash, created_at DESC);
@@ -0,0 +1,13 @@
+-- Migration: Create generation_creators table for per-visitor generation history
+-- Maps generations to the IP hash of the visitor who created them. Kept out of the
+-- generations table so gallery queries and exports can never return it.
+
+CREATE TABLE IF NOT EXISTS generation_creators (
+    generation_id UUID NOT NULL REFERENCES generations(id) ON DELETE CASCADE,
+    ip_hash VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (generation_id, ip_hash)
+);
+
+-- Index for listing a visitor's generations, newest first
+CREATE INDEX IF NOT EXISTS idx_generation_creators_ip_hash ON generation_creators(ip_hash, created_at DESC);
@@ -0,0 +1,8 @@
+-- Migration: Add range columns to scan_findings
+-- Tools that report a range store its end line and 1-based start and end
+-- columns. The columns are nullable so findings without a range, and rows
+-- stored by older versions, still load.
+
+ALTER TABLE scan_findings ADD COLUMN IF NOT EXISTS end_line INTEGER;
+ALTER TABLE scan_findings ADD COLUMN IF NOT EXISTS start_column INTEGER;
+ALTER TABLE scan_findings ADD COLUMN IF NOT EXISTS end_column INTEGER;
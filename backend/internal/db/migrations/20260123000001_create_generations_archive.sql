@@ -0,0 +1,22 @@
+-- Migration: Create generations_archive table for the gallery retention policy
+-- Old generations with few views and ratings are moved here by the archive
+-- sweeper so the live table stays small. Nothing reads from it; restore rows by
+-- copying them back into generations.
+
+CREATE TABLE IF NOT EXISTS generations_archive (
+    id UUID PRIMARY KEY,
+    project_idea TEXT NOT NULL,
+    experience_level VARCHAR(20) NOT NULL,
+    hook_preset VARCHAR(20) NOT NULL,
+    files JSONB NOT NULL,
+    category_id INTEGER,
+    visibility VARCHAR(20) NOT NULL,
+    short_code VARCHAR(16),
+    answers JSONB,
+    content_hash VARCHAR(64),
+    avg_rating DECIMAL(3,2),
+    rating_count INTEGER,
+    view_count INTEGER,
+    created_at TIMESTAMP WITH TIME ZONE,
+    archived_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
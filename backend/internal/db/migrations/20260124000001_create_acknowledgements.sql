@@ -0,0 +1,13 @@
+-- Migration: Create acknowledgements table for accepted scan findings
+-- An acknowledgement accepts one rule in one file of a repository. Matching
+-- findings in every scan of the repository are listed separately and left
+-- out of the score. file_path is relative to the repository root.
+
+CREATE TABLE IF NOT EXISTS acknowledgements (
+    repo_url TEXT NOT NULL,
+    rule_id VARCHAR(200) NOT NULL,
+    file_path TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (repo_url, rule_id, file_path)
+);
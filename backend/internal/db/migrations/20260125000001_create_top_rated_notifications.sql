@@ -0,0 +1,8 @@
+-- Migration: Create top_rated_notifications table for the top-rated webhook
+-- A row means the webhook already fired for that generation, so it fires once
+-- however many votes follow, even across restarts and replicas.
+
+CREATE TABLE IF NOT EXISTS top_rated_notifications (
+    generation_id UUID PRIMARY KEY REFERENCES generations(id) ON DELETE CASCADE,
+    notified_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
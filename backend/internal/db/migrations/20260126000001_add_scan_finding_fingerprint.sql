@@ -0,0 +1,8 @@
+-- Migration: Add fingerprint column to scan_findings
+-- A fingerprint hashes the repository, rule, file path and flagged code, so
+-- the same finding has the same fingerprint in every scan of a repository
+-- and links to it survive rescans. Rows stored by older versions have none.
+
+ALTER TABLE scan_findings ADD COLUMN IF NOT EXISTS fingerprint VARCHAR(64);
+
+CREATE INDEX IF NOT EXISTS idx_scan_findings_fingerprint ON scan_findings(fingerprint);
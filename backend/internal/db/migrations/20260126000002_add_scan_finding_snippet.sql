@@ -0,0 +1,7 @@
+-- Migration: Add snippet columns to scan_findings
+-- With scanner.include_snippets on, the code around a finding is stored with
+-- it (size-capped, secrets masked) so results can show context without the
+-- repository. Both columns are NULL when snippets are off and for older rows.
+
+ALTER TABLE scan_findings ADD COLUMN IF NOT EXISTS snippet TEXT;
+ALTER TABLE scan_findings ADD COLUMN IF NOT EXISTS snippet_start_line INTEGER;
@@ -0,0 +1,23 @@
+-- Migration: Create scan batch tables for multi-repository scanning
+-- A batch groups the scans started by one request. scan_batch_jobs lists
+-- them in request order; an entry points at another request's running scan
+-- of the same repository when the batch was deduplicated against it.
+
+CREATE TABLE IF NOT EXISTS scan_batches (
+    id VARCHAR(36) PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() + INTERVAL '7 days'
+);
+
+-- Index for cleanup job to find expired batches
+CREATE INDEX IF NOT EXISTS idx_scan_batches_expires_at ON scan_batches(expires_at);
+
+CREATE TABLE IF NOT EXISTS scan_batch_jobs (
+    batch_id VARCHAR(36) NOT NULL REFERENCES scan_batches(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    scan_job_id VARCHAR(36) NOT NULL REFERENCES scan_jobs(id) ON DELETE CASCADE,
+    PRIMARY KEY (batch_id, position)
+);
+
+-- Index for finding the batches of an expiring scan
+CREATE INDEX IF NOT EXISTS idx_scan_batch_jobs_scan_job_id ON scan_batch_jobs(scan_job_id);
@@ -0,0 +1,76 @@
+// Package download signs and verifies expiring links to a generation's
+// files, so a ZIP can be shared for a bounded time without authentication.
+//
+// A token is "<expiry unix seconds>.<hex HMAC-SHA256>" where the HMAC covers
+// the generation ID and the expiry. Tokens are stateless: they cannot be
+// revoked early, only left to expire or invalidated by changing the secret.
+package download
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Verification errors.
+var (
+	ErrInvalidToken = errors.New("invalid download token")
+	ErrTokenExpired = errors.New("download token expired")
+)
+
+// Signer issues and verifies download tokens signed with its secret.
+type Signer struct {
+	secret []byte
+	now    func() time.Time // for testing
+}
+
+// NewSigner creates a signer. An empty secret is replaced with a random one,
+// so outstanding links stop working when the process restarts.
+func NewSigner(secret []byte) (*Signer, error) {
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, fmt.Errorf("generate secret: %w", err)
+		}
+	}
+	return &Signer{secret: secret, now: time.Now}, nil
+}
+
+// GenerateDownloadToken returns a token granting access to generation id's
+// files until ttl from now, and the time it expires.
+func (s *Signer) GenerateDownloadToken(id string, ttl time.Duration) (string, time.Time) {
+	expiresAt := s.now().Add(ttl).Truncate(time.Second)
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+	return expires + "." + s.sign(id, expires), expiresAt
+}
+
+// Verify checks that token was issued by this signer for generation id and
+// has not expired.
+func (s *Signer) Verify(id, token string) error {
+	expires, mac, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(mac), []byte(s.sign(id, expires))) {
+		return ErrInvalidToken
+	}
+
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return ErrInvalidToken
+	}
+	if s.now().After(time.Unix(unix, 0)) {
+		return ErrTokenExpired
+	}
+	return nil
+}
+
+// sign returns the hex HMAC of the generation ID and expiry.
+func (s *Signer) sign(id, expires string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(id + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,63 @@
+package download
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+const testID = "550e8400-e29b-41d4-a716-446655440000"
+
+func newTestSigner(t *testing.T) *Signer {
+	t.Helper()
+	signer, err := NewSigner([]byte("test-secret"))
+	if err != nil {
+		t.Fatalf("NewSigner() error = %v", err)
+	}
+	return signer
+}
+
+func TestVerify_AcceptsFreshToken(t *testing.T) {
+	signer := newTestSigner(t)
+	token, expiresAt := signer.GenerateDownloadToken(testID, time.Hour)
+
+	if err := signer.Verify(testID, token); err != nil {
+		t.Errorf("Verify() error = %v, want nil", err)
+	}
+	if until := time.Until(expiresAt); until <= 59*time.Minute || until > time.Hour {
+		t.Errorf("expiresAt is %s away, want about an hour", until)
+	}
+}
+
+func TestVerify_RejectsExpiredToken(t *testing.T) {
+	signer := newTestSigner(t)
+	token, _ := signer.GenerateDownloadToken(testID, time.Minute)
+
+	signer.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	if err := signer.Verify(testID, token); !errors.Is(err, ErrTokenExpired) {
+		t.Errorf("Verify() error = %v, want ErrTokenExpired", err)
+	}
+}
+
+func TestVerify_RejectsTamperedToken(t *testing.T) {
+	signer := newTestSigner(t)
+	token, _ := signer.GenerateDownloadToken(testID, time.Minute)
+	other, err := NewSigner([]byte("other-secret"))
+	if err != nil {
+		t.Fatalf("NewSigner() error = %v", err)
+	}
+	otherToken, _ := other.GenerateDownloadToken(testID, time.Minute)
+
+	tests := map[string]struct{ id, token string }{
+		"other generation": {"6ba7b810-9dad-11d1-80b4-00c04fd430c8", token},
+		"extended expiry":  {testID, "9999999999" + token[len("9999999999"):]},
+		"other secret":     {testID, otherToken},
+		"no signature":     {testID, "9999999999"},
+		"empty":            {testID, ""},
+	}
+	for name, tt := range tests {
+		if err := signer.Verify(tt.id, tt.token); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("%s: Verify() error = %v, want ErrInvalidToken", name, err)
+		}
+	}
+}
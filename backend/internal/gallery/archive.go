@@ -0,0 +1,68 @@
+package gallery
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// archiveSweepInterval is how often RunArchiveSweeper applies the retention policy.
+const archiveSweepInterval = time.Hour
+
+// ArchiveStale archives generations older than the configured retention
+// window that have too few views and ratings to be kept, and returns how
+// many were archived. Popular generations are never archived.
+func (s *Service) ArchiveStale(ctx context.Context, now time.Time) (int, error) {
+	if s.archiveAfter <= 0 {
+		return 0, nil
+	}
+
+	policy := s.archivePolicy
+	policy.OlderThan = now.Add(-s.archiveAfter)
+
+	start := time.Now()
+	archived, err := s.repo.ArchiveGenerations(ctx, policy)
+	if err != nil {
+		if s.log != nil {
+			s.log.Error("gallery_archive_failed", slog.String("error", err.Error()))
+		}
+		return 0, err
+	}
+	if archived == 0 {
+		return 0, nil
+	}
+
+	// Archived generations change the per-category counts
+	s.countsMu.Lock()
+	s.categoryCounts = nil
+	s.countsMu.Unlock()
+
+	if s.log != nil {
+		s.log.Info("gallery_archive_complete",
+			slog.Int("archived", archived),
+			slog.Bool("deleted", policy.Delete),
+			slog.Time("older_than", policy.OlderThan),
+			slog.Duration("duration", time.Since(start)),
+		)
+	}
+	return archived, nil
+}
+
+// RunArchiveSweeper applies the retention policy every hour until ctx is
+// done. It returns immediately when archival is disabled.
+func (s *Service) RunArchiveSweeper(ctx context.Context) {
+	if s.archiveAfter <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(archiveSweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			_, _ = s.ArchiveStale(ctx, now)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package gallery
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"better-kiro-prompts/internal/config"
+	"better-kiro-prompts/internal/storage"
+)
+
+func TestArchiveStale_ArchivesOldUnpopularGenerations(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	old := now.Add(-100 * 24 * time.Hour).Format(time.RFC3339)
+	recent := now.Add(-24 * time.Hour).Format(time.RFC3339)
+
+	repo := storage.NewMemoryRepository()
+	backup := strings.Join([]string{
+		`{"id":"11111111-1111-1111-1111-111111111111","projectIdea":"old and quiet","files":[],"viewCount":3,"createdAt":"` + old + `"}`,
+		`{"id":"22222222-2222-2222-2222-222222222222","projectIdea":"old and popular","files":[],"viewCount":500,"createdAt":"` + old + `"}`,
+		`{"id":"33333333-3333-3333-3333-333333333333","projectIdea":"old and rated","files":[],"ratingCount":2,"avgRating":4.5,"createdAt":"` + old + `"}`,
+		`{"id":"44444444-4444-4444-4444-444444444444","projectIdea":"new and quiet","files":[],"createdAt":"` + recent + `"}`,
+	}, "\n")
+	if _, err := repo.ImportGenerations(ctx, strings.NewReader(backup)); err != nil {
+		t.Fatalf("ImportGenerations() error = %v", err)
+	}
+
+	cfg := config.DefaultConfig().Gallery
+	cfg.ArchiveAfterDays = 90
+	svc := NewServiceWithConfig(repo, nil, nil, cfg)
+
+	archived, err := svc.ArchiveStale(ctx, now)
+	if err != nil {
+		t.Fatalf("ArchiveStale() error = %v", err)
+	}
+	if archived != 1 {
+		t.Errorf("ArchiveStale() = %d, want 1", archived)
+	}
+
+	if _, err := svc.GetGeneration(ctx, "11111111-1111-1111-1111-111111111111"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("old, unpopular generation still served: err = %v", err)
+	}
+	for _, id := range []string{
+		"22222222-2222-2222-2222-222222222222",
+		"33333333-3333-3333-3333-333333333333",
+		"44444444-4444-4444-4444-444444444444",
+	} {
+		if _, err := svc.GetGeneration(ctx, id); err != nil {
+			t.Errorf("GetGeneration(%s) error = %v, want it retained", id, err)
+		}
+	}
+}
+
+func TestArchiveStale_DisabledByDefault(t *testing.T) {
+	repo := newMockRepository()
+	repo.generations = append(repo.generations, storage.Generation{ID: generateID(), CreatedAt: time.Now().AddDate(-5, 0, 0)})
+	svc := NewService(repo, nil, nil)
+
+	archived, err := svc.ArchiveStale(context.Background(), time.Now())
+	if err != nil || archived != 0 || len(repo.generations) != 1 {
+		t.Errorf("ArchiveStale() = %d, %v with %d left; want nothing archived", archived, err, len(repo.generations))
+	}
+}
@@ -0,0 +1,158 @@
+package gallery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/rand"
+	"testing"
+	"testing/quick"
+
+	"better-kiro-prompts/internal/pagination"
+	"better-kiro-prompts/internal/storage"
+)
+
+// newSeededMemoryRepository returns a MemoryRepository holding n random
+// generations, imported the way a backup restore would so their ratings,
+// views and creation times are kept.
+func newSeededMemoryRepository(t *testing.T, r *rand.Rand, n int) *storage.MemoryRepository {
+	t.Helper()
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for i := 0; i < n; i++ {
+		gen := generateRandomGeneration(r, 1+r.Intn(5))
+		if err := enc.Encode(storage.ExportedGeneration{
+			ProjectIdea:     gen.ProjectIdea,
+			ExperienceLevel: gen.ExperienceLevel,
+			HookPreset:      gen.HookPreset,
+			Files:           gen.Files,
+			CategoryID:      gen.CategoryID,
+			AvgRating:       gen.AvgRating,
+			RatingCount:     gen.RatingCount,
+			ViewCount:       gen.ViewCount,
+			CreatedAt:       gen.CreatedAt,
+		}); err != nil {
+			t.Fatalf("encode generation: %v", err)
+		}
+	}
+
+	repo := storage.NewMemoryRepository()
+	if _, err := repo.ImportGenerations(context.Background(), &buf); err != nil {
+		t.Fatalf("ImportGenerations() error = %v", err)
+	}
+	return repo
+}
+
+// TestMemoryRepository_GalleryProperties runs the gallery filtering, sorting
+// and pagination properties (5, 6 and 7) against the in-memory backend.
+func TestMemoryRepository_GalleryProperties(t *testing.T) {
+	cfg := &quick.Config{MaxCount: 50}
+
+	t.Run("filtering", func(t *testing.T) {
+		property := func(seed int64) bool {
+			r := rand.New(rand.NewSource(seed))
+			repo := newSeededMemoryRepository(t, r, 10+r.Intn(50))
+			svc := NewService(repo, nil, nil)
+
+			categoryID := 1 + r.Intn(5)
+			resp, err := svc.ListGenerations(context.Background(), ListRequest{CategoryID: &categoryID, Page: 1, PageSize: 100})
+			if err != nil {
+				t.Logf("ListGenerations failed: %v", err)
+				return false
+			}
+
+			counts, err := repo.GetCategoryCounts(context.Background())
+			if err != nil {
+				t.Logf("GetCategoryCounts failed: %v", err)
+				return false
+			}
+			for _, c := range counts {
+				if c.ID == categoryID && c.Count != resp.Total {
+					t.Logf("Total %d doesn't match category count %d", resp.Total, c.Count)
+					return false
+				}
+			}
+			for _, item := range resp.Items {
+				if item.CategoryID != categoryID {
+					t.Logf("Item %s has category %d, expected %d", item.ID, item.CategoryID, categoryID)
+					return false
+				}
+			}
+			return len(resp.Items) == resp.Total
+		}
+		if err := quick.Check(property, cfg); err != nil {
+			t.Errorf("Property 5 (Gallery Filtering Correctness) failed: %v", err)
+		}
+	})
+
+	for _, sortBy := range []string{"newest", "highest_rated", "most_viewed"} {
+		t.Run("sort_"+sortBy, func(t *testing.T) {
+			property := func(seed int64) bool {
+				r := rand.New(rand.NewSource(seed))
+				repo := newSeededMemoryRepository(t, r, 10+r.Intn(30))
+				svc := NewService(repo, nil, nil)
+
+				resp, err := svc.ListGenerations(context.Background(), ListRequest{SortBy: sortBy, Page: 1, PageSize: 100})
+				if err != nil {
+					t.Logf("ListGenerations failed: %v", err)
+					return false
+				}
+
+				for i := 1; i < len(resp.Items); i++ {
+					prev, curr := resp.Items[i-1], resp.Items[i]
+					switch sortBy {
+					case "newest":
+						if prev.CreatedAt.Before(curr.CreatedAt) {
+							return false
+						}
+					case "highest_rated":
+						if prev.AvgRating < curr.AvgRating {
+							return false
+						}
+					case "most_viewed":
+						if prev.ViewCount < curr.ViewCount {
+							return false
+						}
+					}
+				}
+				return true
+			}
+			if err := quick.Check(property, cfg); err != nil {
+				t.Errorf("Property 6 (Gallery Sorting - %s) failed: %v", sortBy, err)
+			}
+		})
+	}
+
+	t.Run("pagination", func(t *testing.T) {
+		property := func(seed int64) bool {
+			r := rand.New(rand.NewSource(seed))
+			numGenerations := r.Intn(100)
+			repo := newSeededMemoryRepository(t, r, numGenerations)
+			svc := NewService(repo, nil, nil)
+
+			pageSize := pagination.NormalizePageSize(r.Intn(50))
+			seen := 0
+			for page := 1; ; page++ {
+				resp, err := svc.ListGenerations(context.Background(), ListRequest{Page: page, PageSize: pageSize})
+				if err != nil {
+					t.Logf("ListGenerations failed: %v", err)
+					return false
+				}
+				if len(resp.Items) > pageSize || resp.Total != numGenerations ||
+					resp.TotalPages != pagination.TotalPages(resp.Total, pageSize) {
+					t.Logf("page %d: %d items, total %d, total pages %d", page, len(resp.Items), resp.Total, resp.TotalPages)
+					return false
+				}
+				if len(resp.Items) == 0 {
+					break
+				}
+				seen += len(resp.Items)
+			}
+			return seen == numGenerations
+		}
+		if err := quick.Check(property, cfg); err != nil {
+			t.Errorf("Property 7 (Pagination Bounds) failed: %v", err)
+		}
+	})
+}
@@ -4,12 +4,15 @@ package gallery
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
 	"log/slog"
-	"math"
+	"sync"
 	"time"
 
 	"better-kiro-prompts/internal/config"
 	"better-kiro-prompts/internal/logger"
+	"better-kiro-prompts/internal/pagination"
 	"better-kiro-prompts/internal/ratelimit"
 	"better-kiro-prompts/internal/storage"
 )
@@ -22,10 +25,11 @@ var (
 	ErrInvalidRating = errors.New("rating must be between 1 and 5")
 	ErrInvalidPage   = errors.New("page must be positive")
 	ErrInvalidSort   = errors.New("invalid sort option")
+	ErrInvalidImport = errors.New("invalid gallery import")
 )
 
-// MaxPageSize is the maximum allowed page size.
-const MaxPageSize = 100
+// categoryCountsTTL is how long category counts are reused before re-querying.
+const categoryCountsTTL = 30 * time.Second
 
 // ValidSortOptions defines the allowed sort options.
 var ValidSortOptions = map[string]bool{
@@ -58,6 +62,21 @@ type Service struct {
 	log         *slog.Logger
 	pageSize    int
 	defaultSort string
+
+	// Retention policy; zero archiveAfter disables the archive sweeper
+	archiveAfter  time.Duration
+	archivePolicy storage.ArchivePolicy
+
+	// Top-rated webhook; an empty URL disables it
+	topRatedURL       string
+	topRatedMinRating float64
+	topRatedMinVotes  int
+	webhooks          sync.WaitGroup // in-flight deliveries
+
+	// Cached category counts
+	countsMu       sync.Mutex
+	categoryCounts []storage.CategoryCount
+	countsAt       time.Time
 }
 
 // NewService creates a new gallery service with default configuration.
@@ -79,6 +98,17 @@ func NewServiceWithConfig(repo storage.Repository, rateLimiter *ratelimit.Limite
 		log:         slogger,
 		pageSize:    cfg.PageSize,
 		defaultSort: cfg.DefaultSort,
+
+		archiveAfter: time.Duration(cfg.ArchiveAfterDays) * 24 * time.Hour,
+		archivePolicy: storage.ArchivePolicy{
+			MinViews:   cfg.ArchiveMinViews,
+			MinRatings: cfg.ArchiveMinRatings,
+			Delete:     cfg.ArchiveMode == "delete",
+		},
+
+		topRatedURL:       cfg.TopRatedWebhookURL,
+		topRatedMinRating: cfg.TopRatedMinRating,
+		topRatedMinVotes:  cfg.TopRatedMinVotes,
 	}
 }
 
@@ -99,15 +129,8 @@ func (s *Service) ListGenerations(ctx context.Context, req ListRequest) (*ListRe
 	}
 
 	// Validate and normalize inputs
-	if req.Page < 1 {
-		req.Page = 1
-	}
-	if req.PageSize < 1 {
-		req.PageSize = s.pageSize
-	}
-	if req.PageSize > MaxPageSize {
-		req.PageSize = MaxPageSize
-	}
+	req.Page = pagination.NormalizePage(req.Page)
+	req.PageSize = pagination.NormalizePageSizeWithDefault(req.PageSize, s.pageSize)
 
 	// Validate sort option
 	if req.SortBy == "" {
@@ -145,10 +168,7 @@ func (s *Service) ListGenerations(ctx context.Context, req ListRequest) (*ListRe
 	}
 
 	// Calculate total pages
-	totalPages := int(math.Ceil(float64(total) / float64(req.PageSize)))
-	if totalPages < 1 {
-		totalPages = 1
-	}
+	totalPages := pagination.TotalPages(total, req.PageSize)
 
 	// Log completion
 	if s.log != nil {
@@ -169,31 +189,10 @@ func (s *Service) ListGenerations(ctx context.Context, req ListRequest) (*ListRe
 	}, nil
 }
 
-// GetGeneration retrieves a single generation by ID and increments view count.
-// Deprecated: Use GetGenerationWithView for IP-deduplicated view tracking.
+// GetGeneration retrieves a single generation by ID. It is read-only: views
+// are registered separately through RecordView, so fetching a generation can
+// be cached and repeated without inflating its view count.
 func (s *Service) GetGeneration(ctx context.Context, id string) (*storage.Generation, error) {
-	if id == "" {
-		return nil, ErrInvalidInput
-	}
-
-	// Get the generation
-	gen, err := s.repo.GetGeneration(ctx, id)
-	if err != nil {
-		if errors.Is(err, storage.ErrNotFound) {
-			return nil, ErrNotFound
-		}
-		return nil, err
-	}
-
-	// Increment view count (fire and forget - don't fail if this fails)
-	_ = s.repo.IncrementViewCount(ctx, id)
-
-	return gen, nil
-}
-
-// GetGenerationWithView retrieves a single generation by ID and records a view
-// deduplicated by IP hash. Only increments view count for new unique views.
-func (s *Service) GetGenerationWithView(ctx context.Context, id string, ipHash string) (*storage.Generation, error) {
 	requestID := logger.GetRequestID(ctx)
 	start := time.Now()
 
@@ -237,35 +236,119 @@ func (s *Service) GetGenerationWithView(ctx context.Context, id string, ipHash s
 		return nil, err
 	}
 
-	// Record view with IP deduplication (fire and forget - don't fail if this fails)
-	newView := false
-	if ipHash != "" {
-		newView, _ = s.repo.RecordView(ctx, id, ipHash)
+	// Log completion
+	if s.log != nil {
+		s.log.Info("gallery_get_complete",
+			slog.String("request_id", requestID),
+			slog.String("generation_id", id),
+			slog.Duration("duration", time.Since(start)),
+		)
+	}
+
+	return gen, nil
+}
+
+// GetGenerationByShortCode resolves a share link's short code to its
+// generation. Like GetGeneration it is read-only. Codes that are not
+// well-formed are reported as not found without querying the database.
+func (s *Service) GetGenerationByShortCode(ctx context.Context, code string) (*storage.Generation, error) {
+	requestID := logger.GetRequestID(ctx)
+
+	if !storage.IsValidShortCode(code) {
+		return nil, ErrNotFound
+	}
+
+	gen, err := s.repo.GetGenerationByShortCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			if s.log != nil {
+				s.log.Warn("gallery_short_code_not_found",
+					slog.String("request_id", requestID),
+					slog.String("short_code", code),
+				)
+			}
+			return nil, ErrNotFound
+		}
+		if s.log != nil {
+			s.log.Error("gallery_short_code_failed",
+				slog.String("request_id", requestID),
+				slog.String("short_code", code),
+				slog.String("error", err.Error()),
+			)
+		}
+		return nil, err
+	}
+
+	if s.log != nil {
+		s.log.Info("gallery_short_code_resolved",
+			slog.String("request_id", requestID),
+			slog.String("short_code", code),
+			slog.String("generation_id", gen.ID),
+		)
+	}
+
+	return gen, nil
+}
+
+// RecordView registers a view of a generation, deduplicated by IP hash.
+// It reports whether the view was new; repeat views from the same IP are
+// accepted but not counted again. previousHashes are the viewer's hashes
+// under a rotated-out salt (privacy.PreviousHashes), so views recorded
+// before a rotation still count as repeats.
+func (s *Service) RecordView(ctx context.Context, id string, ipHash string, previousHashes ...string) (bool, error) {
+	requestID := logger.GetRequestID(ctx)
+
+	if id == "" || ipHash == "" {
+		return false, ErrInvalidInput
+	}
+
+	// Verify the generation exists so unknown IDs are reported as not found
+	if _, err := s.repo.GetGeneration(ctx, id); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return false, ErrNotFound
+		}
+		return false, err
+	}
+
+	// A view recorded before the IP salt was rotated is still a duplicate
+	for _, previous := range previousHashes {
+		viewed, err := s.repo.HasViewed(ctx, id, previous)
+		if err != nil {
+			return false, err
+		}
+		if viewed {
+			return false, nil
+		}
+	}
+
+	newView, err := s.repo.RecordView(ctx, id, ipHash)
+	if err != nil {
 		if s.log != nil {
-			s.log.Debug("gallery_view_recorded",
+			s.log.Error("gallery_view_failed",
 				slog.String("request_id", requestID),
 				slog.String("generation_id", id),
-				slog.Bool("new_view", newView),
+				slog.String("error", err.Error()),
 			)
 		}
+		return false, err
 	}
 
-	// Log completion
 	if s.log != nil {
-		s.log.Info("gallery_get_complete",
+		s.log.Debug("gallery_view_recorded",
 			slog.String("request_id", requestID),
 			slog.String("generation_id", id),
 			slog.Bool("new_view", newView),
-			slog.Duration("duration", time.Since(start)),
 		)
 	}
 
-	return gen, nil
+	return newView, nil
 }
 
 // RateGeneration submits or updates a rating for a generation.
-// Returns the retry-after duration if rate limited.
-func (s *Service) RateGeneration(ctx context.Context, genID string, score int, voterHash string, clientIP string) (retryAfter int, err error) {
+// Returns the retry-after duration if rate limited. A rating stored under
+// one of previousHashes, from before an IP salt rotation, is updated rather
+// than counted twice.
+func (s *Service) RateGeneration(ctx context.Context, genID string, score int, voterHash string, clientIP string, previousHashes ...string) (retryAfter int, err error) {
 	requestID := logger.GetRequestID(ctx)
 	start := time.Now()
 
@@ -298,8 +381,8 @@ func (s *Service) RateGeneration(ctx context.Context, genID string, score int, v
 		return 0, ErrInvalidRating
 	}
 
-	// Check rate limit if limiter is configured
-	if s.rateLimiter != nil {
+	// Check rate limit if limiter is configured; internal tooling is exempt
+	if s.rateLimiter != nil && !ratelimit.IsExempt(ctx) {
 		allowed, duration := s.rateLimiter.Allow(clientIP)
 		if !allowed {
 			if s.log != nil {
@@ -340,6 +423,12 @@ func (s *Service) RateGeneration(ctx context.Context, genID string, score int, v
 		return 0, err
 	}
 
+	// A rating made before the IP salt was rotated is updated in place
+	voterHash, err = s.ratingVoterHash(ctx, genID, voterHash, previousHashes)
+	if err != nil {
+		return 0, err
+	}
+
 	// Create or update rating
 	err = s.repo.CreateOrUpdateRating(ctx, genID, score, voterHash)
 	if err != nil {
@@ -353,6 +442,8 @@ func (s *Service) RateGeneration(ctx context.Context, genID string, score int, v
 		return 0, err
 	}
 
+	s.checkTopRated(ctx, genID)
+
 	// Log completion
 	if s.log != nil {
 		s.log.Info("gallery_rate_complete",
@@ -366,43 +457,157 @@ func (s *Service) RateGeneration(ctx context.Context, genID string, score int, v
 	return 0, nil
 }
 
-// GetUserRating retrieves the user's rating for a generation.
-// Returns 0 if the user hasn't rated the generation.
-func (s *Service) GetUserRating(ctx context.Context, genID string, voterHash string) (int, error) {
+// GetUserRating retrieves the user's rating for a generation, including one
+// stored under previousHashes. Returns 0 if the user hasn't rated the generation.
+func (s *Service) GetUserRating(ctx context.Context, genID string, voterHash string, previousHashes ...string) (int, error) {
 	if genID == "" || voterHash == "" {
 		return 0, ErrInvalidInput
 	}
 
+	voterHash, err := s.ratingVoterHash(ctx, genID, voterHash, previousHashes)
+	if err != nil {
+		return 0, err
+	}
 	return s.repo.GetUserRating(ctx, genID, voterHash)
 }
 
+// ratingVoterHash returns the hash a voter's rating of genID is stored
+// under: voterHash, unless the voter has only rated under one of their
+// previousHashes from before an IP salt rotation.
+func (s *Service) ratingVoterHash(ctx context.Context, genID, voterHash string, previousHashes []string) (string, error) {
+	if len(previousHashes) == 0 {
+		return voterHash, nil
+	}
+	if score, err := s.repo.GetUserRating(ctx, genID, voterHash); err != nil || score > 0 {
+		return voterHash, err
+	}
+	for _, previous := range previousHashes {
+		score, err := s.repo.GetUserRating(ctx, genID, previous)
+		if err != nil {
+			return voterHash, err
+		}
+		if score > 0 {
+			return previous, nil
+		}
+	}
+	return voterHash, nil
+}
+
 // GetCategories retrieves all available categories.
 func (s *Service) GetCategories(ctx context.Context) ([]storage.Category, error) {
 	return s.repo.GetCategories(ctx)
 }
 
-// CalculateTotalPages is a helper function to calculate total pages.
-// Exported for use in property tests.
-func CalculateTotalPages(total, pageSize int) int {
-	defaultCfg := config.DefaultConfig()
-	if pageSize <= 0 {
-		pageSize = defaultCfg.Gallery.PageSize
+// GetCategoriesWithCounts retrieves all categories with the number of listed
+// generations in each. Results are cached for categoryCountsTTL, so counts may
+// briefly lag behind new generations.
+func (s *Service) GetCategoriesWithCounts(ctx context.Context) ([]storage.CategoryCount, error) {
+	s.countsMu.Lock()
+	defer s.countsMu.Unlock()
+
+	if s.categoryCounts != nil && time.Since(s.countsAt) < categoryCountsTTL {
+		return s.categoryCounts, nil
 	}
-	if total <= 0 {
-		return 1
+
+	counts, err := s.repo.GetCategoryCounts(ctx)
+	if err != nil {
+		return nil, err
 	}
-	return int(math.Ceil(float64(total) / float64(pageSize)))
+
+	s.categoryCounts = counts
+	s.countsAt = time.Now()
+	return counts, nil
 }
 
-// NormalizePageSize ensures page size is within valid bounds.
-// Exported for use in property tests.
-func NormalizePageSize(pageSize int) int {
-	defaultCfg := config.DefaultConfig()
-	if pageSize < 1 {
-		return defaultCfg.Gallery.PageSize
+// ExportGenerations writes the whole gallery to w as NDJSON for backups.
+func (s *Service) ExportGenerations(ctx context.Context, w io.Writer) error {
+	requestID := logger.GetRequestID(ctx)
+	start := time.Now()
+
+	if err := s.repo.ExportGenerations(ctx, w); err != nil {
+		if s.log != nil {
+			s.log.Error("gallery_export_failed",
+				slog.String("request_id", requestID),
+				slog.String("error", err.Error()),
+			)
+		}
+		return err
 	}
-	if pageSize > MaxPageSize {
-		return MaxPageSize
+
+	if s.log != nil {
+		s.log.Info("gallery_export_complete",
+			slog.String("request_id", requestID),
+			slog.Duration("duration", time.Since(start)),
+		)
+	}
+	return nil
+}
+
+// StreamGenerations calls fn with every public generation, oldest first.
+// Unlike ExportGenerations it leaves out answers, so the stream is safe to
+// hand to bulk consumers.
+func (s *Service) StreamGenerations(ctx context.Context, fn func(*storage.Generation) error) error {
+	requestID := logger.GetRequestID(ctx)
+	start := time.Now()
+
+	count := 0
+	err := s.repo.StreamPublicGenerations(ctx, func(gen *storage.Generation) error {
+		count++
+		return fn(gen)
+	})
+	if err != nil {
+		if s.log != nil {
+			s.log.Error("gallery_stream_failed",
+				slog.String("request_id", requestID),
+				slog.Int("streamed", count),
+				slog.String("error", err.Error()),
+			)
+		}
+		return err
+	}
+
+	if s.log != nil {
+		s.log.Info("gallery_stream_complete",
+			slog.String("request_id", requestID),
+			slog.Int("streamed", count),
+			slog.Duration("duration", time.Since(start)),
+		)
+	}
+	return nil
+}
+
+// ImportGenerations restores an ExportGenerations stream, upserting every
+// generation in one transaction. Malformed records abort the whole import
+// with ErrInvalidImport.
+func (s *Service) ImportGenerations(ctx context.Context, r io.Reader) (int, error) {
+	requestID := logger.GetRequestID(ctx)
+	start := time.Now()
+
+	imported, err := s.repo.ImportGenerations(ctx, r)
+	if err != nil {
+		if s.log != nil {
+			s.log.Error("gallery_import_failed",
+				slog.String("request_id", requestID),
+				slog.String("error", err.Error()),
+			)
+		}
+		if errors.Is(err, storage.ErrInvalidInput) {
+			return 0, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+		}
+		return 0, err
+	}
+
+	// Imported generations change the per-category counts
+	s.countsMu.Lock()
+	s.categoryCounts = nil
+	s.countsMu.Unlock()
+
+	if s.log != nil {
+		s.log.Info("gallery_import_complete",
+			slog.String("request_id", requestID),
+			slog.Int("imported", imported),
+			slog.Duration("duration", time.Since(start)),
+		)
 	}
-	return pageSize
+	return imported, nil
 }
@@ -4,13 +4,17 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"math/rand"
 	"sort"
+	"strings"
 	"testing"
 	"testing/quick"
 	"time"
 
 	"better-kiro-prompts/internal/config"
+	"better-kiro-prompts/internal/pagination"
 	"better-kiro-prompts/internal/ratelimit"
 	"better-kiro-prompts/internal/storage"
 )
@@ -20,6 +24,9 @@ type mockRepository struct {
 	generations []storage.Generation
 	categories  []storage.Category
 	ratings     map[string]map[string]int // genID -> voterHash -> score
+	topRated    map[string]bool
+
+	countQueries int
 }
 
 func newMockRepository() *mockRepository {
@@ -35,6 +42,11 @@ func (m *mockRepository) CreateGeneration(_ context.Context, gen *storage.Genera
 		return storage.ErrInvalidInput
 	}
 	gen.ID = generateID()
+	code, err := storage.NewShortCode()
+	if err != nil {
+		return err
+	}
+	gen.ShortCode = code
 	gen.CreatedAt = time.Now()
 	m.generations = append(m.generations, *gen)
 	return nil
@@ -49,10 +61,61 @@ func (m *mockRepository) GetGeneration(_ context.Context, id string) (*storage.G
 	return nil, storage.ErrNotFound
 }
 
+func (m *mockRepository) GetGenerationFromPrimary(ctx context.Context, id string) (*storage.Generation, error) {
+	return m.GetGeneration(ctx, id)
+}
+
+func (m *mockRepository) GetGenerationByShortCode(_ context.Context, code string) (*storage.Generation, error) {
+	for i := range m.generations {
+		if m.generations[i].ShortCode == code {
+			return &m.generations[i], nil
+		}
+	}
+	return nil, storage.ErrNotFound
+}
+
+func (m *mockRepository) FindGenerationByContentHash(_ context.Context, hash string, since time.Time) (*storage.Generation, error) {
+	for i := len(m.generations) - 1; i >= 0; i-- {
+		gen := m.generations[i]
+		if hash != "" && gen.ContentHash == hash && gen.Visibility != storage.VisibilityUnlisted && !gen.CreatedAt.Before(since) {
+			return &gen, nil
+		}
+	}
+	return nil, storage.ErrNotFound
+}
+
+func (m *mockRepository) StreamPublicGenerations(_ context.Context, fn func(*storage.Generation) error) error {
+	for i := range m.generations {
+		if m.generations[i].Visibility == storage.VisibilityUnlisted {
+			continue
+		}
+		gen := m.generations[i]
+		if err := fn(&gen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *mockRepository) MarkTopRated(_ context.Context, genID string) (bool, error) {
+	if m.topRated == nil {
+		m.topRated = make(map[string]bool)
+	}
+	if m.topRated[genID] {
+		return false, nil
+	}
+	m.topRated[genID] = true
+	return true, nil
+}
+
 func (m *mockRepository) ListGenerations(_ context.Context, filter storage.ListFilter) ([]storage.Generation, int, error) {
 	// Apply category filter
 	filtered := []storage.Generation{}
 	for _, gen := range m.generations {
+		// Mirrors the repository: unlisted generations are never listed
+		if gen.Visibility == storage.VisibilityUnlisted {
+			continue
+		}
 		if filter.CategoryID != nil && gen.CategoryID != *filter.CategoryID {
 			continue
 		}
@@ -111,6 +174,14 @@ func (m *mockRepository) IncrementViewCount(_ context.Context, id string) error
 	return storage.ErrNotFound
 }
 
+func (m *mockRepository) RecordCreator(_ context.Context, _ string, _ string) error {
+	return nil
+}
+
+func (m *mockRepository) ListGenerationsByCreator(_ context.Context, _ string, _ int) ([]storage.Generation, error) {
+	return []storage.Generation{}, nil
+}
+
 func (m *mockRepository) RecordView(_ context.Context, generationID string, ipHash string) (bool, error) {
 	if generationID == "" || ipHash == "" {
 		return false, storage.ErrInvalidInput
@@ -144,6 +215,10 @@ func (m *mockRepository) RecordView(_ context.Context, generationID string, ipHa
 	return true, nil
 }
 
+func (m *mockRepository) HasViewed(_ context.Context, generationID string, ipHash string) (bool, error) {
+	return m.ratings["view:"+generationID+":"+ipHash] != nil, nil
+}
+
 func (m *mockRepository) CreateOrUpdateRating(_ context.Context, genID string, score int, voterHash string) error {
 	if score < 1 || score > 5 {
 		return storage.ErrInvalidInput
@@ -201,6 +276,58 @@ func (m *mockRepository) GetCategories(_ context.Context) ([]storage.Category, e
 	return m.categories, nil
 }
 
+func (m *mockRepository) GetCategoryCounts(_ context.Context) ([]storage.CategoryCount, error) {
+	m.countQueries++
+	counts := make([]storage.CategoryCount, len(m.categories))
+	for i, cat := range m.categories {
+		counts[i].Category = cat
+		for _, gen := range m.generations {
+			if gen.CategoryID == cat.ID && gen.Visibility != storage.VisibilityUnlisted {
+				counts[i].Count++
+			}
+		}
+	}
+	return counts, nil
+}
+
+func (m *mockRepository) ExportGenerations(_ context.Context, w io.Writer) error {
+	enc := json.NewEncoder(w)
+	for _, gen := range m.generations {
+		if err := enc.Encode(storage.ExportedGeneration{ID: gen.ID, ProjectIdea: gen.ProjectIdea, Files: gen.Files}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *mockRepository) ImportGenerations(_ context.Context, r io.Reader) (int, error) {
+	dec := json.NewDecoder(r)
+	imported := 0
+	for dec.More() {
+		var gen storage.ExportedGeneration
+		if err := dec.Decode(&gen); err != nil {
+			return 0, fmt.Errorf("%w: %v", storage.ErrInvalidInput, err)
+		}
+		m.generations = append(m.generations, storage.Generation{ID: gen.ID, ProjectIdea: gen.ProjectIdea, Files: gen.Files})
+		imported++
+	}
+	return imported, nil
+}
+
+func (m *mockRepository) ArchiveGenerations(_ context.Context, policy storage.ArchivePolicy) (int, error) {
+	kept := m.generations[:0]
+	for _, gen := range m.generations {
+		popular := policy.MinViews > 0 && gen.ViewCount >= policy.MinViews ||
+			policy.MinRatings > 0 && gen.RatingCount >= policy.MinRatings
+		if popular || !gen.CreatedAt.Before(policy.OlderThan) {
+			kept = append(kept, gen)
+		}
+	}
+	archived := len(m.generations) - len(kept)
+	m.generations = kept
+	return archived, nil
+}
+
 // Helper functions for generating test data
 
 var idCounter int
@@ -500,14 +627,14 @@ func TestProperty7_PaginationBounds(t *testing.T) {
 		}
 
 		// Verify items count doesn't exceed page size
-		normalizedPageSize := NormalizePageSize(pageSize)
+		normalizedPageSize := pagination.NormalizePageSize(pageSize)
 		if len(resp.Items) > normalizedPageSize {
 			t.Logf("Items count %d exceeds page size %d", len(resp.Items), normalizedPageSize)
 			return false
 		}
 
 		// Verify total pages calculation
-		expectedTotalPages := CalculateTotalPages(resp.Total, normalizedPageSize)
+		expectedTotalPages := pagination.TotalPages(resp.Total, normalizedPageSize)
 		if resp.TotalPages != expectedTotalPages {
 			t.Logf("TotalPages %d doesn't match expected %d (total=%d, pageSize=%d)",
 				resp.TotalPages, expectedTotalPages, resp.Total, normalizedPageSize)
@@ -589,11 +716,11 @@ func TestProperty7_PaginationMaxPageSize(t *testing.T) {
 	}
 
 	// Verify page size is capped at 100
-	if len(resp.Items) > MaxPageSize {
-		t.Errorf("Page size not capped: got %d items, max is %d", len(resp.Items), MaxPageSize)
+	if len(resp.Items) > pagination.MaxPageSize {
+		t.Errorf("Page size not capped: got %d items, max is %d", len(resp.Items), pagination.MaxPageSize)
 	}
-	if resp.PageSize != MaxPageSize {
-		t.Errorf("PageSize in response: got %d, expected %d", resp.PageSize, MaxPageSize)
+	if resp.PageSize != pagination.MaxPageSize {
+		t.Errorf("PageSize in response: got %d, expected %d", resp.PageSize, pagination.MaxPageSize)
 	}
 }
 
@@ -690,10 +817,45 @@ func TestService_GetGeneration(t *testing.T) {
 		t.Errorf("Expected ID test-gen-1, got %s", result.ID)
 	}
 
-	// View count should be incremented
+	// Fetching is read-only; views are recorded through RecordView
+	if repo.generations[0].ViewCount != 5 {
+		t.Errorf("Expected view count to stay 5, got %d", repo.generations[0].ViewCount)
+	}
+}
+
+func TestService_RecordView(t *testing.T) {
+	repo := newMockRepository()
+	svc := NewService(repo, nil, nil)
+	ctx := context.Background()
+	repo.generations = append(repo.generations, storage.Generation{ID: "test-gen-1", ViewCount: 5})
+
+	for i := 0; i < 3; i++ {
+		if _, err := svc.GetGeneration(ctx, "test-gen-1"); err != nil {
+			t.Fatalf("GetGeneration failed: %v", err)
+		}
+	}
+	if repo.generations[0].ViewCount != 5 {
+		t.Fatalf("GET changed the view count to %d", repo.generations[0].ViewCount)
+	}
+
+	counted, err := svc.RecordView(ctx, "test-gen-1", "ip-hash-1")
+	if err != nil || !counted {
+		t.Fatalf("RecordView = %v, %v; want a counted view", counted, err)
+	}
+	counted, err = svc.RecordView(ctx, "test-gen-1", "ip-hash-1")
+	if err != nil || counted {
+		t.Errorf("repeat RecordView = %v, %v; want accepted but not counted", counted, err)
+	}
 	if repo.generations[0].ViewCount != 6 {
 		t.Errorf("Expected view count 6, got %d", repo.generations[0].ViewCount)
 	}
+
+	if _, err := svc.RecordView(ctx, "nonexistent", "ip-hash-1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for unknown generation, got %v", err)
+	}
+	if _, err := svc.RecordView(ctx, "test-gen-1", ""); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput without an IP hash, got %v", err)
+	}
 }
 
 func TestService_GetGeneration_NotFound(t *testing.T) {
@@ -716,6 +878,102 @@ func TestService_GetGeneration_EmptyID(t *testing.T) {
 	}
 }
 
+func TestService_UnlistedGenerations(t *testing.T) {
+	repo := newMockRepository()
+	svc := NewService(repo, nil, nil)
+	ctx := context.Background()
+
+	public := &storage.Generation{ProjectIdea: "Public project", ExperienceLevel: "novice", HookPreset: "default", Files: json.RawMessage(`[]`), CategoryID: 1}
+	unlisted := &storage.Generation{ProjectIdea: "Private project", ExperienceLevel: "novice", HookPreset: "default", Files: json.RawMessage(`[]`), CategoryID: 1, Visibility: storage.VisibilityUnlisted}
+	for _, gen := range []*storage.Generation{public, unlisted} {
+		if err := repo.CreateGeneration(ctx, gen); err != nil {
+			t.Fatalf("CreateGeneration failed: %v", err)
+		}
+	}
+
+	resp, err := svc.ListGenerations(ctx, ListRequest{})
+	if err != nil {
+		t.Fatalf("ListGenerations failed: %v", err)
+	}
+	if resp.Total != 1 || len(resp.Items) != 1 || resp.Items[0].ID != public.ID {
+		t.Errorf("expected only the public generation to be listed, got total %d items %+v", resp.Total, resp.Items)
+	}
+
+	// Reachable by direct ID, with views and ratings still recorded
+	if _, err := svc.RecordView(ctx, unlisted.ID, "ip-hash-1"); err != nil {
+		t.Fatalf("RecordView failed for unlisted generation: %v", err)
+	}
+	got, err := svc.GetGeneration(ctx, unlisted.ID)
+	if err != nil {
+		t.Fatalf("GetGeneration failed for unlisted generation: %v", err)
+	}
+	if got.ID != unlisted.ID {
+		t.Errorf("ID = %s, want %s", got.ID, unlisted.ID)
+	}
+	if got.ViewCount != 1 {
+		t.Errorf("ViewCount = %d, want 1", got.ViewCount)
+	}
+
+	if _, err := svc.RateGeneration(ctx, unlisted.ID, 4, "voter-1", "10.0.0.1"); err != nil {
+		t.Fatalf("RateGeneration failed for unlisted generation: %v", err)
+	}
+	score, err := svc.GetUserRating(ctx, unlisted.ID, "voter-1")
+	if err != nil || score != 4 {
+		t.Errorf("GetUserRating = %d, %v; want 4", score, err)
+	}
+}
+
+func TestService_GetCategoriesWithCounts(t *testing.T) {
+	repo := newMockRepository()
+	svc := NewService(repo, nil, nil)
+	ctx := context.Background()
+
+	add := func(categoryID int, visibility string) {
+		gen := &storage.Generation{ProjectIdea: "Project", ExperienceLevel: "novice", HookPreset: "default", Files: json.RawMessage(`[]`), CategoryID: categoryID, Visibility: visibility}
+		if err := repo.CreateGeneration(ctx, gen); err != nil {
+			t.Fatalf("CreateGeneration failed: %v", err)
+		}
+	}
+	add(1, storage.VisibilityPublic)
+	add(1, "")
+	add(1, storage.VisibilityUnlisted)
+	add(3, storage.VisibilityPublic)
+
+	counts, err := svc.GetCategoriesWithCounts(ctx)
+	if err != nil {
+		t.Fatalf("GetCategoriesWithCounts failed: %v", err)
+	}
+	if len(counts) != len(repo.categories) {
+		t.Fatalf("expected %d categories, got %d", len(repo.categories), len(counts))
+	}
+
+	want := map[int]int{1: 2, 3: 1}
+	for _, c := range counts {
+		if c.Count != want[c.ID] {
+			t.Errorf("category %d (%s): count = %d, want %d", c.ID, c.Name, c.Count, want[c.ID])
+		}
+
+		// Counts agree with what the gallery actually lists
+		id := c.ID
+		resp, err := svc.ListGenerations(ctx, ListRequest{CategoryID: &id})
+		if err != nil {
+			t.Fatalf("ListGenerations failed: %v", err)
+		}
+		if resp.Total != c.Count {
+			t.Errorf("category %d: count = %d but listing has %d", c.ID, c.Count, resp.Total)
+		}
+	}
+
+	// A second call within the TTL is served from cache
+	add(3, storage.VisibilityPublic)
+	if _, err := svc.GetCategoriesWithCounts(ctx); err != nil {
+		t.Fatalf("GetCategoriesWithCounts failed: %v", err)
+	}
+	if repo.countQueries != 1 {
+		t.Errorf("expected 1 count query, got %d", repo.countQueries)
+	}
+}
+
 func TestService_InvalidSortOption(t *testing.T) {
 	repo := newMockRepository()
 	svc := NewService(repo, nil, nil)
@@ -1149,3 +1407,65 @@ func TestProperty10_RatingLimiterConfiguration(t *testing.T) {
 		t.Error("Expected positive retry-after duration")
 	}
 }
+
+func TestGetGenerationByShortCode(t *testing.T) {
+	repo := newMockRepository()
+	svc := NewService(repo, nil, nil)
+
+	var stored []storage.Generation
+	for _, idea := range []string{"A todo app", "A chat bot"} {
+		gen := &storage.Generation{ProjectIdea: idea, Files: json.RawMessage(`[]`)}
+		if err := repo.CreateGeneration(context.Background(), gen); err != nil {
+			t.Fatalf("CreateGeneration() error = %v", err)
+		}
+		stored = append(stored, *gen)
+	}
+	if stored[0].ShortCode == "" || stored[0].ShortCode == stored[1].ShortCode {
+		t.Fatalf("short codes %q and %q, want two distinct codes", stored[0].ShortCode, stored[1].ShortCode)
+	}
+
+	for _, want := range stored {
+		got, err := svc.GetGenerationByShortCode(context.Background(), want.ShortCode)
+		if err != nil {
+			t.Fatalf("GetGenerationByShortCode(%q) error = %v", want.ShortCode, err)
+		}
+		if got.ID != want.ID {
+			t.Errorf("GetGenerationByShortCode(%q) = %s, want %s", want.ShortCode, got.ID, want.ID)
+		}
+	}
+
+	for _, code := range []string{"zzzzzzzz", "not-a-code", ""} {
+		if _, err := svc.GetGenerationByShortCode(context.Background(), code); !errors.Is(err, ErrNotFound) {
+			t.Errorf("GetGenerationByShortCode(%q) error = %v, want ErrNotFound", code, err)
+		}
+	}
+}
+
+func TestImportGenerations_InvalidatesCategoryCounts(t *testing.T) {
+	repo := newMockRepository()
+	svc := NewService(repo, nil, nil)
+	ctx := context.Background()
+
+	if _, err := svc.GetCategoriesWithCounts(ctx); err != nil {
+		t.Fatalf("GetCategoriesWithCounts() error = %v", err)
+	}
+	imported, err := svc.ImportGenerations(ctx, strings.NewReader(`{"id":"gen-1","projectIdea":"A CLI tool","files":[]}`+"\n"))
+	if err != nil || imported != 1 {
+		t.Fatalf("ImportGenerations() = %d, %v; want 1, nil", imported, err)
+	}
+	if _, err := svc.GetCategoriesWithCounts(ctx); err != nil {
+		t.Fatalf("GetCategoriesWithCounts() error = %v", err)
+	}
+	if repo.countQueries != 2 {
+		t.Errorf("count queries = %d, want 2 (import must invalidate the cache)", repo.countQueries)
+	}
+}
+
+func TestImportGenerations_InvalidInput(t *testing.T) {
+	svc := NewService(newMockRepository(), nil, nil)
+
+	_, err := svc.ImportGenerations(context.Background(), strings.NewReader("not json"))
+	if !errors.Is(err, ErrInvalidImport) {
+		t.Errorf("error = %v, want ErrInvalidImport", err)
+	}
+}
@@ -0,0 +1,165 @@
+package gallery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"better-kiro-prompts/internal/logger"
+	"better-kiro-prompts/internal/storage"
+)
+
+// topRatedEventName identifies TopRatedEvent payloads.
+const topRatedEventName = "generation.top_rated"
+
+// webhookTimeout bounds each top-rated webhook delivery.
+const webhookTimeout = 10 * time.Second
+
+// TopRatedEvent is posted to gallery.top_rated_webhook_url the first time a
+// public generation reaches the configured average rating with enough votes.
+type TopRatedEvent struct {
+	Event        string  `json:"event"`
+	GenerationID string  `json:"generationId"`
+	ProjectIdea  string  `json:"projectIdea"`
+	Category     string  `json:"category"`
+	ShortCode    string  `json:"shortCode,omitempty"`
+	AvgRating    float64 `json:"avgRating"`
+	RatingCount  int     `json:"ratingCount"`
+	CrossedAt    string  `json:"crossedAt"`
+}
+
+// isTopRated reports whether gen meets the top-rated threshold.
+func (s *Service) isTopRated(gen *storage.Generation) bool {
+	return gen.Visibility != storage.VisibilityUnlisted &&
+		gen.RatingCount >= s.topRatedMinVotes &&
+		gen.AvgRating >= s.topRatedMinRating
+}
+
+// checkTopRated fires the top-rated webhook if the rating just written took
+// the generation over the threshold. The repository records each generation
+// the first time it crosses, so later votes never fire it again. The
+// generation is read from the primary so the new rating is counted. Delivery
+// runs in the background and never fails the rating.
+func (s *Service) checkTopRated(ctx context.Context, genID string) {
+	if s.topRatedURL == "" {
+		return
+	}
+	requestID := logger.GetRequestID(ctx)
+
+	gen, err := s.repo.GetGenerationFromPrimary(ctx, genID)
+	if err != nil {
+		if s.log != nil {
+			s.log.Warn("gallery_top_rated_check_failed",
+				slog.String("request_id", requestID),
+				slog.String("generation_id", genID),
+				slog.String("error", err.Error()),
+			)
+		}
+		return
+	}
+	if !s.isTopRated(gen) {
+		return
+	}
+
+	first, err := s.repo.MarkTopRated(ctx, genID)
+	if err != nil {
+		if s.log != nil {
+			s.log.Warn("gallery_top_rated_check_failed",
+				slog.String("request_id", requestID),
+				slog.String("generation_id", genID),
+				slog.String("error", err.Error()),
+			)
+		}
+		return
+	}
+	if !first {
+		return
+	}
+
+	event := TopRatedEvent{
+		Event:        topRatedEventName,
+		GenerationID: gen.ID,
+		ProjectIdea:  gen.ProjectIdea,
+		Category:     gen.CategoryName,
+		ShortCode:    gen.ShortCode,
+		AvgRating:    gen.AvgRating,
+		RatingCount:  gen.RatingCount,
+		CrossedAt:    time.Now().UTC().Format(time.RFC3339),
+	}
+	s.webhooks.Add(1)
+	go func() {
+		defer s.webhooks.Done()
+		s.sendTopRated(context.WithoutCancel(ctx), event)
+	}()
+}
+
+// WaitWebhooks waits for in-flight top-rated webhook deliveries, or until ctx
+// is done. It should be called during shutdown, after the server stops
+// accepting ratings.
+func (s *Service) WaitWebhooks(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.webhooks.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// sendTopRated posts event to the top-rated webhook and logs the outcome.
+// A failed delivery is not retried.
+func (s *Service) sendTopRated(ctx context.Context, event TopRatedEvent) {
+	requestID := logger.GetRequestID(ctx)
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+
+	err := postJSON(ctx, s.topRatedURL, event)
+	if s.log == nil {
+		return
+	}
+	if err != nil {
+		s.log.Error("gallery_top_rated_webhook_failed",
+			slog.String("request_id", requestID),
+			slog.String("generation_id", event.GenerationID),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+	s.log.Info("gallery_top_rated_webhook_sent",
+		slog.String("request_id", requestID),
+		slog.String("generation_id", event.GenerationID),
+		slog.Float64("avg_rating", event.AvgRating),
+		slog.Int("rating_count", event.RatingCount),
+	)
+}
+
+// postJSON posts v as JSON to url and fails on a non-2xx response.
+func postJSON(ctx context.Context, url string, v any) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package gallery
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"better-kiro-prompts/internal/config"
+	"better-kiro-prompts/internal/storage"
+)
+
+func TestRateGeneration_TopRatedWebhookFiresOnce(t *testing.T) {
+	var mu sync.Mutex
+	var events []TopRatedEvent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event TopRatedEvent
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Errorf("decode webhook body: %v", err)
+		}
+		mu.Lock()
+		events = append(events, event)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	repo := storage.NewMemoryRepository()
+	gen := &storage.Generation{ProjectIdea: "A habit tracker", Files: json.RawMessage(`[]`), CategoryID: 1}
+	if err := repo.CreateGeneration(ctx, gen); err != nil {
+		t.Fatalf("CreateGeneration() error = %v", err)
+	}
+
+	cfg := config.DefaultConfig().Gallery
+	cfg.TopRatedWebhookURL = server.URL
+	cfg.TopRatedMinRating = 4.5
+	cfg.TopRatedMinVotes = 3
+	svc := NewServiceWithConfig(repo, nil, nil, cfg)
+
+	// 5, 5 has too few votes; 5, 5, 4 crosses; later votes keep it above the threshold
+	for i, score := range []int{5, 5, 4, 5, 5} {
+		if _, err := svc.RateGeneration(ctx, gen.ID, score, fmt.Sprintf("voter-%d", i), ""); err != nil {
+			t.Fatalf("RateGeneration() #%d error = %v", i+1, err)
+		}
+		svc.webhooks.Wait()
+
+		mu.Lock()
+		got := len(events)
+		mu.Unlock()
+		want := 0
+		if i >= 2 {
+			want = 1
+		}
+		if got != want {
+			t.Fatalf("after vote %d: %d webhook events, want %d", i+1, got, want)
+		}
+	}
+
+	event := events[0]
+	if event.Event != topRatedEventName || event.GenerationID != gen.ID || event.RatingCount != 3 {
+		t.Errorf("event = %+v, want %s for %s at 3 votes", event, topRatedEventName, gen.ID)
+	}
+}
+
+func TestRateGeneration_TopRatedSkipsUnlisted(t *testing.T) {
+	repo := newMockRepository()
+	repo.generations = append(repo.generations, storage.Generation{ID: "gen-1", Visibility: storage.VisibilityUnlisted, AvgRating: 5, RatingCount: 50})
+
+	cfg := config.DefaultConfig().Gallery
+	cfg.TopRatedWebhookURL = "http://127.0.0.1:1/never-called"
+	svc := NewServiceWithConfig(repo, nil, nil, cfg)
+
+	if _, err := svc.RateGeneration(context.Background(), "gen-1", 5, "voter", ""); err != nil {
+		t.Fatalf("RateGeneration() error = %v", err)
+	}
+	svc.webhooks.Wait()
+	if repo.topRated["gen-1"] {
+		t.Error("unlisted generation was marked top-rated")
+	}
+}
+
+func TestWaitWebhooks_WaitsForDelivery(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+
+	repo := newMockRepository()
+	repo.generations = append(repo.generations, storage.Generation{ID: "gen-1", AvgRating: 5, RatingCount: 50})
+	cfg := config.DefaultConfig().Gallery
+	cfg.TopRatedWebhookURL = server.URL
+	cfg.TopRatedMinVotes = 1
+	svc := NewServiceWithConfig(repo, nil, nil, cfg)
+
+	if _, err := svc.RateGeneration(context.Background(), "gen-1", 5, "voter", ""); err != nil {
+		t.Fatalf("RateGeneration() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := svc.WaitWebhooks(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("WaitWebhooks() with delivery pending = %v, want DeadlineExceeded", err)
+	}
+
+	close(release)
+	if err := svc.WaitWebhooks(context.Background()); err != nil {
+		t.Errorf("WaitWebhooks() after delivery = %v, want nil", err)
+	}
+}
@@ -0,0 +1,149 @@
+package generation
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"unicode"
+
+	"better-kiro-prompts/internal/logger"
+)
+
+// Answer hint codes reported by AssessAnswers.
+const (
+	HintEmpty    = "empty"
+	HintEvasive  = "evasive"
+	HintTooShort = "too_short"
+	HintOffTopic = "off_topic"
+)
+
+// Answer quality heuristics
+const (
+	minAnswerWords = 3
+	// Answers at least this long are assumed to be on topic
+	detailedAnswerWords = 8
+	minKeywordLength    = 4
+)
+
+// AnswerHint is a quality note for one answer, meant to prompt the user to
+// improve it before generating outputs.
+type AnswerHint struct {
+	QuestionID int    `json:"questionId"`
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+}
+
+// evasiveAnswers are non-answers that give the model nothing to work with.
+var evasiveAnswers = map[string]bool{
+	"idk": true, "i dont know": true, "i don't know": true, "dont know": true,
+	"don't know": true, "dunno": true, "no idea": true, "not sure": true,
+	"none": true, "nothing": true, "n/a": true, "na": true, "whatever": true,
+	"anything": true, "skip": true, "tbd": true, "?": true, "-": true,
+}
+
+// keywordStopwords are common question words that do not indicate the topic.
+var keywordStopwords = map[string]bool{
+	"what": true, "which": true, "when": true, "where": true, "will": true,
+	"would": true, "should": true, "does": true, "your": true, "with": true,
+	"that": true, "this": true, "have": true, "they": true, "them": true,
+	"there": true, "their": true, "from": true, "into": true, "about": true,
+	"need": true, "want": true, "like": true, "many": true, "much": true,
+	"kind": true, "type": true, "types": true, "some": true, "other": true,
+	"project": true, "app": true,
+}
+
+// AssessAnswers flags answers that are empty, evasive, very short, or brief
+// and unrelated to their question, using simple heuristics and no model call.
+// Unanswered questions are reported as empty. Hints follow question order,
+// then any answers to questions not in the list; substantive answers get none.
+func (s *Service) AssessAnswers(ctx context.Context, questions []Question, answers []Answer) []AnswerHint {
+	byQuestion := make(map[int]string, len(answers))
+	for _, a := range answers {
+		byQuestion[a.QuestionID] = a.Answer
+	}
+
+	hints := []AnswerHint{}
+	asked := make(map[int]bool, len(questions))
+	for _, q := range questions {
+		asked[q.ID] = true
+		if hint, ok := assessAnswer(q.ID, q.Text, byQuestion[q.ID]); ok {
+			hints = append(hints, hint)
+		}
+	}
+	for _, a := range answers {
+		if asked[a.QuestionID] {
+			continue
+		}
+		if hint, ok := assessAnswer(a.QuestionID, "", a.Answer); ok {
+			hints = append(hints, hint)
+		}
+	}
+
+	s.log.Debug("assess_answers_complete",
+		slog.String("request_id", logger.GetRequestID(ctx)),
+		slog.Int("answer_count", len(answers)),
+		slog.Int("hint_count", len(hints)),
+	)
+
+	return hints
+}
+
+// assessAnswer returns the hint for a single answer, if it needs one. An
+// empty question text skips the topic check.
+func assessAnswer(questionID int, question, answer string) (AnswerHint, bool) {
+	answer = strings.TrimSpace(answer)
+	normalized := strings.ToLower(strings.TrimRight(answer, ".!"))
+	words := strings.Fields(answer)
+
+	switch {
+	case answer == "":
+		return AnswerHint{QuestionID: questionID, Code: HintEmpty,
+			Message: "This question has no answer yet."}, true
+	case evasiveAnswers[normalized]:
+		return AnswerHint{QuestionID: questionID, Code: HintEvasive,
+			Message: "This answer doesn't give any direction. Even a rough guess helps the generated files fit your project."}, true
+	case len(words) < minAnswerWords:
+		return AnswerHint{QuestionID: questionID, Code: HintTooShort,
+			Message: "This answer is very short. A sentence or two of detail will make the generated files more specific."}, true
+	case question != "" && len(words) < detailedAnswerWords && !sharesKeyword(question, answer):
+		return AnswerHint{QuestionID: questionID, Code: HintOffTopic,
+			Message: "This answer may not address the question. Check that it answers what was asked."}, true
+	}
+	return AnswerHint{}, false
+}
+
+// sharesKeyword reports whether the answer mentions any significant word of
+// the question. Words match when one is a prefix of the other, so "user"
+// matches "users". A question without keywords always matches.
+func sharesKeyword(question, answer string) bool {
+	keywords := keywordsOf(question)
+	if len(keywords) == 0 {
+		return true
+	}
+	for _, word := range splitWords(answer) {
+		for _, kw := range keywords {
+			if strings.HasPrefix(word, kw) || (len(word) >= minKeywordLength && strings.HasPrefix(kw, word)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// keywordsOf returns the significant lowercase words of a question.
+func keywordsOf(text string) []string {
+	var keywords []string
+	for _, word := range splitWords(text) {
+		if len(word) >= minKeywordLength && !keywordStopwords[word] {
+			keywords = append(keywords, word)
+		}
+	}
+	return keywords
+}
+
+// splitWords splits text into lowercase words of letters and digits.
+func splitWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
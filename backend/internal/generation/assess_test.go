@@ -0,0 +1,71 @@
+package generation
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAssessAnswers(t *testing.T) {
+	svc := NewService(nil)
+	questions := []Question{
+		{ID: 1, Text: "How will users sign in to the app?"},
+		{ID: 2, Text: "Which database should store the tasks?"},
+		{ID: 3, Text: "Who are the main users?"},
+	}
+
+	tests := []struct {
+		name     string
+		answer   string
+		wantCode string
+	}{
+		{"empty", "", HintEmpty},
+		{"whitespace", "   ", HintEmpty},
+		{"evasive", "idk", HintEvasive},
+		{"evasive with punctuation", "Not sure.", HintEvasive},
+		{"one word", "Postgres", HintTooShort},
+		{"two words", "email login", HintTooShort},
+		{"brief and unrelated", "Blue and green colours", HintOffTopic},
+		{"brief but on topic", "Users sign in with Google", ""},
+		{"substantive", "Email and password with a magic link fallback for people who forget passwords", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hints := svc.AssessAnswers(context.Background(), questions[:1], []Answer{{QuestionID: 1, Answer: tt.answer}})
+			if tt.wantCode == "" {
+				if len(hints) != 0 {
+					t.Errorf("expected no hints, got %+v", hints)
+				}
+				return
+			}
+			if len(hints) != 1 || hints[0].Code != tt.wantCode || hints[0].QuestionID != 1 {
+				t.Fatalf("hints = %+v, want one %q hint for question 1", hints, tt.wantCode)
+			}
+			if hints[0].Message == "" {
+				t.Error("expected a message with the hint")
+			}
+		})
+	}
+}
+
+func TestAssessAnswers_UnansweredAndUnknownQuestions(t *testing.T) {
+	svc := NewService(nil)
+	questions := []Question{
+		{ID: 1, Text: "How will users sign in?"},
+		{ID: 2, Text: "Which database should store the tasks?"},
+	}
+	answers := []Answer{
+		{QuestionID: 2, Answer: "PostgreSQL, because the tasks are relational"},
+		{QuestionID: 7, Answer: "idk"},
+	}
+
+	hints := svc.AssessAnswers(context.Background(), questions, answers)
+	if len(hints) != 2 {
+		t.Fatalf("expected 2 hints, got %+v", hints)
+	}
+	if hints[0].QuestionID != 1 || hints[0].Code != HintEmpty {
+		t.Errorf("hints[0] = %+v, want question 1 reported empty", hints[0])
+	}
+	if hints[1].QuestionID != 7 || hints[1].Code != HintEvasive {
+		t.Errorf("hints[1] = %+v, want question 7 reported evasive", hints[1])
+	}
+}
@@ -0,0 +1,105 @@
+package generation
+
+import (
+	"errors"
+	"fmt"
+
+	"better-kiro-prompts/internal/openai"
+	"better-kiro-prompts/internal/prompts"
+)
+
+// Prompt budget limits
+const (
+	defaultMaxPromptTokens = 32000
+	// charsPerToken is a rough estimate that holds well for English prose and JSON
+	charsPerToken = 4
+	// minTruncatedAnswerLength is the shortest an answer is cut to, in runes
+	minTruncatedAnswerLength = 200
+	truncationNote           = " [truncated to fit the model's context window]"
+)
+
+// ErrPromptTooLarge is returned when the assembled prompt cannot be brought
+// within the token budget by shortening answers.
+var ErrPromptTooLarge = errors.New("prompt exceeds the model context budget")
+
+// estimateTokens returns a rough token count for the messages.
+func estimateTokens(messages []openai.Message) int {
+	chars := 0
+	for _, m := range messages {
+		chars += len(m.Content)
+	}
+	return (chars + charsPerToken - 1) / charsPerToken
+}
+
+// withHouseRules returns messages with the configured prompt prefix and
+// suffix applied to the system message. messages is not modified.
+func (s *Service) withHouseRules(messages []openai.Message) []openai.Message {
+	if s.promptPrefix == "" && s.promptSuffix == "" {
+		return messages
+	}
+	wrapped := make([]openai.Message, len(messages))
+	copy(wrapped, messages)
+	for i, m := range wrapped {
+		if m.Role == "system" {
+			wrapped[i].Content = prompts.WithHouseRules(m.Content, s.promptPrefix, s.promptSuffix)
+		}
+	}
+	return wrapped
+}
+
+// promptBudget returns the token budget left for the composed messages once
+// the house rules are accounted for.
+func (s *Service) promptBudget() int {
+	rules := prompts.WithHouseRules("", s.promptPrefix, s.promptSuffix)
+	return s.maxPromptTokens - estimateTokens([]openai.Message{{Content: rules}})
+}
+
+// fitOutputsMessages composes the output generation messages within maxTokens.
+// Answers are the least important content, so while the prompt is over budget
+// the longest answer is halved (never below minTruncatedAnswerLength) and
+// marked as truncated. It returns the messages and how many answers were cut,
+// or ErrPromptTooLarge if no answer can be shortened further.
+func fitOutputsMessages(projectIdea string, answers []Answer, experienceLevel string, hookPreset string, maxTokens int) ([]openai.Message, int, error) {
+	return fitAnswers(answers, maxTokens, func(answers []Answer) []openai.Message {
+		return buildOutputsMessages(projectIdea, answers, experienceLevel, hookPreset)
+	})
+}
+
+// fitAnswers composes messages with build, shortening answers as described
+// for fitOutputsMessages until they fit within maxTokens.
+func fitAnswers(answers []Answer, maxTokens int, build func([]Answer) []openai.Message) ([]openai.Message, int, error) {
+	messages := build(answers)
+	estimate := estimateTokens(messages)
+	if estimate <= maxTokens {
+		return messages, 0, nil
+	}
+
+	fitted := make([]Answer, len(answers))
+	copy(fitted, answers)
+	bodies := make([][]rune, len(answers))
+	for i, a := range answers {
+		bodies[i] = []rune(a.Answer)
+	}
+	truncated := make(map[int]bool)
+
+	for estimate > maxTokens {
+		longest := -1
+		for i, body := range bodies {
+			if len(body) > minTruncatedAnswerLength && (longest == -1 || len(body) > len(bodies[longest])) {
+				longest = i
+			}
+		}
+		if longest == -1 {
+			return nil, len(truncated), fmt.Errorf("%w: estimated %d tokens, budget is %d", ErrPromptTooLarge, estimate, maxTokens)
+		}
+
+		bodies[longest] = bodies[longest][:max(minTruncatedAnswerLength, len(bodies[longest])/2)]
+		fitted[longest].Answer = string(bodies[longest]) + truncationNote
+		truncated[longest] = true
+
+		messages = build(fitted)
+		estimate = estimateTokens(messages)
+	}
+
+	return messages, len(truncated), nil
+}
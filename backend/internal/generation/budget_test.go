@@ -0,0 +1,78 @@
+package generation
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"better-kiro-prompts/internal/openai"
+)
+
+func longAnswers(n, length int) []Answer {
+	answers := make([]Answer, n)
+	for i := range answers {
+		answers[i] = Answer{QuestionID: i + 1, Answer: strings.Repeat("detail ", length/7)}
+	}
+	return answers
+}
+
+func TestFitOutputsMessages_WithinBudgetUnchanged(t *testing.T) {
+	answers := longAnswers(3, 100)
+	want := buildOutputsMessages("A task tracker", answers, "novice", "default")
+
+	got, truncated, err := fitOutputsMessages("A task tracker", answers, "novice", "default", defaultMaxPromptTokens)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if truncated != 0 {
+		t.Errorf("truncated = %d, want 0", truncated)
+	}
+	if got[1].Content != want[1].Content {
+		t.Error("expected user prompt to be unchanged when within budget")
+	}
+}
+
+func TestGenerateOutputs_OverBudgetTruncatesAnswers(t *testing.T) {
+	answers := longAnswers(10, 1000)
+	base := estimateTokens(buildOutputsMessages("A task tracker", nil, "novice", "default"))
+	budget := base + 1000
+
+	client := openai.NewFakeClient(validOutputsJSON(t))
+	svc := NewService(nil)
+	svc.openaiClient = client
+	svc.maxPromptTokens = budget
+
+	if _, err := svc.GenerateOutputs(context.Background(), "A task tracker", answers, "novice", "default"); err != nil {
+		t.Fatalf("expected over-budget answers to be truncated, got error: %v", err)
+	}
+	if client.CallCount() != 1 {
+		t.Fatalf("expected 1 model call, got %d", client.CallCount())
+	}
+
+	sent := client.Calls()[0].Messages
+	if estimateTokens(sent) > budget {
+		t.Errorf("sent prompt estimated at %d tokens, budget is %d", estimateTokens(sent), budget)
+	}
+	if !strings.Contains(sent[1].Content, strings.TrimSpace(truncationNote)) {
+		t.Error("expected truncated answers to carry the truncation note")
+	}
+	if answers[0].Answer != strings.Repeat("detail ", 1000/7) {
+		t.Error("caller's answers must not be modified")
+	}
+}
+
+func TestGenerateOutputs_UnfittablePromptRejected(t *testing.T) {
+	client := openai.NewFakeClient(validOutputsJSON(t))
+	svc := NewService(nil)
+	svc.openaiClient = client
+	svc.maxPromptTokens = 100 // smaller than the system prompt alone
+
+	_, err := svc.GenerateOutputs(context.Background(), "A task tracker", longAnswers(5, 1000), "novice", "default")
+	if !errors.Is(err, ErrPromptTooLarge) {
+		t.Fatalf("expected ErrPromptTooLarge, got %v", err)
+	}
+	if client.CallCount() != 0 {
+		t.Errorf("expected no model call for an over-budget prompt, got %d", client.CallCount())
+	}
+}
@@ -0,0 +1,102 @@
+package generation
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// bundleSections titles the sections of a Markdown bundle, one per canonical
+// file group; core and conditional steering files share a section.
+var bundleSections = []struct {
+	title  string
+	groups []int
+}{
+	{"Kickoff Prompt", []int{fileGroupKickoff}},
+	{"Steering Files", []int{fileGroupCoreSteering, fileGroupSteering}},
+	{"Hooks", []int{fileGroupHook}},
+	{"Agent Guidelines", []int{fileGroupAgents}},
+	{"Other Files", []int{fileGroupOther}},
+}
+
+// BuildMarkdownBundle combines generated files into a single Markdown
+// document for users who prefer one file. Each file gets a section headed by
+// its path, grouped and ordered like SortGeneratedFiles; hooks are summarized
+// above their JSON definition. files is not modified.
+func BuildMarkdownBundle(files []GeneratedFile) string {
+	sorted := make([]GeneratedFile, len(files))
+	copy(sorted, files)
+	SortGeneratedFiles(sorted)
+
+	var b strings.Builder
+	b.WriteString("# Kiro Project Bundle\n\n")
+	b.WriteString("Each section below holds one generated file; the heading is the path it belongs at.\n")
+
+	for _, section := range bundleSections {
+		var sectionFiles []GeneratedFile
+		for _, f := range sorted {
+			for _, group := range section.groups {
+				if fileGroup(f) == group {
+					sectionFiles = append(sectionFiles, f)
+				}
+			}
+		}
+		if len(sectionFiles) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(&b, "\n## %s\n", section.title)
+		for _, f := range sectionFiles {
+			fmt.Fprintf(&b, "\n### `%s`\n\n", f.Path)
+			if f.Type == "hook" {
+				writeHookSummary(&b, f.Content)
+				continue
+			}
+			b.WriteString(strings.TrimRight(f.Content, "\n"))
+			b.WriteString("\n")
+		}
+	}
+
+	return b.String()
+}
+
+// writeHookSummary writes what a hook does followed by its definition. A hook
+// that does not parse is written as-is.
+func writeHookSummary(b *strings.Builder, content string) {
+	var hook HookFile
+	if err := json.Unmarshal([]byte(content), &hook); err == nil {
+		fmt.Fprintf(b, "- **Name:** %s\n", hook.Name)
+		if hook.Description != "" {
+			fmt.Fprintf(b, "- **Description:** %s\n", hook.Description)
+		}
+		trigger := hook.When.Type
+		if len(hook.When.Patterns) > 0 {
+			trigger += " on `" + strings.Join(hook.When.Patterns, "`, `") + "`"
+		}
+		fmt.Fprintf(b, "- **Trigger:** %s\n", trigger)
+		if hook.Then.Command != "" {
+			fmt.Fprintf(b, "- **Action:** %s `%s`\n", hook.Then.Type, hook.Then.Command)
+		} else {
+			fmt.Fprintf(b, "- **Action:** %s\n", hook.Then.Type)
+		}
+		b.WriteString("\n")
+	}
+
+	fence := codeFence(content)
+	fmt.Fprintf(b, "%sjson\n%s\n%s\n", fence, strings.TrimRight(content, "\n"), fence)
+}
+
+// codeFence returns a backtick fence longer than any backtick run in content,
+// so the content can't close the code block early.
+func codeFence(content string) string {
+	longest, run := 0, 0
+	for _, r := range content {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return strings.Repeat("`", max(3, longest+1))
+}
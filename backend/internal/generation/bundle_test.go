@@ -0,0 +1,84 @@
+package generation
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildMarkdownBundle_LabelsEveryFile(t *testing.T) {
+	hook := buildValidHook("fileEdited", "runCommand")
+	files := []GeneratedFile{
+		{Path: "AGENTS.md", Content: "# Agent Guidelines\n\nKeep changes small.", Type: "agents"},
+		{Path: ".kiro/hooks/format.kiro.hook", Content: hook, Type: "hook"},
+		{Path: ".kiro/steering/api.md", Content: "---\ninclusion: manual\n---\n\n# API\n\n```go\nfunc main() {}\n```", Type: "steering"},
+		{Path: ".kiro/steering/product.md", Content: "---\ninclusion: always\n---\n\n# Product", Type: "steering"},
+		{Path: "kickoff-prompt.md", Content: minimalValidKickoff(), Type: "kickoff"},
+	}
+	original := files[0]
+
+	bundle := BuildMarkdownBundle(files)
+
+	// Every file's content follows its labeled heading, within its group's section
+	sections := map[string]string{
+		"kickoff-prompt.md":            "## Kickoff Prompt",
+		".kiro/steering/product.md":    "## Steering Files",
+		".kiro/steering/api.md":        "## Steering Files",
+		".kiro/hooks/format.kiro.hook": "## Hooks",
+		"AGENTS.md":                    "## Agent Guidelines",
+	}
+	for _, f := range files {
+		heading := "### `" + f.Path + "`"
+		at := strings.Index(bundle, heading)
+		if at < 0 {
+			t.Errorf("bundle has no section for %s", f.Path)
+			continue
+		}
+		if !strings.Contains(bundle[at:], strings.TrimRight(f.Content, "\n")) {
+			t.Errorf("content of %s does not follow its heading", f.Path)
+		}
+		section := strings.LastIndex(bundle[:at], "\n## ")
+		if section < 0 || !strings.HasPrefix(bundle[section+1:], sections[f.Path]) {
+			t.Errorf("%s is not under %q", f.Path, sections[f.Path])
+		}
+	}
+
+	// Sections follow the canonical file order
+	last := -1
+	for _, title := range []string{"## Kickoff Prompt", "## Steering Files", "## Hooks", "## Agent Guidelines"} {
+		at := strings.Index(bundle, title)
+		if at <= last {
+			t.Errorf("section %q is out of order", title)
+		}
+		last = at
+	}
+	if strings.Index(bundle, "`.kiro/steering/product.md`") > strings.Index(bundle, "`.kiro/steering/api.md`") {
+		t.Error("core steering files should come before conditional ones")
+	}
+
+	// Hooks are summarized above their definition
+	for _, want := range []string{"- **Name:** Test Hook", "- **Trigger:** fileEdited on `**/*.go`", "- **Action:** runCommand `go fmt ./...`", "```json\n"} {
+		if !strings.Contains(bundle, want) {
+			t.Errorf("bundle is missing hook summary line %q", want)
+		}
+	}
+
+	if files[0] != original {
+		t.Error("BuildMarkdownBundle reordered the caller's files")
+	}
+}
+
+func TestCodeFence(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{`{"name": "x"}`, "```"},
+		{"run `go test`", "```"},
+		{"```go\ncode\n```", "````"},
+	}
+	for _, tt := range tests {
+		if got := codeFence(tt.content); got != tt.want {
+			t.Errorf("codeFence(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package generation
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Project complexity estimates reported by EstimateComplexity.
+const (
+	ComplexitySmall  = "small"
+	ComplexityMedium = "medium"
+	ComplexityLarge  = "large"
+)
+
+// Complexity heuristics: each distinct concern scores a point, as does every
+// complexityWordsPerPoint words of description.
+const (
+	complexityWordsPerPoint = 50
+	maxSmallPoints          = 1
+	maxMediumPoints         = 4
+)
+
+// complexityConcerns match the features that each add a distinct piece of
+// work to a project, such as sign-in or payments.
+var complexityConcerns = func() []*regexp.Regexp {
+	concerns := []string{
+		`log ?in|sign ?(in|up)|auth\w*|accounts?|passwords?|sso`,
+		`roles?|permissions?|admins?|moderat\w+`,
+		`payments?|billing|checkout|subscriptions?|stripe|invoices?`,
+		`real-?time|chat|messag\w+|websockets?|live updates?`,
+		`notifications?|emails?|sms|push`,
+		`search\w*|filter\w*`,
+		`uploads?|photos?|images?|videos?|files?|attachments?`,
+		`integrat\w+|third-party|webhooks?|imports?|exports?|sync\w*`,
+		`analytics|reports?|reporting|dashboards?|charts?|metrics`,
+		`mobile|ios|android|offline`,
+		`maps?|locations?|gps|geo\w*`,
+		`calendars?|schedul\w+|bookings?|reservations?|reminders?`,
+		`multi-?tenant|organi[sz]ations?|teams?|workspaces?`,
+		`ai|machine learning|ml|recommendations?|llm`,
+		`i18n|locali[sz]ation|translations?|multi-?language`,
+	}
+	patterns := make([]*regexp.Regexp, len(concerns))
+	for i, c := range concerns {
+		patterns[i] = regexp.MustCompile(`(?i)\b(` + c + `)\b`)
+	}
+	return patterns
+}()
+
+// EstimateComplexity gives a rough size for a project from its idea and any
+// answers so far, without a model call: how many distinct concerns (sign-in,
+// payments, real-time updates and so on) are mentioned, and how long the
+// description is. It is meant to set expectations, not to plan work.
+func EstimateComplexity(projectIdea string, answers []Answer) string {
+	text := projectIdea
+	for _, a := range answers {
+		text += "\n" + a.Answer
+	}
+
+	points := len(strings.Fields(text)) / complexityWordsPerPoint
+	for _, p := range complexityConcerns {
+		if p.MatchString(text) {
+			points++
+		}
+	}
+
+	switch {
+	case points <= maxSmallPoints:
+		return ComplexitySmall
+	case points <= maxMediumPoints:
+		return ComplexityMedium
+	default:
+		return ComplexityLarge
+	}
+}
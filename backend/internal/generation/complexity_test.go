@@ -0,0 +1,40 @@
+package generation
+
+import "testing"
+
+func TestEstimateComplexity_OneLineIdeaIsSmall(t *testing.T) {
+	if got := EstimateComplexity("A todo list for my groceries", nil); got != ComplexitySmall {
+		t.Errorf("EstimateComplexity() = %q, want %q", got, ComplexitySmall)
+	}
+}
+
+func TestEstimateComplexity_MultiFeatureIdeaIsLarge(t *testing.T) {
+	idea := `A marketplace where local bakers sell cakes to customers in their area.
+
+Customers sign up with email or Google, search bakers by location on a map, and
+book a pickup slot from the baker's calendar. Payments go through Stripe at
+checkout, and bakers get a monthly payout report on their dashboard.
+
+Bakers and customers chat in real time about custom orders, and both get push
+notifications when an order changes. Admins moderate listings and reviews.
+Bakers upload photos of their cakes, and the whole thing should work on mobile.`
+
+	if got := EstimateComplexity(idea, nil); got != ComplexityLarge {
+		t.Errorf("EstimateComplexity() = %q, want %q", got, ComplexityLarge)
+	}
+}
+
+func TestEstimateComplexity_AnswersAddConcerns(t *testing.T) {
+	idea := "A recipe sharing site where users log in"
+	if got := EstimateComplexity(idea, nil); got != ComplexitySmall {
+		t.Fatalf("EstimateComplexity() without answers = %q, want %q", got, ComplexitySmall)
+	}
+
+	answers := []Answer{
+		{QuestionID: 1, Answer: "Users upload photos and search recipes by ingredient"},
+		{QuestionID: 2, Answer: "Admins review new recipes before they are published"},
+	}
+	if got := EstimateComplexity(idea, answers); got != ComplexityMedium {
+		t.Errorf("EstimateComplexity() with answers = %q, want %q", got, ComplexityMedium)
+	}
+}
@@ -0,0 +1,58 @@
+package generation
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"better-kiro-prompts/internal/logger"
+	"better-kiro-prompts/internal/storage"
+)
+
+// hashGenerationContent hashes the inputs a generation is made from. Model
+// output varies between runs, so identical inputs are what make two gallery
+// entries duplicates; visibility is left out since only public generations
+// are de-duplicated.
+func hashGenerationContent(projectIdea string, answers []Answer, experienceLevel, hookPreset string) string {
+	payload, _ := json.Marshal(struct {
+		ProjectIdea     string   `json:"projectIdea"`
+		Answers         []Answer `json:"answers"`
+		ExperienceLevel string   `json:"experienceLevel"`
+		HookPreset      string   `json:"hookPreset"`
+	}{
+		ProjectIdea:     strings.TrimSpace(projectIdea),
+		Answers:         answers,
+		ExperienceLevel: experienceLevel,
+		HookPreset:      hookPreset,
+	})
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+// findDuplicate returns the public generation with the same content hash
+// stored within the dedupe window, or nil when there is none, de-duplication
+// is disabled or the generation is unlisted. Lookup errors are logged and
+// treated as no duplicate so the generation is still stored.
+func (s *Service) findDuplicate(ctx context.Context, contentHash, visibility string) *storage.Generation {
+	if s.dedupeWindow <= 0 || (visibility != "" && visibility != storage.VisibilityPublic) {
+		return nil
+	}
+
+	existing, err := s.repository.FindGenerationByContentHash(ctx, contentHash, time.Now().Add(-s.dedupeWindow))
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		s.log.Warn("storage_dedupe_lookup_failed",
+			slog.String("request_id", logger.GetRequestID(ctx)),
+			slog.String("error", err.Error()),
+		)
+		return nil
+	}
+	return existing
+}
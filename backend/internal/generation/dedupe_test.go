@@ -0,0 +1,91 @@
+package generation
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"better-kiro-prompts/internal/openai"
+	"better-kiro-prompts/internal/storage"
+)
+
+func TestGenerateAndStoreOutputs_DeduplicatesIdenticalPublicGenerations(t *testing.T) {
+	// The second model response differs, as a real regeneration would
+	regenerated := strings.Replace(validOutputsJSON(t), "# Agent Guidelines", "# Agent Guidelines (v2)", 1)
+	client := openai.NewFakeClient(validOutputsJSON(t), regenerated)
+	repo := &stubRepository{}
+	svc := NewService(nil)
+	svc.openaiClient = client
+	svc.SetRepository(repo)
+	svc.SetDedupeWindow(time.Hour)
+	ctx := context.Background()
+	answers := []Answer{{QuestionID: 1, Answer: "Small teams"}}
+
+	first, err := svc.GenerateAndStoreOutputs(ctx, "A todo app", answers, "novice", "default", "")
+	if err != nil {
+		t.Fatalf("first call failed: %v", err)
+	}
+	second, err := svc.GenerateAndStoreOutputs(ctx, "  A todo app ", answers, "novice", "default", storage.VisibilityPublic)
+	if err != nil {
+		t.Fatalf("second call failed: %v", err)
+	}
+
+	if repo.created != 1 {
+		t.Errorf("repository stored %d generations, want 1", repo.created)
+	}
+	if second.GenerationID != first.GenerationID {
+		t.Errorf("GenerationID = %q, want the stored %q", second.GenerationID, first.GenerationID)
+	}
+	if first.Duplicate || !second.Duplicate {
+		t.Errorf("Duplicate = %v, %v, want false, true", first.Duplicate, second.Duplicate)
+	}
+	if reflect.DeepEqual(second.Files, first.Files) {
+		t.Error("duplicate response should carry the files just generated, not the stored generation's")
+	}
+	if got := client.CallCount(); got != 2 {
+		t.Errorf("model called %d times, want 2", got)
+	}
+}
+
+func TestGenerateAndStoreOutputs_StoresDistinctAndUnlistedGenerations(t *testing.T) {
+	tests := []struct {
+		name       string
+		window     time.Duration
+		idea       string
+		answer     string
+		visibility string
+	}{
+		{"different answers", time.Hour, "A todo app", "Large teams", ""},
+		{"different idea", time.Hour, "A chat app", "Small teams", ""},
+		{"unlisted", time.Hour, "A todo app", "Small teams", storage.VisibilityUnlisted},
+		{"disabled", 0, "A todo app", "Small teams", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &stubRepository{}
+			svc := NewService(nil)
+			svc.openaiClient = openai.NewFakeClient(validOutputsJSON(t), validOutputsJSON(t))
+			svc.SetRepository(repo)
+			svc.SetDedupeWindow(tt.window)
+			ctx := context.Background()
+
+			if _, err := svc.GenerateAndStoreOutputs(ctx, "A todo app", []Answer{{QuestionID: 1, Answer: "Small teams"}}, "novice", "default", ""); err != nil {
+				t.Fatalf("first call failed: %v", err)
+			}
+			second, err := svc.GenerateAndStoreOutputs(ctx, tt.idea, []Answer{{QuestionID: 1, Answer: tt.answer}}, "novice", "default", tt.visibility)
+			if err != nil {
+				t.Fatalf("second call failed: %v", err)
+			}
+
+			if repo.created != 2 {
+				t.Errorf("repository stored %d generations, want 2", repo.created)
+			}
+			if second.Duplicate {
+				t.Error("second generation marked as a duplicate")
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package generation
+
+import "better-kiro-prompts/internal/prompts"
+
+// SetDefaults sets the experience level and hook preset used when a request
+// omits them or names an unknown one. Empty values keep novice and default.
+func (s *Service) SetDefaults(experienceLevel, hookPreset string) {
+	s.defaultLevel = experienceLevel
+	s.defaultHookPreset = hookPreset
+}
+
+// ResolveExperienceLevel returns the experience level generation uses: the
+// configured default when level is empty or unknown, with auto inferred from
+// the project idea. The result is never auto.
+func (s *Service) ResolveExperienceLevel(level, projectIdea string) string {
+	if level != prompts.ExperienceAuto && !prompts.IsValidExperienceLevel(level) {
+		level = s.defaultLevel
+	}
+	level = prompts.ResolveExperienceLevel(level, projectIdea)
+	if !prompts.IsValidExperienceLevel(level) {
+		return prompts.ExperienceNovice
+	}
+	return level
+}
+
+// ResolveHookPreset returns preset, or the configured default when it is
+// empty or unknown.
+func (s *Service) ResolveHookPreset(preset string) string {
+	if prompts.IsValidHookPreset(preset) {
+		return preset
+	}
+	if prompts.IsValidHookPreset(s.defaultHookPreset) {
+		return s.defaultHookPreset
+	}
+	return prompts.HookPresetDefault
+}
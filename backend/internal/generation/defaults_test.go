@@ -0,0 +1,67 @@
+package generation
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"better-kiro-prompts/internal/config"
+	"better-kiro-prompts/internal/prompts"
+)
+
+func TestResolveExperienceLevel_UsesConfiguredDefault(t *testing.T) {
+	svc := NewService(nil)
+	svc.SetDefaults(prompts.ExperienceBeginner, prompts.HookPresetStrict)
+
+	tests := []struct {
+		level string
+		want  string
+	}{
+		{"", prompts.ExperienceBeginner},
+		{"intermediate", prompts.ExperienceBeginner},
+		{prompts.ExperienceExpert, prompts.ExperienceExpert},
+	}
+	for _, tt := range tests {
+		if got := svc.ResolveExperienceLevel(tt.level, "A todo app"); got != tt.want {
+			t.Errorf("ResolveExperienceLevel(%q) = %q, want %q", tt.level, got, tt.want)
+		}
+	}
+
+	if got := svc.ResolveHookPreset(""); got != prompts.HookPresetStrict {
+		t.Errorf("ResolveHookPreset(\"\") = %q, want %q", got, prompts.HookPresetStrict)
+	}
+	if got := svc.ResolveHookPreset(prompts.HookPresetLight); got != prompts.HookPresetLight {
+		t.Errorf("ResolveHookPreset(light) = %q, want light", got)
+	}
+}
+
+func TestResolveExperienceLevel_UnsetDefaultsToNovice(t *testing.T) {
+	svc := NewService(nil)
+
+	if got := svc.ResolveExperienceLevel("", "A todo app"); got != prompts.ExperienceNovice {
+		t.Errorf("ResolveExperienceLevel(\"\") = %q, want novice", got)
+	}
+	if got := svc.ResolveHookPreset(""); got != prompts.HookPresetDefault {
+		t.Errorf("ResolveHookPreset(\"\") = %q, want default", got)
+	}
+}
+
+func TestPreviewOutputsPrompt_OmittedLevelUsesConfiguredDefault(t *testing.T) {
+	cfg := config.DefaultConfig().Generation
+	cfg.DefaultLevel = prompts.ExperienceBeginner
+	cfg.DefaultHookPreset = prompts.HookPresetLight
+	svc := NewServiceWithConfig(nil, nil, nil, nil, cfg)
+
+	answers := []Answer{{QuestionID: 1, Answer: "Families planning weekly meals"}}
+	preview, err := svc.PreviewOutputsPrompt(context.Background(), "A recipe sharing app", answers, "", "")
+	if err != nil {
+		t.Fatalf("PreviewOutputsPrompt() error = %v", err)
+	}
+
+	if !strings.Contains(preview.SystemPrompt, "Experience Level: beginner") {
+		t.Error("system prompt should use the configured default experience level, not novice")
+	}
+	if !strings.Contains(preview.SystemPrompt, "Hook Preset: light") {
+		t.Error("system prompt should use the configured default hook preset")
+	}
+}
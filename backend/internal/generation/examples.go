@@ -0,0 +1,165 @@
+package generation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"better-kiro-prompts/internal/logger"
+	"better-kiro-prompts/internal/openai"
+	"better-kiro-prompts/internal/prompts"
+)
+
+// Example answer limits
+const (
+	exampleCount      = 3
+	maxQuestionLength = 500
+)
+
+var (
+	ErrEmptyQuestion   = errors.New("question is required")
+	ErrQuestionTooLong = errors.New("question exceeds maximum length")
+)
+
+// ExamplesResponse is the expected JSON structure from the AI for example answers.
+type ExamplesResponse struct {
+	Examples []string `json:"examples"`
+}
+
+// ValidateQuestion validates the question text for example regeneration.
+func ValidateQuestion(question string) error {
+	trimmed := strings.TrimSpace(question)
+	if trimmed == "" {
+		return ErrEmptyQuestion
+	}
+	if len(trimmed) > maxQuestionLength {
+		return ErrQuestionTooLong
+	}
+	return nil
+}
+
+// GenerateExamples generates fresh example answers for a single question.
+// The model must return exactly three distinct, non-empty examples; anything
+// less is rejected with ErrInvalidResponse rather than padded.
+func (s *Service) GenerateExamples(ctx context.Context, question string, projectIdea string, experienceLevel string) ([]string, error) {
+	experienceLevel = s.ResolveExperienceLevel(experienceLevel, projectIdea)
+	requestID := logger.GetRequestID(ctx)
+	start := time.Now()
+
+	s.log.Info("generate_examples_start",
+		slog.String("request_id", requestID),
+		slog.String("experience_level", experienceLevel),
+		slog.Int("question_length", len(question)),
+	)
+
+	if err := ValidateQuestion(question); err != nil {
+		s.log.Warn("generate_examples_validation_failed",
+			slog.String("request_id", requestID),
+			slog.String("error", err.Error()),
+			slog.String("validation_type", "question"),
+		)
+		return nil, err
+	}
+	if err := s.ValidateProjectIdea(projectIdea); err != nil {
+		s.log.Warn("generate_examples_validation_failed",
+			slog.String("request_id", requestID),
+			slog.String("error", err.Error()),
+			slog.String("validation_type", "project_idea"),
+		)
+		return nil, err
+	}
+
+	if s.openaiClient == nil {
+		s.log.Error("openai_client_unavailable", slog.String("request_id", requestID))
+		return nil, ErrAIUnavailable
+	}
+
+	// Acquire queue slot if queue is configured
+	if s.requestQueue != nil {
+		if err := s.requestQueue.Acquire(ctx); err != nil {
+			s.log.Error("queue_acquire_failed",
+				slog.String("request_id", requestID),
+				slog.String("error", err.Error()),
+			)
+			return nil, fmt.Errorf("failed to acquire queue slot: %w", err)
+		}
+		defer s.requestQueue.Release()
+	}
+
+	callCtx, cancel := withTimeout(ctx, s.questionsTimeout)
+	response, err := s.openaiClient.ChatCompletion(callCtx, s.withHouseRules(buildExamplesMessages(question, projectIdea, experienceLevel)))
+	cancel()
+	if err != nil {
+		s.log.Error("generate_examples_openai_failed",
+			slog.String("request_id", requestID),
+			slog.String("error", err.Error()),
+			slog.Duration("duration", time.Since(start)),
+		)
+		return nil, fmt.Errorf("failed to generate examples: %w", err)
+	}
+
+	examples, err := parseExamplesResponse(response)
+	if err != nil {
+		s.log.Error("generate_examples_parse_failed",
+			slog.String("request_id", requestID),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	s.log.Info("generate_examples_complete",
+		slog.String("request_id", requestID),
+		slog.Duration("duration", time.Since(start)),
+	)
+
+	return examples, nil
+}
+
+// buildExamplesMessages composes the chat messages for example answer generation.
+// An auto experience level is inferred; unknown levels fall back to novice.
+func buildExamplesMessages(question string, projectIdea string, experienceLevel string) []openai.Message {
+	experienceLevel = prompts.ResolveExperienceLevel(experienceLevel, projectIdea)
+	if !prompts.IsValidExperienceLevel(experienceLevel) {
+		experienceLevel = prompts.ExperienceNovice
+	}
+
+	return []openai.Message{
+		{Role: "system", Content: prompts.GetExamplesSystemPrompt(experienceLevel)},
+		{Role: "user", Content: prompts.GetExamplesUserPrompt(strings.TrimSpace(question), strings.TrimSpace(projectIdea), experienceLevel)},
+	}
+}
+
+// parseExamplesResponse extracts the example answers, dropping blank and duplicate
+// entries and keeping the first three. Fewer than three usable examples is an error.
+func parseExamplesResponse(response string) ([]string, error) {
+	jsonStr := extractJSON(response)
+
+	var er ExamplesResponse
+	if err := json.Unmarshal([]byte(jsonStr), &er); err != nil {
+		return nil, fmt.Errorf("%w: failed to parse examples JSON: %v", ErrInvalidResponse, err)
+	}
+
+	examples := make([]string, 0, exampleCount)
+	seen := make(map[string]bool, len(er.Examples))
+	for _, e := range er.Examples {
+		e = strings.TrimSpace(e)
+		if e == "" || seen[strings.ToLower(e)] {
+			continue
+		}
+		seen[strings.ToLower(e)] = true
+		examples = append(examples, e)
+		if len(examples) == exampleCount {
+			break
+		}
+	}
+
+	if len(examples) < exampleCount {
+		return nil, fmt.Errorf("%w: expected %d distinct examples, got %d", ErrInvalidResponse, exampleCount, len(examples))
+	}
+
+	return examples, nil
+}
@@ -0,0 +1,113 @@
+package generation
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"better-kiro-prompts/internal/openai"
+)
+
+func TestGenerateExamples_ReturnsThreeExamples(t *testing.T) {
+	client := openai.NewFakeClient(`{"examples": ["Email and password", "Sign in with Google", "No accounts needed"]}`)
+	svc := NewService(nil)
+	svc.openaiClient = client
+
+	question := "How will people log in?"
+	examples, err := svc.GenerateExamples(context.Background(), question, "A book club organizer", "beginner")
+	if err != nil {
+		t.Fatalf("GenerateExamples() error = %v", err)
+	}
+	if len(examples) != 3 {
+		t.Fatalf("got %d examples, want 3", len(examples))
+	}
+
+	if got := client.CallCount(); got != 1 {
+		t.Fatalf("model called %d times, want 1", got)
+	}
+	userPrompt := client.Calls()[0].Messages[1].Content
+	if !strings.Contains(userPrompt, question) {
+		t.Error("user prompt should contain the question")
+	}
+}
+
+func TestGenerateExamples_WithoutClientIsUnavailable(t *testing.T) {
+	svc := NewService(nil)
+	_, err := svc.GenerateExamples(context.Background(), "Who uses it?", "A book club organizer", "novice")
+	if !errors.Is(err, ErrAIUnavailable) {
+		t.Errorf("expected ErrAIUnavailable, got %v", err)
+	}
+}
+
+// TestParseExamplesResponse covers the policy for short or noisy model output:
+// extras are trimmed to three, and fewer than three usable examples are rejected, not padded.
+func TestParseExamplesResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name:     "exactly three",
+			response: `{"examples": ["A", "B", "C"]}`,
+			want:     []string{"A", "B", "C"},
+		},
+		{
+			name:     "extra examples are dropped",
+			response: `{"examples": ["A", "B", "C", "D"]}`,
+			want:     []string{"A", "B", "C"},
+		},
+		{
+			name:     "blank and duplicate entries are skipped",
+			response: "```json\n{\"examples\": [\" A \", \"\", \"a\", \"B\", \"C\"]}\n```",
+			want:     []string{"A", "B", "C"},
+		},
+		{
+			name:     "fewer than three is flagged",
+			response: `{"examples": ["A", "B"]}`,
+			wantErr:  true,
+		},
+		{
+			name:     "duplicates leaving fewer than three is flagged",
+			response: `{"examples": ["A", "A", "B"]}`,
+			wantErr:  true,
+		},
+		{
+			name:     "invalid JSON",
+			response: `examples: A, B, C`,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseExamplesResponse(tt.response)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidResponse) {
+					t.Errorf("expected ErrInvalidResponse, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateQuestion(t *testing.T) {
+	if err := ValidateQuestion("   "); !errors.Is(err, ErrEmptyQuestion) {
+		t.Errorf("blank question: got %v, want ErrEmptyQuestion", err)
+	}
+	if err := ValidateQuestion(strings.Repeat("q", maxQuestionLength+1)); !errors.Is(err, ErrQuestionTooLong) {
+		t.Errorf("long question: got %v, want ErrQuestionTooLong", err)
+	}
+	if err := ValidateQuestion("Who uses it?"); err != nil {
+		t.Errorf("valid question: got %v", err)
+	}
+}
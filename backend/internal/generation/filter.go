@@ -0,0 +1,69 @@
+package generation
+
+import (
+	"context"
+	"log/slog"
+
+	"better-kiro-prompts/internal/logger"
+	"better-kiro-prompts/internal/sanitize"
+)
+
+// Secret policies for project ideas stored in the gallery.
+const (
+	SecretPolicyReject = "reject" // Skip storing generations whose idea contains a secret
+	SecretPolicyMask   = "mask"   // Store the idea with secrets masked
+	SecretPolicyOff    = "off"    // Store the idea as given
+)
+
+// filterStored applies the secret policy and profanity wordlist to
+// everything stored with a generation: the project idea, every answer and
+// every generated file. It returns filtered copies, leaving the caller's
+// answers and files untouched, or false when any of them contains a secret
+// and the policy rejects it; the user still gets their files either way.
+func (s *Service) filterStored(ctx context.Context, projectIdea string, answers []Answer, files []GeneratedFile) (string, []Answer, []GeneratedFile, bool) {
+	masked := false
+	filter := func(text string) (string, bool) {
+		switch s.secretPolicy {
+		case SecretPolicyOff:
+		case SecretPolicyMask:
+			if m := sanitize.MaskSecrets(text); m != text {
+				masked = true
+				text = m
+			}
+		default:
+			if sanitize.ContainsSecret(text) {
+				return "", false
+			}
+		}
+		return s.profanityMasker.Mask(text), true
+	}
+
+	storedIdea, ok := filter(projectIdea)
+	if !ok {
+		return "", nil, nil, false
+	}
+	var storedAnswers []Answer
+	if answers != nil {
+		storedAnswers = make([]Answer, len(answers))
+		for i, a := range answers {
+			if a.Answer, ok = filter(a.Answer); !ok {
+				return "", nil, nil, false
+			}
+			storedAnswers[i] = a
+		}
+	}
+	storedFiles := make([]GeneratedFile, len(files))
+	for i, f := range files {
+		if f.Content, ok = filter(f.Content); !ok {
+			return "", nil, nil, false
+		}
+		storedFiles[i] = f
+	}
+
+	if masked {
+		s.log.Info("storage_secret_masked",
+			slog.String("request_id", logger.GetRequestID(ctx)),
+		)
+	}
+	return storedIdea, storedAnswers, storedFiles, true
+}
@@ -0,0 +1,36 @@
+package generation
+
+import (
+	"context"
+	"log/slog"
+
+	"better-kiro-prompts/internal/logger"
+	"better-kiro-prompts/internal/pagination"
+	"better-kiro-prompts/internal/storage"
+)
+
+// RecordCreator remembers that the visitor with ipHash created a generation,
+// so it shows up in their History. It is best effort: failures are logged and
+// the generation itself is unaffected.
+func (s *Service) RecordCreator(ctx context.Context, generationID, ipHash string) {
+	if s.repository == nil || generationID == "" || ipHash == "" {
+		return
+	}
+	if err := s.repository.RecordCreator(ctx, generationID, ipHash); err != nil {
+		s.log.Warn("history_record_failed",
+			slog.String("request_id", logger.GetRequestID(ctx)),
+			slog.String("generation_id", generationID),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// History returns up to limit generations the visitor with ipHash created,
+// newest first, including unlisted ones. limit is bounded like any other
+// page size. The IP hash itself is never part of the result.
+func (s *Service) History(ctx context.Context, ipHash string, limit int) ([]storage.Generation, error) {
+	if s.repository == nil || ipHash == "" {
+		return []storage.Generation{}, nil
+	}
+	return s.repository.ListGenerationsByCreator(ctx, ipHash, pagination.NormalizePageSize(limit))
+}
@@ -0,0 +1,161 @@
+package generation
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultIdempotencyTTL is how long a cached result is replayed for a key.
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+// ErrIdempotencyKeyReused is returned when a key is replayed with a different request.
+var ErrIdempotencyKeyReused = errors.New("idempotency key was already used with a different request")
+
+// idempotencyEntry holds the state for a single idempotency key.
+// done is closed once the owning request has finished (successfully or not).
+type idempotencyEntry struct {
+	requestHash string
+	result      *GenerationResult
+	expiresAt   time.Time
+	done        chan struct{}
+}
+
+// IdempotencyStore caches generation results by client-supplied idempotency key
+// so that retried requests return the original result instead of calling the model again.
+type IdempotencyStore struct {
+	mu      sync.Mutex
+	entries map[string]*idempotencyEntry
+	ttl     time.Duration
+	now     func() time.Time // for testing
+}
+
+// NewIdempotencyStore creates a store that keeps results for the given TTL.
+func NewIdempotencyStore(ttl time.Duration) *IdempotencyStore {
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyTTL
+	}
+	return &IdempotencyStore{
+		entries: make(map[string]*idempotencyEntry),
+		ttl:     ttl,
+		now:     time.Now,
+	}
+}
+
+// acquire looks up a key. It returns the cached result if one exists, or an entry
+// the caller now owns and must finish with complete. If another request with the
+// same key is in flight, acquire waits for it.
+func (st *IdempotencyStore) acquire(ctx context.Context, key, requestHash string) (*GenerationResult, *idempotencyEntry, error) {
+	for {
+		st.mu.Lock()
+		st.pruneLocked()
+
+		entry, exists := st.entries[key]
+		if !exists {
+			entry = &idempotencyEntry{
+				requestHash: requestHash,
+				done:        make(chan struct{}),
+			}
+			st.entries[key] = entry
+			st.mu.Unlock()
+			return nil, entry, nil
+		}
+
+		if entry.requestHash != requestHash {
+			st.mu.Unlock()
+			return nil, nil, ErrIdempotencyKeyReused
+		}
+
+		if entry.result != nil {
+			result := entry.result
+			st.mu.Unlock()
+			return result, nil, nil
+		}
+
+		// Another request with this key is still running - wait for it, then re-check
+		done := entry.done
+		st.mu.Unlock()
+
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+	}
+}
+
+// lookup returns the finished result for key without claiming it, or nil when
+// there is none yet. A key used with a different request returns
+// ErrIdempotencyKeyReused.
+func (st *IdempotencyStore) lookup(key, requestHash string) (*GenerationResult, error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.pruneLocked()
+
+	entry, exists := st.entries[key]
+	if !exists {
+		return nil, nil
+	}
+	if entry.requestHash != requestHash {
+		return nil, ErrIdempotencyKeyReused
+	}
+	return entry.result, nil
+}
+
+// complete records the outcome for an entry returned by acquire.
+// A nil result releases the key so a later retry can generate again.
+func (st *IdempotencyStore) complete(key string, entry *idempotencyEntry, result *GenerationResult) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if result == nil {
+		if st.entries[key] == entry {
+			delete(st.entries, key)
+		}
+	} else {
+		entry.result = result
+		entry.expiresAt = st.now().Add(st.ttl)
+	}
+	close(entry.done)
+}
+
+// pruneLocked removes expired entries. Caller must hold st.mu.
+func (st *IdempotencyStore) pruneLocked() {
+	now := st.now()
+	for key, entry := range st.entries {
+		if entry.result != nil && now.After(entry.expiresAt) {
+			delete(st.entries, key)
+		}
+	}
+}
+
+// scopedIdempotencyKey namespaces a client-supplied key to the client that sent
+// it, so one client can't replay another's result by guessing its key.
+func scopedIdempotencyKey(clientID, key string) string {
+	return clientID + "\x00" + key
+}
+
+// hashOutputsRequest returns a stable hash of the inputs to an outputs generation,
+// used to make sure an idempotency key is only replayed for the same request.
+func hashOutputsRequest(projectIdea string, answers []Answer, experienceLevel, hookPreset, visibility string) string {
+	payload, _ := json.Marshal(struct {
+		ProjectIdea     string   `json:"projectIdea"`
+		Answers         []Answer `json:"answers"`
+		ExperienceLevel string   `json:"experienceLevel"`
+		HookPreset      string   `json:"hookPreset"`
+		Visibility      string   `json:"visibility"`
+	}{
+		ProjectIdea:     strings.TrimSpace(projectIdea),
+		Answers:         answers,
+		ExperienceLevel: experienceLevel,
+		HookPreset:      hookPreset,
+		Visibility:      visibility,
+	})
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
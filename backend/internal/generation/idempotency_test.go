@@ -0,0 +1,250 @@
+package generation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"better-kiro-prompts/internal/openai"
+	"better-kiro-prompts/internal/storage"
+)
+
+// stubRepository records created generations; other Repository methods are unused.
+type stubRepository struct {
+	storage.Repository
+	mu          sync.Mutex
+	created     int
+	generations map[string]storage.Generation
+	creators    map[string][]string // ipHash -> generation IDs in creation order
+}
+
+func (r *stubRepository) CreateGeneration(_ context.Context, gen *storage.Generation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.created++
+	gen.ID = fmt.Sprintf("gen-%d", r.created)
+	gen.CreatedAt = time.Now()
+	if r.generations == nil {
+		r.generations = make(map[string]storage.Generation)
+	}
+	r.generations[gen.ID] = *gen
+	return nil
+}
+
+func (r *stubRepository) GetGeneration(_ context.Context, id string) (*storage.Generation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	gen, ok := r.generations[id]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	return &gen, nil
+}
+
+func (r *stubRepository) FindGenerationByContentHash(_ context.Context, hash string, since time.Time) (*storage.Generation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, gen := range r.generations {
+		if gen.ContentHash == hash && gen.Visibility != storage.VisibilityUnlisted && !gen.CreatedAt.Before(since) {
+			return &gen, nil
+		}
+	}
+	return nil, storage.ErrNotFound
+}
+
+func (r *stubRepository) RecordCreator(_ context.Context, generationID string, ipHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.creators == nil {
+		r.creators = make(map[string][]string)
+	}
+	r.creators[ipHash] = append(r.creators[ipHash], generationID)
+	return nil
+}
+
+func (r *stubRepository) ListGenerationsByCreator(_ context.Context, ipHash string, limit int) ([]storage.Generation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ids := r.creators[ipHash]
+	generations := []storage.Generation{}
+	for i := len(ids) - 1; i >= 0 && len(generations) < limit; i-- {
+		generations = append(generations, r.generations[ids[i]])
+	}
+	return generations, nil
+}
+
+func (r *stubRepository) GetCategoryByKeywords(_ context.Context, _ string) (int, error) {
+	return 5, nil
+}
+
+// validOutputsJSON returns a model response that passes parsing and validation.
+func validOutputsJSON(t *testing.T) string {
+	t.Helper()
+	resp := OutputsResponse{Files: []GeneratedFile{
+		{Path: "kickoff-prompt.md", Content: minimalValidKickoff(), Type: "kickoff"},
+		{Path: ".kiro/steering/product.md", Content: "---\ninclusion: always\n---\n\n# Product", Type: "steering"},
+		{Path: ".kiro/steering/tech.md", Content: "---\ninclusion: always\n---\n\n# Tech", Type: "steering"},
+		{Path: ".kiro/steering/structure.md", Content: "---\ninclusion: always\n---\n\n# Structure", Type: "steering"},
+		{Path: ".kiro/hooks/format-on-stop.kiro.hook", Content: buildValidHook("agentStop", "runCommand"), Type: "hook"},
+		{Path: "AGENTS.md", Content: "# Agent Guidelines", Type: "agents"},
+	}}
+	data, err := json.Marshal(resp)
+	if err != nil {
+		t.Fatalf("failed to marshal outputs: %v", err)
+	}
+	return string(data)
+}
+
+func newIdempotentTestService(client *openai.FakeClient) (*Service, *stubRepository) {
+	repo := &stubRepository{}
+	svc := NewService(nil)
+	svc.openaiClient = client
+	svc.SetRepository(repo)
+	svc.SetIdempotencyStore(NewIdempotencyStore(time.Hour))
+	return svc, repo
+}
+
+func TestGenerateAndStoreOutputsIdempotent_ReplaysResult(t *testing.T) {
+	client := openai.NewFakeClient(validOutputsJSON(t))
+	svc, repo := newIdempotentTestService(client)
+	ctx := context.Background()
+	answers := []Answer{{QuestionID: 1, Answer: "Small teams"}}
+
+	first, err := svc.GenerateAndStoreOutputsIdempotent(ctx, "client-a", "key-1", "A todo app", answers, "novice", "default", "")
+	if err != nil {
+		t.Fatalf("first call failed: %v", err)
+	}
+	second, err := svc.GenerateAndStoreOutputsIdempotent(ctx, "client-a", "key-1", "A todo app", answers, "novice", "default", "")
+	if err != nil {
+		t.Fatalf("second call failed: %v", err)
+	}
+
+	if first.GenerationID == "" {
+		t.Fatal("expected a generation ID to be stored")
+	}
+	if second.GenerationID != first.GenerationID {
+		t.Errorf("GenerationID = %q, want %q", second.GenerationID, first.GenerationID)
+	}
+	if got := client.CallCount(); got != 1 {
+		t.Errorf("model called %d times, want 1", got)
+	}
+	if repo.created != 1 {
+		t.Errorf("repository stored %d generations, want 1", repo.created)
+	}
+}
+
+func TestGenerateAndStoreOutputsIdempotent_RejectsMismatchedRequest(t *testing.T) {
+	client := openai.NewFakeClient(validOutputsJSON(t))
+	svc, _ := newIdempotentTestService(client)
+	ctx := context.Background()
+
+	if _, err := svc.GenerateAndStoreOutputsIdempotent(ctx, "client-a", "key-1", "A todo app", nil, "novice", "default", ""); err != nil {
+		t.Fatalf("first call failed: %v", err)
+	}
+	_, err := svc.GenerateAndStoreOutputsIdempotent(ctx, "client-a", "key-1", "A chat app", nil, "novice", "default", "")
+	if !errors.Is(err, ErrIdempotencyKeyReused) {
+		t.Errorf("expected ErrIdempotencyKeyReused, got %v", err)
+	}
+	if got := client.CallCount(); got != 1 {
+		t.Errorf("model called %d times, want 1", got)
+	}
+}
+
+func TestGenerateAndStoreOutputsIdempotent_FailureReleasesKey(t *testing.T) {
+	client := openai.NewFakeClient("not json", "not json", validOutputsJSON(t))
+	svc, _ := newIdempotentTestService(client)
+	ctx := context.Background()
+
+	if _, err := svc.GenerateAndStoreOutputsIdempotent(ctx, "client-a", "key-1", "A todo app", nil, "novice", "default", ""); err == nil {
+		t.Fatal("expected first call to fail")
+	}
+	result, err := svc.GenerateAndStoreOutputsIdempotent(ctx, "client-a", "key-1", "A todo app", nil, "novice", "default", "")
+	if err != nil {
+		t.Fatalf("retry after failure should generate again: %v", err)
+	}
+	if result.GenerationID == "" {
+		t.Error("expected a generation ID after retry")
+	}
+}
+
+func TestIdempotencyStore_ExpiresEntries(t *testing.T) {
+	store := NewIdempotencyStore(time.Minute)
+	now := time.Now()
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	_, entry, err := store.acquire(ctx, "key", "hash")
+	if err != nil {
+		t.Fatalf("acquire failed: %v", err)
+	}
+	store.complete("key", entry, &GenerationResult{GenerationID: "gen-1"})
+
+	cached, _, _ := store.acquire(ctx, "key", "hash")
+	if cached == nil || cached.GenerationID != "gen-1" {
+		t.Fatalf("expected cached result before expiry, got %+v", cached)
+	}
+
+	now = now.Add(2 * time.Minute)
+	cached, entry, err = store.acquire(ctx, "key", "other-hash")
+	if err != nil {
+		t.Fatalf("expired key should be reusable: %v", err)
+	}
+	if cached != nil || entry == nil {
+		t.Error("expected a fresh entry after expiry")
+	}
+}
+
+func TestGenerateAndStoreOutputsIdempotent_ScopedToClient(t *testing.T) {
+	client := openai.NewFakeClient(validOutputsJSON(t), validOutputsJSON(t))
+	svc, repo := newIdempotentTestService(client)
+	ctx := context.Background()
+
+	first, err := svc.GenerateAndStoreOutputsIdempotent(ctx, "client-a", "key-1", "A todo app", nil, "novice", "default", "")
+	if err != nil {
+		t.Fatalf("first call failed: %v", err)
+	}
+
+	// Another client guessing the key must not see the first client's result
+	if replayed, err := svc.CachedOutputs(ctx, "client-b", "key-1", "A todo app", nil, "novice", "default", ""); err != nil || replayed != nil {
+		t.Fatalf("CachedOutputs() for another client = %+v, %v; want nil, nil", replayed, err)
+	}
+	second, err := svc.GenerateAndStoreOutputsIdempotent(ctx, "client-b", "key-1", "A todo app", nil, "novice", "default", "")
+	if err != nil {
+		t.Fatalf("second client's call failed: %v", err)
+	}
+	if second == first {
+		t.Error("expected the second client to get its own result")
+	}
+	if got := client.CallCount(); got != 2 || repo.created != 2 {
+		t.Errorf("model called %d times and stored %d generations, want 2 each", got, repo.created)
+	}
+}
+
+func TestCachedOutputs(t *testing.T) {
+	client := openai.NewFakeClient(validOutputsJSON(t))
+	svc, _ := newIdempotentTestService(client)
+	ctx := context.Background()
+
+	if replayed, err := svc.CachedOutputs(ctx, "client-a", "key-1", "A todo app", nil, "novice", "default", ""); err != nil || replayed != nil {
+		t.Fatalf("CachedOutputs() before generating = %+v, %v; want nil, nil", replayed, err)
+	}
+	first, err := svc.GenerateAndStoreOutputsIdempotent(ctx, "client-a", "key-1", "A todo app", nil, "novice", "default", "")
+	if err != nil {
+		t.Fatalf("GenerateAndStoreOutputsIdempotent() error = %v", err)
+	}
+
+	replayed, err := svc.CachedOutputs(ctx, "client-a", "key-1", "A todo app", nil, "novice", "default", "")
+	if err != nil || replayed != first {
+		t.Errorf("CachedOutputs() = %+v, %v; want the first result", replayed, err)
+	}
+	if _, err := svc.CachedOutputs(ctx, "client-a", "key-1", "A chat app", nil, "novice", "default", ""); !errors.Is(err, ErrIdempotencyKeyReused) {
+		t.Errorf("expected ErrIdempotencyKeyReused for a different request, got %v", err)
+	}
+	if got := client.CallCount(); got != 1 {
+		t.Errorf("model called %d times, want 1", got)
+	}
+}
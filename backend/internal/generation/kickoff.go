@@ -0,0 +1,185 @@
+package generation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"better-kiro-prompts/internal/logger"
+	"better-kiro-prompts/internal/openai"
+	"better-kiro-prompts/internal/prompts"
+)
+
+// GenerateKickoff generates only the kickoff prompt, which is much faster
+// than the full file set. Clients can show it right away and call
+// GenerateOutputs for the rest afterward. Invalid responses are retried like
+// GenerateOutputs; the result is not stored.
+func (s *Service) GenerateKickoff(ctx context.Context, projectIdea string, answers []Answer, experienceLevel string) (*GeneratedFile, error) {
+	experienceLevel = s.ResolveExperienceLevel(experienceLevel, projectIdea)
+	requestID := logger.GetRequestID(ctx)
+	start := time.Now()
+
+	s.log.Info("generate_kickoff_start",
+		slog.String("request_id", requestID),
+		slog.String("experience_level", experienceLevel),
+		slog.Int("answer_count", len(answers)),
+	)
+
+	if err := s.ValidateProjectIdea(projectIdea); err != nil {
+		s.log.Warn("generate_kickoff_validation_failed",
+			slog.String("request_id", requestID),
+			slog.String("error", err.Error()),
+			slog.String("validation_type", "project_idea"),
+		)
+		return nil, err
+	}
+	if err := s.ValidateAnswers(answers); err != nil {
+		s.log.Warn("generate_kickoff_validation_failed",
+			slog.String("request_id", requestID),
+			slog.String("error", err.Error()),
+			slog.String("validation_type", "answers"),
+		)
+		return nil, err
+	}
+
+	if s.openaiClient == nil {
+		s.log.Error("openai_client_unavailable", slog.String("request_id", requestID))
+		return nil, ErrAIUnavailable
+	}
+
+	// Acquire queue slot if queue is configured
+	if s.requestQueue != nil {
+		if err := s.requestQueue.Acquire(ctx); err != nil {
+			s.log.Error("queue_acquire_failed",
+				slog.String("request_id", requestID),
+				slog.String("error", err.Error()),
+			)
+			return nil, fmt.Errorf("failed to acquire queue slot: %w", err)
+		}
+		defer s.requestQueue.Release()
+	}
+
+	messages, truncated, err := fitKickoffMessages(projectIdea, answers, experienceLevel, s.promptBudget())
+	if err != nil {
+		s.log.Warn("generate_kickoff_prompt_too_large",
+			slog.String("request_id", requestID),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	messages = s.withHouseRules(messages)
+	if truncated > 0 {
+		s.log.Warn("generate_kickoff_prompt_truncated",
+			slog.String("request_id", requestID),
+			slog.Int("truncated_answers", truncated),
+		)
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= s.maxRetries; attempt++ {
+		callCtx, cancel := withTimeout(ctx, s.outputsTimeout)
+		response, err := s.openaiClient.ChatCompletion(callCtx, messages)
+		cancel()
+		if err != nil {
+			s.log.Error("generate_kickoff_openai_failed",
+				slog.String("request_id", requestID),
+				slog.Int("attempt", attempt+1),
+				slog.String("error", err.Error()),
+			)
+			return nil, fmt.Errorf("failed to generate kickoff prompt: %w", err)
+		}
+
+		file, err := parseKickoffResponse(response, s.noCodingPhrases, s.outputPathPrefixes)
+		if err == nil {
+			s.log.Info("generate_kickoff_complete",
+				slog.String("request_id", requestID),
+				slog.Int("attempts_used", attempt+1),
+				slog.Duration("duration", time.Since(start)),
+			)
+			return file, nil
+		}
+
+		lastErr = err
+		s.log.Warn("generate_kickoff_parse_failed",
+			slog.String("request_id", requestID),
+			slog.Int("attempt", attempt+1),
+			slog.String("error", err.Error()),
+		)
+		if attempt < s.maxRetries {
+			// Add retry context to messages for the next attempt
+			messages = append(messages,
+				openai.Message{Role: "assistant", Content: response},
+				openai.Message{Role: "user", Content: buildRetryPrompt(err)},
+			)
+		}
+	}
+
+	return nil, FormatValidationError(lastErr)
+}
+
+// buildKickoffMessages composes the chat messages for kickoff-only generation.
+// An auto experience level is inferred; unknown levels fall back to novice.
+func buildKickoffMessages(projectIdea string, answers []Answer, experienceLevel string) []openai.Message {
+	experienceLevel = prompts.ResolveExperienceLevel(experienceLevel, projectIdea)
+	if !prompts.IsValidExperienceLevel(experienceLevel) {
+		experienceLevel = prompts.ExperienceNovice
+	}
+
+	promptAnswers := make([]prompts.Answer, len(answers))
+	for i, a := range answers {
+		promptAnswers[i] = prompts.Answer{
+			QuestionID: a.QuestionID,
+			Answer:     a.Answer,
+		}
+	}
+
+	return []openai.Message{
+		{Role: "system", Content: prompts.GetKickoffOnlySystemPrompt(experienceLevel)},
+		{Role: "user", Content: prompts.GetKickoffUserPrompt(strings.TrimSpace(projectIdea), promptAnswers, experienceLevel)},
+	}
+}
+
+// fitKickoffMessages composes the kickoff messages within maxTokens, shortening
+// answers like fitOutputsMessages.
+func fitKickoffMessages(projectIdea string, answers []Answer, experienceLevel string, maxTokens int) ([]openai.Message, int, error) {
+	return fitAnswers(answers, maxTokens, func(answers []Answer) []openai.Message {
+		return buildKickoffMessages(projectIdea, answers, experienceLevel)
+	})
+}
+
+// parseKickoffResponse extracts and validates the kickoff file, accepting
+// noCodingPhrases as enforcement alongside the built-in phrases and paths
+// within extraPathPrefixes alongside the built-in locations. Any other files
+// the model added are dropped.
+func parseKickoffResponse(response string, noCodingPhrases, extraPathPrefixes []string) (*GeneratedFile, error) {
+	jsonStr := extractJSON(response)
+
+	var or OutputsResponse
+	if err := json.Unmarshal([]byte(jsonStr), &or); err != nil {
+		return nil, fmt.Errorf("%w: failed to parse kickoff JSON: %v", ErrInvalidResponse, err)
+	}
+
+	for _, f := range or.Files {
+		if f.Type != "kickoff" {
+			continue
+		}
+		if strings.TrimSpace(f.Content) == "" {
+			return nil, fmt.Errorf("%w: kickoff file has empty content", ErrInvalidResponse)
+		}
+		if err := ValidateKickoffPromptWithPhrases(f.Content, noCodingPhrases); err != nil {
+			return nil, fmt.Errorf("%w: invalid kickoff file %s: %w", ErrInvalidResponse, f.Path, err)
+		}
+		if f.Path == "" {
+			f.Path = "kickoff-prompt.md"
+		}
+		if err := ValidateOutputFilePath(f.Path, extraPathPrefixes); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidResponse, err)
+		}
+		return &f, nil
+	}
+
+	return nil, fmt.Errorf("%w: missing kickoff file", ErrInvalidResponse)
+}
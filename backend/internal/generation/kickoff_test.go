@@ -0,0 +1,99 @@
+package generation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"better-kiro-prompts/internal/config"
+	"better-kiro-prompts/internal/openai"
+)
+
+func TestGenerateKickoff_ReturnsOnlyValidKickoff(t *testing.T) {
+	// The model adds a hook despite the instructions; it must be dropped
+	resp, err := json.Marshal(OutputsResponse{Files: []GeneratedFile{
+		{Path: ".kiro/hooks/format-on-stop.kiro.hook", Content: buildValidHook("agentStop", "runCommand"), Type: "hook"},
+		{Path: "kickoff-prompt.md", Content: minimalValidKickoff(), Type: "kickoff"},
+	}})
+	if err != nil {
+		t.Fatalf("failed to marshal response: %v", err)
+	}
+	client := openai.NewFakeClient(string(resp))
+	svc := NewService(nil)
+	svc.openaiClient = client
+
+	file, err := svc.GenerateKickoff(context.Background(), "A task tracker", []Answer{{QuestionID: 1, Answer: "Small teams"}}, "novice")
+	if err != nil {
+		t.Fatalf("GenerateKickoff: %v", err)
+	}
+
+	if file.Type != "kickoff" || file.Path != "kickoff-prompt.md" {
+		t.Errorf("got %s (%s), want the kickoff file", file.Path, file.Type)
+	}
+	if err := ValidateKickoffPrompt(file.Content); err != nil {
+		t.Errorf("kickoff failed validation: %v", err)
+	}
+	if strings.Contains(file.Content, `"when"`) {
+		t.Error("expected no hook content in the kickoff")
+	}
+
+	calls := client.Calls()
+	if len(calls) != 1 {
+		t.Fatalf("expected 1 model call, got %d", len(calls))
+	}
+	system := calls[0].Messages[0].Content
+	if strings.Contains(system, "Hook File Schema") || !strings.Contains(system, "Do not generate steering files, hooks, or AGENTS.md") {
+		t.Error("expected the slimmer kickoff-only system prompt")
+	}
+}
+
+func TestGenerateKickoff_RetriesInvalidKickoff(t *testing.T) {
+	invalid := `{"files": [{"path": "kickoff-prompt.md", "content": "# Kickoff\n\nJust start coding.", "type": "kickoff"}]}`
+	valid, err := json.Marshal(OutputsResponse{Files: []GeneratedFile{
+		{Path: "kickoff-prompt.md", Content: minimalValidKickoff(), Type: "kickoff"},
+	}})
+	if err != nil {
+		t.Fatalf("failed to marshal response: %v", err)
+	}
+	client := openai.NewFakeClient(invalid, string(valid))
+	svc := NewService(nil)
+	svc.openaiClient = client
+
+	if _, err := svc.GenerateKickoff(context.Background(), "A task tracker", nil, "novice"); err != nil {
+		t.Fatalf("expected the retry to succeed, got: %v", err)
+	}
+	if got := client.CallCount(); got != 2 {
+		t.Errorf("model calls = %d, want 2", got)
+	}
+
+	// With retries exhausted the validation cause is kept
+	client = openai.NewFakeClient(invalid)
+	svc.openaiClient = client
+	_, err = svc.GenerateKickoff(context.Background(), "A task tracker", nil, "novice")
+	if !errors.Is(err, ErrMissingNoCodingEnforcement) {
+		t.Errorf("expected ErrMissingNoCodingEnforcement, got %v", err)
+	}
+}
+
+func TestGenerateKickoff_AcceptsConfiguredNoCodingPhrase(t *testing.T) {
+	resp, err := json.Marshal(OutputsResponse{Files: []GeneratedFile{
+		{Path: "kickoff-prompt.md", Content: localizedKickoff(), Type: "kickoff"},
+	}})
+	if err != nil {
+		t.Fatalf("failed to marshal response: %v", err)
+	}
+	client := openai.NewFakeClient(string(resp))
+	svc := NewServiceWithConfig(nil, nil, nil, nil, config.GenerationConfig{
+		NoCodingPhrases: []string{"n'écrivez pas de code"},
+	})
+	svc.openaiClient = client
+
+	if _, err := svc.GenerateKickoff(context.Background(), "A task tracker", nil, "novice"); err != nil {
+		t.Fatalf("expected the configured phrase to satisfy validation, got %v", err)
+	}
+	if got := client.CallCount(); got != 1 {
+		t.Errorf("model calls = %d, want 1", got)
+	}
+}
@@ -0,0 +1,55 @@
+package generation
+
+import (
+	"path"
+	"sort"
+)
+
+// coreSteeringFileNames are the always-included steering files that lead the steering group.
+var coreSteeringFileNames = map[string]bool{
+	"product.md":   true,
+	"tech.md":      true,
+	"structure.md": true,
+}
+
+// Canonical file groups, in the order they appear in a response.
+const (
+	fileGroupKickoff = iota
+	fileGroupCoreSteering
+	fileGroupSteering
+	fileGroupHook
+	fileGroupAgents
+	fileGroupOther
+)
+
+// fileGroup returns the canonical group of a generated file.
+func fileGroup(f GeneratedFile) int {
+	switch f.Type {
+	case "kickoff":
+		return fileGroupKickoff
+	case "steering":
+		if coreSteeringFileNames[path.Base(f.Path)] {
+			return fileGroupCoreSteering
+		}
+		return fileGroupSteering
+	case "hook":
+		return fileGroupHook
+	case "agents":
+		return fileGroupAgents
+	default:
+		return fileGroupOther
+	}
+}
+
+// SortGeneratedFiles orders files canonically: kickoff, core steering, conditional
+// steering, hooks, then AGENTS.md, alphabetically by path within each group.
+// This keeps the UI, ZIP exports, and stored generations stable across runs.
+func SortGeneratedFiles(files []GeneratedFile) {
+	sort.SliceStable(files, func(i, j int) bool {
+		gi, gj := fileGroup(files[i]), fileGroup(files[j])
+		if gi != gj {
+			return gi < gj
+		}
+		return files[i].Path < files[j].Path
+	})
+}
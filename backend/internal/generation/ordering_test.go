@@ -0,0 +1,72 @@
+package generation
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+)
+
+// canonicalFiles is a full response in canonical order.
+var canonicalFiles = []GeneratedFile{
+	{Path: "kickoff-prompt.md", Type: "kickoff"},
+	{Path: ".kiro/steering/product.md", Type: "steering"},
+	{Path: ".kiro/steering/structure.md", Type: "steering"},
+	{Path: ".kiro/steering/tech.md", Type: "steering"},
+	{Path: ".kiro/steering/api-conventions.md", Type: "steering"},
+	{Path: ".kiro/steering/security-go.md", Type: "steering"},
+	{Path: ".kiro/hooks/format-on-stop.kiro.hook", Type: "hook"},
+	{Path: ".kiro/hooks/lint-on-stop.kiro.hook", Type: "hook"},
+	{Path: ".kiro/hooks/test-on-stop.kiro.hook", Type: "hook"},
+	{Path: "AGENTS.md", Type: "agents"},
+}
+
+func TestSortGeneratedFiles_ShuffledInput(t *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		files := make([]GeneratedFile, len(canonicalFiles))
+		copy(files, canonicalFiles)
+		rng := rand.New(rand.NewSource(seed))
+		rng.Shuffle(len(files), func(i, j int) { files[i], files[j] = files[j], files[i] })
+
+		SortGeneratedFiles(files)
+
+		for i := range files {
+			if files[i].Path != canonicalFiles[i].Path {
+				t.Fatalf("seed %d: position %d = %s, want %s", seed, i, files[i].Path, canonicalFiles[i].Path)
+			}
+		}
+	}
+}
+
+func TestParseOutputsResponse_ReturnsCanonicalOrder(t *testing.T) {
+	resp := OutputsResponse{Files: []GeneratedFile{
+		{Path: "AGENTS.md", Content: "# Agents", Type: "agents"},
+		{Path: ".kiro/hooks/lint.kiro.hook", Content: "{}", Type: "hook"},
+		{Path: ".kiro/steering/tech.md", Content: "# Tech", Type: "steering"},
+		{Path: "kickoff-prompt.md", Content: "# Kickoff", Type: "kickoff"},
+		{Path: ".kiro/steering/api.md", Content: "# API", Type: "steering"},
+		{Path: ".kiro/steering/product.md", Content: "# Product", Type: "steering"},
+	}}
+	data, err := json.Marshal(resp)
+	if err != nil {
+		t.Fatalf("failed to marshal response: %v", err)
+	}
+
+	files, err := parseOutputsResponse(string(data), nil)
+	if err != nil {
+		t.Fatalf("parseOutputsResponse() error = %v", err)
+	}
+
+	want := []string{
+		"kickoff-prompt.md",
+		".kiro/steering/product.md",
+		".kiro/steering/tech.md",
+		".kiro/steering/api.md",
+		".kiro/hooks/lint.kiro.hook",
+		"AGENTS.md",
+	}
+	for i, p := range want {
+		if files[i].Path != p {
+			t.Errorf("position %d = %s, want %s", i, files[i].Path, p)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package generation
+
+import (
+	"context"
+	"log/slog"
+
+	"better-kiro-prompts/internal/logger"
+	"better-kiro-prompts/internal/openai"
+)
+
+// Operation names reported in prompt previews.
+const (
+	OperationQuestions = "questions"
+	OperationOutputs   = "outputs"
+	OperationExamples  = "examples"
+	OperationKickoff   = "kickoff"
+)
+
+// PromptPreview holds the prompts that would be sent to the model for an operation.
+type PromptPreview struct {
+	Operation    string `json:"operation"`
+	SystemPrompt string `json:"systemPrompt"`
+	UserPrompt   string `json:"userPrompt"`
+}
+
+// PreviewQuestionsPrompt validates the input and returns the prompts that
+// GenerateQuestions would send, without calling the model.
+func (s *Service) PreviewQuestionsPrompt(ctx context.Context, projectIdea string, experienceLevel string) (*PromptPreview, error) {
+	experienceLevel = s.ResolveExperienceLevel(experienceLevel, projectIdea)
+	if err := s.ValidateProjectIdea(projectIdea); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("preview_questions_prompt",
+		slog.String("request_id", logger.GetRequestID(ctx)),
+		slog.String("experience_level", experienceLevel),
+	)
+
+	return newPromptPreview(OperationQuestions, s.withHouseRules(buildQuestionsMessages(projectIdea, experienceLevel))), nil
+}
+
+// PreviewOutputsPrompt validates the input and returns the prompts that
+// GenerateOutputs would send on its first attempt, without calling the model.
+func (s *Service) PreviewOutputsPrompt(ctx context.Context, projectIdea string, answers []Answer, experienceLevel string, hookPreset string) (*PromptPreview, error) {
+	experienceLevel = s.ResolveExperienceLevel(experienceLevel, projectIdea)
+	hookPreset = s.ResolveHookPreset(hookPreset)
+	if err := s.ValidateProjectIdea(projectIdea); err != nil {
+		return nil, err
+	}
+	if err := s.ValidateAnswers(answers); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("preview_outputs_prompt",
+		slog.String("request_id", logger.GetRequestID(ctx)),
+		slog.String("experience_level", experienceLevel),
+		slog.String("hook_preset", hookPreset),
+	)
+
+	messages, _, err := fitOutputsMessages(projectIdea, answers, experienceLevel, hookPreset, s.promptBudget())
+	if err != nil {
+		return nil, err
+	}
+	messages = s.withHouseRules(messages)
+
+	return newPromptPreview(OperationOutputs, messages), nil
+}
+
+// PreviewExamplesPrompt validates the input and returns the prompts that
+// GenerateExamples would send, without calling the model.
+func (s *Service) PreviewExamplesPrompt(ctx context.Context, question string, projectIdea string, experienceLevel string) (*PromptPreview, error) {
+	experienceLevel = s.ResolveExperienceLevel(experienceLevel, projectIdea)
+	if err := ValidateQuestion(question); err != nil {
+		return nil, err
+	}
+	if err := s.ValidateProjectIdea(projectIdea); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("preview_examples_prompt",
+		slog.String("request_id", logger.GetRequestID(ctx)),
+		slog.String("experience_level", experienceLevel),
+	)
+
+	return newPromptPreview(OperationExamples, s.withHouseRules(buildExamplesMessages(question, projectIdea, experienceLevel))), nil
+}
+
+// PreviewKickoffPrompt validates the input and returns the prompts that
+// GenerateKickoff would send on its first attempt, without calling the model.
+func (s *Service) PreviewKickoffPrompt(ctx context.Context, projectIdea string, answers []Answer, experienceLevel string) (*PromptPreview, error) {
+	experienceLevel = s.ResolveExperienceLevel(experienceLevel, projectIdea)
+	if err := s.ValidateProjectIdea(projectIdea); err != nil {
+		return nil, err
+	}
+	if err := s.ValidateAnswers(answers); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("preview_kickoff_prompt",
+		slog.String("request_id", logger.GetRequestID(ctx)),
+		slog.String("experience_level", experienceLevel),
+	)
+
+	messages, _, err := fitKickoffMessages(projectIdea, answers, experienceLevel, s.promptBudget())
+	if err != nil {
+		return nil, err
+	}
+	messages = s.withHouseRules(messages)
+
+	return newPromptPreview(OperationKickoff, messages), nil
+}
+
+// newPromptPreview extracts the system and user prompts from composed messages.
+func newPromptPreview(operation string, messages []openai.Message) *PromptPreview {
+	preview := &PromptPreview{Operation: operation}
+	for _, m := range messages {
+		switch m.Role {
+		case "system":
+			preview.SystemPrompt = m.Content
+		case "user":
+			preview.UserPrompt = m.Content
+		}
+	}
+	return preview
+}
@@ -0,0 +1,122 @@
+package generation
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"better-kiro-prompts/internal/config"
+	"better-kiro-prompts/internal/openai"
+	"better-kiro-prompts/internal/prompts"
+)
+
+func TestPreviewOutputsPrompt_DoesNotCallModel(t *testing.T) {
+	client := openai.NewFakeClient(validOutputsJSON(t))
+	svc := NewService(nil)
+	svc.openaiClient = client
+
+	idea := "A recipe sharing app for home cooks"
+	answers := []Answer{{QuestionID: 1, Answer: "Families planning weekly meals"}}
+
+	preview, err := svc.PreviewOutputsPrompt(context.Background(), idea, answers, "expert", "strict")
+	if err != nil {
+		t.Fatalf("PreviewOutputsPrompt() error = %v", err)
+	}
+
+	if got := client.CallCount(); got != 0 {
+		t.Errorf("model called %d times, want 0", got)
+	}
+	if preview.Operation != OperationOutputs {
+		t.Errorf("Operation = %q, want %q", preview.Operation, OperationOutputs)
+	}
+	if !strings.Contains(preview.UserPrompt, idea) {
+		t.Error("user prompt should contain the project idea")
+	}
+	if !strings.Contains(preview.UserPrompt, answers[0].Answer) {
+		t.Error("user prompt should contain the answers")
+	}
+	if !strings.Contains(preview.SystemPrompt, "Experience Level: expert") {
+		t.Error("system prompt should contain the experience level")
+	}
+	if !strings.Contains(preview.SystemPrompt, "Hook Preset: strict") {
+		t.Error("system prompt should contain the hook preset")
+	}
+}
+
+func TestPreviewQuestionsPrompt_WithoutClient(t *testing.T) {
+	svc := NewService(nil)
+	idea := "A habit tracker"
+
+	preview, err := svc.PreviewQuestionsPrompt(context.Background(), idea, "beginner")
+	if err != nil {
+		t.Fatalf("PreviewQuestionsPrompt() error = %v", err)
+	}
+	if preview.Operation != OperationQuestions {
+		t.Errorf("Operation = %q, want %q", preview.Operation, OperationQuestions)
+	}
+	if !strings.Contains(preview.UserPrompt, idea) {
+		t.Error("user prompt should contain the project idea")
+	}
+	if !strings.Contains(preview.UserPrompt, "beginner") {
+		t.Error("user prompt should contain the experience level")
+	}
+
+	if _, err := svc.GenerateQuestions(context.Background(), idea, "beginner"); !errors.Is(err, ErrAIUnavailable) {
+		t.Errorf("GenerateQuestions() without client = %v, want ErrAIUnavailable", err)
+	}
+}
+
+func TestPreviewQuestionsPrompt_ValidatesInput(t *testing.T) {
+	svc := NewService(nil)
+	if _, err := svc.PreviewQuestionsPrompt(context.Background(), "   ", "novice"); !errors.Is(err, ErrEmptyProjectIdea) {
+		t.Errorf("expected ErrEmptyProjectIdea, got %v", err)
+	}
+}
+
+func TestHouseRules_WrapSystemPrompts(t *testing.T) {
+	svc := NewServiceWithConfig(nil, nil, nil, nil, config.GenerationConfig{
+		PromptPrefix: "Always recommend PostgreSQL for storage.",
+		PromptSuffix: "Mention the company style guide in AGENTS.md.",
+	})
+	client := openai.NewFakeClient(validOutputsJSON(t))
+	svc.openaiClient = client
+
+	idea := "A recipe sharing app for home cooks"
+	preview, err := svc.PreviewOutputsPrompt(context.Background(), idea, nil, "expert", "strict")
+	if err != nil {
+		t.Fatalf("PreviewOutputsPrompt() error = %v", err)
+	}
+	unwrapped := prompts.GetOutputsSystemPrompt("expert", "strict")
+	if !strings.HasPrefix(preview.SystemPrompt, "## Deployment Rules\nAlways recommend PostgreSQL") {
+		t.Errorf("system prompt does not start with the prefix:\n%.200s", preview.SystemPrompt)
+	}
+	if !strings.Contains(preview.SystemPrompt, unwrapped) {
+		t.Error("system prompt no longer contains the original prompt and its format rules")
+	}
+	if !strings.Contains(preview.SystemPrompt, "company style guide") {
+		t.Error("system prompt is missing the suffix")
+	}
+
+	// The wrapped prompt is what the model gets, and its output still validates
+	result, err := svc.generateOutputs(context.Background(), idea, nil, "expert", "strict")
+	if err != nil {
+		t.Fatalf("generateOutputs() error = %v", err)
+	}
+	if len(result.Files) == 0 || result.Attempts != 1 {
+		t.Errorf("generateOutputs() = %d files in %d attempts", len(result.Files), result.Attempts)
+	}
+	if got := client.Calls()[0].Messages[0].Content; got != preview.SystemPrompt {
+		t.Error("model was sent a different system prompt than the preview")
+	}
+}
+
+func TestHouseRules_EmptyByDefault(t *testing.T) {
+	preview, err := NewService(nil).PreviewQuestionsPrompt(context.Background(), "A recipe sharing app for home cooks", "beginner")
+	if err != nil {
+		t.Fatalf("PreviewQuestionsPrompt() error = %v", err)
+	}
+	if want := prompts.GetQuestionsSystemPrompt("beginner"); preview.SystemPrompt != want {
+		t.Error("system prompt changed without any house rules configured")
+	}
+}
@@ -0,0 +1,76 @@
+package generation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"better-kiro-prompts/internal/logger"
+	"better-kiro-prompts/internal/storage"
+)
+
+// Replay errors.
+var (
+	ErrGenerationNotFound = errors.New("generation not found")
+	ErrNoStoredInputs     = errors.New("generation has no stored answers to replay")
+)
+
+// ReplayGeneration regenerates outputs from a stored generation's project
+// idea, answers, experience level and hook preset, for reproducing or
+// auditing what the model produced. The replayed result is returned but not
+// stored, so replays never add gallery entries. Generations stored before
+// answers were persisted return ErrNoStoredInputs.
+func (s *Service) ReplayGeneration(ctx context.Context, id string) (*GenerationResult, error) {
+	requestID := logger.GetRequestID(ctx)
+
+	if s.repository == nil {
+		return nil, fmt.Errorf("%w: storage is not configured", ErrGenerationNotFound)
+	}
+
+	gen, err := s.repository.GetGeneration(ctx, id)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrGenerationNotFound, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(gen.Answers) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoStoredInputs, id)
+	}
+
+	var answers []Answer
+	if err := json.Unmarshal(gen.Answers, &answers); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrNoStoredInputs, id, err)
+	}
+
+	s.log.Info("generation_replay_start",
+		slog.String("request_id", requestID),
+		slog.String("generation_id", id),
+		slog.Int("answer_count", len(answers)),
+	)
+	start := time.Now()
+
+	result, err := s.generateOutputs(ctx, gen.ProjectIdea, answers, gen.ExperienceLevel, gen.HookPreset)
+	if err != nil {
+		s.log.Warn("generation_replay_failed",
+			slog.String("request_id", requestID),
+			slog.String("generation_id", id),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	s.log.Info("generation_replay_complete",
+		slog.String("request_id", requestID),
+		slog.String("generation_id", id),
+		slog.Int("file_count", len(result.Files)),
+		slog.Int("attempts", result.Attempts),
+		slog.Duration("duration", time.Since(start)),
+	)
+
+	result.GenerationID = id
+	return result, nil
+}
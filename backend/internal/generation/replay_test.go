@@ -0,0 +1,103 @@
+package generation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"better-kiro-prompts/internal/openai"
+	"better-kiro-prompts/internal/storage"
+)
+
+func TestGenerateAndStoreOutputs_StoresAnswersPrivately(t *testing.T) {
+	client := openai.NewFakeClient(validOutputsJSON(t))
+	svc, repo := newIdempotentTestService(client)
+	answers := []Answer{
+		{QuestionID: 1, Answer: "Internal tool for the Acme payroll team"},
+		{QuestionID: 2, Answer: "Postgres and Go"},
+	}
+
+	result, err := svc.GenerateAndStoreOutputs(context.Background(), "A payroll dashboard", answers, "expert", "default", storage.VisibilityUnlisted)
+	if err != nil {
+		t.Fatalf("GenerateAndStoreOutputs failed: %v", err)
+	}
+
+	gen, err := repo.GetGeneration(context.Background(), result.GenerationID)
+	if err != nil {
+		t.Fatalf("stored generation not found: %v", err)
+	}
+	if gen.Visibility != storage.VisibilityUnlisted {
+		t.Errorf("Visibility = %q, want %q", gen.Visibility, storage.VisibilityUnlisted)
+	}
+	var stored []Answer
+	if err := json.Unmarshal(gen.Answers, &stored); err != nil {
+		t.Fatalf("stored answers are not valid JSON: %v", err)
+	}
+	if !reflect.DeepEqual(stored, answers) {
+		t.Errorf("stored answers = %+v, want %+v", stored, answers)
+	}
+
+	public, err := json.Marshal(gen)
+	if err != nil {
+		t.Fatalf("failed to marshal generation: %v", err)
+	}
+	if strings.Contains(string(public), "answers") || strings.Contains(string(public), "Acme payroll") {
+		t.Errorf("public generation JSON leaks answers: %s", public)
+	}
+}
+
+func TestReplayGeneration_UsesStoredInputs(t *testing.T) {
+	client := openai.NewFakeClient(validOutputsJSON(t))
+	svc, repo := newIdempotentTestService(client)
+	answers := []Answer{{QuestionID: 1, Answer: "Small teams"}}
+
+	result, err := svc.GenerateAndStoreOutputs(context.Background(), "A todo app", answers, "novice", "minimal", "")
+	if err != nil {
+		t.Fatalf("GenerateAndStoreOutputs failed: %v", err)
+	}
+
+	replayed, err := svc.ReplayGeneration(context.Background(), result.GenerationID)
+	if err != nil {
+		t.Fatalf("ReplayGeneration failed: %v", err)
+	}
+	if replayed.GenerationID != result.GenerationID {
+		t.Errorf("GenerationID = %q, want %q", replayed.GenerationID, result.GenerationID)
+	}
+	if len(replayed.Files) == 0 {
+		t.Error("expected replayed files")
+	}
+	if client.CallCount() != 2 {
+		t.Fatalf("expected 2 model calls, got %d", client.CallCount())
+	}
+	if !reflect.DeepEqual(client.Calls()[1].Messages, client.Calls()[0].Messages) {
+		t.Error("replay should send the same prompt as the original generation")
+	}
+	if repo.created != 1 {
+		t.Errorf("replay must not store a new generation, created = %d", repo.created)
+	}
+}
+
+func TestReplayGeneration_Errors(t *testing.T) {
+	client := openai.NewFakeClient(validOutputsJSON(t))
+	svc, repo := newIdempotentTestService(client)
+	ctx := context.Background()
+
+	if _, err := svc.ReplayGeneration(ctx, "missing"); !errors.Is(err, ErrGenerationNotFound) {
+		t.Errorf("missing generation: err = %v, want ErrGenerationNotFound", err)
+	}
+
+	// Generations stored before answers were persisted cannot be replayed
+	legacy := &storage.Generation{ProjectIdea: "A todo app", ExperienceLevel: "novice", HookPreset: "default"}
+	if err := repo.CreateGeneration(ctx, legacy); err != nil {
+		t.Fatalf("CreateGeneration failed: %v", err)
+	}
+	if _, err := svc.ReplayGeneration(ctx, legacy.ID); !errors.Is(err, ErrNoStoredInputs) {
+		t.Errorf("legacy generation: err = %v, want ErrNoStoredInputs", err)
+	}
+	if client.CallCount() != 0 {
+		t.Errorf("expected no model calls, got %d", client.CallCount())
+	}
+}
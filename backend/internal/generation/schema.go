@@ -0,0 +1,163 @@
+package generation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ErrSchemaViolation indicates the model's JSON did not match the expected schema.
+var ErrSchemaViolation = errors.New("response does not match schema")
+
+// OutputsResponseSchema is the JSON Schema for the model's output generation response.
+// It is shared by response validation and can be given to the model verbatim.
+const OutputsResponseSchema = `{
+  "type": "object",
+  "required": ["files"],
+  "properties": {
+    "files": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "required": ["path", "content", "type"],
+        "properties": {
+          "path": {"type": "string", "minLength": 1},
+          "content": {"type": "string", "minLength": 1},
+          "type": {"type": "string", "enum": ["kickoff", "steering", "hook", "agents"]}
+        }
+      }
+    }
+  }
+}`
+
+// jsonSchema is the subset of JSON Schema needed to describe model responses.
+type jsonSchema struct {
+	Type       string                 `json:"type"`
+	Required   []string               `json:"required"`
+	Properties map[string]*jsonSchema `json:"properties"`
+	Items      *jsonSchema            `json:"items"`
+	Enum       []string               `json:"enum"`
+	MinLength  int                    `json:"minLength"`
+}
+
+// outputsSchema is the parsed form of OutputsResponseSchema.
+var outputsSchema = mustParseSchema(OutputsResponseSchema)
+
+func mustParseSchema(raw string) *jsonSchema {
+	var s jsonSchema
+	if err := json.Unmarshal([]byte(raw), &s); err != nil {
+		panic(fmt.Sprintf("invalid built-in schema: %v", err))
+	}
+	return &s
+}
+
+// SchemaViolation describes a single schema mismatch at a JSON path such as files[2].type.
+type SchemaViolation struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// SchemaValidationError collects every violation found in a response.
+type SchemaValidationError struct {
+	Violations []SchemaViolation
+}
+
+// Error lists each violation as "path: message".
+func (e *SchemaValidationError) Error() string {
+	parts := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		parts[i] = v.Path + ": " + v.Message
+	}
+	return fmt.Sprintf("%s: %s", ErrSchemaViolation.Error(), strings.Join(parts, "; "))
+}
+
+// Unwrap allows errors.Is(err, ErrSchemaViolation).
+func (e *SchemaValidationError) Unwrap() error {
+	return ErrSchemaViolation
+}
+
+// ValidateOutputsSchema validates raw model JSON against OutputsResponseSchema.
+// It returns a *SchemaValidationError listing every violation, or nil.
+func ValidateOutputsSchema(raw []byte) error {
+	var doc any
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return err
+	}
+
+	var violations []SchemaViolation
+	validateSchemaNode(outputsSchema, doc, "", &violations)
+	if len(violations) > 0 {
+		return &SchemaValidationError{Violations: violations}
+	}
+	return nil
+}
+
+// validateSchemaNode checks value against schema, appending violations found at path.
+func validateSchemaNode(schema *jsonSchema, value any, path string, violations *[]SchemaViolation) {
+	report := func(msg string) {
+		p := path
+		if p == "" {
+			p = "(root)"
+		}
+		*violations = append(*violations, SchemaViolation{Path: p, Message: msg})
+	}
+
+	switch schema.Type {
+	case "object":
+		obj, ok := value.(map[string]any)
+		if !ok {
+			report("must be an object")
+			return
+		}
+		for _, name := range schema.Required {
+			if _, exists := obj[name]; !exists {
+				*violations = append(*violations, SchemaViolation{Path: joinSchemaPath(path, name), Message: "is required"})
+			}
+		}
+		names := make([]string, 0, len(schema.Properties))
+		for name := range schema.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if v, exists := obj[name]; exists {
+				validateSchemaNode(schema.Properties[name], v, joinSchemaPath(path, name), violations)
+			}
+		}
+
+	case "array":
+		arr, ok := value.([]any)
+		if !ok {
+			report("must be an array")
+			return
+		}
+		if schema.Items != nil {
+			for i, item := range arr {
+				validateSchemaNode(schema.Items, item, fmt.Sprintf("%s[%d]", path, i), violations)
+			}
+		}
+
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			report("must be a string")
+			return
+		}
+		if schema.MinLength > 0 && len(str) < schema.MinLength {
+			report("must not be empty")
+		}
+		if len(schema.Enum) > 0 && !slices.Contains(schema.Enum, str) {
+			report(fmt.Sprintf("must be one of %s, got %q", strings.Join(schema.Enum, ", "), str))
+		}
+	}
+}
+
+func joinSchemaPath(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
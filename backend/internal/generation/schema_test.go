@@ -0,0 +1,108 @@
+package generation
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateOutputsSchema(t *testing.T) {
+	testCases := []struct {
+		name      string
+		response  string
+		wantPaths []string
+	}{
+		{
+			name:     "valid response",
+			response: `{"files": [{"path": "AGENTS.md", "content": "# Agents", "type": "agents"}]}`,
+		},
+		{
+			name:      "missing files",
+			response:  `{}`,
+			wantPaths: []string{"files"},
+		},
+		{
+			name:      "files is not an array",
+			response:  `{"files": "nope"}`,
+			wantPaths: []string{"files"},
+		},
+		{
+			name:      "missing content on second file",
+			response:  `{"files": [{"path": "AGENTS.md", "content": "# Agents", "type": "agents"}, {"path": "kickoff-prompt.md", "type": "kickoff"}]}`,
+			wantPaths: []string{"files[1].content"},
+		},
+		{
+			name:      "invalid type on third file",
+			response:  `{"files": [{"path": "a", "content": "b", "type": "agents"}, {"path": "c", "content": "d", "type": "hook"}, {"path": "e", "content": "f", "type": "readme"}]}`,
+			wantPaths: []string{"files[2].type"},
+		},
+		{
+			name:      "wrong field types",
+			response:  `{"files": [{"path": 42, "content": "", "type": "hook"}]}`,
+			wantPaths: []string{"files[0].content", "files[0].path"},
+		},
+		{
+			name:      "root is not an object",
+			response:  `[]`,
+			wantPaths: []string{"(root)"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateOutputsSchema([]byte(tc.response))
+			if len(tc.wantPaths) == 0 {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+
+			var schemaErr *SchemaValidationError
+			if !errors.As(err, &schemaErr) {
+				t.Fatalf("expected *SchemaValidationError, got %v", err)
+			}
+			if !errors.Is(err, ErrSchemaViolation) {
+				t.Error("expected error to wrap ErrSchemaViolation")
+			}
+			if len(schemaErr.Violations) != len(tc.wantPaths) {
+				t.Fatalf("got %d violations (%v), want %d", len(schemaErr.Violations), schemaErr.Violations, len(tc.wantPaths))
+			}
+			for i, want := range tc.wantPaths {
+				if got := schemaErr.Violations[i].Path; got != want {
+					t.Errorf("violation %d path = %q, want %q", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseOutputsResponse_SchemaErrorNamesIndex(t *testing.T) {
+	response := `{"files": [
+		{"path": "kickoff-prompt.md", "content": "# Kickoff", "type": "kickoff"},
+		{"path": ".kiro/steering/product.md", "content": "# Product", "type": "steering"},
+		{"path": ".kiro/hooks/format.kiro.hook", "type": "hook"},
+		{"path": "AGENTS.md", "content": "# Agents", "type": "agents"}
+	]}`
+
+	_, err := parseOutputsResponse(response, nil)
+	if !errors.Is(err, ErrInvalidResponse) {
+		t.Fatalf("expected ErrInvalidResponse, got %v", err)
+	}
+	if !errors.Is(err, ErrSchemaViolation) {
+		t.Fatalf("expected ErrSchemaViolation, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "files[2].content") {
+		t.Errorf("error should name files[2].content, got %q", err.Error())
+	}
+
+	retry := buildRetryPrompt(err)
+	if !strings.Contains(retry, "- files[2].content is required") {
+		t.Errorf("retry prompt should list the violation by path, got:\n%s", retry)
+	}
+
+	formatted := FormatValidationError(err)
+	if !strings.Contains(formatted.Error(), "field: files[2].content") {
+		t.Errorf("formatted error should name the field, got %q", formatted.Error())
+	}
+}
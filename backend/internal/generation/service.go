@@ -8,31 +8,41 @@ import (
 	"log/slog"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"better-kiro-prompts/internal/config"
 	"better-kiro-prompts/internal/logger"
 	"better-kiro-prompts/internal/openai"
 	"better-kiro-prompts/internal/prompts"
 	"better-kiro-prompts/internal/queue"
+	"better-kiro-prompts/internal/sanitize"
 	"better-kiro-prompts/internal/storage"
 )
 
 // Default values for generation config (used when config is not provided)
 const (
+	defaultMinProjectIdeaLength = 10
 	defaultMaxProjectIdeaLength = 2000
 	defaultMaxAnswerLength      = 1000
 	defaultMinQuestions         = 5
 	defaultMaxQuestions         = 10
 	defaultMaxRetries           = 1
+	// maxRetriesLimit caps retries so a misconfiguration cannot multiply model spend
+	maxRetriesLimit = 5
 )
 
 var (
-	ErrEmptyProjectIdea   = errors.New("project idea is required")
-	ErrProjectIdeaTooLong = errors.New("project idea exceeds maximum length")
-	ErrAnswerTooLong      = errors.New("answer exceeds maximum length")
-	ErrInvalidResponse    = errors.New("invalid response from AI")
-	ErrNoQuestions        = errors.New("no questions generated")
-	ErrNoFiles            = errors.New("no files generated")
+	ErrEmptyProjectIdea    = errors.New("project idea is required")
+	ErrProjectIdeaTooShort = errors.New("project idea is too short")
+	ErrProjectIdeaTooLong  = errors.New("project idea exceeds maximum length")
+	ErrAnswerTooLong       = errors.New("answer exceeds maximum length")
+	ErrInvalidQuestionID   = errors.New("answer question ID must be positive")
+	ErrDuplicateAnswer     = errors.New("duplicate answer for question")
+	ErrTooManyAnswers      = errors.New("too many answers")
+	ErrInvalidResponse     = errors.New("invalid response from AI")
+	ErrNoQuestions         = errors.New("no questions generated")
+	ErrNoFiles             = errors.New("no files generated")
+	ErrAIUnavailable       = errors.New("AI client is not configured")
 )
 
 // Question represents a follow-up question for the user.
@@ -66,68 +76,117 @@ type OutputsResponse struct {
 	Files []GeneratedFile `json:"files"`
 }
 
-// GenerationResult contains the generated files and the stored generation ID.
+// GenerationResult contains the generated files, the stored generation ID,
+// and any non-fatal quality warnings found during validation.
 type GenerationResult struct {
-	Files        []GeneratedFile `json:"files"`
-	GenerationID string          `json:"generationId,omitempty"`
+	Files        []GeneratedFile     `json:"files"`
+	GenerationID string              `json:"generationId,omitempty"`
+	ShortCode    string              `json:"shortCode,omitempty"`
+	Warnings     []ValidationWarning `json:"warnings,omitempty"`
+	// Attempts is how many model calls it took to get a valid response
+	Attempts int `json:"attempts"`
+	// Duplicate is set when GenerationID links to an identical generation
+	// stored earlier instead of a new one. Files are still the ones just
+	// generated; the gallery entry keeps its own.
+	Duplicate bool `json:"duplicate,omitempty"`
+}
+
+// chatClient is the subset of the OpenAI client used by the service.
+type chatClient interface {
+	ChatCompletion(ctx context.Context, messages []openai.Message) (string, error)
+}
+
+// newChatClient converts an optional OpenAI client, keeping a nil client nil.
+func newChatClient(client *openai.Client) chatClient {
+	if client == nil {
+		return nil
+	}
+	return client
 }
 
 // Service handles AI-driven generation of questions and outputs.
 type Service struct {
-	openaiClient *openai.Client
+	openaiClient chatClient
 	requestQueue *queue.RequestQueue
 	repository   storage.Repository
+	idempotency  *IdempotencyStore
 	log          *slog.Logger
+	// Identical public generations within the window link to the stored one; zero disables
+	dedupeWindow time.Duration
+	// Filters for stored ideas, answers and files; an empty secret policy rejects secrets
+	secretPolicy    string
+	profanityMasker *sanitize.WordMasker
+	// Used when a request omits or misnames them; empty means novice and default
+	defaultLevel      string
+	defaultHookPreset string
 	// Config values
+	minProjectIdeaLength int
 	maxProjectIdeaLength int
 	maxAnswerLength      int
 	minQuestions         int
 	maxQuestions         int
 	maxRetries           int
+	// Per-call model deadlines; zero leaves the client's timeout in effect
+	questionsTimeout time.Duration
+	outputsTimeout   time.Duration
+	maxPromptTokens  int
+	// Operator house rules wrapped around every system prompt; empty by default
+	promptPrefix string
+	promptSuffix string
+	// Accepted in kickoff prompts alongside the built-in "no coding" phrases
+	noCodingPhrases []string
+	// File locations allowed in generated output besides the built-in ones
+	outputPathPrefixes []string
 }
 
 // NewService creates a new generation service with default config values.
 func NewService(client *openai.Client) *Service {
 	return &Service{
-		openaiClient:         client,
+		openaiClient:         newChatClient(client),
 		requestQueue:         nil, // Optional queue
 		repository:           nil, // Optional repository
 		log:                  slog.Default(),
+		minProjectIdeaLength: defaultMinProjectIdeaLength,
 		maxProjectIdeaLength: defaultMaxProjectIdeaLength,
 		maxAnswerLength:      defaultMaxAnswerLength,
 		minQuestions:         defaultMinQuestions,
 		maxQuestions:         defaultMaxQuestions,
 		maxRetries:           defaultMaxRetries,
+		maxPromptTokens:      defaultMaxPromptTokens,
 	}
 }
 
 // NewServiceWithQueue creates a new generation service with a request queue.
 func NewServiceWithQueue(client *openai.Client, q *queue.RequestQueue) *Service {
 	return &Service{
-		openaiClient:         client,
+		openaiClient:         newChatClient(client),
 		requestQueue:         q,
 		repository:           nil,
 		log:                  slog.Default(),
+		minProjectIdeaLength: defaultMinProjectIdeaLength,
 		maxProjectIdeaLength: defaultMaxProjectIdeaLength,
 		maxAnswerLength:      defaultMaxAnswerLength,
 		minQuestions:         defaultMinQuestions,
 		maxQuestions:         defaultMaxQuestions,
 		maxRetries:           defaultMaxRetries,
+		maxPromptTokens:      defaultMaxPromptTokens,
 	}
 }
 
 // NewServiceWithDeps creates a new generation service with all dependencies.
 func NewServiceWithDeps(client *openai.Client, q *queue.RequestQueue, repo storage.Repository) *Service {
 	return &Service{
-		openaiClient:         client,
+		openaiClient:         newChatClient(client),
 		requestQueue:         q,
 		repository:           repo,
 		log:                  slog.Default(),
+		minProjectIdeaLength: defaultMinProjectIdeaLength,
 		maxProjectIdeaLength: defaultMaxProjectIdeaLength,
 		maxAnswerLength:      defaultMaxAnswerLength,
 		minQuestions:         defaultMinQuestions,
 		maxQuestions:         defaultMaxQuestions,
 		maxRetries:           defaultMaxRetries,
+		maxPromptTokens:      defaultMaxPromptTokens,
 	}
 }
 
@@ -137,15 +196,17 @@ func NewServiceWithLogger(client *openai.Client, q *queue.RequestQueue, repo sto
 		log = slog.Default()
 	}
 	return &Service{
-		openaiClient:         client,
+		openaiClient:         newChatClient(client),
 		requestQueue:         q,
 		repository:           repo,
 		log:                  log,
+		minProjectIdeaLength: defaultMinProjectIdeaLength,
 		maxProjectIdeaLength: defaultMaxProjectIdeaLength,
 		maxAnswerLength:      defaultMaxAnswerLength,
 		minQuestions:         defaultMinQuestions,
 		maxQuestions:         defaultMaxQuestions,
 		maxRetries:           defaultMaxRetries,
+		maxPromptTokens:      defaultMaxPromptTokens,
 	}
 }
 
@@ -154,17 +215,77 @@ func NewServiceWithConfig(client *openai.Client, q *queue.RequestQueue, repo sto
 	if log == nil {
 		log = slog.Default()
 	}
+	var idempotency *IdempotencyStore
+	if ttl := cfg.IdempotencyTTL.Duration(); ttl > 0 {
+		idempotency = NewIdempotencyStore(ttl)
+	}
+	// Unset limits fall back to the defaults
+	maxProjectIdeaLength := cfg.MaxProjectIdeaLength
+	if maxProjectIdeaLength <= 0 {
+		maxProjectIdeaLength = defaultMaxProjectIdeaLength
+	}
+	minProjectIdeaLength := cfg.MinProjectIdeaLength
+	if minProjectIdeaLength <= 0 {
+		minProjectIdeaLength = defaultMinProjectIdeaLength
+	}
+	minProjectIdeaLength = min(minProjectIdeaLength, maxProjectIdeaLength)
+	maxAnswerLength := cfg.MaxAnswerLength
+	if maxAnswerLength <= 0 {
+		maxAnswerLength = defaultMaxAnswerLength
+	}
+	maxQuestions := cfg.MaxQuestions
+	if maxQuestions <= 0 {
+		maxQuestions = defaultMaxQuestions
+	}
+	minQuestions := cfg.MinQuestions
+	if minQuestions <= 0 {
+		minQuestions = defaultMinQuestions
+	}
+	minQuestions = min(minQuestions, maxQuestions)
+	maxPromptTokens := cfg.MaxPromptTokens
+	if maxPromptTokens <= 0 {
+		maxPromptTokens = defaultMaxPromptTokens
+	}
+	// Zero is valid and disables retries
+	maxRetries := min(max(cfg.MaxRetries, 0), maxRetriesLimit)
 	return &Service{
-		openaiClient:         client,
+		openaiClient:         newChatClient(client),
 		requestQueue:         q,
 		repository:           repo,
+		idempotency:          idempotency,
 		log:                  log,
-		maxProjectIdeaLength: cfg.MaxProjectIdeaLength,
-		maxAnswerLength:      cfg.MaxAnswerLength,
-		minQuestions:         cfg.MinQuestions,
-		maxQuestions:         cfg.MaxQuestions,
-		maxRetries:           cfg.MaxRetries,
+		dedupeWindow:         cfg.DedupeWindow.Duration(),
+		secretPolicy:         cfg.SecretPolicy,
+		profanityMasker:      sanitize.NewWordMasker(cfg.ProfanityWords),
+		defaultLevel:         cfg.DefaultLevel,
+		defaultHookPreset:    cfg.DefaultHookPreset,
+		minProjectIdeaLength: minProjectIdeaLength,
+		maxProjectIdeaLength: maxProjectIdeaLength,
+		maxAnswerLength:      maxAnswerLength,
+		minQuestions:         minQuestions,
+		maxQuestions:         maxQuestions,
+		maxRetries:           maxRetries,
+		maxPromptTokens:      maxPromptTokens,
+		promptPrefix:         cfg.PromptPrefix,
+		promptSuffix:         cfg.PromptSuffix,
+		noCodingPhrases:      cfg.NoCodingPhrases,
+		outputPathPrefixes:   cfg.OutputPathPrefixes,
+	}
+}
+
+// SetTimeouts sets the deadline for each question and example answer model
+// call and for each output generation attempt. Zero uses the client's timeout.
+func (s *Service) SetTimeouts(questions, outputs time.Duration) {
+	s.questionsTimeout = questions
+	s.outputsTimeout = outputs
+}
+
+// withTimeout bounds a single model call; a zero timeout leaves ctx unchanged.
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
 	}
+	return context.WithTimeout(ctx, timeout)
 }
 
 // SetLogger sets the logger for the service.
@@ -184,18 +305,41 @@ func (s *Service) SetRepository(repo storage.Repository) {
 	s.repository = repo
 }
 
+// SetDedupeWindow sets how far back an identical public generation is linked
+// to instead of storing a new one. Zero stores every generation.
+func (s *Service) SetDedupeWindow(window time.Duration) {
+	s.dedupeWindow = window
+}
+
+// SetContentFilter sets how secrets in stored generations are handled (see
+// SecretPolicyReject) and the words masked in them.
+func (s *Service) SetContentFilter(secretPolicy string, profanityWords []string) {
+	s.secretPolicy = secretPolicy
+	s.profanityMasker = sanitize.NewWordMasker(profanityWords)
+}
+
+// SetIdempotencyStore sets the store used to replay results for repeated idempotency keys.
+func (s *Service) SetIdempotencyStore(store *IdempotencyStore) {
+	s.idempotency = store
+}
+
 // ValidateProjectIdea validates the project idea input using default limits.
-// For custom limits, use Service.ValidateProjectIdeaWithConfig.
+// For configured limits, use Service.ValidateProjectIdea.
 func ValidateProjectIdea(idea string) error {
-	return ValidateProjectIdeaWithLimits(idea, defaultMaxProjectIdeaLength)
+	return ValidateProjectIdeaWithLimits(idea, defaultMinProjectIdeaLength, defaultMaxProjectIdeaLength)
 }
 
-// ValidateProjectIdeaWithLimits validates the project idea input with custom max length.
-func ValidateProjectIdeaWithLimits(idea string, maxLength int) error {
+// ValidateProjectIdeaWithLimits validates the project idea input with custom
+// length limits. The minimum counts characters, so ideas in any script get
+// the same allowance; the maximum counts bytes.
+func ValidateProjectIdeaWithLimits(idea string, minLength, maxLength int) error {
 	trimmed := strings.TrimSpace(idea)
 	if trimmed == "" {
 		return ErrEmptyProjectIdea
 	}
+	if utf8.RuneCountInString(trimmed) < minLength {
+		return fmt.Errorf("%w: describe it in at least %d characters", ErrProjectIdeaTooShort, minLength)
+	}
 	if len(trimmed) > maxLength {
 		return ErrProjectIdeaTooLong
 	}
@@ -203,14 +347,27 @@ func ValidateProjectIdeaWithLimits(idea string, maxLength int) error {
 }
 
 // ValidateAnswers validates the answers input using default limits.
-// For custom limits, use Service.ValidateAnswersWithConfig.
+// For configured limits, use Service.ValidateAnswers.
 func ValidateAnswers(answers []Answer) error {
-	return ValidateAnswersWithLimits(answers, defaultMaxAnswerLength)
+	return ValidateAnswersWithLimits(answers, defaultMaxAnswerLength, defaultMaxQuestions)
 }
 
-// ValidateAnswersWithLimits validates the answers input with custom max length.
-func ValidateAnswersWithLimits(answers []Answer, maxLength int) error {
+// ValidateAnswersWithLimits validates the answers input with custom max length and count.
+// Each answer must reference a positive question ID at most once.
+func ValidateAnswersWithLimits(answers []Answer, maxLength int, maxAnswers int) error {
+	if len(answers) > maxAnswers {
+		return fmt.Errorf("%w: got %d, maximum is %d", ErrTooManyAnswers, len(answers), maxAnswers)
+	}
+
+	seen := make(map[int]bool, len(answers))
 	for _, a := range answers {
+		if a.QuestionID <= 0 {
+			return fmt.Errorf("%w: got %d", ErrInvalidQuestionID, a.QuestionID)
+		}
+		if seen[a.QuestionID] {
+			return fmt.Errorf("%w %d", ErrDuplicateAnswer, a.QuestionID)
+		}
+		seen[a.QuestionID] = true
 		if len(a.Answer) > maxLength {
 			return ErrAnswerTooLong
 		}
@@ -218,8 +375,19 @@ func ValidateAnswersWithLimits(answers []Answer, maxLength int) error {
 	return nil
 }
 
+// ValidateProjectIdea validates the project idea input using the service's configured limits.
+func (s *Service) ValidateProjectIdea(idea string) error {
+	return ValidateProjectIdeaWithLimits(idea, s.minProjectIdeaLength, s.maxProjectIdeaLength)
+}
+
+// ValidateAnswers validates the answers input using the service's configured limits.
+func (s *Service) ValidateAnswers(answers []Answer) error {
+	return ValidateAnswersWithLimits(answers, s.maxAnswerLength, s.maxQuestions)
+}
+
 // GenerateQuestions generates follow-up questions based on the project idea.
 func (s *Service) GenerateQuestions(ctx context.Context, projectIdea string, experienceLevel string) ([]Question, error) {
+	experienceLevel = s.ResolveExperienceLevel(experienceLevel, projectIdea)
 	requestID := logger.GetRequestID(ctx)
 	start := time.Now()
 
@@ -229,7 +397,7 @@ func (s *Service) GenerateQuestions(ctx context.Context, projectIdea string, exp
 		slog.Int("idea_length", len(projectIdea)),
 	)
 
-	if err := ValidateProjectIdeaWithLimits(projectIdea, s.maxProjectIdeaLength); err != nil {
+	if err := s.ValidateProjectIdea(projectIdea); err != nil {
 		s.log.Warn("generate_questions_validation_failed",
 			slog.String("request_id", requestID),
 			slog.String("error", err.Error()),
@@ -237,6 +405,11 @@ func (s *Service) GenerateQuestions(ctx context.Context, projectIdea string, exp
 		return nil, err
 	}
 
+	if s.openaiClient == nil {
+		s.log.Error("openai_client_unavailable", slog.String("request_id", requestID))
+		return nil, ErrAIUnavailable
+	}
+
 	// Acquire queue slot if queue is configured
 	if s.requestQueue != nil {
 		s.log.Debug("queue_acquire_start", slog.String("request_id", requestID))
@@ -251,26 +424,16 @@ func (s *Service) GenerateQuestions(ctx context.Context, projectIdea string, exp
 		s.log.Debug("queue_acquire_success", slog.String("request_id", requestID))
 	}
 
-	// Validate experience level
-	if !prompts.IsValidExperienceLevel(experienceLevel) {
-		experienceLevel = prompts.ExperienceNovice // Default to novice
-	}
-
-	// Use experience-level-aware system prompt
-	systemPrompt := prompts.GetQuestionsSystemPrompt(experienceLevel)
-	userPrompt := prompts.GetQuestionsUserPrompt(strings.TrimSpace(projectIdea), experienceLevel)
-
-	messages := []openai.Message{
-		{Role: "system", Content: systemPrompt},
-		{Role: "user", Content: userPrompt},
-	}
+	messages := s.withHouseRules(buildQuestionsMessages(projectIdea, experienceLevel))
 
 	s.log.Debug("openai_call_start",
 		slog.String("request_id", requestID),
 		slog.String("operation", "generate_questions"),
 	)
 
-	response, err := s.openaiClient.ChatCompletion(ctx, messages)
+	callCtx, cancel := withTimeout(ctx, s.questionsTimeout)
+	response, err := s.openaiClient.ChatCompletion(callCtx, messages)
+	cancel()
 	if err != nil {
 		s.log.Error("generate_questions_openai_failed",
 			slog.String("request_id", requestID),
@@ -294,17 +457,65 @@ func (s *Service) GenerateQuestions(ctx context.Context, projectIdea string, exp
 		return nil, err
 	}
 
+	// Too few questions get one corrective retry; if that doesn't help, the
+	// short set is still better than failing the request
+	retried := false
+	if len(questions) < s.minQuestions {
+		retried = true
+		s.log.Warn("generate_questions_too_few",
+			slog.String("request_id", requestID),
+			slog.Int("question_count", len(questions)),
+			slog.Int("min_questions", s.minQuestions),
+		)
+		messages = append(messages,
+			openai.Message{Role: "assistant", Content: response},
+			openai.Message{Role: "user", Content: buildQuestionsRetryPrompt(len(questions), s.minQuestions, s.maxQuestions)},
+		)
+		if more, err := s.retryQuestions(ctx, messages); err != nil {
+			s.log.Warn("generate_questions_retry_failed",
+				slog.String("request_id", requestID),
+				slog.String("error", err.Error()),
+			)
+		} else if len(more) > len(questions) {
+			questions = more
+		}
+	}
+
 	s.log.Info("generate_questions_complete",
 		slog.String("request_id", requestID),
 		slog.Int("question_count", len(questions)),
+		slog.Bool("retried", retried),
 		slog.Duration("duration", time.Since(start)),
 	)
 
 	return questions, nil
 }
 
+// retryQuestions makes the corrective questions call and parses its response.
+func (s *Service) retryQuestions(ctx context.Context, messages []openai.Message) ([]Question, error) {
+	callCtx, cancel := withTimeout(ctx, s.questionsTimeout)
+	response, err := s.openaiClient.ChatCompletion(callCtx, messages)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+	return s.parseQuestionsResponse(response)
+}
+
 // GenerateOutputs generates kickoff prompt, steering files, hooks, and AGENTS.md.
 func (s *Service) GenerateOutputs(ctx context.Context, projectIdea string, answers []Answer, experienceLevel string, hookPreset string) ([]GeneratedFile, error) {
+	result, err := s.generateOutputs(ctx, projectIdea, answers, experienceLevel, hookPreset)
+	if err != nil {
+		return nil, err
+	}
+	return result.Files, nil
+}
+
+// generateOutputs generates output files and returns them with any validation
+// warnings and the number of attempts used. The result is not stored.
+func (s *Service) generateOutputs(ctx context.Context, projectIdea string, answers []Answer, experienceLevel string, hookPreset string) (*GenerationResult, error) {
+	experienceLevel = s.ResolveExperienceLevel(experienceLevel, projectIdea)
+	hookPreset = s.ResolveHookPreset(hookPreset)
 	requestID := logger.GetRequestID(ctx)
 	start := time.Now()
 
@@ -315,7 +526,7 @@ func (s *Service) GenerateOutputs(ctx context.Context, projectIdea string, answe
 		slog.Int("answer_count", len(answers)),
 	)
 
-	if err := ValidateProjectIdeaWithLimits(projectIdea, s.maxProjectIdeaLength); err != nil {
+	if err := s.ValidateProjectIdea(projectIdea); err != nil {
 		s.log.Warn("generate_outputs_validation_failed",
 			slog.String("request_id", requestID),
 			slog.String("error", err.Error()),
@@ -323,7 +534,7 @@ func (s *Service) GenerateOutputs(ctx context.Context, projectIdea string, answe
 		)
 		return nil, err
 	}
-	if err := ValidateAnswersWithLimits(answers, s.maxAnswerLength); err != nil {
+	if err := s.ValidateAnswers(answers); err != nil {
 		s.log.Warn("generate_outputs_validation_failed",
 			slog.String("request_id", requestID),
 			slog.String("error", err.Error()),
@@ -332,6 +543,11 @@ func (s *Service) GenerateOutputs(ctx context.Context, projectIdea string, answe
 		return nil, err
 	}
 
+	if s.openaiClient == nil {
+		s.log.Error("openai_client_unavailable", slog.String("request_id", requestID))
+		return nil, ErrAIUnavailable
+	}
+
 	// Acquire queue slot if queue is configured
 	if s.requestQueue != nil {
 		s.log.Debug("queue_acquire_start", slog.String("request_id", requestID))
@@ -346,30 +562,22 @@ func (s *Service) GenerateOutputs(ctx context.Context, projectIdea string, answe
 		s.log.Debug("queue_acquire_success", slog.String("request_id", requestID))
 	}
 
-	// Validate experience level and hook preset
-	if !prompts.IsValidExperienceLevel(experienceLevel) {
-		experienceLevel = prompts.ExperienceNovice
-	}
-	if !prompts.IsValidHookPreset(hookPreset) {
-		hookPreset = prompts.HookPresetDefault
-	}
-
-	// Convert answers to prompts.Answer type
-	promptAnswers := make([]prompts.Answer, len(answers))
-	for i, a := range answers {
-		promptAnswers[i] = prompts.Answer{
-			QuestionID: a.QuestionID,
-			Answer:     a.Answer,
-		}
+	messages, truncated, err := fitOutputsMessages(projectIdea, answers, experienceLevel, hookPreset, s.promptBudget())
+	if err != nil {
+		s.log.Warn("generate_outputs_prompt_too_large",
+			slog.String("request_id", requestID),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
 	}
-
-	// Use comprehensive system and user prompts
-	systemPrompt := prompts.GetOutputsSystemPrompt(experienceLevel, hookPreset)
-	userPrompt := prompts.GetOutputsUserPrompt(strings.TrimSpace(projectIdea), promptAnswers, experienceLevel, hookPreset)
-
-	messages := []openai.Message{
-		{Role: "system", Content: systemPrompt},
-		{Role: "user", Content: userPrompt},
+	messages = s.withHouseRules(messages)
+	if truncated > 0 {
+		s.log.Warn("generate_outputs_prompt_truncated",
+			slog.String("request_id", requestID),
+			slog.Int("truncated_answers", truncated),
+			slog.Int("estimated_tokens", estimateTokens(messages)),
+			slog.Int("max_prompt_tokens", s.maxPromptTokens),
+		)
 	}
 
 	var lastErr error
@@ -380,7 +588,9 @@ func (s *Service) GenerateOutputs(ctx context.Context, projectIdea string, answe
 			slog.Int("max_attempts", s.maxRetries+1),
 		)
 
-		response, err := s.openaiClient.ChatCompletion(ctx, messages)
+		callCtx, cancel := withTimeout(ctx, s.outputsTimeout)
+		response, err := s.openaiClient.ChatCompletion(callCtx, messages)
+		cancel()
 		if err != nil {
 			s.log.Error("generate_outputs_openai_failed",
 				slog.String("request_id", requestID),
@@ -390,7 +600,7 @@ func (s *Service) GenerateOutputs(ctx context.Context, projectIdea string, answe
 			return nil, fmt.Errorf("failed to generate outputs: %w", err)
 		}
 
-		files, err := parseOutputsResponse(response)
+		files, err := parseOutputsResponse(response, s.outputPathPrefixes)
 		if err != nil {
 			lastErr = err
 			s.log.Warn("generate_outputs_parse_failed",
@@ -409,9 +619,10 @@ func (s *Service) GenerateOutputs(ctx context.Context, projectIdea string, answe
 			return nil, FormatValidationError(err)
 		}
 
-		// Validate generated files
-		if err := ValidateGeneratedFiles(files); err != nil {
-			lastErr = fmt.Errorf("%w: %v", ErrInvalidResponse, err)
+		// Validate generated files; warnings are reported but do not fail the request
+		warnings, err := validateGeneratedFilesWithWarnings(files, s.noCodingPhrases)
+		if err != nil {
+			lastErr = fmt.Errorf("%w: %w", ErrInvalidResponse, err)
 			s.log.Warn("generate_outputs_validation_failed",
 				slog.String("request_id", requestID),
 				slog.Int("attempt", attempt+1),
@@ -432,11 +643,16 @@ func (s *Service) GenerateOutputs(ctx context.Context, projectIdea string, answe
 		s.log.Info("generate_outputs_complete",
 			slog.String("request_id", requestID),
 			slog.Int("file_count", len(files)),
+			slog.Int("warning_count", len(warnings)),
 			slog.Int("attempts_used", attempt+1),
 			slog.Duration("duration", time.Since(start)),
 		)
 
-		return files, nil
+		return &GenerationResult{
+			Files:    files,
+			Warnings: warnings,
+			Attempts: attempt + 1,
+		}, nil
 	}
 
 	// Should not reach here, but return last error if we do
@@ -445,28 +661,41 @@ func (s *Service) GenerateOutputs(ctx context.Context, projectIdea string, answe
 
 // GenerateAndStoreOutputs generates outputs and stores them in the database.
 // Returns the generated files and the generation ID if storage is configured.
-func (s *Service) GenerateAndStoreOutputs(ctx context.Context, projectIdea string, answers []Answer, experienceLevel string, hookPreset string) (*GenerationResult, error) {
+// Visibility controls whether the stored generation is listed in the gallery;
+// empty means public.
+func (s *Service) GenerateAndStoreOutputs(ctx context.Context, projectIdea string, answers []Answer, experienceLevel string, hookPreset string, visibility string) (*GenerationResult, error) {
 	requestID := logger.GetRequestID(ctx)
 
+	// Resolve defaults and an auto experience level so the stored generation
+	// has concrete values
+	experienceLevel = s.ResolveExperienceLevel(experienceLevel, projectIdea)
+	hookPreset = s.ResolveHookPreset(hookPreset)
+
 	// Generate the outputs
-	files, err := s.GenerateOutputs(ctx, projectIdea, answers, experienceLevel, hookPreset)
+	result, err := s.generateOutputs(ctx, projectIdea, answers, experienceLevel, hookPreset)
 	if err != nil {
 		return nil, err
 	}
 
-	result := &GenerationResult{
-		Files: files,
-	}
-
 	// Store in database if repository is configured
 	if s.repository != nil {
 		s.log.Debug("storage_attempt_start",
 			slog.String("request_id", requestID),
-			slog.Int("file_count", len(files)),
+			slog.Int("file_count", len(result.Files)),
 		)
 
+		// Stored content is shown publicly or replayed; keep pasted secrets and
+		// filtered words out of it. The user still gets the unfiltered files.
+		storedIdea, storedAnswers, storedFiles, ok := s.filterStored(ctx, projectIdea, answers, result.Files)
+		if !ok {
+			s.log.Warn("storage_skipped_secret",
+				slog.String("request_id", requestID),
+			)
+			return result, nil
+		}
+
 		// Convert files to JSON
-		filesJSON, err := json.Marshal(files)
+		filesJSON, err := json.Marshal(storedFiles)
 		if err != nil {
 			s.log.Error("storage_marshal_failed",
 				slog.String("request_id", requestID),
@@ -476,6 +705,32 @@ func (s *Service) GenerateAndStoreOutputs(ctx context.Context, projectIdea strin
 			return result, nil
 		}
 
+		// Keep the inputs so the generation can be replayed; never served publicly
+		if storedAnswers == nil {
+			storedAnswers = []Answer{}
+		}
+		answersJSON, err := json.Marshal(storedAnswers)
+		if err != nil {
+			s.log.Error("storage_marshal_failed",
+				slog.String("request_id", requestID),
+				slog.String("error", err.Error()),
+			)
+			return result, nil
+		}
+
+		// Link identical public generations to the stored one to keep the gallery clean
+		contentHash := hashGenerationContent(projectIdea, answers, experienceLevel, hookPreset)
+		if existing := s.findDuplicate(ctx, contentHash, visibility); existing != nil {
+			s.log.Info("storage_deduplicated",
+				slog.String("request_id", requestID),
+				slog.String("generation_id", existing.ID),
+			)
+			result.GenerationID = existing.ID
+			result.ShortCode = existing.ShortCode
+			result.Duplicate = true
+			return result, nil
+		}
+
 		// Get category based on project idea
 		s.log.Debug("category_lookup_start",
 			slog.String("request_id", requestID),
@@ -498,11 +753,14 @@ func (s *Service) GenerateAndStoreOutputs(ctx context.Context, projectIdea strin
 
 		// Create generation record
 		gen := &storage.Generation{
-			ProjectIdea:     strings.TrimSpace(projectIdea),
+			ProjectIdea:     strings.TrimSpace(storedIdea),
 			ExperienceLevel: experienceLevel,
 			HookPreset:      hookPreset,
 			Files:           filesJSON,
 			CategoryID:      categoryID,
+			Visibility:      visibility,
+			Answers:         answersJSON,
+			ContentHash:     contentHash,
 		}
 
 		if err := s.repository.CreateGeneration(ctx, gen); err != nil {
@@ -521,67 +779,183 @@ func (s *Service) GenerateAndStoreOutputs(ctx context.Context, projectIdea strin
 		)
 
 		result.GenerationID = gen.ID
+		result.ShortCode = gen.ShortCode
 	}
 
 	return result, nil
 }
 
+// GenerateAndStoreOutputsIdempotent behaves like GenerateAndStoreOutputs, but when
+// key is non-empty and an idempotency store is configured, a repeated key with the
+// same request from the same clientID returns the previously generated result
+// without calling the model. Reusing a key with a different request returns
+// ErrIdempotencyKeyReused.
+func (s *Service) GenerateAndStoreOutputsIdempotent(ctx context.Context, clientID, key string, projectIdea string, answers []Answer, experienceLevel string, hookPreset string, visibility string) (*GenerationResult, error) {
+	if key == "" || s.idempotency == nil {
+		return s.GenerateAndStoreOutputs(ctx, projectIdea, answers, experienceLevel, hookPreset, visibility)
+	}
+
+	requestID := logger.GetRequestID(ctx)
+	requestHash := hashOutputsRequest(projectIdea, answers, experienceLevel, hookPreset, visibility)
+	key = scopedIdempotencyKey(clientID, key)
+
+	cached, entry, err := s.idempotency.acquire(ctx, key, requestHash)
+	if err != nil {
+		s.log.Warn("idempotency_key_rejected",
+			slog.String("request_id", requestID),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	if cached != nil {
+		s.log.Info("idempotency_replay",
+			slog.String("request_id", requestID),
+			slog.String("generation_id", cached.GenerationID),
+		)
+		return cached, nil
+	}
+
+	result, err := s.GenerateAndStoreOutputs(ctx, projectIdea, answers, experienceLevel, hookPreset, visibility)
+	s.idempotency.complete(key, entry, result)
+	return result, err
+}
+
+// CachedOutputs returns the finished result clientID already generated with
+// key for the same request, or nil when there is none, without claiming the
+// key. Callers use it to answer retries before charging them a rate-limit token.
+func (s *Service) CachedOutputs(ctx context.Context, clientID, key string, projectIdea string, answers []Answer, experienceLevel string, hookPreset string, visibility string) (*GenerationResult, error) {
+	if key == "" || s.idempotency == nil {
+		return nil, nil
+	}
+
+	requestHash := hashOutputsRequest(projectIdea, answers, experienceLevel, hookPreset, visibility)
+	cached, err := s.idempotency.lookup(scopedIdempotencyKey(clientID, key), requestHash)
+	if err != nil {
+		s.log.Warn("idempotency_key_rejected",
+			slog.String("request_id", logger.GetRequestID(ctx)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	if cached != nil {
+		s.log.Info("idempotency_replay",
+			slog.String("request_id", logger.GetRequestID(ctx)),
+			slog.String("generation_id", cached.GenerationID),
+		)
+	}
+	return cached, nil
+}
+
+// buildQuestionsMessages composes the chat messages for question generation.
+// An auto experience level is inferred; unknown levels fall back to novice.
+func buildQuestionsMessages(projectIdea string, experienceLevel string) []openai.Message {
+	experienceLevel = prompts.ResolveExperienceLevel(experienceLevel, projectIdea)
+	if !prompts.IsValidExperienceLevel(experienceLevel) {
+		experienceLevel = prompts.ExperienceNovice
+	}
+
+	// Use experience-level-aware system prompt
+	systemPrompt := prompts.GetQuestionsSystemPrompt(experienceLevel)
+	userPrompt := prompts.GetQuestionsUserPrompt(strings.TrimSpace(projectIdea), experienceLevel)
+
+	return []openai.Message{
+		{Role: "system", Content: systemPrompt},
+		{Role: "user", Content: userPrompt},
+	}
+}
+
+// buildOutputsMessages composes the chat messages for output generation.
+// An auto experience level is inferred; unknown experience levels and hook
+// presets fall back to novice and default.
+func buildOutputsMessages(projectIdea string, answers []Answer, experienceLevel string, hookPreset string) []openai.Message {
+	experienceLevel = prompts.ResolveExperienceLevel(experienceLevel, projectIdea)
+	if !prompts.IsValidExperienceLevel(experienceLevel) {
+		experienceLevel = prompts.ExperienceNovice
+	}
+	if !prompts.IsValidHookPreset(hookPreset) {
+		hookPreset = prompts.HookPresetDefault
+	}
+
+	// Convert answers to prompts.Answer type
+	promptAnswers := make([]prompts.Answer, len(answers))
+	for i, a := range answers {
+		promptAnswers[i] = prompts.Answer{
+			QuestionID: a.QuestionID,
+			Answer:     a.Answer,
+		}
+	}
+
+	// Use comprehensive system and user prompts
+	systemPrompt := prompts.GetOutputsSystemPrompt(experienceLevel, hookPreset)
+	userPrompt := prompts.GetOutputsUserPrompt(strings.TrimSpace(projectIdea), promptAnswers, experienceLevel, hookPreset)
+
+	return []openai.Message{
+		{Role: "system", Content: systemPrompt},
+		{Role: "user", Content: userPrompt},
+	}
+}
+
 // buildRetryPrompt creates a prompt explaining the validation error for retry
 func buildRetryPrompt(err error) string {
 	return fmt.Sprintf(`The previous response had validation errors. Please fix the following issues and regenerate the complete JSON response:
 
 Error: %v
-
+%s
 Remember:
 - All steering files must have valid YAML frontmatter with 'inclusion' field
 - fileMatch mode requires 'fileMatchPattern' field
+- product.md, tech.md and structure.md must use 'inclusion: always'
 - All hook files must have valid JSON with required fields: name, description, version, enabled, when, then
 - when.type must be one of: fileEdited, fileCreated, fileDeleted, promptSubmit, agentStop, userTriggered
 - then.type must be 'askAgent' or 'runCommand'
 - runCommand can only be used with promptSubmit or agentStop triggers
 - File-based triggers (fileEdited, fileCreated, fileDeleted) require patterns array
+- Use fileCreated for new-file checks (e.g., reminding to add tests) and fileDeleted for cleanup of dangling references; both must use askAgent
 
-Please provide the corrected JSON response.`, err)
+Please provide the corrected JSON response.`, err, schemaRetryGuidance(err))
 }
 
-func (s *Service) parseQuestionsResponse(response string) ([]Question, error) {
-	// Try to extract JSON from response (handle potential markdown code blocks)
-	jsonStr := extractJSON(response)
-
-	var qr QuestionsResponse
-	if err := json.Unmarshal([]byte(jsonStr), &qr); err != nil {
-		return nil, fmt.Errorf("%w: failed to parse questions JSON: %v", ErrInvalidResponse, err)
-	}
-
-	if len(qr.Questions) == 0 {
-		return nil, ErrNoQuestions
-	}
+// buildQuestionsRetryPrompt asks the model for a fuller question set after it
+// returned too few.
+func buildQuestionsRetryPrompt(got, minQuestions, maxQuestions int) string {
+	return fmt.Sprintf(`The previous response only had %d questions. Please regenerate the complete JSON response with between %d and %d questions, keeping the same format.`, got, minQuestions, maxQuestions)
+}
 
-	// Validate question count using config values
-	if len(qr.Questions) < s.minQuestions || len(qr.Questions) > s.maxQuestions {
-		// Truncate or pad if needed, but still return what we have
-		if len(qr.Questions) > s.maxQuestions {
-			qr.Questions = qr.Questions[:s.maxQuestions]
-		}
+// schemaRetryGuidance lists schema violations by path so the model can fix each one.
+// It returns an empty string for errors that are not schema violations.
+func schemaRetryGuidance(err error) string {
+	var schemaErr *SchemaValidationError
+	if !errors.As(err, &schemaErr) {
+		return ""
 	}
 
-	// Validate each question has required fields
-	for i, q := range qr.Questions {
-		if q.Text == "" {
-			return nil, fmt.Errorf("%w: question %d has empty text", ErrInvalidResponse, i+1)
-		}
-		// Ensure IDs are set
-		if q.ID == 0 {
-			qr.Questions[i].ID = i + 1
-		}
+	var b strings.Builder
+	b.WriteString("\nSchema violations:\n")
+	for _, v := range schemaErr.Violations {
+		fmt.Fprintf(&b, "- %s %s\n", v.Path, v.Message)
 	}
+	b.WriteString("\nThe response must match this JSON Schema:\n")
+	b.WriteString(OutputsResponseSchema)
+	b.WriteString("\n")
+	return b.String()
+}
 
-	return qr.Questions, nil
+// parseQuestionsResponse parses a questions response, truncating it to the
+// service's maximum question count.
+func (s *Service) parseQuestionsResponse(response string) ([]Question, error) {
+	return parseQuestions(response, s.maxQuestions)
 }
 
 // parseQuestionsResponse is a package-level function for backward compatibility with tests.
 // It uses default config values.
 func parseQuestionsResponse(response string) ([]Question, error) {
+	return parseQuestions(response, defaultMaxQuestions)
+}
+
+// parseQuestions parses a questions response and keeps at most maxQuestions
+// of them. Too few questions are not an error here; GenerateQuestions asks
+// the model again instead.
+func parseQuestions(response string, maxQuestions int) ([]Question, error) {
 	// Try to extract JSON from response (handle potential markdown code blocks)
 	jsonStr := extractJSON(response)
 
@@ -594,12 +968,8 @@ func parseQuestionsResponse(response string) ([]Question, error) {
 		return nil, ErrNoQuestions
 	}
 
-	// Validate question count using default values
-	if len(qr.Questions) < defaultMinQuestions || len(qr.Questions) > defaultMaxQuestions {
-		// Truncate or pad if needed, but still return what we have
-		if len(qr.Questions) > defaultMaxQuestions {
-			qr.Questions = qr.Questions[:defaultMaxQuestions]
-		}
+	if len(qr.Questions) > maxQuestions {
+		qr.Questions = qr.Questions[:maxQuestions]
 	}
 
 	// Validate each question has required fields
@@ -616,10 +986,21 @@ func parseQuestionsResponse(response string) ([]Question, error) {
 	return qr.Questions, nil
 }
 
-func parseOutputsResponse(response string) ([]GeneratedFile, error) {
+// parseOutputsResponse parses and checks a generated outputs response. File
+// paths may also lie within extraPathPrefixes besides the built-in locations.
+func parseOutputsResponse(response string, extraPathPrefixes []string) ([]GeneratedFile, error) {
 	// Try to extract JSON from response (handle potential markdown code blocks)
 	jsonStr := extractJSON(response)
 
+	// Check the raw JSON against the shared schema first for precise, path-based errors
+	if err := ValidateOutputsSchema([]byte(jsonStr)); err != nil {
+		var schemaErr *SchemaValidationError
+		if errors.As(err, &schemaErr) {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidResponse, err)
+		}
+		return nil, fmt.Errorf("%w: failed to parse outputs JSON: %v", ErrInvalidResponse, err)
+	}
+
 	var or OutputsResponse
 	if err := json.Unmarshal([]byte(jsonStr), &or); err != nil {
 		return nil, fmt.Errorf("%w: failed to parse outputs JSON: %v", ErrInvalidResponse, err)
@@ -639,6 +1020,9 @@ func parseOutputsResponse(response string) ([]GeneratedFile, error) {
 		if f.Path == "" || f.Content == "" {
 			return nil, fmt.Errorf("%w: file has empty path or content", ErrInvalidResponse)
 		}
+		if err := ValidateOutputFilePath(f.Path, extraPathPrefixes); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidResponse, err)
+		}
 		switch f.Type {
 		case "kickoff":
 			hasKickoff = true
@@ -664,10 +1048,14 @@ func parseOutputsResponse(response string) ([]GeneratedFile, error) {
 		return nil, fmt.Errorf("%w: missing AGENTS.md file", ErrInvalidResponse)
 	}
 
+	SortGeneratedFiles(or.Files)
+
 	return or.Files, nil
 }
 
 // extractJSON attempts to extract JSON from a response that might contain markdown code blocks.
+// With JSON mode enabled the response is already bare JSON; this remains the
+// fallback for models that do not support it.
 func extractJSON(response string) string {
 	response = strings.TrimSpace(response)
 
@@ -1,11 +1,19 @@
 package generation
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"math/rand"
 	"reflect"
+	"strings"
 	"testing"
 	"testing/quick"
+	"time"
+
+	"better-kiro-prompts/internal/config"
+	"better-kiro-prompts/internal/openai"
 )
 
 // Feature: ai-driven-generation, Property 1: Question Plan Structure
@@ -221,7 +229,7 @@ func TestProperty2_GenerationResponseCompleteness(t *testing.T) {
 		}
 
 		// Parse the response
-		files, err := parseOutputsResponse(string(jsonBytes))
+		files, err := parseOutputsResponse(string(jsonBytes), nil)
 		if err != nil {
 			t.Logf("Parse error: %v", err)
 			return false
@@ -342,7 +350,7 @@ func TestProperty2_GenerationResponseCompleteness_RejectsInvalid(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			_, err := parseOutputsResponse(tc.response)
+			_, err := parseOutputsResponse(tc.response, nil)
 			if (err != nil) != tc.wantErr {
 				t.Errorf("parseOutputsResponse() error = %v, wantErr %v", err, tc.wantErr)
 			}
@@ -350,7 +358,424 @@ func TestProperty2_GenerationResponseCompleteness_RejectsInvalid(t *testing.T) {
 	}
 }
 
+func TestParseOutputsResponse_RejectsUnsafePaths(t *testing.T) {
+	response := func(agentsPath string) string {
+		resp := OutputsResponse{Files: []GeneratedFile{
+			{Path: "kickoff-prompt.md", Content: "# Kickoff", Type: "kickoff"},
+			{Path: ".kiro/steering/product.md", Content: "# Product", Type: "steering"},
+			{Path: ".kiro/hooks/format.kiro.hook", Content: "{}", Type: "hook"},
+			{Path: agentsPath, Content: "# Agents", Type: "agents"},
+		}}
+		data, err := json.Marshal(resp)
+		if err != nil {
+			t.Fatalf("failed to marshal response: %v", err)
+		}
+		return string(data)
+	}
+
+	for _, p := range []string{"../../etc/passwd", "/etc/passwd"} {
+		_, err := parseOutputsResponse(response(p), nil)
+		if !errors.Is(err, ErrUnsafeFilePath) || !errors.Is(err, ErrInvalidResponse) {
+			t.Errorf("path %q: error = %v, want ErrUnsafeFilePath wrapped in ErrInvalidResponse", p, err)
+		}
+	}
+
+	if _, err := parseOutputsResponse(response("AGENTS.md"), nil); err != nil {
+		t.Errorf("unexpected error for safe paths: %v", err)
+	}
+	if _, err := parseOutputsResponse(response("CLAUDE.md"), nil); !errors.Is(err, ErrUnsafeFilePath) {
+		t.Errorf("expected an unconfigured location to be rejected, got %v", err)
+	}
+	if _, err := parseOutputsResponse(response("CLAUDE.md"), []string{"CLAUDE.md"}); err != nil {
+		t.Errorf("unexpected error for a configured location: %v", err)
+	}
+}
+
 // Generate implements quick.Generator for OutputsResponse.
 func (OutputsResponse) Generate(rand *rand.Rand, size int) reflect.Value {
 	return reflect.ValueOf(generateValidOutputsResponse(rand))
 }
+
+// TestServiceValidation_UsesConfiguredLimits tests that limits from config replace the defaults.
+func TestServiceValidation_UsesConfiguredLimits(t *testing.T) {
+	cfg := config.GenerationConfig{MaxProjectIdeaLength: 100, MaxAnswerLength: 100}
+	svc := NewServiceWithConfig(nil, nil, nil, nil, cfg)
+
+	idea := strings.Repeat("a", 500)
+	answers := []Answer{{QuestionID: 1, Answer: strings.Repeat("b", 500)}}
+
+	// The defaults accept these inputs
+	if err := ValidateProjectIdea(idea); err != nil {
+		t.Fatalf("default ValidateProjectIdea() = %v, want nil", err)
+	}
+	if err := ValidateAnswers(answers); err != nil {
+		t.Fatalf("default ValidateAnswers() = %v, want nil", err)
+	}
+
+	if err := svc.ValidateProjectIdea(idea); !errors.Is(err, ErrProjectIdeaTooLong) {
+		t.Errorf("configured ValidateProjectIdea() = %v, want ErrProjectIdeaTooLong", err)
+	}
+	if err := svc.ValidateAnswers(answers); !errors.Is(err, ErrAnswerTooLong) {
+		t.Errorf("configured ValidateAnswers() = %v, want ErrAnswerTooLong", err)
+	}
+	if _, err := svc.PreviewOutputsPrompt(context.Background(), "A todo app", answers, "novice", "default"); !errors.Is(err, ErrAnswerTooLong) {
+		t.Errorf("PreviewOutputsPrompt() = %v, want ErrAnswerTooLong", err)
+	}
+}
+
+// TestNewServiceWithConfig_ZeroLimitsUseDefaults tests that unset limits fall back to the defaults.
+func TestNewServiceWithConfig_ZeroLimitsUseDefaults(t *testing.T) {
+	svc := NewServiceWithConfig(nil, nil, nil, nil, config.GenerationConfig{})
+
+	if err := svc.ValidateProjectIdea(strings.Repeat("a", defaultMaxProjectIdeaLength)); err != nil {
+		t.Errorf("ValidateProjectIdea() at default limit = %v, want nil", err)
+	}
+	if err := svc.ValidateProjectIdea(strings.Repeat("a", defaultMaxProjectIdeaLength+1)); !errors.Is(err, ErrProjectIdeaTooLong) {
+		t.Errorf("ValidateProjectIdea() over default limit = %v, want ErrProjectIdeaTooLong", err)
+	}
+	if err := svc.ValidateProjectIdea(strings.Repeat("a", defaultMinProjectIdeaLength-1)); !errors.Is(err, ErrProjectIdeaTooShort) {
+		t.Errorf("ValidateProjectIdea() under default minimum = %v, want ErrProjectIdeaTooShort", err)
+	}
+}
+
+// TestValidateProjectIdea_MinimumLength tests the configured minimum idea length.
+func TestValidateProjectIdea_MinimumLength(t *testing.T) {
+	svc := NewServiceWithConfig(nil, nil, nil, nil, config.GenerationConfig{MinProjectIdeaLength: 20})
+
+	tests := []struct {
+		name string
+		idea string
+		want error
+	}{
+		{"empty", "   ", ErrEmptyProjectIdea},
+		{"one word", "app", ErrProjectIdeaTooShort},
+		{"one under minimum", strings.Repeat("a", 19), ErrProjectIdeaTooShort},
+		{"padding does not count", "  " + strings.Repeat("a", 19) + "  ", ErrProjectIdeaTooShort},
+		{"at minimum", strings.Repeat("a", 20), nil},
+		{"just over minimum", strings.Repeat("a", 21), nil},
+		{"counts characters not bytes", strings.Repeat("é", 20), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := svc.ValidateProjectIdea(tt.idea)
+			if !errors.Is(err, tt.want) || (tt.want == nil && err != nil) {
+				t.Errorf("ValidateProjectIdea(%q) = %v, want %v", tt.idea, err, tt.want)
+			}
+		})
+	}
+
+	// The package-level check uses the default minimum
+	if err := ValidateProjectIdea("app"); !errors.Is(err, ErrProjectIdeaTooShort) {
+		t.Errorf("ValidateProjectIdea(app) = %v, want ErrProjectIdeaTooShort", err)
+	}
+	if err := ValidateProjectIdea("A todo app"); err != nil {
+		t.Errorf("ValidateProjectIdea at the default minimum = %v, want nil", err)
+	}
+}
+
+// TestValidateAnswers_QuestionMatching tests question ID, duplicate, and answer count validation.
+func TestValidateAnswers_QuestionMatching(t *testing.T) {
+	tooMany := make([]Answer, defaultMaxQuestions+1)
+	for i := range tooMany {
+		tooMany[i] = Answer{QuestionID: i + 1, Answer: "yes"}
+	}
+
+	tests := []struct {
+		name    string
+		answers []Answer
+		wantErr error
+	}{
+		{
+			name:    "no answers",
+			answers: nil,
+		},
+		{
+			name:    "distinct answers",
+			answers: []Answer{{QuestionID: 1, Answer: "Teams"}, {QuestionID: 2, Answer: "Web"}},
+		},
+		{
+			name:    "duplicate question ID",
+			answers: []Answer{{QuestionID: 1, Answer: "Teams"}, {QuestionID: 2, Answer: "Web"}, {QuestionID: 1, Answer: "Solo"}},
+			wantErr: ErrDuplicateAnswer,
+		},
+		{
+			name:    "zero question ID",
+			answers: []Answer{{QuestionID: 0, Answer: "Teams"}},
+			wantErr: ErrInvalidQuestionID,
+		},
+		{
+			name:    "negative question ID",
+			answers: []Answer{{QuestionID: -3, Answer: "Teams"}},
+			wantErr: ErrInvalidQuestionID,
+		},
+		{
+			name:    "more answers than questions",
+			answers: tooMany,
+			wantErr: ErrTooManyAnswers,
+		},
+		{
+			name:    "exactly max answers",
+			answers: tooMany[:defaultMaxQuestions],
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateAnswers(tt.answers)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("ValidateAnswers() = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ValidateAnswers() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerateOutputs_RetriesOnceAfterInvalidResponse(t *testing.T) {
+	client := openai.NewFakeClient("not json", validOutputsJSON(t))
+	svc := NewService(nil)
+	svc.openaiClient = client
+
+	files, err := svc.GenerateOutputs(context.Background(), "A task tracker", nil, "novice", "default")
+	if err != nil {
+		t.Fatalf("expected the retry to succeed, got: %v", err)
+	}
+	if len(files) == 0 {
+		t.Error("expected generated files from the retry")
+	}
+
+	calls := client.Calls()
+	if len(calls) != 2 {
+		t.Fatalf("expected 2 model calls, got %d", len(calls))
+	}
+	// The retry carries the rejected response and the correction prompt
+	retry := calls[1].Messages
+	if len(retry) != len(calls[0].Messages)+2 {
+		t.Fatalf("expected retry to add 2 messages, got %d", len(retry)-len(calls[0].Messages))
+	}
+	if retry[len(retry)-2].Role != "assistant" || retry[len(retry)-2].Content != "not json" {
+		t.Errorf("expected the invalid response echoed back, got %+v", retry[len(retry)-2])
+	}
+	if retry[len(retry)-1].Role != "user" {
+		t.Errorf("expected a user retry prompt, got role %q", retry[len(retry)-1].Role)
+	}
+}
+
+func TestGenerateAndStoreOutputs_ReportsAttempts(t *testing.T) {
+	valid := validOutputsJSON(t)
+	var want OutputsResponse
+	if err := json.Unmarshal([]byte(valid), &want); err != nil {
+		t.Fatalf("failed to unmarshal valid outputs: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		responses []string
+		attempts  int
+	}{
+		{"valid first time", []string{valid}, 1},
+		{"invalid then valid", []string{`{"files": []}`, valid}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewService(nil)
+			svc.openaiClient = openai.NewFakeClient(tt.responses...)
+
+			result, err := svc.GenerateAndStoreOutputs(context.Background(), "A task tracker", nil, "novice", "default", "")
+			if err != nil {
+				t.Fatalf("GenerateAndStoreOutputs: %v", err)
+			}
+			if result.Attempts != tt.attempts {
+				t.Errorf("Attempts = %d, want %d", result.Attempts, tt.attempts)
+			}
+			// Files may be reordered, so compare by path
+			got := make(map[string]string, len(result.Files))
+			for _, f := range result.Files {
+				got[f.Path] = f.Content
+			}
+			if len(got) != len(want.Files) {
+				t.Fatalf("got %d files, want %d", len(got), len(want.Files))
+			}
+			for _, f := range want.Files {
+				if content, ok := got[f.Path]; !ok || content != f.Content {
+					t.Errorf("expected %s from the valid response", f.Path)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateOutputs_ExhaustedRetriesKeepCause(t *testing.T) {
+	svc := NewService(nil)
+	svc.openaiClient = openai.NewFakeClient(`{"files": []}`)
+
+	_, err := svc.GenerateOutputs(context.Background(), "A task tracker", nil, "novice", "default")
+	if !errors.Is(err, ErrNoFiles) {
+		t.Fatalf("expected formatted error to wrap ErrNoFiles, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "did not generate any files") {
+		t.Errorf("expected the user-facing message, got %q", err.Error())
+	}
+}
+
+func TestFormatValidationError_WrapsCause(t *testing.T) {
+	cause := fmt.Errorf("%w: %w", ErrInvalidResponse, ErrMissingHookField)
+	err := FormatValidationError(cause)
+	if !errors.Is(err, ErrInvalidResponse) || !errors.Is(err, ErrMissingHookField) {
+		t.Errorf("expected both sentinels to be preserved, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "missing a required field") {
+		t.Errorf("expected the hook field message, got %q", err.Error())
+	}
+}
+
+// TestNewServiceWithConfig_MaxRetries tests that the configured retry count drives the retry loop.
+func TestNewServiceWithConfig_MaxRetries(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxRetries int
+		wantCalls  int
+	}{
+		{"disabled", 0, 1},
+		{"two retries", 2, 3},
+		{"capped", 100, maxRetriesLimit + 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewServiceWithConfig(nil, nil, nil, nil, config.GenerationConfig{MaxRetries: tt.maxRetries})
+			client := openai.NewFakeClient("not json")
+			svc.openaiClient = client
+
+			_, err := svc.GenerateOutputs(context.Background(), "A task tracker", nil, "novice", "default")
+			if !errors.Is(err, ErrInvalidResponse) {
+				t.Fatalf("expected ErrInvalidResponse after exhausting retries, got %v", err)
+			}
+			if got := client.CallCount(); got != tt.wantCalls {
+				t.Errorf("model calls = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+// questionsJSON returns a questions response with n questions.
+func questionsJSON(t *testing.T, n int) string {
+	t.Helper()
+	qr := QuestionsResponse{}
+	for i := 1; i <= n; i++ {
+		qr.Questions = append(qr.Questions, Question{ID: i, Text: fmt.Sprintf("Question %d?", i)})
+	}
+	b, err := json.Marshal(qr)
+	if err != nil {
+		t.Fatalf("failed to marshal questions: %v", err)
+	}
+	return string(b)
+}
+
+// TestGenerateQuestions_TooFewRetriesOnce tests that a response below the
+// configured minimum gets one corrective retry.
+func TestGenerateQuestions_TooFewRetriesOnce(t *testing.T) {
+	svc := NewServiceWithConfig(nil, nil, nil, nil, config.GenerationConfig{MinQuestions: 5, MaxQuestions: 10})
+	client := openai.NewFakeClient(questionsJSON(t, 3), questionsJSON(t, 6))
+	svc.openaiClient = client
+
+	questions, err := svc.GenerateQuestions(context.Background(), "A task tracker for small teams", "novice")
+	if err != nil {
+		t.Fatalf("GenerateQuestions: %v", err)
+	}
+	if len(questions) != 6 {
+		t.Errorf("got %d questions, want the 6 from the retry", len(questions))
+	}
+
+	calls := client.Calls()
+	if len(calls) != 2 {
+		t.Fatalf("expected 2 model calls, got %d", len(calls))
+	}
+	retry := calls[1].Messages
+	if last := retry[len(retry)-1].Content; !strings.Contains(last, "only had 3 questions") {
+		t.Errorf("retry prompt = %q, want it to mention the short count", last)
+	}
+}
+
+// TestGenerateQuestions_TooFewAcceptedAfterRetry tests that a retry that is
+// still short falls back to the original set rather than failing.
+func TestGenerateQuestions_TooFewAcceptedAfterRetry(t *testing.T) {
+	svc := NewService(nil)
+	client := openai.NewFakeClient(questionsJSON(t, 3), "not json")
+	svc.openaiClient = client
+
+	questions, err := svc.GenerateQuestions(context.Background(), "A task tracker for small teams", "novice")
+	if err != nil || len(questions) != 3 {
+		t.Fatalf("GenerateQuestions() = %d questions, %v; want the original 3", len(questions), err)
+	}
+	if got := client.CallCount(); got != 2 {
+		t.Errorf("model calls = %d, want 2", got)
+	}
+}
+
+// TestGenerateQuestions_TruncatedToConfiguredMax tests that extra questions
+// are dropped without a retry.
+func TestGenerateQuestions_TruncatedToConfiguredMax(t *testing.T) {
+	svc := NewServiceWithConfig(nil, nil, nil, nil, config.GenerationConfig{MinQuestions: 3, MaxQuestions: 8})
+	client := openai.NewFakeClient(questionsJSON(t, 11))
+	svc.openaiClient = client
+
+	questions, err := svc.GenerateQuestions(context.Background(), "A task tracker for small teams", "novice")
+	if err != nil {
+		t.Fatalf("GenerateQuestions: %v", err)
+	}
+	if len(questions) != 8 {
+		t.Errorf("got %d questions, want 8", len(questions))
+	}
+	if got := client.CallCount(); got != 1 {
+		t.Errorf("model calls = %d, want 1", got)
+	}
+}
+
+// TestSetTimeouts tests that each model call gets its own per-operation deadline.
+func TestSetTimeouts(t *testing.T) {
+	questions, err := json.Marshal(generateValidQuestionsResponse(rand.New(rand.NewSource(1))))
+	if err != nil {
+		t.Fatalf("failed to marshal questions: %v", err)
+	}
+
+	svc := NewService(nil)
+	svc.SetTimeouts(time.Minute, 5*time.Minute)
+	client := openai.NewFakeClient(string(questions), "not json", validOutputsJSON(t))
+	svc.openaiClient = client
+
+	start := time.Now()
+	if _, err := svc.GenerateQuestions(context.Background(), "A task tracker for small teams", "novice"); err != nil {
+		t.Fatalf("GenerateQuestions: %v", err)
+	}
+	if _, err := svc.GenerateOutputs(context.Background(), "A task tracker", nil, "novice", "default"); err != nil {
+		t.Fatalf("GenerateOutputs: %v", err)
+	}
+
+	calls := client.Calls()
+	if len(calls) != 3 {
+		t.Fatalf("expected 3 model calls, got %d", len(calls))
+	}
+	// Each retry attempt gets a fresh outputs deadline
+	want := []time.Duration{time.Minute, 5 * time.Minute, 5 * time.Minute}
+	for i, call := range calls {
+		if call.Deadline.IsZero() {
+			t.Fatalf("call %d had no deadline", i)
+		}
+		if got := call.Deadline.Sub(start); got < want[i] || got > want[i]+time.Second {
+			t.Errorf("call %d deadline in %v, want %v", i, got, want[i])
+		}
+	}
+
+	// Without configured timeouts the caller's context is passed through
+	svc.SetTimeouts(0, 0)
+	if _, err := svc.GenerateOutputs(context.Background(), "A task tracker", nil, "novice", "default"); err != nil {
+		t.Fatalf("GenerateOutputs: %v", err)
+	}
+	if calls := client.Calls(); !calls[len(calls)-1].Deadline.IsZero() {
+		t.Error("expected no deadline when timeouts are unset")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"path"
 	"regexp"
 	"strings"
 )
@@ -21,6 +22,8 @@ var (
 	ErrRunCommandRestriction      = errors.New("runCommand can only be used with promptSubmit or agentStop triggers")
 	ErrMissingNoCodingEnforcement = errors.New("kickoff prompt must contain 'no coding' enforcement phrase")
 	ErrMissingKickoffSection      = errors.New("kickoff prompt missing required section")
+	ErrCoreSteeringNotAlways      = errors.New("core steering file must use inclusion: always")
+	ErrUnsafeFilePath             = errors.New("unsafe file path")
 )
 
 // Valid inclusion modes for steering files
@@ -92,6 +95,16 @@ func ValidateSteeringFile(content string) error {
 	return nil
 }
 
+// steeringInclusion returns the inclusion mode in a steering file's
+// frontmatter, or "" when there is none.
+func steeringInclusion(content string) string {
+	matches := frontmatterRegex.FindStringSubmatch(content)
+	if len(matches) < 2 {
+		return ""
+	}
+	return extractYAMLField(matches[1], "inclusion")
+}
+
 // extractYAMLField extracts a simple string field from YAML content
 func extractYAMLField(yaml, field string) string {
 	// Simple regex-based extraction for single-line string values
@@ -216,17 +229,18 @@ var requiredKickoffSections = []string{
 
 // ValidateKickoffPrompt validates a kickoff prompt for completeness
 func ValidateKickoffPrompt(content string) error {
+	return ValidateKickoffPromptWithPhrases(content, nil)
+}
+
+// ValidateKickoffPromptWithPhrases validates a kickoff prompt like
+// ValidateKickoffPrompt, also accepting any of extraPhrases as "no coding"
+// enforcement, e.g. localized or rephrased equivalents. Phrases match
+// case-insensitively; blank ones are ignored.
+func ValidateKickoffPromptWithPhrases(content string, extraPhrases []string) error {
 	contentLower := strings.ToLower(content)
 
 	// Check for "no coding" enforcement phrase
-	hasNoCodingEnforcement := false
-	for _, phrase := range noCodingPhrases {
-		if strings.Contains(contentLower, phrase) {
-			hasNoCodingEnforcement = true
-			break
-		}
-	}
-	if !hasNoCodingEnforcement {
+	if !containsNoCodingPhrase(contentLower, noCodingPhrases) && !containsNoCodingPhrase(contentLower, extraPhrases) {
 		return ErrMissingNoCodingEnforcement
 	}
 
@@ -245,8 +259,80 @@ func ValidateKickoffPrompt(content string) error {
 	return nil
 }
 
+// containsNoCodingPhrase reports whether contentLower contains any of phrases.
+func containsNoCodingPhrase(contentLower string, phrases []string) bool {
+	for _, phrase := range phrases {
+		phrase = strings.ToLower(strings.TrimSpace(phrase))
+		if phrase != "" && strings.Contains(contentLower, phrase) {
+			return true
+		}
+	}
+	return false
+}
+
+// outputPathPrefixes lists where generated files may be written. An entry
+// ending in "/" allows any file below that directory; any other entry allows
+// exactly that file.
+var outputPathPrefixes = []string{
+	".kiro/",
+	"AGENTS.md",
+	"kickoff-prompt.md",
+}
+
+// ValidateOutputFilePath checks that a generated file path is safe to write
+// relative to a project root: relative, normalized, free of ".." segments and
+// within the built-in output locations or one of extraPrefixes.
+func ValidateOutputFilePath(p string, extraPrefixes []string) error {
+	switch {
+	case p == "":
+		return fmt.Errorf("%w: empty path", ErrUnsafeFilePath)
+	case strings.ContainsAny(p, "\\\x00"):
+		return fmt.Errorf("%w: %q contains a backslash or NUL byte", ErrUnsafeFilePath, p)
+	case path.IsAbs(p) || (len(p) >= 2 && p[1] == ':'):
+		return fmt.Errorf("%w: %q is absolute", ErrUnsafeFilePath, p)
+	}
+	for _, segment := range strings.Split(p, "/") {
+		if segment == ".." {
+			return fmt.Errorf("%w: %q contains a '..' segment", ErrUnsafeFilePath, p)
+		}
+	}
+	if path.Clean(p) != p {
+		return fmt.Errorf("%w: %q is not normalized", ErrUnsafeFilePath, p)
+	}
+
+	if !hasOutputPathPrefix(p, outputPathPrefixes) && !hasOutputPathPrefix(p, extraPrefixes) {
+		return fmt.Errorf("%w: %q is outside the allowed output locations", ErrUnsafeFilePath, p)
+	}
+	return nil
+}
+
+// hasOutputPathPrefix reports whether p is one of the files, or lies below one
+// of the directories, in prefixes.
+func hasOutputPathPrefix(p string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		prefix = strings.TrimSpace(prefix)
+		if prefix == "" {
+			continue
+		}
+		if strings.HasSuffix(prefix, "/") {
+			if len(p) > len(prefix) && strings.HasPrefix(p, prefix) {
+				return true
+			}
+		} else if p == prefix {
+			return true
+		}
+	}
+	return false
+}
+
 // ValidateGeneratedFiles validates all generated files
 func ValidateGeneratedFiles(files []GeneratedFile) error {
+	return validateGeneratedFiles(files, nil)
+}
+
+// validateGeneratedFiles validates all generated files, accepting
+// noCodingPhrases in kickoff prompts alongside the built-in phrases.
+func validateGeneratedFiles(files []GeneratedFile, noCodingPhrases []string) error {
 	if len(files) == 0 {
 		return ErrNoFiles
 	}
@@ -257,12 +343,19 @@ func ValidateGeneratedFiles(files []GeneratedFile) error {
 			if err := ValidateSteeringFile(f.Content); err != nil {
 				return fmt.Errorf("invalid steering file %s: %w", f.Path, err)
 			}
+			// product.md, tech.md and structure.md must always be loaded; other
+			// steering files may use any mode, so extra manual files are fine
+			if coreSteeringFileNames[path.Base(f.Path)] {
+				if inclusion := steeringInclusion(f.Content); inclusion != "always" {
+					return fmt.Errorf("invalid steering file %s: %w, got '%s'", f.Path, ErrCoreSteeringNotAlways, inclusion)
+				}
+			}
 		case "hook":
 			if err := ValidateHookFile(f.Content); err != nil {
 				return fmt.Errorf("invalid hook file %s: %w", f.Path, err)
 			}
 		case "kickoff":
-			if err := ValidateKickoffPrompt(f.Content); err != nil {
+			if err := ValidateKickoffPromptWithPhrases(f.Content, noCodingPhrases); err != nil {
 				return fmt.Errorf("invalid kickoff file %s: %w", f.Path, err)
 			}
 		}
@@ -270,6 +363,95 @@ func ValidateGeneratedFiles(files []GeneratedFile) error {
 	return nil
 }
 
+// minHookDescriptionWords is the fewest words a hook description should have to be useful
+const minHookDescriptionWords = 3
+
+// ValidationWarning describes a non-fatal quality issue in a generated file
+type ValidationWarning struct {
+	FilePath string `json:"filePath"`
+	Message  string `json:"message"`
+}
+
+// ValidateGeneratedFilesWithWarnings validates all generated files like ValidateGeneratedFiles
+// and additionally reports quality issues that should be surfaced without failing the request.
+// Warnings are only returned when there is no hard error.
+func ValidateGeneratedFilesWithWarnings(files []GeneratedFile) ([]ValidationWarning, error) {
+	return validateGeneratedFilesWithWarnings(files, nil)
+}
+
+// validateGeneratedFilesWithWarnings is ValidateGeneratedFilesWithWarnings
+// with extra "no coding" phrases, as in validateGeneratedFiles.
+func validateGeneratedFilesWithWarnings(files []GeneratedFile, noCodingPhrases []string) ([]ValidationWarning, error) {
+	if err := validateGeneratedFiles(files, noCodingPhrases); err != nil {
+		return nil, err
+	}
+
+	var warnings []ValidationWarning
+	hookNames := make(map[string]string)
+
+	for _, f := range files {
+		switch f.Type {
+		case "steering":
+			if !hasContentBeyondHeadings(frontmatterRegex.ReplaceAllString(f.Content, "")) {
+				warnings = append(warnings, ValidationWarning{FilePath: f.Path, Message: "steering file has no content beyond headings"})
+			}
+		case "hook":
+			var hook HookFile
+			if err := json.Unmarshal([]byte(f.Content), &hook); err != nil {
+				continue
+			}
+			if len(strings.Fields(hook.Description)) < minHookDescriptionWords {
+				warnings = append(warnings, ValidationWarning{FilePath: f.Path, Message: fmt.Sprintf("hook description %q is too short to explain what the hook does", hook.Description)})
+			}
+			if first, exists := hookNames[hook.Name]; exists {
+				warnings = append(warnings, ValidationWarning{FilePath: f.Path, Message: fmt.Sprintf("hook name %q is already used by %s", hook.Name, first)})
+			} else {
+				hookNames[hook.Name] = f.Path
+			}
+		case "kickoff":
+			if !hasBoundaryExamples(f.Content) {
+				warnings = append(warnings, ValidationWarning{FilePath: f.Path, Message: "boundary examples section has no examples"})
+			}
+		}
+	}
+
+	return warnings, nil
+}
+
+// hasContentBeyondHeadings reports whether markdown content has any non-heading text
+func hasContentBeyondHeadings(content string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			return true
+		}
+	}
+	return false
+}
+
+// hasBoundaryExamples reports whether the kickoff's boundary examples section contains a list item
+func hasBoundaryExamples(content string) bool {
+	idx := strings.Index(strings.ToLower(content), "boundary examples")
+	if idx == -1 {
+		return false
+	}
+
+	lines := strings.Split(content[idx:], "\n")
+	for _, line := range lines[1:] {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "#") {
+			break
+		}
+		if strings.HasPrefix(line, "- ") || strings.HasPrefix(line, "* ") || listItemRegex.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// listItemRegex matches a numbered markdown list item such as "1. "
+var listItemRegex = regexp.MustCompile(`^\d+\.\s`)
+
 // ValidationErrorDetails provides structured information about validation failures
 type ValidationErrorDetails struct {
 	FileType    string `json:"fileType,omitempty"`
@@ -282,7 +464,8 @@ type ValidationErrorDetails struct {
 	UserMessage string `json:"userMessage"`
 }
 
-// FormatValidationError converts a validation error into a user-friendly error with details
+// FormatValidationError converts a validation error into a user-friendly error with details.
+// The original error is wrapped, so errors.Is and errors.As still see the cause.
 func FormatValidationError(err error) error {
 	if err == nil {
 		return nil
@@ -296,6 +479,13 @@ func FormatValidationError(err error) error {
 	errStr := err.Error()
 
 	switch {
+	case errors.Is(err, ErrCoreSteeringNotAlways):
+		details.FileType = "steering"
+		details.Field = "inclusion"
+		details.Expected = "inclusion: always for product.md, tech.md and structure.md"
+		details.Suggestion = "Set 'inclusion: always' on the core steering files; use manual or fileMatch only for additional files"
+		details.UserMessage = "A core steering file (product.md, tech.md or structure.md) is not always included."
+
 	case errors.Is(err, ErrInvalidFrontmatter) || strings.Contains(errStr, "frontmatter"):
 		details.FileType = "steering"
 		details.Field = "frontmatter"
@@ -357,9 +547,23 @@ func FormatValidationError(err error) error {
 		details.Suggestion = "Change then.type to 'askAgent' or change when.type to 'promptSubmit' or 'agentStop'"
 		details.UserMessage = "A hook file uses 'runCommand' with an incompatible trigger. runCommand can only be used with promptSubmit or agentStop triggers."
 
+	case errors.Is(err, ErrUnsafeFilePath):
+		details.Field = "path"
+		details.Expected = "a relative path under .kiro/, or AGENTS.md or kickoff-prompt.md"
+		details.Suggestion = "Use relative paths without '..' segments inside the allowed locations"
+		details.UserMessage = "A generated file has an unsafe path."
+
 	case errors.Is(err, ErrNoFiles):
 		details.UserMessage = "The AI did not generate any files. Please try again."
 
+	case errors.Is(err, ErrSchemaViolation):
+		var schemaErr *SchemaValidationError
+		if errors.As(err, &schemaErr) && len(schemaErr.Violations) > 0 {
+			details.Field = schemaErr.Violations[0].Path
+		}
+		details.Expected = "JSON matching the outputs response schema"
+		details.UserMessage = "The AI response did not match the expected file format."
+
 	case strings.Contains(errStr, "missing kickoff"):
 		details.FileType = "kickoff"
 		details.UserMessage = "The AI response is missing the required kickoff prompt file."
@@ -389,5 +593,5 @@ func FormatValidationError(err error) error {
 		msg = fmt.Sprintf("%s Suggestion: %s", msg, details.Suggestion)
 	}
 
-	return fmt.Errorf("%s [raw: %s]", msg, details.RawError)
+	return fmt.Errorf("%s [raw: %w]", msg, err)
 }
@@ -3,6 +3,7 @@ package generation
 import (
 	"better-kiro-prompts/internal/prompts"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"regexp"
 	"strings"
@@ -474,27 +475,61 @@ inclusion: always
 // Feature: phase4-production, Property 2: Core Steering Files Validity
 // **Validates: Requirements 4.1, 4.2, 4.3**
 func TestProperty2_CoreSteeringFilesInclusionAlways(t *testing.T) {
-	// Test that core steering files with wrong inclusion mode fail validation
-	// when we check for the specific requirement
+	// Core files are valid steering files in any mode, but the output must
+	// always include them
+	invalidFrontmatter := map[string]string{
+		"manual":    "---\ninclusion: manual\n---\n\n# Product",
+		"fileMatch": "---\ninclusion: fileMatch\nfileMatchPattern: \"**/*.go\"\n---\n\n# Product",
+	}
+
+	for _, name := range []string{"product.md", "tech.md", "structure.md"} {
+		for mode, content := range invalidFrontmatter {
+			t.Run(name+"_"+mode, func(t *testing.T) {
+				if err := ValidateSteeringFile(content); err != nil {
+					t.Fatalf("file should be a valid steering file on its own: %v", err)
+				}
 
-	invalidModes := []string{"fileMatch", "manual"}
+				files := []GeneratedFile{
+					{Path: "kickoff-prompt.md", Content: minimalValidKickoff(), Type: "kickoff"},
+					{Path: ".kiro/steering/" + name, Content: content, Type: "steering"},
+				}
+				err := ValidateGeneratedFiles(files)
+				if !errors.Is(err, ErrCoreSteeringNotAlways) {
+					t.Errorf("expected ErrCoreSteeringNotAlways, got %v", err)
+				}
+			})
+		}
+	}
+}
 
-	for _, mode := range invalidModes {
-		t.Run("invalid_mode_"+mode, func(t *testing.T) {
-			content := "---\ninclusion: " + mode + "\n---\n\n# Product"
+// TestValidateGeneratedFiles_ExtraManualSteeringFile tests that additional
+// steering files may use manual inclusion alongside always-included core files.
+func TestValidateGeneratedFiles_ExtraManualSteeringFile(t *testing.T) {
+	files := []GeneratedFile{
+		{Path: "kickoff-prompt.md", Content: minimalValidKickoff(), Type: "kickoff"},
+		{Path: ".kiro/steering/product.md", Content: "---\ninclusion: always\n---\n\n# Product", Type: "steering"},
+		{Path: ".kiro/steering/tech.md", Content: "---\ninclusion: always\n---\n\n# Tech", Type: "steering"},
+		{Path: ".kiro/steering/structure.md", Content: "---\ninclusion: always\n---\n\n# Structure", Type: "steering"},
+		{Path: ".kiro/steering/release-checklist.md", Content: "---\ninclusion: manual\n---\n\n# Release Checklist", Type: "steering"},
+	}
 
-			// The file itself is valid YAML, but for core files we expect 'always'
-			err := ValidateSteeringFile(content)
+	if err := ValidateGeneratedFiles(files); err != nil {
+		t.Errorf("extra manual steering file should pass: %v", err)
+	}
+}
 
-			// Basic validation passes (it's valid frontmatter)
-			// But the semantic check for core files would fail
-			if mode == "fileMatch" {
-				// fileMatch without pattern should fail
-				if err == nil {
-					t.Errorf("fileMatch mode without pattern should fail validation")
-				}
-			}
-		})
+// TestFormatValidationError_CoreSteeringNotAlways tests the user-facing details for a core file that is not always included.
+func TestFormatValidationError_CoreSteeringNotAlways(t *testing.T) {
+	err := ValidateGeneratedFiles([]GeneratedFile{
+		{Path: ".kiro/steering/tech.md", Content: "---\ninclusion: manual\n---\n\n# Tech", Type: "steering"},
+	})
+
+	formatted := FormatValidationError(err)
+	if !errors.Is(formatted, ErrCoreSteeringNotAlways) {
+		t.Fatalf("formatted error should wrap ErrCoreSteeringNotAlways: %v", formatted)
+	}
+	if !strings.Contains(formatted.Error(), "core steering file") {
+		t.Errorf("formatted error = %q, want the core steering message", formatted.Error())
 	}
 }
 
@@ -2451,3 +2486,209 @@ func TestProperty6_OrderingGuidanceCompleteness(t *testing.T) {
 		})
 	}
 }
+
+// hookExampleFromPrompts extracts the JSON body of a named hook example from prompts.FileLifecycleHookExamples.
+func hookExampleFromPrompts(t *testing.T, filename string) string {
+	t.Helper()
+	marker := "#### " + filename + "\n```json\n"
+	start := strings.Index(prompts.FileLifecycleHookExamples, marker)
+	if start == -1 {
+		t.Fatalf("hook example %s not found in prompts.FileLifecycleHookExamples", filename)
+	}
+	body := prompts.FileLifecycleHookExamples[start+len(marker):]
+	end := strings.Index(body, "```")
+	if end == -1 {
+		t.Fatalf("hook example %s is not terminated", filename)
+	}
+	return body[:end]
+}
+
+// TestStrictPresetFileLifecycleHooksAreValid tests that the fileCreated and fileDeleted
+// hooks offered by the strict preset pass validation as part of a full generation.
+func TestStrictPresetFileLifecycleHooksAreValid(t *testing.T) {
+	createdHook := hookExampleFromPrompts(t, "test-on-create.kiro.hook")
+	deletedHook := hookExampleFromPrompts(t, "cleanup-on-delete.kiro.hook")
+
+	var parsed HookFile
+	if err := json.Unmarshal([]byte(createdHook), &parsed); err != nil {
+		t.Fatalf("failed to parse test-on-create hook: %v", err)
+	}
+	if parsed.When.Type != "fileCreated" {
+		t.Errorf("test-on-create when.type = %q, want fileCreated", parsed.When.Type)
+	}
+
+	files := []GeneratedFile{
+		{Path: "kickoff-prompt.md", Content: minimalValidKickoff(), Type: "kickoff"},
+		{Path: ".kiro/steering/product.md", Content: "---\ninclusion: always\n---\n\n# Product", Type: "steering"},
+		{Path: ".kiro/hooks/dep-scan.kiro.hook", Content: buildValidHook("agentStop", "askAgent"), Type: "hook"},
+		{Path: ".kiro/hooks/test-on-create.kiro.hook", Content: createdHook, Type: "hook"},
+		{Path: ".kiro/hooks/cleanup-on-delete.kiro.hook", Content: deletedHook, Type: "hook"},
+		{Path: "AGENTS.md", Content: "# Agent Guidelines", Type: "agents"},
+	}
+
+	if err := ValidateGeneratedFiles(files); err != nil {
+		t.Errorf("strict preset output with file lifecycle hooks should be valid: %v", err)
+	}
+
+	if !strings.Contains(buildRetryPrompt(ErrInvalidWhenType), "fileCreated") {
+		t.Error("retry prompt should mention fileCreated guidance")
+	}
+}
+
+// TestValidateGeneratedFilesWithWarnings_ThinHook tests that a valid but thin hook
+// produces a warning rather than a hard error.
+func TestValidateGeneratedFilesWithWarnings_ThinHook(t *testing.T) {
+	files := []GeneratedFile{
+		{Path: "kickoff-prompt.md", Content: minimalValidKickoff(), Type: "kickoff"},
+		{Path: ".kiro/steering/product.md", Content: "---\ninclusion: always\n---\n\n# Product\n\nA task tracker for small teams.", Type: "steering"},
+		{Path: ".kiro/hooks/lint.kiro.hook", Content: buildValidHookWithParams("agentStop", "runCommand", "Lint", "Lint", "1.0.0"), Type: "hook"},
+		{Path: "AGENTS.md", Content: "# Agent Guidelines", Type: "agents"},
+	}
+
+	warnings, err := ValidateGeneratedFilesWithWarnings(files)
+	if err != nil {
+		t.Fatalf("thin hook should not be a hard error: %v", err)
+	}
+	if len(warnings) != 1 {
+		t.Fatalf("expected 1 warning, got %d: %+v", len(warnings), warnings)
+	}
+	if warnings[0].FilePath != ".kiro/hooks/lint.kiro.hook" {
+		t.Errorf("warning FilePath = %q, want the hook path", warnings[0].FilePath)
+	}
+	if !strings.Contains(warnings[0].Message, "too short") {
+		t.Errorf("warning Message = %q, want a short description warning", warnings[0].Message)
+	}
+}
+
+// TestValidateGeneratedFilesWithWarnings tests each warning condition and that hard errors still fail.
+func TestValidateGeneratedFilesWithWarnings(t *testing.T) {
+	kickoff := GeneratedFile{Path: "kickoff-prompt.md", Content: minimalValidKickoff(), Type: "kickoff"}
+	steering := GeneratedFile{Path: ".kiro/steering/tech.md", Content: "---\ninclusion: always\n---\n\n# Tech\n\nGo and PostgreSQL.", Type: "steering"}
+	hook := GeneratedFile{Path: ".kiro/hooks/a.kiro.hook", Content: buildValidHookWithParams("agentStop", "askAgent", "Review", "Review changes before stopping", "1.0.0"), Type: "hook"}
+
+	tests := []struct {
+		name         string
+		files        []GeneratedFile
+		wantErr      bool
+		wantWarnings []string
+	}{
+		{
+			name:  "clean output",
+			files: []GeneratedFile{kickoff, steering, hook},
+		},
+		{
+			name: "heading-only steering file",
+			files: []GeneratedFile{kickoff, hook,
+				{Path: ".kiro/steering/product.md", Content: "---\ninclusion: always\n---\n\n# Product\n\n## Goals", Type: "steering"},
+			},
+			wantWarnings: []string{"no content beyond headings"},
+		},
+		{
+			name: "duplicate hook names",
+			files: []GeneratedFile{kickoff, steering, hook,
+				{Path: ".kiro/hooks/b.kiro.hook", Content: buildValidHookWithParams("promptSubmit", "askAgent", "Review", "Review the prompt first", "1.0.0"), Type: "hook"},
+			},
+			wantWarnings: []string{"already used by .kiro/hooks/a.kiro.hook"},
+		},
+		{
+			name: "empty boundary examples",
+			files: []GeneratedFile{steering, hook,
+				{Path: "kickoff-prompt.md", Content: strings.Replace(minimalValidKickoff(), "- Admin CAN delete users\n", "TBD\n", 1), Type: "kickoff"},
+			},
+			wantWarnings: []string{"boundary examples section has no examples"},
+		},
+		{
+			name: "hard error takes precedence",
+			files: []GeneratedFile{kickoff, steering,
+				{Path: ".kiro/hooks/bad.kiro.hook", Content: buildValidHookWithParams("fileEdited", "runCommand", "X", "X", "1.0.0"), Type: "hook"},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings, err := ValidateGeneratedFilesWithWarnings(tt.files)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(warnings) != len(tt.wantWarnings) {
+				t.Fatalf("got %d warnings %+v, want %d", len(warnings), warnings, len(tt.wantWarnings))
+			}
+			for i, want := range tt.wantWarnings {
+				if !strings.Contains(warnings[i].Message, want) {
+					t.Errorf("warning %d = %q, want it to contain %q", i, warnings[i].Message, want)
+				}
+			}
+		})
+	}
+}
+
+// localizedKickoff returns a valid kickoff whose "no coding" enforcement is
+// in French, which none of the built-in phrases match.
+func localizedKickoff() string {
+	return strings.Replace(minimalValidKickoff(),
+		"Do not write any code until all questions below are answered.",
+		"N'écrivez pas de code avant d'avoir répondu à toutes les questions.", 1)
+}
+
+func TestValidateKickoffPromptWithPhrases_CustomPhrase(t *testing.T) {
+	kickoff := localizedKickoff()
+	if err := ValidateKickoffPrompt(kickoff); !errors.Is(err, ErrMissingNoCodingEnforcement) {
+		t.Fatalf("expected ErrMissingNoCodingEnforcement without a custom phrase, got %v", err)
+	}
+
+	// Configured phrases match case-insensitively and extend the built-ins
+	phrases := []string{"  ", "N'ÉCRIVEZ PAS DE CODE"}
+	if err := ValidateKickoffPromptWithPhrases(kickoff, phrases); err != nil {
+		t.Errorf("expected the configured phrase to pass, got %v", err)
+	}
+	if err := ValidateKickoffPromptWithPhrases(minimalValidKickoff(), phrases); err != nil {
+		t.Errorf("expected the built-in phrases to still pass, got %v", err)
+	}
+
+	// Genuinely missing enforcement still fails
+	missing := strings.Replace(kickoff, "N'écrivez pas de code avant", "Commencez", 1)
+	if err := ValidateKickoffPromptWithPhrases(missing, phrases); !errors.Is(err, ErrMissingNoCodingEnforcement) {
+		t.Errorf("expected ErrMissingNoCodingEnforcement, got %v", err)
+	}
+}
+
+func TestValidateOutputFilePath(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		extra   []string
+		wantErr bool
+	}{
+		{"steering file", ".kiro/steering/tech.md", nil, false},
+		{"hook file", ".kiro/hooks/lint.kiro.hook", nil, false},
+		{"agents file", "AGENTS.md", nil, false},
+		{"kickoff file", "kickoff-prompt.md", nil, false},
+		{"traversal", "../../etc/passwd", nil, true},
+		{"traversal inside allowed prefix", ".kiro/../../etc/passwd", nil, true},
+		{"absolute path", "/etc/passwd", nil, true},
+		{"windows absolute path", `C:\Windows\system32`, nil, true},
+		{"backslash separators", `.kiro\steering\tech.md`, nil, true},
+		{"not normalized", "./.kiro/steering/tech.md", nil, true},
+		{"double slash", ".kiro//steering/tech.md", nil, true},
+		{"bare allowed directory", ".kiro/", nil, true},
+		{"outside allowed locations", "main.go", nil, true},
+		{"agents file in a subdirectory", "docs/AGENTS.md", nil, true},
+		{"configured directory", ".github/copilot-instructions.md", []string{".github/"}, false},
+		{"configured file", "CLAUDE.md", []string{"CLAUDE.md"}, false},
+		{"configured prefix does not allow traversal", ".github/../../etc/passwd", []string{".github/"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateOutputFilePath(tt.path, tt.extra)
+			if tt.wantErr && !errors.Is(err, ErrUnsafeFilePath) {
+				t.Errorf("ValidateOutputFilePath(%q) error = %v, want ErrUnsafeFilePath", tt.path, err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("ValidateOutputFilePath(%q) unexpected error: %v", tt.path, err)
+			}
+		})
+	}
+}
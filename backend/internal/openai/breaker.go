@@ -0,0 +1,107 @@
+package openai
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// Circuit breaker defaults
+const (
+	defaultBreakerThreshold = 5
+	defaultBreakerCooldown  = 30 * time.Second
+)
+
+// ErrCircuitOpen is returned without contacting OpenAI while the circuit
+// breaker is open after repeated outages.
+var ErrCircuitOpen = errors.New("AI temporarily unavailable")
+
+// CircuitOpenError is the ErrCircuitOpen a call returns, with how long until
+// the breaker lets a probe through.
+type CircuitOpenError struct {
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string { return ErrCircuitOpen.Error() }
+
+// Is makes errors.Is(err, ErrCircuitOpen) match.
+func (e *CircuitOpenError) Is(target error) bool { return target == ErrCircuitOpen }
+
+// breaker is a consecutive-failure circuit breaker. After threshold outages
+// in a row it opens and fails calls immediately for cooldown, then lets a
+// single probe through: a successful probe closes it, a failed one reopens
+// it for another cooldown.
+type breaker struct {
+	threshold int // zero disables the breaker
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time // zero while closed
+	probing  bool
+}
+
+// newBreaker returns a closed breaker. A threshold of zero or less disables it.
+func newBreaker(threshold int, cooldown time.Duration) *breaker {
+	return &breaker{threshold: threshold, cooldown: cooldown, now: time.Now}
+}
+
+// allow reports whether a call may proceed. It returns a *CircuitOpenError
+// while the breaker is open or another caller's probe is in flight.
+func (b *breaker) allow() error {
+	if b == nil || b.threshold <= 0 {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.openedAt.IsZero() {
+		return nil
+	}
+	if remaining := b.cooldown - b.now().Sub(b.openedAt); b.probing || remaining > 0 {
+		return &CircuitOpenError{RetryAfter: max(remaining, 0)}
+	}
+	b.probing = true
+	return nil
+}
+
+// success records a call that reached OpenAI and closes the breaker.
+func (b *breaker) success() {
+	if b == nil || b.threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	b.openedAt = time.Time{}
+	b.probing = false
+}
+
+// failure records an outage and reports whether it opened the breaker.
+func (b *breaker) failure() bool {
+	if b == nil || b.threshold <= 0 {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.probing || (b.openedAt.IsZero() && b.failures >= b.threshold) {
+		b.openedAt = b.now()
+		b.probing = false
+		return true
+	}
+	return false
+}
+
+// release ends a probe that neither succeeded nor failed, such as one the
+// caller cancelled, so the next call can probe instead.
+func (b *breaker) release() {
+	if b == nil || b.threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
@@ -0,0 +1,157 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newBreakerTestClient returns a client against server with a breaker whose
+// clock the test controls.
+func newBreakerTestClient(t *testing.T, server *httptest.Server, threshold int, now *time.Time) *Client {
+	t.Helper()
+	client, err := NewClientWithConfig(ClientConfig{
+		APIKey:           "test-key",
+		BaseURL:          server.URL,
+		BreakerThreshold: threshold,
+		BreakerCooldown:  time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("NewClientWithConfig: %v", err)
+	}
+	client.breaker.now = func() time.Time { return *now }
+	return client
+}
+
+func TestBreaker_OpensAfterThresholdAndRecovers(t *testing.T) {
+	var hits atomic.Int32
+	var healthy atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte(`{"output_text":"ok"}`))
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	client := newBreakerTestClient(t, server, 3, &now)
+	messages := []Message{{Role: "user", Content: "hello"}}
+
+	for i := 0; i < 3; i++ {
+		_, err := client.ChatCompletion(context.Background(), messages)
+		if !errors.Is(err, ErrRequestFailed) {
+			t.Fatalf("call %d: expected ErrRequestFailed, got %v", i+1, err)
+		}
+	}
+
+	// Open: calls fail fast without reaching the server
+	for i := 0; i < 5; i++ {
+		if _, err := client.ChatCompletion(context.Background(), messages); !errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("expected ErrCircuitOpen while open, got %v", err)
+		}
+	}
+	if got := hits.Load(); got != 3 {
+		t.Fatalf("expected 3 requests to reach the server, got %d", got)
+	}
+
+	// After the cooldown a successful probe closes the breaker
+	healthy.Store(true)
+	now = now.Add(time.Minute)
+	if _, err := client.ChatCompletion(context.Background(), messages); err != nil {
+		t.Fatalf("probe: %v", err)
+	}
+	if _, err := client.ChatCompletion(context.Background(), messages); err != nil {
+		t.Fatalf("call after recovery: %v", err)
+	}
+	if got := hits.Load(); got != 5 {
+		t.Errorf("expected 5 requests to reach the server, got %d", got)
+	}
+}
+
+func TestBreaker_FailedProbeReopens(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	client := newBreakerTestClient(t, server, 2, &now)
+	messages := []Message{{Role: "user", Content: "hello"}}
+
+	for i := 0; i < 2; i++ {
+		_, _ = client.ChatCompletion(context.Background(), messages)
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := client.ChatCompletion(context.Background(), messages); !errors.Is(err, ErrRequestFailed) {
+		t.Fatalf("probe: expected ErrRequestFailed, got %v", err)
+	}
+	if _, err := client.ChatCompletion(context.Background(), messages); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen after failed probe, got %v", err)
+	}
+	if got := hits.Load(); got != 3 {
+		t.Errorf("expected 3 requests to reach the server, got %d", got)
+	}
+}
+
+func TestBreaker_ClientErrorsDoNotTrip(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	client := newBreakerTestClient(t, server, 2, &now)
+	messages := []Message{{Role: "user", Content: "hello"}}
+
+	for i := 0; i < 5; i++ {
+		if _, err := client.ChatCompletion(context.Background(), messages); errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("call %d: breaker opened on a 4xx response", i+1)
+		}
+	}
+	if got := hits.Load(); got != 5 {
+		t.Errorf("expected 5 requests to reach the server, got %d", got)
+	}
+}
+
+func TestBreaker_NegativeThresholdDisables(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	client := newBreakerTestClient(t, server, -1, &now)
+	for i := 0; i < 10; i++ {
+		if _, err := client.ChatCompletion(context.Background(), []Message{{Role: "user", Content: "hi"}}); errors.Is(err, ErrCircuitOpen) {
+			t.Fatal("disabled breaker returned ErrCircuitOpen")
+		}
+	}
+}
+
+func TestBreaker_OpenErrorReportsRemainingCooldown(t *testing.T) {
+	now := time.Now()
+	b := newBreaker(1, 2*time.Minute)
+	b.now = func() time.Time { return now }
+	b.failure()
+
+	now = now.Add(45 * time.Second)
+	var openErr *CircuitOpenError
+	if err := b.allow(); !errors.As(err, &openErr) || !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("allow() = %v, want a *CircuitOpenError matching ErrCircuitOpen", err)
+	}
+	if openErr.RetryAfter != 75*time.Second {
+		t.Errorf("RetryAfter = %v, want 75s", openErr.RetryAfter)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -59,9 +60,18 @@ type Reasoning struct {
 	Effort ReasoningEffort `json:"effort,omitempty"`
 }
 
+// TextFormatJSONObject constrains the model to emit a single valid JSON object.
+const TextFormatJSONObject = "json_object"
+
+// TextFormat selects the output format (the Responses API form of response_format).
+type TextFormat struct {
+	Type string `json:"type"`
+}
+
 // TextConfig configures text output behavior.
 type TextConfig struct {
-	Verbosity Verbosity `json:"verbosity,omitempty"`
+	Verbosity Verbosity   `json:"verbosity,omitempty"`
+	Format    *TextFormat `json:"format,omitempty"`
 }
 
 // ResponsesRequest represents the request body for the Responses API.
@@ -105,11 +115,17 @@ type APIError struct {
 // Client is an OpenAI API client configured for GPT-5.2.
 type Client struct {
 	apiKey          string
+	orgID           string
 	httpClient      *http.Client
+	timeout         time.Duration
 	baseURL         string
+	reviewBaseURL   string
 	model           string
+	settingsMu      sync.RWMutex // guards reasoningEffort and verbosity, which can change at runtime
 	reasoningEffort ReasoningEffort
 	verbosity       Verbosity
+	jsonMode        bool
+	breaker         *breaker
 	log             *slog.Logger
 }
 
@@ -127,27 +143,41 @@ func NewClient(log *slog.Logger) (*Client, error) {
 	}
 
 	return &Client{
-		apiKey: apiKey,
-		httpClient: &http.Client{
-			Timeout: defaultTimeout,
-		},
+		apiKey:          apiKey,
+		orgID:           os.Getenv("OPENAI_ORG_ID"),
+		httpClient:      &http.Client{},
+		timeout:         defaultTimeout,
 		baseURL:         defaultBaseURL,
+		reviewBaseURL:   defaultBaseURL,
 		model:           defaultModel,
 		reasoningEffort: ReasoningMedium,
 		verbosity:       VerbosityMedium,
+		breaker:         newBreaker(defaultBreakerThreshold, defaultBreakerCooldown),
 		log:             log,
 	}, nil
 }
 
 // ClientConfig holds configuration options for the client.
+// OrgID is sent as the OpenAI-Organization header when set. ReviewBaseURL
+// overrides BaseURL for ChatCompletionWithModel requests, so code review
+// traffic can go to a different endpoint than generation. JSONMode requests
+// JSON object output and should only be enabled for models that support it.
+// After BreakerThreshold consecutive outages the client fails fast with
+// ErrCircuitOpen for BreakerCooldown; zero uses the defaults and a negative
+// threshold disables the breaker.
 type ClientConfig struct {
-	APIKey          string
-	BaseURL         string
-	Model           string
-	Timeout         time.Duration
-	ReasoningEffort ReasoningEffort
-	Verbosity       Verbosity
-	Logger          *slog.Logger
+	APIKey           string
+	OrgID            string
+	BaseURL          string
+	ReviewBaseURL    string
+	Model            string
+	Timeout          time.Duration
+	ReasoningEffort  ReasoningEffort
+	Verbosity        Verbosity
+	JSONMode         bool
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+	Logger           *slog.Logger
 }
 
 // NewClientWithConfig creates a new OpenAI client with custom configuration.
@@ -159,6 +189,9 @@ func NewClientWithConfig(cfg ClientConfig) (*Client, error) {
 	if cfg.BaseURL == "" {
 		cfg.BaseURL = defaultBaseURL
 	}
+	if cfg.ReviewBaseURL == "" {
+		cfg.ReviewBaseURL = cfg.BaseURL
+	}
 	if cfg.Model == "" {
 		cfg.Model = defaultModel
 	}
@@ -171,6 +204,12 @@ func NewClientWithConfig(cfg ClientConfig) (*Client, error) {
 	if cfg.Verbosity == "" {
 		cfg.Verbosity = VerbosityMedium
 	}
+	if cfg.BreakerThreshold == 0 {
+		cfg.BreakerThreshold = defaultBreakerThreshold
+	}
+	if cfg.BreakerCooldown <= 0 {
+		cfg.BreakerCooldown = defaultBreakerCooldown
+	}
 
 	// Use a no-op logger if none provided
 	log := cfg.Logger
@@ -180,27 +219,43 @@ func NewClientWithConfig(cfg ClientConfig) (*Client, error) {
 
 	return &Client{
 		apiKey: cfg.APIKey,
-		httpClient: &http.Client{
-			Timeout: cfg.Timeout,
-		},
+		orgID:  cfg.OrgID,
+		// Timeouts are applied per request through the context, so callers
+		// can give an operation a shorter deadline than the default
+		httpClient:      &http.Client{},
+		timeout:         cfg.Timeout,
 		baseURL:         cfg.BaseURL,
+		reviewBaseURL:   cfg.ReviewBaseURL,
 		model:           cfg.Model,
 		reasoningEffort: cfg.ReasoningEffort,
 		verbosity:       cfg.Verbosity,
+		jsonMode:        cfg.JSONMode,
+		breaker:         newBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown),
 		log:             log,
 	}, nil
 }
 
 // SetReasoningEffort updates the reasoning effort level.
 func (c *Client) SetReasoningEffort(effort ReasoningEffort) {
+	c.settingsMu.Lock()
+	defer c.settingsMu.Unlock()
 	c.reasoningEffort = effort
 }
 
 // SetVerbosity updates the verbosity level.
 func (c *Client) SetVerbosity(v Verbosity) {
+	c.settingsMu.Lock()
+	defer c.settingsMu.Unlock()
 	c.verbosity = v
 }
 
+// settings returns the current reasoning effort and verbosity.
+func (c *Client) settings() (ReasoningEffort, Verbosity) {
+	c.settingsMu.RLock()
+	defer c.settingsMu.RUnlock()
+	return c.reasoningEffort, c.verbosity
+}
+
 // ValidateInput checks if the input is valid (non-empty and not whitespace only).
 func ValidateInput(input string) error {
 	if strings.TrimSpace(input) == "" {
@@ -210,13 +265,21 @@ func ValidateInput(input string) error {
 }
 
 // ChatCompletion sends a request to the GPT-5.2 Responses API.
-// The context can be used to set a timeout or cancel the request.
+// The context can be used to cancel the request. The call is bounded by the
+// client's configured timeout; an earlier context deadline takes precedence.
 func (c *Client) ChatCompletion(ctx context.Context, messages []Message) (string, error) {
-	return c.ChatCompletionWithModel(ctx, messages, c.model)
+	return c.complete(ctx, messages, c.model, c.baseURL)
 }
 
 // ChatCompletionWithModel sends a request using a specific model.
+// It is used for code review and goes to the review base URL, which
+// defaults to the main base URL.
 func (c *Client) ChatCompletionWithModel(ctx context.Context, messages []Message, model string) (string, error) {
+	return c.complete(ctx, messages, model, c.reviewBaseURL)
+}
+
+// complete sends a Responses API request for model to baseURL.
+func (c *Client) complete(ctx context.Context, messages []Message, model string, baseURL string) (string, error) {
 	requestID := logger.GetRequestID(ctx)
 	start := time.Now()
 
@@ -224,18 +287,23 @@ func (c *Client) ChatCompletionWithModel(ctx context.Context, messages []Message
 		return "", ErrEmptyInput
 	}
 
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
 	// Calculate prompt metrics
 	promptLength := 0
 	for _, m := range messages {
 		promptLength += len(m.Content)
 	}
 
+	effort, verbosity := c.settings()
+
 	c.log.Info("openai_request_start",
 		slog.String("request_id", requestID),
 		slog.String("model", model),
 		slog.Int("prompt_length", promptLength),
 		slog.Int("message_count", len(messages)),
-		slog.String("reasoning_effort", string(c.reasoningEffort)),
+		slog.String("reasoning_effort", string(effort)),
 	)
 
 	// Debug: truncated preview (first 500 chars of last message)
@@ -258,12 +326,15 @@ func (c *Client) ChatCompletionWithModel(ctx context.Context, messages []Message
 		Model: model,
 		Input: input,
 		Reasoning: &Reasoning{
-			Effort: c.reasoningEffort,
+			Effort: effort,
 		},
 		Text: &TextConfig{
-			Verbosity: c.verbosity,
+			Verbosity: verbosity,
 		},
 	}
+	if c.jsonMode {
+		reqBody.Text.Format = &TextFormat{Type: TextFormatJSONObject}
+	}
 
 	jsonBody, err := json.Marshal(reqBody)
 	if err != nil {
@@ -275,7 +346,7 @@ func (c *Client) ChatCompletionWithModel(ctx context.Context, messages []Message
 		return "", fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/responses", bytes.NewReader(jsonBody))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/responses", bytes.NewReader(jsonBody))
 	if err != nil {
 		c.log.Error("openai_request_create_failed",
 			slog.String("request_id", requestID),
@@ -287,9 +358,40 @@ func (c *Client) ChatCompletionWithModel(ctx context.Context, messages []Message
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	if c.orgID != "" {
+		req.Header.Set("OpenAI-Organization", c.orgID)
+	}
+
+	// Fail fast while OpenAI is down rather than waiting out every timeout
+	if err := c.breaker.allow(); err != nil {
+		c.log.Warn("openai_circuit_open",
+			slog.String("request_id", requestID),
+			slog.String("model", model),
+		)
+		return "", err
+	}
+	// Transport errors, timeouts, 429s and 5xx count as outages; any other
+	// response shows OpenAI is reachable
+	var outage, reached bool
+	defer func() {
+		switch {
+		case outage:
+			if c.breaker.failure() {
+				c.log.Error("openai_circuit_opened",
+					slog.String("request_id", requestID),
+					slog.Duration("cooldown", c.breaker.cooldown),
+				)
+			}
+		case reached:
+			c.breaker.success()
+		default:
+			c.breaker.release()
+		}
+	}()
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
+		outage = !errors.Is(err, context.Canceled)
 		if errors.Is(err, context.DeadlineExceeded) {
 			c.log.Error("openai_request_timeout",
 				slog.String("request_id", requestID),
@@ -306,6 +408,8 @@ func (c *Client) ChatCompletionWithModel(ctx context.Context, messages []Message
 		return "", fmt.Errorf("%w: %v", ErrRequestFailed, err)
 	}
 	defer func() { _ = resp.Body.Close() }()
+	outage = resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+	reached = !outage
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
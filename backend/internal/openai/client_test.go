@@ -1,9 +1,14 @@
 package openai
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"testing/quick"
+	"time"
 )
 
 // Property 7: Input Validation
@@ -81,3 +86,126 @@ func TestValidateInput_Property_TrimmedEquivalence(t *testing.T) {
 		t.Errorf("Property failed: validation should be equivalent to TrimSpace check: %v", err)
 	}
 }
+
+func TestChatCompletion_OrgHeaderAndReviewBaseURL(t *testing.T) {
+	type seen struct {
+		path string
+		org  string
+	}
+	var requests []seen
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, seen{path: r.URL.Path, org: r.Header.Get("OpenAI-Organization")})
+		_, _ = w.Write([]byte(`{"output_text":"ok"}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithConfig(ClientConfig{
+		APIKey:        "test-key",
+		OrgID:         "org-123",
+		BaseURL:       server.URL + "/generation",
+		ReviewBaseURL: server.URL + "/review",
+	})
+	if err != nil {
+		t.Fatalf("NewClientWithConfig: %v", err)
+	}
+
+	messages := []Message{{Role: "user", Content: "hello"}}
+	if _, err := client.ChatCompletion(context.Background(), messages); err != nil {
+		t.Fatalf("ChatCompletion: %v", err)
+	}
+	if _, err := client.ChatCompletionWithModel(context.Background(), messages, "review-model"); err != nil {
+		t.Fatalf("ChatCompletionWithModel: %v", err)
+	}
+
+	want := []seen{
+		{path: "/generation/responses", org: "org-123"},
+		{path: "/review/responses", org: "org-123"},
+	}
+	if len(requests) != len(want) {
+		t.Fatalf("expected %d requests, got %d", len(want), len(requests))
+	}
+	for i := range want {
+		if requests[i] != want[i] {
+			t.Errorf("request %d = %+v, want %+v", i, requests[i], want[i])
+		}
+	}
+}
+
+func TestChatCompletionWithModel_DefaultsToBaseURL(t *testing.T) {
+	var path, org string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		org = r.Header.Get("OpenAI-Organization")
+		_, _ = w.Write([]byte(`{"output_text":"ok"}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithConfig(ClientConfig{APIKey: "test-key", BaseURL: server.URL + "/v1"})
+	if err != nil {
+		t.Fatalf("NewClientWithConfig: %v", err)
+	}
+
+	if _, err := client.ChatCompletionWithModel(context.Background(), []Message{{Role: "user", Content: "hi"}}, "m"); err != nil {
+		t.Fatalf("ChatCompletionWithModel: %v", err)
+	}
+	if path != "/v1/responses" {
+		t.Errorf("path = %q, want /v1/responses", path)
+	}
+	if org != "" {
+		t.Errorf("OpenAI-Organization = %q, want unset", org)
+	}
+}
+
+func TestChatCompletion_JSONModeSetsTextFormat(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		var body ResponsesRequest
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			_, _ = w.Write([]byte(`{"output_text":"{}"}`))
+		}))
+
+		client, err := NewClientWithConfig(ClientConfig{APIKey: "test-key", BaseURL: server.URL, JSONMode: enabled})
+		if err != nil {
+			t.Fatalf("NewClientWithConfig: %v", err)
+		}
+		if _, err := client.ChatCompletion(context.Background(), []Message{{Role: "user", Content: "Return JSON"}}); err != nil {
+			t.Fatalf("ChatCompletion: %v", err)
+		}
+		server.Close()
+
+		if enabled {
+			if body.Text == nil || body.Text.Format == nil || body.Text.Format.Type != TextFormatJSONObject {
+				t.Errorf("JSON mode enabled: text.format = %+v, want json_object", body.Text)
+			}
+		} else if body.Text != nil && body.Text.Format != nil {
+			t.Errorf("JSON mode disabled: unexpected text.format %+v", body.Text.Format)
+		}
+	}
+}
+
+func TestChatCompletion_ClientTimeoutCapsContextDeadline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithConfig(ClientConfig{APIKey: "test-key", BaseURL: server.URL, Timeout: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewClientWithConfig: %v", err)
+	}
+
+	// A later caller deadline must not extend the client's timeout
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := client.ChatCompletion(ctx, []Message{{Role: "user", Content: "hi"}}); err == nil {
+		t.Fatal("expected the client timeout to end the call")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("call took %s, want it bounded by the 50ms client timeout", elapsed)
+	}
+}
@@ -0,0 +1,85 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrNoScriptedResponse is returned by FakeClient when it has no responses.
+var ErrNoScriptedResponse = errors.New("fake client: no scripted response")
+
+// FakeCall records a single request received by FakeClient.
+type FakeCall struct {
+	Messages []Message
+	Model    string
+	// Deadline of the request context; zero when it had none
+	Deadline time.Time
+}
+
+// FakeClient is an in-memory stand-in for Client in tests. It returns the
+// scripted responses in order, repeating the last one once the script is
+// exhausted, and records every call it receives. It never touches the network.
+type FakeClient struct {
+	mu        sync.Mutex
+	responses []string
+	err       error
+	calls     []FakeCall
+}
+
+// NewFakeClient creates a FakeClient that returns responses in order.
+func NewFakeClient(responses ...string) *FakeClient {
+	return &FakeClient{responses: responses}
+}
+
+// SetError makes every subsequent call fail with err; nil restores the script.
+func (f *FakeClient) SetError(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.err = err
+}
+
+// ChatCompletion records the request and returns the next scripted response.
+func (f *FakeClient) ChatCompletion(ctx context.Context, messages []Message) (string, error) {
+	return f.next(ctx, messages, "")
+}
+
+// ChatCompletionWithModel records the request and model and returns the next
+// scripted response.
+func (f *FakeClient) ChatCompletionWithModel(ctx context.Context, messages []Message, model string) (string, error) {
+	return f.next(ctx, messages, model)
+}
+
+func (f *FakeClient) next(ctx context.Context, messages []Message, model string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	deadline, _ := ctx.Deadline()
+	f.calls = append(f.calls, FakeCall{Messages: messages, Model: model, Deadline: deadline})
+	if f.err != nil {
+		return "", f.err
+	}
+	if len(f.responses) == 0 {
+		return "", ErrNoScriptedResponse
+	}
+	resp := f.responses[0]
+	if len(f.responses) > 1 {
+		f.responses = f.responses[1:]
+	}
+	return resp, nil
+}
+
+// Calls returns a copy of the requests received so far.
+func (f *FakeClient) Calls() []FakeCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]FakeCall(nil), f.calls...)
+}
+
+// CallCount returns how many requests have been received.
+func (f *FakeClient) CallCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.calls)
+}
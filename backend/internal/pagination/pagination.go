@@ -0,0 +1,54 @@
+// Package pagination holds the page bounds shared by every list endpoint, so
+// the gallery, history and later lists default and cap page sizes the same
+// way.
+package pagination
+
+import "math"
+
+// DefaultPageSize is the page size used when a request doesn't ask for one.
+const DefaultPageSize = 20
+
+// MaxPageSize is the largest page any list endpoint returns.
+const MaxPageSize = 100
+
+// NormalizePage returns page, or 1 if it is not positive.
+func NormalizePage(page int) int {
+	if page < 1 {
+		return 1
+	}
+	return page
+}
+
+// NormalizePageSize returns pageSize capped at MaxPageSize, or
+// DefaultPageSize if it is not positive.
+func NormalizePageSize(pageSize int) int {
+	return NormalizePageSizeWithDefault(pageSize, DefaultPageSize)
+}
+
+// NormalizePageSizeWithDefault is NormalizePageSize for lists with their own
+// configured default. The default is itself normalized, so it can't exceed
+// MaxPageSize either.
+func NormalizePageSizeWithDefault(pageSize, defaultSize int) int {
+	if pageSize < 1 {
+		if defaultSize < 1 {
+			return DefaultPageSize
+		}
+		pageSize = defaultSize
+	}
+	return min(pageSize, MaxPageSize)
+}
+
+// TotalPages returns how many pages of pageSize hold total items. An empty
+// list still has one (empty) page.
+func TotalPages(total, pageSize int) int {
+	pageSize = NormalizePageSize(pageSize)
+	if total <= 0 {
+		return 1
+	}
+	return int(math.Ceil(float64(total) / float64(pageSize)))
+}
+
+// Offset returns how many items come before page.
+func Offset(page, pageSize int) int {
+	return (NormalizePage(page) - 1) * NormalizePageSize(pageSize)
+}
@@ -0,0 +1,47 @@
+package pagination
+
+import "testing"
+
+func TestNormalizePageSize(t *testing.T) {
+	tests := []struct {
+		pageSize, defaultSize, want int
+	}{
+		{0, 0, DefaultPageSize},
+		{-5, 0, DefaultPageSize},
+		{0, 50, 50},
+		{0, 500, MaxPageSize},
+		{30, 50, 30},
+		{MaxPageSize + 1, 50, MaxPageSize},
+	}
+	for _, tt := range tests {
+		if got := NormalizePageSizeWithDefault(tt.pageSize, tt.defaultSize); got != tt.want {
+			t.Errorf("NormalizePageSizeWithDefault(%d, %d) = %d, want %d", tt.pageSize, tt.defaultSize, got, tt.want)
+		}
+	}
+	if got := NormalizePageSize(0); got != DefaultPageSize {
+		t.Errorf("NormalizePageSize(0) = %d, want %d", got, DefaultPageSize)
+	}
+}
+
+func TestTotalPagesAndOffset(t *testing.T) {
+	tests := []struct {
+		total, pageSize, want int
+	}{
+		{0, 20, 1},
+		{20, 20, 1},
+		{21, 20, 2},
+		{45, 0, 3}, // default page size
+		{250, 1000, 3},
+	}
+	for _, tt := range tests {
+		if got := TotalPages(tt.total, tt.pageSize); got != tt.want {
+			t.Errorf("TotalPages(%d, %d) = %d, want %d", tt.total, tt.pageSize, got, tt.want)
+		}
+	}
+	if got := Offset(3, 20); got != 40 {
+		t.Errorf("Offset(3, 20) = %d, want 40", got)
+	}
+	if got := Offset(0, 0); got != 0 {
+		t.Errorf("Offset(0, 0) = %d, want 0", got)
+	}
+}
@@ -0,0 +1,147 @@
+// Package pow issues and verifies stateless proof-of-work challenges that
+// make anonymous generation requests cost the client some CPU time.
+//
+// A challenge token carries a random nonce, the difficulty and an expiry,
+// signed with an HMAC so the server needs no storage to verify it. A
+// solution is any string s for which SHA-256(token + ":" + s) starts with
+// at least difficulty zero bits. Tokens are not single-use: a solved token
+// stays valid until it expires, so keep the TTL short.
+package pow
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Verification errors.
+var (
+	ErrInvalidChallenge = errors.New("invalid proof-of-work challenge")
+	ErrChallengeExpired = errors.New("proof-of-work challenge expired")
+	ErrInvalidSolution  = errors.New("invalid proof-of-work solution")
+)
+
+// MaxDifficulty is the largest supported difficulty in leading zero bits.
+// The browser solver hashes one candidate per await, so anything harder
+// would not finish within a challenge's TTL.
+const MaxDifficulty = 24
+
+// maxSolutionLength bounds the solution string a client may send.
+const maxSolutionLength = 64
+
+// Challenge is an issued proof-of-work challenge.
+type Challenge struct {
+	Token      string    `json:"token"`
+	Difficulty int       `json:"difficulty"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+}
+
+// Issuer issues and verifies challenges signed with its secret.
+type Issuer struct {
+	secret     []byte
+	difficulty int
+	ttl        time.Duration
+	now        func() time.Time // for testing
+}
+
+// NewIssuer creates an issuer. An empty secret is replaced with a random one,
+// so outstanding challenges stop verifying when the process restarts.
+func NewIssuer(secret []byte, difficulty int, ttl time.Duration) (*Issuer, error) {
+	if difficulty < 1 || difficulty > MaxDifficulty {
+		return nil, fmt.Errorf("difficulty must be 1-%d, got %d", MaxDifficulty, difficulty)
+	}
+	if ttl <= 0 {
+		return nil, fmt.Errorf("ttl must be positive, got %s", ttl)
+	}
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, fmt.Errorf("generate secret: %w", err)
+		}
+	}
+	return &Issuer{secret: secret, difficulty: difficulty, ttl: ttl, now: time.Now}, nil
+}
+
+// Issue creates a new challenge at the issuer's difficulty.
+func (i *Issuer) Issue() (*Challenge, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("generate nonce: %w", err)
+	}
+	expiresAt := i.now().Add(i.ttl).Truncate(time.Second)
+	payload := fmt.Sprintf("%s.%d.%d", hex.EncodeToString(nonce), i.difficulty, expiresAt.Unix())
+
+	return &Challenge{
+		Token:      payload + "." + i.sign(payload),
+		Difficulty: i.difficulty,
+		ExpiresAt:  expiresAt,
+	}, nil
+}
+
+// Verify checks that token was issued by this issuer, has not expired, and
+// that solution meets the difficulty the token was issued with.
+func (i *Issuer) Verify(token, solution string) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 4 {
+		return ErrInvalidChallenge
+	}
+	payload := strings.Join(parts[:3], ".")
+	if !hmac.Equal([]byte(parts[3]), []byte(i.sign(payload))) {
+		return ErrInvalidChallenge
+	}
+
+	difficulty, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return ErrInvalidChallenge
+	}
+	expires, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return ErrInvalidChallenge
+	}
+	if i.now().After(time.Unix(expires, 0)) {
+		return ErrChallengeExpired
+	}
+
+	if solution == "" || len(solution) > maxSolutionLength || leadingZeroBits(token, solution) < difficulty {
+		return ErrInvalidSolution
+	}
+	return nil
+}
+
+// sign returns the hex HMAC of payload.
+func (i *Issuer) sign(payload string) string {
+	mac := hmac.New(sha256.New, i.secret)
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Solve finds a solution for token by counting up from zero. It is what
+// clients do, and is exported for tests and internal tooling.
+func Solve(token string, difficulty int) string {
+	for n := 0; ; n++ {
+		solution := strconv.Itoa(n)
+		if leadingZeroBits(token, solution) >= difficulty {
+			return solution
+		}
+	}
+}
+
+// leadingZeroBits counts the leading zero bits of SHA-256(token + ":" + solution).
+func leadingZeroBits(token, solution string) int {
+	sum := sha256.Sum256([]byte(token + ":" + solution))
+	count := 0
+	for _, b := range sum {
+		if b != 0 {
+			return count + bits.LeadingZeros8(b)
+		}
+		count += 8
+	}
+	return count
+}
@@ -0,0 +1,113 @@
+package pow
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestIssuer(t *testing.T, difficulty int) *Issuer {
+	t.Helper()
+	issuer, err := NewIssuer([]byte("test-secret"), difficulty, time.Minute)
+	if err != nil {
+		t.Fatalf("NewIssuer() error = %v", err)
+	}
+	return issuer
+}
+
+func TestVerify_AcceptsValidSolution(t *testing.T) {
+	issuer := newTestIssuer(t, 8)
+	challenge, err := issuer.Issue()
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+
+	solution := Solve(challenge.Token, challenge.Difficulty)
+	if err := issuer.Verify(challenge.Token, solution); err != nil {
+		t.Errorf("Verify() error = %v, want nil", err)
+	}
+}
+
+func TestVerify_RejectsInvalidSolution(t *testing.T) {
+	issuer := newTestIssuer(t, 16)
+	challenge, err := issuer.Issue()
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+
+	// Find a candidate that misses the difficulty
+	var wrong string
+	for n := 0; wrong == ""; n++ {
+		if candidate := strconv.Itoa(n); leadingZeroBits(challenge.Token, candidate) < challenge.Difficulty {
+			wrong = candidate
+		}
+	}
+
+	for _, solution := range []string{wrong, "", strings.Repeat("9", maxSolutionLength+1)} {
+		if err := issuer.Verify(challenge.Token, solution); !errors.Is(err, ErrInvalidSolution) {
+			t.Errorf("Verify(%q) error = %v, want ErrInvalidSolution", solution, err)
+		}
+	}
+}
+
+func TestVerify_RejectsExpiredChallenge(t *testing.T) {
+	issuer := newTestIssuer(t, 4)
+	challenge, err := issuer.Issue()
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+	solution := Solve(challenge.Token, challenge.Difficulty)
+
+	issuer.now = func() time.Time { return challenge.ExpiresAt.Add(time.Second) }
+
+	if err := issuer.Verify(challenge.Token, solution); !errors.Is(err, ErrChallengeExpired) {
+		t.Errorf("Verify() error = %v, want ErrChallengeExpired", err)
+	}
+}
+
+func TestVerify_RejectsTamperedToken(t *testing.T) {
+	issuer := newTestIssuer(t, 8)
+	challenge, err := issuer.Issue()
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+
+	// Lowering the difficulty invalidates the signature
+	parts := strings.Split(challenge.Token, ".")
+	parts[1] = "1"
+	tampered := strings.Join(parts, ".")
+
+	otherIssuer := newTestIssuer(t, 8)
+	otherIssuer.secret = []byte("other-secret")
+
+	tests := []struct {
+		name   string
+		issuer *Issuer
+		token  string
+	}{
+		{name: "lowered difficulty", issuer: issuer, token: tampered},
+		{name: "malformed", issuer: issuer, token: "not-a-token"},
+		{name: "other secret", issuer: otherIssuer, token: challenge.Token},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.issuer.Verify(tt.token, Solve(tt.token, 1)); !errors.Is(err, ErrInvalidChallenge) {
+				t.Errorf("Verify() error = %v, want ErrInvalidChallenge", err)
+			}
+		})
+	}
+}
+
+func TestNewIssuer_RejectsBadSettings(t *testing.T) {
+	if _, err := NewIssuer(nil, 0, time.Minute); err == nil {
+		t.Error("NewIssuer() accepted difficulty 0")
+	}
+	if _, err := NewIssuer(nil, MaxDifficulty+1, time.Minute); err == nil {
+		t.Error("NewIssuer() accepted a difficulty above MaxDifficulty")
+	}
+	if _, err := NewIssuer(nil, 8, 0); err == nil {
+		t.Error("NewIssuer() accepted a zero TTL")
+	}
+}
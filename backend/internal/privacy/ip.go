@@ -0,0 +1,80 @@
+// Package privacy derives non-reversible identifiers from client data, so
+// views, ratings and rate-limit logs never store or print a raw IP address.
+package privacy
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+var (
+	saltMu sync.RWMutex
+	ipSalt string
+
+	// Salt replaced by the last rotation, honoured until previousUntil
+	previousSalt  string
+	previousUntil time.Time
+)
+
+// SetIPSalt sets the secret salt HashIP mixes into every hash. Changing it
+// changes every hash, which resets view and rating deduplication unless the
+// old salt is kept with SetPreviousIPSalt.
+func SetIPSalt(salt string) {
+	saltMu.Lock()
+	defer saltMu.Unlock()
+	ipSalt = salt
+}
+
+// SetPreviousIPSalt keeps the salt a rotation replaced so PreviousHashes can
+// recognise hashes stored under it until the grace period ends. An empty
+// salt is the unsalted SHA-256, for a first rotation; a zero until disables
+// the grace period.
+func SetPreviousIPSalt(salt string, until time.Time) {
+	saltMu.Lock()
+	defer saltMu.Unlock()
+	previousSalt = salt
+	previousUntil = until
+}
+
+// HashIP returns a lowercase hex SHA-256 identifier for a client IP using the
+// salt from SetIPSalt. Use it everywhere an IP is stored or logged.
+func HashIP(ip string) string {
+	saltMu.RLock()
+	salt := ipSalt
+	saltMu.RUnlock()
+	return HashIPWithSalt(ip, salt)
+}
+
+// HashIPWithSalt hashes ip with an explicit salt. With a salt the hash is an
+// HMAC-SHA256 keyed by it, so hashes cannot be reversed by hashing every
+// possible address without knowing the salt. An empty salt gives the plain
+// SHA-256 used before salting was configurable, keeping stored hashes valid.
+func HashIPWithSalt(ip, salt string) string {
+	if salt == "" {
+		sum := sha256.Sum256([]byte(ip))
+		return hex.EncodeToString(sum[:])
+	}
+	mac := hmac.New(sha256.New, []byte(salt))
+	mac.Write([]byte(ip))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// PreviousHashes returns the hashes ip had under the salt replaced by the
+// last rotation, for duplicate checks against records stored before it. It
+// returns nil once the grace period from SetPreviousIPSalt has ended.
+func PreviousHashes(ip string) []string {
+	return previousHashesAt(ip, time.Now())
+}
+
+func previousHashesAt(ip string, now time.Time) []string {
+	saltMu.RLock()
+	salt, until, current := previousSalt, previousUntil, ipSalt
+	saltMu.RUnlock()
+	if until.IsZero() || !now.Before(until) || salt == current {
+		return nil
+	}
+	return []string{HashIPWithSalt(ip, salt)}
+}
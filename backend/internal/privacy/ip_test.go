@@ -0,0 +1,80 @@
+package privacy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+var sha256HexPattern = regexp.MustCompile(`^[a-f0-9]{64}$`)
+
+func TestHashIPWithSalt_StableAcrossCalls(t *testing.T) {
+	for _, ip := range []string{"203.0.113.7", "2001:db8::1", "127.0.0.1"} {
+		for _, salt := range []string{"", "pepper"} {
+			first := HashIPWithSalt(ip, salt)
+			if second := HashIPWithSalt(ip, salt); first != second {
+				t.Errorf("HashIPWithSalt(%q, %q) not stable: %s vs %s", ip, salt, first, second)
+			}
+			if !sha256HexPattern.MatchString(first) {
+				t.Errorf("HashIPWithSalt(%q, %q) = %q, want 64 lowercase hex chars", ip, salt, first)
+			}
+			if strings.Contains(first, ip) {
+				t.Errorf("hash of %q contains the address", ip)
+			}
+		}
+	}
+}
+
+func TestHashIPWithSalt_SaltChangesHash(t *testing.T) {
+	ip := "203.0.113.7"
+	unsalted := HashIPWithSalt(ip, "")
+	salted := HashIPWithSalt(ip, "pepper")
+	other := HashIPWithSalt(ip, "paprika")
+
+	if salted == unsalted || salted == other {
+		t.Errorf("expected different hashes per salt, got unsalted=%s pepper=%s paprika=%s", unsalted, salted, other)
+	}
+}
+
+func TestHashIPWithSalt_EmptySaltMatchesPlainSHA256(t *testing.T) {
+	ip := "198.51.100.20"
+	sum := sha256.Sum256([]byte(ip))
+	if got, want := HashIPWithSalt(ip, ""), hex.EncodeToString(sum[:]); got != want {
+		t.Errorf("HashIPWithSalt(%q, \"\") = %s, want plain SHA-256 %s", ip, got, want)
+	}
+}
+
+func TestHashIP_UsesConfiguredSalt(t *testing.T) {
+	t.Cleanup(func() { SetIPSalt("") })
+
+	ip := "203.0.113.7"
+	SetIPSalt("pepper")
+	if got, want := HashIP(ip), HashIPWithSalt(ip, "pepper"); got != want {
+		t.Errorf("HashIP() = %s, want the configured salt's hash %s", got, want)
+	}
+}
+
+func TestPreviousHashes_OnlyDuringGracePeriod(t *testing.T) {
+	t.Cleanup(func() {
+		SetIPSalt("")
+		SetPreviousIPSalt("", time.Time{})
+	})
+
+	ip := "203.0.113.7"
+	now := time.Now()
+	SetIPSalt("paprika")
+	if got := previousHashesAt(ip, now); got != nil {
+		t.Errorf("previousHashesAt() without a rotation = %v, want nil", got)
+	}
+
+	SetPreviousIPSalt("pepper", now.Add(time.Hour))
+	if got := previousHashesAt(ip, now); len(got) != 1 || got[0] != HashIPWithSalt(ip, "pepper") {
+		t.Errorf("previousHashesAt() in the grace period = %v, want the old salt's hash", got)
+	}
+	if got := previousHashesAt(ip, now.Add(time.Hour)); got != nil {
+		t.Errorf("previousHashesAt() after the grace period = %v, want nil", got)
+	}
+}
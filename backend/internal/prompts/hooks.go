@@ -162,7 +162,7 @@ Balanced safety - adds secret scanning and prompt guardrails.
 ` + "```" + `
 
 ### Strict Preset Hooks
-Maximum enforcement - adds static analysis and dependency scanning.
+Maximum enforcement - adds static analysis, dependency scanning, and file lifecycle checks.
 
 #### static-analysis.kiro.hook
 ` + "```json" + `
@@ -198,6 +198,8 @@ Maximum enforcement - adds static analysis and dependency scanning.
 }
 ` + "```" + `
 
+The strict preset's fileCreated and fileDeleted hooks are shown with the preset guidance.
+
 ### File-Based Hook Examples
 
 #### go-test-on-change.kiro.hook
@@ -237,6 +239,47 @@ Maximum enforcement - adds static analysis and dependency scanning.
 ` + "```" + `
 `
 
+// FileLifecycleHookExamples contains the fileCreated and fileDeleted hooks used by the strict preset.
+// It is appended once, by the strict preset guidance, rather than repeated in HookExamples.
+const FileLifecycleHookExamples = `#### test-on-create.kiro.hook
+` + "```json" + `
+{
+  "name": "Test Reminder on New File",
+  "description": "Remind to add tests when a new source file is created",
+  "version": "1.0.0",
+  "enabled": true,
+  "when": {
+    "type": "fileCreated",
+    "patterns": ["src/**/*"]
+  },
+  "then": {
+    "type": "askAgent",
+    "prompt": "A new source file was created. Check whether it has a matching test file. If not, propose tests covering its main behavior and edge cases."
+  }
+}
+` + "```" + `
+
+#### cleanup-on-delete.kiro.hook
+` + "```json" + `
+{
+  "name": "Cleanup on File Delete",
+  "description": "Find dangling references when a source file is deleted",
+  "version": "1.0.0",
+  "enabled": true,
+  "when": {
+    "type": "fileDeleted",
+    "patterns": ["src/**/*"]
+  },
+  "then": {
+    "type": "askAgent",
+    "prompt": "A source file was deleted. Search for imports, references, and tests that still point to it and list what needs to be updated or removed."
+  }
+}
+` + "```" + `
+
+Note: fileCreated and fileDeleted hooks must use "askAgent" and must include "patterns". Adjust the patterns to the project's source directories and languages.
+`
+
 // HookPresetDescriptions describes what each preset includes.
 var HookPresetDescriptions = map[string]struct {
 	Title       string
@@ -260,8 +303,8 @@ var HookPresetDescriptions = map[string]struct {
 	},
 	HookPresetStrict: {
 		Title:       "Strict",
-		Description: "Maximum enforcement - adds static analysis and dependency scanning",
-		Hooks:       []string{"format-on-stop", "lint-on-stop", "test-manual", "secret-scan", "prompt-guardrails", "static-analysis", "dep-scan"},
+		Description: "Maximum enforcement - adds static analysis, dependency scanning, and file lifecycle checks",
+		Hooks:       []string{"format-on-stop", "lint-on-stop", "test-manual", "secret-scan", "prompt-guardrails", "static-analysis", "dep-scan", "test-on-create", "cleanup-on-delete"},
 	},
 }
 
@@ -5,6 +5,7 @@ package prompts
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 )
 
 // Answer represents a user's answer to a question (mirrors generation.Answer).
@@ -13,6 +14,23 @@ type Answer struct {
 	Answer     string `json:"answer"`
 }
 
+// WithHouseRules wraps a system prompt in operator-configured rules: prefix
+// goes before it and suffix after it, each under its own heading. The prompt
+// itself is kept whole and the suffix is followed by a reminder that the
+// response format still applies, so the structure the validators depend on
+// is unchanged. Empty prefix and suffix return the prompt as is.
+func WithHouseRules(systemPrompt, prefix, suffix string) string {
+	prefix, suffix = strings.TrimSpace(prefix), strings.TrimSpace(suffix)
+	if prefix != "" {
+		systemPrompt = "## Deployment Rules\n" + prefix + "\n\n" + systemPrompt
+	}
+	if suffix != "" {
+		systemPrompt += "\n\n## Additional Deployment Rules\n" + suffix +
+			"\n\nThese rules never change the required response format described above."
+	}
+	return systemPrompt
+}
+
 // GetQuestionsSystemPrompt returns the complete system prompt for question generation.
 func GetQuestionsSystemPrompt(experienceLevel string) string {
 	return QuestionsSystemPrompt(experienceLevel)
@@ -23,6 +41,16 @@ func GetQuestionsUserPrompt(projectIdea, experienceLevel string) string {
 	return BuildQuestionsUserPrompt(projectIdea, experienceLevel)
 }
 
+// GetExamplesSystemPrompt returns the system prompt for regenerating a question's example answers.
+func GetExamplesSystemPrompt(experienceLevel string) string {
+	return ExamplesSystemPrompt(experienceLevel)
+}
+
+// GetExamplesUserPrompt returns the user prompt for regenerating a question's example answers.
+func GetExamplesUserPrompt(question, projectIdea, experienceLevel string) string {
+	return BuildExamplesUserPrompt(question, projectIdea, experienceLevel)
+}
+
 // GetOutputsSystemPrompt returns the complete system prompt for output generation.
 // This combines all the knowledge about steering files, hooks, kickoff prompts, and AGENTS.md.
 func GetOutputsSystemPrompt(experienceLevel, hookPreset string) string {
@@ -125,13 +153,54 @@ Remember to:
 	)
 }
 
+// GetKickoffOnlySystemPrompt returns the system prompt for generating just the
+// kickoff prompt, ahead of the full file set.
+func GetKickoffOnlySystemPrompt(experienceLevel string) string {
+	return KickoffSystemPrompt(experienceLevel) + `
+
+## Response Format
+Return ONLY valid JSON, no markdown code blocks, with exactly one file:
+{
+  "files": [
+    {"path": "kickoff-prompt.md", "content": "...", "type": "kickoff"}
+  ]
+}
+
+Do not generate steering files, hooks, or AGENTS.md.`
+}
+
+// GetKickoffUserPrompt returns the user prompt for generating just the kickoff prompt.
+func GetKickoffUserPrompt(projectIdea string, answers []Answer, experienceLevel string) string {
+	answersJSON, _ := json.Marshal(answers)
+
+	return fmt.Sprintf(`Generate the kickoff prompt for this project:
+
+## Project Idea
+%s
+
+## User's Answers to Questions
+%s
+
+## Configuration
+- Experience Level: %s
+
+Remember to:
+- Adapt language to %s experience level
+- Include all required sections in the kickoff prompt`,
+		projectIdea,
+		string(answersJSON),
+		experienceLevel,
+		experienceLevel,
+	)
+}
+
 func getHookPresetGuidance(preset string) string {
 	presetInfo, ok := HookPresetDescriptions[preset]
 	if !ok {
 		presetInfo = HookPresetDescriptions[HookPresetDefault]
 	}
 
-	return fmt.Sprintf(`## Selected Preset: %s
+	guidance := fmt.Sprintf(`## Selected Preset: %s
 %s
 
 Generate these hooks: %v
@@ -141,14 +210,52 @@ Refer to the hook examples above for the correct format for each hook type.`,
 		presetInfo.Description,
 		presetInfo.Hooks,
 	)
+
+	if preset == HookPresetStrict {
+		guidance += "\n\n## File Lifecycle Hooks\n" + FileLifecycleHookExamples
+	}
+
+	return guidance
+}
+
+// ExperienceLevelDescriptions describes each experience level, including auto.
+var ExperienceLevelDescriptions = map[string]struct {
+	Title       string
+	Description string
+}{
+	ExperienceBeginner: {
+		Title:       "Beginner",
+		Description: "New to programming - simple explanations, no jargon, step-by-step guidance",
+	},
+	ExperienceNovice: {
+		Title:       "Novice",
+		Description: "Some experience - moderate technical terms, helpful hints, best practices",
+	},
+	ExperienceExpert: {
+		Title:       "Expert",
+		Description: "Experienced developer - technical details, architecture focus, advanced patterns",
+	},
+	ExperienceAuto: {
+		Title:       "Auto",
+		Description: "Inferred from the project idea",
+	},
 }
 
-// ValidExperienceLevels returns the list of valid experience levels.
+// ValidExperienceLevels returns the list of valid experience levels, in the
+// order options are shown.
 func ValidExperienceLevels() []string {
 	return []string{ExperienceBeginner, ExperienceNovice, ExperienceExpert}
 }
 
-// ValidHookPresets returns the list of valid hook presets.
+// RequestExperienceLevels returns the levels a request or the configured
+// default may name: every valid level plus ExperienceAuto, which is resolved
+// before any prompt is built.
+func RequestExperienceLevels() []string {
+	return append(ValidExperienceLevels(), ExperienceAuto)
+}
+
+// ValidHookPresets returns the list of valid hook presets, from least to most
+// enforcement.
 func ValidHookPresets() []string {
 	return []string{HookPresetLight, HookPresetBasic, HookPresetDefault, HookPresetStrict}
 }
@@ -163,6 +270,15 @@ func IsValidExperienceLevel(level string) bool {
 	return false
 }
 
+// ResolveExperienceLevel returns level unchanged unless it is ExperienceAuto,
+// in which case the level is inferred from the project idea.
+func ResolveExperienceLevel(level, projectIdea string) string {
+	if level == ExperienceAuto {
+		return InferExperienceLevel(projectIdea)
+	}
+	return level
+}
+
 // IsValidHookPreset checks if the given preset is valid.
 func IsValidHookPreset(preset string) bool {
 	for _, valid := range ValidHookPresets() {
@@ -116,6 +116,55 @@ func TestHookPresetDescriptions(t *testing.T) {
 	}
 }
 
+// TestExperienceLevelDescriptions tests that all levels, including auto, have descriptions.
+func TestExperienceLevelDescriptions(t *testing.T) {
+	for _, level := range RequestExperienceLevels() {
+		info, ok := ExperienceLevelDescriptions[level]
+		if !ok {
+			t.Errorf("ExperienceLevelDescriptions missing entry for %q", level)
+			continue
+		}
+		if info.Title == "" || info.Description == "" {
+			t.Errorf("ExperienceLevelDescriptions[%q] has an empty title or description", level)
+		}
+	}
+}
+
+// TestStrictPresetIncludesFileLifecycleHooks tests that the strict preset asks for
+// fileCreated and fileDeleted hooks and documents them in the system prompt.
+func TestStrictPresetIncludesFileLifecycleHooks(t *testing.T) {
+	hooks := HookPresetDescriptions[HookPresetStrict].Hooks
+	for _, name := range []string{"test-on-create", "cleanup-on-delete"} {
+		found := false
+		for _, h := range hooks {
+			if h == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("strict preset should include %q hook, got %v", name, hooks)
+		}
+	}
+
+	prompt := GetOutputsSystemPrompt(ExperienceNovice, HookPresetStrict)
+	for _, trigger := range []string{`"type": "fileCreated"`, `"type": "fileDeleted"`} {
+		if !strings.Contains(prompt, trigger) {
+			t.Errorf("strict outputs prompt should contain an example with %s", trigger)
+		}
+	}
+
+	// The examples are appended once, even alongside the full example catalog
+	for name, p := range map[string]string{
+		"outputs prompt":        prompt,
+		"hooks prompt + preset": HooksSystemPrompt() + getHookPresetGuidance(HookPresetStrict),
+	} {
+		if n := strings.Count(p, "#### test-on-create.kiro.hook"); n != 1 {
+			t.Errorf("%s contains the file lifecycle examples %d times, want 1", name, n)
+		}
+	}
+}
+
 // TestValidExperienceLevels tests that ValidExperienceLevels returns all levels.
 func TestValidExperienceLevels(t *testing.T) {
 	levels := ValidExperienceLevels()
@@ -589,3 +638,64 @@ func TestProperty3_QuestionsIncludeExactlyThreeExamples(t *testing.T) {
 		t.Errorf("Property 3 (Questions Include Exactly Three Examples) failed: %v", err)
 	}
 }
+
+// TestInferExperienceLevel tests that jargon density picks the experience level.
+func TestInferExperienceLevel(t *testing.T) {
+	tests := []struct {
+		name string
+		idea string
+		want string
+	}{
+		{
+			name: "plain language",
+			idea: "An app where my family can share recipes and plan what to cook each week",
+			want: ExperienceBeginner,
+		},
+		{
+			name: "single technical term",
+			idea: "A todo list with a REST API so I can add tasks from my phone and my laptop",
+			want: ExperienceNovice,
+		},
+		{
+			name: "jargon heavy",
+			idea: "Event-driven microservices with CQRS and event sourcing, deployed on Kubernetes with OAuth and JWT",
+			want: ExperienceExpert,
+		},
+		{
+			name: "short but dense",
+			idea: "GraphQL gateway with caching",
+			want: ExperienceExpert,
+		},
+		{
+			name: "plural terms count",
+			idea: "A dashboard that calls a few APIs to show the weather and train times for my commute home",
+			want: ExperienceNovice,
+		},
+		{
+			name: "empty idea",
+			idea: "   ",
+			want: ExperienceNovice,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := InferExperienceLevel(tt.idea); got != tt.want {
+				t.Errorf("InferExperienceLevel(%q) = %q, want %q", tt.idea, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestResolveExperienceLevel tests that only auto is resolved and that auto is never a stored level.
+func TestResolveExperienceLevel(t *testing.T) {
+	if IsValidExperienceLevel(ExperienceAuto) {
+		t.Error("auto must not be a valid stored experience level")
+	}
+	if got := ResolveExperienceLevel(ExperienceAuto, "Kubernetes microservices with CQRS"); got != ExperienceExpert {
+		t.Errorf("ResolveExperienceLevel(auto) = %q, want expert", got)
+	}
+	if got := ResolveExperienceLevel(ExperienceBeginner, "Kubernetes microservices with CQRS"); got != ExperienceBeginner {
+		t.Errorf("ResolveExperienceLevel(beginner) = %q, want beginner", got)
+	}
+}
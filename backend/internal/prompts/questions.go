@@ -2,13 +2,21 @@
 // Kiro project files with experience-level adaptation.
 package prompts
 
-import "fmt"
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
 
 // Experience level constants
 const (
 	ExperienceBeginner = "beginner"
 	ExperienceNovice   = "novice"
 	ExperienceExpert   = "expert"
+
+	// ExperienceAuto asks for the level to be inferred from the project idea.
+	// It is resolved before generation and is never stored.
+	ExperienceAuto = "auto"
 )
 
 // ForbiddenBeginnerTerms are technical terms that MUST NOT appear in beginner questions.
@@ -35,6 +43,53 @@ var ForbiddenBeginnerTerms = []string{
 // JargonTerms is an alias for backward compatibility
 var JargonTerms = ForbiddenBeginnerTerms
 
+// jargonPatterns match each jargon term as a whole word, case-insensitively.
+var jargonPatterns = func() []*regexp.Regexp {
+	seen := make(map[string]bool)
+	var patterns []*regexp.Regexp
+	for _, term := range JargonTerms {
+		key := strings.ToLower(term)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		patterns = append(patterns, regexp.MustCompile(`(?i)\b`+regexp.QuoteMeta(term)+`s?\b`))
+	}
+	return patterns
+}()
+
+// Jargon thresholds for experience level inference
+const (
+	expertJargonTerms   = 3  // distinct jargon terms that mark an expert idea
+	expertJargonPercent = 15 // jargon terms per 100 words that mark an expert idea
+)
+
+// InferExperienceLevel guesses the user's experience level from the jargon
+// density of their project idea: no jargon suggests a beginner, heavy jargon
+// an expert, and anything in between a novice.
+func InferExperienceLevel(projectIdea string) string {
+	words := len(strings.Fields(projectIdea))
+	if words == 0 {
+		return ExperienceNovice
+	}
+
+	hits := 0
+	for _, p := range jargonPatterns {
+		if p.MatchString(projectIdea) {
+			hits++
+		}
+	}
+
+	switch {
+	case hits == 0:
+		return ExperienceBeginner
+	case hits >= expertJargonTerms || hits*100/words >= expertJargonPercent:
+		return ExperienceExpert
+	default:
+		return ExperienceNovice
+	}
+}
+
 // QuestionsSystemPrompt returns the system prompt for question generation
 // adapted to the user's experience level.
 func QuestionsSystemPrompt(experienceLevel string) string {
@@ -236,3 +291,37 @@ func getExperienceLevelDescription(level string) string {
 		return "some experience, understands basic concepts"
 	}
 }
+
+// ExamplesSystemPrompt returns the system prompt for regenerating the example
+// answers of a single question, adapted to the user's experience level.
+func ExamplesSystemPrompt(experienceLevel string) string {
+	basePrompt := `You are helping a developer answer a planning question about their project by suggesting example answers.
+
+## Your Role
+Suggest exactly 3 clickable example answers for the given question.
+- Examples should be realistic, helpful answers the user might give
+- Examples should match the user's experience level
+- Examples should cover different common scenarios
+- Examples should be concise but complete enough to be useful
+- Examples must be different from each other
+
+## Response Format
+Return ONLY valid JSON, no markdown code blocks:
+{"examples": ["Example 1", "Example 2", "Example 3"]}
+`
+
+	levelGuidance := getLevelGuidance(experienceLevel)
+	return basePrompt + "\n" + levelGuidance
+}
+
+// BuildExamplesUserPrompt builds the user prompt for regenerating a question's example answers.
+func BuildExamplesUserPrompt(question, projectIdea, experienceLevel string) string {
+	levelDesc := getExperienceLevelDescription(experienceLevel)
+	return fmt.Sprintf(`Project Idea: %s
+
+User Experience Level: %s (%s)
+
+Question: %s
+
+Suggest 3 new example answers to this question for this project.`, projectIdea, experienceLevel, levelDesc, question)
+}
@@ -0,0 +1,18 @@
+package ratelimit
+
+import "context"
+
+// exemptKey is the context key marking a request as exempt from rate limits.
+type exemptKey struct{}
+
+// WithExemption marks ctx so that callers checking IsExempt skip rate limiting.
+// It is set only for requests authenticated as internal tooling.
+func WithExemption(ctx context.Context) context.Context {
+	return context.WithValue(ctx, exemptKey{}, true)
+}
+
+// IsExempt reports whether ctx was marked with WithExemption.
+func IsExempt(ctx context.Context) bool {
+	exempt, _ := ctx.Value(exemptKey{}).(bool)
+	return exempt
+}
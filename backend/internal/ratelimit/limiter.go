@@ -1,11 +1,11 @@
 package ratelimit
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
 	"log/slog"
 	"sync"
 	"time"
+
+	"better-kiro-prompts/internal/privacy"
 )
 
 const (
@@ -104,53 +104,35 @@ func NewRatingLimiterWithLogger(log *slog.Logger) *Limiter {
 // Returns true if allowed, false if rate limited.
 // Also returns the duration until the rate limit resets.
 func (l *Limiter) Allow(ip string) (bool, time.Duration) {
+	return l.AllowN(ip, 1)
+}
+
+// AllowN is like Allow for a request that counts as n requests, such as a
+// batch. It is allowed only if all n fit in the current window; a denied
+// request counts nothing.
+func (l *Limiter) AllowN(ip string, n int) (bool, time.Duration) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
 	// Hash IP for privacy in logs
-	ipHash := hashIP(ip)
+	ipHash := privacy.HashIP(ip)
 
 	now := l.now()
+	count, windowStart := 0, now
 	state, exists := l.store[ip]
-
-	if !exists {
-		// First request from this IP
-		l.store[ip] = &clientState{
-			count:       1,
-			windowStart: now,
-		}
-		if l.log != nil {
-			l.log.Debug("rate_limit_allowed",
-				slog.String("ip_hash", ipHash),
-				slog.Int("remaining", l.limit-1),
-			)
-		}
-		return true, 0
-	}
-
-	// Check if window has expired
-	windowEnd := state.windowStart.Add(l.window)
-	if now.After(windowEnd) {
-		// Reset window
-		state.count = 1
-		state.windowStart = now
-		if l.log != nil {
-			l.log.Debug("rate_limit_allowed",
-				slog.String("ip_hash", ipHash),
-				slog.Int("remaining", l.limit-1),
-			)
-		}
-		return true, 0
+	if exists && !now.After(state.windowStart.Add(l.window)) {
+		// Window still active
+		count, windowStart = state.count, state.windowStart
 	}
 
-	// Window still active
-	if state.count >= l.limit {
+	if count+n > l.limit {
 		// Rate limited - return time until reset
-		retryAfter := windowEnd.Sub(now)
+		retryAfter := windowStart.Add(l.window).Sub(now)
 		if l.log != nil {
 			l.log.Warn("rate_limit_denied",
 				slog.String("ip_hash", ipHash),
-				slog.Int("count", state.count),
+				slog.Int("count", count),
+				slog.Int("requested", n),
 				slog.Int("limit", l.limit),
 				slog.Duration("retry_after", retryAfter),
 			)
@@ -158,8 +140,13 @@ func (l *Limiter) Allow(ip string) (bool, time.Duration) {
 		return false, retryAfter
 	}
 
-	// Allow request and increment count
-	state.count++
+	// Allow request, starting a new window if the last one expired
+	if !exists {
+		state = &clientState{}
+		l.store[ip] = state
+	}
+	state.count = count + n
+	state.windowStart = windowStart
 	if l.log != nil {
 		l.log.Debug("rate_limit_allowed",
 			slog.String("ip_hash", ipHash),
@@ -192,6 +179,25 @@ func (l *Limiter) Remaining(ip string) int {
 	return remaining
 }
 
+// ResetAt returns when the current window for the given IP ends and its
+// full allowance is restored, or the zero time when the IP has no requests
+// in an active window.
+func (l *Limiter) ResetAt(ip string) time.Time {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	state, exists := l.store[ip]
+	if !exists {
+		return time.Time{}
+	}
+
+	windowEnd := state.windowStart.Add(l.window)
+	if l.now().After(windowEnd) {
+		return time.Time{}
+	}
+	return windowEnd
+}
+
 // Reset clears the rate limit state for a given IP.
 func (l *Limiter) Reset(ip string) {
 	l.mu.Lock()
@@ -206,15 +212,27 @@ func (l *Limiter) ResetAll() {
 	l.store = make(map[string]*clientState)
 }
 
+// Limit returns the number of requests allowed per window.
+func (l *Limiter) Limit() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.limit
+}
+
+// SetLimit changes the number of requests allowed per window. Existing client
+// windows keep their counts and are checked against the new limit.
+func (l *Limiter) SetLimit(limit int) {
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limit = limit
+}
+
 // setNow sets a custom time function (for testing).
 func (l *Limiter) setNow(fn func() time.Time) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 	l.now = fn
 }
-
-// hashIP creates a privacy-preserving hash of an IP address for logging.
-func hashIP(ip string) string {
-	hash := sha256.Sum256([]byte(ip))
-	return hex.EncodeToString(hash[:8]) // First 8 bytes (16 hex chars)
-}
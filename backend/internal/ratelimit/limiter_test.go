@@ -152,3 +152,55 @@ func TestRemaining_Property_DecreasesWithRequests(t *testing.T) {
 		t.Errorf("Property failed: remaining should decrease with each request: %v", err)
 	}
 }
+
+func TestAllowN_CountsWholeRequest(t *testing.T) {
+	limiter := NewLimiter()
+	now := time.Now()
+	limiter.setNow(func() time.Time { return now })
+
+	if allowed, _ := limiter.AllowN("1.2.3.4", 7); !allowed {
+		t.Fatal("expected a request of 7 to fit in a limit of 10")
+	}
+	// A request that does not fit is denied and counts nothing
+	if allowed, retryAfter := limiter.AllowN("1.2.3.4", 4); allowed || retryAfter <= 0 {
+		t.Fatalf("expected a request of 4 to be denied with a retry time, got allowed=%v retryAfter=%v", allowed, retryAfter)
+	}
+	if got := limiter.Remaining("1.2.3.4"); got != 3 {
+		t.Errorf("Remaining() = %d after a denied request, want 3", got)
+	}
+
+	// More than the whole limit never fits, and does not start a window
+	if allowed, _ := limiter.AllowN("5.6.7.8", 11); allowed {
+		t.Error("expected a request larger than the limit to be denied")
+	}
+	if got := limiter.Remaining("5.6.7.8"); got != 10 {
+		t.Errorf("Remaining() = %d after an oversized request, want 10", got)
+	}
+
+	now = now.Add(DefaultWindow + time.Second)
+	if allowed, _ := limiter.AllowN("1.2.3.4", 10); !allowed {
+		t.Error("expected the full limit to be available in a new window")
+	}
+}
+
+func TestResetAt(t *testing.T) {
+	limiter := NewLimiter()
+	now := time.Now()
+	limiter.setNow(func() time.Time { return now })
+
+	if got := limiter.ResetAt("1.2.3.4"); !got.IsZero() {
+		t.Errorf("ResetAt() = %v before any request, want zero", got)
+	}
+
+	limiter.Allow("1.2.3.4")
+	now = now.Add(10 * time.Minute)
+	limiter.Allow("1.2.3.4")
+	if got, want := limiter.ResetAt("1.2.3.4"), now.Add(DefaultWindow-10*time.Minute); !got.Equal(want) {
+		t.Errorf("ResetAt() = %v, want the end of the window that began with the first request, %v", got, want)
+	}
+
+	now = now.Add(DefaultWindow)
+	if got := limiter.ResetAt("1.2.3.4"); !got.IsZero() {
+		t.Errorf("ResetAt() = %v after the window expired, want zero", got)
+	}
+}
@@ -0,0 +1,76 @@
+package sanitize
+
+import (
+	"regexp"
+	"strings"
+)
+
+// MaskedSecret replaces secrets found by MaskSecrets.
+const MaskedSecret = "[REDACTED]"
+
+// secretPatterns match credentials that are commonly pasted by accident:
+// well-known token formats plus key=value assignments to secret-looking names.
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),                                   // AWS access key ID
+	regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`),                                  // GitHub token
+	regexp.MustCompile(`\bgithub_pat_[A-Za-z0-9_]{22,}\b`),                                // GitHub fine-grained token
+	regexp.MustCompile(`\bsk-(?:proj-|ant-)?[A-Za-z0-9_-]{20,}`),                          // OpenAI-style secret key
+	regexp.MustCompile(`\b[rs]k_(?:live|test)_[A-Za-z0-9]{16,}\b`),                        // Stripe key
+	regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`),                                 // Slack token
+	regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`),                                       // Google API key
+	regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`), // JWT
+	regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`),                              // PEM private key
+	regexp.MustCompile(`(?i)\b(?:api[_-]?key|secret|token|password|passwd)\s*[:=]\s*["']?[^\s"']{8,}`),
+}
+
+// ContainsSecret reports whether input contains something that looks like a
+// credential.
+func ContainsSecret(input string) bool {
+	for _, re := range secretPatterns {
+		if re.MatchString(input) {
+			return true
+		}
+	}
+	return false
+}
+
+// MaskSecrets replaces everything that looks like a credential with
+// MaskedSecret.
+func MaskSecrets(input string) string {
+	for _, re := range secretPatterns {
+		input = re.ReplaceAllString(input, MaskedSecret)
+	}
+	return input
+}
+
+// WordMasker replaces whole-word, case-insensitive occurrences of a fixed
+// set of words with asterisks of the same length. Its pattern is compiled
+// once, so it can be reused for every input. A nil WordMasker masks nothing.
+type WordMasker struct {
+	re *regexp.Regexp
+}
+
+// NewWordMasker compiles a masker for words. Empty words are ignored; with
+// none left it returns nil.
+func NewWordMasker(words []string) *WordMasker {
+	quoted := make([]string, 0, len(words))
+	for _, w := range words {
+		if w = strings.TrimSpace(w); w != "" {
+			quoted = append(quoted, regexp.QuoteMeta(w))
+		}
+	}
+	if len(quoted) == 0 {
+		return nil
+	}
+	return &WordMasker{re: regexp.MustCompile(`(?i)\b(?:` + strings.Join(quoted, "|") + `)\b`)}
+}
+
+// Mask returns input with every listed word masked.
+func (m *WordMasker) Mask(input string) string {
+	if m == nil {
+		return input
+	}
+	return m.re.ReplaceAllStringFunc(input, func(match string) string {
+		return strings.Repeat("*", len([]rune(match)))
+	})
+}
@@ -0,0 +1,51 @@
+// Package scancatalog lists the scanning tools and languages the scanner
+// supports. It has no project dependencies, so configuration validation and
+// the scanner read the same lists and a new tool is accepted everywhere once.
+package scancatalog
+
+import "slices"
+
+// Tool describes a scanning tool and when it runs.
+type Tool struct {
+	// Name is the tool name used in configuration and reported in results.
+	Name string
+	// Languages selects the tool when any of them is detected; ignored for universal tools.
+	Languages []string
+	// Universal tools run for every repository.
+	Universal bool
+	// Probe is the command used to check the tool is installed;
+	// defaults to "<name> --version".
+	Probe []string
+}
+
+// Languages lists the languages the scanner detects, in report order.
+var Languages = []string{"go", "javascript", "typescript", "python", "java", "ruby", "php", "c", "cpp", "rust"}
+
+// Tools lists every scanning tool, in the order they run.
+var Tools = []Tool{
+	// Universal tools (always run)
+	{Name: "trivy", Universal: true},
+	{Name: "semgrep", Universal: true},
+	{Name: "trufflehog", Universal: true},
+	{Name: "gitleaks", Universal: true, Probe: []string{"gitleaks", "version"}},
+
+	// Language-specific tools
+	{Name: "govulncheck", Languages: []string{"go"}},
+	{Name: "bandit", Languages: []string{"python"}},
+	{Name: "pip-audit", Languages: []string{"python"}},
+	{Name: "safety", Languages: []string{"python"}},
+	{Name: "npm-audit", Languages: []string{"javascript", "typescript"}, Probe: []string{"npm", "--version"}},
+	{Name: "cargo-audit", Languages: []string{"rust"}, Probe: []string{"cargo", "audit", "--version"}},
+	{Name: "bundler-audit", Languages: []string{"ruby"}, Probe: []string{"bundle-audit", "--version"}},
+	{Name: "brakeman", Languages: []string{"ruby"}},
+}
+
+// IsTool reports whether name is a catalogued scanning tool.
+func IsTool(name string) bool {
+	return slices.ContainsFunc(Tools, func(t Tool) bool { return t.Name == name })
+}
+
+// IsLanguage reports whether lang is a language the scanner detects.
+func IsLanguage(lang string) bool {
+	return slices.Contains(Languages, lang)
+}
@@ -0,0 +1,40 @@
+package scancatalog
+
+import "testing"
+
+func TestTools_WellFormed(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, tool := range Tools {
+		if seen[tool.Name] {
+			t.Errorf("tool %q is listed twice", tool.Name)
+		}
+		seen[tool.Name] = true
+
+		if !tool.Universal && len(tool.Languages) == 0 {
+			t.Errorf("tool %q is neither universal nor tied to a language", tool.Name)
+		}
+		for _, lang := range tool.Languages {
+			if !IsLanguage(lang) {
+				t.Errorf("tool %q selects unknown language %q", tool.Name, lang)
+			}
+		}
+	}
+}
+
+func TestIsTool(t *testing.T) {
+	if !IsTool("gitleaks") {
+		t.Error("IsTool(gitleaks) = false, want true")
+	}
+	if IsTool("not-a-tool") {
+		t.Error("IsTool(not-a-tool) = true, want false")
+	}
+}
+
+func TestIsLanguage(t *testing.T) {
+	if !IsLanguage("rust") {
+		t.Error("IsLanguage(rust) = false, want true")
+	}
+	if IsLanguage("cobol") {
+		t.Error("IsLanguage(cobol) = true, want false")
+	}
+}
@@ -0,0 +1,176 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"better-kiro-prompts/internal/logger"
+)
+
+// Acknowledgement limits.
+const (
+	MaxAckRuleIDLength = 200
+	MaxAckReasonLength = 1000
+)
+
+// Acknowledgement accepts the findings of one rule in one file of a
+// repository. Matching findings in every scan of the repository are listed
+// under AcknowledgedFindings and left out of the score.
+type Acknowledgement struct {
+	RepoURL   string    `json:"repo_url"`
+	RuleID    string    `json:"rule_id"`
+	FilePath  string    `json:"file_path"` // Relative to the repository root
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AckRequest is a request to acknowledge a finding. FilePath is the path
+// reported in the finding, which is relative to Subdir for subdirectory scans.
+type AckRequest struct {
+	RepoURL  string `json:"repo_url"`
+	Subdir   string `json:"subdir,omitempty"`
+	RuleID   string `json:"rule_id"`
+	FilePath string `json:"file_path"`
+	Reason   string `json:"reason"`
+}
+
+// ackKey identifies the findings an acknowledgement covers.
+type ackKey struct {
+	ruleID   string
+	filePath string
+}
+
+// Acknowledge records req, replacing the reason of an existing
+// acknowledgement for the same repository, rule and file.
+func (s *Service) Acknowledge(ctx context.Context, req AckRequest) (*Acknowledgement, error) {
+	ack, verr := normalizeAck(req)
+	if verr != nil {
+		return nil, verr
+	}
+
+	query := `
+		INSERT INTO acknowledgements (repo_url, rule_id, file_path, reason, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (repo_url, rule_id, file_path)
+		DO UPDATE SET reason = EXCLUDED.reason, created_at = EXCLUDED.created_at
+	`
+	if _, err := s.db.ExecContext(ctx, query, ack.RepoURL, ack.RuleID, ack.FilePath, ack.Reason, ack.CreatedAt); err != nil {
+		s.log.Error("scan_ack_failed",
+			slog.String("request_id", logger.GetRequestID(ctx)),
+			slog.String("repo_url", ack.RepoURL),
+			slog.String("error", err.Error()),
+		)
+		return nil, fmt.Errorf("failed to store acknowledgement: %w", err)
+	}
+
+	s.log.Info("scan_ack_recorded",
+		slog.String("request_id", logger.GetRequestID(ctx)),
+		slog.String("repo_url", ack.RepoURL),
+		slog.String("rule_id", ack.RuleID),
+		slog.String("file_path", ack.FilePath),
+	)
+	return ack, nil
+}
+
+// normalizeAck validates req and returns the acknowledgement to store, with
+// the repository URL in canonical form and the file path made relative to
+// the repository root.
+func normalizeAck(req AckRequest) (*Acknowledgement, *ValidationError) {
+	repoURL := NormalizeGitHubURL(req.RepoURL)
+	if verr := ValidateGitHubURL(repoURL); verr != nil {
+		return nil, verr
+	}
+	subdir, verr := NormalizeSubdir(req.Subdir)
+	if verr != nil {
+		return nil, verr
+	}
+
+	ruleID := strings.TrimSpace(req.RuleID)
+	if ruleID == "" || utf8.RuneCountInString(ruleID) > MaxAckRuleIDLength {
+		return nil, &ValidationError{
+			Code:    "INVALID_RULE_ID",
+			Message: fmt.Sprintf("rule_id is required and must be at most %d characters", MaxAckRuleIDLength),
+			Field:   "rule_id",
+		}
+	}
+
+	filePath, verr := NormalizeSubdir(req.FilePath)
+	if verr != nil {
+		verr.Field = "file_path"
+		verr.Example = "internal/api/router.go"
+		return nil, verr
+	}
+	if filePath == "" {
+		return nil, &ValidationError{
+			Code:    "INVALID_FILE_PATH",
+			Message: "file_path is required",
+			Field:   "file_path",
+			Example: "internal/api/router.go",
+		}
+	}
+
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" || utf8.RuneCountInString(reason) > MaxAckReasonLength {
+		return nil, &ValidationError{
+			Code:    "INVALID_REASON",
+			Message: fmt.Sprintf("reason is required and must be at most %d characters", MaxAckReasonLength),
+			Field:   "reason",
+		}
+	}
+
+	return &Acknowledgement{
+		RepoURL:   repoURL,
+		RuleID:    ruleID,
+		FilePath:  path.Join(subdir, filePath),
+		Reason:    reason,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// loadAcknowledgements returns the acknowledgement reasons for repoURL.
+func (s *Service) loadAcknowledgements(ctx context.Context, repoURL string) (map[ackKey]string, error) {
+	query := `SELECT rule_id, file_path, reason FROM acknowledgements WHERE repo_url = $1`
+	rows, err := s.db.QueryContext(ctx, query, repoURL)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	acks := make(map[ackKey]string)
+	for rows.Next() {
+		var key ackKey
+		var reason string
+		if err := rows.Scan(&key.ruleID, &key.filePath, &reason); err != nil {
+			return nil, err
+		}
+		acks[key] = reason
+	}
+	return acks, rows.Err()
+}
+
+// applyAcknowledgements moves the findings of job covered by acks to
+// AcknowledgedFindings and marks them acknowledged. Findings without a rule
+// ID are never covered.
+func applyAcknowledgements(job *ScanJob, acks map[ackKey]string) {
+	if len(acks) == 0 {
+		return
+	}
+
+	kept := make([]Finding, 0, len(job.Findings))
+	for _, f := range job.Findings {
+		reason, ok := acks[ackKey{ruleID: f.RuleID, filePath: path.Join(job.Subdir, f.FilePath)}]
+		if !ok || f.RuleID == "" {
+			kept = append(kept, f)
+			continue
+		}
+		f.Acknowledged = true
+		f.AckReason = reason
+		job.AcknowledgedFindings = append(job.AcknowledgedFindings, f)
+	}
+	job.Findings = kept
+}
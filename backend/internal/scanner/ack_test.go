@@ -0,0 +1,91 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestApplyAcknowledgements_ExcludedFromScoreButListed(t *testing.T) {
+	job := &ScanJob{
+		Status: StatusCompleted,
+		Subdir: "services/api",
+		Findings: []Finding{
+			{ID: "1", Severity: SeverityCritical, RuleID: "go.sql-injection", FilePath: "db.go"},
+			{ID: "2", Severity: SeverityLow, RuleID: "go.weak-hash", FilePath: "hash.go"},
+			{ID: "3", Severity: SeverityHigh, FilePath: "db.go"}, // No rule ID, never acknowledged
+		},
+	}
+	acks := map[ackKey]string{
+		{ruleID: "go.sql-injection", filePath: "services/api/db.go"}: "Input is a constant",
+		{ruleID: "go.weak-hash", filePath: "hash.go"}:                "Path outside the scanned subdirectory",
+	}
+	unacknowledged := (&Service{}).Score(&ScanJob{Status: StatusCompleted, Findings: job.Findings})
+
+	applyAcknowledgements(job, acks)
+
+	if len(job.Findings) != 2 || job.Findings[0].ID != "2" || job.Findings[1].ID != "3" {
+		t.Errorf("Findings = %+v, want findings 2 and 3", job.Findings)
+	}
+	if len(job.AcknowledgedFindings) != 1 {
+		t.Fatalf("AcknowledgedFindings = %+v, want finding 1", job.AcknowledgedFindings)
+	}
+	if acked := job.AcknowledgedFindings[0]; acked.ID != "1" || !acked.Acknowledged || acked.AckReason != "Input is a constant" {
+		t.Errorf("AcknowledgedFindings[0] = %+v", acked)
+	}
+
+	score := (&Service{}).Score(job)
+	if want := ScoreFindings(job.Findings); *score != want {
+		t.Errorf("Score() = %+v, want %+v from the unacknowledged findings", *score, want)
+	}
+	if score.Score <= unacknowledged.Score {
+		t.Errorf("Score() = %d, want above %d once the critical is acknowledged", score.Score, unacknowledged.Score)
+	}
+}
+
+func TestAcknowledge_StoresNormalizedAcknowledgement(t *testing.T) {
+	db := &recordingDB{}
+	s := NewService(nil, nil, "")
+	s.db = db
+
+	ack, err := s.Acknowledge(context.Background(), AckRequest{
+		RepoURL:  "github.com/Owner/Repo.git",
+		Subdir:   "/services/api/",
+		RuleID:   " go.sql-injection ",
+		FilePath: "./db.go",
+		Reason:   "Input is a constant",
+	})
+	if err != nil {
+		t.Fatalf("Acknowledge() error = %v", err)
+	}
+	if ack.RepoURL != "https://github.com/owner/repo" || ack.RuleID != "go.sql-injection" || ack.FilePath != "services/api/db.go" {
+		t.Errorf("Acknowledge() = %+v", ack)
+	}
+	if len(db.execs) != 1 || db.execs[0].args[2] != "services/api/db.go" {
+		t.Errorf("stored %+v, want one upsert", db.execs)
+	}
+}
+
+func TestAcknowledge_RejectsInvalidRequests(t *testing.T) {
+	valid := AckRequest{RepoURL: "https://github.com/owner/repo", RuleID: "rule", FilePath: "main.go", Reason: "Accepted"}
+	tests := map[string]func(*AckRequest){
+		"bad repo":        func(r *AckRequest) { r.RepoURL = "https://example.com/owner/repo" },
+		"missing rule":    func(r *AckRequest) { r.RuleID = " " },
+		"missing file":    func(r *AckRequest) { r.FilePath = "" },
+		"escaping file":   func(r *AckRequest) { r.FilePath = "../secrets.env" },
+		"missing reason":  func(r *AckRequest) { r.Reason = "" },
+		"escaping subdir": func(r *AckRequest) { r.Subdir = "../other" },
+	}
+	for name, mutate := range tests {
+		req := valid
+		mutate(&req)
+		s := NewService(nil, nil, "")
+		s.db = &recordingDB{}
+
+		_, err := s.Acknowledge(context.Background(), req)
+		var verr *ValidationError
+		if !errors.As(err, &verr) {
+			t.Errorf("%s: Acknowledge() error = %v, want a ValidationError", name, err)
+		}
+	}
+}
@@ -2,7 +2,6 @@ package scanner
 
 import (
 	"sort"
-	"strings"
 
 	"github.com/google/uuid"
 )
@@ -32,10 +31,26 @@ type Finding struct {
 	Tool        string `json:"tool"`
 	FilePath    string `json:"file_path"`
 	LineNumber  *int   `json:"line_number,omitempty"`
+	EndLine     *int   `json:"end_line,omitempty"`     // Last line of a multi-line range
+	StartColumn *int   `json:"start_column,omitempty"` // 1-based
+	EndColumn   *int   `json:"end_column,omitempty"`   // 1-based
 	Description string `json:"description"`
 	Remediation string `json:"remediation,omitempty"`
 	CodeExample string `json:"code_example,omitempty"`
 	RuleID      string `json:"rule_id,omitempty"`
+	Confidence  string `json:"confidence,omitempty"`
+
+	// Stable across rescans of the repository; see FindingFingerprint
+	Fingerprint string `json:"fingerprint,omitempty"`
+
+	// Code around the finding, starting at SnippetStartLine; stored only
+	// when scanner.include_snippets is on
+	Snippet          string `json:"snippet,omitempty"`
+	SnippetStartLine *int   `json:"snippet_start_line,omitempty"`
+
+	// Set on findings covered by an Acknowledgement
+	Acknowledged bool   `json:"acknowledged,omitempty"`
+	AckReason    string `json:"ack_reason,omitempty"`
 }
 
 // Aggregator aggregates and deduplicates findings from multiple tools.
@@ -71,37 +86,34 @@ func (a *Aggregator) convertRawFinding(raw RawFinding, tool string) Finding {
 		Tool:        tool,
 		FilePath:    raw.FilePath,
 		Description: raw.Description,
-		Severity:    a.normalizeSeverity(raw.Severity),
+		Severity:    NormalizeSeverity(tool, raw.Severity),
 		RuleID:      raw.RuleID,
+		Confidence:  NormalizeConfidence(tool, raw.Confidence),
 	}
 
 	if raw.LineNumber > 0 {
 		lineNum := raw.LineNumber
 		finding.LineNumber = &lineNum
+
+		// A range only means something alongside its start line
+		finding.EndLine = positiveInt(raw.EndLine)
+		finding.StartColumn = positiveInt(raw.StartColumn)
+		finding.EndColumn = positiveInt(raw.EndColumn)
+	}
+	if raw.Snippet != "" {
+		finding.Snippet = raw.Snippet
+		finding.SnippetStartLine = positiveInt(raw.SnippetStartLine)
 	}
 
 	return finding
 }
 
-// normalizeSeverity normalizes severity strings to standard values.
-func (a *Aggregator) normalizeSeverity(severity string) string {
-	severity = strings.ToLower(strings.TrimSpace(severity))
-
-	switch severity {
-	case "critical", "crit":
-		return SeverityCritical
-	case "high", "error":
-		return SeverityHigh
-	case "medium", "moderate", "warning", "warn":
-		return SeverityMedium
-	case "low":
-		return SeverityLow
-	case "info", "informational", "note":
-		return SeverityInfo
-	default:
-		// Default to medium if unknown
-		return SeverityMedium
+// positiveInt returns a pointer to n, or nil when n is not positive.
+func positiveInt(n int) *int {
+	if n <= 0 {
+		return nil
 	}
+	return &n
 }
 
 // Deduplicate removes duplicate findings based on file, line, and description.
@@ -151,6 +163,16 @@ func (a *Aggregator) AggregateAndProcess(results []ToolResult) []Finding {
 	return findings
 }
 
+// LimitFindings keeps at most limit findings from a list ranked by
+// RankBySeverity, so the least severe are dropped. It returns the kept
+// findings and how many were dropped; a limit of zero or less keeps all.
+func (a *Aggregator) LimitFindings(findings []Finding, limit int) ([]Finding, int) {
+	if limit <= 0 || len(findings) <= limit {
+		return findings, 0
+	}
+	return findings[:limit], len(findings) - limit
+}
+
 // GetUniqueFiles returns a list of unique file paths from findings.
 func (a *Aggregator) GetUniqueFiles(findings []Finding) []string {
 	seen := make(map[string]bool)
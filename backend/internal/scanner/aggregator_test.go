@@ -9,9 +9,7 @@ import (
 // Unit Tests for Aggregator
 // =============================================================================
 
-func TestAggregator_normalizeSeverity(t *testing.T) {
-	a := NewAggregator()
-
+func TestNormalizeSeverity_WithoutTool(t *testing.T) {
 	tests := []struct {
 		input string
 		want  string
@@ -50,9 +48,9 @@ func TestAggregator_normalizeSeverity(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.input, func(t *testing.T) {
-			got := a.normalizeSeverity(tt.input)
+			got := NormalizeSeverity("", tt.input)
 			if got != tt.want {
-				t.Errorf("normalizeSeverity(%q) = %q, want %q", tt.input, got, tt.want)
+				t.Errorf("NormalizeSeverity(\"\", %q) = %q, want %q", tt.input, got, tt.want)
 			}
 		})
 	}
@@ -107,6 +105,32 @@ func TestAggregator_Aggregate(t *testing.T) {
 	}
 }
 
+func TestAggregator_AggregateKeepsRange(t *testing.T) {
+	a := NewAggregator()
+
+	findings := a.Aggregate([]ToolResult{{
+		Tool: "semgrep",
+		Findings: []RawFinding{
+			{FilePath: "main.go", LineNumber: 10, EndLine: 12, StartColumn: 3, EndColumn: 8, Description: "Range", Severity: "high"},
+			{FilePath: "main.go", LineNumber: 20, Description: "Single line", Severity: "high"},
+			{FilePath: "go.sum", EndLine: 4, StartColumn: 1, Description: "No start line", Severity: "high"},
+		},
+	}})
+
+	if len(findings) != 3 {
+		t.Fatalf("Expected 3 findings, got %d", len(findings))
+	}
+	r := findings[0]
+	if r.EndLine == nil || *r.EndLine != 12 || r.StartColumn == nil || *r.StartColumn != 3 || r.EndColumn == nil || *r.EndColumn != 8 {
+		t.Errorf("range = %v:%v-%v, want 12:3-8", r.EndLine, r.StartColumn, r.EndColumn)
+	}
+	for _, f := range findings[1:] {
+		if f.EndLine != nil || f.StartColumn != nil || f.EndColumn != nil {
+			t.Errorf("finding %q has a range, want none", f.Description)
+		}
+	}
+}
+
 func TestAggregator_Deduplicate(t *testing.T) {
 	a := NewAggregator()
 
@@ -342,9 +366,9 @@ func TestProperty8_FindingAggregationCompleteness(t *testing.T) {
 		}
 
 		for _, input := range severityInputs {
-			normalized := a.normalizeSeverity(input)
+			normalized := NormalizeSeverity("", input)
 			if !IsValidSeverity(normalized) {
-				t.Errorf("normalizeSeverity(%q) = %q is not valid", input, normalized)
+				t.Errorf("NormalizeSeverity(\"\", %q) = %q is not valid", input, normalized)
 			}
 		}
 	})
@@ -0,0 +1,417 @@
+package scanner
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"better-kiro-prompts/internal/logger"
+
+	"github.com/google/uuid"
+)
+
+// Batch limits.
+const (
+	// DefaultMaxBatchSize is the number of repositories allowed in one batch
+	// when no limit is configured.
+	DefaultMaxBatchSize = 10
+
+	// defaultBatchConcurrency is how many scans of a batch run at once when
+	// the per-client scan limit is disabled or does not apply.
+	defaultBatchConcurrency = 2
+
+	// batchRetryInterval is how long a batch waits before retrying a scan
+	// the per-client limit rejected.
+	batchRetryInterval = 5 * time.Second
+)
+
+// StatusRunning is the status of a batch with scans still in progress.
+// Batches are otherwise pending, completed or failed like their scans.
+const StatusRunning = "running"
+
+// BatchRequest represents a request to scan several repositories.
+type BatchRequest struct {
+	RepoURLs []string `json:"repo_urls"`
+
+	// IP hash of the requesting client for the concurrent scan limit; empty is not limited
+	ClientID string `json:"-"`
+}
+
+// ScanBatch is a group of scans started together, with their statuses and
+// findings aggregated.
+type ScanBatch struct {
+	ID        string     `json:"id"`
+	Status    string     `json:"status"`
+	CreatedAt time.Time  `json:"created_at"`
+	Jobs      []BatchJob `json:"jobs"`
+
+	// Number of jobs in each status
+	StatusCounts map[string]int `json:"status_counts"`
+	// Findings of every job by severity; acknowledged findings are not counted
+	SeverityCounts map[string]int `json:"severity_counts"`
+}
+
+// BatchJob summarizes one scan of a batch.
+type BatchJob struct {
+	ID             string         `json:"id"`
+	RepoURL        string         `json:"repo_url"`
+	Status         string         `json:"status"`
+	Error          string         `json:"error,omitempty"`
+	SeverityCounts map[string]int `json:"severity_counts"`
+	Score          *ScanScore     `json:"score,omitempty"`
+}
+
+// StartBatch validates every repository URL, stores a batch with a pending
+// scan for each repository and starts the scans in the background. Repeated
+// repositories are scanned once. The scans run a few at a time, within the
+// requesting client's concurrent scan limit, so a large batch queues instead
+// of being rejected or overwhelming the scanner.
+func (s *Service) StartBatch(ctx context.Context, req BatchRequest) (*ScanBatch, error) {
+	requestID := logger.GetRequestID(ctx)
+
+	if s.isDraining() {
+		s.log.Warn("scan_rejected_shutting_down",
+			slog.String("request_id", requestID),
+		)
+		return nil, ErrShuttingDown
+	}
+
+	repoURLs, verr := s.ValidateBatch(req.RepoURLs)
+	if verr != nil {
+		s.log.Warn("scan_batch_validation_failed",
+			slog.String("request_id", requestID),
+			slog.String("error", verr.Error()),
+		)
+		return nil, verr
+	}
+
+	batch := &ScanBatch{
+		ID:        uuid.New().String(),
+		CreatedAt: time.Now(),
+	}
+	jobs := make([]*ScanJob, len(repoURLs))
+	for i, repoURL := range repoURLs {
+		jobs[i] = &ScanJob{
+			ID:        uuid.New().String(),
+			Status:    StatusPending,
+			RepoURL:   repoURL,
+			CreatedAt: batch.CreatedAt,
+
+			SchemaVersion: FindingSchemaVersion,
+		}
+	}
+
+	if err := s.createBatch(ctx, batch, jobs); err != nil {
+		s.log.Error("scan_create_batch_failed",
+			slog.String("request_id", requestID),
+			slog.String("error", err.Error()),
+		)
+		return nil, fmt.Errorf("failed to create batch: %w", err)
+	}
+
+	s.log.Info("scan_batch_created",
+		slog.String("request_id", requestID),
+		slog.String("batch_id", batch.ID),
+		slog.Int("job_count", len(jobs)),
+	)
+
+	s.batches.Add(1)
+	go s.runBatch(batch.ID, jobs, req.ClientID)
+
+	summarizeBatch(batch, jobs)
+	return batch, nil
+}
+
+// ValidateBatch returns the canonical form of each repository URL, without
+// repeats, or a validation error naming the first invalid entry. StartBatch
+// runs it too; handlers call it first to size the rate limit charge.
+func (s *Service) ValidateBatch(urls []string) ([]string, *ValidationError) {
+	if len(urls) == 0 {
+		return nil, &ValidationError{
+			Code:    "EMPTY_BATCH",
+			Message: "repo_urls must list at least one repository",
+			Field:   "repo_urls",
+			Example: "https://github.com/owner/repo",
+		}
+	}
+	if len(urls) > s.maxBatchSize {
+		return nil, &ValidationError{
+			Code:    "BATCH_TOO_LARGE",
+			Message: fmt.Sprintf("repo_urls may list at most %d repositories", s.maxBatchSize),
+			Field:   "repo_urls",
+		}
+	}
+
+	seen := make(map[string]bool, len(urls))
+	repoURLs := make([]string, 0, len(urls))
+	for i, raw := range urls {
+		repoURL := NormalizeGitHubURL(raw)
+		if verr := ValidateGitHubURL(repoURL); verr != nil {
+			verr.Field = fmt.Sprintf("repo_urls[%d]", i)
+			verr.Message = fmt.Sprintf("repo_urls[%d]: %s", i, verr.Message)
+			return nil, verr
+		}
+		if !seen[repoURL] {
+			seen[repoURL] = true
+			repoURLs = append(repoURLs, repoURL)
+		}
+	}
+	return repoURLs, nil
+}
+
+// batchConcurrency returns how many scans of a batch may run at once.
+func (s *Service) batchConcurrency() int {
+	if s.maxScansPerClient > 0 {
+		return s.maxScansPerClient
+	}
+	return defaultBatchConcurrency
+}
+
+// runBatch starts the scans of a batch in order as slots free up, and
+// returns once they have all finished. When the service starts draining,
+// the scans not yet started are failed instead.
+func (s *Service) runBatch(batchID string, jobs []*ScanJob, clientID string) {
+	defer s.batches.Done()
+
+	slots := make(chan struct{}, s.batchConcurrency())
+	var wg sync.WaitGroup
+	for i, job := range jobs {
+		slots <- struct{}{}
+		ctx, ok := s.claimBatchScan(batchID, job, clientID)
+		if !ok {
+			<-slots
+			s.abortBatch(batchID, jobs[i:])
+			break
+		}
+		if ctx == nil {
+			// Deduplicated against a scan that is already running
+			<-slots
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			defer s.untrackScan(job.ID)
+			s.runJob(ctx, job)
+		}()
+	}
+	wg.Wait()
+
+	s.log.Info("scan_batch_complete",
+		slog.String("batch_id", batchID),
+		slog.Int("job_count", len(jobs)),
+	)
+}
+
+// claimBatchScan registers the scan of a batch job, waiting while the
+// client's other scans hold every slot of the per-client limit. It returns
+// the scan context, or nil when the job was replaced by a running scan of
+// the same repository, and false when the service is shutting down.
+func (s *Service) claimBatchScan(batchID string, job *ScanJob, clientID string) (context.Context, bool) {
+	for {
+		ctx, existing, err := s.claimScan(job.ID, job.RepoURL, "", clientID, time.Now())
+		switch {
+		case errors.Is(err, ErrShuttingDown):
+			return nil, false
+		case err != nil:
+			time.Sleep(batchRetryInterval)
+		case existing != nil:
+			s.replaceBatchJob(batchID, job.ID, existing.ID)
+			return nil, true
+		default:
+			return ctx, true
+		}
+	}
+}
+
+// replaceBatchJob points a batch at a running scan of the same repository
+// in place of its own pending job, which is removed.
+func (s *Service) replaceBatchJob(batchID, jobID, existingID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), drainWriteTimeout)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE scan_batch_jobs SET scan_job_id = $1 WHERE batch_id = $2 AND scan_job_id = $3`,
+		existingID, batchID, jobID)
+	if err == nil {
+		_, err = s.db.ExecContext(ctx, `DELETE FROM scan_jobs WHERE id = $1`, jobID)
+	}
+	if err != nil {
+		s.log.Error("scan_batch_replace_job_failed",
+			slog.String("batch_id", batchID),
+			slog.String("job_id", jobID),
+			slog.String("error", err.Error()),
+		)
+		_ = s.failJob(ctx, jobID, "Scan deduplicated: "+existingID)
+		return
+	}
+
+	s.log.Info("scan_deduplicated",
+		slog.String("batch_id", batchID),
+		slog.String("job_id", existingID),
+	)
+}
+
+// abortBatch fails batch jobs that never started because the service is
+// shutting down.
+func (s *Service) abortBatch(batchID string, jobs []*ScanJob) {
+	ctx, cancel := context.WithTimeout(context.Background(), drainWriteTimeout)
+	defer cancel()
+
+	for _, job := range jobs {
+		if err := s.failJob(ctx, job.ID, shutdownReason); err != nil {
+			s.log.Error("scan_drain_fail_job_error",
+				slog.String("job_id", job.ID),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+	s.log.Warn("scan_batch_aborted",
+		slog.String("batch_id", batchID),
+		slog.Int("aborted_scans", len(jobs)),
+	)
+}
+
+// waitBatches waits for running batches to finish starting or failing
+// their scans, until ctx is done.
+func (s *Service) waitBatches(ctx context.Context) {
+	done := make(chan struct{})
+	go func() {
+		s.batches.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+}
+
+// GetBatch retrieves a scan batch with the current status and findings of
+// its scans. Scans that have expired are left out.
+func (s *Service) GetBatch(ctx context.Context, batchID string) (*ScanBatch, error) {
+	batch := &ScanBatch{ID: batchID}
+	err := s.db.QueryRowContext(ctx,
+		`SELECT created_at FROM scan_batches WHERE id = $1`, batchID,
+	).Scan(&batch.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrBatchNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	jobIDs, err := s.loadBatchJobIDs(ctx, batchID)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]*ScanJob, 0, len(jobIDs))
+	for _, id := range jobIDs {
+		job, err := s.GetJob(ctx, id)
+		if errors.Is(err, ErrJobNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+
+	summarizeBatch(batch, jobs)
+	return batch, nil
+}
+
+// loadBatchJobIDs returns the job IDs of a batch in request order.
+func (s *Service) loadBatchJobIDs(ctx context.Context, batchID string) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT scan_job_id FROM scan_batch_jobs WHERE batch_id = $1 ORDER BY position`, batchID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// summarizeBatch sets the jobs of batch and aggregates their statuses and
+// severity counts. A batch is pending until a scan starts, running until
+// every scan has finished, then completed, or failed if every scan failed.
+func summarizeBatch(batch *ScanBatch, jobs []*ScanJob) {
+	batch.Jobs = make([]BatchJob, 0, len(jobs))
+	batch.StatusCounts = make(map[string]int)
+	batch.SeverityCounts = make(map[string]int)
+
+	for _, job := range jobs {
+		summary := BatchJob{
+			ID:             job.ID,
+			RepoURL:        job.RepoURL,
+			Status:         job.Status,
+			Error:          job.Error,
+			SeverityCounts: make(map[string]int),
+			Score:          job.Score,
+		}
+		for _, f := range job.Findings {
+			summary.SeverityCounts[f.Severity]++
+			batch.SeverityCounts[f.Severity]++
+		}
+		batch.Jobs = append(batch.Jobs, summary)
+		batch.StatusCounts[job.Status]++
+	}
+
+	finished := batch.StatusCounts[StatusCompleted] + batch.StatusCounts[StatusFailed]
+	switch {
+	case batch.StatusCounts[StatusPending] == len(jobs):
+		batch.Status = StatusPending
+	case finished < len(jobs):
+		batch.Status = StatusRunning
+	case batch.StatusCounts[StatusFailed] == len(jobs):
+		batch.Status = StatusFailed
+	default:
+		batch.Status = StatusCompleted
+	}
+}
+
+// createBatch stores a batch and its pending jobs in one transaction.
+func (s *Service) createBatch(ctx context.Context, batch *ScanBatch, jobs []*ScanJob) (err error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	expiresAt := batch.CreatedAt.Add(time.Duration(s.retentionDays) * 24 * time.Hour)
+	if _, err = tx.ExecContext(ctx,
+		`INSERT INTO scan_batches (id, created_at, expires_at) VALUES ($1, $2, $3)`,
+		batch.ID, batch.CreatedAt, expiresAt); err != nil {
+		return err
+	}
+	for i, job := range jobs {
+		if err = s.insertJob(ctx, tx, job); err != nil {
+			return err
+		}
+		if _, err = tx.ExecContext(ctx,
+			`INSERT INTO scan_batch_jobs (batch_id, position, scan_job_id) VALUES ($1, $2, $3)`,
+			batch.ID, i, job.ID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,127 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStartBatch_CreatesChildJobs(t *testing.T) {
+	// Cloning fails at once, so every scan of the batch fails without network access
+	cloner := NewCloner(WithTempDir(filepath.Join(t.TempDir(), "missing")))
+	db := &recordingDB{}
+	s := NewService(nil, nil, "", WithServiceCloner(cloner))
+	s.db = db
+
+	batch, err := s.StartBatch(context.Background(), BatchRequest{RepoURLs: []string{
+		"https://github.com/owner/api",
+		"https://github.com/owner/web",
+		"https://github.com/owner/cli",
+		"http://www.github.com/Owner/api.git", // Same repository as the first
+	}})
+	if err != nil {
+		t.Fatalf("StartBatch() error = %v", err)
+	}
+	if len(batch.Jobs) != 3 {
+		t.Fatalf("expected 3 child jobs, got %d", len(batch.Jobs))
+	}
+	if batch.Status != StatusPending || batch.StatusCounts[StatusPending] != 3 {
+		t.Errorf("new batch status = %q with counts %v, want 3 pending", batch.Status, batch.StatusCounts)
+	}
+
+	s.batches.Wait()
+	if got := s.ActiveScans(); got != 0 {
+		t.Errorf("expected no active scans after the batch finished, got %d", got)
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	counts := map[string]int{}
+	for _, e := range db.execs {
+		for _, stmt := range []string{"INSERT INTO scan_batches", "INSERT INTO scan_jobs", "INSERT INTO scan_batch_jobs"} {
+			if strings.Contains(e.query, stmt) {
+				counts[stmt]++
+			}
+		}
+		if strings.Contains(e.query, "UPDATE scan_jobs SET status") && e.args[0] == StatusFailed {
+			counts["failed"]++
+		}
+	}
+	want := map[string]int{"INSERT INTO scan_batches": 1, "INSERT INTO scan_jobs": 3, "INSERT INTO scan_batch_jobs": 3, "failed": 3}
+	for stmt, n := range want {
+		if counts[stmt] != n {
+			t.Errorf("%q ran %d times, want %d", stmt, counts[stmt], n)
+		}
+	}
+}
+
+func TestStartBatch_Validation(t *testing.T) {
+	s := NewService(nil, nil, "", WithMaxBatchSize(2))
+	s.db = &recordingDB{}
+
+	tests := []struct {
+		name  string
+		urls  []string
+		field string
+	}{
+		{"empty", nil, "repo_urls"},
+		{"too many", []string{"https://github.com/a/b", "https://github.com/a/c", "https://github.com/a/d"}, "repo_urls"},
+		{"invalid entry", []string{"https://github.com/a/b", "https://gitlab.com/a/b"}, "repo_urls[1]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.StartBatch(context.Background(), BatchRequest{RepoURLs: tt.urls})
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("expected a ValidationError, got %v", err)
+			}
+			if verr.Field != tt.field {
+				t.Errorf("field = %q, want %q", verr.Field, tt.field)
+			}
+		})
+	}
+}
+
+func TestSummarizeBatch(t *testing.T) {
+	completed := &ScanJob{ID: "job-1", RepoURL: "https://github.com/owner/api", Status: StatusCompleted, Findings: []Finding{
+		{Severity: SeverityHigh}, {Severity: SeverityHigh}, {Severity: SeverityLow},
+	}}
+	failed := &ScanJob{ID: "job-2", RepoURL: "https://github.com/owner/web", Status: StatusFailed, Error: "Clone failed"}
+	scanning := &ScanJob{ID: "job-3", RepoURL: "https://github.com/owner/cli", Status: StatusScanning, Findings: []Finding{
+		{Severity: SeverityCritical},
+	}}
+
+	batch := &ScanBatch{ID: "batch-1"}
+	summarizeBatch(batch, []*ScanJob{completed, failed, scanning})
+
+	if batch.Status != StatusRunning {
+		t.Errorf("status = %q, want %q while a scan is in progress", batch.Status, StatusRunning)
+	}
+	for status, n := range map[string]int{StatusCompleted: 1, StatusFailed: 1, StatusScanning: 1} {
+		if batch.StatusCounts[status] != n {
+			t.Errorf("StatusCounts[%s] = %d, want %d", status, batch.StatusCounts[status], n)
+		}
+	}
+	for severity, n := range map[string]int{SeverityCritical: 1, SeverityHigh: 2, SeverityLow: 1} {
+		if batch.SeverityCounts[severity] != n {
+			t.Errorf("SeverityCounts[%s] = %d, want %d", severity, batch.SeverityCounts[severity], n)
+		}
+	}
+	if batch.Jobs[0].SeverityCounts[SeverityHigh] != 2 || batch.Jobs[1].Error != "Clone failed" {
+		t.Errorf("job summaries = %+v", batch.Jobs)
+	}
+
+	scanning.Status = StatusCompleted
+	summarizeBatch(batch, []*ScanJob{completed, failed, scanning})
+	if batch.Status != StatusCompleted {
+		t.Errorf("status = %q, want %q once every scan finished", batch.Status, StatusCompleted)
+	}
+
+	completed.Status, scanning.Status = StatusFailed, StatusFailed
+	summarizeBatch(batch, []*ScanJob{completed, failed, scanning})
+	if batch.Status != StatusFailed {
+		t.Errorf("status = %q, want %q when every scan failed", batch.Status, StatusFailed)
+	}
+}
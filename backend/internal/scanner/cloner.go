@@ -174,6 +174,32 @@ func (c *Cloner) Clone(ctx context.Context, repoURL string) (*CloneResult, error
 	}, nil
 }
 
+// RemoveStale removes clone directories last modified before cutoff and
+// returns how many were removed. Directories the cloner did not create are
+// left alone.
+func (c *Cloner) RemoveStale(cutoff time.Time) (int, error) {
+	entries, err := os.ReadDir(c.tempDir)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrCleanupFailed, err)
+	}
+
+	removed := 0
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), DefaultTempDirPrefix) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || !info.ModTime().Before(cutoff) {
+			continue
+		}
+		if err := c.Cleanup(filepath.Join(c.tempDir, entry.Name())); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
 // Cleanup removes a cloned repository directory.
 func (c *Cloner) Cleanup(path string) error {
 	if path == "" {
@@ -0,0 +1,77 @@
+package scanner
+
+import "strings"
+
+// Confidence levels for findings. Confidence describes how likely a finding
+// is a true positive, independent of how severe it would be.
+const (
+	ConfidenceHigh   = "high"
+	ConfidenceMedium = "medium"
+	ConfidenceLow    = "low"
+)
+
+// confidenceOrder defines the sort order for confidence (lower = more confident).
+var confidenceOrder = map[string]int{
+	ConfidenceHigh:   0,
+	ConfidenceMedium: 1,
+	ConfidenceLow:    2,
+}
+
+// unknownConfidenceOrder ranks findings without a confidence after every known level.
+const unknownConfidenceOrder = 3
+
+// toolConfidenceAliases maps tool-specific confidence values onto the scale,
+// keyed by lowercase raw value.
+var toolConfidenceAliases = map[string]map[string]string{
+	"brakeman": {
+		"weak": ConfidenceLow,
+	},
+	"trufflehog": {
+		"verified":   ConfidenceHigh,
+		"unverified": ConfidenceMedium,
+	},
+}
+
+// NormalizeConfidence maps a tool's raw confidence to high, medium, or low.
+// An empty or unrecognized value yields "" since most tools do not report one.
+func NormalizeConfidence(tool, raw string) string {
+	c := strings.ToLower(strings.TrimSpace(raw))
+	if aliases, ok := toolConfidenceAliases[tool]; ok {
+		if mapped, ok := aliases[c]; ok {
+			return mapped
+		}
+	}
+	if _, ok := confidenceOrder[c]; ok {
+		return c
+	}
+	return ""
+}
+
+// IsValidConfidence checks if a confidence string is valid.
+func IsValidConfidence(confidence string) bool {
+	_, ok := confidenceOrder[confidence]
+	return ok
+}
+
+// confidenceRank returns the sort rank for a confidence value.
+func confidenceRank(confidence string) int {
+	if rank, ok := confidenceOrder[confidence]; ok {
+		return rank
+	}
+	return unknownConfidenceOrder
+}
+
+// FilterByConfidence returns findings with confidence at or above the given level.
+// Findings without a reported confidence are excluded.
+func (a *Aggregator) FilterByConfidence(findings []Finding, minConfidence string) []Finding {
+	minOrder := confidenceRank(minConfidence)
+	var filtered []Finding
+
+	for _, f := range findings {
+		if IsValidConfidence(f.Confidence) && confidenceOrder[f.Confidence] <= minOrder {
+			filtered = append(filtered, f)
+		}
+	}
+
+	return filtered
+}
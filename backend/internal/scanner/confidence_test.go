@@ -0,0 +1,86 @@
+package scanner
+
+import "testing"
+
+func TestNormalizeConfidence(t *testing.T) {
+	tests := []struct {
+		tool string
+		raw  string
+		want string
+	}{
+		{"brakeman", "High", ConfidenceHigh},
+		{"brakeman", "Medium", ConfidenceMedium},
+		{"brakeman", "Weak", ConfidenceLow},
+		{"semgrep", "HIGH", ConfidenceHigh},
+		{"semgrep", "LOW", ConfidenceLow},
+		{"trufflehog", "verified", ConfidenceHigh},
+		{"trufflehog", "unverified", ConfidenceMedium},
+		{"semgrep", "", ""},
+		{"gosec", "certain", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tool+"/"+tt.raw, func(t *testing.T) {
+			if got := NormalizeConfidence(tt.tool, tt.raw); got != tt.want {
+				t.Errorf("NormalizeConfidence(%q, %q) = %q, want %q", tt.tool, tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseBrakemanOutput_Confidence(t *testing.T) {
+	output := []byte(`{"warnings":[
+		{"warning_type":"SQL Injection","message":"Possible SQL injection","file":"app/models/user.rb","line":12,"confidence":"High"},
+		{"warning_type":"Cross-Site Scripting","message":"Unescaped output","file":"app/views/show.html.erb","line":4,"confidence":"Weak"}
+	]}`)
+
+	findings := NewAggregator().Aggregate([]ToolResult{{Tool: "brakeman", Findings: parseBrakemanOutput(output)}})
+	if len(findings) != 2 {
+		t.Fatalf("expected 2 findings, got %d", len(findings))
+	}
+	if findings[0].Confidence != ConfidenceHigh {
+		t.Errorf("High warning confidence = %q, want high", findings[0].Confidence)
+	}
+	if findings[1].Confidence != ConfidenceLow {
+		t.Errorf("Weak warning confidence = %q, want low", findings[1].Confidence)
+	}
+}
+
+func TestParseSemgrepOutput_MetadataConfidence(t *testing.T) {
+	output := []byte(`{"results":[
+		{"check_id":"go.lang.security.sqli","path":"db.go","start":{"line":7},"extra":{"message":"SQL injection","severity":"ERROR","metadata":{"confidence":"MEDIUM"}}},
+		{"check_id":"go.lang.style","path":"main.go","start":{"line":1},"extra":{"message":"Style","severity":"INFO"}}
+	]}`)
+
+	findings := parseSemgrepOutput(output)
+	if len(findings) != 2 {
+		t.Fatalf("expected 2 findings, got %d", len(findings))
+	}
+	if findings[0].Confidence != ConfidenceMedium {
+		t.Errorf("confidence = %q, want medium", findings[0].Confidence)
+	}
+	if findings[1].Confidence != "" {
+		t.Errorf("confidence without metadata = %q, want empty", findings[1].Confidence)
+	}
+}
+
+func TestAggregator_FilterByConfidence(t *testing.T) {
+	a := NewAggregator()
+
+	findings := []Finding{
+		{ID: "1", Confidence: ConfidenceHigh},
+		{ID: "2", Confidence: ConfidenceMedium},
+		{ID: "3", Confidence: ConfidenceLow},
+		{ID: "4"},
+	}
+
+	if filtered := a.FilterByConfidence(findings, ConfidenceHigh); len(filtered) != 1 {
+		t.Errorf("Expected 1 finding (high), got %d", len(filtered))
+	}
+	if filtered := a.FilterByConfidence(findings, ConfidenceMedium); len(filtered) != 2 {
+		t.Errorf("Expected 2 findings (high, medium), got %d", len(filtered))
+	}
+	if filtered := a.FilterByConfidence(findings, ConfidenceLow); len(filtered) != 3 {
+		t.Errorf("Expected 3 findings (unrated excluded), got %d", len(filtered))
+	}
+}
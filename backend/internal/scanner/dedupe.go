@@ -0,0 +1,83 @@
+package scanner
+
+import (
+	"context"
+	"time"
+)
+
+// scanDedupeWindow is how long after a scan starts that a request for the
+// same repository gets the running job instead of starting another scan.
+const scanDedupeWindow = 5 * time.Minute
+
+// claimScan registers jobID as a running scan of subdir in repoURL, a
+// canonical URL from NormalizeGitHubURL, started by clientID. If a scan of
+// the same repository and subdirectory started within scanDedupeWindow is
+// still running, nothing is registered and a snapshot of that job is
+// returned instead. Otherwise ErrTooManyScans is returned when clientID
+// already has the maximum number of scans running, and ErrShuttingDown once
+// DrainScans has been called. Checking both under activeMu means no scan can
+// be registered after DrainScans has taken its snapshot.
+func (s *Service) claimScan(jobID, repoURL, subdir, clientID string, now time.Time) (context.Context, *ScanJob, error) {
+	s.activeMu.Lock()
+	defer s.activeMu.Unlock()
+
+	if s.draining {
+		return nil, nil, ErrShuttingDown
+	}
+
+	running := 0
+	for id, scan := range s.active {
+		if scan.repoURL == repoURL && scan.subdir == subdir && now.Sub(scan.startedAt) < scanDedupeWindow {
+			return nil, &ScanJob{
+				ID:        id,
+				Status:    StatusPending,
+				RepoURL:   scan.repoURL,
+				Subdir:    scan.subdir,
+				CreatedAt: scan.startedAt,
+			}, nil
+		}
+		if clientID != "" && scan.clientID == clientID {
+			running++
+		}
+	}
+	if clientID != "" && s.maxScansPerClient > 0 && running >= s.maxScansPerClient {
+		return nil, nil, ErrTooManyScans
+	}
+
+	ctx, scan := s.trackScanLocked(jobID)
+	scan.repoURL = repoURL
+	scan.subdir = subdir
+	scan.clientID = clientID
+	scan.startedAt = now
+	return ctx, nil, nil
+}
+
+// claimPlan registers a scan plan under planID so it counts toward clientID's
+// concurrent scan limit and is waited for by DrainScans, like a scan. Plans
+// are never deduplicated. The returned context is cancelled if the drain
+// times out.
+func (s *Service) claimPlan(planID, clientID string) (context.Context, error) {
+	s.activeMu.Lock()
+	defer s.activeMu.Unlock()
+
+	if s.draining {
+		return nil, ErrShuttingDown
+	}
+	if clientID != "" && s.maxScansPerClient > 0 {
+		running := 0
+		for _, scan := range s.active {
+			if scan.clientID == clientID {
+				running++
+			}
+		}
+		if running >= s.maxScansPerClient {
+			return nil, ErrTooManyScans
+		}
+	}
+
+	ctx, scan := s.trackScanLocked(planID)
+	scan.plan = true
+	scan.clientID = clientID
+	scan.startedAt = time.Now()
+	return ctx, nil
+}
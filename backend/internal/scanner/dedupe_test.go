@@ -0,0 +1,123 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestClaimScan_ReturnsRunningJobForSameRepo(t *testing.T) {
+	s := NewService(nil, nil, "")
+	now := time.Now()
+	repoURL := NormalizeGitHubURL("https://github.com/owner/repo")
+
+	ctx, existing, _ := s.claimScan("job-1", repoURL, "", "", now)
+	if ctx == nil || existing != nil {
+		t.Fatalf("expected the first request to claim the scan, got existing %+v", existing)
+	}
+
+	// An equivalent spelling of the same repository reuses the running job
+	_, existing, _ = s.claimScan("job-2", NormalizeGitHubURL("http://www.github.com/Owner/repo.git/"), "", "", now.Add(time.Minute))
+	if existing == nil || existing.ID != "job-1" {
+		t.Fatalf("expected job-1 to be returned, got %+v", existing)
+	}
+	if n := s.ActiveScans(); n != 1 {
+		t.Errorf("ActiveScans = %d, want 1", n)
+	}
+
+	// A different repository starts its own scan
+	if _, existing, _ := s.claimScan("job-3", "https://github.com/owner/other", "", "", now); existing != nil {
+		t.Errorf("expected a new scan for another repository, got %+v", existing)
+	}
+}
+
+func TestClaimScan_StartsNewScanAfterWindowOrCompletion(t *testing.T) {
+	s := NewService(nil, nil, "")
+	now := time.Now()
+	repoURL := "https://github.com/owner/repo"
+
+	s.claimScan("job-1", repoURL, "", "", now)
+	if _, existing, _ := s.claimScan("job-2", repoURL, "", "", now.Add(scanDedupeWindow)); existing != nil {
+		t.Errorf("expected a new scan once the window passed, got %+v", existing)
+	}
+
+	s.untrackScan("job-2")
+	s.untrackScan("job-1")
+	if _, existing, _ := s.claimScan("job-3", repoURL, "", "", now); existing != nil {
+		t.Errorf("expected a new scan after the previous one finished, got %+v", existing)
+	}
+}
+
+func TestStartScan_RejectsThirdConcurrentScanFromSameClient(t *testing.T) {
+	s := NewService(nil, nil, "", WithMaxScansPerClient(2))
+	now := time.Now()
+
+	for _, id := range []string{"job-1", "job-2"} {
+		if _, _, err := s.claimScan(id, "https://github.com/owner/"+id, "", "client-a", now); err != nil {
+			t.Fatalf("claimScan(%s) error = %v", id, err)
+		}
+	}
+
+	_, err := s.StartScan(context.Background(), ScanRequest{RepoURL: "https://github.com/owner/job-3", ClientID: "client-a"})
+	if !errors.Is(err, ErrTooManyScans) {
+		t.Fatalf("StartScan() third scan error = %v, want ErrTooManyScans", err)
+	}
+
+	// Other clients, and scans of a repository already running, are unaffected
+	if _, _, err := s.claimScan("job-4", "https://github.com/owner/job-4", "", "client-b", now); err != nil {
+		t.Errorf("claimScan() for another client error = %v", err)
+	}
+	if _, existing, err := s.claimScan("job-5", "https://github.com/owner/job-1", "", "client-a", now); err != nil || existing == nil {
+		t.Errorf("claimScan() of a running repository = %v, %v; want the running job", existing, err)
+	}
+
+	// A finished scan frees its slot
+	s.untrackScan("job-1")
+	if _, _, err := s.claimScan("job-3", "https://github.com/owner/job-3", "", "client-a", now); err != nil {
+		t.Errorf("claimScan() after a scan finished error = %v", err)
+	}
+}
+
+func TestClaimScan_RejectedWhileDraining(t *testing.T) {
+	s := NewService(nil, nil, "")
+	if err := s.DrainScans(context.Background()); err != nil {
+		t.Fatalf("DrainScans() = %v", err)
+	}
+
+	// Even a caller that got past an earlier draining check can't register a scan
+	if _, _, err := s.claimScan("job-1", "https://github.com/owner/repo", "", "", time.Now()); !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("claimScan() after drain = %v, want ErrShuttingDown", err)
+	}
+	if got := s.ActiveScans(); got != 0 {
+		t.Errorf("ActiveScans() = %d, want 0", got)
+	}
+}
+
+func TestPlanScan_HeldToScanSlots(t *testing.T) {
+	s := NewService(nil, nil, "", WithMaxScansPerClient(1))
+	if _, _, err := s.claimScan("job-1", "https://github.com/owner/repo", "", "client-a", time.Now()); err != nil {
+		t.Fatalf("claimScan() error = %v", err)
+	}
+
+	// The client's only slot is taken, so planning must not clone
+	_, err := s.PlanScan(context.Background(), ScanRequest{RepoURL: "https://github.com/owner/other", ClientID: "client-a"})
+	if !errors.Is(err, ErrTooManyScans) {
+		t.Errorf("PlanScan() with no free slot = %v, want ErrTooManyScans", err)
+	}
+	if got := s.ActiveScans(); got != 1 {
+		t.Errorf("ActiveScans() = %d, want only the running scan", got)
+	}
+}
+
+func TestPlanScan_RejectedWhileDraining(t *testing.T) {
+	s := NewService(nil, nil, "")
+	if err := s.DrainScans(context.Background()); err != nil {
+		t.Fatalf("DrainScans() = %v", err)
+	}
+
+	_, err := s.PlanScan(context.Background(), ScanRequest{RepoURL: "https://github.com/owner/repo"})
+	if !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("PlanScan() while draining = %v, want ErrShuttingDown", err)
+	}
+}
@@ -0,0 +1,204 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// shutdownReason is recorded on scans that are aborted by DrainScans.
+const shutdownReason = "Scan aborted: server shutting down"
+
+// drainWriteTimeout bounds the database update for each aborted scan.
+const drainWriteTimeout = 5 * time.Second
+
+// activeScan tracks a background scan so it can be drained on shutdown.
+type activeScan struct {
+	cancel   context.CancelFunc
+	repoPath string
+	done     chan struct{}
+
+	// Canonical repository URL, subdirectory and start time, for deduplicating requests
+	repoURL   string
+	subdir    string
+	startedAt time.Time
+
+	// IP hash of the client that started the scan, for the per-client limit
+	clientID string
+
+	// plan marks a PlanScan clone, which holds a slot but has no job to fail
+	plan bool
+
+	// Event subscribers, closed when the scan is untracked
+	subscribers map[chan ScanEvent]struct{}
+}
+
+// trackScan registers a background scan and returns the context it should run with.
+func (s *Service) trackScan(jobID string) context.Context {
+	s.activeMu.Lock()
+	defer s.activeMu.Unlock()
+	ctx, _ := s.trackScanLocked(jobID)
+	return ctx
+}
+
+// trackScanLocked registers a background scan; s.activeMu must be held.
+func (s *Service) trackScanLocked(jobID string) (context.Context, *activeScan) {
+	ctx, cancel := context.WithCancel(context.Background())
+	if s.active == nil {
+		s.active = make(map[string]*activeScan)
+	}
+	scan := &activeScan{cancel: cancel, done: make(chan struct{})}
+	s.active[jobID] = scan
+	return ctx, scan
+}
+
+// setScanRepoPath records where a tracked scan cloned its repository.
+func (s *Service) setScanRepoPath(jobID, repoPath string) {
+	s.activeMu.Lock()
+	defer s.activeMu.Unlock()
+	if scan, ok := s.active[jobID]; ok {
+		scan.repoPath = repoPath
+	}
+}
+
+// scanRepoURL returns the canonical repository URL of a tracked scan, or ""
+// when the scan is not tracked.
+func (s *Service) scanRepoURL(jobID string) string {
+	s.activeMu.Lock()
+	defer s.activeMu.Unlock()
+	if scan, ok := s.active[jobID]; ok {
+		return scan.repoURL
+	}
+	return ""
+}
+
+// untrackScan marks a tracked scan as finished.
+func (s *Service) untrackScan(jobID string) {
+	s.activeMu.Lock()
+	defer s.activeMu.Unlock()
+	if scan, ok := s.active[jobID]; ok {
+		scan.cancel()
+		scan.closeSubscribers()
+		close(scan.done)
+		delete(s.active, jobID)
+	}
+}
+
+// isDraining reports whether DrainScans has been called.
+func (s *Service) isDraining() bool {
+	s.activeMu.Lock()
+	defer s.activeMu.Unlock()
+	return s.draining
+}
+
+// ActiveScans returns the number of scans currently running in the background.
+func (s *Service) ActiveScans() int {
+	s.activeMu.Lock()
+	defer s.activeMu.Unlock()
+	return len(s.active)
+}
+
+// DrainScans stops accepting new scans and waits for running scans to finish.
+// If ctx expires first, the remaining scans are cancelled, marked failed with a
+// shutdown reason, and their cloned repositories are removed. Batch scans that
+// have not started are marked failed the same way.
+// It should be called before the database is closed.
+func (s *Service) DrainScans(ctx context.Context) error {
+	s.activeMu.Lock()
+	s.draining = true
+	waiting := make(map[string]*activeScan, len(s.active))
+	for id, scan := range s.active {
+		waiting[id] = scan
+	}
+	s.activeMu.Unlock()
+
+	if len(waiting) == 0 {
+		s.waitBatches(ctx)
+		return nil
+	}
+
+	s.log.Info("scan_drain_start",
+		slog.Int("active_scans", len(waiting)),
+	)
+
+	for id, scan := range waiting {
+		select {
+		case <-scan.done:
+			delete(waiting, id)
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	// Scans may have finished while we waited on others
+	for id, scan := range waiting {
+		select {
+		case <-scan.done:
+			delete(waiting, id)
+		default:
+		}
+	}
+
+	if len(waiting) == 0 {
+		s.waitBatches(ctx)
+		s.log.Info("scan_drain_complete")
+		return nil
+	}
+
+	for id, scan := range waiting {
+		s.abortScan(id, scan)
+	}
+
+	// Batches fail their remaining scans once the aborted ones release their slots
+	batchCtx, cancel := context.WithTimeout(context.Background(), drainWriteTimeout)
+	s.waitBatches(batchCtx)
+	cancel()
+
+	s.log.Warn("scan_drain_aborted",
+		slog.Int("aborted_scans", len(waiting)),
+	)
+	return fmt.Errorf("%w: aborted %d running scans: %v", ErrShuttingDown, len(waiting), ctx.Err())
+}
+
+// abortScan cancels a running scan, records it as failed, and removes its clone.
+// A plan is only cancelled; it removes its own clone as it returns.
+func (s *Service) abortScan(jobID string, scan *activeScan) {
+	scan.cancel()
+	if scan.plan {
+		return
+	}
+
+	s.activeMu.Lock()
+	repoPath := scan.repoPath
+	s.activeMu.Unlock()
+
+	// The drain context has already expired, so use a fresh bounded one
+	ctx, cancel := context.WithTimeout(context.Background(), drainWriteTimeout)
+	defer cancel()
+
+	if err := s.failJob(ctx, jobID, shutdownReason); err != nil {
+		s.log.Error("scan_drain_fail_job_error",
+			slog.String("job_id", jobID),
+			slog.String("error", err.Error()),
+		)
+	}
+	s.publishEvent(jobID, ScanEvent{Type: EventScanFailed, Message: shutdownReason})
+
+	if repoPath != "" {
+		if err := s.cloner.Cleanup(repoPath); err != nil {
+			s.log.Warn("scan_drain_cleanup_error",
+				slog.String("job_id", jobID),
+				slog.String("path", repoPath),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+
+	s.log.Warn("scan_aborted",
+		slog.String("job_id", jobID),
+		slog.String("reason", shutdownReason),
+	)
+}
@@ -0,0 +1,183 @@
+package scanner
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// execRecord captures a single ExecContext call.
+type execRecord struct {
+	query string
+	args  []any
+}
+
+// recordingDB records ExecContext calls; query methods are not supported.
+// Statements run in a transaction are recorded only once it commits.
+type recordingDB struct {
+	mu    sync.Mutex
+	execs []execRecord
+
+	// failOn makes any statement containing it fail
+	failOn string
+}
+
+func (d *recordingDB) ExecContext(_ context.Context, query string, args ...any) (sql.Result, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.failOn != "" && strings.Contains(query, d.failOn) {
+		return nil, errors.New("recordingDB: injected failure")
+	}
+	d.execs = append(d.execs, execRecord{query: query, args: args})
+	return driverResult(1), nil
+}
+
+func (d *recordingDB) BeginTx(context.Context, *sql.TxOptions) (scanTx, error) {
+	return &recordingTx{db: d}, nil
+}
+
+// recordingTx buffers statements until Commit.
+type recordingTx struct {
+	db      *recordingDB
+	pending []execRecord
+	done    bool
+}
+
+func (tx *recordingTx) ExecContext(_ context.Context, query string, args ...any) (sql.Result, error) {
+	if tx.db.failOn != "" && strings.Contains(query, tx.db.failOn) {
+		return nil, errors.New("recordingDB: injected failure")
+	}
+	tx.pending = append(tx.pending, execRecord{query: query, args: args})
+	return driverResult(1), nil
+}
+
+func (tx *recordingTx) Commit() error {
+	if tx.done {
+		return sql.ErrTxDone
+	}
+	tx.done = true
+	tx.db.mu.Lock()
+	defer tx.db.mu.Unlock()
+	tx.db.execs = append(tx.db.execs, tx.pending...)
+	return nil
+}
+
+func (tx *recordingTx) Rollback() error {
+	if tx.done {
+		return sql.ErrTxDone
+	}
+	tx.done = true
+	return nil
+}
+
+func (d *recordingDB) QueryContext(context.Context, string, ...any) (*sql.Rows, error) {
+	return nil, errors.New("recordingDB: QueryContext not supported")
+}
+
+func (d *recordingDB) QueryRowContext(context.Context, string, ...any) *sql.Row {
+	return nil
+}
+
+// insertedFindings splits the recorded scan_findings inserts into one
+// argument row per finding.
+func (d *recordingDB) insertedFindings() [][]any {
+	var rows [][]any
+	for _, e := range d.execs {
+		if !strings.Contains(e.query, "INSERT INTO scan_findings") {
+			continue
+		}
+		for start := 0; start < len(e.args); start += findingColumns {
+			rows = append(rows, e.args[start:start+findingColumns])
+		}
+	}
+	return rows
+}
+
+// driverResult is a sql.Result reporting a fixed number of affected rows.
+type driverResult int64
+
+func (r driverResult) LastInsertId() (int64, error) { return 0, nil }
+func (r driverResult) RowsAffected() (int64, error) { return int64(r), nil }
+
+func TestDrainScans_NoActiveScans(t *testing.T) {
+	s := NewService(nil, nil, "")
+	if err := s.DrainScans(context.Background()); err != nil {
+		t.Errorf("DrainScans() with no scans = %v, want nil", err)
+	}
+
+	_, err := s.StartScan(context.Background(), ScanRequest{RepoURL: "https://github.com/owner/repo"})
+	if !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("StartScan() after drain = %v, want ErrShuttingDown", err)
+	}
+}
+
+func TestDrainScans_WaitsForFinishedScan(t *testing.T) {
+	s := NewService(nil, nil, "")
+	s.trackScan("job-1")
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		s.untrackScan("job-1")
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := s.DrainScans(ctx); err != nil {
+		t.Errorf("DrainScans() = %v, want nil", err)
+	}
+	if n := s.ActiveScans(); n != 0 {
+		t.Errorf("ActiveScans() = %d, want 0", n)
+	}
+}
+
+func TestDrainScans_AbortsScanAfterDeadline(t *testing.T) {
+	tempDir := t.TempDir()
+	repoPath := filepath.Join(tempDir, "repo-1")
+	if err := os.MkdirAll(repoPath, 0755); err != nil {
+		t.Fatalf("failed to create repo dir: %v", err)
+	}
+
+	db := &recordingDB{}
+	s := NewService(nil, nil, "", WithServiceCloner(NewCloner(WithTempDir(tempDir))))
+	s.db = db
+
+	scanCtx := s.trackScan("job-1")
+	s.setScanRepoPath("job-1", repoPath)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := s.DrainScans(ctx)
+	if !errors.Is(err, ErrShuttingDown) {
+		t.Fatalf("DrainScans() = %v, want ErrShuttingDown", err)
+	}
+
+	if scanCtx.Err() == nil {
+		t.Error("expected the running scan's context to be cancelled")
+	}
+
+	if _, statErr := os.Stat(repoPath); !os.IsNotExist(statErr) {
+		t.Errorf("expected repo dir to be removed, stat err = %v", statErr)
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if len(db.execs) != 1 {
+		t.Fatalf("expected 1 database update, got %d", len(db.execs))
+	}
+	args := db.execs[0].args
+	if args[0] != StatusFailed {
+		t.Errorf("status = %v, want %q", args[0], StatusFailed)
+	}
+	if args[1] != shutdownReason {
+		t.Errorf("error = %v, want %q", args[1], shutdownReason)
+	}
+	if args[len(args)-1] != "job-1" {
+		t.Errorf("job id = %v, want job-1", args[len(args)-1])
+	}
+}
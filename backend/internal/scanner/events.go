@@ -0,0 +1,104 @@
+package scanner
+
+import (
+	"log/slog"
+	"time"
+)
+
+// Scan event types, published as a running scan moves through its phases.
+const (
+	// EventStatus carries a job's stored status when a stream opens
+	EventStatus            = "status"
+	EventCloneStarted      = "clone_started"
+	EventCloneComplete     = "clone_complete"
+	EventLanguagesDetected = "languages_detected"
+	EventToolStarted       = "tool_started"
+	EventToolComplete      = "tool_complete"
+	EventBudgetExceeded    = "budget_exceeded"
+	EventAggregateComplete = "aggregate_complete"
+	EventReviewStarted     = "review_started"
+	EventReviewComplete    = "review_complete"
+	EventScanCompleted     = "completed"
+	EventScanFailed        = "failed"
+)
+
+// subscriberBuffer is how many events a subscriber may fall behind by before
+// further events are dropped for it. Scans never wait on subscribers.
+const subscriberBuffer = 64
+
+// ScanEvent is a progress event for a running scan.
+type ScanEvent struct {
+	Type         string    `json:"type"`
+	JobID        string    `json:"job_id"`
+	Tool         string    `json:"tool,omitempty"`
+	Languages    []string  `json:"languages,omitempty"`
+	FindingCount int       `json:"finding_count,omitempty"`
+	Message      string    `json:"message,omitempty"`
+	Time         time.Time `json:"time"`
+}
+
+// Subscribe registers for the events of a running scan. The channel is
+// closed when the scan finishes; cancel must be called once the caller stops
+// reading. It returns false when the scan is not running on this server, in
+// which case no events will follow and the job's stored status is final.
+func (s *Service) Subscribe(jobID string) (<-chan ScanEvent, func(), bool) {
+	s.activeMu.Lock()
+	defer s.activeMu.Unlock()
+
+	scan, ok := s.active[jobID]
+	if !ok {
+		return nil, func() {}, false
+	}
+
+	ch := make(chan ScanEvent, subscriberBuffer)
+	if scan.subscribers == nil {
+		scan.subscribers = make(map[chan ScanEvent]struct{})
+	}
+	scan.subscribers[ch] = struct{}{}
+
+	cancel := func() {
+		s.activeMu.Lock()
+		defer s.activeMu.Unlock()
+		// untrackScan may already have closed and removed the channel
+		if _, ok := scan.subscribers[ch]; ok {
+			delete(scan.subscribers, ch)
+			close(ch)
+		}
+	}
+	return ch, cancel, true
+}
+
+// publishEvent sends an event to every subscriber of the scan without
+// blocking. Subscribers whose buffer is full miss the event.
+func (s *Service) publishEvent(jobID string, event ScanEvent) {
+	event.JobID = jobID
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	s.activeMu.Lock()
+	defer s.activeMu.Unlock()
+
+	scan, ok := s.active[jobID]
+	if !ok {
+		return
+	}
+	for ch := range scan.subscribers {
+		select {
+		case ch <- event:
+		default:
+			s.log.Debug("scan_event_dropped",
+				slog.String("job_id", jobID),
+				slog.String("event", event.Type),
+			)
+		}
+	}
+}
+
+// closeSubscribers ends every subscription to a scan. The caller must hold activeMu.
+func (scan *activeScan) closeSubscribers() {
+	for ch := range scan.subscribers {
+		close(ch)
+	}
+	scan.subscribers = nil
+}
@@ -0,0 +1,27 @@
+package scanner
+
+import (
+	"context"
+	"os/exec"
+)
+
+// Executor runs a command in the scanning environment and returns its
+// combined stdout and stderr. An empty workDir uses the environment's default.
+type Executor interface {
+	Exec(ctx context.Context, workDir string, name string, args ...string) ([]byte, error)
+}
+
+// dockerExecutor runs commands inside the scanner container via docker exec.
+type dockerExecutor struct{}
+
+// Exec implements Executor.
+func (dockerExecutor) Exec(ctx context.Context, workDir string, name string, args ...string) ([]byte, error) {
+	dockerArgs := []string{"exec"}
+	if workDir != "" {
+		dockerArgs = append(dockerArgs, "-w", workDir)
+	}
+	dockerArgs = append(dockerArgs, scannerContainer, name)
+	dockerArgs = append(dockerArgs, args...)
+
+	return exec.CommandContext(ctx, "docker", dockerArgs...).CombinedOutput()
+}
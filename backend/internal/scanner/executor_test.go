@@ -0,0 +1,49 @@
+package scanner
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fakeExecutor returns scripted output per command and records every call.
+// Commands with a delay block until it elapses or the context is done.
+type fakeExecutor struct {
+	mu      sync.Mutex
+	outputs map[string][]byte // keyed by "name args..."
+	errs    map[string]error
+	delays  map[string]time.Duration // keyed by command name
+	calls   []string
+
+	inFlight    int // commands currently executing
+	maxInFlight int // most commands seen executing at once
+}
+
+func (f *fakeExecutor) Exec(ctx context.Context, _ string, name string, args ...string) ([]byte, error) {
+	key := strings.Join(append([]string{name}, args...), " ")
+	f.mu.Lock()
+	f.calls = append(f.calls, key)
+	delay := f.delays[name]
+	f.inFlight++
+	f.maxInFlight = max(f.maxInFlight, f.inFlight)
+	f.mu.Unlock()
+
+	defer func() {
+		f.mu.Lock()
+		f.inFlight--
+		f.mu.Unlock()
+	}()
+
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.outputs[key], f.errs[key]
+}
@@ -0,0 +1,66 @@
+package scanner
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// expirySweepInterval is how often RunExpirySweeper deletes expired scans.
+const expirySweepInterval = time.Hour
+
+// DeleteExpired deletes the scan batches and scan jobs whose retention
+// window ended before now, and returns how many of each were deleted.
+// Findings and batch entries are removed with their rows. Batches go first,
+// so one never lists only some of its scans.
+func (s *Service) DeleteExpired(ctx context.Context, now time.Time) (batches, jobs int, err error) {
+	start := time.Now()
+
+	batches, err = s.deleteExpired(ctx, `DELETE FROM scan_batches WHERE expires_at < $1`, now)
+	if err != nil {
+		s.log.Error("scan_expiry_failed", slog.String("table", "scan_batches"), slog.String("error", err.Error()))
+		return 0, 0, err
+	}
+	jobs, err = s.deleteExpired(ctx, `DELETE FROM scan_jobs WHERE expires_at < $1`, now)
+	if err != nil {
+		s.log.Error("scan_expiry_failed", slog.String("table", "scan_jobs"), slog.String("error", err.Error()))
+		return batches, 0, err
+	}
+
+	if batches > 0 || jobs > 0 {
+		s.log.Info("scan_expiry_complete",
+			slog.Int("batches_deleted", batches),
+			slog.Int("jobs_deleted", jobs),
+			slog.Duration("duration", time.Since(start)),
+		)
+	}
+	return batches, jobs, nil
+}
+
+// deleteExpired runs one expiry statement and returns the rows it deleted.
+func (s *Service) deleteExpired(ctx context.Context, query string, now time.Time) (int, error) {
+	result, err := s.db.ExecContext(ctx, query, now)
+	if err != nil {
+		return 0, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(n), nil
+}
+
+// RunExpirySweeper deletes expired scans and batches every hour until ctx is
+// done.
+func (s *Service) RunExpirySweeper(ctx context.Context) {
+	ticker := time.NewTicker(expirySweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			_, _, _ = s.DeleteExpired(ctx, now)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package scanner
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDeleteExpired_DeletesBatchesThenJobs(t *testing.T) {
+	db := &recordingDB{}
+	s := NewService(nil, nil, "")
+	s.db = db
+	now := time.Now()
+
+	batches, jobs, err := s.DeleteExpired(context.Background(), now)
+	if err != nil {
+		t.Fatalf("DeleteExpired() error = %v", err)
+	}
+	if batches != 1 || jobs != 1 {
+		t.Errorf("deleted %d batches and %d jobs, want 1 and 1", batches, jobs)
+	}
+
+	if len(db.execs) != 2 {
+		t.Fatalf("ran %d statements, want 2", len(db.execs))
+	}
+	for i, table := range []string{"scan_batches", "scan_jobs"} {
+		exec := db.execs[i]
+		if !strings.Contains(exec.query, "DELETE FROM "+table+" WHERE expires_at < $1") {
+			t.Errorf("statement %d = %q, want a delete of expired %s", i, exec.query, table)
+		}
+		if len(exec.args) != 1 || exec.args[0] != now {
+			t.Errorf("statement %d args = %v, want [now]", i, exec.args)
+		}
+	}
+}
+
+func TestDeleteExpired_StopsOnBatchFailure(t *testing.T) {
+	db := &recordingDB{failOn: "scan_batches"}
+	s := NewService(nil, nil, "")
+	s.db = db
+
+	if _, _, err := s.DeleteExpired(context.Background(), time.Now()); err == nil {
+		t.Fatal("DeleteExpired() error = nil, want the batch delete failure")
+	}
+	if len(db.execs) != 0 {
+		t.Errorf("ran %d statements after the batch delete failed, want 0", len(db.execs))
+	}
+}
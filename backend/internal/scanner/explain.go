@@ -0,0 +1,176 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"better-kiro-prompts/internal/logger"
+	"better-kiro-prompts/internal/openai"
+)
+
+// ErrNoExplanation is returned when the model gives an empty explanation.
+var ErrNoExplanation = errors.New("no explanation returned")
+
+// maxCachedExplanations bounds the per-rule explanation cache. Rule IDs come
+// from a fixed set of tool rules, so the bound is only a safeguard.
+const maxCachedExplanations = 1000
+
+// FindingExplanation is a plain-language explanation of a finding's rule.
+type FindingExplanation struct {
+	FindingID   string `json:"finding_id"`
+	Tool        string `json:"tool"`
+	RuleID      string `json:"rule_id,omitempty"`
+	Explanation string `json:"explanation"`
+	Cached      bool   `json:"cached"` // Served from the per-rule cache without a model call
+}
+
+// explainSystemPrompt asks for a generic explanation; no code is sent.
+const explainSystemPrompt = `You are a security educator. Explain a finding reported by a security scanning tool to a developer in plain language.
+
+Cover, briefly:
+1. What the rule or category detects
+2. Why it matters (the potential impact)
+3. How this kind of issue is generally fixed
+
+You are not shown the code, so do not guess at specifics of the project. Answer in at most three short paragraphs of plain text, without JSON or headings.`
+
+// ExplainFinding explains a finding of a scan from its tool, rule, severity
+// and description alone, so no repository is cloned. Explanations are generic
+// to the rule and cached by tool and rule ID; findings without a rule ID are
+// explained afresh each time since their description is all there is to go on.
+func (s *Service) ExplainFinding(ctx context.Context, jobID, findingID string) (*FindingExplanation, error) {
+	job, err := s.loadJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	var finding *Finding
+	for i := range job.Findings {
+		if job.Findings[i].ID == findingID {
+			finding = &job.Findings[i]
+			break
+		}
+	}
+	if finding == nil {
+		return nil, fmt.Errorf("%w: %s", ErrFindingNotFound, findingID)
+	}
+
+	return s.explain(ctx, jobID, *finding)
+}
+
+// explain returns the cached explanation for a finding's rule, or asks the
+// model and caches the answer.
+func (s *Service) explain(ctx context.Context, jobID string, finding Finding) (*FindingExplanation, error) {
+	requestID := logger.GetRequestID(ctx)
+	result := &FindingExplanation{FindingID: finding.ID, Tool: finding.Tool, RuleID: finding.RuleID}
+
+	key := explanationKey(finding)
+	if explanation, ok := s.cachedExplanation(key); ok {
+		result.Explanation = explanation
+		result.Cached = true
+		return result, nil
+	}
+	if !s.reviewer.HasClient() {
+		return nil, ErrReviewUnavailable
+	}
+
+	start := time.Now()
+	explanation, err := s.reviewer.ExplainFinding(ctx, finding)
+	if err != nil {
+		s.log.Warn("scan_explain_failed",
+			slog.String("request_id", requestID),
+			slog.String("job_id", jobID),
+			slog.String("finding_id", finding.ID),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	s.cacheExplanation(key, explanation)
+
+	s.log.Info("scan_explain_complete",
+		slog.String("request_id", requestID),
+		slog.String("job_id", jobID),
+		slog.String("finding_id", finding.ID),
+		slog.String("rule_id", finding.RuleID),
+		slog.Duration("duration", time.Since(start)),
+	)
+
+	result.Explanation = explanation
+	return result, nil
+}
+
+// explanationKey returns the cache key for a finding's explanation, or ""
+// when the finding has no rule to key on.
+func explanationKey(f Finding) string {
+	if f.RuleID == "" {
+		return ""
+	}
+	return f.Tool + ":" + f.RuleID
+}
+
+func (s *Service) cachedExplanation(key string) (string, bool) {
+	if key == "" {
+		return "", false
+	}
+	s.explainMu.Lock()
+	defer s.explainMu.Unlock()
+	explanation, ok := s.explanations[key]
+	return explanation, ok
+}
+
+func (s *Service) cacheExplanation(key, explanation string) {
+	if key == "" {
+		return
+	}
+	s.explainMu.Lock()
+	defer s.explainMu.Unlock()
+	if s.explanations == nil {
+		s.explanations = make(map[string]string)
+	}
+	if len(s.explanations) >= maxCachedExplanations {
+		return
+	}
+	s.explanations[key] = explanation
+}
+
+// ExplainFinding asks the model for a generic explanation of a finding's
+// rule. No file content is sent.
+func (r *CodeReviewer) ExplainFinding(ctx context.Context, finding Finding) (string, error) {
+	if r.client == nil {
+		return "", ErrReviewUnavailable
+	}
+
+	var prompt strings.Builder
+	fmt.Fprintf(&prompt, "Tool: %s\n", finding.Tool)
+	if finding.RuleID != "" {
+		fmt.Fprintf(&prompt, "Rule: %s\n", finding.RuleID)
+	}
+	fmt.Fprintf(&prompt, "Severity: %s\n", finding.Severity)
+	fmt.Fprintf(&prompt, "Description: %s\n", finding.Description)
+
+	messages := []openai.Message{
+		{Role: "system", Content: explainSystemPrompt},
+		{Role: "user", Content: prompt.String()},
+	}
+
+	callCtx := ctx
+	if r.timeout > 0 {
+		var cancel context.CancelFunc
+		callCtx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+
+	response, err := r.client.ChatCompletionWithModel(callCtx, messages, r.model)
+	if err != nil {
+		return "", fmt.Errorf("ai explanation failed: %w", err)
+	}
+	explanation := strings.TrimSpace(response)
+	if explanation == "" {
+		return "", ErrNoExplanation
+	}
+	return explanation, nil
+}
@@ -0,0 +1,147 @@
+package scanner
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Fingerprint limits.
+const (
+	// fingerprintContextLines caps the flagged lines hashed into a fingerprint.
+	fingerprintContextLines = 5
+	// maxFingerprintFileSize skips reading context from very large files.
+	maxFingerprintFileSize = 2 << 20
+)
+
+// FingerprintLength is the length of a finding fingerprint in hex characters.
+const FingerprintLength = sha256.Size * 2
+
+// FindingFingerprint returns a stable identifier for a finding: a hash of
+// the canonical repository URL, the tool and rule, the file path relative
+// to the repository root and the flagged code with whitespace collapsed.
+// Unlike the random finding ID it is the same in every scan that reports
+// the finding, even after unrelated edits move it to another line.
+func FindingFingerprint(repoURL, tool, ruleID, filePath, snippet string) string {
+	h := sha256.New()
+	for _, part := range []string{repoURL, tool, ruleID, filePath, normalizeSnippet(snippet)} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// normalizeSnippet collapses runs of whitespace within each line and drops
+// blank lines, so reindenting or reformatting code keeps its fingerprint.
+func normalizeSnippet(snippet string) string {
+	var lines []string
+	for _, line := range strings.Split(snippet, "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			lines = append(lines, strings.Join(fields, " "))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// fingerprintFindings sets the fingerprint of each finding. scanPath is the
+// directory the tools ran in and subdir its path within the repository, so
+// a finding keeps its fingerprint whether the whole repository or only the
+// subdirectory was scanned. Findings whose code cannot be read, such as
+// dependency findings without a line, are fingerprinted by description.
+func fingerprintFindings(findings []Finding, repoURL, subdir, scanPath string) {
+	files := make(map[string][]string)
+	for i := range findings {
+		f := &findings[i]
+		scanRel := scanRelativePath(f.FilePath, scanPath)
+
+		snippet := ""
+		if f.LineNumber != nil && scanRel != "" {
+			lines, ok := files[scanRel]
+			if !ok {
+				lines = readFileLines(scanPath, scanRel)
+				files[scanRel] = lines
+			}
+			snippet = lineRange(lines, *f.LineNumber, f.EndLine)
+		}
+		if snippet == "" {
+			snippet = f.Description
+		}
+
+		repoRel := ""
+		if scanRel != "" {
+			repoRel = path.Join(subdir, scanRel)
+		}
+		f.Fingerprint = FindingFingerprint(repoURL, f.Tool, f.RuleID, repoRel, snippet)
+	}
+}
+
+// scanRelativePath returns a finding's path relative to scanPath in slash
+// form. Tools report paths relative to scanPath or absolute.
+func scanRelativePath(filePath, scanPath string) string {
+	if filePath == "" {
+		return ""
+	}
+	rel := filepath.Clean(filePath)
+	if filepath.IsAbs(rel) {
+		var err error
+		if rel, err = filepath.Rel(scanPath, rel); err != nil {
+			return filepath.ToSlash(filePath)
+		}
+	}
+	return filepath.ToSlash(rel)
+}
+
+// readFileLines returns the lines of relPath under scanPath, or nil when the
+// file is missing, outside scanPath or too large.
+func readFileLines(scanPath, relPath string) []string {
+	full := filepath.Join(scanPath, filepath.FromSlash(relPath))
+	if !isWithin(scanPath, full) {
+		return nil
+	}
+	info, err := os.Stat(full)
+	if err != nil || !info.Mode().IsRegular() || info.Size() > maxFingerprintFileSize {
+		return nil
+	}
+	data, err := os.ReadFile(full)
+	if err != nil {
+		return nil
+	}
+	return strings.Split(string(data), "\n")
+}
+
+// lineRange returns the 1-based lines from start through end, capped at
+// fingerprintContextLines, or "" when start is out of range.
+func lineRange(lines []string, start int, end *int) string {
+	if start < 1 || start > len(lines) {
+		return ""
+	}
+	last := start
+	if end != nil && *end > start {
+		last = *end
+	}
+	last = min(last, start+fingerprintContextLines-1, len(lines))
+	return strings.Join(lines[start-1:last], "\n")
+}
+
+// GetFindingByFingerprint returns the finding of a scan with the given
+// fingerprint, with acknowledgements applied as in GetJob. When several
+// findings share a fingerprint the most severe is returned.
+func (s *Service) GetFindingByFingerprint(ctx context.Context, jobID, fingerprint string) (*Finding, error) {
+	job, err := s.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range [][]Finding{job.Findings, job.AcknowledgedFindings} {
+		for i := range group {
+			if group[i].Fingerprint == fingerprint {
+				return &group[i], nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrFindingNotFound, fingerprint)
+}
@@ -0,0 +1,121 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeRepoFile writes content to name under a new temporary clone.
+func writeRepoFile(t *testing.T, name, content string) string {
+	t.Helper()
+	repoPath := t.TempDir()
+	full := filepath.Join(repoPath, name)
+	if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return repoPath
+}
+
+func TestFingerprintFindings_StableAcrossRescans(t *testing.T) {
+	const repoURL = "https://github.com/owner/repo"
+
+	// First scan: the flagged call is on line 3, reported with a relative path
+	first := writeRepoFile(t, "app/db.go", "package app\n\nrows, _ := db.Query(\"SELECT * FROM users WHERE id=\" + id)\n")
+	line := 3
+	firstScan := []Finding{{ID: "finding-1", Tool: "semgrep", RuleID: "go.lang.sqli", FilePath: "app/db.go", LineNumber: &line, Description: "SQL injection"}}
+	fingerprintFindings(firstScan, repoURL, "", first)
+
+	// Rescan in a new clone: unrelated lines moved the call and it was
+	// reindented, and the tool reports an absolute path this time
+	second := writeRepoFile(t, "app/db.go", "package app\n\nimport \"fmt\"\n\n// lookup\n\t\trows, _ :=   db.Query(\"SELECT * FROM users WHERE id=\" + id)\n")
+	moved := 6
+	secondScan := []Finding{{ID: "finding-2", Tool: "semgrep", RuleID: "go.lang.sqli", FilePath: filepath.Join(second, "app", "db.go"), LineNumber: &moved, Description: "SQL injection"}}
+	fingerprintFindings(secondScan, repoURL, "", second)
+
+	if firstScan[0].ID == secondScan[0].ID {
+		t.Fatal("test findings should have different row IDs")
+	}
+	if firstScan[0].Fingerprint == "" || len(firstScan[0].Fingerprint) != FingerprintLength {
+		t.Fatalf("fingerprint = %q, want %d hex characters", firstScan[0].Fingerprint, FingerprintLength)
+	}
+	if firstScan[0].Fingerprint != secondScan[0].Fingerprint {
+		t.Errorf("fingerprints differ across rescans: %s vs %s", firstScan[0].Fingerprint, secondScan[0].Fingerprint)
+	}
+
+	// A subdirectory scan reports paths relative to the subdirectory
+	subdirScan := []Finding{{ID: "finding-3", Tool: "semgrep", RuleID: "go.lang.sqli", FilePath: "db.go", LineNumber: &line, Description: "SQL injection"}}
+	fingerprintFindings(subdirScan, repoURL, "app", filepath.Join(first, "app"))
+	if subdirScan[0].Fingerprint != firstScan[0].Fingerprint {
+		t.Errorf("subdirectory scan fingerprint = %s, want %s", subdirScan[0].Fingerprint, firstScan[0].Fingerprint)
+	}
+}
+
+func TestFingerprintFindings_DistinguishesFindings(t *testing.T) {
+	repoPath := writeRepoFile(t, "main.py", "import os\nos.system(cmd)\nos.system(other)\n")
+	two, three := 2, 3
+	base := Finding{Tool: "bandit", RuleID: "B605", FilePath: "main.py", LineNumber: &two, Description: "Shell call"}
+
+	otherLine := base
+	otherLine.LineNumber = &three
+	otherRule := base
+	otherRule.RuleID = "B607"
+	findings := []Finding{base, otherLine, otherRule}
+	fingerprintFindings(findings, "https://github.com/owner/repo", "", repoPath)
+
+	otherRepo := []Finding{base}
+	fingerprintFindings(otherRepo, "https://github.com/owner/fork", "", repoPath)
+
+	seen := map[string]int{}
+	for i, f := range append(findings, otherRepo...) {
+		if j, ok := seen[f.Fingerprint]; ok {
+			t.Errorf("findings %d and %d share fingerprint %s", j, i, f.Fingerprint)
+		}
+		seen[f.Fingerprint] = i
+	}
+}
+
+func TestFingerprintFindings_WithoutCodeUsesDescription(t *testing.T) {
+	findings := []Finding{
+		{Tool: "trivy", RuleID: "CVE-2024-0001", FilePath: "go.sum", Description: "Remote code execution"},
+		{Tool: "trivy", RuleID: "CVE-2024-0001", FilePath: "go.sum", Description: "Remote code execution"},
+		{Tool: "trivy", RuleID: "CVE-2024-0001", FilePath: "go.sum", Description: "Denial of service"},
+	}
+	fingerprintFindings(findings, "https://github.com/owner/repo", "", t.TempDir())
+
+	if findings[0].Fingerprint != findings[1].Fingerprint {
+		t.Error("identical dependency findings should share a fingerprint")
+	}
+	if findings[0].Fingerprint == findings[2].Fingerprint {
+		t.Error("dependency findings with different descriptions should differ")
+	}
+}
+
+func TestLineRange(t *testing.T) {
+	lines := []string{"a", "b", "c", "d", "e", "f", "g"}
+	end := func(n int) *int { return &n }
+
+	tests := []struct {
+		name  string
+		start int
+		end   *int
+		want  string
+	}{
+		{"single line", 2, nil, "b"},
+		{"range", 2, end(4), "b\nc\nd"},
+		{"capped", 1, end(7), "a\nb\nc\nd\ne"},
+		{"end before start", 3, end(1), "c"},
+		{"out of range", 9, nil, ""},
+		{"zero", 0, nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lineRange(lines, tt.start, tt.end); got != tt.want {
+				t.Errorf("lineRange() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+
+	"better-kiro-prompts/internal/scancatalog"
 )
 
 // Language represents a detected programming language.
@@ -170,6 +172,22 @@ func (d *LanguageDetector) Detect(repoPath string) ([]LanguageResult, error) {
 	return results, nil
 }
 
+// SignificantLanguages returns the detected languages that make up at least
+// minPercent of the repository's files or have at least minFiles files,
+// keeping their order. A zero threshold is disabled; with both disabled every
+// detected language is returned.
+func SignificantLanguages(results []LanguageResult, minPercent float64, minFiles int) []Language {
+	languages := make([]Language, 0, len(results))
+	for _, r := range results {
+		if (minPercent <= 0 && minFiles <= 0) ||
+			(minPercent > 0 && r.Percentage >= minPercent) ||
+			(minFiles > 0 && r.FileCount >= minFiles) {
+			languages = append(languages, r.Language)
+		}
+	}
+	return languages
+}
+
 // DetectLanguages returns just the language names sorted by prevalence.
 func (d *LanguageDetector) DetectLanguages(repoPath string) ([]Language, error) {
 	results, err := d.Detect(repoPath)
@@ -230,18 +248,11 @@ func (d *LanguageDetector) GetSupportedExtensions() []string {
 
 // GetSupportedLanguages returns all supported languages.
 func (d *LanguageDetector) GetSupportedLanguages() []Language {
-	return []Language{
-		LangGo,
-		LangJavaScript,
-		LangTypeScript,
-		LangPython,
-		LangJava,
-		LangRuby,
-		LangPHP,
-		LangC,
-		LangCPP,
-		LangRust,
+	languages := make([]Language, len(scancatalog.Languages))
+	for i, lang := range scancatalog.Languages {
+		languages[i] = Language(lang)
 	}
+	return languages
 }
 
 // String returns the string representation of a Language.
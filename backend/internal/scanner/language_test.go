@@ -1,8 +1,10 @@
 package scanner
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 	"testing/quick"
 )
@@ -246,6 +248,48 @@ func TestLanguageDetector_DetectLanguages(t *testing.T) {
 	}
 }
 
+func TestSignificantLanguages_SkipsStrayLanguage(t *testing.T) {
+	// 99 Go files and a single Python script
+	tempDir := t.TempDir()
+	for i := range 99 {
+		if err := os.WriteFile(filepath.Join(tempDir, fmt.Sprintf("file%d.go", i)), []byte("package main"), 0o644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "release.py"), []byte("print()"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	results, err := NewLanguageDetector().Detect(tempDir)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	runner := NewToolRunner()
+
+	tests := []struct {
+		name       string
+		minPercent float64
+		minFiles   int
+		wantPython bool
+	}{
+		{"no threshold", 0, 0, true},
+		{"percent threshold", 5, 0, false},
+		{"file threshold", 0, 2, false},
+		{"either threshold is enough", 5, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tools := runner.GetToolsForLanguages(SignificantLanguages(results, tt.minPercent, tt.minFiles))
+			if !slices.Contains(tools, "govulncheck") || !slices.Contains(tools, "trivy") {
+				t.Errorf("expected Go and universal tools, got %v", tools)
+			}
+			if got := slices.Contains(tools, "bandit"); got != tt.wantPython {
+				t.Errorf("bandit selected = %v, want %v (tools %v)", got, tt.wantPython, tools)
+			}
+		})
+	}
+}
+
 func TestLanguageDetector_GetSupportedLanguages(t *testing.T) {
 	d := NewLanguageDetector()
 	languages := d.GetSupportedLanguages()
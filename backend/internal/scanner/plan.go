@@ -0,0 +1,135 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"better-kiro-prompts/internal/logger"
+
+	"github.com/google/uuid"
+)
+
+// ScanPlan describes what a scan of a repository would do, without running it.
+type ScanPlan struct {
+	RepoURL   string           `json:"repo_url"`
+	Subdir    string           `json:"subdir,omitempty"`
+	Languages []LanguageResult `json:"languages"` // Every detected language, most prevalent first
+	Tools     []PlannedTool    `json:"tools"`     // Tools in the order a scan runs them
+
+	// Upper bound for the tool phase: the tools' timeouts added up, capped by
+	// the scan budget. Clone and AI review time are not included.
+	EstimatedMaxSeconds int `json:"estimated_max_seconds"`
+}
+
+// PlannedTool is a tool a scan would run.
+type PlannedTool struct {
+	Name           string `json:"name"`
+	TimeoutSeconds int    `json:"timeout_seconds"`
+}
+
+// PlanScan clones the repository and reports the languages it contains and
+// the tools a scan would run. No tools run, no job is created and nothing is
+// stored; the clone is removed before returning. The clone takes one of the
+// client's concurrent scan slots and is refused once scans are draining.
+func (s *Service) PlanScan(ctx context.Context, req ScanRequest) (*ScanPlan, error) {
+	requestID := logger.GetRequestID(ctx)
+
+	repoURL := NormalizeGitHubURL(req.RepoURL)
+	if err := ValidateGitHubURL(repoURL); err != nil {
+		return nil, err
+	}
+	subdir, verr := NormalizeSubdir(req.Subdir)
+	if verr != nil {
+		return nil, verr
+	}
+
+	planID := uuid.New().String()
+	slotCtx, err := s.claimPlan(planID, req.ClientID)
+	if err != nil {
+		s.log.Warn("scan_plan_rejected",
+			slog.String("request_id", requestID),
+			slog.String("repo_url", repoURL),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	defer s.untrackScan(planID)
+
+	// A drain that times out cancels the plan along with running scans
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer context.AfterFunc(slotCtx, cancel)()
+
+	start := time.Now()
+	cloneResult, err := s.cloner.Clone(ctx, repoURL)
+	if err != nil {
+		s.log.Warn("scan_plan_clone_failed",
+			slog.String("request_id", requestID),
+			slog.String("repo_url", repoURL),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	defer func() { _ = s.cloner.Cleanup(cloneResult.Path) }()
+
+	scanPath, err := resolveScanPath(cloneResult.Path, subdir)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := s.planRepository(scanPath)
+	if err != nil {
+		s.log.Error("scan_plan_failed",
+			slog.String("request_id", requestID),
+			slog.String("repo_url", repoURL),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	plan.RepoURL = repoURL
+	plan.Subdir = subdir
+
+	s.log.Info("scan_plan_complete",
+		slog.String("request_id", requestID),
+		slog.String("repo_url", repoURL),
+		slog.String("subdir", subdir),
+		slog.Int("language_count", len(plan.Languages)),
+		slog.Int("tool_count", len(plan.Tools)),
+		slog.Duration("duration", time.Since(start)),
+	)
+	return plan, nil
+}
+
+// planRepository detects languages in scanPath and selects tools the same
+// way runScan does.
+func (s *Service) planRepository(scanPath string) (*ScanPlan, error) {
+	detected, err := s.detector.Detect(scanPath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: language detection: %v", ErrScanFailed, err)
+	}
+
+	languages := SignificantLanguages(detected, s.minLanguagePercent, s.minLanguageFiles)
+	toolNames := s.toolRunner.GetToolsForLanguages(languages)
+
+	if detected == nil {
+		detected = []LanguageResult{}
+	}
+	plan := &ScanPlan{
+		Languages: detected,
+		Tools:     make([]PlannedTool, len(toolNames)),
+	}
+	var total time.Duration
+	for i, name := range toolNames {
+		timeout := s.toolRunner.timeoutFor(name)
+		plan.Tools[i] = PlannedTool{Name: name, TimeoutSeconds: int(timeout.Seconds())}
+		total += timeout
+	}
+	if s.maxScanDuration > 0 {
+		total = min(total, s.maxScanDuration)
+	}
+	plan.EstimatedMaxSeconds = int(total.Seconds())
+
+	return plan, nil
+}
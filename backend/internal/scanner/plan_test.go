@@ -0,0 +1,84 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestPlanRepository_GoAndPython(t *testing.T) {
+	repoPath := t.TempDir()
+	for _, file := range []string{"main.go", "server/handler.go", "scripts/build.py"} {
+		path := filepath.Join(repoPath, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("// source\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	exec := &fakeExecutor{}
+	db := &recordingDB{}
+	runner := NewToolRunner(WithExecutor(exec), WithToolTimeouts(map[string]time.Duration{"bandit": time.Minute}))
+	s := NewService(nil, nil, "", WithServiceToolRunner(runner), WithMaxScanDuration(time.Hour))
+	s.db = db
+
+	plan, err := s.planRepository(repoPath)
+	if err != nil {
+		t.Fatalf("planRepository() error = %v", err)
+	}
+
+	var languages []Language
+	for _, l := range plan.Languages {
+		languages = append(languages, l.Language)
+	}
+	if want := []Language{LangGo, LangPython}; !slices.Equal(languages, want) {
+		t.Errorf("languages = %v, want %v", languages, want)
+	}
+	if got := plan.Languages[0].Percentage; got < 66 || got > 67 {
+		t.Errorf("go percentage = %v, want about 66.7", got)
+	}
+
+	var tools []string
+	for _, tool := range plan.Tools {
+		tools = append(tools, tool.Name)
+	}
+	want := []string{"trivy", "semgrep", "trufflehog", "gitleaks", "govulncheck", "bandit", "pip-audit", "safety"}
+	if !slices.Equal(tools, want) {
+		t.Errorf("tools = %v, want %v", tools, want)
+	}
+
+	// Seven tools at the default timeout plus bandit's override
+	if wantSeconds := int((7*DefaultToolTimeout + time.Minute).Seconds()); plan.EstimatedMaxSeconds != wantSeconds {
+		t.Errorf("estimated max seconds = %d, want %d", plan.EstimatedMaxSeconds, wantSeconds)
+	}
+
+	if len(exec.calls) != 0 {
+		t.Errorf("tools ran during planning: %v", exec.calls)
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if len(db.execs) != 0 {
+		t.Errorf("planning wrote %d statements, want none", len(db.execs))
+	}
+}
+
+func TestPlanRepository_EstimateCappedByScanBudget(t *testing.T) {
+	repoPath := t.TempDir()
+	if err := os.WriteFile(filepath.Join(repoPath, "main.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewService(nil, nil, "", WithServiceToolRunner(NewToolRunner(WithExecutor(&fakeExecutor{}))), WithMaxScanDuration(2*time.Minute))
+
+	plan, err := s.planRepository(repoPath)
+	if err != nil {
+		t.Fatalf("planRepository() error = %v", err)
+	}
+	if plan.EstimatedMaxSeconds != 120 {
+		t.Errorf("estimated max seconds = %d, want the 120s scan budget", plan.EstimatedMaxSeconds)
+	}
+}
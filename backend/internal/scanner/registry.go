@@ -0,0 +1,161 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"better-kiro-prompts/internal/scancatalog"
+)
+
+// Tool selection errors.
+var (
+	ErrUnknownTool  = errors.New("unknown scanning tool")
+	ErrToolDisabled = errors.New("scanning tool disabled by configuration")
+)
+
+// toolDescriptor describes a scanning tool and how to run it.
+type toolDescriptor struct {
+	// name is the tool name reported in results.
+	name string
+	// languages selects the tool when any of them is detected; ignored for universal tools.
+	languages []Language
+	// universal tools run for every repository.
+	universal bool
+	// run executes the tool against a cloned repository.
+	run toolRun
+	// probe is the command used to check the tool is installed;
+	// defaults to "<name> --version".
+	probe []string
+}
+
+// toolRun executes one tool against a cloned repository.
+type toolRun func(r *ToolRunner, ctx context.Context, repoPath string) ToolResult
+
+// toolRuns maps each catalogued tool to how it runs. It must cover
+// scancatalog.Tools exactly; the package fails to initialize otherwise.
+var toolRuns = map[string]toolRun{
+	"trivy": (*ToolRunner).RunTrivy,
+	"semgrep": func(r *ToolRunner, ctx context.Context, repoPath string) ToolResult {
+		return r.RunSemgrep(ctx, repoPath, nil)
+	},
+	"trufflehog":    (*ToolRunner).RunTruffleHog,
+	"gitleaks":      (*ToolRunner).RunGitleaks,
+	"govulncheck":   (*ToolRunner).RunGovulncheck,
+	"bandit":        (*ToolRunner).RunBandit,
+	"pip-audit":     (*ToolRunner).RunPipAudit,
+	"safety":        (*ToolRunner).RunSafety,
+	"npm-audit":     (*ToolRunner).RunNpmAudit,
+	"cargo-audit":   (*ToolRunner).RunCargoAudit,
+	"bundler-audit": (*ToolRunner).RunBundlerAudit,
+	"brakeman":      (*ToolRunner).RunBrakeman,
+}
+
+// toolRegistry pairs every tool in scancatalog.Tools, the single source of
+// truth for which tools exist and when they run, with its runner. Order is
+// preserved by GetToolsForLanguages.
+var toolRegistry = func() []toolDescriptor {
+	registry, err := buildToolRegistry(scancatalog.Tools, toolRuns)
+	if err != nil {
+		panic(err)
+	}
+	return registry
+}()
+
+// buildToolRegistry pairs each catalogued tool with its runner. It fails
+// when a tool has no runner or a runner has no catalog entry, so the two
+// can't drift apart unnoticed.
+func buildToolRegistry(tools []scancatalog.Tool, runs map[string]toolRun) ([]toolDescriptor, error) {
+	registry := make([]toolDescriptor, 0, len(tools))
+	for _, tool := range tools {
+		run, ok := runs[tool.Name]
+		if !ok || run == nil {
+			return nil, fmt.Errorf("scanner: catalogued tool %q has no runner", tool.Name)
+		}
+		languages := make([]Language, len(tool.Languages))
+		for i, lang := range tool.Languages {
+			languages[i] = Language(lang)
+		}
+		registry = append(registry, toolDescriptor{
+			name:      tool.Name,
+			languages: languages,
+			universal: tool.Universal,
+			run:       run,
+			probe:     tool.Probe,
+		})
+	}
+	if len(registry) != len(runs) {
+		for name := range runs {
+			if !slices.ContainsFunc(tools, func(t scancatalog.Tool) bool { return t.Name == name }) {
+				return nil, fmt.Errorf("scanner: runner %q has no scancatalog entry", name)
+			}
+		}
+	}
+	return registry, nil
+}
+
+// toolsByName indexes toolRegistry by tool name.
+var toolsByName = func() map[string]toolDescriptor {
+	m := make(map[string]toolDescriptor, len(toolRegistry))
+	for _, tool := range toolRegistry {
+		m[tool.name] = tool
+	}
+	return m
+}()
+
+// appliesTo reports whether the tool should run for the detected languages.
+func (t toolDescriptor) appliesTo(langSet map[Language]bool) bool {
+	if t.universal {
+		return true
+	}
+	for _, lang := range t.languages {
+		if langSet[lang] {
+			return true
+		}
+	}
+	return false
+}
+
+// GetToolsForLanguages returns the list of tools to run for the given languages.
+// Disabled tools and tools that failed the last self-test are skipped.
+func (r *ToolRunner) GetToolsForLanguages(languages []Language) []string {
+	langSet := make(map[Language]bool)
+	for _, lang := range languages {
+		langSet[lang] = true
+	}
+
+	var tools []string
+	for _, tool := range toolRegistry {
+		if tool.appliesTo(langSet) && r.isEnabled(tool.name) && r.isAvailable(tool.name) {
+			tools = append(tools, tool.name)
+		}
+	}
+	return tools
+}
+
+// RunToolByName runs a specific tool by name. Disabled tools are never run.
+func (r *ToolRunner) RunToolByName(ctx context.Context, toolName string, repoPath string, languages []Language) ToolResult {
+	tool, ok := toolsByName[toolName]
+	if !ok || tool.run == nil {
+		return ToolResult{
+			Tool:  toolName,
+			Error: fmt.Errorf("%w: %s", ErrUnknownTool, toolName),
+		}
+	}
+	if !r.isEnabled(toolName) {
+		return ToolResult{
+			Tool:  toolName,
+			Error: fmt.Errorf("%w: %s", ErrToolDisabled, toolName),
+		}
+	}
+	return tool.run(r, ctx, repoPath)
+}
+
+// isEnabled reports whether operator configuration allows the tool to run.
+func (r *ToolRunner) isEnabled(name string) bool {
+	if r.disabled[name] {
+		return false
+	}
+	return r.enabled == nil || r.enabled[name]
+}
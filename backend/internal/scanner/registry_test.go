@@ -0,0 +1,132 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"better-kiro-prompts/internal/config"
+	"better-kiro-prompts/internal/scancatalog"
+)
+
+// TestToolRegistry_EveryToolIsRunnable tests that every tool GetToolsForLanguages can
+// return has a runner registered under the same name.
+func TestToolRegistry_EveryToolIsRunnable(t *testing.T) {
+	r := NewToolRunner()
+	allLanguages := NewLanguageDetector().GetSupportedLanguages()
+
+	returned := make(map[string]bool)
+	for _, lang := range append(allLanguages, LangUnknown) {
+		for _, name := range r.GetToolsForLanguages([]Language{lang}) {
+			tool, ok := toolsByName[name]
+			if !ok {
+				t.Errorf("GetToolsForLanguages(%s) returned %q, which RunToolByName cannot run", lang, name)
+				continue
+			}
+			if tool.run == nil {
+				t.Errorf("tool %q has no runner", name)
+			}
+			returned[name] = true
+		}
+	}
+
+	for _, tool := range toolRegistry {
+		if !returned[tool.name] {
+			t.Errorf("tool %q is registered but never selected for any language", tool.name)
+		}
+	}
+}
+
+// TestToolRegistry_UniqueNames tests that no two registry entries share a name.
+func TestToolRegistry_UniqueNames(t *testing.T) {
+	if len(toolsByName) != len(toolRegistry) {
+		t.Errorf("toolRegistry has %d entries but %d unique names", len(toolRegistry), len(toolsByName))
+	}
+}
+
+func TestBuildToolRegistry_RejectsMismatch(t *testing.T) {
+	run := toolRuns["trivy"]
+	tests := []struct {
+		name  string
+		tools []scancatalog.Tool
+		runs  map[string]toolRun
+		want  string
+	}{
+		{"tool without runner", []scancatalog.Tool{{Name: "trivy"}, {Name: "newtool"}}, map[string]toolRun{"trivy": run}, `"newtool" has no runner`},
+		{"runner without tool", []scancatalog.Tool{{Name: "trivy"}}, map[string]toolRun{"trivy": run, "oldtool": run}, `"oldtool" has no scancatalog entry`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := buildToolRegistry(tt.tools, tt.runs)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("buildToolRegistry() error = %v, want it to mention %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunToolByName_UnknownToolError(t *testing.T) {
+	result := NewToolRunner().RunToolByName(context.Background(), "not-a-tool", "/tmp", nil)
+	if !errors.Is(result.Error, ErrUnknownTool) {
+		t.Errorf("expected ErrUnknownTool, got %v", result.Error)
+	}
+	if result.Tool != "not-a-tool" {
+		t.Errorf("Tool = %q, want not-a-tool", result.Tool)
+	}
+}
+
+func TestGetToolsForLanguages_DisabledToolRemoved(t *testing.T) {
+	r := NewToolRunner(WithToolSelection(nil, []string{"semgrep"}))
+
+	got := r.GetToolsForLanguages([]Language{LangGo})
+	want := []string{"trivy", "trufflehog", "gitleaks", "govulncheck"}
+	if !slices.Equal(got, want) {
+		t.Errorf("GetToolsForLanguages() = %v, want %v", got, want)
+	}
+}
+
+func TestGetToolsForLanguages_EnabledToolsOnly(t *testing.T) {
+	r := NewToolRunner(WithToolSelection([]string{"trivy", "govulncheck", "bandit"}, []string{"trivy"}))
+
+	// bandit is enabled but Python was not detected; trivy is both, and disabled wins
+	got := r.GetToolsForLanguages([]Language{LangGo})
+	if want := []string{"govulncheck"}; !slices.Equal(got, want) {
+		t.Errorf("GetToolsForLanguages() = %v, want %v", got, want)
+	}
+}
+
+func TestRunToolByName_DisabledToolNeverInvoked(t *testing.T) {
+	exec := &fakeExecutor{}
+	r := NewToolRunner(WithExecutor(exec), WithToolSelection(nil, []string{"semgrep"}))
+
+	result := r.RunToolByName(context.Background(), "semgrep", "/repo", nil)
+	if !errors.Is(result.Error, ErrToolDisabled) {
+		t.Errorf("expected ErrToolDisabled, got %v", result.Error)
+	}
+
+	status := toolAvailability(r.SelfTest(context.Background()))
+	for _, tool := range status {
+		if tool.Name == "semgrep" && (!tool.Disabled || tool.Available) {
+			t.Errorf("semgrep status = %+v, want disabled", tool)
+		}
+	}
+	for _, call := range exec.calls {
+		if strings.HasPrefix(call, "semgrep") {
+			t.Errorf("disabled tool was executed: %q", call)
+		}
+	}
+}
+
+// TestToolRegistry_NamesAcceptedByConfig tests that config validation knows
+// every registered tool, so operators can enable or disable any of them.
+func TestToolRegistry_NamesAcceptedByConfig(t *testing.T) {
+	cfg := config.DefaultConfig()
+	for _, tool := range toolRegistry {
+		cfg.Scanner.DisabledTools = append(cfg.Scanner.DisabledTools, tool.name)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("config rejects registered tool names: %v", err)
+	}
+}
@@ -0,0 +1,135 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"better-kiro-prompts/internal/logger"
+)
+
+// On-demand remediation errors.
+var (
+	ErrFindingNotFound = errors.New("finding not found")
+	ErrScanNotComplete = errors.New("scan is not complete")
+)
+
+// RemediateFinding generates AI remediation for a single finding of a
+// completed scan, for findings the scan's review skipped or failed on. It
+// reviews against the scan's retained clone when there is one, and otherwise
+// clones the repository again. The remediation is stored with the finding
+// and the updated finding is returned; a finding that already has
+// remediation is returned as is without calling the model.
+func (s *Service) RemediateFinding(ctx context.Context, jobID, findingID string) (*Finding, error) {
+	requestID := logger.GetRequestID(ctx)
+
+	job, err := s.loadJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != StatusCompleted {
+		return nil, fmt.Errorf("%w: status is %s", ErrScanNotComplete, job.Status)
+	}
+
+	var finding *Finding
+	for i := range job.Findings {
+		if job.Findings[i].ID == findingID {
+			finding = &job.Findings[i]
+			break
+		}
+	}
+	if finding == nil {
+		return nil, fmt.Errorf("%w: %s", ErrFindingNotFound, findingID)
+	}
+	if finding.Remediation != "" {
+		return finding, nil
+	}
+	if !s.reviewer.HasClient() {
+		return nil, ErrReviewUnavailable
+	}
+
+	s.log.Info("scan_remediate_start",
+		slog.String("request_id", requestID),
+		slog.String("job_id", jobID),
+		slog.String("finding_id", findingID),
+	)
+
+	if repoPath, release, ok := s.acquireRetainedClone(jobID, time.Now()); ok {
+		defer release()
+		return s.remediateInSubdir(ctx, job, repoPath, *finding)
+	}
+
+	cloneResult, err := s.cloner.Clone(ctx, job.RepoURL)
+	if err != nil {
+		s.log.Error("scan_remediate_clone_failed",
+			slog.String("request_id", requestID),
+			slog.String("job_id", jobID),
+			slog.String("error", err.Error()),
+		)
+		return nil, fmt.Errorf("clone failed: %w", err)
+	}
+	defer func() { _ = s.cloner.Cleanup(cloneResult.Path) }()
+
+	return s.remediateInSubdir(ctx, job, cloneResult.Path, *finding)
+}
+
+// remediateInSubdir remediates a finding against the directory the job
+// scanned, since finding paths are relative to it.
+func (s *Service) remediateInSubdir(ctx context.Context, job *ScanJob, repoPath string, finding Finding) (*Finding, error) {
+	scanPath, err := resolveScanPath(repoPath, job.Subdir)
+	if err != nil {
+		return nil, fmt.Errorf("subdirectory %q: %w", job.Subdir, err)
+	}
+	return s.remediateInRepo(ctx, job.ID, scanPath, finding)
+}
+
+// remediateInRepo reviews a finding against a cloned repository and stores
+// the remediation.
+func (s *Service) remediateInRepo(ctx context.Context, jobID, repoPath string, finding Finding) (*Finding, error) {
+	requestID := logger.GetRequestID(ctx)
+	start := time.Now()
+
+	reviewed, err := s.reviewer.ReviewFinding(ctx, repoPath, finding)
+	if err != nil {
+		s.log.Warn("scan_remediate_review_failed",
+			slog.String("request_id", requestID),
+			slog.String("job_id", jobID),
+			slog.String("finding_id", finding.ID),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	if err := s.updateFindingRemediation(ctx, jobID, reviewed); err != nil {
+		s.log.Error("scan_remediate_store_failed",
+			slog.String("request_id", requestID),
+			slog.String("job_id", jobID),
+			slog.String("finding_id", finding.ID),
+			slog.String("error", err.Error()),
+		)
+		return nil, fmt.Errorf("failed to store remediation: %w", err)
+	}
+
+	s.log.Info("scan_remediate_complete",
+		slog.String("request_id", requestID),
+		slog.String("job_id", jobID),
+		slog.String("finding_id", finding.ID),
+		slog.Duration("duration", time.Since(start)),
+	)
+
+	return &reviewed, nil
+}
+
+func (s *Service) updateFindingRemediation(ctx context.Context, jobID string, f Finding) error {
+	query := `UPDATE scan_findings SET remediation = $1, code_example = $2 WHERE id = $3 AND scan_job_id = $4`
+
+	var codeExample *string
+	if f.CodeExample != "" {
+		codeExample = &f.CodeExample
+	}
+
+	_, err := s.db.ExecContext(ctx, query, f.Remediation, codeExample, f.ID, jobID)
+	return err
+}
@@ -0,0 +1,115 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"better-kiro-prompts/internal/openai"
+)
+
+func newRemediateTestService(t *testing.T, responses ...string) (*Service, *recordingDB, string) {
+	t.Helper()
+	repoPath := t.TempDir()
+	if err := os.WriteFile(filepath.Join(repoPath, "app.py"), []byte("import os\nos.system(cmd)\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	reviewer := NewCodeReviewer(nil)
+	reviewer.client = openai.NewFakeClient(responses...)
+	db := &recordingDB{}
+	s := NewService(nil, nil, "", WithServiceCodeReviewer(reviewer))
+	s.db = db
+	return s, db, repoPath
+}
+
+func TestRemediateInRepo_StoresRemediation(t *testing.T) {
+	s, db, repoPath := newRemediateTestService(t,
+		`{"findings": [{"file_path": "app.py", "line_number": 2, "remediation": "Use subprocess.run with a list", "code_example": "subprocess.run([cmd])"}]}`)
+
+	// Low severity is below the scan's review threshold but can be remediated on demand
+	line := 2
+	finding := Finding{ID: "f-1", Severity: SeverityLow, Tool: "bandit", FilePath: "app.py", LineNumber: &line}
+
+	got, err := s.remediateInRepo(context.Background(), "job-1", repoPath, finding)
+	if err != nil {
+		t.Fatalf("remediateInRepo: %v", err)
+	}
+	if got.Remediation != "Use subprocess.run with a list" || got.CodeExample != "subprocess.run([cmd])" {
+		t.Errorf("finding = %+v, want remediation filled in", got)
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if len(db.execs) != 1 || !strings.Contains(db.execs[0].query, "UPDATE scan_findings") {
+		t.Fatalf("expected a single finding update, got %+v", db.execs)
+	}
+	args := db.execs[0].args
+	if args[0] != "Use subprocess.run with a list" || args[2] != "f-1" || args[3] != "job-1" {
+		t.Errorf("update args = %v", args)
+	}
+}
+
+func TestRemediateInRepo_NoRemediation(t *testing.T) {
+	s, db, repoPath := newRemediateTestService(t, `{"findings": []}`)
+
+	finding := Finding{ID: "f-1", Severity: SeverityHigh, FilePath: "app.py"}
+	if _, err := s.remediateInRepo(context.Background(), "job-1", repoPath, finding); !errors.Is(err, ErrNoRemediation) {
+		t.Fatalf("expected ErrNoRemediation, got %v", err)
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if len(db.execs) != 0 {
+		t.Errorf("expected nothing stored, got %d writes", len(db.execs))
+	}
+}
+
+func TestCodeReviewer_ReviewFindingWithoutClient(t *testing.T) {
+	r := NewCodeReviewer(nil)
+	if _, err := r.ReviewFinding(context.Background(), t.TempDir(), Finding{ID: "f-1"}); !errors.Is(err, ErrReviewUnavailable) {
+		t.Errorf("expected ErrReviewUnavailable, got %v", err)
+	}
+}
+
+func TestExplain_CachesByRule(t *testing.T) {
+	s, db, _ := newRemediateTestService(t, "Starting a process through a shell lets crafted input run extra commands.")
+	client := s.reviewer.client.(*openai.FakeClient)
+
+	finding := Finding{ID: "f-1", Severity: SeverityHigh, Tool: "bandit", RuleID: "B602", Description: "subprocess call with shell=True"}
+	first, err := s.explain(context.Background(), "job-1", finding)
+	if err != nil {
+		t.Fatalf("explain: %v", err)
+	}
+	if first.Cached || !strings.Contains(first.Explanation, "shell") {
+		t.Errorf("first explanation = %+v, want a fresh model answer", first)
+	}
+	calls := client.Calls()
+	if len(calls) != 1 {
+		t.Fatalf("expected 1 model call, got %d", len(calls))
+	}
+	if prompt := calls[0].Messages[1].Content; !strings.Contains(prompt, "B602") || !strings.Contains(prompt, "shell=True") {
+		t.Errorf("prompt %q does not carry the rule and description", prompt)
+	}
+
+	// Another finding of the same rule is served from the cache
+	second, err := s.explain(context.Background(), "job-2", Finding{ID: "f-9", Tool: "bandit", RuleID: "B602"})
+	if err != nil {
+		t.Fatalf("explain: %v", err)
+	}
+	if !second.Cached || second.Explanation != first.Explanation || second.FindingID != "f-9" {
+		t.Errorf("second explanation = %+v, want the cached answer", second)
+	}
+	if got := client.CallCount(); got != 1 {
+		t.Errorf("model calls = %d, want 1", got)
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if len(db.execs) != 0 {
+		t.Errorf("explanations should not be stored, got %d writes", len(db.execs))
+	}
+}
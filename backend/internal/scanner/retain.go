@@ -0,0 +1,159 @@
+package scanner
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// cloneSweepInterval is how often RunCloneSweeper removes expired clones.
+const cloneSweepInterval = time.Minute
+
+// orphanCloneGrace is added to the longest a clone can legitimately live
+// before RemoveOrphanedClones treats it as abandoned.
+const orphanCloneGrace = 10 * time.Minute
+
+// retainedClone is a cloned repository kept after its scan finished so
+// on-demand features can use it without cloning again. Retained clones are
+// tracked in memory only: after a restart they are no longer reused, and
+// RemoveOrphanedClones deletes them.
+type retainedClone struct {
+	path      string
+	expiresAt time.Time
+	// Number of callers using the clone; the sweeper skips clones in use
+	inUse int
+}
+
+// WithRetainClone keeps each scan's cloned repository for d after the scan
+// finishes. Zero removes clones as soon as the scan ends.
+func WithRetainClone(d time.Duration) ServiceOption {
+	return func(s *Service) {
+		if d >= 0 {
+			s.retainClone = d
+		}
+	}
+}
+
+// releaseClone removes a finished scan's clone, or keeps it until the
+// retention window expires. Scans abandoned by DrainScans are always removed.
+func (s *Service) releaseClone(ctx context.Context, jobID, repoPath string) {
+	if s.retainClone <= 0 || ctx.Err() != nil {
+		s.log.Debug("scan_cleanup_start",
+			slog.String("job_id", jobID),
+			slog.String("path", repoPath),
+		)
+		_ = s.cloner.Cleanup(repoPath)
+		return
+	}
+
+	expiresAt := time.Now().Add(s.retainClone)
+	s.retainedMu.Lock()
+	if s.retained == nil {
+		s.retained = make(map[string]*retainedClone)
+	}
+	s.retained[jobID] = &retainedClone{path: repoPath, expiresAt: expiresAt}
+	s.retainedMu.Unlock()
+
+	s.log.Debug("scan_clone_retained",
+		slog.String("job_id", jobID),
+		slog.Time("expires_at", expiresAt),
+	)
+}
+
+// acquireRetainedClone returns the retained clone for a job, if it has not
+// expired. The clone is not swept until release is called.
+func (s *Service) acquireRetainedClone(jobID string, now time.Time) (string, func(), bool) {
+	s.retainedMu.Lock()
+	defer s.retainedMu.Unlock()
+
+	clone, ok := s.retained[jobID]
+	if !ok || !now.Before(clone.expiresAt) {
+		return "", nil, false
+	}
+	clone.inUse++
+
+	release := func() {
+		s.retainedMu.Lock()
+		defer s.retainedMu.Unlock()
+		clone.inUse--
+	}
+	return clone.path, release, true
+}
+
+// SweepRetainedClones removes retained clones that expired before now and
+// are not in use. It returns how many were removed.
+func (s *Service) SweepRetainedClones(now time.Time) int {
+	s.retainedMu.Lock()
+	var expired []string
+	for jobID, clone := range s.retained {
+		if clone.inUse == 0 && !now.Before(clone.expiresAt) {
+			expired = append(expired, clone.path)
+			delete(s.retained, jobID)
+		}
+	}
+	s.retainedMu.Unlock()
+
+	for _, path := range expired {
+		if err := s.cloner.Cleanup(path); err != nil {
+			s.log.Warn("scan_clone_sweep_failed",
+				slog.String("path", path),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+	if len(expired) > 0 {
+		s.log.Info("scan_clones_swept", slog.Int("removed", len(expired)))
+	}
+	return len(expired)
+}
+
+// RunCloneSweeper removes expired retained clones every minute until ctx is
+// done. It returns immediately when clone retention is disabled.
+func (s *Service) RunCloneSweeper(ctx context.Context) {
+	if s.retainClone <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(cloneSweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.SweepRetainedClones(now)
+		}
+	}
+}
+
+// RemoveOrphanedClones deletes clone directories that no running process can
+// still be using, such as those left behind by a crash. A clone lives at most
+// the maximum scan duration plus the retention window, so older directories
+// are safe to remove even when several servers share the clone directory.
+// It returns how many were removed.
+func (s *Service) RemoveOrphanedClones(now time.Time) int {
+	cutoff := now.Add(-(s.maxScanDuration + s.retainClone + orphanCloneGrace))
+	removed, err := s.cloner.RemoveStale(cutoff)
+	if err != nil {
+		s.log.Warn("scan_orphaned_clone_cleanup_failed",
+			slog.String("error", err.Error()),
+		)
+	}
+	if removed > 0 {
+		s.log.Info("scan_orphaned_clones_removed", slog.Int("removed", removed))
+	}
+	return removed
+}
+
+// RemoveRetainedClones removes every retained clone regardless of expiry.
+// It is called on shutdown so clones do not outlive the server.
+func (s *Service) RemoveRetainedClones() {
+	s.retainedMu.Lock()
+	clones := s.retained
+	s.retained = nil
+	s.retainedMu.Unlock()
+
+	for _, clone := range clones {
+		_ = s.cloner.Cleanup(clone.path)
+	}
+}
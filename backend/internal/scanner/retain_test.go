@@ -0,0 +1,140 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newRetainTestService returns a service whose cloner works in a temp dir,
+// plus a fake clone inside it.
+func newRetainTestService(t *testing.T, retain time.Duration) (*Service, string) {
+	t.Helper()
+	tempDir := t.TempDir()
+	repoPath := filepath.Join(tempDir, "scan-repo-1")
+	if err := os.MkdirAll(repoPath, 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	s := NewService(nil, nil, "",
+		WithServiceCloner(NewCloner(WithTempDir(tempDir))),
+		WithRetainClone(retain),
+	)
+	return s, repoPath
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func TestReleaseClone_NoRetentionRemovesImmediately(t *testing.T) {
+	s, repoPath := newRetainTestService(t, 0)
+
+	s.releaseClone(context.Background(), "job-1", repoPath)
+
+	if exists(repoPath) {
+		t.Error("expected the clone to be removed when retention is disabled")
+	}
+	if _, _, ok := s.acquireRetainedClone("job-1", time.Now()); ok {
+		t.Error("expected no retained clone")
+	}
+}
+
+func TestReleaseClone_RetainedUntilSwept(t *testing.T) {
+	s, repoPath := newRetainTestService(t, 10*time.Minute)
+
+	s.releaseClone(context.Background(), "job-1", repoPath)
+	if !exists(repoPath) {
+		t.Fatal("expected the clone to be retained")
+	}
+	path, release, ok := s.acquireRetainedClone("job-1", time.Now())
+	if !ok || path != repoPath {
+		t.Fatalf("acquireRetainedClone = %q, %v; want %q", path, ok, repoPath)
+	}
+	release()
+
+	// Within the window the sweeper leaves it alone
+	if n := s.SweepRetainedClones(time.Now()); n != 0 {
+		t.Errorf("swept %d clones before expiry, want 0", n)
+	}
+	if !exists(repoPath) {
+		t.Fatal("clone removed before its retention window expired")
+	}
+
+	later := time.Now().Add(11 * time.Minute)
+	if n := s.SweepRetainedClones(later); n != 1 {
+		t.Errorf("swept %d clones after expiry, want 1", n)
+	}
+	if exists(repoPath) {
+		t.Error("expected the expired clone to be removed")
+	}
+	if _, _, ok := s.acquireRetainedClone("job-1", time.Now()); ok {
+		t.Error("expected the swept clone to be gone")
+	}
+}
+
+func TestSweepRetainedClones_SkipsClonesInUse(t *testing.T) {
+	s, repoPath := newRetainTestService(t, time.Minute)
+	s.releaseClone(context.Background(), "job-1", repoPath)
+
+	_, release, ok := s.acquireRetainedClone("job-1", time.Now())
+	if !ok {
+		t.Fatal("expected the retained clone")
+	}
+	later := time.Now().Add(2 * time.Minute)
+	if n := s.SweepRetainedClones(later); n != 0 || !exists(repoPath) {
+		t.Fatal("a clone in use must not be swept")
+	}
+
+	release()
+	if n := s.SweepRetainedClones(later); n != 1 || exists(repoPath) {
+		t.Error("expected the clone to be swept once released")
+	}
+}
+
+func TestReleaseClone_AbandonedScanRemoved(t *testing.T) {
+	s, repoPath := newRetainTestService(t, 10*time.Minute)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	s.releaseClone(ctx, "job-1", repoPath)
+
+	if exists(repoPath) {
+		t.Error("expected a drained scan's clone to be removed despite retention")
+	}
+}
+
+func TestRemoveOrphanedClones(t *testing.T) {
+	s, orphan := newRetainTestService(t, 10*time.Minute)
+	dir := filepath.Dir(orphan)
+	recent := filepath.Join(dir, "scan-repo-2")
+	unrelated := filepath.Join(dir, "other")
+	for _, p := range []string{recent, unrelated} {
+		if err := os.MkdirAll(p, 0o755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+	}
+
+	// Only clones older than a scan plus its retention window are orphaned
+	old := time.Now().Add(-2 * time.Hour)
+	for _, p := range []string{orphan, unrelated} {
+		if err := os.Chtimes(p, old, old); err != nil {
+			t.Fatalf("Chtimes: %v", err)
+		}
+	}
+
+	if removed := s.RemoveOrphanedClones(time.Now()); removed != 1 {
+		t.Errorf("removed %d clones, want 1", removed)
+	}
+	if exists(orphan) {
+		t.Error("expected the orphaned clone to be removed")
+	}
+	if !exists(recent) {
+		t.Error("expected a clone that may still be in use to be kept")
+	}
+	if !exists(unrelated) {
+		t.Error("expected a directory the cloner did not create to be kept")
+	}
+}
@@ -4,12 +4,15 @@ import (
 	"better-kiro-prompts/internal/openai"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 )
 
 // Default configuration for code review.
@@ -17,22 +20,51 @@ const (
 	DefaultMaxFilesToReview    = 10
 	DefaultMaxFindingsToReview = 10
 	DefaultMaxFileSize         = 50 * 1024 // 50KB max file size
+	// MaxReviewFileSize caps WithMaxFileSize, since each flagged file is read into a buffer this large
+	MaxReviewFileSize = 1024 * 1024
+	// DefaultReviewMinSeverity reviews critical, high and medium findings
+	DefaultReviewMinSeverity = SeverityMedium
 )
 
-// ReviewableSeverities defines which severities get AI review (high and medium only)
-var ReviewableSeverities = map[string]bool{
-	"critical": true,
-	"high":     true,
-	"medium":   true,
-	// "low" and "info" are excluded
+// Reviewer errors.
+var (
+	ErrReviewUnavailable = errors.New("ai review is not configured")
+	ErrNoRemediation     = errors.New("no remediation returned")
+)
+
+// reviewClient is the subset of the OpenAI client used by the reviewer.
+type reviewClient interface {
+	ChatCompletionWithModel(ctx context.Context, messages []openai.Message, model string) (string, error)
+}
+
+// newReviewClient converts an optional OpenAI client, keeping a nil client nil.
+func newReviewClient(client *openai.Client) reviewClient {
+	if client == nil {
+		return nil
+	}
+	return client
 }
 
 // CodeReviewer uses AI to provide remediation guidance for security findings.
 type CodeReviewer struct {
-	client   *openai.Client
+	client   reviewClient
 	maxFiles int
 	model    string
 	log      *slog.Logger
+
+	// Per-language model overrides, picked by the reviewed files' dominant language
+	languageModels map[Language]string
+	detector       *LanguageDetector
+
+	// Lowest severity sent for review
+	minSeverity string
+	// Most findings sent per review, and the byte limit per file read
+	maxFindings int
+	maxFileSize int64
+	// Deadline for the model call; zero leaves the client's timeout in effect
+	timeout time.Duration
+	// System prompt sent with every review
+	systemPrompt string
 }
 
 // CodeReviewerOption is a functional option for configuring a CodeReviewer.
@@ -45,6 +77,34 @@ func WithMaxFiles(max int) CodeReviewerOption {
 	}
 }
 
+// WithMaxFindings sets the maximum number of findings sent for review.
+func WithMaxFindings(max int) CodeReviewerOption {
+	return func(r *CodeReviewer) {
+		if max > 0 {
+			r.maxFindings = max
+		}
+	}
+}
+
+// WithMaxFileSize sets how many bytes of each flagged file are sent for
+// review; longer files are truncated. Sizes above MaxReviewFileSize are capped.
+func WithMaxFileSize(bytes int64) CodeReviewerOption {
+	return func(r *CodeReviewer) {
+		if bytes > 0 {
+			r.maxFileSize = min(bytes, MaxReviewFileSize)
+		}
+	}
+}
+
+// WithReviewTimeout sets the deadline for the review model call.
+func WithReviewTimeout(timeout time.Duration) CodeReviewerOption {
+	return func(r *CodeReviewer) {
+		if timeout > 0 {
+			r.timeout = timeout
+		}
+	}
+}
+
 // WithModel sets the model to use for code review.
 func WithModel(model string) CodeReviewerOption {
 	return func(r *CodeReviewer) {
@@ -52,13 +112,50 @@ func WithModel(model string) CodeReviewerOption {
 	}
 }
 
+// WithLanguageModels sets per-language review models. A review uses the
+// model for the most common language among the files it sends, and the
+// default model when that language has no override.
+func WithLanguageModels(models map[Language]string) CodeReviewerOption {
+	return func(r *CodeReviewer) {
+		r.languageModels = models
+	}
+}
+
+// WithReviewMinSeverity sets the lowest severity that gets AI review.
+// Unknown values are ignored and the default is kept.
+func WithReviewMinSeverity(severity string) CodeReviewerOption {
+	return func(r *CodeReviewer) {
+		if IsValidSeverity(severity) {
+			r.minSeverity = severity
+		}
+	}
+}
+
+// WithReviewSystemPrompt replaces the built-in review system prompt, for
+// teams with their own remediation style. The prompt must still ask for the
+// ReviewResponse JSON; config validation checks this. Empty keeps the default.
+func WithReviewSystemPrompt(prompt string) CodeReviewerOption {
+	return func(r *CodeReviewer) {
+		if prompt != "" {
+			r.systemPrompt = prompt
+		}
+	}
+}
+
 // NewCodeReviewer creates a new CodeReviewer.
 func NewCodeReviewer(client *openai.Client, opts ...CodeReviewerOption) *CodeReviewer {
 	r := &CodeReviewer{
-		client:   client,
+		client:   newReviewClient(client),
 		maxFiles: DefaultMaxFilesToReview,
 		model:    "gpt-5.1-codex-max", // Use codex model for security code review
 		log:      slog.Default().With("component", "reviewer"),
+		detector: NewLanguageDetector(),
+
+		minSeverity: DefaultReviewMinSeverity,
+		maxFindings: DefaultMaxFindingsToReview,
+		maxFileSize: DefaultMaxFileSize,
+
+		systemPrompt: codeReviewSystemPrompt,
 	}
 	for _, opt := range opts {
 		opt(r)
@@ -66,7 +163,8 @@ func NewCodeReviewer(client *openai.Client, opts ...CodeReviewerOption) *CodeRev
 	return r
 }
 
-// codeReviewSystemPrompt is the system prompt for the AI code reviewer.
+// codeReviewSystemPrompt is the default system prompt for the AI code
+// reviewer, replaced by scanner.review_prompt when configured.
 const codeReviewSystemPrompt = `You are a security code reviewer. Your task is to analyze code files that have been flagged by security scanning tools and provide actionable remediation guidance.
 
 For each finding:
@@ -111,13 +209,14 @@ type ReviewResult struct {
 // ReviewStats tracks AI review statistics.
 type ReviewStats struct {
 	TotalFindings      int `json:"total_findings"`
-	ReviewableFindings int `json:"reviewable_findings"` // high/medium/critical only
-	ReviewedFindings   int `json:"reviewed_findings"`   // actually sent to AI (max 10)
+	ReviewableFindings int `json:"reviewable_findings"` // at or above the review threshold
+	ReviewedFindings   int `json:"reviewed_findings"`   // actually sent to AI
 	MatchedFindings    int `json:"matched_findings"`    // successfully matched with AI response
 }
 
 // Review analyzes findings and adds AI-generated remediation guidance.
-// It only reviews high/medium severity findings, limited to DefaultMaxFindingsToReview.
+// It only reviews findings at or above the configured minimum severity
+// (medium by default), limited to the configured maximum number of findings.
 func (r *CodeReviewer) Review(ctx context.Context, repoPath string, findings []Finding) (ReviewResult, error) {
 	stats := ReviewStats{TotalFindings: len(findings)}
 
@@ -132,10 +231,10 @@ func (r *CodeReviewer) Review(ctx context.Context, repoPath string, findings []F
 		return ReviewResult{Findings: findings, Stats: stats}, nil
 	}
 
-	// Filter to only high/medium severity findings
+	// Filter to findings at or above the review threshold
 	var reviewableFindings []Finding
 	for _, f := range findings {
-		if ReviewableSeverities[f.Severity] {
+		if r.isReviewable(f.Severity) {
 			reviewableFindings = append(reviewableFindings, f)
 		}
 	}
@@ -143,22 +242,54 @@ func (r *CodeReviewer) Review(ctx context.Context, repoPath string, findings []F
 
 	r.log.Info("findings_filtered",
 		slog.Int("total", len(findings)),
-		slog.Int("reviewable", len(reviewableFindings)))
+		slog.Int("reviewable", len(reviewableFindings)),
+		slog.String("min_severity", r.minSeverity))
 
 	if len(reviewableFindings) == 0 {
-		r.log.Info("review_skipped", slog.String("reason", "no_high_medium_findings"))
+		r.log.Info("review_skipped", slog.String("reason", "no_reviewable_findings"))
 		return ReviewResult{Findings: findings, Stats: stats}, nil
 	}
 
 	// Limit to max findings for review
-	if len(reviewableFindings) > DefaultMaxFindingsToReview {
-		reviewableFindings = reviewableFindings[:DefaultMaxFindingsToReview]
-		r.log.Info("findings_limited", slog.Int("limit", DefaultMaxFindingsToReview))
+	if len(reviewableFindings) > r.maxFindings {
+		reviewableFindings = reviewableFindings[:r.maxFindings]
+		r.log.Info("findings_limited", slog.Int("limit", r.maxFindings))
 	}
 	stats.ReviewedFindings = len(reviewableFindings)
 
+	mergedFindings, matchCount, err := r.requestRemediation(ctx, repoPath, reviewableFindings, findings)
+	if err != nil {
+		// Review failures leave findings without remediation
+		return ReviewResult{Findings: findings, Stats: stats}, nil
+	}
+	stats.MatchedFindings = matchCount
+
+	return ReviewResult{Findings: mergedFindings, Stats: stats}, nil
+}
+
+// ReviewFinding requests remediation for a single finding regardless of the
+// severity threshold. It returns ErrNoRemediation if the model gave none.
+func (r *CodeReviewer) ReviewFinding(ctx context.Context, repoPath string, finding Finding) (Finding, error) {
+	if r.client == nil {
+		return finding, ErrReviewUnavailable
+	}
+
+	merged, matchCount, err := r.requestRemediation(ctx, repoPath, []Finding{finding}, []Finding{finding})
+	if err != nil {
+		return finding, err
+	}
+	if matchCount == 0 || merged[0].Remediation == "" {
+		return finding, ErrNoRemediation
+	}
+	return merged[0], nil
+}
+
+// requestRemediation reads the files behind reviewable findings, asks the
+// model for remediation, and merges it into findings. It returns the merged
+// findings and how many were matched.
+func (r *CodeReviewer) requestRemediation(ctx context.Context, repoPath string, reviewable, findings []Finding) ([]Finding, int, error) {
 	// Get unique files with findings, prioritized by severity
-	filesToReview := r.selectFilesToReview(reviewableFindings)
+	filesToReview := r.selectFilesToReview(reviewable)
 	r.log.Info("files_selected", slog.Int("count", len(filesToReview)))
 
 	// Read file contents
@@ -187,48 +318,79 @@ func (r *CodeReviewer) Review(ctx context.Context, repoPath string, findings []F
 	r.log.Info("files_read", slog.Int("count", len(fileContents)))
 
 	if len(fileContents) == 0 {
-		// No files could be read
-		return ReviewResult{Findings: findings, Stats: stats}, nil
+		return nil, 0, fmt.Errorf("%w: no files could be read", ErrNoRemediation)
 	}
 
 	// Build the review request
-	userPrompt := r.buildUserPrompt(reviewableFindings, fileContents)
+	userPrompt := r.buildUserPrompt(reviewable, fileContents)
 
 	// Call the AI with codex model
 	messages := []openai.Message{
-		{Role: "system", Content: codeReviewSystemPrompt},
+		{Role: "system", Content: r.systemPrompt},
 		{Role: "user", Content: userPrompt},
 	}
 
-	response, err := r.client.ChatCompletionWithModel(ctx, messages, r.model)
+	callCtx := ctx
+	if r.timeout > 0 {
+		var cancel context.CancelFunc
+		callCtx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+
+	model := r.modelFor(filesToReview)
+	response, err := r.client.ChatCompletionWithModel(callCtx, messages, model)
 	if err != nil {
-		// AI review failed, log and return findings without remediation
 		r.log.Error("ai_review_failed", slog.String("error", err.Error()))
-		return ReviewResult{Findings: findings, Stats: stats}, nil
+		return nil, 0, fmt.Errorf("ai review failed: %w", err)
 	}
 
-	r.log.Info("ai_response_received", slog.Int("length", len(response)))
+	r.log.Info("ai_response_received", slog.Int("length", len(response)), slog.String("model", model))
 
 	// Parse the response
 	reviewResponse, err := r.parseResponse(response)
 	if err != nil {
-		// Failed to parse, log and return findings without remediation
 		r.log.Error("parse_failed", slog.String("error", err.Error()))
-		return ReviewResult{Findings: findings, Stats: stats}, nil
+		return nil, 0, fmt.Errorf("%w: %v", ErrNoRemediation, err)
 	}
 
 	r.log.Info("remediation_parsed", slog.Int("count", len(reviewResponse.Findings)))
 
 	// Merge remediation into findings and get match count
 	mergedFindings, matchCount := r.mergeRemediation(findings, reviewResponse)
-	stats.MatchedFindings = matchCount
+	return mergedFindings, matchCount, nil
+}
 
-	return ReviewResult{Findings: mergedFindings, Stats: stats}, nil
+// modelFor returns the review model for files: the override for their most
+// common language, or the default model. Ties go to the language of the
+// earliest file, which selectFilesToReview puts most severe first.
+func (r *CodeReviewer) modelFor(files []string) string {
+	if len(r.languageModels) == 0 {
+		return r.model
+	}
+
+	counts := make(map[Language]int)
+	var dominant Language
+	for _, file := range files {
+		lang := r.detector.GetLanguageForExtension(filepath.Ext(file))
+		if lang == LangUnknown {
+			continue
+		}
+		counts[lang]++
+		if counts[lang] > counts[dominant] {
+			dominant = lang
+		}
+	}
+
+	if model, ok := r.languageModels[dominant]; ok {
+		return model
+	}
+	return r.model
 }
 
 // selectFilesToReview selects files to review, prioritizing by severity.
-// Returns at most maxFiles files. When files have the same severity,
-// they are sorted alphabetically by path for deterministic ordering.
+// Returns at most maxFiles files. When files have the same severity, those
+// with higher-confidence findings come first, then they are sorted
+// alphabetically by path for deterministic ordering.
 func (r *CodeReviewer) selectFilesToReview(findings []Finding) []string {
 	// Group findings by file
 	fileFindings := make(map[string][]Finding)
@@ -238,27 +400,42 @@ func (r *CodeReviewer) selectFilesToReview(findings []Finding) []string {
 
 	// Score each file by highest severity finding
 	type fileScore struct {
-		path  string
-		score int
+		path       string
+		score      int
+		confidence int
 	}
 
 	var scores []fileScore
 	for path, ff := range fileFindings {
-		// Find highest severity (lowest score = most severe)
+		// Find highest severity (lowest score = most severe), and the
+		// highest confidence among findings at that severity
 		minScore := 999
+		minConfidence := unknownConfidenceOrder
 		for _, f := range ff {
-			if s, ok := severityOrder[f.Severity]; ok && s < minScore {
+			s, ok := severityOrder[f.Severity]
+			if !ok {
+				continue
+			}
+			c := confidenceRank(f.Confidence)
+			if s < minScore {
 				minScore = s
+				minConfidence = c
+			} else if s == minScore && c < minConfidence {
+				minConfidence = c
 			}
 		}
-		scores = append(scores, fileScore{path: path, score: minScore})
+		scores = append(scores, fileScore{path: path, score: minScore, confidence: minConfidence})
 	}
 
-	// Sort by score (most severe first), then by path (alphabetically) for determinism
+	// Sort by score (most severe first), then confidence, then by path
+	// (alphabetically) for determinism
 	sort.Slice(scores, func(i, j int) bool {
 		if scores[i].score != scores[j].score {
 			return scores[i].score < scores[j].score
 		}
+		if scores[i].confidence != scores[j].confidence {
+			return scores[i].confidence < scores[j].confidence
+		}
 		// Secondary sort by path for deterministic ordering
 		return scores[i].path < scores[j].path
 	})
@@ -282,7 +459,7 @@ func (r *CodeReviewer) readFileContent(path string) (string, error) {
 		return "", err
 	}
 
-	if info.Size() > DefaultMaxFileSize {
+	if info.Size() > r.maxFileSize {
 		// File too large, read only the beginning
 		file, err := os.Open(path)
 		if err != nil {
@@ -290,9 +467,10 @@ func (r *CodeReviewer) readFileContent(path string) (string, error) {
 		}
 		defer func() { _ = file.Close() }()
 
-		buf := make([]byte, DefaultMaxFileSize)
-		n, err := file.Read(buf)
-		if err != nil {
+		// A single Read may return less than the limit; fill the buffer
+		buf := make([]byte, r.maxFileSize)
+		n, err := io.ReadFull(file, buf)
+		if err != nil && err != io.ErrUnexpectedEOF {
 			return "", err
 		}
 		return string(buf[:n]) + "\n... (truncated)", nil
@@ -464,8 +642,8 @@ func (r *CodeReviewer) mergeRemediation(findings []Finding, review *ReviewRespon
 			continue
 		}
 
-		// Only log no_match for reviewable severities (high/medium/critical)
-		if ReviewableSeverities[f.Severity] {
+		// Only log no_match for severities that were sent for review
+		if r.isReviewable(f.Severity) {
 			r.log.Warn("no_match",
 				slog.String("file_path", f.FilePath),
 				slog.String("extracted_file", findingFile),
@@ -479,6 +657,12 @@ func (r *CodeReviewer) mergeRemediation(findings []Finding, review *ReviewRespon
 	return result, matchCount
 }
 
+// isReviewable reports whether a severity is at or above the review threshold.
+func (r *CodeReviewer) isReviewable(severity string) bool {
+	order, ok := severityOrder[severity]
+	return ok && order <= severityOrder[r.minSeverity]
+}
+
 // GetMaxFiles returns the maximum number of files to review.
 func (r *CodeReviewer) GetMaxFiles() int {
 	return r.maxFiles
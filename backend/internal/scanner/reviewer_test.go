@@ -1,8 +1,18 @@
 package scanner
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"testing/quick"
+	"time"
+
+	"better-kiro-prompts/internal/config"
+	"better-kiro-prompts/internal/openai"
 )
 
 // =============================================================================
@@ -15,6 +25,12 @@ func TestNewCodeReviewer(t *testing.T) {
 		if r.maxFiles != DefaultMaxFilesToReview {
 			t.Errorf("maxFiles = %d, want %d", r.maxFiles, DefaultMaxFilesToReview)
 		}
+		if r.maxFindings != DefaultMaxFindingsToReview {
+			t.Errorf("maxFindings = %d, want %d", r.maxFindings, DefaultMaxFindingsToReview)
+		}
+		if r.maxFileSize != DefaultMaxFileSize {
+			t.Errorf("maxFileSize = %d, want %d", r.maxFileSize, DefaultMaxFileSize)
+		}
 	})
 
 	t.Run("with custom max files", func(t *testing.T) {
@@ -25,6 +41,201 @@ func TestNewCodeReviewer(t *testing.T) {
 	})
 }
 
+func TestCodeReviewer_readFileContent(t *testing.T) {
+	content := strings.Repeat("x", 2048)
+	path := filepath.Join(t.TempDir(), "main.go")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	t.Run("smaller limit truncates", func(t *testing.T) {
+		r := NewCodeReviewer(nil, WithMaxFileSize(1024))
+		got, err := r.readFileContent(path)
+		if err != nil {
+			t.Fatalf("readFileContent: %v", err)
+		}
+		if got != content[:1024]+"\n... (truncated)" {
+			t.Errorf("expected first 1024 bytes plus truncation marker, got %d bytes", len(got))
+		}
+	})
+
+	t.Run("larger limit reads whole file", func(t *testing.T) {
+		r := NewCodeReviewer(nil, WithMaxFileSize(4096))
+		got, err := r.readFileContent(path)
+		if err != nil {
+			t.Fatalf("readFileContent: %v", err)
+		}
+		if got != content {
+			t.Errorf("expected full %d byte file, got %d bytes", len(content), len(got))
+		}
+	})
+
+	t.Run("oversized limit is capped", func(t *testing.T) {
+		r := NewCodeReviewer(nil, WithMaxFileSize(1<<40))
+		if r.maxFileSize != MaxReviewFileSize {
+			t.Errorf("maxFileSize = %d, want %d", r.maxFileSize, MaxReviewFileSize)
+		}
+	})
+}
+
+func TestCodeReviewer_ReviewMinSeverity(t *testing.T) {
+	// Findings point at files that do not exist, so Review stops after
+	// filtering without calling the model
+	client := openai.NewFakeClient()
+	findings := []Finding{
+		{ID: "1", Severity: SeverityCritical, FilePath: "a.go"},
+		{ID: "2", Severity: SeverityHigh, FilePath: "b.go"},
+		{ID: "3", Severity: SeverityMedium, FilePath: "c.go"},
+		{ID: "4", Severity: SeverityLow, FilePath: "d.go"},
+		{ID: "5", Severity: SeverityInfo, FilePath: "e.go"},
+	}
+
+	tests := []struct {
+		name        string
+		minSeverity string
+		want        int
+	}{
+		{"default", "", 3},
+		{"low includes low findings", SeverityLow, 4},
+		{"critical excludes medium", SeverityCritical, 1},
+		{"unknown keeps default", "bogus", 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewCodeReviewer(nil, WithReviewMinSeverity(tt.minSeverity))
+			r.client = client
+			result, err := r.Review(context.Background(), t.TempDir(), findings)
+			if err != nil {
+				t.Fatalf("Review: %v", err)
+			}
+			if result.Stats.ReviewableFindings != tt.want {
+				t.Errorf("ReviewableFindings = %d, want %d", result.Stats.ReviewableFindings, tt.want)
+			}
+			if len(result.Findings) != len(findings) {
+				t.Errorf("Review dropped findings: got %d, want %d", len(result.Findings), len(findings))
+			}
+		})
+	}
+	if client.CallCount() != 0 {
+		t.Errorf("expected no model calls, got %d", client.CallCount())
+	}
+}
+
+func TestCodeReviewer_ReviewMergesRemediation(t *testing.T) {
+	repo := t.TempDir()
+	if err := os.WriteFile(filepath.Join(repo, "main.go"), []byte("package main\n\nvar password = \"hunter2\"\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	client := openai.NewFakeClient("```json\n" + `{"findings": [{"file_path": "main.go", "line_number": 3, "remediation": "Load the password from the environment", "code_example": "os.Getenv(\"PASSWORD\")"}]}` + "\n```")
+	r := NewCodeReviewer(nil, WithModel("review-model"))
+	r.client = client
+
+	line := 3
+	findings := []Finding{
+		{ID: "1", Severity: SeverityHigh, FilePath: "main.go", LineNumber: &line, Description: "Hardcoded password"},
+		{ID: "2", Severity: SeverityLow, FilePath: "util.go", Description: "Style issue"},
+	}
+	result, err := r.Review(context.Background(), repo, findings)
+	if err != nil {
+		t.Fatalf("Review: %v", err)
+	}
+
+	calls := client.Calls()
+	if len(calls) != 1 {
+		t.Fatalf("expected 1 model call, got %d", len(calls))
+	}
+	if calls[0].Model != "review-model" {
+		t.Errorf("model = %q, want review-model", calls[0].Model)
+	}
+	if prompt := calls[0].Messages[1].Content; !strings.Contains(prompt, "hunter2") || strings.Contains(prompt, "Style issue") {
+		t.Error("expected the prompt to carry the file and only reviewable findings")
+	}
+
+	if result.Stats.MatchedFindings != 1 {
+		t.Errorf("MatchedFindings = %d, want 1", result.Stats.MatchedFindings)
+	}
+	if result.Findings[0].Remediation != "Load the password from the environment" {
+		t.Errorf("Remediation = %q", result.Findings[0].Remediation)
+	}
+	if result.Findings[1].Remediation != "" {
+		t.Errorf("low finding should not get remediation, got %q", result.Findings[1].Remediation)
+	}
+}
+
+func TestCodeReviewer_LanguageModelOverride(t *testing.T) {
+	repo := t.TempDir()
+	for name, content := range map[string]string{
+		"main.go":       "package main\n",
+		"server/db.go":  "package server\n",
+		"scripts/up.py": "import os\n",
+	} {
+		path := filepath.Join(repo, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		files    []string
+		override map[Language]string
+		want     string
+	}{
+		{"go files use the go model", []string{"main.go", "server/db.go", "scripts/up.py"}, map[Language]string{LangGo: "go-model"}, "go-model"},
+		{"other languages use the default", []string{"scripts/up.py"}, map[Language]string{LangGo: "go-model"}, "default-model"},
+		{"no overrides use the default", []string{"main.go"}, nil, "default-model"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := openai.NewFakeClient(`{"findings": []}`)
+			r := NewCodeReviewer(nil, WithModel("default-model"), WithLanguageModels(tt.override))
+			r.client = client
+
+			var findings []Finding
+			for i, file := range tt.files {
+				findings = append(findings, Finding{ID: fmt.Sprint(i), Severity: SeverityHigh, FilePath: file})
+			}
+			if _, err := r.Review(context.Background(), repo, findings); err != nil {
+				t.Fatalf("Review: %v", err)
+			}
+
+			calls := client.Calls()
+			if len(calls) != 1 {
+				t.Fatalf("expected 1 model call, got %d", len(calls))
+			}
+			if calls[0].Model != tt.want {
+				t.Errorf("model = %q, want %q", calls[0].Model, tt.want)
+			}
+		})
+	}
+}
+
+func TestCodeReviewer_ReviewClientErrorKeepsFindings(t *testing.T) {
+	repo := t.TempDir()
+	if err := os.WriteFile(filepath.Join(repo, "main.go"), []byte("package main\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	client := openai.NewFakeClient()
+	client.SetError(errors.New("upstream unavailable"))
+	r := NewCodeReviewer(nil)
+	r.client = client
+
+	findings := []Finding{{ID: "1", Severity: SeverityCritical, FilePath: "main.go"}}
+	result, err := r.Review(context.Background(), repo, findings)
+	if err != nil {
+		t.Fatalf("Review should not fail on model errors: %v", err)
+	}
+	if len(result.Findings) != 1 || result.Findings[0].Remediation != "" {
+		t.Errorf("expected findings returned unchanged, got %+v", result.Findings)
+	}
+}
+
 func TestCodeReviewer_HasClient(t *testing.T) {
 	t.Run("no client", func(t *testing.T) {
 		r := NewCodeReviewer(nil)
@@ -392,3 +603,72 @@ func TestProperty9_AIReviewScopeLimitation_EdgeCases(t *testing.T) {
 		}
 	})
 }
+
+func TestCodeReviewer_ReviewTimeout(t *testing.T) {
+	repo := t.TempDir()
+	if err := os.WriteFile(filepath.Join(repo, "main.go"), []byte("package main\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	client := openai.NewFakeClient(`{"findings": []}`)
+	r := NewCodeReviewer(nil, WithReviewTimeout(3*time.Minute))
+	r.client = client
+
+	start := time.Now()
+	findings := []Finding{{ID: "1", Severity: SeverityCritical, FilePath: "main.go"}}
+	if _, err := r.Review(context.Background(), repo, findings); err != nil {
+		t.Fatalf("Review: %v", err)
+	}
+
+	calls := client.Calls()
+	if len(calls) != 1 {
+		t.Fatalf("expected 1 model call, got %d", len(calls))
+	}
+	if got := calls[0].Deadline.Sub(start); got < 3*time.Minute || got > 3*time.Minute+time.Second {
+		t.Errorf("review deadline in %v, want 3m", got)
+	}
+}
+
+func TestCodeReviewer_SystemPrompt(t *testing.T) {
+	repo := t.TempDir()
+	if err := os.WriteFile(filepath.Join(repo, "main.go"), []byte("package main\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	custom := `Cite the CWE for every issue. Respond with JSON: {"findings": [{"file_path": "", "line_number": 0, "remediation": "", "code_example": ""}]}`
+
+	tests := []struct {
+		name string
+		opts []CodeReviewerOption
+		want string
+	}{
+		{"default", nil, codeReviewSystemPrompt},
+		{"empty keeps default", []CodeReviewerOption{WithReviewSystemPrompt("")}, codeReviewSystemPrompt},
+		{"custom", []CodeReviewerOption{WithReviewSystemPrompt(custom)}, custom},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := openai.NewFakeClient(`{"findings": []}`)
+			r := NewCodeReviewer(nil, tt.opts...)
+			r.client = client
+
+			findings := []Finding{{ID: "1", Severity: SeverityCritical, FilePath: "main.go"}}
+			if _, err := r.Review(context.Background(), repo, findings); err != nil {
+				t.Fatalf("Review: %v", err)
+			}
+
+			calls := client.Calls()
+			if len(calls) != 1 {
+				t.Fatalf("expected 1 model call, got %d", len(calls))
+			}
+			if got := calls[0].Messages[0]; got.Role != "system" || got.Content != tt.want {
+				t.Errorf("system message = %q, want %q", got.Content, tt.want)
+			}
+		})
+	}
+}
+
+func TestCodeReviewSystemPrompt_PassesConfigValidation(t *testing.T) {
+	if err := config.ValidateReviewPrompt(codeReviewSystemPrompt); err != nil {
+		t.Errorf("built-in review prompt fails validation: %v", err)
+	}
+}
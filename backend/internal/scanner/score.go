@@ -0,0 +1,80 @@
+package scanner
+
+import "math"
+
+// Security score
+//
+// A completed scan is scored from 0 to 100 from its findings:
+//
+//	penalty = sum of SeverityPenalties for every finding
+//	score   = round(100 * e^(-penalty / ScoreDecay))
+//
+// A scan without findings scores 100. Each finding lowers the score and
+// more severe findings lower it more; rather than bottoming out at a fixed
+// count, the score keeps falling towards 0 as findings accumulate. With the
+// defaults one critical gives 61, one high 82, one medium 92 and one low 98.
+// Grades follow the usual bands in ScoreGrades.
+
+// SeverityPenalties is the penalty per finding of each severity.
+var SeverityPenalties = map[string]float64{
+	SeverityCritical: 25,
+	SeverityHigh:     10,
+	SeverityMedium:   4,
+	SeverityLow:      1,
+	SeverityInfo:     0,
+}
+
+// ScoreDecay is the total penalty that brings the score down to about 37.
+const ScoreDecay = 50.0
+
+// ScoreGrades maps the lowest score for each letter grade, best first.
+var ScoreGrades = []struct {
+	MinScore int
+	Grade    string
+}{
+	{90, "A"},
+	{80, "B"},
+	{70, "C"},
+	{60, "D"},
+	{0, "F"},
+}
+
+// ScanScore is a scan's security score and letter grade.
+type ScanScore struct {
+	Score int    `json:"score"`
+	Grade string `json:"grade"`
+}
+
+// Score computes the security score of a completed scan. It returns nil for
+// scans that have not completed and for no-code scans, where nothing was
+// checked. Truncated scans are scored on the findings kept, which are the
+// most severe. Acknowledged findings are not in job.Findings and so are not
+// scored.
+func (s *Service) Score(job *ScanJob) *ScanScore {
+	if job.Status != StatusCompleted || job.NoCode {
+		return nil
+	}
+	score := ScoreFindings(job.Findings)
+	return &score
+}
+
+// ScoreFindings computes the security score for a set of findings.
+func ScoreFindings(findings []Finding) ScanScore {
+	var penalty float64
+	for _, f := range findings {
+		penalty += SeverityPenalties[f.Severity]
+	}
+
+	score := int(math.Round(100 * math.Exp(-penalty/ScoreDecay)))
+	return ScanScore{Score: score, Grade: gradeFor(score)}
+}
+
+// gradeFor maps a score to its letter grade.
+func gradeFor(score int) string {
+	for _, g := range ScoreGrades {
+		if score >= g.MinScore {
+			return g.Grade
+		}
+	}
+	return ScoreGrades[len(ScoreGrades)-1].Grade
+}
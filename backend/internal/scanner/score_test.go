@@ -0,0 +1,72 @@
+package scanner
+
+import "testing"
+
+func findingsOf(severity string, n int) []Finding {
+	findings := make([]Finding, n)
+	for i := range findings {
+		findings[i] = Finding{Severity: severity}
+	}
+	return findings
+}
+
+func TestScoreFindings_CleanScanScoresA(t *testing.T) {
+	got := ScoreFindings(nil)
+	if got.Score != 100 || got.Grade != "A" {
+		t.Errorf("ScoreFindings(nil) = %+v, want 100/A", got)
+	}
+}
+
+func TestScoreFindings_CriticalsScoreLowerThanLows(t *testing.T) {
+	for n := 1; n <= 5; n++ {
+		criticals := ScoreFindings(findingsOf(SeverityCritical, n))
+		lows := ScoreFindings(findingsOf(SeverityLow, n))
+		if criticals.Score >= lows.Score {
+			t.Errorf("%d criticals scored %d, not below %d lows at %d", n, criticals.Score, n, lows.Score)
+		}
+	}
+}
+
+func TestScoreFindings_MoreCriticalsScoreLower(t *testing.T) {
+	previous := ScoreFindings(nil).Score
+	for n := 1; n <= 5; n++ {
+		got := ScoreFindings(findingsOf(SeverityCritical, n)).Score
+		if got >= previous {
+			t.Errorf("%d criticals scored %d, not below %d", n, got, previous)
+		}
+		previous = got
+	}
+}
+
+func TestScoreFindings_Grades(t *testing.T) {
+	tests := []struct {
+		findings []Finding
+		want     ScanScore
+	}{
+		{findingsOf(SeverityInfo, 10), ScanScore{Score: 100, Grade: "A"}},
+		{findingsOf(SeverityLow, 1), ScanScore{Score: 98, Grade: "A"}},
+		{findingsOf(SeverityHigh, 1), ScanScore{Score: 82, Grade: "B"}},
+		{findingsOf(SeverityCritical, 1), ScanScore{Score: 61, Grade: "D"}},
+		{findingsOf(SeverityCritical, 2), ScanScore{Score: 37, Grade: "F"}},
+	}
+
+	for _, tt := range tests {
+		if got := ScoreFindings(tt.findings); got != tt.want {
+			t.Errorf("ScoreFindings(%d x %s) = %+v, want %+v", len(tt.findings), tt.findings[0].Severity, got, tt.want)
+		}
+	}
+}
+
+func TestService_ScoreOnlyCompletedScans(t *testing.T) {
+	s := NewService(nil, nil, "")
+
+	if got := s.Score(&ScanJob{Status: StatusScanning}); got != nil {
+		t.Errorf("running scan score = %+v, want none", got)
+	}
+	if got := s.Score(&ScanJob{Status: StatusCompleted, NoCode: true}); got != nil {
+		t.Errorf("no-code scan score = %+v, want none", got)
+	}
+	if got := s.Score(&ScanJob{Status: StatusCompleted}); got == nil || got.Score != 100 {
+		t.Errorf("clean scan score = %+v, want 100", got)
+	}
+}
@@ -0,0 +1,96 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// selfTestTimeout bounds each tool's version probe.
+const selfTestTimeout = 30 * time.Second
+
+// ToolAvailability reports whether a scanning tool passed the self-test.
+type ToolAvailability struct {
+	Name      string `json:"name"`
+	Available bool   `json:"available"`
+	Disabled  bool   `json:"disabled,omitempty"` // Turned off by configuration; never probed or run
+	Error     string `json:"error,omitempty"`
+}
+
+// SelfTest runs every registered tool's version probe through the executor
+// and returns the error for each tool, nil when it is available. Tools that
+// fail are skipped by GetToolsForLanguages until the next self-test, so
+// scans still run with whatever remains. Disabled tools are not probed and
+// report ErrToolDisabled.
+func (r *ToolRunner) SelfTest(ctx context.Context) map[string]error {
+	results := make(map[string]error, len(toolRegistry))
+	unavailable := make(map[string]error)
+
+	for _, tool := range toolRegistry {
+		if !r.isEnabled(tool.name) {
+			results[tool.name] = ErrToolDisabled
+			continue
+		}
+		err := r.probeTool(ctx, tool)
+		results[tool.name] = err
+		if err != nil {
+			unavailable[tool.name] = err
+		}
+	}
+
+	r.mu.Lock()
+	r.unavailable = unavailable
+	r.mu.Unlock()
+
+	return results
+}
+
+// probeTool runs a single tool's version command.
+func (r *ToolRunner) probeTool(ctx context.Context, tool toolDescriptor) error {
+	probe := tool.probe
+	if len(probe) == 0 {
+		probe = []string{tool.name, "--version"}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, selfTestTimeout)
+	defer cancel()
+
+	output, err := r.executor.Exec(ctx, "", probe[0], probe[1:]...)
+	if err != nil {
+		if msg := strings.TrimSpace(string(output)); msg != "" {
+			return fmt.Errorf("%s: %w: %s", strings.Join(probe, " "), err, msg)
+		}
+		return fmt.Errorf("%s: %w", strings.Join(probe, " "), err)
+	}
+	return nil
+}
+
+// isAvailable reports whether the tool passed the last self-test.
+// Tools are assumed available until a self-test says otherwise.
+func (r *ToolRunner) isAvailable(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, failed := r.unavailable[name]
+	return !failed
+}
+
+// toolAvailability orders self-test results by the registry.
+func toolAvailability(results map[string]error) []ToolAvailability {
+	tools := make([]ToolAvailability, 0, len(toolRegistry))
+	for _, tool := range toolRegistry {
+		err, ok := results[tool.name]
+		if !ok {
+			continue
+		}
+		status := ToolAvailability{Name: tool.name, Available: err == nil}
+		if errors.Is(err, ErrToolDisabled) {
+			status.Disabled = true
+		} else if err != nil {
+			status.Error = err.Error()
+		}
+		tools = append(tools, status)
+	}
+	return tools
+}
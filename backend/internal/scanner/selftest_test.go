@@ -0,0 +1,125 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestToolRunner_SelfTest_ReportsFailedTool(t *testing.T) {
+	exec := &fakeExecutor{
+		outputs: map[string][]byte{"bandit --version": []byte("bandit: not found")},
+		errs:    map[string]error{"bandit --version": errors.New("exit status 127")},
+	}
+	runner := NewToolRunner(WithExecutor(exec))
+
+	results := runner.SelfTest(context.Background())
+	if len(results) != len(toolRegistry) {
+		t.Fatalf("expected a result for each of %d tools, got %d", len(toolRegistry), len(results))
+	}
+	for name, err := range results {
+		if name == "bandit" {
+			if err == nil || !strings.Contains(err.Error(), "not found") {
+				t.Errorf("bandit error = %v, want probe failure with output", err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", name, err)
+		}
+	}
+
+	// Probes use each tool's own version command
+	for _, want := range []string{"gitleaks version", "cargo audit --version", "npm --version"} {
+		found := false
+		for _, call := range exec.calls {
+			if call == want {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected probe %q, calls: %v", want, exec.calls)
+		}
+	}
+
+	// Python scans skip the missing tool but keep the universal set
+	tools := runner.GetToolsForLanguages([]Language{LangPython})
+	for _, name := range tools {
+		if name == "bandit" {
+			t.Error("unavailable tool bandit should not be selected")
+		}
+	}
+	for _, want := range []string{"trivy", "semgrep", "trufflehog", "gitleaks", "pip-audit", "safety"} {
+		if !containsTool(tools, want) {
+			t.Errorf("expected %s to still be selected, got %v", want, tools)
+		}
+	}
+}
+
+func TestToolAvailability_RegistryOrder(t *testing.T) {
+	status := toolAvailability(map[string]error{
+		"brakeman": nil,
+		"trivy":    errors.New("missing"),
+	})
+	if len(status) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(status))
+	}
+	if status[0].Name != "trivy" || status[0].Available || status[0].Error != "missing" {
+		t.Errorf("first entry = %+v, want unavailable trivy", status[0])
+	}
+	if status[1].Name != "brakeman" || !status[1].Available {
+		t.Errorf("second entry = %+v, want available brakeman", status[1])
+	}
+}
+
+func containsTool(tools []string, name string) bool {
+	for _, t := range tools {
+		if t == name {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCheckTools_ServesStaleResultsWhileProbing(t *testing.T) {
+	s := NewService(nil, nil, "")
+	stale := []ToolAvailability{{Name: "semgrep", Available: true}}
+	s.toolStatus = stale
+	s.toolsCheckedAt = time.Now().Add(-2 * toolStatusTTL)
+	s.toolsProbe = make(chan struct{}) // another request is re-probing
+
+	done := make(chan []ToolAvailability, 1)
+	go func() { done <- s.CheckTools(context.Background()) }()
+
+	select {
+	case got := <-done:
+		if len(got) != 1 || got[0].Name != "semgrep" {
+			t.Errorf("CheckTools() = %+v, want the previous results", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("CheckTools() blocked behind a running self-test")
+	}
+}
+
+func TestCheckTools_WaitsForFirstProbe(t *testing.T) {
+	s := NewService(nil, nil, "")
+	probe := make(chan struct{})
+	s.toolsProbe = probe
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan []ToolAvailability, 1)
+	go func() { done <- s.CheckTools(ctx) }()
+
+	// With no results yet the caller waits, but gives up with its request
+	cancel()
+	select {
+	case got := <-done:
+		if got != nil {
+			t.Errorf("CheckTools() = %+v, want nil before the first probe finishes", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("CheckTools() ignored its cancelled context")
+	}
+}
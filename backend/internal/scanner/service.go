@@ -7,6 +7,9 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 
 	"better-kiro-prompts/internal/config"
@@ -28,38 +31,145 @@ const (
 
 // Service errors.
 var (
-	ErrJobNotFound = errors.New("scan job not found")
-	ErrScanFailed  = errors.New("scan failed")
+	ErrJobNotFound   = errors.New("scan job not found")
+	ErrScanFailed    = errors.New("scan failed")
+	ErrShuttingDown  = errors.New("scanner is shutting down")
+	ErrTooManyScans  = errors.New("too many concurrent scans")
+	ErrBatchNotFound = errors.New("scan batch not found")
 )
 
+// FindingSchemaVersion is the finding layout written by this build.
+// Schema changes are additive only: new finding columns are nullable and new
+// JSON fields are optional, so rows and clients from older versions keep working.
+//
+//	1: severity, tool, location, description, remediation and confidence
+//	2: adds rule_id
+//	3: adds end_line, start_column and end_column
+//	4: adds fingerprint
+//	5: adds snippet and snippet_start_line
+const FindingSchemaVersion = 5
+
+// NoCodeMessage explains a completed scan that found no source files. It
+// distinguishes an empty or documentation-only repository from a clean one.
+const NoCodeMessage = "No scannable code detected"
+
+// defaultMaxScanDuration bounds a whole scan when no config is provided.
+const defaultMaxScanDuration = 30 * time.Minute
+
 // ScanJob represents a security scan job.
 type ScanJob struct {
-	ID          string       `json:"id"`
-	Status      string       `json:"status"`
-	RepoURL     string       `json:"repo_url"`
-	Languages   []string     `json:"languages"`
-	Findings    []Finding    `json:"findings"`
-	ReviewStats *ReviewStats `json:"review_stats,omitempty"`
-	Error       string       `json:"error,omitempty"`
-	CreatedAt   time.Time    `json:"created_at"`
-	CompletedAt *time.Time   `json:"completed_at,omitempty"`
+	ID                 string       `json:"id"`
+	Status             string       `json:"status"`
+	RepoURL            string       `json:"repo_url"`
+	Subdir             string       `json:"subdir,omitempty"` // Repository subdirectory scanned; empty for the whole repository
+	Languages          []string     `json:"languages"`
+	Findings           []Finding    `json:"findings"`
+	ReviewStats        *ReviewStats `json:"review_stats,omitempty"`
+	Error              string       `json:"error,omitempty"`
+	CreatedAt          time.Time    `json:"created_at"`
+	CompletedAt        *time.Time   `json:"completed_at,omitempty"`
+	SuppressedFindings int          `json:"suppressed_findings"`
+	Partial            bool         `json:"partial"`
+	Truncated          bool         `json:"truncated"`
+	TruncatedFindings  int          `json:"truncated_findings"` // Least severe findings dropped by the per-job cap
+	SchemaVersion      int          `json:"schema_version"`     // FindingSchemaVersion the findings were stored with
+	NoCode             bool         `json:"no_code"`            // No source files were detected, so no tools ran
+	Score              *ScanScore   `json:"score,omitempty"`    // Security score of a completed scan; see ScoreFindings
+
+	// Findings covered by an Acknowledgement; excluded from Findings and the score
+	AcknowledgedFindings []Finding `json:"acknowledged_findings,omitempty"`
 }
 
 // ScanRequest represents a request to start a scan.
 type ScanRequest struct {
 	RepoURL string `json:"repo_url"`
+	Subdir  string `json:"subdir,omitempty"` // Optional subdirectory to scan, relative to the repository root
+
+	// IP hash of the requesting client for the concurrent scan limit; empty is not limited
+	ClientID string `json:"-"`
+}
+
+// scanDB is the subset of *sql.DB used by the service.
+type scanDB interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (scanTx, error)
+}
+
+// scanTx is the subset of *sql.Tx used by the service.
+type scanTx interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	Commit() error
+	Rollback() error
+}
+
+// sqlScanDB adapts *sql.DB to scanDB.
+type sqlScanDB struct {
+	*sql.DB
+}
+
+func (d sqlScanDB) BeginTx(ctx context.Context, opts *sql.TxOptions) (scanTx, error) {
+	tx, err := d.DB.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return tx, nil
 }
 
 // Service orchestrates security scanning operations.
 type Service struct {
-	db            *sql.DB
+	db            scanDB
 	cloner        *Cloner
 	detector      *LanguageDetector
 	toolRunner    *ToolRunner
 	aggregator    *Aggregator
 	reviewer      *CodeReviewer
+	allowlist     *SecretAllowlist
 	log           *slog.Logger
 	retentionDays int
+
+	// Budget for a whole scan; zero means unlimited
+	maxScanDuration time.Duration
+
+	// Languages below both thresholds get no language-specific tools;
+	// zero disables a threshold
+	minLanguagePercent float64
+	minLanguageFiles   int
+
+	// Findings kept per job, most severe first; zero keeps all
+	maxFindings int
+
+	// Store code snippets with findings, with this many lines of context
+	snippets       bool
+	snippetContext int
+
+	// Scans one client may have running at once; zero disables the limit
+	maxScansPerClient int
+
+	// Repositories allowed in one batch, and the running batch dispatchers
+	maxBatchSize int
+	batches      sync.WaitGroup
+
+	// Clones kept after their scan for on-demand features; zero retains none
+	retainClone time.Duration
+	retainedMu  sync.Mutex
+	retained    map[string]*retainedClone
+
+	// Cached tool self-test results
+	toolsMu        sync.Mutex
+	toolStatus     []ToolAvailability
+	toolsCheckedAt time.Time
+	toolsProbe     chan struct{} // non-nil while a self-test runs; closed when it finishes
+
+	// Background scan tracking for graceful shutdown
+	activeMu sync.Mutex
+	active   map[string]*activeScan
+	draining bool
+
+	// Finding explanations by tool and rule ID
+	explainMu    sync.Mutex
+	explanations map[string]string
 }
 
 // ServiceOption is a functional option for configuring a Service.
@@ -86,6 +196,15 @@ func WithServiceCodeReviewer(r *CodeReviewer) ServiceOption {
 	}
 }
 
+// WithServiceSecretAllowlist sets the allowlist applied to secret findings.
+func WithServiceSecretAllowlist(l *SecretAllowlist) ServiceOption {
+	return func(s *Service) {
+		if l != nil {
+			s.allowlist = l
+		}
+	}
+}
+
 // WithServiceLogger sets the logger for the service.
 func WithServiceLogger(log *slog.Logger) ServiceOption {
 	return func(s *Service) {
@@ -104,17 +223,85 @@ func WithRetentionDays(days int) ServiceOption {
 	}
 }
 
+// WithServiceReviewTimeout sets the deadline for the AI review model call.
+func WithServiceReviewTimeout(timeout time.Duration) ServiceOption {
+	return func(s *Service) {
+		WithReviewTimeout(timeout)(s.reviewer)
+	}
+}
+
+// WithMaxScanDuration caps the total time a scan may take. Once exceeded,
+// no further tools are started and the job completes as partial.
+func WithMaxScanDuration(d time.Duration) ServiceOption {
+	return func(s *Service) {
+		if d > 0 {
+			s.maxScanDuration = d
+		}
+	}
+}
+
+// WithLanguageThreshold only runs a language's tools when the language makes
+// up at least minPercent of the repository's files or has at least minFiles
+// files. Universal tools always run. Zero disables a threshold.
+func WithLanguageThreshold(minPercent float64, minFiles int) ServiceOption {
+	return func(s *Service) {
+		s.minLanguagePercent = max(minPercent, 0)
+		s.minLanguageFiles = max(minFiles, 0)
+	}
+}
+
+// WithMaxStoredFindings caps the findings stored and returned per job. The
+// most severe findings are kept and the job is marked truncated. Zero or
+// less keeps every finding.
+func WithMaxStoredFindings(n int) ServiceOption {
+	return func(s *Service) {
+		s.maxFindings = max(n, 0)
+	}
+}
+
+// WithFindingSnippets stores the code around each finding with it, with
+// contextLines lines on each side, capped at MaxSnippetContextLines.
+func WithFindingSnippets(contextLines int) ServiceOption {
+	return func(s *Service) {
+		s.snippets = true
+		s.snippetContext = min(max(contextLines, 0), MaxSnippetContextLines)
+	}
+}
+
+// WithMaxScansPerClient caps the scans one client may have running at once.
+// Zero or less disables the limit.
+func WithMaxScansPerClient(n int) ServiceOption {
+	return func(s *Service) {
+		s.maxScansPerClient = max(n, 0)
+	}
+}
+
+// WithMaxBatchSize caps the repositories in one scan batch. Zero or less
+// keeps the default.
+func WithMaxBatchSize(n int) ServiceOption {
+	return func(s *Service) {
+		if n > 0 {
+			s.maxBatchSize = n
+		}
+	}
+}
+
 // NewService creates a new scanner service.
 func NewService(db *sql.DB, openaiClient *openai.Client, githubToken string, opts ...ServiceOption) *Service {
 	s := &Service{
-		db:            db,
+		db:            sqlScanDB{db},
 		cloner:        NewCloner(WithGitHubToken(githubToken)),
 		detector:      NewLanguageDetector(),
 		toolRunner:    NewToolRunner(),
 		aggregator:    NewAggregator(),
 		reviewer:      NewCodeReviewer(openaiClient),
+		allowlist:     defaultSecretAllowlist(),
 		log:           slog.Default(),
 		retentionDays: 7, // Default retention days
+		active:        make(map[string]*activeScan),
+
+		maxScanDuration: defaultMaxScanDuration,
+		maxBatchSize:    DefaultMaxBatchSize,
 	}
 
 	for _, opt := range opts {
@@ -134,28 +321,68 @@ func NewServiceWithConfig(db *sql.DB, openaiClient *openai.Client, githubToken s
 	)
 
 	// Create tool runner with config values
+	toolTimeouts := make(map[string]time.Duration, len(cfg.ToolTimeouts))
+	for tool, timeout := range cfg.ToolTimeouts {
+		toolTimeouts[tool] = timeout.Duration()
+	}
 	toolRunner := NewToolRunner(
-		WithToolTimeout(time.Duration(cfg.ToolTimeoutSeconds) * time.Second),
+		WithToolTimeout(time.Duration(cfg.ToolTimeoutSeconds)*time.Second),
+		WithToolTimeouts(toolTimeouts),
+		WithVerifiedSecretsOnly(cfg.VerifiedSecretsOnly),
+		WithMaxConcurrentTools(cfg.MaxConcurrentTools),
+		WithToolSelection(cfg.EnabledTools, cfg.DisabledTools),
 	)
 
 	// Create code reviewer with config values
 	reviewerOpts := []CodeReviewerOption{
 		WithMaxFiles(cfg.MaxReviewFiles),
+		WithMaxFindings(cfg.MaxReviewFindings),
+		WithMaxFileSize(int64(cfg.MaxReviewFileSizeKB) * 1024),
+		WithReviewMinSeverity(cfg.ReviewMinSeverity),
+		WithReviewSystemPrompt(cfg.ReviewPrompt),
 	}
 	if codeReviewModel != "" {
 		reviewerOpts = append(reviewerOpts, WithModel(codeReviewModel))
 	}
+	if len(cfg.ReviewModels) > 0 {
+		languageModels := make(map[Language]string, len(cfg.ReviewModels))
+		for lang, model := range cfg.ReviewModels {
+			languageModels[Language(lang)] = model
+		}
+		reviewerOpts = append(reviewerOpts, WithLanguageModels(languageModels))
+	}
 	reviewer := NewCodeReviewer(openaiClient, reviewerOpts...)
 
+	// Config validation rejects bad patterns, so an error here only means
+	// the config was built without validation; fall back to the defaults
+	allowlist, err := NewSecretAllowlist(cfg.SecretAllowlist)
+	if err != nil {
+		allowlist = defaultSecretAllowlist()
+	}
+
 	s := &Service{
-		db:            db,
+		db:            sqlScanDB{db},
 		cloner:        cloner,
 		detector:      NewLanguageDetector(),
 		toolRunner:    toolRunner,
 		aggregator:    NewAggregator(),
 		reviewer:      reviewer,
+		allowlist:     allowlist,
 		log:           slog.Default(),
 		retentionDays: cfg.RetentionDays,
+		active:        make(map[string]*activeScan),
+
+		maxScanDuration:    cfg.MaxScanDuration.Duration(),
+		retainClone:        time.Duration(cfg.RetainCloneMinutes) * time.Minute,
+		minLanguagePercent: cfg.MinLanguagePercent,
+		minLanguageFiles:   cfg.MinLanguageFiles,
+		maxFindings:        max(cfg.MaxFindings, 0),
+		maxScansPerClient:  max(cfg.MaxConcurrentScansPerIP, 0),
+		maxBatchSize:       DefaultMaxBatchSize,
+	}
+	WithMaxBatchSize(cfg.MaxBatchSize)(s)
+	if cfg.IncludeSnippets {
+		WithFindingSnippets(cfg.SnippetContextLines)(s)
 	}
 
 	for _, opt := range opts {
@@ -172,6 +399,79 @@ func (s *Service) SetLogger(log *slog.Logger) {
 	}
 }
 
+// toolStatusTTL is how long self-test results are reused before re-probing.
+const toolStatusTTL = time.Minute
+
+// CheckTools runs the tool self-test and logs any tools that are missing.
+// Results are cached for toolStatusTTL so repeated requests do not re-probe.
+// The self-test runs without holding the lock and at most once at a time:
+// while it re-probes, callers get the previous results, and only callers with
+// no results yet wait for it.
+func (s *Service) CheckTools(ctx context.Context) []ToolAvailability {
+	s.toolsMu.Lock()
+	if s.toolStatus != nil && time.Since(s.toolsCheckedAt) < toolStatusTTL {
+		defer s.toolsMu.Unlock()
+		return s.toolStatus
+	}
+	if probe := s.toolsProbe; probe != nil {
+		stale := s.toolStatus
+		s.toolsMu.Unlock()
+		if stale != nil {
+			return stale
+		}
+		select {
+		case <-probe:
+		case <-ctx.Done():
+		}
+		s.toolsMu.Lock()
+		defer s.toolsMu.Unlock()
+		return s.toolStatus
+	}
+	probe := make(chan struct{})
+	s.toolsProbe = probe
+	s.toolsMu.Unlock()
+
+	status := s.selfTest(context.WithoutCancel(ctx))
+
+	s.toolsMu.Lock()
+	s.toolStatus = status
+	s.toolsCheckedAt = time.Now()
+	s.toolsProbe = nil
+	s.toolsMu.Unlock()
+	close(probe)
+	return status
+}
+
+// selfTest probes every tool and logs the ones that are missing.
+func (s *Service) selfTest(ctx context.Context) []ToolAvailability {
+	start := time.Now()
+	status := toolAvailability(s.toolRunner.SelfTest(ctx))
+
+	available := 0
+	for _, t := range status {
+		if t.Available {
+			available++
+			continue
+		}
+		if t.Disabled {
+			continue
+		}
+		s.log.Warn("scanner_tool_unavailable",
+			slog.String("request_id", logger.GetRequestID(ctx)),
+			slog.String("tool", t.Name),
+			slog.String("error", t.Error),
+		)
+	}
+	s.log.Info("scanner_self_test_complete",
+		slog.String("request_id", logger.GetRequestID(ctx)),
+		slog.Int("available", available),
+		slog.Int("total", len(status)),
+		slog.Duration("duration", time.Since(start)),
+	)
+
+	return status
+}
+
 // StartScan initiates a new security scan.
 func (s *Service) StartScan(ctx context.Context, req ScanRequest) (*ScanJob, error) {
 	requestID := logger.GetRequestID(ctx)
@@ -181,25 +481,68 @@ func (s *Service) StartScan(ctx context.Context, req ScanRequest) (*ScanJob, err
 		slog.String("repo_url", req.RepoURL),
 	)
 
-	// Validate URL
-	if err := ValidateGitHubURL(req.RepoURL); err != nil {
+	// Validate the canonical form so equivalent URL spellings are accepted
+	repoURL := NormalizeGitHubURL(req.RepoURL)
+	if err := ValidateGitHubURL(repoURL); err != nil {
 		s.log.Warn("scan_validation_failed",
 			slog.String("request_id", requestID),
 			slog.String("error", err.Error()),
 		)
 		return nil, err
 	}
+	subdir, verr := NormalizeSubdir(req.Subdir)
+	if verr != nil {
+		s.log.Warn("scan_validation_failed",
+			slog.String("request_id", requestID),
+			slog.String("error", verr.Error()),
+		)
+		return nil, verr
+	}
 
 	// Create job
 	job := &ScanJob{
 		ID:        uuid.New().String(),
 		Status:    StatusPending,
-		RepoURL:   NormalizeGitHubURL(req.RepoURL),
+		RepoURL:   repoURL,
+		Subdir:    subdir,
 		CreatedAt: time.Now(),
+
+		SchemaVersion: FindingSchemaVersion,
+	}
+
+	// Reuse a recent scan of the same repository that is still running
+	scanCtx, existing, err := s.claimScan(job.ID, repoURL, subdir, req.ClientID, job.CreatedAt)
+	if errors.Is(err, ErrShuttingDown) {
+		s.log.Warn("scan_rejected_shutting_down",
+			slog.String("request_id", requestID),
+		)
+		return nil, err
+	}
+	if err != nil {
+		s.log.Warn("scan_rejected_client_limit",
+			slog.String("request_id", requestID),
+			slog.String("repo_url", repoURL),
+			slog.Int("max_scans_per_client", s.maxScansPerClient),
+		)
+		return nil, err
+	}
+	if existing != nil {
+		s.log.Info("scan_deduplicated",
+			slog.String("request_id", requestID),
+			slog.String("job_id", existing.ID),
+			slog.String("repo_url", repoURL),
+			slog.String("subdir", subdir),
+		)
+		// The other request may not have stored its job yet
+		if current, err := s.loadJob(ctx, existing.ID); err == nil {
+			return current, nil
+		}
+		return existing, nil
 	}
 
 	// Persist job
 	if err := s.createJob(ctx, job); err != nil {
+		s.untrackScan(job.ID)
 		s.log.Error("scan_create_job_failed",
 			slog.String("request_id", requestID),
 			slog.String("error", err.Error()),
@@ -211,10 +554,11 @@ func (s *Service) StartScan(ctx context.Context, req ScanRequest) (*ScanJob, err
 		slog.String("request_id", requestID),
 		slog.String("job_id", job.ID),
 		slog.String("repo_url", job.RepoURL),
+		slog.String("subdir", job.Subdir),
 	)
 
-	// Start scan in background
-	go s.runScan(context.Background(), job.ID)
+	// Start scan in background, tracked so shutdown can drain it
+	go s.runScan(scanCtx, job.ID)
 
 	return job, nil
 }
@@ -245,11 +589,27 @@ func (s *Service) GetJob(ctx context.Context, jobID string) (*ScanJob, error) {
 		return nil, err
 	}
 
+	// Acknowledgements are applied on read, so new ones also cover earlier scans
+	if len(job.Findings) > 0 {
+		acks, err := s.loadAcknowledgements(ctx, job.RepoURL)
+		if err != nil {
+			s.log.Warn("scan_ack_load_failed",
+				slog.String("request_id", requestID),
+				slog.String("job_id", jobID),
+				slog.String("error", err.Error()),
+			)
+		}
+		applyAcknowledgements(job, acks)
+	}
+
+	job.Score = s.Score(job)
+
 	s.log.Debug("scan_get_job_complete",
 		slog.String("request_id", requestID),
 		slog.String("job_id", jobID),
 		slog.String("status", job.Status),
 		slog.Int("finding_count", len(job.Findings)),
+		slog.Int("acknowledged_count", len(job.AcknowledgedFindings)),
 	)
 
 	return job, nil
@@ -260,10 +620,29 @@ func (s *Service) HasPrivateRepoSupport() bool {
 	return s.cloner.HasToken()
 }
 
-// runScan executes the full scan pipeline.
+// runScan executes the full scan pipeline for a stored job.
 func (s *Service) runScan(ctx context.Context, jobID string) {
+	// Deferred first so it runs after repo cleanup
+	defer s.untrackScan(jobID)
+
+	// Load job
+	job, err := s.loadJob(ctx, jobID)
+	if err != nil {
+		s.log.Error("scan_load_job_failed",
+			slog.String("job_id", jobID),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+
+	s.runJob(ctx, job)
+}
+
+// runJob clones the repository of job and scans it. The caller untracks
+// the scan once it returns.
+func (s *Service) runJob(ctx context.Context, job *ScanJob) {
 	var repoPath string
-	var err error
+	jobID := job.ID
 	start := time.Now()
 
 	s.log.Info("scan_pipeline_start",
@@ -271,26 +650,12 @@ func (s *Service) runScan(ctx context.Context, jobID string) {
 	)
 
 	defer func() {
-		// Cleanup cloned repo
+		// Cleanup cloned repo, unless it is retained for on-demand features
 		if repoPath != "" {
-			s.log.Debug("scan_cleanup_start",
-				slog.String("job_id", jobID),
-				slog.String("path", repoPath),
-			)
-			_ = s.cloner.Cleanup(repoPath)
+			s.releaseClone(ctx, jobID, repoPath)
 		}
 	}()
 
-	// Load job
-	job, err := s.loadJob(ctx, jobID)
-	if err != nil {
-		s.log.Error("scan_load_job_failed",
-			slog.String("job_id", jobID),
-			slog.String("error", err.Error()),
-		)
-		return
-	}
-
 	// Phase 1: Clone repository
 	s.log.Info("scan_phase_clone_start",
 		slog.String("job_id", jobID),
@@ -298,6 +663,7 @@ func (s *Service) runScan(ctx context.Context, jobID string) {
 	)
 	cloneStart := time.Now()
 	_ = s.updateJobStatus(ctx, jobID, StatusCloning, "")
+	s.publishEvent(jobID, ScanEvent{Type: EventCloneStarted})
 	cloneResult, err := s.cloner.Clone(ctx, job.RepoURL)
 	if err != nil {
 		s.log.Error("scan_phase_clone_failed",
@@ -306,21 +672,44 @@ func (s *Service) runScan(ctx context.Context, jobID string) {
 			slog.Duration("duration", time.Since(cloneStart)),
 		)
 		_ = s.failJob(ctx, jobID, fmt.Sprintf("Clone failed: %v", err))
+		s.publishEvent(jobID, ScanEvent{Type: EventScanFailed, Message: "Clone failed"})
 		return
 	}
 	repoPath = cloneResult.Path
+	s.setScanRepoPath(jobID, repoPath)
 	s.log.Info("scan_phase_clone_complete",
 		slog.String("job_id", jobID),
 		slog.String("path", repoPath),
 		slog.Duration("duration", time.Since(cloneStart)),
 	)
+	s.publishEvent(jobID, ScanEvent{Type: EventCloneComplete})
+
+	// A monorepo scan covers only the requested subdirectory
+	scanPath, err := resolveScanPath(repoPath, job.Subdir)
+	if err != nil {
+		s.log.Warn("scan_subdir_invalid",
+			slog.String("job_id", jobID),
+			slog.String("subdir", job.Subdir),
+			slog.String("error", err.Error()),
+		)
+		_ = s.failJob(ctx, jobID, fmt.Sprintf("Subdirectory %q: %v", job.Subdir, err))
+		s.publishEvent(jobID, ScanEvent{Type: EventScanFailed, Message: "Subdirectory not found"})
+		return
+	}
 
+	s.detectAndScan(ctx, jobID, repoPath, job.Subdir, scanPath, start)
+}
+
+// detectAndScan detects the languages in scanPath and scans the repository
+// for them. A repository with no recognised source files completes at once
+// as a no-code scan instead of running tools that have nothing to check.
+func (s *Service) detectAndScan(ctx context.Context, jobID, repoPath, subdir, scanPath string, start time.Time) {
 	// Phase 2: Detect languages
 	s.log.Info("scan_phase_detect_start",
 		slog.String("job_id", jobID),
 	)
 	detectStart := time.Now()
-	languages, err := s.detector.DetectLanguages(repoPath)
+	detected, err := s.detector.Detect(scanPath)
 	if err != nil {
 		s.log.Error("scan_phase_detect_failed",
 			slog.String("job_id", jobID),
@@ -328,22 +717,79 @@ func (s *Service) runScan(ctx context.Context, jobID string) {
 			slog.Duration("duration", time.Since(detectStart)),
 		)
 		_ = s.failJob(ctx, jobID, fmt.Sprintf("Language detection failed: %v", err))
+		s.publishEvent(jobID, ScanEvent{Type: EventScanFailed, Message: "Language detection failed"})
 		return
 	}
 
 	// Convert to string slice for storage and logging
-	langStrings := make([]string, len(languages))
-	for i, l := range languages {
-		langStrings[i] = string(l)
+	langStrings := make([]string, len(detected))
+	for i, l := range detected {
+		langStrings[i] = string(l.Language)
 	}
 	_ = s.updateJobLanguages(ctx, jobID, langStrings)
 
 	s.log.Info("scan_phase_detect_complete",
 		slog.String("job_id", jobID),
 		slog.Any("languages", langStrings),
-		slog.Int("language_count", len(languages)),
+		slog.Int("language_count", len(detected)),
 		slog.Duration("duration", time.Since(detectStart)),
 	)
+	s.publishEvent(jobID, ScanEvent{Type: EventLanguagesDetected, Languages: langStrings})
+
+	if len(detected) == 0 {
+		if err := s.completeJobNoCode(ctx, jobID); err != nil {
+			s.log.Error("scan_complete_failed",
+				slog.String("job_id", jobID),
+				slog.String("error", err.Error()),
+			)
+			_ = s.failJob(ctx, jobID, fmt.Sprintf("Storing results failed: %v", err))
+			s.publishEvent(jobID, ScanEvent{Type: EventScanFailed, Message: "Storing results failed"})
+			return
+		}
+		s.log.Info("scan_no_code_detected",
+			slog.String("job_id", jobID),
+			slog.Duration("total_duration", time.Since(start)),
+		)
+		s.publishEvent(jobID, ScanEvent{Type: EventScanCompleted, Message: NoCodeMessage})
+		return
+	}
+
+	// Stray files of a language do not justify running its tools
+	languages := SignificantLanguages(detected, s.minLanguagePercent, s.minLanguageFiles)
+	if len(languages) < len(detected) {
+		s.log.Info("scan_languages_below_threshold",
+			slog.String("job_id", jobID),
+			slog.Int("skipped_count", len(detected)-len(languages)),
+			slog.Float64("min_language_percent", s.minLanguagePercent),
+			slog.Int("min_language_files", s.minLanguageFiles),
+		)
+	}
+
+	s.scanRepository(ctx, jobID, repoPath, subdir, languages, start)
+}
+
+// scanRepository runs the tool, aggregation and review phases on a cloned
+// repository and completes the job. With a subdir, tools and review run on
+// that directory only and findings are reported relative to it; repoPath
+// stays the root for repository-wide settings such as .gitleaksignore.
+// The phases share what is left of
+// maxScanDuration, measured from start: once it runs out no further tools
+// are launched, AI review is skipped, and the job completes as partial with
+// whatever findings were gathered.
+func (s *Service) scanRepository(ctx context.Context, jobID, repoPath, subdir string, languages []Language, start time.Time) {
+	scanPath := filepath.Join(repoPath, subdir)
+
+	budgetCtx := ctx
+	if s.maxScanDuration > 0 {
+		var cancel context.CancelFunc
+		budgetCtx, cancel = context.WithDeadline(ctx, start.Add(s.maxScanDuration))
+		defer cancel()
+	}
+	// Cancellation by DrainScans is not a budget overrun
+	budgetExceeded := func() bool {
+		return budgetCtx.Err() != nil && ctx.Err() == nil
+	}
+	partial := false
 
 	// Phase 3: Run security tools
 	toolNames := s.toolRunner.GetToolsForLanguages(languages)
@@ -356,23 +802,37 @@ func (s *Service) runScan(ctx context.Context, jobID string) {
 	_ = s.updateJobStatus(ctx, jobID, StatusScanning, "")
 
 	var results []ToolResult
-	for _, toolName := range toolNames {
+	for i, toolName := range toolNames {
+		if budgetExceeded() {
+			partial = true
+			s.log.Warn("scan_budget_exceeded",
+				slog.String("job_id", jobID),
+				slog.Any("skipped_tools", toolNames[i:]),
+				slog.Duration("max_scan_duration", s.maxScanDuration),
+			)
+			s.publishEvent(jobID, ScanEvent{Type: EventBudgetExceeded, Message: "Scan time limit reached; remaining tools skipped"})
+			break
+		}
+
 		toolStart := time.Now()
 		s.log.Debug("scan_tool_start",
 			slog.String("job_id", jobID),
 			slog.String("tool", toolName),
 		)
+		s.publishEvent(jobID, ScanEvent{Type: EventToolStarted, Tool: toolName})
 
-		result := s.toolRunner.RunToolByName(ctx, toolName, repoPath, languages)
+		result := s.toolRunner.RunToolByName(budgetCtx, toolName, scanPath, languages)
 
 		s.log.Info("scan_tool_complete",
 			slog.String("job_id", jobID),
 			slog.String("tool", toolName),
 			slog.Int("finding_count", len(result.Findings)),
 			slog.Bool("timed_out", result.TimedOut),
+			slog.Duration("timeout", result.Timeout),
 			slog.Bool("success", result.Error == nil),
 			slog.Duration("duration", time.Since(toolStart)),
 		)
+		s.publishEvent(jobID, ScanEvent{Type: EventToolComplete, Tool: toolName, FindingCount: len(result.Findings)})
 
 		if result.Error != nil {
 			s.log.Warn("scan_tool_error",
@@ -384,6 +844,10 @@ func (s *Service) runScan(ctx context.Context, jobID string) {
 
 		results = append(results, result)
 	}
+	// The last tool may have been cut short by the budget
+	if budgetExceeded() {
+		partial = true
+	}
 
 	s.log.Info("scan_phase_tools_complete",
 		slog.String("job_id", jobID),
@@ -397,7 +861,30 @@ func (s *Service) runScan(ctx context.Context, jobID string) {
 		slog.Int("result_count", len(results)),
 	)
 	aggStart := time.Now()
+	results, suppressed := s.aggregator.SuppressAllowlistedSecrets(results, s.allowlist.WithIgnoreFile(repoPath), repoPath)
+	if subdir != "" {
+		var outside int
+		results, outside = confineToScanPath(results, scanPath)
+		if outside > 0 {
+			s.log.Debug("scan_findings_outside_subdir",
+				slog.String("job_id", jobID),
+				slog.String("subdir", subdir),
+				slog.Int("dropped", outside),
+			)
+		}
+	}
+	if s.snippets {
+		captureSnippets(results, scanPath, s.snippetContext)
+	}
 	findings := s.aggregator.AggregateAndProcess(results)
+	findings, truncated := s.aggregator.LimitFindings(findings, s.maxFindings)
+	if truncated > 0 {
+		s.log.Warn("scan_findings_truncated",
+			slog.String("job_id", jobID),
+			slog.Int("max_findings", s.maxFindings),
+			slog.Int("dropped", truncated),
+		)
+	}
 
 	// Count by severity
 	severityCounts := map[string]int{"critical": 0, "high": 0, "medium": 0, "low": 0}
@@ -412,20 +899,24 @@ func (s *Service) runScan(ctx context.Context, jobID string) {
 		slog.Int("high", severityCounts["high"]),
 		slog.Int("medium", severityCounts["medium"]),
 		slog.Int("low", severityCounts["low"]),
+		slog.Int("suppressed", suppressed),
+		slog.Int("truncated", truncated),
 		slog.Duration("duration", time.Since(aggStart)),
 	)
+	s.publishEvent(jobID, ScanEvent{Type: EventAggregateComplete, FindingCount: len(findings)})
 
-	// Phase 5: AI review (if findings exist and client available)
+	// Phase 5: AI review (if findings exist, client available and budget remains)
 	var reviewStats *ReviewStats
-	if len(findings) > 0 && s.reviewer.HasClient() {
+	if !partial && len(findings) > 0 && s.reviewer.HasClient() {
 		s.log.Info("scan_phase_review_start",
 			slog.String("job_id", jobID),
 			slog.Int("findings_to_review", len(findings)),
 		)
 		reviewStart := time.Now()
 		_ = s.updateJobStatus(ctx, jobID, StatusReviewing, "")
+		s.publishEvent(jobID, ScanEvent{Type: EventReviewStarted, FindingCount: len(findings)})
 
-		reviewResult, reviewErr := s.reviewer.Review(ctx, repoPath, findings)
+		reviewResult, reviewErr := s.reviewer.Review(budgetCtx, scanPath, findings)
 		if reviewErr != nil {
 			s.log.Warn("scan_phase_review_partial",
 				slog.String("job_id", jobID),
@@ -434,6 +925,9 @@ func (s *Service) runScan(ctx context.Context, jobID string) {
 		}
 		findings = reviewResult.Findings
 		reviewStats = &reviewResult.Stats
+		if budgetExceeded() {
+			partial = true
+		}
 
 		s.log.Info("scan_phase_review_complete",
 			slog.String("job_id", jobID),
@@ -441,9 +935,12 @@ func (s *Service) runScan(ctx context.Context, jobID string) {
 			slog.Int("matched_findings", reviewStats.MatchedFindings),
 			slog.Duration("duration", time.Since(reviewStart)),
 		)
+		s.publishEvent(jobID, ScanEvent{Type: EventReviewComplete, FindingCount: reviewStats.MatchedFindings})
 	} else {
 		skipReason := "no_findings"
-		if len(findings) > 0 {
+		if partial {
+			skipReason = "scan_budget_exceeded"
+		} else if len(findings) > 0 {
 			skipReason = "no_ai_client"
 		}
 		s.log.Debug("scan_phase_review_skipped",
@@ -454,12 +951,36 @@ func (s *Service) runScan(ctx context.Context, jobID string) {
 		)
 	}
 
+	// A cancelled scan was abandoned by DrainScans, which records the failure itself
+	if ctx.Err() != nil {
+		s.log.Warn("scan_pipeline_abandoned",
+			slog.String("job_id", jobID),
+			slog.String("error", ctx.Err().Error()),
+		)
+		return
+	}
+
+	// Findings keep their fingerprints across rescans, for permalinks
+	fingerprintFindings(findings, s.scanRepoURL(jobID), subdir, scanPath)
+
 	// Complete job
-	_ = s.completeJobWithStats(ctx, jobID, findings, reviewStats)
+	outcome := jobOutcome{stats: reviewStats, partial: partial, suppressed: suppressed, truncated: truncated}
+	if err := s.completeJobWithStats(ctx, jobID, findings, outcome); err != nil {
+		s.log.Error("scan_complete_failed",
+			slog.String("job_id", jobID),
+			slog.Int("total_findings", len(findings)),
+			slog.String("error", err.Error()),
+		)
+		_ = s.failJob(ctx, jobID, fmt.Sprintf("Storing results failed: %v", err))
+		s.publishEvent(jobID, ScanEvent{Type: EventScanFailed, Message: "Storing results failed"})
+		return
+	}
+	s.publishEvent(jobID, ScanEvent{Type: EventScanCompleted, FindingCount: len(findings)})
 
 	s.log.Info("scan_pipeline_complete",
 		slog.String("job_id", jobID),
 		slog.Int("total_findings", len(findings)),
+		slog.Bool("partial", partial),
 		slog.Duration("total_duration", time.Since(start)),
 	)
 }
@@ -467,14 +988,29 @@ func (s *Service) runScan(ctx context.Context, jobID string) {
 // Database operations
 
 func (s *Service) createJob(ctx context.Context, job *ScanJob) error {
+	return s.insertJob(ctx, s.db, job)
+}
+
+// jobExecer is the statement method shared by scanDB and scanTx.
+type jobExecer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// insertJob stores a new job with db, which may be a transaction.
+func (s *Service) insertJob(ctx context.Context, db jobExecer, job *ScanJob) error {
 	query := `
-		INSERT INTO scan_jobs (id, repo_url, status, created_at, expires_at)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO scan_jobs (id, repo_url, subdir, status, created_at, expires_at, schema_version)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 	`
 	expiresAt := job.CreatedAt.Add(time.Duration(s.retentionDays) * 24 * time.Hour)
 
-	_, err := s.db.ExecContext(ctx, query,
-		job.ID, job.RepoURL, job.Status, job.CreatedAt, expiresAt)
+	var subdir *string
+	if job.Subdir != "" {
+		subdir = &job.Subdir
+	}
+
+	_, err := db.ExecContext(ctx, query,
+		job.ID, job.RepoURL, subdir, job.Status, job.CreatedAt, expiresAt, job.SchemaVersion)
 	return err
 }
 
@@ -482,19 +1018,20 @@ func (s *Service) loadJob(ctx context.Context, jobID string) (*ScanJob, error) {
 	job := &ScanJob{}
 
 	query := `
-		SELECT id, repo_url, status, languages, error, created_at, completed_at, review_stats
+		SELECT id, repo_url, subdir, status, languages, error, created_at, completed_at, review_stats, suppressed_findings, partial, truncated, truncated_findings, schema_version, no_code
 		FROM scan_jobs
 		WHERE id = $1
 	`
 
 	var languagesJSON []byte
-	var errorStr sql.NullString
+	var subdir, errorStr sql.NullString
 	var completedAt sql.NullTime
 	var reviewStatsJSON []byte
 
 	err := s.db.QueryRowContext(ctx, query, jobID).Scan(
-		&job.ID, &job.RepoURL, &job.Status, &languagesJSON,
-		&errorStr, &job.CreatedAt, &completedAt, &reviewStatsJSON,
+		&job.ID, &job.RepoURL, &subdir, &job.Status, &languagesJSON,
+		&errorStr, &job.CreatedAt, &completedAt, &reviewStatsJSON, &job.SuppressedFindings, &job.Partial,
+		&job.Truncated, &job.TruncatedFindings, &job.SchemaVersion, &job.NoCode,
 	)
 	if err == sql.ErrNoRows {
 		return nil, ErrJobNotFound
@@ -506,6 +1043,7 @@ func (s *Service) loadJob(ctx context.Context, jobID string) (*ScanJob, error) {
 	if languagesJSON != nil {
 		_ = json.Unmarshal(languagesJSON, &job.Languages)
 	}
+	job.Subdir = subdir.String
 	if errorStr.Valid {
 		job.Error = errorStr.String
 	}
@@ -530,7 +1068,7 @@ func (s *Service) loadJob(ctx context.Context, jobID string) (*ScanJob, error) {
 
 func (s *Service) loadFindings(ctx context.Context, jobID string) ([]Finding, error) {
 	query := `
-		SELECT id, severity, tool, file_path, line_number, description, remediation, code_example
+		SELECT id, severity, tool, file_path, line_number, description, remediation, code_example, confidence, rule_id, end_line, start_column, end_column, fingerprint, snippet, snippet_start_line
 		FROM scan_findings
 		WHERE scan_job_id = $1
 		ORDER BY 
@@ -551,35 +1089,65 @@ func (s *Service) loadFindings(ctx context.Context, jobID string) ([]Finding, er
 
 	var findings []Finding
 	for rows.Next() {
-		var f Finding
-		var lineNumber sql.NullInt64
-		var remediation, codeExample sql.NullString
-
-		err := rows.Scan(
-			&f.ID, &f.Severity, &f.Tool, &f.FilePath, &lineNumber,
-			&f.Description, &remediation, &codeExample,
-		)
+		f, err := scanFinding(rows)
 		if err != nil {
 			return nil, err
 		}
-
-		if lineNumber.Valid {
-			ln := int(lineNumber.Int64)
-			f.LineNumber = &ln
-		}
-		if remediation.Valid {
-			f.Remediation = remediation.String
-		}
-		if codeExample.Valid {
-			f.CodeExample = codeExample.String
-		}
-
 		findings = append(findings, f)
 	}
 
 	return findings, rows.Err()
 }
 
+// rowScanner is the Scan method shared by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanFinding reads one scan_findings row in loadFindings' column order.
+// Every column added after the first schema version is nullable, so rows
+// stored by older versions load with zero values for the newer fields.
+func scanFinding(row rowScanner) (Finding, error) {
+	var f Finding
+	var lineNumber, endLine, startColumn, endColumn, snippetStart sql.NullInt64
+	var remediation, codeExample, confidence, ruleID, fingerprint, snippet sql.NullString
+
+	err := row.Scan(
+		&f.ID, &f.Severity, &f.Tool, &f.FilePath, &lineNumber,
+		&f.Description, &remediation, &codeExample, &confidence, &ruleID,
+		&endLine, &startColumn, &endColumn, &fingerprint, &snippet, &snippetStart,
+	)
+	if err != nil {
+		return Finding{}, err
+	}
+
+	if lineNumber.Valid {
+		ln := int(lineNumber.Int64)
+		f.LineNumber = &ln
+	}
+	f.EndLine = nullableInt(endLine)
+	f.StartColumn = nullableInt(startColumn)
+	f.EndColumn = nullableInt(endColumn)
+	f.Remediation = remediation.String
+	f.CodeExample = codeExample.String
+	f.Confidence = confidence.String
+	f.RuleID = ruleID.String
+	f.Fingerprint = fingerprint.String
+	f.Snippet = snippet.String
+	f.SnippetStartLine = nullableInt(snippetStart)
+
+	return f, nil
+}
+
+// nullableInt returns a pointer to a non-NULL column value, or nil.
+func nullableInt(n sql.NullInt64) *int {
+	if !n.Valid {
+		return nil
+	}
+	v := int(n.Int64)
+	return &v
+}
+
 func (s *Service) updateJobStatus(ctx context.Context, jobID, status, errorMsg string) error {
 	query := `UPDATE scan_jobs SET status = $1, error = $2 WHERE id = $3`
 	var errPtr *string
@@ -604,59 +1172,138 @@ func (s *Service) failJob(ctx context.Context, jobID, errorMsg string) error {
 	return err
 }
 
-func (s *Service) completeJobWithStats(ctx context.Context, jobID string, findings []Finding, stats *ReviewStats) error {
+// completeJobNoCode marks the job completed without findings because no
+// scannable code was detected.
+func (s *Service) completeJobNoCode(ctx context.Context, jobID string) error {
+	query := `UPDATE scan_jobs SET status = $1, completed_at = $2, no_code = true WHERE id = $3`
+	_, err := s.db.ExecContext(ctx, query, StatusCompleted, time.Now(), jobID)
+	return err
+}
+
+// jobOutcome is what a completed scan records on its job besides findings.
+type jobOutcome struct {
+	stats      *ReviewStats // nil when the AI review did not run
+	partial    bool
+	suppressed int // secret findings dropped by the allowlist
+	truncated  int // findings dropped by the max_findings cap
+}
+
+// completeJobWithStats marks the job completed, records its outcome and
+// stores its findings in a single transaction, so a failure leaves the job
+// without partial results.
+func (s *Service) completeJobWithStats(ctx context.Context, jobID string, findings []Finding, outcome jobOutcome) (err error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
 	now := time.Now()
 
 	// Update job status with optional review stats
-	var err error
-	if stats != nil {
-		statsJSON, _ := json.Marshal(stats)
-		query := `UPDATE scan_jobs SET status = $1, completed_at = $2, review_stats = $3 WHERE id = $4`
-		_, err = s.db.ExecContext(ctx, query, StatusCompleted, now, statsJSON, jobID)
+	truncated := outcome.truncated > 0
+	if outcome.stats != nil {
+		statsJSON, _ := json.Marshal(outcome.stats)
+		query := `UPDATE scan_jobs SET status = $1, completed_at = $2, review_stats = $3, partial = $4,
+			suppressed_findings = $5, truncated = $6, truncated_findings = $7 WHERE id = $8`
+		_, err = tx.ExecContext(ctx, query, StatusCompleted, now, statsJSON, outcome.partial,
+			outcome.suppressed, truncated, outcome.truncated, jobID)
 	} else {
-		query := `UPDATE scan_jobs SET status = $1, completed_at = $2 WHERE id = $3`
-		_, err = s.db.ExecContext(ctx, query, StatusCompleted, now, jobID)
+		query := `UPDATE scan_jobs SET status = $1, completed_at = $2, partial = $3,
+			suppressed_findings = $4, truncated = $5, truncated_findings = $6 WHERE id = $7`
+		_, err = tx.ExecContext(ctx, query, StatusCompleted, now, outcome.partial,
+			outcome.suppressed, truncated, outcome.truncated, jobID)
 	}
 	if err != nil {
-		return err
+		return fmt.Errorf("update job: %w", err)
 	}
 
-	// Insert findings
-	for _, f := range findings {
-		err := s.insertFinding(ctx, jobID, f)
-		if err != nil {
-			// Log but continue
-			continue
+	for start := 0; start < len(findings); start += findingInsertBatchSize {
+		batch := findings[start:min(start+findingInsertBatchSize, len(findings))]
+		if err = insertFindings(ctx, tx, jobID, batch); err != nil {
+			return fmt.Errorf("insert findings: %w", err)
 		}
 	}
 
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
 	return nil
 }
 
-func (s *Service) insertFinding(ctx context.Context, jobID string, f Finding) error {
-	query := `
-		INSERT INTO scan_findings (id, scan_job_id, severity, tool, file_path, line_number, description, remediation, code_example)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-	`
+// findingInsertBatchSize caps the rows per INSERT, keeping each statement
+// well under PostgreSQL's 65535 bind parameter limit.
+const findingInsertBatchSize = 500
 
+// findingColumns is the number of scan_findings columns insertFindings writes.
+const findingColumns = 17
+
+// insertFindings stores findings with a single multi-row INSERT.
+func insertFindings(ctx context.Context, tx scanTx, jobID string, findings []Finding) error {
+	if len(findings) == 0 {
+		return nil
+	}
+
+	var query strings.Builder
+	query.WriteString(`INSERT INTO scan_findings (id, scan_job_id, severity, tool, file_path, line_number, description, remediation, code_example, confidence, rule_id, end_line, start_column, end_column, fingerprint, snippet, snippet_start_line) VALUES `)
+	args := make([]any, 0, len(findings)*findingColumns)
+	for i, f := range findings {
+		if i > 0 {
+			query.WriteString(", ")
+		}
+		query.WriteString("(")
+		for col := range findingColumns {
+			if col > 0 {
+				query.WriteString(", ")
+			}
+			fmt.Fprintf(&query, "$%d", i*findingColumns+col+1)
+		}
+		query.WriteString(")")
+		args = append(args, findingArgs(jobID, f)...)
+	}
+
+	_, err := tx.ExecContext(ctx, query.String(), args...)
+	return err
+}
+
+// findingArgs returns the insert values for one finding in column order.
+// Optional fields are stored as NULL when empty.
+func findingArgs(jobID string, f Finding) []any {
 	var lineNumber *int
 	if f.LineNumber != nil {
 		lineNumber = f.LineNumber
 	}
 
-	var remediation, codeExample *string
+	var remediation, codeExample, confidence, ruleID, fingerprint, snippet *string
 	if f.Remediation != "" {
 		remediation = &f.Remediation
 	}
 	if f.CodeExample != "" {
 		codeExample = &f.CodeExample
 	}
+	if f.Confidence != "" {
+		confidence = &f.Confidence
+	}
+	if f.RuleID != "" {
+		ruleID = &f.RuleID
+	}
+	if f.Fingerprint != "" {
+		fingerprint = &f.Fingerprint
+	}
+	if f.Snippet != "" {
+		snippet = &f.Snippet
+	}
 
-	_, err := s.db.ExecContext(ctx, query,
+	return []any{
 		f.ID, jobID, f.Severity, f.Tool, f.FilePath, lineNumber,
-		f.Description, remediation, codeExample,
-	)
-	return err
+		f.Description, remediation, codeExample, confidence, ruleID,
+		f.EndLine, f.StartColumn, f.EndColumn, fingerprint,
+		snippet, f.SnippetStartLine,
+	}
 }
 
 // GetConfig returns the scanner configuration.
@@ -1,7 +1,13 @@
 package scanner
 
 import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
 	"slices"
+	"strings"
 	"testing"
 	"testing/quick"
 	"time"
@@ -296,3 +302,413 @@ func TestProperty2_JobCreationRoundTrip_EdgeCases(t *testing.T) {
 		}
 	})
 }
+
+func TestScanRepository_BudgetExceededCompletesPartial(t *testing.T) {
+	repoPath := t.TempDir()
+	trivyCmd := "trivy fs --format json --scanners vuln,secret,misconfig --severity CRITICAL,HIGH,MEDIUM,LOW --skip-dirs .git " + repoPath
+	exec := &fakeExecutor{
+		outputs: map[string][]byte{
+			trivyCmd: []byte(`{"Results":[{"Target":"go.sum","Vulnerabilities":[{"VulnerabilityID":"CVE-2024-0001","Severity":"HIGH","Title":"Bad dependency","Description":"Remote code execution"}]}]}`),
+		},
+		// semgrep runs second and outlasts the budget
+		delays: map[string]time.Duration{"semgrep": time.Second},
+	}
+
+	db := &recordingDB{}
+	s := NewService(nil, nil, "",
+		WithServiceToolRunner(NewToolRunner(WithExecutor(exec))),
+		WithMaxScanDuration(100*time.Millisecond),
+	)
+	s.db = db
+
+	s.scanRepository(context.Background(), "job-1", repoPath, "", nil, time.Now())
+
+	for _, call := range exec.calls {
+		if strings.HasPrefix(call, "trufflehog") || strings.HasPrefix(call, "gitleaks") {
+			t.Errorf("tool launched after the budget ran out: %q", call)
+		}
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	completed := completionUpdate(t, db)
+	if completed.args[0] != StatusCompleted {
+		t.Errorf("status = %v, want %q", completed.args[0], StatusCompleted)
+	}
+	if partial := completed.args[len(completed.args)-5]; partial != true {
+		t.Errorf("partial = %v, want true", partial)
+	}
+	if inserted := len(db.insertedFindings()); inserted != 1 {
+		t.Errorf("expected the trivy finding gathered before the budget to be stored, got %d inserts", inserted)
+	}
+}
+
+func TestScanRepository_MaxFindingsKeepsMostSevere(t *testing.T) {
+	repoPath := t.TempDir()
+	trivyCmd := "trivy fs --format json --scanners vuln,secret,misconfig --severity CRITICAL,HIGH,MEDIUM,LOW --skip-dirs .git " + repoPath
+	var vulns []string
+	for i, severity := range []string{"LOW", "CRITICAL", "MEDIUM", "HIGH", "LOW"} {
+		vulns = append(vulns, fmt.Sprintf(`{"VulnerabilityID":"CVE-2024-000%d","Severity":"%s","Title":"Issue %d","Description":"Details"}`, i, severity, i))
+	}
+	exec := &fakeExecutor{outputs: map[string][]byte{
+		trivyCmd: []byte(`{"Results":[{"Target":"go.sum","Vulnerabilities":[` + strings.Join(vulns, ",") + `]}]}`),
+	}}
+
+	db := &recordingDB{}
+	s := NewService(nil, nil, "",
+		WithServiceToolRunner(NewToolRunner(WithExecutor(exec))),
+		WithMaxStoredFindings(2),
+	)
+	s.db = db
+
+	s.scanRepository(context.Background(), "job-1", repoPath, "", nil, time.Now())
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	var severities []string
+	for _, row := range db.insertedFindings() {
+		severities = append(severities, row[2].(string))
+	}
+	if want := []string{"critical", "high"}; !slices.Equal(severities, want) {
+		t.Errorf("stored severities = %v, want %v", severities, want)
+	}
+	completed := completionUpdate(t, db)
+	if args := completed.args; args[len(args)-3] != true || args[len(args)-2] != 3 {
+		t.Errorf("truncated, truncated_findings = %v, %v, want true, 3", args[len(args)-3], args[len(args)-2])
+	}
+}
+
+func TestScanRepository_UnderMaxFindingsNotTruncated(t *testing.T) {
+	repoPath := t.TempDir()
+	db := &recordingDB{}
+	s := NewService(nil, nil, "",
+		WithServiceToolRunner(NewToolRunner(WithExecutor(&fakeExecutor{}))),
+		WithMaxStoredFindings(2),
+	)
+	s.db = db
+
+	s.scanRepository(context.Background(), "job-1", repoPath, "", nil, time.Now())
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	completed := completionUpdate(t, db)
+	if args := completed.args; args[len(args)-3] != false || args[len(args)-2] != 0 {
+		t.Errorf("truncated, truncated_findings = %v, %v, want false, 0", args[len(args)-3], args[len(args)-2])
+	}
+}
+
+// completionUpdate returns the statement that marked the job completed; its
+// last arguments are partial, suppressed_findings, truncated,
+// truncated_findings and the job ID.
+func completionUpdate(t *testing.T, db *recordingDB) *execRecord {
+	t.Helper()
+	for i, e := range db.execs {
+		if strings.Contains(e.query, "UPDATE scan_jobs") && strings.Contains(e.query, "truncated_findings") {
+			return &db.execs[i]
+		}
+	}
+	t.Fatal("expected the job to be completed")
+	return nil
+}
+
+func TestDetectAndScan_NoCodeCompletesWithoutTools(t *testing.T) {
+	repoPath := t.TempDir()
+	for _, file := range []string{"README.md", "LICENSE", "docs/guide.md", "assets/logo.png"} {
+		path := filepath.Join(repoPath, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("not code"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	exec := &fakeExecutor{}
+	db := &recordingDB{}
+	s := NewService(nil, nil, "", WithServiceToolRunner(NewToolRunner(WithExecutor(exec))))
+	s.db = db
+
+	s.trackScan("job-1")
+	events, cancel, _ := s.Subscribe("job-1")
+	defer cancel()
+
+	s.detectAndScan(context.Background(), "job-1", repoPath, "", repoPath, time.Now())
+	s.untrackScan("job-1")
+
+	if len(exec.calls) != 0 {
+		t.Errorf("tools ran for a repository without code: %v", exec.calls)
+	}
+
+	var last ScanEvent
+	for event := range events {
+		last = event
+	}
+	if last.Type != EventScanCompleted || last.Message != NoCodeMessage {
+		t.Errorf("last event = %+v, want completed with %q", last, NoCodeMessage)
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if len(db.insertedFindings()) != 0 {
+		t.Error("expected no findings to be stored")
+	}
+	var completed bool
+	for _, e := range db.execs {
+		if strings.Contains(e.query, "no_code = true") {
+			completed = e.args[0] == StatusCompleted
+		}
+	}
+	if !completed {
+		t.Error("expected the job to be completed as a no-code scan")
+	}
+}
+
+func TestDetectAndScan_CleanScanIsNotNoCode(t *testing.T) {
+	repoPath := t.TempDir()
+	if err := os.WriteFile(filepath.Join(repoPath, "main.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	exec := &fakeExecutor{}
+	db := &recordingDB{}
+	s := NewService(nil, nil, "", WithServiceToolRunner(NewToolRunner(WithExecutor(exec))))
+	s.db = db
+
+	s.detectAndScan(context.Background(), "job-1", repoPath, "", repoPath, time.Now())
+
+	if len(exec.calls) == 0 {
+		t.Error("expected tools to run for a repository with code")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for _, e := range db.execs {
+		if strings.Contains(e.query, "no_code") {
+			t.Errorf("clean scan marked as no-code: %s", e.query)
+		}
+	}
+}
+
+func TestSubscribe_MidScanReceivesSubsequentEvents(t *testing.T) {
+	repoPath := t.TempDir()
+	exec := &fakeExecutor{
+		// trivy runs first and holds the scan long enough to subscribe mid-way
+		delays: map[string]time.Duration{"trivy": 200 * time.Millisecond},
+	}
+	s := NewService(nil, nil, "", WithServiceToolRunner(NewToolRunner(WithExecutor(exec))))
+	s.db = &recordingDB{}
+
+	s.trackScan("job-1")
+	first, cancelFirst, ok := s.Subscribe("job-1")
+	if !ok {
+		t.Fatal("expected a tracked scan to accept subscribers")
+	}
+	defer cancelFirst()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.scanRepository(context.Background(), "job-1", repoPath, "", nil, time.Now())
+		s.untrackScan("job-1")
+	}()
+
+	// Wait until trivy is running, then subscribe
+	for event := range first {
+		if event.Type == EventToolStarted && event.Tool == "trivy" {
+			break
+		}
+	}
+	late, cancelLate, ok := s.Subscribe("job-1")
+	if !ok {
+		t.Fatal("expected the running scan to accept a late subscriber")
+	}
+	defer cancelLate()
+
+	var got []ScanEvent
+	timeout := time.After(5 * time.Second)
+	for open := true; open; {
+		select {
+		case event, more := <-late:
+			if more {
+				got = append(got, event)
+			}
+			open = more
+		case <-timeout:
+			t.Fatal("timed out waiting for the event stream to close")
+		}
+	}
+	<-done
+
+	if len(got) == 0 {
+		t.Fatal("expected events after subscribing")
+	}
+	if got[0].Type != EventToolComplete || got[0].Tool != "trivy" {
+		t.Errorf("first event = %+v, want trivy tool_complete", got[0])
+	}
+	for _, event := range got {
+		if event.JobID != "job-1" {
+			t.Errorf("event %s has job_id %q", event.Type, event.JobID)
+		}
+		if event.Type == EventToolStarted && event.Tool == "trivy" {
+			t.Error("late subscriber received an event from before it subscribed")
+		}
+	}
+	if last := got[len(got)-1]; last.Type != EventScanCompleted {
+		t.Errorf("last event = %s, want %s", last.Type, EventScanCompleted)
+	}
+
+	if _, _, ok := s.Subscribe("job-1"); ok {
+		t.Error("expected a finished scan to reject subscribers")
+	}
+}
+
+func TestPublishEvent_SlowSubscriberDoesNotBlock(t *testing.T) {
+	s := NewService(nil, nil, "")
+	s.trackScan("job-1")
+	_, cancel, _ := s.Subscribe("job-1")
+	defer cancel()
+
+	// Never read: publishing past the buffer must drop rather than block
+	finished := make(chan struct{})
+	go func() {
+		for range subscriberBuffer * 2 {
+			s.publishEvent("job-1", ScanEvent{Type: EventToolStarted})
+		}
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Fatal("publishEvent blocked on a slow subscriber")
+	}
+	s.untrackScan("job-1")
+}
+
+// fakeFindingRow supplies driver values for one scan_findings row.
+// Values are assigned the way database/sql does: nil for NULL columns.
+type fakeFindingRow []any
+
+func (r fakeFindingRow) Scan(dest ...any) error {
+	if len(dest) != len(r) {
+		return fmt.Errorf("expected %d destinations, got %d", len(r), len(dest))
+	}
+	for i, d := range dest {
+		switch d := d.(type) {
+		case sql.Scanner:
+			if err := d.Scan(r[i]); err != nil {
+				return err
+			}
+		case *string:
+			s, ok := r[i].(string)
+			if !ok {
+				return fmt.Errorf("column %d: cannot scan %v into string", i, r[i])
+			}
+			*d = s
+		default:
+			return fmt.Errorf("column %d: unsupported destination %T", i, d)
+		}
+	}
+	return nil
+}
+
+func TestScanFinding_OlderRowLoadsWithDefaults(t *testing.T) {
+	// A schema version 1 row: optional columns were never written, and
+	// rule_id, the range columns, fingerprint and snippet did not exist yet, so all of them read back as NULL
+	row := fakeFindingRow{"finding-1", "high", "trivy", "go.sum", nil, "Vulnerable dependency", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil}
+
+	f, err := scanFinding(row)
+	if err != nil {
+		t.Fatalf("scanFinding() error = %v", err)
+	}
+	if f.ID != "finding-1" || f.Severity != "high" || f.Tool != "trivy" || f.FilePath != "go.sum" || f.Description != "Vulnerable dependency" {
+		t.Errorf("required fields not loaded: %+v", f)
+	}
+	if f.LineNumber != nil || f.Remediation != "" || f.CodeExample != "" || f.Confidence != "" || f.RuleID != "" {
+		t.Errorf("expected zero-valued optional fields, got %+v", f)
+	}
+	if f.EndLine != nil || f.StartColumn != nil || f.EndColumn != nil {
+		t.Errorf("expected no range, got %+v", f)
+	}
+	if f.Fingerprint != "" || f.Snippet != "" || f.SnippetStartLine != nil {
+		t.Errorf("expected no fingerprint or snippet, got %+v", f)
+	}
+}
+
+func TestScanFinding_CurrentRow(t *testing.T) {
+	row := fakeFindingRow{"finding-2", "medium", "semgrep", "main.go", int64(12), "SQL injection", "Use parameters", "db.Query(q, id)", "high", "go.lang.sqli", int64(14), int64(5), int64(22), "fp-1", "rows, err := db.Query(q, id)", int64(10)}
+
+	f, err := scanFinding(row)
+	if err != nil {
+		t.Fatalf("scanFinding() error = %v", err)
+	}
+	if f.LineNumber == nil || *f.LineNumber != 12 {
+		t.Errorf("line number = %v, want 12", f.LineNumber)
+	}
+	if f.Remediation != "Use parameters" || f.CodeExample != "db.Query(q, id)" || f.Confidence != "high" || f.RuleID != "go.lang.sqli" {
+		t.Errorf("optional fields not loaded: %+v", f)
+	}
+	if f.EndLine == nil || *f.EndLine != 14 || f.StartColumn == nil || *f.StartColumn != 5 || f.EndColumn == nil || *f.EndColumn != 22 {
+		t.Errorf("range = %v:%v-%v, want 14:5-22", f.EndLine, f.StartColumn, f.EndColumn)
+	}
+	if f.Fingerprint != "fp-1" {
+		t.Errorf("fingerprint = %q, want fp-1", f.Fingerprint)
+	}
+	if f.Snippet == "" || f.SnippetStartLine == nil || *f.SnippetStartLine != 10 {
+		t.Errorf("snippet = %q from %v, want the stored snippet from line 10", f.Snippet, f.SnippetStartLine)
+	}
+}
+
+func TestCompleteJobWithStats_CommitsStatusAndFindingsTogether(t *testing.T) {
+	line := 7
+	findings := make([]Finding, findingInsertBatchSize+1)
+	for i := range findings {
+		findings[i] = Finding{ID: fmt.Sprintf("f-%d", i), Severity: SeverityLow, Tool: "semgrep", FilePath: "main.go", Description: "Issue"}
+	}
+	findings[0].LineNumber = &line
+	findings[0].RuleID = "go.lang.sqli"
+
+	db := &recordingDB{}
+	s := NewService(nil, nil, "")
+	s.db = db
+
+	if err := s.completeJobWithStats(context.Background(), "job-1", findings, jobOutcome{suppressed: 2, truncated: 4}); err != nil {
+		t.Fatalf("completeJobWithStats() error = %v", err)
+	}
+
+	if len(db.execs) != 3 || !strings.Contains(db.execs[0].query, "UPDATE scan_jobs") {
+		t.Fatalf("expected a status update and two batched inserts, got %d statements", len(db.execs))
+	}
+	if args := db.execs[0].args; len(args) != 7 || args[3] != 2 || args[4] != true || args[5] != 4 {
+		t.Errorf("status update args = %v, want the suppressed and truncated counts", args)
+	}
+	rows := db.insertedFindings()
+	if len(rows) != len(findings) {
+		t.Fatalf("stored %d findings, want %d", len(rows), len(findings))
+	}
+	if got := rows[0][5].(*int); got == nil || *got != 7 {
+		t.Errorf("line_number = %v, want 7", got)
+	}
+	if got := rows[0][10].(*string); got == nil || *got != "go.lang.sqli" {
+		t.Errorf("rule_id = %v, want go.lang.sqli", got)
+	}
+	if rows[1][5].(*int) != nil || rows[1][7].(*string) != nil || rows[1][10].(*string) != nil {
+		t.Errorf("expected empty optional fields to be stored as NULL, got %v", rows[1])
+	}
+}
+
+func TestCompleteJobWithStats_FailedInsertStoresNothing(t *testing.T) {
+	findings := []Finding{
+		{ID: "f-1", Severity: SeverityHigh, Tool: "trivy", FilePath: "go.sum", Description: "Vulnerable dependency"},
+		{ID: "f-2", Severity: SeverityLow, Tool: "semgrep", FilePath: "main.go", Description: "Issue"},
+	}
+
+	db := &recordingDB{failOn: "INSERT INTO scan_findings"}
+	s := NewService(nil, nil, "")
+	s.db = db
+
+	if err := s.completeJobWithStats(context.Background(), "job-1", findings, jobOutcome{stats: &ReviewStats{}, suppressed: 1}); err == nil {
+		t.Fatal("completeJobWithStats() error = nil, want the insert failure")
+	}
+	if len(db.execs) != 0 {
+		t.Errorf("expected the status update to roll back with the findings, got %+v", db.execs)
+	}
+}
@@ -0,0 +1,112 @@
+package scanner
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Canonical severity scale
+//
+// Every finding is normalized to one of five levels, most to least severe:
+//
+//	critical - exploitable now (verified secrets, CVSS >= 9.0)
+//	high     - serious issues and vulnerable dependencies (CVSS >= 7.0)
+//	medium   - likely issues worth fixing (unverified secrets, CVSS >= 4.0); also the fallback for unknown values
+//	low      - minor or low-confidence issues (CVSS > 0)
+//	info     - informational notes (CVSS 0)
+
+// toolDefaultSeverity is used when a tool reports no severity of its own,
+// e.g. secret scanners and dependency auditors that only list matches.
+var toolDefaultSeverity = map[string]string{
+	"trufflehog":    SeverityHigh,
+	"gitleaks":      SeverityHigh,
+	"govulncheck":   SeverityHigh,
+	"pip-audit":     SeverityHigh,
+	"safety":        SeverityHigh,
+	"cargo-audit":   SeverityHigh,
+	"bundler-audit": SeverityHigh,
+}
+
+// toolSeverityOverrides holds tool-specific values that the generic mapping
+// would get wrong or not recognize, keyed by lowercase raw value.
+var toolSeverityOverrides = map[string]map[string]string{
+	// Brakeman reports confidence, not severity
+	"brakeman": {
+		"high":   SeverityHigh,
+		"medium": SeverityMedium,
+		"weak":   SeverityLow,
+	},
+	// Semgrep INFO rules are usually low-severity findings rather than notes
+	"semgrep": {
+		"info": SeverityLow,
+	},
+	// TruffleHog marks secrets it could confirm against the live service
+	"trufflehog": {
+		"verified":   SeverityCritical,
+		"unverified": SeverityMedium,
+	},
+	// Trivy uses UNKNOWN when the advisory has no rating yet
+	"trivy": {
+		"unknown": SeverityMedium,
+	},
+	// Bandit uses UNDEFINED for checks without a rating
+	"bandit": {
+		"undefined": SeverityLow,
+	},
+}
+
+// NormalizeSeverity maps a tool's raw severity to the canonical scale.
+// Tool-specific overrides are applied first, then numeric CVSS scores,
+// then common names; anything unrecognized is treated as medium.
+func NormalizeSeverity(tool, raw string) string {
+	severity := strings.ToLower(strings.TrimSpace(raw))
+
+	if severity == "" {
+		if def, ok := toolDefaultSeverity[tool]; ok {
+			return def
+		}
+		return SeverityMedium
+	}
+
+	if overrides, ok := toolSeverityOverrides[tool]; ok {
+		if mapped, ok := overrides[severity]; ok {
+			return mapped
+		}
+	}
+
+	if score, err := strconv.ParseFloat(severity, 64); err == nil {
+		return severityFromScore(score)
+	}
+
+	switch severity {
+	case "critical", "crit":
+		return SeverityCritical
+	case "high", "error":
+		return SeverityHigh
+	case "medium", "moderate", "warning", "warn":
+		return SeverityMedium
+	case "low":
+		return SeverityLow
+	case "info", "informational", "note":
+		return SeverityInfo
+	default:
+		// Default to medium if unknown
+		return SeverityMedium
+	}
+}
+
+// severityFromScore maps a CVSS base score to the canonical scale.
+func severityFromScore(score float64) string {
+	switch {
+	case score >= 9.0:
+		return SeverityCritical
+	case score >= 7.0:
+		return SeverityHigh
+	case score >= 4.0:
+		return SeverityMedium
+	case score > 0:
+		return SeverityLow
+	default:
+		return SeverityInfo
+	}
+}
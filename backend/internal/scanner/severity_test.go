@@ -0,0 +1,110 @@
+package scanner
+
+import "testing"
+
+func TestNormalizeSeverity(t *testing.T) {
+	tests := []struct {
+		tool string
+		raw  string
+		want string
+	}{
+		// Trivy: uppercase levels plus UNKNOWN
+		{"trivy", "CRITICAL", SeverityCritical},
+		{"trivy", "HIGH", SeverityHigh},
+		{"trivy", "MEDIUM", SeverityMedium},
+		{"trivy", "LOW", SeverityLow},
+		{"trivy", "UNKNOWN", SeverityMedium},
+
+		// Semgrep: ERROR/WARNING/INFO
+		{"semgrep", "ERROR", SeverityHigh},
+		{"semgrep", "WARNING", SeverityMedium},
+		{"semgrep", "INFO", SeverityLow},
+
+		// Bandit: HIGH/MEDIUM/LOW/UNDEFINED
+		{"bandit", "HIGH", SeverityHigh},
+		{"bandit", "LOW", SeverityLow},
+		{"bandit", "UNDEFINED", SeverityLow},
+
+		// npm audit: lowercase with moderate
+		{"npm-audit", "critical", SeverityCritical},
+		{"npm-audit", "moderate", SeverityMedium},
+		{"npm-audit", "info", SeverityInfo},
+
+		// Brakeman: confidence levels
+		{"brakeman", "High", SeverityHigh},
+		{"brakeman", "Medium", SeverityMedium},
+		{"brakeman", "Weak", SeverityLow},
+
+		// TruffleHog: verification status
+		{"trufflehog", "verified", SeverityCritical},
+		{"trufflehog", "unverified", SeverityMedium},
+
+		// Tools without a severity fall back to their default
+		{"gitleaks", "", SeverityHigh},
+		{"govulncheck", "", SeverityHigh},
+		{"pip-audit", "", SeverityHigh},
+		{"safety", "", SeverityHigh},
+		{"cargo-audit", "", SeverityHigh},
+		{"bundler-audit", "", SeverityHigh},
+
+		// Numeric CVSS scores from any tool
+		{"trivy", "9.8", SeverityCritical},
+		{"trivy", "7.5", SeverityHigh},
+		{"trivy", "5", SeverityMedium},
+		{"trivy", "2.1", SeverityLow},
+		{"trivy", "0", SeverityInfo},
+
+		// Unknown tools and values
+		{"", "  High  ", SeverityHigh},
+		{"", "", SeverityMedium},
+		{"unknown-tool", "weak", SeverityMedium},
+		{"unknown-tool", "bogus", SeverityMedium},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tool+"/"+tt.raw, func(t *testing.T) {
+			if got := NormalizeSeverity(tt.tool, tt.raw); got != tt.want {
+				t.Errorf("NormalizeSeverity(%q, %q) = %q, want %q", tt.tool, tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeSeverity_AlwaysCanonical(t *testing.T) {
+	for tool := range toolsByName {
+		for _, raw := range []string{"", "CRITICAL", "Weak", "verified", "7.2", "garbage"} {
+			if got := NormalizeSeverity(tool, raw); !IsValidSeverity(got) {
+				t.Errorf("NormalizeSeverity(%q, %q) = %q is not canonical", tool, raw, got)
+			}
+		}
+	}
+}
+
+func TestParseTruffleHogOutput_VerifiedIsCritical(t *testing.T) {
+	output := []byte(`{"SourceMetadata":{"Data":{"Filesystem":{"file":"config.env","line":3}}},"DetectorName":"AWS","Verified":true}
+{"SourceMetadata":{"Data":{"Filesystem":{"file":"old.env","line":1}}},"DetectorName":"Slack","Verified":false}`)
+
+	findings := parseTruffleHogOutput(output)
+	if len(findings) != 2 {
+		t.Fatalf("expected 2 findings, got %d", len(findings))
+	}
+	if findings[0].Severity != SeverityCritical {
+		t.Errorf("verified secret severity = %q, want critical", findings[0].Severity)
+	}
+	if findings[1].Severity != SeverityMedium {
+		t.Errorf("unverified secret severity = %q, want medium", findings[1].Severity)
+	}
+}
+
+func TestFilterVerifiedSecrets_DropsUnverified(t *testing.T) {
+	output := []byte(`{"SourceMetadata":{"Data":{"Filesystem":{"file":"config.env","line":3}}},"DetectorName":"AWS","Verified":true}
+{"SourceMetadata":{"Data":{"Filesystem":{"file":"old.env","line":1}}},"DetectorName":"Slack","Verified":false}`)
+
+	findings := filterVerifiedSecrets(parseTruffleHogOutput(output))
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 verified finding, got %d", len(findings))
+	}
+	if findings[0].RuleID != "AWS" {
+		t.Errorf("kept finding = %q, want AWS", findings[0].RuleID)
+	}
+}
@@ -0,0 +1,115 @@
+package scanner
+
+import (
+	"strings"
+
+	"better-kiro-prompts/internal/sanitize"
+)
+
+// Snippet limits. A snippet holds at most the flagged lines (capped at
+// fingerprintContextLines) plus the context on each side, with long lines
+// cut, and never more than maxSnippetBytes in total.
+const (
+	DefaultSnippetContextLines = 3
+	MaxSnippetContextLines     = 10
+	maxSnippetLineLength       = 240 // runes
+	maxSnippetBytes            = 4096
+)
+
+// captureSnippets stores the code around each finding in results, read from
+// the files under scanPath, so findings can show their context after the
+// clone is gone. Known credential patterns are masked in every snippet.
+// Secret findings also have the secret masked line by line, and every line of
+// their flagged range dropped entirely when the tool did not report the
+// matched value.
+func captureSnippets(results []ToolResult, scanPath string, contextLines int) {
+	files := make(map[string][]string)
+	for i := range results {
+		for j := range results[i].Findings {
+			raw := &results[i].Findings[j]
+			if raw.LineNumber <= 0 || raw.FilePath == "" {
+				continue
+			}
+
+			rel := scanRelativePath(raw.FilePath, scanPath)
+			lines, ok := files[rel]
+			if !ok {
+				lines = readFileLines(scanPath, rel)
+				files[rel] = lines
+			}
+			if raw.LineNumber > len(lines) {
+				continue
+			}
+
+			flaggedEnd := raw.LineNumber
+			if raw.EndLine > flaggedEnd {
+				flaggedEnd = raw.EndLine
+			}
+			last := min(flaggedEnd, raw.LineNumber+fingerprintContextLines-1)
+			start := max(raw.LineNumber-contextLines, 1)
+			end := min(last+contextLines, len(lines))
+
+			snippet := make([]string, 0, end-start+1)
+			for n := start; n <= end; n++ {
+				line := lines[n-1]
+				if raw.IsSecret {
+					line = redactSecretLine(line, raw.Secret, n >= raw.LineNumber && n <= flaggedEnd)
+				} else {
+					line = sanitize.MaskSecrets(line)
+				}
+				snippet = append(snippet, truncateSnippetLine(line))
+			}
+			raw.Snippet = joinSnippet(snippet)
+			raw.SnippetStartLine = start
+		}
+	}
+}
+
+// redactSecretLine masks secrets in one line of a secret finding's snippet.
+// Each line of a multi-line secret, such as a PEM key, is masked on its own,
+// since a snippet line only ever holds part of it. A flagged line is replaced
+// whole when the matched secret is unknown, since the credential patterns may
+// not recognise it.
+func redactSecretLine(line, secret string, flagged bool) string {
+	if secret != "" {
+		for _, part := range strings.Split(secret, "\n") {
+			if part = strings.TrimSpace(part); part != "" {
+				line = strings.ReplaceAll(line, part, sanitize.MaskedSecret)
+			}
+		}
+	} else if flagged && strings.TrimSpace(line) != "" {
+		return sanitize.MaskedSecret
+	}
+	return sanitize.MaskSecrets(line)
+}
+
+// truncateSnippetLine cuts a line to maxSnippetLineLength runes, so minified
+// files do not fill the snippet.
+func truncateSnippetLine(line string) string {
+	line = strings.TrimRight(line, "\r")
+	runes := []rune(line)
+	if len(runes) <= maxSnippetLineLength {
+		return line
+	}
+	return string(runes[:maxSnippetLineLength]) + "…"
+}
+
+// joinSnippet joins lines up to maxSnippetBytes, dropping the lines that do
+// not fit.
+func joinSnippet(lines []string) string {
+	var b strings.Builder
+	for i, line := range lines {
+		size := len(line)
+		if i > 0 {
+			size++
+		}
+		if b.Len()+size > maxSnippetBytes {
+			break
+		}
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(line)
+	}
+	return b.String()
+}
@@ -0,0 +1,97 @@
+package scanner
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrSubdirNotFound is returned when a requested subdirectory is not a
+// directory in the cloned repository.
+var ErrSubdirNotFound = errors.New("subdirectory not found in repository")
+
+// resolveScanPath returns the directory to scan for subdir, a path from
+// NormalizeSubdir, within the clone at repoPath. It fails if the directory is
+// missing or a symlink leads out of the clone.
+func resolveScanPath(repoPath, subdir string) (string, error) {
+	if subdir == "" {
+		return repoPath, nil
+	}
+
+	scanPath := filepath.Join(repoPath, filepath.FromSlash(subdir))
+	info, err := os.Stat(scanPath)
+	if err != nil || !info.IsDir() {
+		return "", ErrSubdirNotFound
+	}
+
+	root, err := filepath.EvalSymlinks(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("resolve repository path: %w", err)
+	}
+	resolved, err := filepath.EvalSymlinks(scanPath)
+	if err != nil {
+		return "", fmt.Errorf("resolve subdirectory: %w", err)
+	}
+	if !isWithin(root, resolved) {
+		return "", ErrSubdirEscapesRepo
+	}
+
+	return scanPath, nil
+}
+
+// confineToScanPath rewrites finding paths relative to scanPath and drops
+// findings located outside it. Tools report either absolute paths or paths
+// relative to the directory they ran in, which is scanPath. It returns the
+// filtered results and how many findings were dropped.
+func confineToScanPath(results []ToolResult, scanPath string) ([]ToolResult, int) {
+	dropped := 0
+	confined := make([]ToolResult, len(results))
+
+	for i, result := range results {
+		confined[i] = result
+		if len(result.Findings) == 0 {
+			continue
+		}
+
+		kept := make([]RawFinding, 0, len(result.Findings))
+		for _, f := range result.Findings {
+			// Dependency findings may have no file; they belong to the scanned tree
+			if f.FilePath == "" {
+				kept = append(kept, f)
+				continue
+			}
+
+			rel := filepath.Clean(f.FilePath)
+			if filepath.IsAbs(rel) {
+				var err error
+				if rel, err = filepath.Rel(scanPath, rel); err != nil {
+					dropped++
+					continue
+				}
+			}
+			if leavesRoot(rel) {
+				dropped++
+				continue
+			}
+
+			f.FilePath = filepath.ToSlash(rel)
+			kept = append(kept, f)
+		}
+		confined[i].Findings = kept
+	}
+
+	return confined, dropped
+}
+
+// isWithin reports whether path is root or a descendant of it.
+func isWithin(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && !leavesRoot(rel)
+}
+
+// leavesRoot reports whether a cleaned relative path climbs above its root.
+func leavesRoot(rel string) bool {
+	return rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
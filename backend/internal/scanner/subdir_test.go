@@ -0,0 +1,103 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestScanRepository_SubdirConfinesFindings(t *testing.T) {
+	repoPath := t.TempDir()
+	scanPath := filepath.Join(repoPath, "services", "api")
+	if err := os.MkdirAll(scanPath, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	secret := func(file string) string {
+		return `{"SourceMetadata":{"Data":{"Filesystem":{"file":"` + file + `","line":3}}},"DetectorName":"Generic","Verified":true,"Raw":"q8Vz2LmN4rT7wX1c"}`
+	}
+	exec := &fakeExecutor{outputs: map[string][]byte{
+		"trufflehog filesystem --json " + scanPath: []byte(strings.Join([]string{
+			secret(filepath.Join(scanPath, "config", "stripe.go")),
+			secret(filepath.Join(repoPath, "web", "app.js")),
+			secret("handlers/pay.go"),
+			secret("../../web/leak.js"),
+		}, "\n")),
+	}}
+
+	db := &recordingDB{}
+	s := NewService(nil, nil, "", WithServiceToolRunner(NewToolRunner(WithExecutor(exec))))
+	s.db = db
+
+	s.scanRepository(context.Background(), "job-1", repoPath, "services/api", nil, time.Now())
+
+	for _, call := range exec.calls {
+		if strings.HasSuffix(call, " "+repoPath) {
+			t.Errorf("tool ran against the repository root: %q", call)
+		}
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	var paths []string
+	for _, row := range db.insertedFindings() {
+		paths = append(paths, row[4].(string))
+	}
+	slices.Sort(paths)
+	want := []string{"config/stripe.go", "handlers/pay.go"}
+	if !slices.Equal(paths, want) {
+		t.Errorf("stored finding paths = %v, want %v", paths, want)
+	}
+}
+
+func TestResolveScanPath(t *testing.T) {
+	repoPath := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(repoPath, "services", "api"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repoPath, "README.md"), []byte("# repo"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	outside := t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(repoPath, "escape")); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := resolveScanPath(repoPath, ""); err != nil || got != repoPath {
+		t.Errorf("resolveScanPath(root) = %q, %v; want %q", got, err, repoPath)
+	}
+	if got, err := resolveScanPath(repoPath, "services/api"); err != nil || got != filepath.Join(repoPath, "services", "api") {
+		t.Errorf("resolveScanPath(services/api) = %q, %v", got, err)
+	}
+	if _, err := resolveScanPath(repoPath, "missing"); !errors.Is(err, ErrSubdirNotFound) {
+		t.Errorf("missing subdir error = %v, want ErrSubdirNotFound", err)
+	}
+	if _, err := resolveScanPath(repoPath, "README.md"); !errors.Is(err, ErrSubdirNotFound) {
+		t.Errorf("file subdir error = %v, want ErrSubdirNotFound", err)
+	}
+	if _, err := resolveScanPath(repoPath, "escape"); !errors.Is(err, ErrSubdirEscapesRepo) {
+		t.Errorf("symlinked subdir error = %v, want ErrSubdirEscapesRepo", err)
+	}
+}
+
+func TestStartScan_RejectsSubdirTraversal(t *testing.T) {
+	s := NewService(nil, nil, "")
+
+	_, err := s.StartScan(context.Background(), ScanRequest{
+		RepoURL: "https://github.com/owner/repo",
+		Subdir:  "../../etc",
+	})
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || validationErr.Code != "INVALID_SUBDIR" {
+		t.Fatalf("StartScan() error = %v, want INVALID_SUBDIR validation error", err)
+	}
+	if len(s.active) != 0 {
+		t.Error("expected no scan to be tracked for a rejected subdir")
+	}
+}
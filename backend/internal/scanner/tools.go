@@ -4,10 +4,16 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -16,9 +22,34 @@ const (
 	DefaultToolTimeout = 5 * time.Minute
 )
 
+// ErrToolReport is returned when a tool's report file cannot be read, so a
+// missing report is never mistaken for a clean result.
+var ErrToolReport = errors.New("tool report unavailable")
+
+// Report files are read with a second attempt after reportRetryDelay, since a
+// docker exec can fail transiently.
+const reportReadAttempts = 2
+
+var reportRetryDelay = 500 * time.Millisecond
+
 // ToolRunner executes security scanning tools.
 type ToolRunner struct {
-	timeout time.Duration
+	timeout             time.Duration
+	toolTimeouts        map[string]time.Duration
+	verifiedSecretsOnly bool
+	executor            Executor
+
+	// Operator tool selection: when enabled is non-nil only those tools run,
+	// and disabled tools never run
+	enabled  map[string]bool
+	disabled map[string]bool
+
+	// slots bounds tool processes running at once across all scans; nil means unbounded
+	slots chan struct{}
+
+	// unavailable holds tools that failed the last self-test
+	mu          sync.RWMutex
+	unavailable map[string]error
 }
 
 // ToolRunnerOption is a functional option for configuring a ToolRunner.
@@ -31,10 +62,66 @@ func WithToolTimeout(timeout time.Duration) ToolRunnerOption {
 	}
 }
 
+// WithToolTimeouts sets per-tool timeout overrides keyed by tool name.
+// Tools without an override use the default timeout.
+func WithToolTimeouts(timeouts map[string]time.Duration) ToolRunnerOption {
+	return func(r *ToolRunner) {
+		r.toolTimeouts = timeouts
+	}
+}
+
+// WithVerifiedSecretsOnly drops TruffleHog secrets that could not be verified.
+func WithVerifiedSecretsOnly(enabled bool) ToolRunnerOption {
+	return func(r *ToolRunner) {
+		r.verifiedSecretsOnly = enabled
+	}
+}
+
+// WithExecutor sets the executor used to run tools.
+func WithExecutor(e Executor) ToolRunnerOption {
+	return func(r *ToolRunner) {
+		if e != nil {
+			r.executor = e
+		}
+	}
+}
+
+// WithToolSelection restricts which tools may run. A non-empty enabled list
+// runs only the named tools; disabled tools never run, even when enabled.
+// Tools still only run for repositories they apply to.
+func WithToolSelection(enabled, disabled []string) ToolRunnerOption {
+	return func(r *ToolRunner) {
+		r.enabled = nil
+		if len(enabled) > 0 {
+			r.enabled = make(map[string]bool, len(enabled))
+			for _, name := range enabled {
+				r.enabled[name] = true
+			}
+		}
+		r.disabled = make(map[string]bool, len(disabled))
+		for _, name := range disabled {
+			r.disabled[name] = true
+		}
+	}
+}
+
+// WithMaxConcurrentTools limits how many tool processes run at once across
+// every scan sharing the runner. Zero or less means no limit.
+func WithMaxConcurrentTools(n int) ToolRunnerOption {
+	return func(r *ToolRunner) {
+		if n > 0 {
+			r.slots = make(chan struct{}, n)
+		} else {
+			r.slots = nil
+		}
+	}
+}
+
 // NewToolRunner creates a new ToolRunner with the given options.
 func NewToolRunner(opts ...ToolRunnerOption) *ToolRunner {
 	r := &ToolRunner{
-		timeout: DefaultToolTimeout,
+		timeout:  DefaultToolTimeout,
+		executor: dockerExecutor{},
 	}
 	for _, opt := range opts {
 		opt(r)
@@ -49,15 +136,29 @@ type ToolResult struct {
 	Error    error         `json:"-"`
 	TimedOut bool          `json:"timed_out"`
 	Duration time.Duration `json:"duration"`
+	Timeout  time.Duration `json:"timeout"` // effective timeout the tool ran with
 }
 
 // RawFinding represents a finding from a security tool before aggregation.
 type RawFinding struct {
 	FilePath    string `json:"file_path"`
 	LineNumber  int    `json:"line_number,omitempty"`
+	EndLine     int    `json:"end_line,omitempty"`     // Optional range from tools that report one
+	StartColumn int    `json:"start_column,omitempty"` // 1-based
+	EndColumn   int    `json:"end_column,omitempty"`   // 1-based
 	Description string `json:"description"`
 	Severity    string `json:"severity"`
 	RuleID      string `json:"rule_id,omitempty"`
+	Confidence  string `json:"confidence,omitempty"`
+	// IsSecret marks a detected credential, whatever the tool, so its
+	// snippet is redacted
+	IsSecret bool `json:"-"`
+	// Secret is the matched value from secret scanners, used only for
+	// allowlisting and snippet redaction and never persisted or returned.
+	Secret string `json:"-"`
+	// Surrounding code set by captureSnippets, and its first line
+	Snippet          string `json:"-"`
+	SnippetStartLine int    `json:"-"`
 }
 
 // scannerContainer is the name of the scanner container for docker exec.
@@ -71,24 +172,33 @@ func SetScannerContainer(name string) {
 	}
 }
 
-// runTool executes a command inside the scanner container with timeout.
+// timeoutFor returns the tool's timeout override, or the default timeout.
+func (r *ToolRunner) timeoutFor(tool string) time.Duration {
+	if d, ok := r.toolTimeouts[tool]; ok && d > 0 {
+		return d
+	}
+	return r.timeout
+}
+
+// runTool executes a command inside the scanner container with the default timeout.
 func (r *ToolRunner) runTool(ctx context.Context, name string, args []string, workDir string) ([]byte, bool, error) {
-	ctx, cancel := context.WithTimeout(ctx, r.timeout)
-	defer cancel()
+	return r.runToolWithTimeout(ctx, r.timeout, name, args, workDir)
+}
 
-	// Build docker exec command to run tool in scanner container
-	dockerArgs := []string{
-		"exec",
-		"-w", workDir,
-		scannerContainer,
-		name,
+// runToolWithTimeout executes a command inside the scanner container with timeout.
+// Waiting for a free slot counts against the caller's context, not the tool timeout.
+func (r *ToolRunner) runToolWithTimeout(ctx context.Context, timeout time.Duration, name string, args []string, workDir string) ([]byte, bool, error) {
+	release, err := r.acquireSlot(ctx)
+	if err != nil {
+		return nil, false, err
 	}
-	dockerArgs = append(dockerArgs, args...)
+	defer release()
 
-	log.Printf("[ToolRunner] Executing: docker %v", dockerArgs)
-	cmd := exec.CommandContext(ctx, "docker", dockerArgs...)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-	output, err := cmd.CombinedOutput()
+	log.Printf("[ToolRunner] Executing: %s %v in %s", name, args, workDir)
+	output, err := r.executor.Exec(ctx, workDir, name, args...)
 
 	log.Printf("[ToolRunner] Tool %s output length: %d bytes, error: %v", name, len(output), err)
 	if len(output) > 0 && len(output) < 500 {
@@ -102,10 +212,24 @@ func (r *ToolRunner) runTool(ctx context.Context, name string, args []string, wo
 	return output, false, err
 }
 
+// acquireSlot blocks until a tool slot is free or ctx is done.
+// The returned func releases the slot.
+func (r *ToolRunner) acquireSlot(ctx context.Context) (func(), error) {
+	if r.slots == nil {
+		return func() {}, nil
+	}
+	select {
+	case r.slots <- struct{}{}:
+		return func() { <-r.slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
 // RunTrivy executes Trivy for comprehensive vulnerability scanning.
 func (r *ToolRunner) RunTrivy(ctx context.Context, repoPath string) ToolResult {
 	start := time.Now()
-	result := ToolResult{Tool: "trivy"}
+	result := ToolResult{Tool: "trivy", Timeout: r.timeoutFor("trivy")}
 
 	args := []string{
 		"fs",
@@ -116,7 +240,7 @@ func (r *ToolRunner) RunTrivy(ctx context.Context, repoPath string) ToolResult {
 		repoPath,
 	}
 
-	output, timedOut, err := r.runTool(ctx, "trivy", args, repoPath)
+	output, timedOut, err := r.runToolWithTimeout(ctx, result.Timeout, "trivy", args, repoPath)
 	result.Duration = time.Since(start)
 	result.TimedOut = timedOut
 
@@ -135,7 +259,7 @@ func (r *ToolRunner) RunTrivy(ctx context.Context, repoPath string) ToolResult {
 // RunSemgrep executes Semgrep with security rulesets.
 func (r *ToolRunner) RunSemgrep(ctx context.Context, repoPath string, languages []string) ToolResult {
 	start := time.Now()
-	result := ToolResult{Tool: "semgrep"}
+	result := ToolResult{Tool: "semgrep", Timeout: r.timeoutFor("semgrep")}
 
 	args := []string{
 		"scan",
@@ -145,7 +269,7 @@ func (r *ToolRunner) RunSemgrep(ctx context.Context, repoPath string, languages
 		repoPath,
 	}
 
-	output, timedOut, err := r.runTool(ctx, "semgrep", args, repoPath)
+	output, timedOut, err := r.runToolWithTimeout(ctx, result.Timeout, "semgrep", args, repoPath)
 	result.Duration = time.Since(start)
 	result.TimedOut = timedOut
 
@@ -163,7 +287,7 @@ func (r *ToolRunner) RunSemgrep(ctx context.Context, repoPath string, languages
 // RunTruffleHog executes TruffleHog for secret detection in git history.
 func (r *ToolRunner) RunTruffleHog(ctx context.Context, repoPath string) ToolResult {
 	start := time.Now()
-	result := ToolResult{Tool: "trufflehog"}
+	result := ToolResult{Tool: "trufflehog", Timeout: r.timeoutFor("trufflehog")}
 
 	args := []string{
 		"filesystem",
@@ -171,7 +295,7 @@ func (r *ToolRunner) RunTruffleHog(ctx context.Context, repoPath string) ToolRes
 		repoPath,
 	}
 
-	output, timedOut, err := r.runTool(ctx, "trufflehog", args, repoPath)
+	output, timedOut, err := r.runToolWithTimeout(ctx, result.Timeout, "trufflehog", args, repoPath)
 	result.Duration = time.Since(start)
 	result.TimedOut = timedOut
 
@@ -183,13 +307,16 @@ func (r *ToolRunner) RunTruffleHog(ctx context.Context, repoPath string) ToolRes
 	_ = err
 
 	result.Findings = parseTruffleHogOutput(output)
+	if r.verifiedSecretsOnly {
+		result.Findings = filterVerifiedSecrets(result.Findings)
+	}
 	return result
 }
 
 // RunGitleaks executes Gitleaks for additional secret detection.
 func (r *ToolRunner) RunGitleaks(ctx context.Context, repoPath string) ToolResult {
 	start := time.Now()
-	result := ToolResult{Tool: "gitleaks"}
+	result := ToolResult{Tool: "gitleaks", Timeout: r.timeoutFor("gitleaks")}
 
 	// Gitleaks writes JSON to report file, use a temp file
 	reportPath := filepath.Join(repoPath, ".gitleaks-report.json")
@@ -202,7 +329,14 @@ func (r *ToolRunner) RunGitleaks(ctx context.Context, repoPath string) ToolResul
 		"--no-git",
 	}
 
-	_, timedOut, err := r.runTool(ctx, "gitleaks", args, repoPath)
+	// Remove the report even when the run times out or the read fails
+	defer func() {
+		if _, err := r.executor.Exec(context.Background(), "", "rm", "-f", reportPath); err != nil {
+			log.Printf("[ToolRunner] gitleaks report cleanup failed for %s: %v", reportPath, err)
+		}
+	}()
+
+	_, timedOut, runErr := r.runToolWithTimeout(ctx, result.Timeout, "gitleaks", args, repoPath)
 	result.Duration = time.Since(start)
 	result.TimedOut = timedOut
 
@@ -210,34 +344,65 @@ func (r *ToolRunner) RunGitleaks(ctx context.Context, repoPath string) ToolResul
 		return result
 	}
 
-	// Gitleaks returns exit code 1 when findings exist, that's expected
-	_ = err
+	output, err := r.readReport(reportPath)
+	if err != nil {
+		log.Printf("[ToolRunner] gitleaks report missing or empty (run error: %v): %v", runErr, err)
+		result.Error = err
+		return result
+	}
 
-	// Read the report file from the scanner container
-	catArgs := []string{"exec", scannerContainer, "cat", reportPath}
-	cmd := exec.Command("docker", catArgs...)
-	output, _ := cmd.Output()
+	// Gitleaks exits 1 when it finds leaks. Any other failure still left a
+	// readable report, so its findings are kept.
+	var exitErr *exec.ExitError
+	if runErr != nil && !(errors.As(runErr, &exitErr) && exitErr.ExitCode() == 1) {
+		log.Printf("[ToolRunner] gitleaks exited with error, using its report: %v", runErr)
+	}
 
-	// Clean up report file
-	rmArgs := []string{"exec", scannerContainer, "rm", "-f", reportPath}
-	rmCmd := exec.Command("docker", rmArgs...)
-	_ = rmCmd.Run()
+	var report gitleaksOutput
+	if err := json.Unmarshal(output, &report); err != nil {
+		result.Error = fmt.Errorf("%w: %s is not valid JSON: %v", ErrToolReport, reportPath, err)
+		return result
+	}
+	if len(report) == 0 {
+		log.Printf("[ToolRunner] gitleaks report has no findings")
+	}
 
 	result.Findings = parseGitleaksOutput(output)
 	return result
 }
 
+// readReport reads a tool's report file from the scanner container. A failed
+// or empty read is retried once before giving up with ErrToolReport.
+func (r *ToolRunner) readReport(path string) ([]byte, error) {
+	var lastErr error
+	for attempt := 1; attempt <= reportReadAttempts; attempt++ {
+		if attempt > 1 {
+			time.Sleep(reportRetryDelay)
+		}
+		output, err := r.executor.Exec(context.Background(), "", "cat", path)
+		switch {
+		case err != nil:
+			lastErr = fmt.Errorf("read failed: %v: %s", err, bytes.TrimSpace(output))
+		case len(bytes.TrimSpace(output)) == 0:
+			lastErr = errors.New("report is empty")
+		default:
+			return output, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s: %v", ErrToolReport, path, lastErr)
+}
+
 // RunGovulncheck executes govulncheck for Go vulnerability scanning.
 func (r *ToolRunner) RunGovulncheck(ctx context.Context, repoPath string) ToolResult {
 	start := time.Now()
-	result := ToolResult{Tool: "govulncheck"}
+	result := ToolResult{Tool: "govulncheck", Timeout: r.timeoutFor("govulncheck")}
 
 	args := []string{
 		"-json",
 		"./...",
 	}
 
-	output, timedOut, err := r.runTool(ctx, "govulncheck", args, repoPath)
+	output, timedOut, err := r.runToolWithTimeout(ctx, result.Timeout, "govulncheck", args, repoPath)
 	result.Duration = time.Since(start)
 	result.TimedOut = timedOut
 
@@ -255,7 +420,7 @@ func (r *ToolRunner) RunGovulncheck(ctx context.Context, repoPath string) ToolRe
 // RunBandit executes Bandit for Python security analysis.
 func (r *ToolRunner) RunBandit(ctx context.Context, repoPath string) ToolResult {
 	start := time.Now()
-	result := ToolResult{Tool: "bandit"}
+	result := ToolResult{Tool: "bandit", Timeout: r.timeoutFor("bandit")}
 
 	args := []string{
 		"-r",
@@ -263,7 +428,7 @@ func (r *ToolRunner) RunBandit(ctx context.Context, repoPath string) ToolResult
 		repoPath,
 	}
 
-	output, timedOut, err := r.runTool(ctx, "bandit", args, repoPath)
+	output, timedOut, err := r.runToolWithTimeout(ctx, result.Timeout, "bandit", args, repoPath)
 	result.Duration = time.Since(start)
 	result.TimedOut = timedOut
 
@@ -281,7 +446,7 @@ func (r *ToolRunner) RunBandit(ctx context.Context, repoPath string) ToolResult
 // RunPipAudit executes pip-audit for Python dependency scanning.
 func (r *ToolRunner) RunPipAudit(ctx context.Context, repoPath string) ToolResult {
 	start := time.Now()
-	result := ToolResult{Tool: "pip-audit"}
+	result := ToolResult{Tool: "pip-audit", Timeout: r.timeoutFor("pip-audit")}
 
 	// Check for requirements.txt or setup.py
 	reqPath := filepath.Join(repoPath, "requirements.txt")
@@ -290,7 +455,7 @@ func (r *ToolRunner) RunPipAudit(ctx context.Context, repoPath string) ToolResul
 		"--format", "json",
 	}
 
-	output, timedOut, err := r.runTool(ctx, "pip-audit", args, repoPath)
+	output, timedOut, err := r.runToolWithTimeout(ctx, result.Timeout, "pip-audit", args, repoPath)
 	result.Duration = time.Since(start)
 	result.TimedOut = timedOut
 
@@ -308,7 +473,7 @@ func (r *ToolRunner) RunPipAudit(ctx context.Context, repoPath string) ToolResul
 // RunSafety executes Safety for Python dependency checking.
 func (r *ToolRunner) RunSafety(ctx context.Context, repoPath string) ToolResult {
 	start := time.Now()
-	result := ToolResult{Tool: "safety"}
+	result := ToolResult{Tool: "safety", Timeout: r.timeoutFor("safety")}
 
 	reqPath := filepath.Join(repoPath, "requirements.txt")
 	args := []string{
@@ -317,7 +482,7 @@ func (r *ToolRunner) RunSafety(ctx context.Context, repoPath string) ToolResult
 		"--json",
 	}
 
-	output, timedOut, err := r.runTool(ctx, "safety", args, repoPath)
+	output, timedOut, err := r.runToolWithTimeout(ctx, result.Timeout, "safety", args, repoPath)
 	result.Duration = time.Since(start)
 	result.TimedOut = timedOut
 
@@ -332,17 +497,57 @@ func (r *ToolRunner) RunSafety(ctx context.Context, repoPath string) ToolResult
 	return result
 }
 
-// RunNpmAudit executes npm audit for JavaScript/TypeScript dependency scanning.
+// JavaScript package managers, selected by the lockfile a repository has.
+const (
+	PackageManagerNpm  = "npm"
+	PackageManagerPnpm = "pnpm"
+	PackageManagerYarn = "yarn"
+)
+
+// detectPackageManager picks the package manager whose lockfile is in
+// repoPath. npm is used when there is no pnpm or yarn lockfile, or when more
+// than one package manager's lockfile is present.
+func detectPackageManager(repoPath string) string {
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(repoPath, name))
+		return err == nil
+	}
+	pnpm := exists("pnpm-lock.yaml")
+	yarn := exists("yarn.lock")
+	npm := exists("package-lock.json") || exists("npm-shrinkwrap.json")
+
+	switch {
+	case pnpm && !yarn && !npm:
+		return PackageManagerPnpm
+	case yarn && !pnpm && !npm:
+		return PackageManagerYarn
+	default:
+		return PackageManagerNpm
+	}
+}
+
+// RunNpmAudit audits JavaScript/TypeScript dependencies with the package
+// manager the repository uses: npm audit, pnpm audit or yarn npm audit.
 func (r *ToolRunner) RunNpmAudit(ctx context.Context, repoPath string) ToolResult {
 	start := time.Now()
-	result := ToolResult{Tool: "npm-audit"}
-
-	args := []string{
-		"audit",
-		"--json",
+	result := ToolResult{Tool: "npm-audit", Timeout: r.timeoutFor("npm-audit")}
+
+	manager := detectPackageManager(repoPath)
+	var name string
+	var args []string
+	var parse func([]byte) ([]RawFinding, bool)
+	switch manager {
+	case PackageManagerPnpm:
+		name, args, parse = "pnpm", []string{"audit", "--json"}, parsePnpmAuditOutput
+	case PackageManagerYarn:
+		// The image ships Yarn 1, whose audit reads classic yarn.lock files
+		name, args, parse = "yarn", []string{"audit", "--json"}, parseYarnAuditOutput
+	default:
+		name, args, parse = "npm", []string{"audit", "--json"}, parseNpmAuditOutput
 	}
+	log.Printf("[ToolRunner] Auditing JavaScript dependencies with %s", manager)
 
-	output, timedOut, err := r.runTool(ctx, "npm", args, repoPath)
+	output, timedOut, err := r.runToolWithTimeout(ctx, result.Timeout, name, args, repoPath)
 	result.Duration = time.Since(start)
 	result.TimedOut = timedOut
 
@@ -350,24 +555,43 @@ func (r *ToolRunner) RunNpmAudit(ctx context.Context, repoPath string) ToolResul
 		return result
 	}
 
-	// npm audit returns non-zero when vulnerabilities found
-	_ = err
-
-	result.Findings = parseNpmAuditOutput(output)
+	// Audits return non-zero when vulnerabilities are found, so only output
+	// that is not an audit report means the audit itself failed
+	findings, ok := parse(output)
+	if !ok {
+		result.Error = fmt.Errorf("%w: %s %s produced no audit report (exit: %v): %s",
+			ErrToolReport, name, strings.Join(args, " "), err, firstLine(output))
+		return result
+	}
+	result.Findings = findings
 	return result
 }
 
+// firstLine returns the first non-blank line of output, shortened for an
+// error message.
+func firstLine(output []byte) string {
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			if len(line) > 200 {
+				line = line[:200] + "..."
+			}
+			return line
+		}
+	}
+	return "no output"
+}
+
 // RunCargoAudit executes cargo audit for Rust dependency scanning.
 func (r *ToolRunner) RunCargoAudit(ctx context.Context, repoPath string) ToolResult {
 	start := time.Now()
-	result := ToolResult{Tool: "cargo-audit"}
+	result := ToolResult{Tool: "cargo-audit", Timeout: r.timeoutFor("cargo-audit")}
 
 	args := []string{
 		"audit",
 		"--json",
 	}
 
-	output, timedOut, err := r.runTool(ctx, "cargo", args, repoPath)
+	output, timedOut, err := r.runToolWithTimeout(ctx, result.Timeout, "cargo", args, repoPath)
 	result.Duration = time.Since(start)
 	result.TimedOut = timedOut
 
@@ -385,14 +609,14 @@ func (r *ToolRunner) RunCargoAudit(ctx context.Context, repoPath string) ToolRes
 // RunBundlerAudit executes bundler-audit for Ruby dependency scanning.
 func (r *ToolRunner) RunBundlerAudit(ctx context.Context, repoPath string) ToolResult {
 	start := time.Now()
-	result := ToolResult{Tool: "bundler-audit"}
+	result := ToolResult{Tool: "bundler-audit", Timeout: r.timeoutFor("bundler-audit")}
 
 	args := []string{
 		"check",
 		"--format", "json",
 	}
 
-	output, timedOut, err := r.runTool(ctx, "bundle-audit", args, repoPath)
+	output, timedOut, err := r.runToolWithTimeout(ctx, result.Timeout, "bundle-audit", args, repoPath)
 	result.Duration = time.Since(start)
 	result.TimedOut = timedOut
 
@@ -410,7 +634,7 @@ func (r *ToolRunner) RunBundlerAudit(ctx context.Context, repoPath string) ToolR
 // RunBrakeman executes Brakeman for Ruby/Rails security scanning.
 func (r *ToolRunner) RunBrakeman(ctx context.Context, repoPath string) ToolResult {
 	start := time.Now()
-	result := ToolResult{Tool: "brakeman"}
+	result := ToolResult{Tool: "brakeman", Timeout: r.timeoutFor("brakeman")}
 
 	args := []string{
 		"-p", repoPath,
@@ -418,7 +642,7 @@ func (r *ToolRunner) RunBrakeman(ctx context.Context, repoPath string) ToolResul
 		"--no-pager",
 	}
 
-	output, timedOut, err := r.runTool(ctx, "brakeman", args, repoPath)
+	output, timedOut, err := r.runToolWithTimeout(ctx, result.Timeout, "brakeman", args, repoPath)
 	result.Duration = time.Since(start)
 	result.TimedOut = timedOut
 
@@ -433,80 +657,6 @@ func (r *ToolRunner) RunBrakeman(ctx context.Context, repoPath string) ToolResul
 	return result
 }
 
-// GetToolsForLanguages returns the list of tools to run for the given languages.
-func (r *ToolRunner) GetToolsForLanguages(languages []Language) []string {
-	tools := []string{
-		// Universal tools (always run)
-		"trivy",
-		"semgrep",
-		"trufflehog",
-		"gitleaks",
-	}
-
-	// Language-specific tools
-	langSet := make(map[Language]bool)
-	for _, lang := range languages {
-		langSet[lang] = true
-	}
-
-	if langSet[LangGo] {
-		tools = append(tools, "govulncheck")
-	}
-
-	if langSet[LangPython] {
-		tools = append(tools, "bandit", "pip-audit", "safety")
-	}
-
-	if langSet[LangJavaScript] || langSet[LangTypeScript] {
-		tools = append(tools, "npm-audit")
-	}
-
-	if langSet[LangRust] {
-		tools = append(tools, "cargo-audit")
-	}
-
-	if langSet[LangRuby] {
-		tools = append(tools, "bundler-audit", "brakeman")
-	}
-
-	return tools
-}
-
-// RunToolByName runs a specific tool by name.
-func (r *ToolRunner) RunToolByName(ctx context.Context, toolName string, repoPath string, languages []Language) ToolResult {
-	switch toolName {
-	case "trivy":
-		return r.RunTrivy(ctx, repoPath)
-	case "semgrep":
-		return r.RunSemgrep(ctx, repoPath, nil)
-	case "trufflehog":
-		return r.RunTruffleHog(ctx, repoPath)
-	case "gitleaks":
-		return r.RunGitleaks(ctx, repoPath)
-	case "govulncheck":
-		return r.RunGovulncheck(ctx, repoPath)
-	case "bandit":
-		return r.RunBandit(ctx, repoPath)
-	case "pip-audit":
-		return r.RunPipAudit(ctx, repoPath)
-	case "safety":
-		return r.RunSafety(ctx, repoPath)
-	case "npm-audit":
-		return r.RunNpmAudit(ctx, repoPath)
-	case "cargo-audit":
-		return r.RunCargoAudit(ctx, repoPath)
-	case "bundler-audit":
-		return r.RunBundlerAudit(ctx, repoPath)
-	case "brakeman":
-		return r.RunBrakeman(ctx, repoPath)
-	default:
-		return ToolResult{
-			Tool:  toolName,
-			Error: ErrCloneFailed, // Using existing error for now
-		}
-	}
-}
-
 // =============================================================================
 // Output Parsers
 // =============================================================================
@@ -529,6 +679,7 @@ type trivyOutput struct {
 			Title     string `json:"Title"`
 			Match     string `json:"Match"`
 			StartLine int    `json:"StartLine"`
+			EndLine   int    `json:"EndLine"`
 		} `json:"Secrets"`
 	} `json:"Results"`
 }
@@ -546,7 +697,7 @@ func parseTrivyOutput(output []byte) []RawFinding {
 			findings = append(findings, RawFinding{
 				FilePath:    r.Target,
 				Description: v.Title + ": " + v.Description,
-				Severity:    strings.ToLower(v.Severity),
+				Severity:    NormalizeSeverity("trivy", v.Severity),
 				RuleID:      v.VulnerabilityID,
 			})
 		}
@@ -554,9 +705,11 @@ func parseTrivyOutput(output []byte) []RawFinding {
 			findings = append(findings, RawFinding{
 				FilePath:    r.Target,
 				LineNumber:  s.StartLine,
+				EndLine:     s.EndLine,
 				Description: s.Title,
-				Severity:    strings.ToLower(s.Severity),
+				Severity:    NormalizeSeverity("trivy", s.Severity),
 				RuleID:      s.RuleID,
+				IsSecret:    true,
 			})
 		}
 	}
@@ -571,10 +724,18 @@ type semgrepOutput struct {
 		Path    string `json:"path"`
 		Start   struct {
 			Line int `json:"line"`
+			Col  int `json:"col"`
 		} `json:"start"`
+		End struct {
+			Line int `json:"line"`
+			Col  int `json:"col"`
+		} `json:"end"`
 		Extra struct {
 			Message  string `json:"message"`
 			Severity string `json:"severity"`
+			Metadata struct {
+				Confidence string `json:"confidence"`
+			} `json:"metadata"`
 		} `json:"extra"`
 	} `json:"results"`
 }
@@ -591,9 +752,13 @@ func parseSemgrepOutput(output []byte) []RawFinding {
 		findings = append(findings, RawFinding{
 			FilePath:    r.Path,
 			LineNumber:  r.Start.Line,
+			EndLine:     r.End.Line,
+			StartColumn: r.Start.Col,
+			EndColumn:   r.End.Col,
 			Description: r.Extra.Message,
-			Severity:    strings.ToLower(r.Extra.Severity),
+			Severity:    NormalizeSeverity("semgrep", r.Extra.Severity),
 			RuleID:      r.CheckID,
+			Confidence:  NormalizeConfidence("semgrep", r.Extra.Metadata.Confidence),
 		})
 	}
 
@@ -620,6 +785,7 @@ func parseTruffleHogOutput(output []byte) []RawFinding {
 				} `json:"Data"`
 			} `json:"SourceMetadata"`
 			DetectorName string `json:"DetectorName"`
+			Verified     bool   `json:"Verified"`
 			Raw          string `json:"Raw"`
 		}
 
@@ -638,24 +804,47 @@ func parseTruffleHogOutput(output []byte) []RawFinding {
 			continue
 		}
 
+		verification := "unverified"
+		if result.Verified {
+			verification = "verified"
+		}
+
 		findings = append(findings, RawFinding{
 			FilePath:    filePath,
 			LineNumber:  result.SourceMetadata.Data.Filesystem.Line,
 			Description: "Secret detected: " + result.DetectorName,
-			Severity:    "high",
+			Severity:    NormalizeSeverity("trufflehog", verification),
 			RuleID:      result.DetectorName,
+			Confidence:  NormalizeConfidence("trufflehog", verification),
+			IsSecret:    true,
+			Secret:      result.Raw,
 		})
 	}
 
 	return findings
 }
 
+// filterVerifiedSecrets keeps only TruffleHog findings whose secret was
+// verified against the live service.
+func filterVerifiedSecrets(findings []RawFinding) []RawFinding {
+	var verified []RawFinding
+	for _, f := range findings {
+		if f.Confidence == ConfidenceHigh {
+			verified = append(verified, f)
+		}
+	}
+	return verified
+}
+
 // gitleaksOutput represents Gitleaks JSON output structure.
 type gitleaksOutput []struct {
 	RuleID      string `json:"RuleID"`
 	Description string `json:"Description"`
 	File        string `json:"File"`
 	StartLine   int    `json:"StartLine"`
+	EndLine     int    `json:"EndLine"`
+	StartColumn int    `json:"StartColumn"`
+	EndColumn   int    `json:"EndColumn"`
 	Secret      string `json:"Secret"`
 }
 
@@ -671,9 +860,14 @@ func parseGitleaksOutput(output []byte) []RawFinding {
 		findings = append(findings, RawFinding{
 			FilePath:    r.File,
 			LineNumber:  r.StartLine,
+			EndLine:     r.EndLine,
+			StartColumn: r.StartColumn,
+			EndColumn:   r.EndColumn,
 			Description: r.Description,
-			Severity:    "high",
+			Severity:    NormalizeSeverity("gitleaks", ""),
 			RuleID:      r.RuleID,
+			IsSecret:    true,
+			Secret:      r.Secret,
 		})
 	}
 
@@ -724,7 +918,7 @@ func parseGovulncheckOutput(output []byte) []RawFinding {
 			FilePath:    filePath,
 			LineNumber:  lineNum,
 			Description: "Go vulnerability: " + result.Finding.OSV,
-			Severity:    "high",
+			Severity:    NormalizeSeverity("govulncheck", ""),
 			RuleID:      result.Finding.OSV,
 		})
 	}
@@ -737,6 +931,7 @@ type banditOutput struct {
 	Results []struct {
 		Filename   string `json:"filename"`
 		LineNumber int    `json:"line_number"`
+		LineRange  []int  `json:"line_range"`
 		IssueText  string `json:"issue_text"`
 		Severity   string `json:"issue_severity"`
 		TestID     string `json:"test_id"`
@@ -759,11 +954,16 @@ func parseBanditOutput(output []byte) []RawFinding {
 	}
 
 	for _, r := range result.Results {
+		endLine := 0
+		if len(r.LineRange) > 0 {
+			endLine = r.LineRange[len(r.LineRange)-1]
+		}
 		findings = append(findings, RawFinding{
 			FilePath:    r.Filename,
 			LineNumber:  r.LineNumber,
+			EndLine:     endLine,
 			Description: r.IssueText,
-			Severity:    strings.ToLower(r.Severity),
+			Severity:    NormalizeSeverity("bandit", r.Severity),
 			RuleID:      r.TestID,
 		})
 	}
@@ -794,7 +994,7 @@ func parsePipAuditOutput(output []byte) []RawFinding {
 			findings = append(findings, RawFinding{
 				FilePath:    "requirements.txt",
 				Description: dep.Name + "@" + dep.Version + ": " + vuln.Description,
-				Severity:    "high",
+				Severity:    NormalizeSeverity("pip-audit", ""),
 				RuleID:      vuln.ID,
 			})
 		}
@@ -823,7 +1023,7 @@ func parseSafetyOutput(output []byte) []RawFinding {
 		findings = append(findings, RawFinding{
 			FilePath:    "requirements.txt",
 			Description: pkgName + ": " + desc,
-			Severity:    "high",
+			Severity:    NormalizeSeverity("safety", ""),
 			RuleID:      vulnID,
 		})
 	}
@@ -831,32 +1031,219 @@ func parseSafetyOutput(output []byte) []RawFinding {
 	return findings
 }
 
-func parseNpmAuditOutput(output []byte) []RawFinding {
+// npmAuditOutput represents npm audit (v7+) JSON output. Each entry's via
+// list mixes advisory objects, for advisories against the package itself,
+// with plain package names it is vulnerable through.
+type npmAuditOutput struct {
+	Vulnerabilities map[string]struct {
+		Name     string            `json:"name"`
+		Severity string            `json:"severity"`
+		Via      []json.RawMessage `json:"via"`
+	} `json:"vulnerabilities"`
+}
+
+// npmAdvisory is an advisory object in an npm audit via list.
+type npmAdvisory struct {
+	Source   int    `json:"source"`
+	Title    string `json:"title"`
+	URL      string `json:"url"`
+	Severity string `json:"severity"`
+	Range    string `json:"range"`
+}
+
+// id returns the advisory's GHSA ID from its URL, or npm's numeric source ID.
+func (a npmAdvisory) id() string {
+	if i := strings.LastIndex(a.URL, "/"); i >= 0 && strings.HasPrefix(a.URL[i+1:], "GHSA-") {
+		return a.URL[i+1:]
+	}
+	if a.Source != 0 {
+		return strconv.Itoa(a.Source)
+	}
+	return ""
+}
+
+// describe formats the advisory as a finding description for pkg.
+func (a npmAdvisory) describe(pkg string) string {
+	desc := pkg + ": " + a.Title
+	if a.Range != "" {
+		desc += " (affects " + a.Range + ")"
+	}
+	if a.URL != "" {
+		desc += " " + a.URL
+	}
+	return desc
+}
+
+// parseNpmAuditOutput reports one finding per advisory against a package.
+// Packages that are only vulnerable through their dependencies get a single
+// finding naming them, since the advisories are reported on those packages.
+func parseNpmAuditOutput(output []byte) ([]RawFinding, bool) {
 	var findings []RawFinding
+	var result npmAuditOutput
 
-	var result struct {
-		Vulnerabilities map[string]struct {
-			Name     string        `json:"name"`
-			Severity string        `json:"severity"`
-			Via      []interface{} `json:"via"`
-		} `json:"vulnerabilities"`
+	// Failures such as a missing lockfile print an error object instead
+	if err := json.Unmarshal(output, &result); err != nil || result.Vulnerabilities == nil {
+		return nil, false
 	}
 
-	if err := json.Unmarshal(output, &result); err != nil {
-		return findings
+	names := make([]string, 0, len(result.Vulnerabilities))
+	for name := range result.Vulnerabilities {
+		names = append(names, name)
 	}
+	sort.Strings(names)
 
-	for _, vuln := range result.Vulnerabilities {
-		desc := "Vulnerability in " + vuln.Name
-		findings = append(findings, RawFinding{
-			FilePath:    "package.json",
-			Description: desc,
-			Severity:    strings.ToLower(vuln.Severity),
-			RuleID:      vuln.Name,
-		})
+	for _, key := range names {
+		vuln := result.Vulnerabilities[key]
+		name := vuln.Name
+		if name == "" {
+			name = key
+		}
+
+		var via []string
+		advisories := 0
+		for _, raw := range vuln.Via {
+			var dependency string
+			if err := json.Unmarshal(raw, &dependency); err == nil {
+				via = append(via, dependency)
+				continue
+			}
+			var advisory npmAdvisory
+			if err := json.Unmarshal(raw, &advisory); err != nil || advisory.Title == "" {
+				continue
+			}
+			advisories++
+
+			severity := advisory.Severity
+			if severity == "" {
+				severity = vuln.Severity
+			}
+			ruleID := advisory.id()
+			if ruleID == "" {
+				ruleID = name
+			}
+			findings = append(findings, RawFinding{
+				FilePath:    "package.json",
+				Description: advisory.describe(name),
+				Severity:    NormalizeSeverity("npm-audit", severity),
+				RuleID:      ruleID,
+			})
+		}
+
+		if advisories == 0 {
+			desc := "Vulnerability in " + name
+			if len(via) > 0 {
+				desc += " via " + strings.Join(via, ", ")
+			}
+			findings = append(findings, RawFinding{
+				FilePath:    "package.json",
+				Description: desc,
+				Severity:    NormalizeSeverity("npm-audit", vuln.Severity),
+				RuleID:      name,
+			})
+		}
 	}
 
-	return findings
+	return findings, true
+}
+
+// legacyAdvisory is an advisory in npm's legacy audit layout, which pnpm
+// audit and Yarn 1's yarn audit still use.
+type legacyAdvisory struct {
+	ID                 int    `json:"id"`
+	GitHubAdvisoryID   string `json:"github_advisory_id"`
+	Title              string `json:"title"`
+	ModuleName         string `json:"module_name"`
+	VulnerableVersions string `json:"vulnerable_versions"`
+	Severity           string `json:"severity"`
+	URL                string `json:"url"`
+}
+
+// finding reports the advisory against package.json.
+func (a legacyAdvisory) finding() RawFinding {
+	advisory := npmAdvisory{Source: a.ID, Title: a.Title, URL: a.URL, Range: a.VulnerableVersions}
+	ruleID := a.GitHubAdvisoryID
+	if ruleID == "" {
+		ruleID = advisory.id()
+	}
+	if ruleID == "" {
+		ruleID = a.ModuleName
+	}
+	return RawFinding{
+		FilePath:    "package.json",
+		Description: advisory.describe(a.ModuleName),
+		Severity:    NormalizeSeverity("npm-audit", a.Severity),
+		RuleID:      ruleID,
+	}
+}
+
+// pnpmAuditOutput represents pnpm audit JSON output.
+type pnpmAuditOutput struct {
+	Advisories map[string]legacyAdvisory `json:"advisories"`
+}
+
+// parsePnpmAuditOutput reports one finding per advisory.
+func parsePnpmAuditOutput(output []byte) ([]RawFinding, bool) {
+	var findings []RawFinding
+	var result pnpmAuditOutput
+
+	if err := json.Unmarshal(output, &result); err != nil || result.Advisories == nil {
+		return nil, false
+	}
+
+	keys := make([]string, 0, len(result.Advisories))
+	for key := range result.Advisories {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		findings = append(findings, result.Advisories[key].finding())
+	}
+
+	return findings, true
+}
+
+// yarnAuditLine is one line of Yarn 1's yarn audit --json output. Advisory
+// lines carry an advisory; the last line of a completed audit is a summary.
+type yarnAuditLine struct {
+	Type string `json:"type"`
+	Data struct {
+		Advisory legacyAdvisory `json:"advisory"`
+	} `json:"data"`
+}
+
+// parseYarnAuditOutput reports one finding per advisory line, once per
+// advisory even when several dependency paths lead to it.
+func parseYarnAuditOutput(output []byte) ([]RawFinding, bool) {
+	var findings []RawFinding
+	seen := make(map[int]bool)
+	complete := false
+
+	for _, line := range strings.Split(string(output), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		var entry yarnAuditLine
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			continue
+		}
+		switch entry.Type {
+		case "auditSummary":
+			complete = true
+		case "auditAdvisory":
+			advisory := entry.Data.Advisory
+			if advisory.Title == "" || (advisory.ID != 0 && seen[advisory.ID]) {
+				continue
+			}
+			seen[advisory.ID] = true
+			findings = append(findings, advisory.finding())
+		}
+	}
+
+	if !complete {
+		return nil, false
+	}
+	return findings, true
 }
 
 func parseCargoAuditOutput(output []byte) []RawFinding {
@@ -886,7 +1273,7 @@ func parseCargoAuditOutput(output []byte) []RawFinding {
 		findings = append(findings, RawFinding{
 			FilePath:    "Cargo.toml",
 			Description: vuln.Package.Name + "@" + vuln.Package.Version + ": " + vuln.Advisory.Title,
-			Severity:    "high",
+			Severity:    NormalizeSeverity("cargo-audit", ""),
 			RuleID:      vuln.Advisory.ID,
 		})
 	}
@@ -918,7 +1305,7 @@ func parseBundlerAuditOutput(output []byte) []RawFinding {
 		findings = append(findings, RawFinding{
 			FilePath:    "Gemfile.lock",
 			Description: r.Gem.Name + "@" + r.Gem.Version + ": " + r.Advisory.Title,
-			Severity:    "high",
+			Severity:    NormalizeSeverity("bundler-audit", ""),
 			RuleID:      r.Advisory.ID,
 		})
 	}
@@ -946,22 +1333,13 @@ func parseBrakemanOutput(output []byte) []RawFinding {
 	}
 
 	for _, w := range result.Warnings {
-		var severity string
-		switch w.Confidence {
-		case "High":
-			severity = "high"
-		case "Weak":
-			severity = "low"
-		default:
-			severity = "medium"
-		}
-
 		findings = append(findings, RawFinding{
 			FilePath:    w.File,
 			LineNumber:  w.Line,
 			Description: w.WarningType + ": " + w.Message,
-			Severity:    severity,
+			Severity:    NormalizeSeverity("brakeman", w.Confidence),
 			RuleID:      w.WarningType,
+			Confidence:  NormalizeConfidence("brakeman", w.Confidence),
 		})
 	}
 
@@ -2,7 +2,12 @@ package scanner
 
 import (
 	"context"
+	"errors"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -119,6 +124,328 @@ func TestToolRunner_RunToolByName_UnknownTool(t *testing.T) {
 	}
 }
 
+const (
+	gitleaksReportPath = "/repo/.gitleaks-report.json"
+	gitleaksReadCmd    = "cat " + gitleaksReportPath
+	gitleaksCleanupCmd = "rm -f " + gitleaksReportPath
+)
+
+func runGitleaksWith(t *testing.T, exec *fakeExecutor) ToolResult {
+	t.Helper()
+	delay := reportRetryDelay
+	reportRetryDelay = 0
+	t.Cleanup(func() { reportRetryDelay = delay })
+
+	result := NewToolRunner(WithExecutor(exec)).RunGitleaks(context.Background(), "/repo")
+	if !slices.Contains(exec.calls, gitleaksCleanupCmd) {
+		t.Errorf("report was not cleaned up; calls = %v", exec.calls)
+	}
+	return result
+}
+
+func countCalls(calls []string, cmd string) int {
+	n := 0
+	for _, c := range calls {
+		if c == cmd {
+			n++
+		}
+	}
+	return n
+}
+
+func TestRunGitleaks_ReportReadFailureIsAnError(t *testing.T) {
+	exec := &fakeExecutor{
+		outputs: map[string][]byte{gitleaksReadCmd: []byte("cat: " + gitleaksReportPath + ": No such file or directory")},
+		errs:    map[string]error{gitleaksReadCmd: errors.New("exit status 1")},
+	}
+
+	result := runGitleaksWith(t, exec)
+
+	if !errors.Is(result.Error, ErrToolReport) {
+		t.Errorf("Error = %v, want ErrToolReport", result.Error)
+	}
+	if len(result.Findings) != 0 {
+		t.Errorf("Findings = %v, want none", result.Findings)
+	}
+	if got := countCalls(exec.calls, gitleaksReadCmd); got != 2 {
+		t.Errorf("report read %d times, want 2 (one retry)", got)
+	}
+}
+
+func TestRunGitleaks_EmptyReportIsAnError(t *testing.T) {
+	exec := &fakeExecutor{outputs: map[string][]byte{gitleaksReadCmd: []byte("  \n")}}
+
+	result := runGitleaksWith(t, exec)
+
+	if !errors.Is(result.Error, ErrToolReport) {
+		t.Errorf("Error = %v, want ErrToolReport", result.Error)
+	}
+}
+
+func TestRunGitleaks_NoFindings(t *testing.T) {
+	exec := &fakeExecutor{outputs: map[string][]byte{gitleaksReadCmd: []byte("[]")}}
+
+	result := runGitleaksWith(t, exec)
+
+	if result.Error != nil {
+		t.Errorf("Error = %v, want nil for an empty findings list", result.Error)
+	}
+	if len(result.Findings) != 0 {
+		t.Errorf("Findings = %v, want none", result.Findings)
+	}
+	if got := countCalls(exec.calls, gitleaksReadCmd); got != 1 {
+		t.Errorf("report read %d times, want 1", got)
+	}
+}
+
+func TestRunGitleaks_KeepsFindingsWhenToolFails(t *testing.T) {
+	report := `[{"Description":"AWS key","File":"/repo/config.go","StartLine":3,"RuleID":"aws-access-token","Secret":"AKIA"}]`
+	exec := &fakeExecutor{
+		outputs: map[string][]byte{gitleaksReadCmd: []byte(report)},
+		errs: map[string]error{
+			"gitleaks detect --source /repo --report-format json --report-path " + gitleaksReportPath + " --no-git": errors.New("exit status 126"),
+		},
+	}
+
+	result := runGitleaksWith(t, exec)
+
+	if result.Error != nil {
+		t.Errorf("Error = %v, want nil when the report is readable", result.Error)
+	}
+	if len(result.Findings) != 1 || result.Findings[0].RuleID != "aws-access-token" {
+		t.Errorf("Findings = %+v, want the reported leak", result.Findings)
+	}
+}
+
+func TestParseNpmAuditOutput_Advisories(t *testing.T) {
+	output := []byte(`{
+  "auditReportVersion": 2,
+  "vulnerabilities": {
+    "express": {
+      "name": "express",
+      "severity": "high",
+      "isDirect": true,
+      "via": ["body-parser"],
+      "effects": [],
+      "range": "<=4.21.0",
+      "nodes": ["node_modules/express"],
+      "fixAvailable": true
+    },
+    "body-parser": {
+      "name": "body-parser",
+      "severity": "high",
+      "isDirect": false,
+      "via": [
+        {
+          "source": 1099520,
+          "name": "body-parser",
+          "dependency": "body-parser",
+          "title": "body-parser vulnerable to denial of service when url encoding is enabled",
+          "url": "https://github.com/advisories/GHSA-qwcr-r2fm-qrc7",
+          "severity": "high",
+          "cwe": ["CWE-405"],
+          "cvss": {"score": 7.5, "vectorString": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H"},
+          "range": "<1.20.3"
+        },
+        "qs"
+      ],
+      "effects": ["express"],
+      "range": "<1.20.3",
+      "nodes": ["node_modules/body-parser"],
+      "fixAvailable": true
+    }
+  },
+  "metadata": {"vulnerabilities": {"high": 2, "total": 2}}
+}`)
+
+	findings, ok := parseNpmAuditOutput(output)
+	if !ok {
+		t.Fatal("parseNpmAuditOutput() did not recognize the report")
+	}
+
+	if len(findings) != 2 {
+		t.Fatalf("got %d findings, want 2: %+v", len(findings), findings)
+	}
+
+	advisory := findings[0]
+	if !strings.Contains(advisory.Description, "denial of service when url encoding is enabled") {
+		t.Errorf("Description = %q, want the advisory title", advisory.Description)
+	}
+	if !strings.Contains(advisory.Description, "https://github.com/advisories/GHSA-qwcr-r2fm-qrc7") {
+		t.Errorf("Description = %q, want the advisory URL", advisory.Description)
+	}
+	if advisory.RuleID != "GHSA-qwcr-r2fm-qrc7" {
+		t.Errorf("RuleID = %q, want the advisory ID", advisory.RuleID)
+	}
+	if advisory.Severity != SeverityHigh {
+		t.Errorf("Severity = %q, want %q", advisory.Severity, SeverityHigh)
+	}
+
+	transitive := findings[1]
+	if transitive.RuleID != "express" || !strings.Contains(transitive.Description, "via body-parser") {
+		t.Errorf("transitive finding = %+v, want express via body-parser", transitive)
+	}
+}
+
+func TestDetectPackageManager(t *testing.T) {
+	tests := []struct {
+		name      string
+		lockfiles []string
+		want      string
+	}{
+		{"no lockfile", nil, PackageManagerNpm},
+		{"npm", []string{"package-lock.json"}, PackageManagerNpm},
+		{"pnpm", []string{"pnpm-lock.yaml"}, PackageManagerPnpm},
+		{"yarn", []string{"yarn.lock"}, PackageManagerYarn},
+		{"pnpm and yarn", []string{"pnpm-lock.yaml", "yarn.lock"}, PackageManagerNpm},
+		{"pnpm and npm", []string{"pnpm-lock.yaml", "package-lock.json"}, PackageManagerNpm},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repoPath := t.TempDir()
+			for _, name := range tt.lockfiles {
+				if err := os.WriteFile(filepath.Join(repoPath, name), nil, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := detectPackageManager(repoPath); got != tt.want {
+				t.Errorf("detectPackageManager() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunNpmAudit_PnpmLockfileRunsPnpmAudit(t *testing.T) {
+	repoPath := t.TempDir()
+	if err := os.WriteFile(filepath.Join(repoPath, "pnpm-lock.yaml"), []byte("lockfileVersion: '9.0'\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	report := `{
+  "actions": [],
+  "advisories": {
+    "1097142": {
+      "id": 1097142,
+      "github_advisory_id": "GHSA-c2qf-rxjj-qqgw",
+      "title": "semver vulnerable to Regular Expression Denial of Service",
+      "module_name": "semver",
+      "vulnerable_versions": ">=7.0.0 <7.5.2",
+      "severity": "moderate",
+      "url": "https://github.com/advisories/GHSA-c2qf-rxjj-qqgw",
+      "findings": [{"version": "7.5.1", "paths": [".>semver"]}]
+    }
+  },
+  "metadata": {"vulnerabilities": {"moderate": 1}}
+}`
+	exec := &fakeExecutor{outputs: map[string][]byte{"pnpm audit --json": []byte(report)}}
+	runner := NewToolRunner(WithExecutor(exec))
+
+	result := runner.RunNpmAudit(context.Background(), repoPath)
+
+	if !slices.Equal(exec.calls, []string{"pnpm audit --json"}) {
+		t.Errorf("calls = %v, want only pnpm audit", exec.calls)
+	}
+	if len(result.Findings) != 1 {
+		t.Fatalf("got %d findings, want 1: %+v", len(result.Findings), result.Findings)
+	}
+	f := result.Findings[0]
+	if f.RuleID != "GHSA-c2qf-rxjj-qqgw" || f.Severity != SeverityMedium {
+		t.Errorf("finding = %+v, want GHSA-c2qf-rxjj-qqgw at medium", f)
+	}
+	if !strings.Contains(f.Description, "Regular Expression Denial of Service") {
+		t.Errorf("Description = %q, want the advisory title", f.Description)
+	}
+}
+
+func TestParseYarnAuditOutput(t *testing.T) {
+	output := []byte(`{"type":"auditAdvisory","data":{"resolution":{"id":1106913,"path":"lodash","dev":false,"optional":false,"bundled":false},"advisory":{"id":1106913,"github_advisory_id":"GHSA-35jh-r3h4-6jhm","title":"Command Injection in lodash","module_name":"lodash","vulnerable_versions":"<4.17.21","severity":"high","url":"https://github.com/advisories/GHSA-35jh-r3h4-6jhm"}}}
+{"type":"auditAdvisory","data":{"resolution":{"id":1106913,"path":"express>lodash","dev":false,"optional":false,"bundled":false},"advisory":{"id":1106913,"github_advisory_id":"GHSA-35jh-r3h4-6jhm","title":"Command Injection in lodash","module_name":"lodash","vulnerable_versions":"<4.17.21","severity":"high","url":"https://github.com/advisories/GHSA-35jh-r3h4-6jhm"}}}
+{"type":"auditAdvisory","data":{"resolution":{"id":1097678,"path":"mkdirp>minimist","dev":false,"optional":false,"bundled":false},"advisory":{"id":1097678,"title":"Prototype Pollution in minimist","module_name":"minimist","vulnerable_versions":"<1.2.6","severity":"critical","url":"https://npmjs.com/advisories/1097678"}}}
+{"type":"auditSummary","data":{"vulnerabilities":{"info":0,"low":0,"moderate":0,"high":2,"critical":1},"dependencies":42,"devDependencies":0,"optionalDependencies":0,"totalDependencies":42}}
+`)
+
+	findings, ok := parseYarnAuditOutput(output)
+	if !ok {
+		t.Fatal("parseYarnAuditOutput() did not recognize the report")
+	}
+
+	// The lodash advisory is reached through two paths but reported once
+	if len(findings) != 2 {
+		t.Fatalf("got %d findings, want 2: %+v", len(findings), findings)
+	}
+	if findings[0].RuleID != "GHSA-35jh-r3h4-6jhm" || !strings.Contains(findings[0].Description, "Command Injection in lodash") {
+		t.Errorf("first finding = %+v, want the lodash advisory", findings[0])
+	}
+	if findings[1].RuleID != "1097678" || findings[1].Severity != SeverityCritical {
+		t.Errorf("second finding = %+v, want advisory 1097678 at critical", findings[1])
+	}
+}
+
+func TestRunNpmAudit_FailedAuditIsAnError(t *testing.T) {
+	tests := []struct {
+		name     string
+		lockfile string
+		command  string
+		output   string
+	}{
+		{"npm without lockfile", "package-lock.json", "npm audit --json", `{"error":{"code":"ENOLOCK","summary":"This command requires an existing lockfile."}}`},
+		{"yarn berry lockfile", "yarn.lock", "yarn audit --json", `{"type":"error","data":"An unexpected error occurred: \"Unknown lockfile version\"."}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repoPath := t.TempDir()
+			if err := os.WriteFile(filepath.Join(repoPath, tt.lockfile), []byte("{}\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			exec := &fakeExecutor{
+				outputs: map[string][]byte{tt.command: []byte(tt.output)},
+				errs:    map[string]error{tt.command: errors.New("exit status 1")},
+			}
+
+			result := NewToolRunner(WithExecutor(exec)).RunNpmAudit(context.Background(), repoPath)
+
+			if !slices.Equal(exec.calls, []string{tt.command}) {
+				t.Errorf("calls = %v, want %q", exec.calls, tt.command)
+			}
+			if !errors.Is(result.Error, ErrToolReport) {
+				t.Errorf("Error = %v, want ErrToolReport", result.Error)
+			}
+			if len(result.Findings) != 0 {
+				t.Errorf("Findings = %+v, want none", result.Findings)
+			}
+		})
+	}
+}
+
+func TestParseSemgrepOutput_Range(t *testing.T) {
+	output := []byte(`{
+  "results": [
+    {
+      "check_id": "go.lang.security.audit.database.string-formatted-query",
+      "path": "internal/db/users.go",
+      "start": {"line": 42, "col": 9, "offset": 1180},
+      "end": {"line": 44, "col": 31, "offset": 1262},
+      "extra": {
+        "message": "String-formatted SQL query",
+        "severity": "WARNING",
+        "metadata": {"confidence": "LOW"}
+      }
+    }
+  ],
+  "errors": []
+}`)
+
+	findings := parseSemgrepOutput(output)
+
+	if len(findings) != 1 {
+		t.Fatalf("got %d findings, want 1", len(findings))
+	}
+	f := findings[0]
+	if f.LineNumber != 42 || f.EndLine != 44 || f.StartColumn != 9 || f.EndColumn != 31 {
+		t.Errorf("range = %d:%d-%d:%d, want 42:9-44:31", f.LineNumber, f.StartColumn, f.EndLine, f.EndColumn)
+	}
+}
+
 // =============================================================================
 // Property-Based Tests for Tool Timeout
 // =============================================================================
@@ -313,3 +640,93 @@ func TestProperty7_ToolTimeoutEnforcement_EdgeCases(t *testing.T) {
 		}
 	})
 }
+
+func TestToolRunner_PerToolTimeouts(t *testing.T) {
+	exec := &fakeExecutor{delays: map[string]time.Duration{
+		"trufflehog":  200 * time.Millisecond,
+		"govulncheck": 200 * time.Millisecond,
+	}}
+	r := NewToolRunner(
+		WithExecutor(exec),
+		WithToolTimeout(2*time.Second),
+		WithToolTimeouts(map[string]time.Duration{"govulncheck": 50 * time.Millisecond}),
+	)
+
+	short := r.RunToolByName(context.Background(), "govulncheck", "/repo", nil)
+	if !short.TimedOut {
+		t.Error("expected govulncheck to time out with its short override")
+	}
+	if short.Timeout != 50*time.Millisecond {
+		t.Errorf("govulncheck timeout = %v, want 50ms", short.Timeout)
+	}
+
+	def := r.RunToolByName(context.Background(), "trufflehog", "/repo", nil)
+	if def.TimedOut {
+		t.Error("expected trufflehog to complete within the default timeout")
+	}
+	if def.Timeout != 2*time.Second {
+		t.Errorf("trufflehog timeout = %v, want default 2s", def.Timeout)
+	}
+}
+
+func TestToolRunner_MaxConcurrentTools(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit int
+		want  int
+	}{
+		{"limit of one serializes tools", 1, 1},
+		{"no limit lets tools overlap", 0, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec := &fakeExecutor{delays: map[string]time.Duration{
+				"trufflehog":  100 * time.Millisecond,
+				"govulncheck": 100 * time.Millisecond,
+			}}
+			r := NewToolRunner(WithExecutor(exec), WithMaxConcurrentTools(tt.limit))
+
+			// Two scans sharing the runner each start a tool at the same time
+			var wg sync.WaitGroup
+			for _, tool := range []string{"trufflehog", "govulncheck"} {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					if res := r.RunToolByName(context.Background(), tool, "/repo", nil); res.TimedOut {
+						t.Errorf("%s timed out", tool)
+					}
+				}()
+			}
+			wg.Wait()
+
+			if exec.maxInFlight != tt.want {
+				t.Errorf("max tools running at once = %d, want %d", exec.maxInFlight, tt.want)
+			}
+		})
+	}
+}
+
+func TestToolRunner_MaxConcurrentTools_WaitHonorsContext(t *testing.T) {
+	exec := &fakeExecutor{delays: map[string]time.Duration{"trufflehog": time.Second}}
+	r := NewToolRunner(WithExecutor(exec), WithMaxConcurrentTools(1))
+
+	started := make(chan struct{})
+	go func() {
+		close(started)
+		r.RunToolByName(context.Background(), "trufflehog", "/repo", nil)
+	}()
+	<-started
+	// Give the first tool time to take the only slot
+	time.Sleep(20 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, _, err := r.runTool(ctx, "govulncheck", nil, "/repo"); err != context.DeadlineExceeded {
+		t.Errorf("err = %v, want context.DeadlineExceeded while waiting for a slot", err)
+	}
+	exec.mu.Lock()
+	defer exec.mu.Unlock()
+	if len(exec.calls) != 1 {
+		t.Errorf("expected only the first tool to exec, got %v", exec.calls)
+	}
+}
@@ -4,6 +4,7 @@ package scanner
 import (
 	"errors"
 	"fmt"
+	"path"
 	"regexp"
 	"strings"
 )
@@ -17,6 +18,7 @@ var (
 	ErrMissingRepo        = errors.New("repository URL missing repository name")
 	ErrInvalidOwnerFormat = errors.New("invalid owner format")
 	ErrInvalidRepoFormat  = errors.New("invalid repository name format")
+	ErrSubdirEscapesRepo  = errors.New("subdirectory must stay inside the repository")
 )
 
 // ValidationError provides structured information about URL validation failures.
@@ -138,15 +140,54 @@ func ParseGitHubURL(url string) (owner, repo string, err *ValidationError) {
 	return matches[1], matches[2], nil
 }
 
-// NormalizeGitHubURL normalizes a GitHub URL to a consistent format.
-// Removes trailing .git suffix and trailing slashes.
+// githubHostPrefixes are the spellings of the GitHub host that
+// NormalizeGitHubURL rewrites to https://github.com/.
+var githubHostPrefixes = []string{
+	"https://www.github.com/",
+	"http://www.github.com/",
+	"http://github.com/",
+	"www.github.com/",
+	"github.com/",
+}
+
+// NormalizeGitHubURL normalizes a GitHub URL to a canonical form, so that
+// equivalent URLs for the same repository compare equal. It trims whitespace,
+// rewrites http, www. and scheme-less GitHub hosts to https://github.com/,
+// lowercases the URL (GitHub owner and repository names are case-insensitive),
+// and removes a trailing .git suffix and trailing slashes. Other URLs are
+// returned trimmed and lowercased, and still fail validation.
 func NormalizeGitHubURL(url string) string {
-	url = strings.TrimSpace(url)
-	url = strings.TrimSuffix(url, "/")
+	url = strings.ToLower(strings.TrimSpace(url))
+	for _, prefix := range githubHostPrefixes {
+		if rest, ok := strings.CutPrefix(url, prefix); ok {
+			url = "https://github.com/" + rest
+			break
+		}
+	}
+	url = strings.TrimRight(url, "/")
 	url = strings.TrimSuffix(url, ".git")
 	return url
 }
 
+// NormalizeSubdir validates a repository subdirectory to scan and returns it
+// in clean, slash-separated form relative to the repository root. Leading
+// slashes are dropped, and an empty result means the whole repository.
+// Any ".." element is rejected so the scan cannot leave the clone.
+func NormalizeSubdir(subdir string) (string, *ValidationError) {
+	subdir = strings.TrimSpace(subdir)
+	for _, elem := range strings.Split(strings.ReplaceAll(subdir, "\\", "/"), "/") {
+		if elem == ".." {
+			return "", &ValidationError{
+				Code:    "INVALID_SUBDIR",
+				Message: ErrSubdirEscapesRepo.Error(),
+				Field:   "subdir",
+				Example: "services/api",
+			}
+		}
+	}
+	return strings.TrimPrefix(path.Clean("/"+subdir), "/"), nil
+}
+
 // IsValidGitHubURL returns true if the URL is a valid GitHub repository URL.
 func IsValidGitHubURL(url string) bool {
 	return ValidateGitHubURL(url) == nil
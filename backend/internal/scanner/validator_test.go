@@ -233,6 +233,74 @@ func TestNormalizeGitHubURL(t *testing.T) {
 	}
 }
 
+func TestNormalizeGitHubURL_VariantsCollapse(t *testing.T) {
+	const canonical = "https://github.com/owner/repo"
+	variants := []string{
+		"https://github.com/owner/repo",
+		"https://github.com/owner/repo.git",
+		"https://github.com/owner/repo/",
+		"https://github.com/owner/repo.git/",
+		"https://github.com/owner/repo//",
+		"http://github.com/owner/repo",
+		"https://www.github.com/owner/repo",
+		"http://www.github.com/owner/repo.git",
+		"github.com/owner/repo",
+		"www.github.com/owner/repo/",
+		"HTTPS://GitHub.com/Owner/Repo.git",
+		"  https://github.com/owner/repo  ",
+	}
+
+	for _, v := range variants {
+		got := NormalizeGitHubURL(v)
+		if got != canonical {
+			t.Errorf("NormalizeGitHubURL(%q) = %q, want %q", v, got, canonical)
+		}
+		if err := ValidateGitHubURL(got); err != nil {
+			t.Errorf("canonical form of %q failed validation: %v", v, err)
+		}
+	}
+
+	// Other hosts are not rewritten, so they still fail validation
+	if err := ValidateGitHubURL(NormalizeGitHubURL("http://gitlab.com/owner/repo")); err == nil {
+		t.Error("expected a non-GitHub URL to stay invalid after normalization")
+	}
+}
+
+func TestNormalizeSubdir(t *testing.T) {
+	valid := map[string]string{
+		"":                "",
+		"  ":              "",
+		"/":               "",
+		".":               "",
+		"services/api":    "services/api",
+		"/services/api/":  "services/api",
+		"./services//api": "services/api",
+		"services/./api":  "services/api",
+		"..hidden":        "..hidden",
+	}
+	for in, want := range valid {
+		got, err := NormalizeSubdir(in)
+		if err != nil {
+			t.Errorf("NormalizeSubdir(%q) error = %v", in, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("NormalizeSubdir(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	for _, in := range []string{"..", "../other", "services/../../etc", "a/b/..", `..\windows`} {
+		_, err := NormalizeSubdir(in)
+		if err == nil {
+			t.Errorf("NormalizeSubdir(%q) expected traversal to be rejected", in)
+			continue
+		}
+		if err.Code != "INVALID_SUBDIR" || err.Field != "subdir" {
+			t.Errorf("NormalizeSubdir(%q) error = %+v, want INVALID_SUBDIR on subdir", in, err)
+		}
+	}
+}
+
 func TestIsValidGitHubURL(t *testing.T) {
 	tests := []struct {
 		url  string
@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// ArchivePolicy selects generations for the retention sweep: those created
+// before OlderThan with fewer than MinViews views and fewer than MinRatings
+// ratings. A zero minimum means that count never exempts a generation.
+type ArchivePolicy struct {
+	OlderThan  time.Time
+	MinViews   int
+	MinRatings int
+	// Delete drops matching generations instead of moving them to
+	// generations_archive. Their ratings, views and creators go either way.
+	Delete bool
+}
+
+// matches reports whether gen is old and unpopular enough to archive.
+func (p ArchivePolicy) matches(gen *Generation) bool {
+	if !gen.CreatedAt.Before(p.OlderThan) {
+		return false
+	}
+	if p.MinViews > 0 && gen.ViewCount >= p.MinViews {
+		return false
+	}
+	if p.MinRatings > 0 && gen.RatingCount >= p.MinRatings {
+		return false
+	}
+	return true
+}
+
+// archiveWhere is the SQL form of ArchivePolicy.matches over $1-$3.
+const archiveWhere = `created_at < $1 AND ($2 = 0 OR view_count < $2) AND ($3 = 0 OR rating_count < $3)`
+
+// ArchiveGenerations moves every generation matching policy to
+// generations_archive, or deletes it when policy.Delete is set, and returns
+// how many were removed from the gallery. Each run is a single statement, so
+// a generation is never both live and archived. Like exports it is not bound
+// by the per-query timeout.
+func (r *PostgresRepository) ArchiveGenerations(ctx context.Context, policy ArchivePolicy) (int, error) {
+	query := `
+		WITH archived AS (
+			DELETE FROM generations WHERE ` + archiveWhere + `
+			RETURNING id, project_idea, experience_level, hook_preset, files, category_id, visibility,
+			          short_code, answers, content_hash, avg_rating, rating_count, view_count, created_at
+		)
+		INSERT INTO generations_archive (id, project_idea, experience_level, hook_preset, files, category_id, visibility,
+		                                 short_code, answers, content_hash, avg_rating, rating_count, view_count, created_at)
+		SELECT * FROM archived`
+	if policy.Delete {
+		query = `DELETE FROM generations WHERE ` + archiveWhere
+	}
+
+	result, err := r.execContext(ctx, query, policy.OlderThan, policy.MinViews, policy.MinRatings)
+	if err != nil {
+		return 0, dbError(ctx, err)
+	}
+	removed, err := result.RowsAffected()
+	if err != nil {
+		return 0, dbError(ctx, err)
+	}
+	return int(removed), nil
+}
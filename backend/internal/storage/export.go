@@ -0,0 +1,278 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// maxExportLineBytes bounds a single NDJSON record on import.
+const maxExportLineBytes = 8 << 20
+
+// ExportedGeneration is one line of a gallery export. It carries everything
+// needed to restore a generation, including its stored answers, but nothing
+// derived from client IPs: views and individual ratings are not exported.
+type ExportedGeneration struct {
+	ID              string          `json:"id"`
+	ProjectIdea     string          `json:"projectIdea"`
+	ExperienceLevel string          `json:"experienceLevel"`
+	HookPreset      string          `json:"hookPreset"`
+	Files           json.RawMessage `json:"files"`
+	CategoryID      int             `json:"categoryId"`
+	Visibility      string          `json:"visibility"`
+	ShortCode       string          `json:"shortCode,omitempty"`
+	Answers         json.RawMessage `json:"answers,omitempty"`
+	AvgRating       float64         `json:"avgRating"`
+	RatingCount     int             `json:"ratingCount"`
+	ViewCount       int             `json:"viewCount"`
+	CreatedAt       time.Time       `json:"createdAt"`
+}
+
+// ExportGenerations writes every generation, public and unlisted, to w as
+// NDJSON, oldest first. It reads from the primary and is not bound by the
+// per-query timeout; the caller's context limits how long it may run.
+func (r *PostgresRepository) ExportGenerations(ctx context.Context, w io.Writer) error {
+	query := `
+		SELECT id, project_idea, experience_level, hook_preset, files, category_id,
+		       visibility, short_code, answers, avg_rating, rating_count, view_count, created_at
+		FROM generations
+		ORDER BY created_at, id`
+
+	rows, err := r.queryContext(ctx, query)
+	if err != nil {
+		return dbError(ctx, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	enc := json.NewEncoder(w)
+	for rows.Next() {
+		var gen ExportedGeneration
+		var shortCode sql.NullString
+		var answers []byte
+		if err := rows.Scan(
+			&gen.ID, &gen.ProjectIdea, &gen.ExperienceLevel, &gen.HookPreset, &gen.Files, &gen.CategoryID,
+			&gen.Visibility, &shortCode, &answers, &gen.AvgRating, &gen.RatingCount, &gen.ViewCount, &gen.CreatedAt,
+		); err != nil {
+			return dbError(ctx, err)
+		}
+		gen.ShortCode = shortCode.String
+		gen.Answers = answers
+
+		if err := enc.Encode(gen); err != nil {
+			return fmt.Errorf("write export: %w", err)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return dbError(ctx, err)
+	}
+	return nil
+}
+
+// streamBatchSize is how many rows StreamPublicGenerations fetches from its
+// cursor at a time.
+const streamBatchSize = 200
+
+// StreamPublicGenerations calls fn with every public generation, oldest
+// first, without answers or content hashes. Rows are fetched in batches from
+// a server-side cursor, so memory stays flat however large the table is.
+// Like ExportGenerations it reads from the primary and is not bound by the
+// per-query timeout. An error from fn stops the stream and is returned.
+func (r *PostgresRepository) StreamPublicGenerations(ctx context.Context, fn func(*Generation) error) error {
+	tx, err := r.beginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return dbError(ctx, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	declare := fmt.Sprintf(`
+		DECLARE public_generations NO SCROLL CURSOR FOR
+		SELECT g.id, g.project_idea, g.experience_level, g.hook_preset, g.files,
+		       g.category_id, c.name, g.avg_rating, g.rating_count, g.view_count, g.created_at,
+		       g.visibility, g.short_code
+		FROM generations g
+		LEFT JOIN categories c ON g.category_id = c.id
+		WHERE g.visibility = '%s'
+		ORDER BY g.created_at, g.id`, VisibilityPublic)
+	if _, err := tx.ExecContext(ctx, declare); err != nil {
+		return dbError(ctx, err)
+	}
+
+	fetch := fmt.Sprintf("FETCH %d FROM public_generations", streamBatchSize)
+	for {
+		n, err := r.streamBatch(ctx, tx, fetch, fn)
+		if err != nil {
+			return err
+		}
+		if n < streamBatchSize {
+			break
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return dbError(ctx, err)
+	}
+	return nil
+}
+
+// streamBatch runs one cursor FETCH, calling fn for each row, and returns how
+// many rows it read.
+func (r *PostgresRepository) streamBatch(ctx context.Context, tx SQLTx, fetch string, fn func(*Generation) error) (int, error) {
+	rows, err := tx.QueryContext(ctx, fetch)
+	if err != nil {
+		return 0, dbError(ctx, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	n := 0
+	for rows.Next() {
+		var gen Generation
+		var shortCode sql.NullString
+		if err := rows.Scan(
+			&gen.ID, &gen.ProjectIdea, &gen.ExperienceLevel, &gen.HookPreset, &gen.Files,
+			&gen.CategoryID, &gen.CategoryName, &gen.AvgRating, &gen.RatingCount, &gen.ViewCount, &gen.CreatedAt,
+			&gen.Visibility, &shortCode,
+		); err != nil {
+			return n, dbError(ctx, err)
+		}
+		gen.ShortCode = shortCode.String
+		n++
+		if err := fn(&gen); err != nil {
+			return n, err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return n, dbError(ctx, err)
+	}
+	return n, nil
+}
+
+// ImportGenerations reads an ExportGenerations stream and upserts every
+// generation in one transaction, returning how many were imported. IDs and
+// short codes are preserved; records without an ID get a new one. Existing
+// generations have their content replaced but keep their ratings, views and
+// short code. New generations take their counters from the export until the
+// next rating recalculates the average from stored ratings.
+func (r *PostgresRepository) ImportGenerations(ctx context.Context, src io.Reader) (int, error) {
+	tx, err := r.beginTx(ctx, nil)
+	if err != nil {
+		return 0, dbError(ctx, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query := `
+		INSERT INTO generations (id, project_idea, experience_level, hook_preset, files, category_id,
+		                         visibility, short_code, answers, avg_rating, rating_count, view_count, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (id) DO UPDATE SET
+			project_idea = EXCLUDED.project_idea,
+			experience_level = EXCLUDED.experience_level,
+			hook_preset = EXCLUDED.hook_preset,
+			files = EXCLUDED.files,
+			category_id = EXCLUDED.category_id,
+			visibility = EXCLUDED.visibility,
+			answers = EXCLUDED.answers`
+
+	imported := 0
+	err = decodeExport(src, func(gen ExportedGeneration) error {
+		var shortCode, answers any
+		if gen.ShortCode != "" {
+			shortCode = gen.ShortCode
+		}
+		if len(gen.Answers) > 0 {
+			answers = []byte(gen.Answers)
+		}
+		if _, err := tx.ExecContext(ctx, query,
+			gen.ID, gen.ProjectIdea, gen.ExperienceLevel, gen.HookPreset, []byte(gen.Files), gen.CategoryID,
+			gen.Visibility, shortCode, answers, gen.AvgRating, gen.RatingCount, gen.ViewCount, gen.CreatedAt,
+		); err != nil {
+			return dbError(ctx, err)
+		}
+		imported++
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, dbError(ctx, err)
+	}
+	return imported, nil
+}
+
+// decodeExport validates each NDJSON record of an export and passes it to fn.
+// Blank lines are skipped; the first invalid record stops the import.
+func decodeExport(src io.Reader, fn func(ExportedGeneration) error) error {
+	scanner := bufio.NewScanner(src)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxExportLineBytes)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var gen ExportedGeneration
+		if err := json.Unmarshal(scanner.Bytes(), &gen); err != nil {
+			return fmt.Errorf("%w: line %d: %v", ErrInvalidInput, line, err)
+		}
+		if err := normalizeImported(&gen); err != nil {
+			return fmt.Errorf("%w: line %d: %v", ErrInvalidInput, line, err)
+		}
+		if err := fn(gen); err != nil {
+			return err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return fmt.Errorf("%w: line %d: %v", ErrInvalidInput, line+1, err)
+		}
+		return fmt.Errorf("read import: %w", err)
+	}
+	return nil
+}
+
+// normalizeImported fills defaults for an imported record and rejects
+// records that could not have come from an export.
+func normalizeImported(gen *ExportedGeneration) error {
+	if gen.ID == "" {
+		gen.ID = uuid.NewString()
+	} else if _, err := uuid.Parse(gen.ID); err != nil {
+		return fmt.Errorf("invalid id %q", gen.ID)
+	}
+	if gen.ProjectIdea == "" {
+		return errors.New("missing projectIdea")
+	}
+	if !json.Valid(gen.Files) {
+		return errors.New("files must be JSON")
+	}
+	if len(gen.Answers) > 0 && !json.Valid(gen.Answers) {
+		return errors.New("answers must be JSON")
+	}
+	if gen.Visibility == "" {
+		gen.Visibility = VisibilityPublic
+	}
+	if !IsValidVisibility(gen.Visibility) {
+		return fmt.Errorf("unknown visibility %q", gen.Visibility)
+	}
+	if gen.ShortCode != "" && !IsValidShortCode(gen.ShortCode) {
+		return fmt.Errorf("invalid shortCode %q", gen.ShortCode)
+	}
+	if gen.CategoryID == 0 {
+		gen.CategoryID = 5 // Other
+	}
+	if gen.CreatedAt.IsZero() {
+		gen.CreatedAt = time.Now()
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// exportStore is a fake database that serves fixed generation rows to
+// queries and records the arguments of every committed statement.
+type exportStore struct {
+	rows [][]driver.Value
+
+	mu        sync.Mutex
+	pending   [][]driver.Value
+	committed [][]driver.Value
+}
+
+func (s *exportStore) Connect(context.Context) (driver.Conn, error) { return exportConn{s}, nil }
+func (s *exportStore) Driver() driver.Driver                        { return nil }
+
+func (s *exportStore) committedArgs() [][]driver.Value {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.committed
+}
+
+type exportConn struct{ s *exportStore }
+
+func (exportConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (exportConn) Close() error                        { return nil }
+func (c exportConn) Begin() (driver.Tx, error)         { return exportTx(c), nil }
+
+func (c exportConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	return &exportRows{rows: c.s.rows}, nil
+}
+
+func (c exportConn) ExecContext(_ context.Context, _ string, args []driver.NamedValue) (driver.Result, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	c.s.mu.Lock()
+	c.s.pending = append(c.s.pending, values)
+	c.s.mu.Unlock()
+	return driver.RowsAffected(1), nil
+}
+
+type exportTx exportConn
+
+func (t exportTx) Commit() error {
+	t.s.mu.Lock()
+	defer t.s.mu.Unlock()
+	t.s.committed = append(t.s.committed, t.s.pending...)
+	t.s.pending = nil
+	return nil
+}
+
+func (t exportTx) Rollback() error {
+	t.s.mu.Lock()
+	defer t.s.mu.Unlock()
+	t.s.pending = nil
+	return nil
+}
+
+type exportRows struct {
+	rows [][]driver.Value
+	next int
+}
+
+func (r *exportRows) Columns() []string {
+	return []string{"id", "project_idea", "experience_level", "hook_preset", "files", "category_id",
+		"visibility", "short_code", "answers", "avg_rating", "rating_count", "view_count", "created_at"}
+}
+func (r *exportRows) Close() error { return nil }
+
+func (r *exportRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+	return nil
+}
+
+func TestExportImport_RoundTrip(t *testing.T) {
+	created := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	rows := [][]driver.Value{
+		{"550e8400-e29b-41d4-a716-446655440000", "A todo app", "beginner", "default",
+			[]byte(`[{"path":"kickoff-prompt.md","content":"..."}]`), int64(3),
+			"public", "aB3xK9pQ", []byte(`[{"questionId":1,"answer":"React"}]`), 4.5, int64(2), int64(17), created},
+		{"6ba7b810-9dad-11d1-80b4-00c04fd430c8", "A CLI", "expert", "strict",
+			[]byte(`[]`), int64(5),
+			"unlisted", nil, nil, 0.0, int64(0), int64(0), created.Add(time.Hour)},
+	}
+
+	source := sql.OpenDB(&exportStore{rows: rows})
+	defer func() { _ = source.Close() }()
+
+	var buf bytes.Buffer
+	if err := NewPostgresRepository(source).ExportGenerations(context.Background(), &buf); err != nil {
+		t.Fatalf("ExportGenerations: %v", err)
+	}
+	if lines := strings.Count(buf.String(), "\n"); lines != len(rows) {
+		t.Fatalf("exported %d lines, want %d", lines, len(rows))
+	}
+
+	store := &exportStore{}
+	target := sql.OpenDB(store)
+	defer func() { _ = target.Close() }()
+
+	imported, err := NewPostgresRepository(target).ImportGenerations(context.Background(), &buf)
+	if err != nil {
+		t.Fatalf("ImportGenerations: %v", err)
+	}
+	if imported != len(rows) {
+		t.Errorf("imported = %d, want %d", imported, len(rows))
+	}
+
+	got := store.committedArgs()
+	if len(got) != len(rows) {
+		t.Fatalf("committed %d inserts, want %d", len(got), len(rows))
+	}
+	for i, want := range rows {
+		for col, value := range want {
+			if wantTime, ok := value.(time.Time); ok {
+				if gotTime, _ := got[i][col].(time.Time); !gotTime.Equal(wantTime) {
+					t.Errorf("row %d column %d = %v, want %v", i, col, got[i][col], wantTime)
+				}
+				continue
+			}
+			if gotBytes, ok := got[i][col].([]byte); ok {
+				if wantBytes, _ := value.([]byte); !bytes.Equal(gotBytes, wantBytes) {
+					t.Errorf("row %d column %d = %s, want %s", i, col, gotBytes, wantBytes)
+				}
+				continue
+			}
+			if got[i][col] != value {
+				t.Errorf("row %d column %d = %#v, want %#v", i, col, got[i][col], value)
+			}
+		}
+	}
+}
+
+func TestImportGenerations_InvalidLineImportsNothing(t *testing.T) {
+	store := &exportStore{}
+	db := sql.OpenDB(store)
+	defer func() { _ = db.Close() }()
+
+	src := strings.NewReader(
+		`{"id":"550e8400-e29b-41d4-a716-446655440000","projectIdea":"A todo app","files":[]}` + "\n" +
+			`{"id":"not-a-uuid","projectIdea":"A CLI","files":[]}` + "\n")
+
+	_, err := NewPostgresRepository(db).ImportGenerations(context.Background(), src)
+	if !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("error = %v, want ErrInvalidInput", err)
+	}
+	if !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("error %q does not name the failing line", err)
+	}
+	if got := store.committedArgs(); len(got) != 0 {
+		t.Errorf("committed %d inserts, want none", len(got))
+	}
+}
@@ -0,0 +1,599 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"better-kiro-prompts/internal/pagination"
+)
+
+// Both backends must implement the full Repository interface.
+var (
+	_ Repository = (*PostgresRepository)(nil)
+	_ Repository = (*MemoryRepository)(nil)
+)
+
+// MemoryRepository keeps generations, ratings and views in process memory.
+// It is meant for local development and demos without PostgreSQL: nothing
+// survives a restart, and it behaves like PostgresRepository for filtering,
+// sorting, pagination and validation.
+type MemoryRepository struct {
+	mu          sync.RWMutex
+	generations map[string]*Generation
+	shortCodes  map[string]string // short code -> generation ID
+	creators    []memoryCreator   // in the order they were recorded
+	views       map[memoryKey]bool
+	ratings     map[memoryKey]int
+	topRated    map[string]bool // generation IDs the top-rated webhook fired for
+	categories  []Category
+	archived    []Generation // moved out by ArchiveGenerations
+}
+
+// memoryCreator links a generation to the IP hash of the visitor who made it.
+type memoryCreator struct {
+	generationID string
+	ipHash       string
+}
+
+// memoryKey identifies a view or rating by generation and hashed visitor.
+type memoryKey struct {
+	generationID string
+	hash         string
+}
+
+// NewMemoryRepository creates an empty in-memory repository with the
+// default categories.
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		generations: make(map[string]*Generation),
+		shortCodes:  make(map[string]string),
+		views:       make(map[memoryKey]bool),
+		ratings:     make(map[memoryKey]int),
+		topRated:    make(map[string]bool),
+		categories:  DefaultCategories(),
+	}
+}
+
+// CreateGeneration stores a new generation, filling in its ID, short code
+// and creation time.
+func (r *MemoryRepository) CreateGeneration(ctx context.Context, gen *Generation) error {
+	if gen == nil {
+		return ErrInvalidInput
+	}
+	if gen.Visibility == "" {
+		gen.Visibility = VisibilityPublic
+	}
+	if !IsValidVisibility(gen.Visibility) {
+		return fmt.Errorf("%w: unknown visibility %q", ErrInvalidInput, gen.Visibility)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	code, err := insertWithShortCode(NewShortCode, func(code string) error {
+		if _, taken := r.shortCodes[code]; taken {
+			return fmt.Errorf("%w: short code %s", ErrDuplicateKey, code)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	gen.ID = uuid.NewString()
+	gen.ShortCode = code
+	gen.CreatedAt = time.Now()
+
+	stored := *gen
+	stored.CategoryName = ""
+	stored.AvgRating, stored.RatingCount, stored.ViewCount = 0, 0, 0
+	r.generations[stored.ID] = &stored
+	r.shortCodes[code] = stored.ID
+	return nil
+}
+
+// GetGeneration retrieves a generation by ID.
+func (r *MemoryRepository) GetGeneration(ctx context.Context, id string) (*Generation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	gen, ok := r.generations[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	out := r.withCategoryName(*gen)
+	out.ContentHash = ""
+	return &out, nil
+}
+
+// GetGenerationFromPrimary is GetGeneration; memory has no replica.
+func (r *MemoryRepository) GetGenerationFromPrimary(ctx context.Context, id string) (*Generation, error) {
+	return r.GetGeneration(ctx, id)
+}
+
+// GetGenerationByShortCode retrieves a generation by its short code.
+func (r *MemoryRepository) GetGenerationByShortCode(ctx context.Context, code string) (*Generation, error) {
+	r.mu.RLock()
+	id, ok := r.shortCodes[code]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return r.GetGeneration(ctx, id)
+}
+
+// FindGenerationByContentHash returns the newest public generation with the
+// given content hash created at or after since, or ErrNotFound. Only the ID,
+// short code, creation time and hash are filled in.
+func (r *MemoryRepository) FindGenerationByContentHash(ctx context.Context, hash string, since time.Time) (*Generation, error) {
+	if hash == "" {
+		return nil, ErrNotFound
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var newest *Generation
+	for _, gen := range r.generations {
+		if gen.ContentHash != hash || gen.Visibility != VisibilityPublic || gen.CreatedAt.Before(since) {
+			continue
+		}
+		if newest == nil || gen.CreatedAt.After(newest.CreatedAt) {
+			newest = gen
+		}
+	}
+	if newest == nil {
+		return nil, ErrNotFound
+	}
+
+	return &Generation{
+		ID:          newest.ID,
+		ShortCode:   newest.ShortCode,
+		CreatedAt:   newest.CreatedAt,
+		ContentHash: hash,
+		Visibility:  VisibilityPublic,
+	}, nil
+}
+
+// ListGenerations retrieves a paginated list of generations with optional filtering.
+// Unlisted generations are never included.
+func (r *MemoryRepository) ListGenerations(ctx context.Context, filter ListFilter) ([]Generation, int, error) {
+	filter.Page = pagination.NormalizePage(filter.Page)
+	filter.PageSize = pagination.NormalizePageSize(filter.PageSize)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	matched := []Generation{}
+	for _, gen := range r.generations {
+		if gen.Visibility != VisibilityPublic {
+			continue
+		}
+		if filter.CategoryID != nil && gen.CategoryID != *filter.CategoryID {
+			continue
+		}
+		out := r.withCategoryName(*gen)
+		out.ShortCode, out.Answers, out.ContentHash = "", nil, ""
+		matched = append(matched, out)
+	}
+
+	// Newest first, which also orders ties in the other sorts
+	sort.Slice(matched, func(i, j int) bool {
+		if !matched[i].CreatedAt.Equal(matched[j].CreatedAt) {
+			return matched[i].CreatedAt.After(matched[j].CreatedAt)
+		}
+		return matched[i].ID < matched[j].ID
+	})
+	switch filter.SortBy {
+	case "highest_rated":
+		sort.SliceStable(matched, func(i, j int) bool {
+			if matched[i].AvgRating != matched[j].AvgRating {
+				return matched[i].AvgRating > matched[j].AvgRating
+			}
+			return matched[i].RatingCount > matched[j].RatingCount
+		})
+	case "most_viewed":
+		sort.SliceStable(matched, func(i, j int) bool {
+			return matched[i].ViewCount > matched[j].ViewCount
+		})
+	}
+
+	total := len(matched)
+	start := pagination.Offset(filter.Page, filter.PageSize)
+	if start >= total {
+		return []Generation{}, total, nil
+	}
+	end := min(start+filter.PageSize, total)
+	return matched[start:end], total, nil
+}
+
+// IncrementViewCount increments the view count for a generation.
+func (r *MemoryRepository) IncrementViewCount(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	gen, ok := r.generations[id]
+	if !ok {
+		return ErrNotFound
+	}
+	gen.ViewCount++
+	return nil
+}
+
+// RecordCreator associates a generation with the IP hash of the visitor who
+// created it. Recording the same pair again is a no-op.
+func (r *MemoryRepository) RecordCreator(ctx context.Context, generationID string, ipHash string) error {
+	if generationID == "" || ipHash == "" {
+		return ErrInvalidInput
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.generations[generationID]; !ok {
+		return ErrNotFound
+	}
+	for _, c := range r.creators {
+		if c.generationID == generationID && c.ipHash == ipHash {
+			return nil
+		}
+	}
+	r.creators = append(r.creators, memoryCreator{generationID: generationID, ipHash: ipHash})
+	return nil
+}
+
+// StreamPublicGenerations calls fn with every public generation, oldest
+// first, without answers or content hashes. fn runs on a snapshot, so it may
+// call back into the repository.
+func (r *MemoryRepository) StreamPublicGenerations(ctx context.Context, fn func(*Generation) error) error {
+	r.mu.RLock()
+	generations := []Generation{}
+	for _, gen := range r.generations {
+		if gen.Visibility != VisibilityPublic {
+			continue
+		}
+		out := r.withCategoryName(*gen)
+		out.Answers, out.ContentHash = nil, ""
+		generations = append(generations, out)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(generations, func(i, j int) bool {
+		if !generations[i].CreatedAt.Equal(generations[j].CreatedAt) {
+			return generations[i].CreatedAt.Before(generations[j].CreatedAt)
+		}
+		return generations[i].ID < generations[j].ID
+	})
+
+	for i := range generations {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(&generations[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListGenerationsByCreator returns up to limit generations created from an IP
+// hash, most recently created first, without their files. Unlisted
+// generations are included since they belong to the caller.
+func (r *MemoryRepository) ListGenerationsByCreator(ctx context.Context, ipHash string, limit int) ([]Generation, error) {
+	if ipHash == "" {
+		return []Generation{}, nil
+	}
+	limit = pagination.NormalizePageSize(limit)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	generations := []Generation{}
+	for i := len(r.creators) - 1; i >= 0 && len(generations) < limit; i-- {
+		c := r.creators[i]
+		if c.ipHash != ipHash {
+			continue
+		}
+		gen, ok := r.generations[c.generationID]
+		if !ok {
+			continue
+		}
+		out := r.withCategoryName(*gen)
+		out.Files, out.Answers, out.ContentHash = nil, nil, ""
+		generations = append(generations, out)
+	}
+	return generations, nil
+}
+
+// RecordView records a view for a generation, deduplicated by IP hash.
+// Returns true if this is a new view, false if this IP has already viewed
+// this generation. Only increments the view count for new views.
+func (r *MemoryRepository) RecordView(ctx context.Context, generationID string, ipHash string) (bool, error) {
+	if generationID == "" || ipHash == "" {
+		return false, ErrInvalidInput
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	gen, ok := r.generations[generationID]
+	if !ok {
+		return false, ErrNotFound
+	}
+	key := memoryKey{generationID: generationID, hash: ipHash}
+	if r.views[key] {
+		return false, nil
+	}
+	r.views[key] = true
+	gen.ViewCount++
+	return true, nil
+}
+
+// HasViewed reports whether a view of a generation is recorded for an IP hash.
+func (r *MemoryRepository) HasViewed(ctx context.Context, generationID string, ipHash string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.views[memoryKey{generationID: generationID, hash: ipHash}], nil
+}
+
+// MarkTopRated records that a generation crossed the top-rated threshold and
+// reports whether this was the first time.
+func (r *MemoryRepository) MarkTopRated(ctx context.Context, genID string) (bool, error) {
+	if genID == "" {
+		return false, ErrInvalidInput
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.topRated[genID] {
+		return false, nil
+	}
+	r.topRated[genID] = true
+	return true, nil
+}
+
+// CreateOrUpdateRating creates or updates a rating for a generation and
+// recalculates its average.
+func (r *MemoryRepository) CreateOrUpdateRating(ctx context.Context, genID string, score int, voterHash string) error {
+	if score < 1 || score > 5 {
+		return fmt.Errorf("%w: score must be between 1 and 5", ErrInvalidInput)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	gen, ok := r.generations[genID]
+	if !ok {
+		return ErrNotFound
+	}
+	r.ratings[memoryKey{generationID: genID, hash: voterHash}] = score
+
+	var sum, count int
+	for key, s := range r.ratings {
+		if key.generationID == genID {
+			sum += s
+			count++
+		}
+	}
+	gen.AvgRating = float64(sum) / float64(count)
+	gen.RatingCount = count
+	return nil
+}
+
+// GetUserRating retrieves the user's rating for a generation, or 0 if they
+// have not rated it.
+func (r *MemoryRepository) GetUserRating(ctx context.Context, genID string, voterHash string) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.ratings[memoryKey{generationID: genID, hash: voterHash}], nil
+}
+
+// GetCategoryByKeywords matches text against the default categories.
+func (r *MemoryRepository) GetCategoryByKeywords(ctx context.Context, text string) (int, error) {
+	return NewCategoryMatcher(r.categories).Match(text), nil
+}
+
+// GetCategories retrieves all categories.
+func (r *MemoryRepository) GetCategories(ctx context.Context) ([]Category, error) {
+	categories := make([]Category, len(r.categories))
+	copy(categories, r.categories)
+	return categories, nil
+}
+
+// GetCategoryCounts retrieves all categories with how many public
+// generations each holds.
+func (r *MemoryRepository) GetCategoryCounts(ctx context.Context) ([]CategoryCount, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	counts := make([]CategoryCount, len(r.categories))
+	index := make(map[int]int, len(r.categories))
+	for i, cat := range r.categories {
+		counts[i].Category = cat
+		index[cat.ID] = i
+	}
+	for _, gen := range r.generations {
+		if i, ok := index[gen.CategoryID]; ok && gen.Visibility == VisibilityPublic {
+			counts[i].Count++
+		}
+	}
+	return counts, nil
+}
+
+// ExportGenerations writes every generation, public and unlisted, to w as
+// NDJSON, oldest first.
+func (r *MemoryRepository) ExportGenerations(ctx context.Context, w io.Writer) error {
+	r.mu.RLock()
+	generations := make([]Generation, 0, len(r.generations))
+	for _, gen := range r.generations {
+		generations = append(generations, *gen)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(generations, func(i, j int) bool {
+		if !generations[i].CreatedAt.Equal(generations[j].CreatedAt) {
+			return generations[i].CreatedAt.Before(generations[j].CreatedAt)
+		}
+		return generations[i].ID < generations[j].ID
+	})
+
+	enc := json.NewEncoder(w)
+	for _, gen := range generations {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := enc.Encode(ExportedGeneration{
+			ID:              gen.ID,
+			ProjectIdea:     gen.ProjectIdea,
+			ExperienceLevel: gen.ExperienceLevel,
+			HookPreset:      gen.HookPreset,
+			Files:           gen.Files,
+			CategoryID:      gen.CategoryID,
+			Visibility:      gen.Visibility,
+			ShortCode:       gen.ShortCode,
+			Answers:         gen.Answers,
+			AvgRating:       gen.AvgRating,
+			RatingCount:     gen.RatingCount,
+			ViewCount:       gen.ViewCount,
+			CreatedAt:       gen.CreatedAt,
+		}); err != nil {
+			return fmt.Errorf("write export: %w", err)
+		}
+	}
+	return nil
+}
+
+// ImportGenerations reads an ExportGenerations stream and upserts every
+// generation, returning how many were imported. Nothing is stored unless the
+// whole stream is valid. Existing generations have their content replaced
+// but keep their ratings, views and short code.
+func (r *MemoryRepository) ImportGenerations(ctx context.Context, src io.Reader) (int, error) {
+	var imported []ExportedGeneration
+	if err := decodeExport(src, func(gen ExportedGeneration) error {
+		imported = append(imported, gen)
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Check short codes first so a conflict leaves the repository untouched
+	claimed := make(map[string]string)
+	for _, gen := range imported {
+		if gen.ShortCode == "" {
+			continue
+		}
+		if _, exists := r.generations[gen.ID]; exists {
+			continue
+		}
+		owner, taken := r.shortCodes[gen.ShortCode]
+		if !taken {
+			owner, taken = claimed[gen.ShortCode]
+		}
+		if taken && owner != gen.ID {
+			return 0, fmt.Errorf("%w: short code %s", ErrDuplicateKey, gen.ShortCode)
+		}
+		claimed[gen.ShortCode] = gen.ID
+	}
+
+	for _, gen := range imported {
+		if existing, ok := r.generations[gen.ID]; ok {
+			existing.ProjectIdea = gen.ProjectIdea
+			existing.ExperienceLevel = gen.ExperienceLevel
+			existing.HookPreset = gen.HookPreset
+			existing.Files = gen.Files
+			existing.CategoryID = gen.CategoryID
+			existing.Visibility = gen.Visibility
+			existing.Answers = gen.Answers
+			continue
+		}
+		r.generations[gen.ID] = &Generation{
+			ID:              gen.ID,
+			ProjectIdea:     gen.ProjectIdea,
+			ExperienceLevel: gen.ExperienceLevel,
+			HookPreset:      gen.HookPreset,
+			Files:           gen.Files,
+			CategoryID:      gen.CategoryID,
+			Visibility:      gen.Visibility,
+			ShortCode:       gen.ShortCode,
+			Answers:         gen.Answers,
+			AvgRating:       gen.AvgRating,
+			RatingCount:     gen.RatingCount,
+			ViewCount:       gen.ViewCount,
+			CreatedAt:       gen.CreatedAt,
+		}
+		if gen.ShortCode != "" {
+			r.shortCodes[gen.ShortCode] = gen.ID
+		}
+	}
+	return len(imported), nil
+}
+
+// ArchiveGenerations moves every generation matching policy out of the
+// gallery, or deletes it when policy.Delete is set, and returns how many
+// were removed. Their ratings, views and creators are dropped either way.
+func (r *MemoryRepository) ArchiveGenerations(ctx context.Context, policy ArchivePolicy) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	removed := make(map[string]bool)
+	for id, gen := range r.generations {
+		if !policy.matches(gen) {
+			continue
+		}
+		if !policy.Delete {
+			r.archived = append(r.archived, *gen)
+		}
+		delete(r.generations, id)
+		delete(r.shortCodes, gen.ShortCode)
+		removed[id] = true
+	}
+	if len(removed) == 0 {
+		return 0, nil
+	}
+
+	creators := r.creators[:0]
+	for _, c := range r.creators {
+		if !removed[c.generationID] {
+			creators = append(creators, c)
+		}
+	}
+	r.creators = creators
+	for key := range r.views {
+		if removed[key.generationID] {
+			delete(r.views, key)
+		}
+	}
+	for key := range r.ratings {
+		if removed[key.generationID] {
+			delete(r.ratings, key)
+		}
+	}
+	for id := range removed {
+		delete(r.topRated, id)
+	}
+	return len(removed), nil
+}
+
+// withCategoryName returns gen with its category name filled in.
+func (r *MemoryRepository) withCategoryName(gen Generation) Generation {
+	for _, cat := range r.categories {
+		if cat.ID == gen.CategoryID {
+			gen.CategoryName = cat.Name
+			break
+		}
+	}
+	return gen
+}
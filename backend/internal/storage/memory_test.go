@@ -0,0 +1,154 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMemoryRepository_CreateAndGetGeneration(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+
+	gen := &Generation{
+		ProjectIdea:     "A CLI for tracking habits",
+		ExperienceLevel: "novice",
+		HookPreset:      "default",
+		Files:           json.RawMessage(`[]`),
+		CategoryID:      2,
+		Answers:         json.RawMessage(`[{"questionId":1,"answer":"me"}]`),
+	}
+	if err := repo.CreateGeneration(ctx, gen); err != nil {
+		t.Fatalf("CreateGeneration() error = %v", err)
+	}
+	if gen.ID == "" || !IsValidShortCode(gen.ShortCode) || gen.CreatedAt.IsZero() {
+		t.Fatalf("CreateGeneration() left ID %q, short code %q, created at %v", gen.ID, gen.ShortCode, gen.CreatedAt)
+	}
+	if gen.Visibility != VisibilityPublic {
+		t.Errorf("Visibility = %q, want public by default", gen.Visibility)
+	}
+
+	got, err := repo.GetGenerationByShortCode(ctx, gen.ShortCode)
+	if err != nil {
+		t.Fatalf("GetGenerationByShortCode() error = %v", err)
+	}
+	if got.ID != gen.ID || got.CategoryName != "CLI" || string(got.Answers) != string(gen.Answers) {
+		t.Errorf("GetGenerationByShortCode() = %+v", got)
+	}
+
+	if _, err := repo.GetGeneration(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetGeneration(missing) error = %v, want ErrNotFound", err)
+	}
+	if err := repo.CreateGeneration(ctx, &Generation{Visibility: "private"}); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("CreateGeneration(private) error = %v, want ErrInvalidInput", err)
+	}
+}
+
+func TestMemoryRepository_UnlistedOnlyReachableDirectly(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+
+	public := &Generation{ProjectIdea: "public", Files: json.RawMessage(`[]`), CategoryID: 1}
+	unlisted := &Generation{ProjectIdea: "unlisted", Files: json.RawMessage(`[]`), CategoryID: 1, Visibility: VisibilityUnlisted, ContentHash: "h"}
+	for _, gen := range []*Generation{public, unlisted} {
+		if err := repo.CreateGeneration(ctx, gen); err != nil {
+			t.Fatalf("CreateGeneration() error = %v", err)
+		}
+	}
+
+	items, total, err := repo.ListGenerations(ctx, ListFilter{})
+	if err != nil {
+		t.Fatalf("ListGenerations() error = %v", err)
+	}
+	if total != 1 || len(items) != 1 || items[0].ID != public.ID {
+		t.Errorf("ListGenerations() = %d items (total %d), want only the public one", len(items), total)
+	}
+	if _, err := repo.GetGeneration(ctx, unlisted.ID); err != nil {
+		t.Errorf("GetGeneration(unlisted) error = %v", err)
+	}
+	if _, err := repo.FindGenerationByContentHash(ctx, "h", time.Time{}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("FindGenerationByContentHash() error = %v, want ErrNotFound for unlisted", err)
+	}
+}
+
+func TestMemoryRepository_ViewsRatingsAndCreators(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+
+	gen := &Generation{ProjectIdea: "A web app", Files: json.RawMessage(`[]`), CategoryID: 3}
+	if err := repo.CreateGeneration(ctx, gen); err != nil {
+		t.Fatalf("CreateGeneration() error = %v", err)
+	}
+
+	for i, want := range []bool{true, false} {
+		isNew, err := repo.RecordView(ctx, gen.ID, "viewer")
+		if err != nil || isNew != want {
+			t.Errorf("RecordView() #%d = %v, %v; want %v", i+1, isNew, err, want)
+		}
+	}
+
+	if err := repo.CreateOrUpdateRating(ctx, gen.ID, 6, "voter"); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("CreateOrUpdateRating(6) error = %v, want ErrInvalidInput", err)
+	}
+	for _, r := range []struct {
+		voter string
+		score int
+	}{{"a", 5}, {"b", 2}, {"a", 4}} {
+		if err := repo.CreateOrUpdateRating(ctx, gen.ID, r.score, r.voter); err != nil {
+			t.Fatalf("CreateOrUpdateRating() error = %v", err)
+		}
+	}
+	if score, _ := repo.GetUserRating(ctx, gen.ID, "a"); score != 4 {
+		t.Errorf("GetUserRating(a) = %d, want 4", score)
+	}
+
+	if err := repo.RecordCreator(ctx, gen.ID, "creator"); err != nil {
+		t.Fatalf("RecordCreator() error = %v", err)
+	}
+	mine, err := repo.ListGenerationsByCreator(ctx, "creator", 10)
+	if err != nil || len(mine) != 1 {
+		t.Fatalf("ListGenerationsByCreator() = %d items, %v; want 1", len(mine), err)
+	}
+	if mine[0].Files != nil || mine[0].ViewCount != 1 || mine[0].AvgRating != 3 || mine[0].RatingCount != 2 {
+		t.Errorf("ListGenerationsByCreator() = %+v, want no files, 1 view and a 3.0 average from 2 ratings", mine[0])
+	}
+}
+
+func TestMemoryRepository_ExportImportRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	src := NewMemoryRepository()
+	for _, idea := range []string{"first", "second"} {
+		if err := src.CreateGeneration(ctx, &Generation{ProjectIdea: idea, Files: json.RawMessage(`[]`), CategoryID: 1}); err != nil {
+			t.Fatalf("CreateGeneration() error = %v", err)
+		}
+	}
+
+	var backup bytes.Buffer
+	if err := src.ExportGenerations(ctx, &backup); err != nil {
+		t.Fatalf("ExportGenerations() error = %v", err)
+	}
+	if lines := strings.Count(backup.String(), "\n"); lines != 2 {
+		t.Fatalf("export has %d lines, want 2", lines)
+	}
+
+	dst := NewMemoryRepository()
+	n, err := dst.ImportGenerations(ctx, bytes.NewReader(backup.Bytes()))
+	if err != nil || n != 2 {
+		t.Fatalf("ImportGenerations() = %d, %v; want 2", n, err)
+	}
+	var again bytes.Buffer
+	if err := dst.ExportGenerations(ctx, &again); err != nil {
+		t.Fatalf("ExportGenerations() error = %v", err)
+	}
+	if again.String() != backup.String() {
+		t.Errorf("re-export differs:\n%s\nwant:\n%s", again.String(), backup.String())
+	}
+
+	if _, err := dst.ImportGenerations(ctx, strings.NewReader(`{"projectIdea":""}`)); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("ImportGenerations(invalid) error = %v, want ErrInvalidInput", err)
+	}
+}
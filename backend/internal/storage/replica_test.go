@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// poolRecorder records which pool served each statement.
+type poolRecorder struct {
+	mu     sync.Mutex
+	served []string
+}
+
+func (p *poolRecorder) record(pool string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.served = append(p.served, pool)
+}
+
+func (p *poolRecorder) pools() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.Compact(slices.Clone(p.served))
+}
+
+// recordingConnector opens connections that record their pool name and
+// fail every statement, which is enough to see where a call was routed.
+type recordingConnector struct {
+	name     string
+	recorder *poolRecorder
+}
+
+func (c recordingConnector) Connect(context.Context) (driver.Conn, error) {
+	return recordingConn(c), nil
+}
+func (c recordingConnector) Driver() driver.Driver { return nil }
+
+type recordingConn recordingConnector
+
+var errRecorded = errors.New("recorded")
+
+func (recordingConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (recordingConn) Close() error                        { return nil }
+
+func (c recordingConn) Begin() (driver.Tx, error) {
+	c.recorder.record(c.name)
+	return nil, errRecorded
+}
+
+func (c recordingConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	c.recorder.record(c.name)
+	return nil, errRecorded
+}
+
+func (c recordingConn) ExecContext(context.Context, string, []driver.NamedValue) (driver.Result, error) {
+	c.recorder.record(c.name)
+	return nil, errRecorded
+}
+
+func newReplicaTestRepository(t *testing.T) (*PostgresRepository, *poolRecorder) {
+	t.Helper()
+	recorder := &poolRecorder{}
+	primary := sql.OpenDB(recordingConnector{name: "primary", recorder: recorder})
+	replica := sql.OpenDB(recordingConnector{name: "replica", recorder: recorder})
+	t.Cleanup(func() {
+		_ = primary.Close()
+		_ = replica.Close()
+	})
+
+	repo := NewPostgresRepository(primary)
+	repo.SetReplica(replica)
+	return repo, recorder
+}
+
+func TestReplica_RoutesByOperation(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name string
+		call func(r *PostgresRepository)
+		want string
+	}{
+		{"GetGeneration", func(r *PostgresRepository) { _, _ = r.GetGeneration(ctx, "gen-1") }, "replica"},
+		{"GetGenerationFromPrimary", func(r *PostgresRepository) { _, _ = r.GetGenerationFromPrimary(ctx, "gen-1") }, "primary"},
+		{"GetGenerationByShortCode", func(r *PostgresRepository) { _, _ = r.GetGenerationByShortCode(ctx, "aZ09bY18") }, "replica"},
+		{"ListGenerations", func(r *PostgresRepository) { _, _, _ = r.ListGenerations(ctx, ListFilter{}) }, "replica"},
+		{"GetCategories", func(r *PostgresRepository) { _, _ = r.GetCategories(ctx) }, "replica"},
+		{"GetCategoryCounts", func(r *PostgresRepository) { _, _ = r.GetCategoryCounts(ctx) }, "replica"},
+		{"CreateGeneration", func(r *PostgresRepository) { _ = r.CreateGeneration(ctx, &Generation{ProjectIdea: "idea"}) }, "primary"},
+		{"IncrementViewCount", func(r *PostgresRepository) { _ = r.IncrementViewCount(ctx, "gen-1") }, "primary"},
+		{"RecordView", func(r *PostgresRepository) { _, _ = r.RecordView(ctx, "gen-1", "hash") }, "primary"},
+		{"CreateOrUpdateRating", func(r *PostgresRepository) { _ = r.CreateOrUpdateRating(ctx, "gen-1", 5, "voter") }, "primary"},
+		{"GetUserRating", func(r *PostgresRepository) { _, _ = r.GetUserRating(ctx, "gen-1", "voter") }, "primary"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, recorder := newReplicaTestRepository(t)
+			tt.call(repo)
+			if got := recorder.pools(); !slices.Equal(got, []string{tt.want}) {
+				t.Errorf("served by %v, want only %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReplica_ReadsUsePrimaryWithoutReplica(t *testing.T) {
+	recorder := &poolRecorder{}
+	primary := sql.OpenDB(recordingConnector{name: "primary", recorder: recorder})
+	defer func() { _ = primary.Close() }()
+
+	repo := NewPostgresRepository(primary)
+	_, _ = repo.GetGeneration(context.Background(), "gen-1")
+
+	if got := recorder.pools(); !slices.Equal(got, []string{"primary"}) {
+		t.Errorf("served by %v, want primary", got)
+	}
+}
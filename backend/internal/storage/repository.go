@@ -7,9 +7,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"time"
 
 	"better-kiro-prompts/internal/db"
+	"better-kiro-prompts/internal/pagination"
 )
 
 // Common errors
@@ -18,8 +20,26 @@ var (
 	ErrDuplicateKey  = errors.New("duplicate key violation")
 	ErrInvalidInput  = errors.New("invalid input")
 	ErrDatabaseError = errors.New("database error")
+	ErrQueryTimeout  = errors.New("database query timed out")
 )
 
+// DefaultQueryTimeout bounds each repository call unless SetQueryTimeout
+// changes it.
+const DefaultQueryTimeout = 5 * time.Second
+
+// Generation visibility values.
+const (
+	// VisibilityPublic generations are listed in the gallery.
+	VisibilityPublic = "public"
+	// VisibilityUnlisted generations are only reachable by direct ID.
+	VisibilityUnlisted = "unlisted"
+)
+
+// IsValidVisibility reports whether v is a known visibility value.
+func IsValidVisibility(v string) bool {
+	return v == VisibilityPublic || v == VisibilityUnlisted
+}
+
 // Generation represents a stored generation record.
 type Generation struct {
 	ID              string          `json:"id"`
@@ -29,10 +49,22 @@ type Generation struct {
 	Files           json.RawMessage `json:"files"`
 	CategoryID      int             `json:"categoryId"`
 	CategoryName    string          `json:"categoryName,omitempty"`
+	Visibility      string          `json:"visibility"`
+	ShortCode       string          `json:"shortCode,omitempty"` // Empty for generations stored before short links
 	AvgRating       float64         `json:"avgRating"`
 	RatingCount     int             `json:"ratingCount"`
 	ViewCount       int             `json:"viewCount"`
 	CreatedAt       time.Time       `json:"createdAt"`
+
+	// Answers holds the question answers the files were generated from.
+	// They may contain sensitive details, so they are never serialized and
+	// are only read back for admin replays.
+	Answers json.RawMessage `json:"-"`
+
+	// ContentHash identifies the inputs the files were generated from, for
+	// de-duplicating identical public generations. Empty is stored as NULL
+	// and never matches.
+	ContentHash string `json:"-"`
 }
 
 // ListFilter defines filtering and pagination options for listing generations.
@@ -48,19 +80,37 @@ type Repository interface {
 	// Generations
 	CreateGeneration(ctx context.Context, gen *Generation) error
 	GetGeneration(ctx context.Context, id string) (*Generation, error)
+	GetGenerationFromPrimary(ctx context.Context, id string) (*Generation, error)
+	GetGenerationByShortCode(ctx context.Context, code string) (*Generation, error)
+	FindGenerationByContentHash(ctx context.Context, hash string, since time.Time) (*Generation, error)
 	ListGenerations(ctx context.Context, filter ListFilter) ([]Generation, int, error)
+	StreamPublicGenerations(ctx context.Context, fn func(*Generation) error) error
 	IncrementViewCount(ctx context.Context, id string) error
 
+	// Creators (IP hash of who made a generation; never served publicly)
+	RecordCreator(ctx context.Context, generationID string, ipHash string) error
+	ListGenerationsByCreator(ctx context.Context, ipHash string, limit int) ([]Generation, error)
+
 	// Views (IP-deduplicated)
 	RecordView(ctx context.Context, generationID string, ipHash string) (isNew bool, err error)
+	HasViewed(ctx context.Context, generationID string, ipHash string) (bool, error)
 
 	// Ratings
 	CreateOrUpdateRating(ctx context.Context, genID string, score int, voterHash string) error
 	GetUserRating(ctx context.Context, genID string, voterHash string) (int, error)
+	MarkTopRated(ctx context.Context, genID string) (first bool, err error)
 
 	// Categories
 	GetCategoryByKeywords(ctx context.Context, text string) (int, error)
 	GetCategories(ctx context.Context) ([]Category, error)
+	GetCategoryCounts(ctx context.Context) ([]CategoryCount, error)
+
+	// Backups (NDJSON of ExportedGeneration)
+	ExportGenerations(ctx context.Context, w io.Writer) error
+	ImportGenerations(ctx context.Context, r io.Reader) (int, error)
+
+	// Retention
+	ArchiveGenerations(ctx context.Context, policy ArchivePolicy) (int, error)
 }
 
 // Category represents a generation category.
@@ -70,6 +120,12 @@ type Category struct {
 	Keywords []string `json:"keywords"`
 }
 
+// CategoryCount is a category with the number of listed generations in it.
+type CategoryCount struct {
+	Category
+	Count int `json:"count"`
+}
+
 // SQLDB defines the interface for database operations that both sql.DB and LoggingDB satisfy
 type SQLDB interface {
 	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
@@ -88,24 +144,71 @@ type SQLTx interface {
 }
 
 // PostgresRepository implements Repository using PostgreSQL.
+//
+// With a replica configured, gallery reads (GetGeneration,
+// GetGenerationByShortCode, ListGenerations, GetCategories and
+// GetCategoryCounts) are served by the replica and may briefly lag behind
+// writes. Everything else, including reads that must see the caller's own
+// writes, goes to the primary.
 type PostgresRepository struct {
-	db        *sql.DB
-	loggingDB *db.LoggingDB
+	db           *sql.DB
+	loggingDB    *db.LoggingDB
+	queryTimeout time.Duration
+
+	// Optional read replica; nil serves reads from the primary
+	replicaDB        *sql.DB
+	replicaLoggingDB *db.LoggingDB
 }
 
 // NewPostgresRepository creates a new PostgreSQL repository.
 func NewPostgresRepository(sqlDB *sql.DB) *PostgresRepository {
-	return &PostgresRepository{db: sqlDB}
+	return &PostgresRepository{db: sqlDB, queryTimeout: DefaultQueryTimeout}
 }
 
 // NewPostgresRepositoryWithLogging creates a new PostgreSQL repository with logging.
 func NewPostgresRepositoryWithLogging(loggingDB *db.LoggingDB) *PostgresRepository {
 	return &PostgresRepository{
-		db:        loggingDB.DB(),
-		loggingDB: loggingDB,
+		db:           loggingDB.DB(),
+		loggingDB:    loggingDB,
+		queryTimeout: DefaultQueryTimeout,
 	}
 }
 
+// SetReplica routes gallery reads to a read replica.
+func (r *PostgresRepository) SetReplica(replica *sql.DB) {
+	r.replicaDB = replica
+	r.replicaLoggingDB = nil
+}
+
+// SetReplicaWithLogging routes gallery reads to a read replica with logging.
+func (r *PostgresRepository) SetReplicaWithLogging(replica *db.LoggingDB) {
+	r.replicaDB = replica.DB()
+	r.replicaLoggingDB = replica
+}
+
+// SetQueryTimeout sets the deadline for each repository call. Calls that
+// exceed it are cancelled and fail with ErrQueryTimeout. Zero disables it.
+func (r *PostgresRepository) SetQueryTimeout(d time.Duration) {
+	r.queryTimeout = d
+}
+
+// withQueryTimeout derives the context for one repository call.
+func (r *PostgresRepository) withQueryTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.queryTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeoutCause(ctx, r.queryTimeout, ErrQueryTimeout)
+}
+
+// dbError wraps a database error, reporting ErrQueryTimeout when the call's
+// own deadline cut it short rather than the caller's context.
+func dbError(ctx context.Context, err error) error {
+	if errors.Is(context.Cause(ctx), ErrQueryTimeout) {
+		return fmt.Errorf("%w: %v", ErrQueryTimeout, err)
+	}
+	return fmt.Errorf("%w: %v", ErrDatabaseError, err)
+}
+
 // queryContext executes a query using the logging wrapper if available
 func (r *PostgresRepository) queryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
 	if r.loggingDB != nil {
@@ -122,6 +225,29 @@ func (r *PostgresRepository) queryRowContext(ctx context.Context, query string,
 	return r.db.QueryRowContext(ctx, query, args...)
 }
 
+// readQueryContext executes a read that tolerates replication lag, on the
+// replica if one is configured
+func (r *PostgresRepository) readQueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	if r.replicaLoggingDB != nil {
+		return r.replicaLoggingDB.QueryContext(ctx, query, args...)
+	}
+	if r.replicaDB != nil {
+		return r.replicaDB.QueryContext(ctx, query, args...)
+	}
+	return r.queryContext(ctx, query, args...)
+}
+
+// readQueryRowContext is readQueryContext for single-row reads
+func (r *PostgresRepository) readQueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	if r.replicaLoggingDB != nil {
+		return r.replicaLoggingDB.QueryRowContext(ctx, query, args...)
+	}
+	if r.replicaDB != nil {
+		return r.replicaDB.QueryRowContext(ctx, query, args...)
+	}
+	return r.queryRowContext(ctx, query, args...)
+}
+
 // execContext executes a query that doesn't return rows using the logging wrapper if available
 func (r *PostgresRepository) execContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
 	if r.loggingDB != nil {
@@ -143,38 +269,114 @@ func (r *PostgresRepository) CreateGeneration(ctx context.Context, gen *Generati
 	if gen == nil {
 		return ErrInvalidInput
 	}
+	if gen.Visibility == "" {
+		gen.Visibility = VisibilityPublic
+	}
+	if !IsValidVisibility(gen.Visibility) {
+		return fmt.Errorf("%w: unknown visibility %q", ErrInvalidInput, gen.Visibility)
+	}
+
+	ctx, cancel := r.withQueryTimeout(ctx)
+	defer cancel()
 
 	query := `
-		INSERT INTO generations (project_idea, experience_level, hook_preset, files, category_id)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO generations (project_idea, experience_level, hook_preset, files, category_id, answers, visibility, short_code, content_hash)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING id, created_at`
 
-	err := r.queryRowContext(ctx, query,
-		gen.ProjectIdea,
-		gen.ExperienceLevel,
-		gen.HookPreset,
-		gen.Files,
-		gen.CategoryID,
-	).Scan(&gen.ID, &gen.CreatedAt)
-
+	contentHash := sql.NullString{String: gen.ContentHash, Valid: gen.ContentHash != ""}
+
+	code, err := insertWithShortCode(NewShortCode, func(code string) error {
+		return r.queryRowContext(ctx, query,
+			gen.ProjectIdea,
+			gen.ExperienceLevel,
+			gen.HookPreset,
+			gen.Files,
+			gen.CategoryID,
+			gen.Answers,
+			gen.Visibility,
+			code,
+			contentHash,
+		).Scan(&gen.ID, &gen.CreatedAt)
+	})
+	if errors.Is(err, ErrDuplicateKey) {
+		return err
+	}
 	if err != nil {
-		return fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		return dbError(ctx, err)
 	}
+	gen.ShortCode = code
 
 	return nil
 }
 
 // GetGeneration retrieves a generation by ID.
 func (r *PostgresRepository) GetGeneration(ctx context.Context, id string) (*Generation, error) {
+	return r.getGeneration(ctx, r.readQueryRowContext, "g.id = $1", id)
+}
+
+// GetGenerationFromPrimary retrieves a generation by ID from the primary, so
+// it reflects the caller's own writes, such as a rating just recorded.
+func (r *PostgresRepository) GetGenerationFromPrimary(ctx context.Context, id string) (*Generation, error) {
+	return r.getGeneration(ctx, r.queryRowContext, "g.id = $1", id)
+}
+
+// GetGenerationByShortCode retrieves a generation by its short code.
+func (r *PostgresRepository) GetGenerationByShortCode(ctx context.Context, code string) (*Generation, error) {
+	return r.getGeneration(ctx, r.readQueryRowContext, "g.short_code = $1", code)
+}
+
+// FindGenerationByContentHash returns the newest public generation with the
+// given content hash created at or after since, or ErrNotFound. Only the ID,
+// short code, creation time and hash are filled in. It reads from the primary
+// so a generation stored a moment ago is found.
+func (r *PostgresRepository) FindGenerationByContentHash(ctx context.Context, hash string, since time.Time) (*Generation, error) {
+	if hash == "" {
+		return nil, ErrNotFound
+	}
+
+	ctx, cancel := r.withQueryTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT id, short_code, created_at
+		FROM generations
+		WHERE content_hash = $1 AND visibility = $2 AND created_at >= $3
+		ORDER BY created_at DESC
+		LIMIT 1`
+
+	gen := &Generation{ContentHash: hash, Visibility: VisibilityPublic}
+	var shortCode sql.NullString
+	err := r.queryRowContext(ctx, query, hash, VisibilityPublic, since).Scan(&gen.ID, &shortCode, &gen.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+	gen.ShortCode = shortCode.String
+
+	return gen, nil
+}
+
+// getGeneration retrieves the generation matching a single-argument WHERE
+// clause, reading through queryRow.
+func (r *PostgresRepository) getGeneration(ctx context.Context, queryRow func(context.Context, string, ...any) *sql.Row, where string, arg string) (*Generation, error) {
+	ctx, cancel := r.withQueryTimeout(ctx)
+	defer cancel()
+
 	query := `
 		SELECT g.id, g.project_idea, g.experience_level, g.hook_preset, g.files,
-		       g.category_id, c.name, g.avg_rating, g.rating_count, g.view_count, g.created_at
+		       g.category_id, c.name, g.avg_rating, g.rating_count, g.view_count, g.created_at,
+		       g.visibility, g.answers, g.short_code
 		FROM generations g
 		LEFT JOIN categories c ON g.category_id = c.id
-		WHERE g.id = $1`
+		WHERE ` + where
 
 	gen := &Generation{}
-	err := r.queryRowContext(ctx, query, id).Scan(
+	var answers []byte
+	var shortCode sql.NullString
+	err := queryRow(ctx, query, arg).Scan(
 		&gen.ID,
 		&gen.ProjectIdea,
 		&gen.ExperienceLevel,
@@ -186,39 +388,43 @@ func (r *PostgresRepository) GetGeneration(ctx context.Context, id string) (*Gen
 		&gen.RatingCount,
 		&gen.ViewCount,
 		&gen.CreatedAt,
+		&gen.Visibility,
+		&answers,
+		&shortCode,
 	)
 
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, ErrNotFound
 	}
 	if err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		return nil, dbError(ctx, err)
 	}
+	gen.Answers = answers
+	gen.ShortCode = shortCode.String
 
 	return gen, nil
 }
 
 // ListGenerations retrieves a paginated list of generations with optional filtering.
+// Unlisted generations are never included.
 func (r *PostgresRepository) ListGenerations(ctx context.Context, filter ListFilter) ([]Generation, int, error) {
-	// Set defaults
-	if filter.Page < 1 {
-		filter.Page = 1
-	}
-	if filter.PageSize < 1 || filter.PageSize > 100 {
-		filter.PageSize = 20
-	}
+	filter.Page = pagination.NormalizePage(filter.Page)
+	filter.PageSize = pagination.NormalizePageSize(filter.PageSize)
+
+	ctx, cancel := r.withQueryTimeout(ctx)
+	defer cancel()
 
 	// Build query with optional category filter
 	baseQuery := `
 		FROM generations g
 		LEFT JOIN categories c ON g.category_id = c.id`
 
-	whereClause := ""
+	whereClause := fmt.Sprintf(" WHERE g.visibility = '%s'", VisibilityPublic)
 	args := []interface{}{}
 	argIndex := 1
 
 	if filter.CategoryID != nil {
-		whereClause = fmt.Sprintf(" WHERE g.category_id = $%d", argIndex)
+		whereClause += fmt.Sprintf(" AND g.category_id = $%d", argIndex)
 		args = append(args, *filter.CategoryID)
 		argIndex++
 	}
@@ -226,8 +432,8 @@ func (r *PostgresRepository) ListGenerations(ctx context.Context, filter ListFil
 	// Count total
 	countQuery := "SELECT COUNT(*)" + baseQuery + whereClause
 	var total int
-	if err := r.queryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
-		return nil, 0, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	if err := r.readQueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, dbError(ctx, err)
 	}
 
 	// Determine sort order
@@ -240,19 +446,20 @@ func (r *PostgresRepository) ListGenerations(ctx context.Context, filter ListFil
 	}
 
 	// Build select query with pagination
-	offset := (filter.Page - 1) * filter.PageSize
+	offset := pagination.Offset(filter.Page, filter.PageSize)
 	selectQuery := fmt.Sprintf(`
 		SELECT g.id, g.project_idea, g.experience_level, g.hook_preset, g.files,
-		       g.category_id, c.name, g.avg_rating, g.rating_count, g.view_count, g.created_at
+		       g.category_id, c.name, g.avg_rating, g.rating_count, g.view_count, g.created_at,
+		       g.visibility
 		%s%s%s
 		LIMIT $%d OFFSET $%d`,
 		baseQuery, whereClause, orderBy, argIndex, argIndex+1)
 
 	args = append(args, filter.PageSize, offset)
 
-	rows, err := r.queryContext(ctx, selectQuery, args...)
+	rows, err := r.readQueryContext(ctx, selectQuery, args...)
 	if err != nil {
-		return nil, 0, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		return nil, 0, dbError(ctx, err)
 	}
 	defer func() { _ = rows.Close() }()
 
@@ -271,30 +478,114 @@ func (r *PostgresRepository) ListGenerations(ctx context.Context, filter ListFil
 			&gen.RatingCount,
 			&gen.ViewCount,
 			&gen.CreatedAt,
+			&gen.Visibility,
 		); err != nil {
-			return nil, 0, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+			return nil, 0, dbError(ctx, err)
 		}
 		generations = append(generations, gen)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, 0, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		return nil, 0, dbError(ctx, err)
 	}
 
 	return generations, total, nil
 }
 
+// RecordCreator associates a generation with the IP hash of the visitor who
+// created it. Recording the same pair again is a no-op.
+func (r *PostgresRepository) RecordCreator(ctx context.Context, generationID string, ipHash string) error {
+	if generationID == "" || ipHash == "" {
+		return ErrInvalidInput
+	}
+
+	ctx, cancel := r.withQueryTimeout(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO generation_creators (generation_id, ip_hash)
+		VALUES ($1, $2)
+		ON CONFLICT (generation_id, ip_hash) DO NOTHING`
+	if _, err := r.execContext(ctx, query, generationID, ipHash); err != nil {
+		return dbError(ctx, err)
+	}
+	return nil
+}
+
+// ListGenerationsByCreator returns up to limit generations created from an IP
+// hash, most recently created first, without their files. Unlisted
+// generations are included since they belong to the caller. It reads from
+// the primary so a generation made a moment ago is listed.
+func (r *PostgresRepository) ListGenerationsByCreator(ctx context.Context, ipHash string, limit int) ([]Generation, error) {
+	if ipHash == "" {
+		return []Generation{}, nil
+	}
+	limit = pagination.NormalizePageSize(limit)
+
+	ctx, cancel := r.withQueryTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT g.id, g.project_idea, g.experience_level, g.hook_preset,
+		       g.category_id, c.name, g.avg_rating, g.rating_count, g.view_count, g.created_at,
+		       g.visibility, g.short_code
+		FROM generation_creators gc
+		JOIN generations g ON g.id = gc.generation_id
+		LEFT JOIN categories c ON g.category_id = c.id
+		WHERE gc.ip_hash = $1
+		ORDER BY gc.created_at DESC
+		LIMIT $2`
+
+	rows, err := r.queryContext(ctx, query, ipHash, limit)
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	generations := []Generation{}
+	for rows.Next() {
+		var gen Generation
+		var shortCode sql.NullString
+		if err := rows.Scan(
+			&gen.ID,
+			&gen.ProjectIdea,
+			&gen.ExperienceLevel,
+			&gen.HookPreset,
+			&gen.CategoryID,
+			&gen.CategoryName,
+			&gen.AvgRating,
+			&gen.RatingCount,
+			&gen.ViewCount,
+			&gen.CreatedAt,
+			&gen.Visibility,
+			&shortCode,
+		); err != nil {
+			return nil, dbError(ctx, err)
+		}
+		gen.ShortCode = shortCode.String
+		generations = append(generations, gen)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, dbError(ctx, err)
+	}
+
+	return generations, nil
+}
+
 // IncrementViewCount increments the view count for a generation.
 func (r *PostgresRepository) IncrementViewCount(ctx context.Context, id string) error {
+	ctx, cancel := r.withQueryTimeout(ctx)
+	defer cancel()
+
 	query := `UPDATE generations SET view_count = view_count + 1 WHERE id = $1`
 	result, err := r.execContext(ctx, query, id)
 	if err != nil {
-		return fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		return dbError(ctx, err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		return dbError(ctx, err)
 	}
 	if rowsAffected == 0 {
 		return ErrNotFound
@@ -312,10 +603,13 @@ func (r *PostgresRepository) RecordView(ctx context.Context, generationID string
 		return false, ErrInvalidInput
 	}
 
+	ctx, cancel := r.withQueryTimeout(ctx)
+	defer cancel()
+
 	// Use a transaction to ensure atomicity
 	tx, err := r.beginTx(ctx, nil)
 	if err != nil {
-		return false, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		return false, dbError(ctx, err)
 	}
 	defer func() { _ = tx.Rollback() }()
 
@@ -334,45 +628,89 @@ func (r *PostgresRepository) RecordView(ctx context.Context, generationID string
 		if err == sql.ErrNoRows {
 			// Conflict occurred - this IP has already viewed this generation
 			if err := tx.Commit(); err != nil {
-				return false, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+				return false, dbError(ctx, err)
 			}
 			return false, nil
 		}
-		return false, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		return false, dbError(ctx, err)
 	}
 
 	// New view - increment the view count
 	updateQuery := `UPDATE generations SET view_count = view_count + 1 WHERE id = $1`
 	result, err := tx.ExecContext(ctx, updateQuery, generationID)
 	if err != nil {
-		return false, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		return false, dbError(ctx, err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return false, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		return false, dbError(ctx, err)
 	}
 	if rowsAffected == 0 {
 		return false, ErrNotFound
 	}
 
 	if err := tx.Commit(); err != nil {
-		return false, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		return false, dbError(ctx, err)
 	}
 
 	return true, nil
 }
 
+// HasViewed reports whether a view of a generation is recorded for an IP hash.
+func (r *PostgresRepository) HasViewed(ctx context.Context, generationID string, ipHash string) (bool, error) {
+	ctx, cancel := r.withQueryTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT EXISTS (SELECT 1 FROM views WHERE generation_id = $1 AND ip_hash = $2)`
+
+	var viewed bool
+	if err := r.queryRowContext(ctx, query, generationID, ipHash).Scan(&viewed); err != nil {
+		return false, dbError(ctx, err)
+	}
+	return viewed, nil
+}
+
+// MarkTopRated records that a generation crossed the top-rated threshold and
+// reports whether this was the first time. Only the first caller gets true,
+// so the top-rated webhook fires once per generation.
+func (r *PostgresRepository) MarkTopRated(ctx context.Context, genID string) (bool, error) {
+	if genID == "" {
+		return false, ErrInvalidInput
+	}
+
+	ctx, cancel := r.withQueryTimeout(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO top_rated_notifications (generation_id)
+		VALUES ($1)
+		ON CONFLICT (generation_id) DO NOTHING`
+
+	result, err := r.execContext(ctx, query, genID)
+	if err != nil {
+		return false, dbError(ctx, err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, dbError(ctx, err)
+	}
+	return rows == 1, nil
+}
+
 // CreateOrUpdateRating creates or updates a rating for a generation.
 func (r *PostgresRepository) CreateOrUpdateRating(ctx context.Context, genID string, score int, voterHash string) error {
 	if score < 1 || score > 5 {
 		return fmt.Errorf("%w: score must be between 1 and 5", ErrInvalidInput)
 	}
 
+	ctx, cancel := r.withQueryTimeout(ctx)
+	defer cancel()
+
 	// Use upsert to handle both create and update
 	tx, err := r.beginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		return dbError(ctx, err)
 	}
 	defer func() { _ = tx.Rollback() }()
 
@@ -385,7 +723,7 @@ func (r *PostgresRepository) CreateOrUpdateRating(ctx context.Context, genID str
 
 	_, err = tx.ExecContext(ctx, upsertQuery, genID, score, voterHash)
 	if err != nil {
-		return fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		return dbError(ctx, err)
 	}
 
 	// Recalculate average rating
@@ -397,11 +735,11 @@ func (r *PostgresRepository) CreateOrUpdateRating(ctx context.Context, genID str
 
 	_, err = tx.ExecContext(ctx, updateAvgQuery, genID)
 	if err != nil {
-		return fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		return dbError(ctx, err)
 	}
 
 	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		return dbError(ctx, err)
 	}
 
 	return nil
@@ -409,6 +747,9 @@ func (r *PostgresRepository) CreateOrUpdateRating(ctx context.Context, genID str
 
 // GetUserRating retrieves the user's rating for a generation.
 func (r *PostgresRepository) GetUserRating(ctx context.Context, genID string, voterHash string) (int, error) {
+	ctx, cancel := r.withQueryTimeout(ctx)
+	defer cancel()
+
 	query := `SELECT score FROM ratings WHERE generation_id = $1 AND voter_hash = $2`
 
 	var score int
@@ -417,7 +758,7 @@ func (r *PostgresRepository) GetUserRating(ctx context.Context, genID string, vo
 		return 0, nil // No rating yet
 	}
 	if err != nil {
-		return 0, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		return 0, dbError(ctx, err)
 	}
 
 	return score, nil
@@ -427,11 +768,14 @@ func (r *PostgresRepository) GetUserRating(ctx context.Context, genID string, vo
 
 // GetCategories retrieves all categories.
 func (r *PostgresRepository) GetCategories(ctx context.Context) ([]Category, error) {
+	ctx, cancel := r.withQueryTimeout(ctx)
+	defer cancel()
+
 	query := `SELECT id, name, keywords FROM categories ORDER BY id`
 
-	rows, err := r.queryContext(ctx, query)
+	rows, err := r.readQueryContext(ctx, query)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		return nil, dbError(ctx, err)
 	}
 	defer func() { _ = rows.Close() }()
 
@@ -440,7 +784,7 @@ func (r *PostgresRepository) GetCategories(ctx context.Context) ([]Category, err
 		var cat Category
 		var keywords []byte
 		if err := rows.Scan(&cat.ID, &cat.Name, &keywords); err != nil {
-			return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+			return nil, dbError(ctx, err)
 		}
 		// Parse PostgreSQL array format
 		if err := parsePostgresArray(keywords, &cat.Keywords); err != nil {
@@ -450,12 +794,52 @@ func (r *PostgresRepository) GetCategories(ctx context.Context) ([]Category, err
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		return nil, dbError(ctx, err)
 	}
 
 	return categories, nil
 }
 
+// GetCategoryCounts retrieves all categories with how many public generations
+// each holds, in a single grouped query. Unlisted generations are not counted,
+// matching what ListGenerations returns.
+func (r *PostgresRepository) GetCategoryCounts(ctx context.Context) ([]CategoryCount, error) {
+	ctx, cancel := r.withQueryTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT c.id, c.name, c.keywords, COUNT(g.id)
+		FROM categories c
+		LEFT JOIN generations g ON g.category_id = c.id AND g.visibility = $1
+		GROUP BY c.id, c.name, c.keywords
+		ORDER BY c.id`
+
+	rows, err := r.readQueryContext(ctx, query, VisibilityPublic)
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	counts := []CategoryCount{}
+	for rows.Next() {
+		var cc CategoryCount
+		var keywords []byte
+		if err := rows.Scan(&cc.ID, &cc.Name, &keywords, &cc.Count); err != nil {
+			return nil, dbError(ctx, err)
+		}
+		if err := parsePostgresArray(keywords, &cc.Keywords); err != nil {
+			return nil, fmt.Errorf("%w: failed to parse keywords: %v", ErrDatabaseError, err)
+		}
+		counts = append(counts, cc)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, dbError(ctx, err)
+	}
+
+	return counts, nil
+}
+
 // parsePostgresArray parses a PostgreSQL text array into a Go string slice.
 func parsePostgresArray(data []byte, dest *[]string) error {
 	str := string(data)
@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ShortCodeLength is the number of base62 characters in a generation short code.
+// 62^8 codes keep random collisions rare for any realistic gallery size.
+const ShortCodeLength = 8
+
+// shortCodeAlphabet is the base62 alphabet short codes are drawn from.
+const shortCodeAlphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// maxShortCodeAttempts bounds how many fresh codes an insert tries after collisions.
+const maxShortCodeAttempts = 5
+
+// shortCodeConstraint is the unique index that rejects duplicate short codes.
+const shortCodeConstraint = "idx_generations_short_code"
+
+// pgUniqueViolation is the PostgreSQL SQLSTATE for unique constraint violations.
+const pgUniqueViolation = "23505"
+
+// NewShortCode returns a random base62 short code of ShortCodeLength characters.
+func NewShortCode() (string, error) {
+	code := make([]byte, 0, ShortCodeLength)
+	buf := make([]byte, ShortCodeLength*2)
+	for len(code) < ShortCodeLength {
+		if _, err := rand.Read(buf); err != nil {
+			return "", fmt.Errorf("generate short code: %w", err)
+		}
+		for _, b := range buf {
+			// Reject the top of the byte range so every character is equally likely
+			if int(b) >= 256-256%len(shortCodeAlphabet) {
+				continue
+			}
+			code = append(code, shortCodeAlphabet[int(b)%len(shortCodeAlphabet)])
+			if len(code) == ShortCodeLength {
+				break
+			}
+		}
+	}
+	return string(code), nil
+}
+
+// IsValidShortCode reports whether code has the shape of a short code.
+func IsValidShortCode(code string) bool {
+	if len(code) != ShortCodeLength {
+		return false
+	}
+	for i := 0; i < len(code); i++ {
+		c := code[i]
+		if !(c >= '0' && c <= '9' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z') {
+			return false
+		}
+	}
+	return true
+}
+
+// insertWithShortCode runs insert with fresh codes from newCode until one is
+// not taken, and returns the code that was stored. Errors other than a short
+// code collision are returned as-is.
+func insertWithShortCode(newCode func() (string, error), insert func(code string) error) (string, error) {
+	for attempt := 0; attempt < maxShortCodeAttempts; attempt++ {
+		code, err := newCode()
+		if err != nil {
+			return "", err
+		}
+		err = insert(code)
+		if isShortCodeConflict(err) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		return code, nil
+	}
+	return "", fmt.Errorf("%w: no free short code after %d attempts", ErrDuplicateKey, maxShortCodeAttempts)
+}
+
+// isShortCodeConflict reports whether err is a unique violation on the short code index.
+func isShortCodeConflict(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation && pgErr.ConstraintName == shortCodeConstraint
+}
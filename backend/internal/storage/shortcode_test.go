@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestNewShortCode_UniqueBase62(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		code, err := NewShortCode()
+		if err != nil {
+			t.Fatalf("NewShortCode() error = %v", err)
+		}
+		if !IsValidShortCode(code) {
+			t.Fatalf("NewShortCode() = %q, not a valid base62 short code", code)
+		}
+		if seen[code] {
+			t.Fatalf("NewShortCode() repeated %q", code)
+		}
+		seen[code] = true
+	}
+}
+
+func TestIsValidShortCode(t *testing.T) {
+	tests := []struct {
+		code string
+		want bool
+	}{
+		{"aZ09bY18", true},
+		{"aZ09bY1", false},
+		{"aZ09bY18x", false},
+		{"aZ09-Y18", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := IsValidShortCode(tt.code); got != tt.want {
+			t.Errorf("IsValidShortCode(%q) = %v, want %v", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestInsertWithShortCode_RetriesCollisions(t *testing.T) {
+	codes := []string{"taken001", "taken002", "free0003"}
+	next := 0
+	newCode := func() (string, error) {
+		code := codes[next]
+		next++
+		return code, nil
+	}
+
+	var tried []string
+	code, err := insertWithShortCode(newCode, func(code string) error {
+		tried = append(tried, code)
+		if code != "free0003" {
+			return fmt.Errorf("insert: %w", &pgconn.PgError{Code: pgUniqueViolation, ConstraintName: shortCodeConstraint})
+		}
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("insertWithShortCode() error = %v", err)
+	}
+	if code != "free0003" {
+		t.Errorf("code = %q, want free0003", code)
+	}
+	if len(tried) != 3 {
+		t.Errorf("tried %v, want 3 attempts", tried)
+	}
+}
+
+func TestInsertWithShortCode_GivesUp(t *testing.T) {
+	attempts := 0
+	_, err := insertWithShortCode(NewShortCode, func(string) error {
+		attempts++
+		return &pgconn.PgError{Code: pgUniqueViolation, ConstraintName: shortCodeConstraint}
+	})
+
+	if !errors.Is(err, ErrDuplicateKey) {
+		t.Errorf("error = %v, want ErrDuplicateKey", err)
+	}
+	if attempts != maxShortCodeAttempts {
+		t.Errorf("attempts = %d, want %d", attempts, maxShortCodeAttempts)
+	}
+}
+
+func TestInsertWithShortCode_OtherErrorsNotRetried(t *testing.T) {
+	attempts := 0
+	otherConstraint := &pgconn.PgError{Code: pgUniqueViolation, ConstraintName: "generations_pkey"}
+	_, err := insertWithShortCode(NewShortCode, func(string) error {
+		attempts++
+		return otherConstraint
+	})
+
+	if !errors.Is(err, otherConstraint) {
+		t.Errorf("error = %v, want the insert error", err)
+	}
+	if attempts != 1 {
+		t.Errorf("attempts = %d, want 1", attempts)
+	}
+}
@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+)
+
+// slowConnector opens connections whose queries block until their context
+// is done, standing in for a database that never answers.
+type slowConnector struct{}
+
+func (slowConnector) Connect(context.Context) (driver.Conn, error) { return slowConn{}, nil }
+func (slowConnector) Driver() driver.Driver                        { return slowDriver{} }
+
+type slowDriver struct{}
+
+func (slowDriver) Open(string) (driver.Conn, error) { return slowConn{}, nil }
+
+type slowConn struct{}
+
+func (slowConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (slowConn) Close() error                        { return nil }
+func (slowConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (slowConn) QueryContext(ctx context.Context, _ string, _ []driver.NamedValue) (driver.Rows, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (slowConn) ExecContext(ctx context.Context, _ string, _ []driver.NamedValue) (driver.Result, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func newSlowRepository(t *testing.T, timeout time.Duration) *PostgresRepository {
+	t.Helper()
+	sqlDB := sql.OpenDB(slowConnector{})
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	repo := NewPostgresRepository(sqlDB)
+	repo.SetQueryTimeout(timeout)
+	return repo
+}
+
+func TestQueryTimeout_SlowQueryReturnsTimeoutError(t *testing.T) {
+	repo := newSlowRepository(t, 50*time.Millisecond)
+
+	calls := map[string]func(ctx context.Context) error{
+		"GetGeneration": func(ctx context.Context) error {
+			_, err := repo.GetGeneration(ctx, "gen-1")
+			return err
+		},
+		"ListGenerations": func(ctx context.Context) error {
+			_, _, err := repo.ListGenerations(ctx, ListFilter{})
+			return err
+		},
+		"IncrementViewCount": func(ctx context.Context) error {
+			return repo.IncrementViewCount(ctx, "gen-1")
+		},
+	}
+
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			done := make(chan error, 1)
+			go func() { done <- call(context.Background()) }()
+
+			select {
+			case err := <-done:
+				if !errors.Is(err, ErrQueryTimeout) {
+					t.Errorf("error = %v, want ErrQueryTimeout", err)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("call hung past the query timeout")
+			}
+		})
+	}
+}
+
+func TestQueryTimeout_CallerCancellationIsNotATimeout(t *testing.T) {
+	repo := newSlowRepository(t, time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := repo.GetGeneration(ctx, "gen-1")
+	if errors.Is(err, ErrQueryTimeout) || !errors.Is(err, ErrDatabaseError) {
+		t.Errorf("error = %v, want ErrDatabaseError for the caller's own deadline", err)
+	}
+}
@@ -42,8 +42,8 @@ const PRESET_OPTIONS: PresetOption[] = [
   {
     id: 'strict',
     title: 'Strict',
-    description: 'Maximum enforcement - adds static analysis and dependency scanning',
-    hooks: ['format-on-stop', 'lint-on-stop', 'test-manual', 'secret-scan', 'prompt-guardrails', 'static-analysis', 'dep-scan'],
+    description: 'Maximum enforcement - adds static analysis, dependency scanning, and file lifecycle checks',
+    hooks: ['format-on-stop', 'lint-on-stop', 'test-manual', 'secret-scan', 'prompt-guardrails', 'static-analysis', 'dep-scan', 'test-on-create', 'cleanup-on-delete'],
     icon: <ShieldAlert className="h-5 w-5" />,
   },
 ]
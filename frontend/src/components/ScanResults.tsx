@@ -105,6 +105,17 @@ function FindingCard({ finding }: { finding: Finding }) {
       </CardHeader>
       <CardContent className="space-y-3">
         <p className="text-sm">{finding.description}</p>
+
+        {finding.snippet && (
+          <div className="space-y-2">
+            <div className="text-sm font-medium text-muted-foreground">
+              Code{finding.snippet_start_line ? ` (from line ${finding.snippet_start_line})` : ''}
+            </div>
+            <pre className="text-xs font-mono bg-muted/50 rounded p-2 overflow-x-auto whitespace-pre">
+              {finding.snippet}
+            </pre>
+          </div>
+        )}
         
         {finding.remediation && (
           <div className="space-y-2">
@@ -191,6 +202,28 @@ export function ScanResults({ job }: ScanResultsProps) {
     )
   }
 
+  // Nothing was scanned, which is not the same as a clean result
+  if (job.no_code) {
+    return (
+      <Card className="bg-card/50 backdrop-blur">
+        <CardHeader>
+          <CardTitle className="flex items-center gap-2">
+            <Info className="h-5 w-5 text-muted-foreground" />
+            No Scannable Code Detected
+          </CardTitle>
+        </CardHeader>
+        <CardContent>
+          <CardDescription>
+            No source files were found in{' '}
+            <span className="font-mono text-foreground">{job.repo_url}</span>
+            {job.subdir && <> under <span className="font-mono text-foreground">{job.subdir}</span></>}, so no
+            security tools were run. The repository may be empty or contain only documentation.
+          </CardDescription>
+        </CardContent>
+      </Card>
+    )
+  }
+
   // Handle no findings
   if (totalFindings === 0) {
     return (
@@ -6,7 +6,7 @@ const API_BASE = '/api'
 const DEFAULT_TIMEOUT_MS = 240 * 1000
 
 // Experience level type
-export type ExperienceLevel = 'beginner' | 'novice' | 'expert'
+export type ExperienceLevel = 'beginner' | 'novice' | 'expert' | 'auto'
 
 // Hook preset type
 export type HookPreset = 'light' | 'basic' | 'default' | 'strict'
@@ -36,24 +36,65 @@ export interface GenerateQuestionsRequest {
   experienceLevel: ExperienceLevel
 }
 
+export type ProjectComplexity = 'small' | 'medium' | 'large'
+
 export interface GenerateQuestionsResponse {
   questions: Question[]
+  experienceLevel: ExperienceLevel // Resolved level, never 'auto'
+  complexity: ProjectComplexity    // Heuristic size estimate
+}
+
+export interface GenerateExamplesResponse {
+  examples: string[]
 }
 
+// Unlisted generations are stored but hidden from the gallery listing
+export type Visibility = 'public' | 'unlisted'
+
 export interface GenerateOutputsRequest {
   projectIdea: string
   answers: Answer[]
   experienceLevel: ExperienceLevel
   hookPreset: HookPreset
+  visibility?: Visibility
+}
+
+export interface ValidationWarning {
+  filePath: string
+  message: string
 }
 
 export interface GenerateOutputsResponse {
   files: GeneratedFile[]
   generationId?: string // ID of stored generation for gallery link
+  shortCode?: string // Share code for resolveShortCode, when the generation was stored
+  warnings?: ValidationWarning[] // Non-fatal quality issues in the generated files
+  experienceLevel: ExperienceLevel // Resolved level, never 'auto'
+  attempts: number // Model calls needed for a valid response
+  duplicate?: boolean // generationId links to an identical generation stored earlier
+}
+
+export interface GenerateKickoffResponse {
+  file: GeneratedFile
+  experienceLevel: ExperienceLevel // Resolved level, never 'auto'
+}
+
+export type AnswerHintCode = 'empty' | 'evasive' | 'too_short' | 'off_topic'
+
+export interface AnswerHint {
+  questionId: number
+  code: AnswerHintCode
+  message: string
+}
+
+export interface AssessAnswersResponse {
+  hints: AnswerHint[]
 }
 
 export interface ErrorResponse {
   error: string
+  code?: string
+  requestId?: string
   retryAfter?: number
 }
 
@@ -62,13 +103,15 @@ export class ApiError extends Error {
   status: number
   retryAfter?: number
   isTimeout?: boolean
+  code?: string
 
-  constructor(message: string, status: number, retryAfter?: number, isTimeout?: boolean) {
+  constructor(message: string, status: number, retryAfter?: number, isTimeout?: boolean, code?: string) {
     super(message)
     this.name = 'ApiError'
     this.status = status
     this.retryAfter = retryAfter
     this.isTimeout = isTimeout
+    this.code = code
   }
 }
 
@@ -112,7 +155,7 @@ async function fetchWithRetry<T>(
       
       if (!res.ok) {
         const error: ErrorResponse = await res.json().catch(() => ({ error: errorMessage }))
-        const apiError = new ApiError(error.error, res.status, error.retryAfter)
+        const apiError = new ApiError(error.error, res.status, error.retryAfter, undefined, error.code)
         
         // Log the failed API call
         logger.logApiCall(method, url, res.status, duration)
@@ -179,31 +222,166 @@ async function fetchWithRetry<T>(
   throw new ApiError(errorMessage, 500)
 }
 
+// Proof-of-work challenge (only enforced when generation.pow_enabled is on)
+export interface ChallengeResponse {
+  enabled: boolean
+  token?: string
+  difficulty?: number
+  expiresAt?: string
+}
+
+export async function getChallenge(): Promise<ChallengeResponse> {
+  return fetchWithRetry<ChallengeResponse>(
+    `${API_BASE}/generate/challenge`,
+    { method: 'GET' },
+    'Failed to get challenge'
+  )
+}
+
+function leadingZeroBits(digest: Uint8Array): number {
+  let count = 0
+  for (const byte of digest) {
+    if (byte !== 0) {
+      return count + Math.clz32(byte) - 24
+    }
+    count += 8
+  }
+  return count
+}
+
+// Finds n such that SHA-256(token + ":" + n) starts with difficulty zero bits
+async function solveChallenge(token: string, difficulty: number): Promise<string> {
+  const encoder = new TextEncoder()
+  for (let n = 0; ; n++) {
+    const digest = await crypto.subtle.digest('SHA-256', encoder.encode(`${token}:${n}`))
+    if (leadingZeroBits(new Uint8Array(digest)) >= difficulty) {
+      return String(n)
+    }
+  }
+}
+
+// Returns the JSON headers for a generation request, solving a challenge if the server wants one
+async function generationHeaders(): Promise<Record<string, string>> {
+  const headers: Record<string, string> = { 'Content-Type': 'application/json' }
+  const challenge = await getChallenge()
+  if (challenge.enabled && challenge.token && challenge.difficulty) {
+    headers['X-PoW-Token'] = challenge.token
+    headers['X-PoW-Solution'] = await solveChallenge(challenge.token, challenge.difficulty)
+  }
+  return headers
+}
+
 // API functions
+// Accepted experience levels and hook presets, for data-driven selectors
+export interface GenerateOption {
+  value: string
+  title: string
+  description: string
+}
+
+export interface HookPresetOption extends GenerateOption {
+  hooks: string[]
+}
+
+export interface GenerateOptionsResponse {
+  experienceLevels: GenerateOption[]
+  hookPresets: HookPresetOption[]
+}
+
+export async function getGenerateOptions(): Promise<GenerateOptionsResponse> {
+  return fetchWithRetry<GenerateOptionsResponse>(
+    `${API_BASE}/generate/options`,
+    { method: 'GET' },
+    'Failed to load generation options'
+  )
+}
+
 export async function generateQuestions(projectIdea: string, experienceLevel: ExperienceLevel): Promise<GenerateQuestionsResponse> {
   return fetchWithRetry<GenerateQuestionsResponse>(
     `${API_BASE}/generate/questions`,
     {
       method: 'POST',
-      headers: { 'Content-Type': 'application/json' },
+      headers: await generationHeaders(),
       body: JSON.stringify({ projectIdea, experienceLevel }),
     },
     'Failed to generate questions'
   )
 }
 
-export async function generateOutputs(projectIdea: string, answers: Answer[], experienceLevel: ExperienceLevel, hookPreset: HookPreset): Promise<GenerateOutputsResponse> {
+export async function generateExamples(question: string, projectIdea: string, experienceLevel: ExperienceLevel): Promise<GenerateExamplesResponse> {
+  return fetchWithRetry<GenerateExamplesResponse>(
+    `${API_BASE}/generate/examples`,
+    {
+      method: 'POST',
+      headers: await generationHeaders(),
+      body: JSON.stringify({ question, projectIdea, experienceLevel }),
+    },
+    'Failed to generate examples'
+  )
+}
+
+export async function generateOutputs(projectIdea: string, answers: Answer[], experienceLevel: ExperienceLevel, hookPreset: HookPreset, visibility: Visibility = 'public'): Promise<GenerateOutputsResponse> {
   return fetchWithRetry<GenerateOutputsResponse>(
     `${API_BASE}/generate/outputs`,
     {
       method: 'POST',
-      headers: { 'Content-Type': 'application/json' },
-      body: JSON.stringify({ projectIdea, answers, experienceLevel, hookPreset }),
+      headers: await generationHeaders(),
+      body: JSON.stringify({ projectIdea, answers, experienceLevel, hookPreset, visibility }),
     },
     'Failed to generate outputs'
   )
 }
 
+// Generates just the kickoff prompt; call generateOutputs for the full file set
+export async function generateKickoff(projectIdea: string, answers: Answer[], experienceLevel: ExperienceLevel): Promise<GenerateKickoffResponse> {
+  return fetchWithRetry<GenerateKickoffResponse>(
+    `${API_BASE}/generate/kickoff`,
+    {
+      method: 'POST',
+      headers: await generationHeaders(),
+      body: JSON.stringify({ projectIdea, answers, experienceLevel }),
+    },
+    'Failed to generate kickoff prompt'
+  )
+}
+
+// Flags weak answers with quick heuristics; no model call is made
+export async function assessAnswers(questions: Question[], answers: Answer[]): Promise<AssessAnswersResponse> {
+  return fetchWithRetry<AssessAnswersResponse>(
+    `${API_BASE}/generate/assess`,
+    {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ questions, answers }),
+    },
+    'Failed to check answers'
+  )
+}
+
+// A generation created from the caller's IP
+export interface GenerationHistoryItem {
+  id: string
+  projectIdea: string
+  category: string
+  visibility: Visibility
+  shortCode?: string
+  createdAt: string
+  createdAtRelative?: string // with ?relative=true
+}
+
+export interface GenerationHistoryResponse {
+  items: GenerationHistoryItem[]
+}
+
+export async function getGenerationHistory(pageSize?: number): Promise<GenerationHistoryResponse> {
+  const query = pageSize ? `?pageSize=${pageSize}` : ''
+  return fetchWithRetry<GenerationHistoryResponse>(
+    `${API_BASE}/generate/history${query}`,
+    { method: 'GET' },
+    'Failed to load your generations'
+  )
+}
+
 // Gallery types
 export interface GalleryItem {
   id: string
@@ -214,6 +392,7 @@ export interface GalleryItem {
   viewCount: number
   createdAt: string
   preview: string
+  createdAtRelative?: string // with ?relative=true
 }
 
 export interface GalleryListResponse {
@@ -235,6 +414,8 @@ export interface GalleryDetail {
   ratingCount: number
   viewCount: number
   createdAt: string
+  shortCode?: string // absent for generations stored before share links
+  createdAtRelative?: string // with ?relative=true
 }
 
 export interface GalleryDetailResponse {
@@ -253,6 +434,25 @@ export interface RateResponse {
   success: boolean
 }
 
+export interface ViewResponse {
+  counted: boolean // false when this visitor already viewed the generation
+}
+
+export interface DownloadLinkResponse {
+  url: string // relative; works without authentication until expiresAt
+  expiresAt: string
+}
+
+export interface GalleryCategory {
+  id: number
+  name: string
+  count: number // listed generations only; unlisted ones are not counted
+}
+
+export interface GalleryCategoriesResponse {
+  categories: GalleryCategory[]
+}
+
 // Gallery API functions
 export async function listGallery(filters: GalleryFilters): Promise<GalleryListResponse> {
   const params = new URLSearchParams()
@@ -272,6 +472,14 @@ export async function listGallery(filters: GalleryFilters): Promise<GalleryListR
   )
 }
 
+export async function listGalleryCategories(): Promise<GalleryCategoriesResponse> {
+  return fetchWithRetry<GalleryCategoriesResponse>(
+    `${API_BASE}/gallery/categories`,
+    { method: 'GET' },
+    'Failed to load categories'
+  )
+}
+
 export async function getGalleryItem(id: string, voterHash?: string): Promise<GalleryDetailResponse> {
   const params = new URLSearchParams()
   if (voterHash) {
@@ -287,6 +495,24 @@ export async function getGalleryItem(id: string, voterHash?: string): Promise<Ga
   )
 }
 
+// Resolves a share link's short code to the same detail as getGalleryItem
+export async function resolveShortCode(code: string): Promise<GalleryDetailResponse> {
+  return fetchWithRetry<GalleryDetailResponse>(
+    `${API_BASE}/gallery/s/${encodeURIComponent(code)}`,
+    { method: 'GET' },
+    'Failed to load shared generation'
+  )
+}
+
+// Registers a page view; fetching an item never counts as a view on its own
+export async function recordGalleryView(id: string): Promise<ViewResponse> {
+  return fetchWithRetry<ViewResponse>(
+    `${API_BASE}/gallery/${id}/view`,
+    { method: 'POST' },
+    'Failed to record view'
+  )
+}
+
 export async function rateGalleryItem(id: string, score: number, voterHash: string): Promise<RateResponse> {
   return fetchWithRetry<RateResponse>(
     `${API_BASE}/gallery/${id}/rate`,
@@ -299,9 +525,19 @@ export async function rateGalleryItem(id: string, score: number, voterHash: stri
   )
 }
 
+// Signs a time-limited link that downloads the generation's files as a ZIP
+export async function createDownloadLink(id: string): Promise<DownloadLinkResponse> {
+  return fetchWithRetry<DownloadLinkResponse>(
+    `${API_BASE}/gallery/${id}/download-link`,
+    { method: 'POST' },
+    'Failed to create download link'
+  )
+}
+
 // Security Scan types
 export type ScanStatus = 'pending' | 'cloning' | 'scanning' | 'reviewing' | 'completed' | 'failed'
 export type FindingSeverity = 'critical' | 'high' | 'medium' | 'low' | 'info'
+export type FindingConfidence = 'high' | 'medium' | 'low'
 
 export interface Finding {
   id: string
@@ -309,14 +545,24 @@ export interface Finding {
   tool: string
   file_path: string
   line_number?: number
+  end_line?: number
+  start_column?: number  // 1-based
+  end_column?: number    // 1-based
   description: string
   remediation?: string
   code_example?: string
+  confidence?: FindingConfidence
+  rule_id?: string
+  fingerprint?: string    // stable across rescans; absent on scans before schema version 4
+  snippet?: string        // surrounding code when the server stores snippets; secrets masked
+  snippet_start_line?: number
+  acknowledged?: boolean  // set on findings in acknowledged_findings
+  ack_reason?: string
 }
 
 export interface ReviewStats {
   total_findings: number
-  reviewable_findings: number  // high/medium/critical only
+  reviewable_findings: number  // at or above the review severity threshold (medium by default)
   reviewed_findings: number    // actually sent to AI (max 10)
   matched_findings: number     // successfully matched with AI response
 }
@@ -325,12 +571,52 @@ export interface ScanJob {
   id: string
   status: ScanStatus
   repo_url: string
+  subdir?: string  // scanned subdirectory; finding paths are relative to it
   languages: string[]
   findings: Finding[]
   review_stats?: ReviewStats
   error?: string
   created_at: string
   completed_at?: string
+  created_at_relative?: string  // with ?relative=true
+  completed_at_relative?: string
+  suppressed_findings: number
+  partial: boolean  // scan hit the duration limit; findings are incomplete
+  truncated: boolean  // findings were capped at the server limit, keeping the most severe
+  truncated_findings: number
+  schema_version: number  // finding layout the scan was stored with; newer fields may be absent on older scans
+  no_code: boolean  // no source files were detected, so no tools ran; not the same as a clean scan
+  score?: ScanScore  // set once the scan completes, except for no-code scans
+  acknowledged_findings?: Finding[]  // accepted via acknowledgeFinding; not in findings or the score
+}
+
+export interface ScanScore {
+  score: number  // 0-100, 100 for a scan without findings
+  grade: 'A' | 'B' | 'C' | 'D' | 'F'
+}
+
+export type ScanEventType =
+  | 'status'
+  | 'clone_started'
+  | 'clone_complete'
+  | 'languages_detected'
+  | 'tool_started'
+  | 'tool_complete'
+  | 'budget_exceeded'
+  | 'aggregate_complete'
+  | 'review_started'
+  | 'review_complete'
+  | 'completed'
+  | 'failed'
+
+export interface ScanEvent {
+  type: ScanEventType
+  job_id: string
+  tool?: string
+  languages?: string[]
+  finding_count?: number
+  message?: string  // job status for 'status', reason for 'failed'
+  time: string
 }
 
 export interface ScanConfig {
@@ -346,13 +632,14 @@ interface ScanConfigResponse {
 }
 
 // Security Scan API functions
-export async function startScan(repoUrl: string): Promise<ScanJob> {
+// subdir limits the scan to one directory of a monorepo; omit it to scan the whole repository
+export async function startScan(repoUrl: string, subdir?: string): Promise<ScanJob> {
   return fetchWithRetry<ScanJob>(
     `${API_BASE}/scan`,
     {
       method: 'POST',
       headers: { 'Content-Type': 'application/json' },
-      body: JSON.stringify({ repo_url: repoUrl }),
+      body: JSON.stringify({ repo_url: repoUrl, subdir: subdir || undefined }),
     },
     'Failed to start scan'
   )
@@ -366,6 +653,95 @@ export async function getScanStatus(jobId: string): Promise<ScanJob> {
   )
 }
 
+const SCAN_EVENT_TYPES: ScanEventType[] = [
+  'status', 'clone_started', 'clone_complete', 'languages_detected', 'tool_started', 'tool_complete',
+  'budget_exceeded', 'aggregate_complete', 'review_started', 'review_complete', 'completed', 'failed',
+]
+
+// Streams live progress for a scan. The server closes the stream when the scan
+// finishes; the returned function closes it early.
+export function subscribeScanEvents(jobId: string, onEvent: (event: ScanEvent) => void): () => void {
+  const source = new EventSource(`${API_BASE}/scan/${jobId}/events`)
+  const handle = (e: MessageEvent) => onEvent(JSON.parse(e.data) as ScanEvent)
+  for (const type of SCAN_EVENT_TYPES) {
+    source.addEventListener(type, handle)
+  }
+  // Without this EventSource reconnects once the server ends the stream
+  source.onerror = () => source.close()
+  return () => source.close()
+}
+
+export async function remediateFinding(jobId: string, findingId: string): Promise<Finding> {
+  const response = await fetchWithRetry<{ finding: Finding }>(
+    `${API_BASE}/scan/${jobId}/findings/${findingId}/remediate`,
+    { method: 'POST' },
+    'Failed to generate remediation'
+  )
+  return response.finding
+}
+
+// Looks up a finding by fingerprint, for permalinks that survive rescans.
+export async function getFindingByFingerprint(jobId: string, fingerprint: string): Promise<Finding> {
+  const response = await fetchWithRetry<{ finding: Finding }>(
+    `${API_BASE}/scan/${jobId}/findings/${fingerprint}`,
+    { method: 'GET' },
+    'Failed to get finding'
+  )
+  return response.finding
+}
+
+export interface Acknowledgement {
+  repo_url: string
+  rule_id: string
+  file_path: string  // relative to the repository root
+  reason: string
+  created_at: string
+}
+
+// Accepts a rule's findings in one file for every scan of the repository.
+// filePath is the finding's path, relative to subdir for subdirectory scans.
+// The server only accepts this with its internal API key, so browser calls
+// without one are rejected with 401.
+export async function acknowledgeFinding(
+  repoUrl: string,
+  ruleId: string,
+  filePath: string,
+  reason: string,
+  subdir?: string
+): Promise<Acknowledgement> {
+  return fetchWithRetry<Acknowledgement>(
+    `${API_BASE}/scan/ack`,
+    {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({
+        repo_url: repoUrl,
+        subdir: subdir || undefined,
+        rule_id: ruleId,
+        file_path: filePath,
+        reason,
+      }),
+    },
+    'Failed to acknowledge finding'
+  )
+}
+
+export interface FindingExplanation {
+  finding_id: string
+  tool: string
+  rule_id?: string
+  explanation: string
+  cached: boolean  // served from the server's per-rule cache
+}
+
+export async function explainFinding(jobId: string, findingId: string): Promise<FindingExplanation> {
+  return fetchWithRetry<FindingExplanation>(
+    `${API_BASE}/scan/${jobId}/findings/${findingId}/explain`,
+    { method: 'POST' },
+    'Failed to explain finding'
+  )
+}
+
 export async function getScanConfig(): Promise<ScanConfig> {
   const response = await fetchWithRetry<ScanConfigResponse>(
     `${API_BASE}/scan/config`,
@@ -7,6 +7,7 @@ import { ErrorMessage } from '@/components/shared/ErrorMessage'
 import {
   listGallery,
   getGalleryItem,
+  recordGalleryView,
   rateGalleryItem,
   type GalleryItem,
   type GalleryDetail as GalleryDetailType,
@@ -86,6 +87,8 @@ export function GalleryPage({ onBack, initialItemId, onOpenInfo }: GalleryPagePr
   useEffect(() => {
     if (selectedId) {
       fetchDetail(selectedId)
+      // Count the page view once; refetches after rating must not count again
+      recordGalleryView(selectedId).catch(() => {})
     } else {
       setSelectedGeneration(null)
       setUserRating(null)